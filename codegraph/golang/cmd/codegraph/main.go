@@ -4,10 +4,13 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/humanbeeng/lepo/prototypes/codegraph/extract/golang"
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract/python"
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract/typescript"
 	"github.com/humanbeeng/lepo/prototypes/codegraph/process"
 )
 
 func main() {
 	_ = godotenv.Load()
-	process.Orchestrate(golang.NewGoExtractor())
+	scope := process.ExtractScopeFromEnv()
+	process.Orchestrate(golang.NewGoExtractor().WithScope(scope), typescript.NewTSExtractor().WithScope(scope), python.NewPyExtractor().WithScope(scope), scope)
 }
@@ -0,0 +1,53 @@
+package extract
+
+import "slices"
+
+// AsyncBoundary is a hand-off where work crosses from one call stack to
+// another without a call between them, like a job queue: the webhook
+// handler enqueues a task and a worker picks it up later.
+//
+// Produce and Consume list qnames. A function calling one of Produce puts
+// work on the boundary. A function calling one of Consume, or returning one
+// of them (a handler type such as queue.MessageProcessor, whose factory
+// builds the code that runs per message), takes work off it.
+type AsyncBoundary struct {
+	Name    string   `json:"name"`
+	Produce []string `json:"produce"`
+	Consume []string `json:"consume"`
+}
+
+// LinkAsync sets Enqueues and Dequeues on the functions that produce to or
+// consume from one of boundaries and returns how many functions it linked.
+// It runs on the merged result so producers and consumers in different
+// modules are linked too.
+func LinkAsync(functions map[string]Function, boundaries []AsyncBoundary) int {
+	linked := 0
+	for qname, fn := range functions {
+		fn.Enqueues, fn.Dequeues = nil, nil
+		for _, b := range boundaries {
+			if b.Name == "" {
+				continue
+			}
+			if containsAny(fn.Calls, b.Produce) {
+				fn.Enqueues = append(fn.Enqueues, b.Name)
+			}
+			if containsAny(fn.Calls, b.Consume) || containsAny(fn.ReturnQNames, b.Consume) {
+				fn.Dequeues = append(fn.Dequeues, b.Name)
+			}
+		}
+		if len(fn.Enqueues) > 0 || len(fn.Dequeues) > 0 {
+			linked++
+		}
+		functions[qname] = fn
+	}
+	return linked
+}
+
+func containsAny(qnames, targets []string) bool {
+	for _, t := range targets {
+		if slices.Contains(qnames, t) {
+			return true
+		}
+	}
+	return false
+}
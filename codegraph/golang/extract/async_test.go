@@ -0,0 +1,54 @@
+package extract
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLinkAsync(t *testing.T) {
+	functions := map[string]Function{
+		"app/api.HandleWebhook": {
+			QName: "app/api.HandleWebhook",
+			Calls: []string{"app/api.parse", "app/queue.Producer.Enqueue"},
+		},
+		"app/worker.runLoop": {
+			QName: "app/worker.runLoop",
+			Calls: []string{"app/queue.RedisConsumer.Read"},
+		},
+		"app/worker.newProcessor": {
+			QName:        "app/worker.newProcessor",
+			Calls:        []string{"app/brain.Planner.Plan"},
+			ReturnQNames: []string{"app/queue.MessageProcessor"},
+		},
+		"app/api.parse": {
+			QName: "app/api.parse",
+			// Left over from an earlier run; relinking clears it.
+			Enqueues: []string{"stale"},
+		},
+	}
+	boundaries := []AsyncBoundary{
+		{
+			Name:    "tasks",
+			Produce: []string{"app/queue.Producer.Enqueue"},
+			Consume: []string{"app/queue.RedisConsumer.Read", "app/queue.MessageProcessor"},
+		},
+		{Name: "", Produce: []string{"app/api.parse"}},
+	}
+
+	if got := LinkAsync(functions, boundaries); got != 3 {
+		t.Errorf("LinkAsync linked %d functions, want 3", got)
+	}
+
+	if fn := functions["app/api.HandleWebhook"]; !slices.Equal(fn.Enqueues, []string{"tasks"}) || fn.Dequeues != nil {
+		t.Errorf("HandleWebhook enqueues %v, dequeues %v; want enqueue on tasks", fn.Enqueues, fn.Dequeues)
+	}
+	if fn := functions["app/worker.runLoop"]; !slices.Equal(fn.Dequeues, []string{"tasks"}) || fn.Enqueues != nil {
+		t.Errorf("runLoop enqueues %v, dequeues %v; want dequeue from tasks", fn.Enqueues, fn.Dequeues)
+	}
+	if fn := functions["app/worker.newProcessor"]; !slices.Equal(fn.Dequeues, []string{"tasks"}) {
+		t.Errorf("newProcessor dequeues %v, want tasks via its returned handler type", fn.Dequeues)
+	}
+	if fn := functions["app/api.parse"]; fn.Enqueues != nil || fn.Dequeues != nil {
+		t.Errorf("parse enqueues %v, dequeues %v; want neither", fn.Enqueues, fn.Dequeues)
+	}
+}
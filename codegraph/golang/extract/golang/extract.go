@@ -5,6 +5,7 @@ import (
 	"go/token"
 	"go/types"
 	"log/slog"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,12 +15,36 @@ import (
 	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
 )
 
-type GoExtractor struct{}
+type GoExtractor struct {
+	scope extract.Scope
+}
 
 func NewGoExtractor() *GoExtractor {
 	return &GoExtractor{}
 }
 
+// WithScope restricts extraction to files allowed by scope. Paths are
+// matched relative to the directory passed to Extract.
+func (g *GoExtractor) WithScope(scope extract.Scope) *GoExtractor {
+	g.scope = scope
+	return g
+}
+
+func (g *GoExtractor) inScope(dir, filename string) bool {
+	if g.scope.IsZero() {
+		return true
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return true
+	}
+	rel, err := filepath.Rel(absDir, filename)
+	if err != nil {
+		return true
+	}
+	return g.scope.Allows(filepath.ToSlash(rel))
+}
+
 func (g *GoExtractor) Extract(pkgstr string, dir string) (extract.ExtractNodesResult, error) {
 	// TODO: Change or add directory path as well.
 	start := time.Now()
@@ -103,6 +128,17 @@ func (g *GoExtractor) Extract(pkgstr string, dir string) (extract.ExtractNodesRe
 			return
 		}
 
+		files := make([]*ast.File, 0, len(pkg.Syntax))
+		for _, file := range pkg.Syntax {
+			if g.inScope(dir, fset.Position(file.Pos()).Filename) {
+				files = append(files, file)
+			}
+		}
+		if len(files) == 0 {
+			slog.Info("Skipping package outside extraction scope", "package", pkg.PkgPath)
+			return
+		}
+
 		extractRes.Namespaces = append(
 			extractRes.Namespaces,
 			extract.Namespace{
@@ -111,7 +147,7 @@ func (g *GoExtractor) Extract(pkgstr string, dir string) (extract.ExtractNodesRe
 		)
 
 		slog.Info("Analysing", "package", pkg.PkgPath)
-		slog.Info("Files found in", "package", pkg.PkgPath, "count", len(pkg.Syntax))
+		slog.Info("Files found in", "package", pkg.PkgPath, "count", len(files), "out_of_scope", len(pkg.Syntax)-len(files))
 
 		typeObjs := make(map[string]types.Type)
 		interfaceObjs := make(map[string]*types.Interface)
@@ -159,7 +195,7 @@ func (g *GoExtractor) Extract(pkgstr string, dir string) (extract.ExtractNodesRe
 			InterfaceObjs: interfaceObjs,
 		}
 
-		for _, file := range pkg.Syntax {
+		for _, file := range files {
 			slog.Info("Walking", "file", fset.Position(file.Pos()).Filename)
 			ast.Walk(tv, file)
 			ast.Walk(nv, file)
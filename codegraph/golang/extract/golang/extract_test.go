@@ -1,11 +1,14 @@
 package golang
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"go/token"
 	"go/types"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
@@ -685,9 +688,9 @@ go 1.24
 
 type User struct {
 	ID        int64
-	Email     string
+	Email     string `+"`json:\"email\"`"+`
 	Profile   *Profile
-	CreatedAt int64
+	CreatedAt int64 `+"`json:\"created_at\" db:\"created_at\"`"+`
 }
 
 type Profile struct {
@@ -726,6 +729,18 @@ type Profile struct {
 			t.Errorf("field %s has ParentQName=%q, want %q", fieldQName, member.ParentQName, userQName)
 		}
 	}
+
+	// Verify struct tags are captured without the backquotes
+	wantTags := map[string]string{
+		"Email":     `json:"email"`,
+		"CreatedAt": `json:"created_at" db:"created_at"`,
+		"ID":        "",
+	}
+	for fieldName, want := range wantTags {
+		if got := res.Members[userQName+"."+fieldName].Tag; got != want {
+			t.Errorf("field %s has Tag=%q, want %q", fieldName, got, want)
+		}
+	}
 }
 
 // TestInterfaceImplementation verifies the implements relationship extraction.
@@ -911,18 +926,24 @@ func (l *List[T]) Get(index int) T {
 		t.Errorf("missing generic type %s", stackQName)
 	}
 
-	// Test: Methods on generic type should be extracted
-	// KNOWN LIMITATION: Methods on generic types are extracted but without ParentQName
-	// because the receiver type includes type parameters (e.g., *Stack[T]) which
-	// doesn't match the simple type name extraction logic.
-	pushQName := "example.com/generics/collections.Push"
-	pushFn, ok := res.Functions[pushQName]
-	if !ok {
-		t.Errorf("missing method %s", pushQName)
-	} else {
-		t.Logf("Push method extracted with parent: %q (empty is known limitation for generics)", pushFn.ParentQName)
-		// Log signature to verify it's captured
-		t.Logf("Push signature: %s", pushFn.Signature)
+	// Test: Methods on generic types should be qualified by and linked to the
+	// type, with the type parameters stripped from the receiver
+	for _, method := range []string{"Push", "Pop"} {
+		methodQName := stackQName + "." + method
+		fn, ok := res.Functions[methodQName]
+		if !ok {
+			t.Errorf("missing method %s", methodQName)
+			continue
+		}
+		if fn.ParentQName != stackQName {
+			t.Errorf("method %s has ParentQName=%q, want %q", methodQName, fn.ParentQName, stackQName)
+		}
+		if fn.Signature == "" {
+			t.Errorf("method %s has empty signature", methodQName)
+		}
+	}
+	if _, ok := res.Functions["example.com/generics/collections.Push"]; ok {
+		t.Errorf("generic method Push should not be extracted as a package-level function")
 	}
 
 	// Test: Generic interface should be extracted
@@ -1456,3 +1477,304 @@ var initialized = func() bool {
 	// Closures themselves are anonymous and typically not extracted as named functions
 	// That's expected behavior - we're verifying the containing functions work
 }
+
+// TestExtractorScopeExcludesFiles verifies that out-of-scope files produce no nodes.
+func TestExtractorScopeExcludesFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "go.mod"), `module example.com/scoped
+
+go 1.24
+`)
+
+	writeFile(t, filepath.Join(dir, "app", "app.go"), `package app
+
+type Server struct {
+	Addr string
+}
+
+func Run() {}
+`)
+
+	writeFile(t, filepath.Join(dir, "app", "zz_gen.go"), `package app
+
+type GeneratedClient struct{}
+
+func GeneratedHelper() {}
+`)
+
+	writeFile(t, filepath.Join(dir, "mocks", "mocks.go"), `package mocks
+
+func NewMock() {}
+`)
+
+	extractor := NewGoExtractor().WithScope(extract.Scope{
+		Exclude: []string{"*_gen.go", "mocks/"},
+	})
+	res, err := extractor.Extract("example.com/scoped", dir)
+	if err != nil {
+		t.Fatalf("extract failed: %v", err)
+	}
+
+	for _, qname := range []string{"example.com/scoped/app.Run", "example.com/scoped/app.Server"} {
+		_, isFunc := res.Functions[qname]
+		_, isType := res.TypeDecls[qname]
+		if !isFunc && !isType {
+			t.Errorf("expected in-scope symbol %s to be extracted", qname)
+		}
+	}
+
+	for _, qname := range []string{"example.com/scoped/app.GeneratedHelper", "example.com/scoped/mocks.NewMock"} {
+		if _, ok := res.Functions[qname]; ok {
+			t.Errorf("expected out-of-scope function %s to be skipped", qname)
+		}
+	}
+	if _, ok := res.TypeDecls["example.com/scoped/app.GeneratedClient"]; ok {
+		t.Errorf("expected out-of-scope type GeneratedClient to be skipped")
+	}
+
+	for filename := range res.Files {
+		if strings.HasSuffix(filename, "zz_gen.go") || strings.Contains(filename, "/mocks/") {
+			t.Errorf("expected out-of-scope file %s to be skipped", filename)
+		}
+	}
+	for _, ns := range res.Namespaces {
+		if ns.Name == "example.com/scoped/mocks" {
+			t.Errorf("expected fully excluded package %s to have no namespace node", ns.Name)
+		}
+	}
+}
+
+// TestExtractorScopeInclude verifies that a non-empty include list limits extraction.
+func TestExtractorScopeInclude(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "go.mod"), `module example.com/included
+
+go 1.24
+`)
+	writeFile(t, filepath.Join(dir, "core", "core.go"), "package core\n\nfunc Core() {}\n")
+	writeFile(t, filepath.Join(dir, "tools", "tools.go"), "package tools\n\nfunc Tool() {}\n")
+
+	extractor := NewGoExtractor().WithScope(extract.Scope{Include: []string{"core/**"}})
+	res, err := extractor.Extract("example.com/included", dir)
+	if err != nil {
+		t.Fatalf("extract failed: %v", err)
+	}
+
+	if _, ok := res.Functions["example.com/included/core.Core"]; !ok {
+		t.Errorf("expected included function Core to be extracted")
+	}
+	if _, ok := res.Functions["example.com/included/tools.Tool"]; ok {
+		t.Errorf("expected non-included function Tool to be skipped")
+	}
+}
+
+// TestAliasedImportResolvesToNamespace verifies that aliased imports keep the
+// alias as the import name and point at the imported package's namespace.
+func TestAliasedImportResolvesToNamespace(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "go.mod"), `module example.com/aliased
+
+go 1.24
+`)
+
+	writeFile(t, filepath.Join(dir, "repo", "repo.go"), `package repo
+
+type User struct{}
+`)
+
+	writeFile(t, filepath.Join(dir, "service", "service.go"), `package service
+
+import r "example.com/aliased/repo"
+
+func Load() *r.User {
+	return nil
+}
+`)
+
+	res, err := NewGoExtractor().Extract("example.com/aliased", dir)
+	if err != nil {
+		t.Fatalf("extract failed: %v", err)
+	}
+
+	namespaces := make(map[string]bool)
+	for _, ns := range res.Namespaces {
+		namespaces[ns.Name] = true
+	}
+
+	var found bool
+	for _, f := range res.Files {
+		if f.Namespace.Name != "example.com/aliased/service" {
+			continue
+		}
+		for _, imp := range f.Imports {
+			if imp.Name != "r" {
+				continue
+			}
+			found = true
+			if imp.Path != "example.com/aliased/repo" {
+				t.Errorf("import path = %q, want example.com/aliased/repo", imp.Path)
+			}
+			if !namespaces[imp.Path] {
+				t.Errorf("import path %q has no matching namespace node, namespaces: %v", imp.Path, namespaces)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("aliased import not recorded, files: %+v", res.Files)
+	}
+}
+
+func TestFileContentHash(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "go.mod"), `module example.com/hashed
+
+go 1.24
+`)
+
+	// No declarations besides a func, so no GenDecl in the file
+	src := `package util
+
+func Double(n int) int {
+	return n * 2
+}
+`
+	utilPath := filepath.Join(dir, "util", "util.go")
+	writeFile(t, utilPath, src)
+
+	res, err := NewGoExtractor().Extract("example.com/hashed", dir)
+	if err != nil {
+		t.Fatalf("extract failed: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(src))
+	want := hex.EncodeToString(sum[:])
+
+	var found bool
+	for _, f := range res.Files {
+		if f.Filename != utilPath {
+			continue
+		}
+		found = true
+		if f.Hash != want {
+			t.Errorf("file hash = %q, want %q", f.Hash, want)
+		}
+	}
+	if !found {
+		t.Fatalf("file %s not recorded, files: %+v", utilPath, res.Files)
+	}
+}
+
+func TestCanonicalImportPath(t *testing.T) {
+	tests := []struct {
+		importer string
+		path     string
+		want     string
+	}{
+		{"example.com/app/service", "example.com/app/repo", "example.com/app/repo"},
+		{"example.com/app/service", "./internal", "example.com/app/service/internal"},
+		{"example.com/app/service", "../repo", "example.com/app/repo"},
+		{"example.com/app/service", "example.com/app/vendor/github.com/pkg/errors", "github.com/pkg/errors"},
+		{"example.com/app/service", "vendor/golang.org/x/net/http2", "golang.org/x/net/http2"},
+		{"example.com/app/service", "context", "context"},
+	}
+
+	for _, tt := range tests {
+		if got := canonicalImportPath(tt.importer, tt.path); got != tt.want {
+			t.Errorf("canonicalImportPath(%q, %q) = %q, want %q", tt.importer, tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestAsyncBoundaryLinking extracts a producer and a consumer of a queue and
+// checks LinkAsync connects them through the qnames the extractor emits for
+// an interface method call and a returned handler type.
+func TestAsyncBoundaryLinking(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "go.mod"), `module example.com/asyncq
+
+go 1.24
+`)
+
+	writeFile(t, filepath.Join(dir, "queue", "queue.go"), `package queue
+
+type Task struct{ ID int }
+
+type Producer interface {
+	Enqueue(task Task) error
+}
+
+type Consumer struct{}
+
+func (c *Consumer) Read() []Task { return nil }
+
+type MessageProcessor func(task Task) error
+`)
+
+	writeFile(t, filepath.Join(dir, "api", "webhook.go"), `package api
+
+import "example.com/asyncq/queue"
+
+type Handler struct {
+	producer queue.Producer
+}
+
+func (h *Handler) HandleWebhook(id int) error {
+	return h.producer.Enqueue(queue.Task{ID: id})
+}
+`)
+
+	writeFile(t, filepath.Join(dir, "worker", "worker.go"), `package worker
+
+import "example.com/asyncq/queue"
+
+func Plan(task queue.Task) error { return nil }
+
+func runLoop(c *queue.Consumer, process queue.MessageProcessor) {
+	for _, task := range c.Read() {
+		_ = process(task)
+	}
+}
+
+func newProcessor() queue.MessageProcessor {
+	return func(task queue.Task) error {
+		return Plan(task)
+	}
+}
+`)
+
+	res, err := NewGoExtractor().Extract("", dir)
+	if err != nil {
+		t.Fatalf("extract failed: %v", err)
+	}
+
+	linked := extract.LinkAsync(res.Functions, []extract.AsyncBoundary{{
+		Name:    "tasks",
+		Produce: []string{"example.com/asyncq/queue.Producer.Enqueue"},
+		Consume: []string{"example.com/asyncq/queue.Consumer.Read", "example.com/asyncq/queue.MessageProcessor"},
+	}})
+	if linked != 3 {
+		t.Errorf("LinkAsync linked %d functions, want 3", linked)
+	}
+
+	handle := res.Functions["example.com/asyncq/api.Handler.HandleWebhook"]
+	if !slices.Equal(handle.Enqueues, []string{"tasks"}) {
+		t.Errorf("HandleWebhook enqueues %v (calls %v), want [tasks]", handle.Enqueues, handle.Calls)
+	}
+
+	for _, qname := range []string{"example.com/asyncq/worker.runLoop", "example.com/asyncq/worker.newProcessor"} {
+		fn := res.Functions[qname]
+		if !slices.Equal(fn.Dequeues, []string{"tasks"}) {
+			t.Errorf("%s dequeues %v (calls %v, returns %v), want [tasks]", qname, fn.Dequeues, fn.Calls, fn.ReturnQNames)
+		}
+	}
+
+	// The worker side reaches Plan through the processor factory.
+	if calls := res.Functions["example.com/asyncq/worker.newProcessor"].Calls; !slices.Contains(calls, "example.com/asyncq/worker.Plan") {
+		t.Errorf("newProcessor calls = %v, want Plan from the returned closure", calls)
+	}
+}
@@ -1,9 +1,14 @@
 package golang
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"go/ast"
 	"go/token"
 	"go/types"
+	"log/slog"
+	"os"
+	"path"
 	"strings"
 
 	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
@@ -33,6 +38,7 @@ func (v *FileVisitor) Visit(node ast.Node) ast.Visitor {
 				Namespace: namespace,
 				Language:  extract.Go,
 				Imports:   make([]extract.Import, 0),
+				Hash:      fileHash(filename),
 			}
 
 			for _, d := range nd.Decls {
@@ -47,7 +53,7 @@ func (v *FileVisitor) Visit(node ast.Node) ast.Visitor {
 						continue
 					}
 					i := extract.Import{
-						Path: strings.Trim(is.Path.Value, "\""),
+						Path: v.resolveImportPath(is),
 						Doc: extract.Doc{
 							Comment: is.Doc.Text() + is.Comment.Text(),
 							OfQName: is.Path.Value,
@@ -59,8 +65,8 @@ func (v *FileVisitor) Visit(node ast.Node) ast.Visitor {
 					}
 					f.Imports = append(f.Imports, i)
 				}
-				v.Files[f.Namespace.Name+"."+f.Filename] = f
 			}
+			v.Files[f.Namespace.Name+"."+f.Filename] = f
 			return v
 		}
 	default:
@@ -69,3 +75,41 @@ func (v *FileVisitor) Visit(node ast.Node) ast.Visitor {
 		}
 	}
 }
+
+// fileHash returns the hex SHA-256 of the file's contents, or "" if it
+// can't be read.
+func fileHash(filename string) string {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		slog.Warn("unable to hash file", "file", filename, "err", err)
+		return ""
+	}
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveImportPath returns the import path in the same form used for
+// namespace names (the package path), so IMPORTS edges land on the module
+// node of the imported package. The type checker's view wins since it already
+// accounts for vendoring and relative imports; aliases don't change the path.
+func (v *FileVisitor) resolveImportPath(is *ast.ImportSpec) string {
+	if v.Info != nil {
+		if pn := v.Info.PkgNameOf(is); pn != nil && pn.Imported() != nil {
+			return pn.Imported().Path()
+		}
+	}
+	return canonicalImportPath(v.Package, strings.Trim(is.Path.Value, "\"`"))
+}
+
+// canonicalImportPath normalizes a raw import path without type information:
+// relative imports are joined onto the importing package and vendored paths
+// lose their vendor prefix.
+func canonicalImportPath(importer, importPath string) string {
+	if strings.HasPrefix(importPath, "./") || strings.HasPrefix(importPath, "../") {
+		return path.Join(importer, importPath)
+	}
+	if idx := strings.LastIndex(importPath, "/vendor/"); idx >= 0 {
+		return importPath[idx+len("/vendor/"):]
+	}
+	return strings.TrimPrefix(importPath, "vendor/")
+}
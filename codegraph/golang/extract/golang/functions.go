@@ -44,54 +44,30 @@ func (v *FunctionVisitor) Visit(node ast.Node) ast.Visitor {
 
 			if n.Recv != nil {
 				for _, field := range n.Recv.List {
-					if id, ok := field.Type.(*ast.Ident); ok {
-						// Regular method - qname includes type: pkg.Type.Method
-						typeName := id.Name
-						stQName := fnObj.Pkg().Path() + "." + typeName
-						methodQName := stQName + "." + fnObj.Name()
-
-						f := extract.Function{
-							Name:        fnObj.Name(),
-							QName:       methodQName,
-							Namespace:   namespace,
-							ParentQName: stQName,
-							Pos:         pos,
-							End:         end,
-							Filepath:    filepath,
-							Code:        mCode,
-						}
-
-						v.extractParamsAndReturns(n, &f)
-						v.extractDoc(n, &f)
-
-						v.Functions[methodQName] = f
-						qname = methodQName // Update for body visitor
-
-					} else if se, ok := field.Type.(*ast.StarExpr); ok {
-						// Pointer based method - qname includes type: pkg.Type.Method
-						if id, ok := se.X.(*ast.Ident); ok {
-							typeName := id.Name
-							stQName := fnObj.Pkg().Path() + "." + typeName
-							methodQName := stQName + "." + fnObj.Name()
-
-							f := extract.Function{
-								Name:        fnObj.Name(),
-								QName:       methodQName,
-								Namespace:   namespace,
-								ParentQName: stQName,
-								Pos:         pos,
-								End:         end,
-								Filepath:    filepath,
-								Code:        mCode,
-							}
-
-							v.extractParamsAndReturns(n, &f)
-							v.extractDoc(n, &f)
-
-							v.Functions[methodQName] = f
-							qname = methodQName // Update for body visitor
-						}
+					typeName := receiverTypeName(field.Type)
+					if typeName == "" {
+						continue
 					}
+					// Method - qname includes type: pkg.Type.Method
+					stQName := fnObj.Pkg().Path() + "." + typeName
+					methodQName := stQName + "." + fnObj.Name()
+
+					f := extract.Function{
+						Name:        fnObj.Name(),
+						QName:       methodQName,
+						Namespace:   namespace,
+						ParentQName: stQName,
+						Pos:         pos,
+						End:         end,
+						Filepath:    filepath,
+						Code:        mCode,
+					}
+
+					v.extractParamsAndReturns(n, &f)
+					v.extractDoc(n, &f)
+
+					v.Functions[methodQName] = f
+					qname = methodQName // Update for body visitor
 				}
 			} else {
 				// Just a regular function
@@ -177,6 +153,25 @@ func (v *FunctionVisitor) extractParamsAndReturns(n *ast.FuncDecl, f *extract.Fu
 	f.Signature = v.buildSignature(n, f.Name)
 }
 
+// receiverTypeName returns the type name of a method receiver, stripping
+// the pointer and any type parameters: T, *T, T[K], *T[K, V] all give "T".
+// It returns "" for receiver expressions it doesn't recognize.
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	}
+	return ""
+}
+
 // buildSignature creates a human-readable signature from the AST.
 // Examples:
 //   - Function: "NewPlanner(cfg Config, arango Client) *Planner"
@@ -5,6 +5,7 @@ import (
 	"go/token"
 	"go/types"
 	"log/slog"
+	"strconv"
 	"strings"
 
 	extract "github.com/humanbeeng/lepo/prototypes/codegraph/extract"
@@ -168,6 +169,7 @@ func (v *TypeVisitor) handleFieldNode(field *ast.Field, parentQName string) erro
 			TypeQName:   fieldObj.Type().String(),
 			Namespace:   namespace,
 			ParentQName: parentQName,
+			Tag:         fieldTag(field),
 			Pos:         v.Fset.Position(field.Pos()).Line,
 			End:         v.Fset.Position(field.End()).Line,
 			Filepath:    v.Fset.Position(field.Pos()).Filename,
@@ -180,6 +182,19 @@ func (v *TypeVisitor) handleFieldNode(field *ast.Field, parentQName string) erro
 	return nil
 }
 
+// fieldTag returns the field's struct tag with the surrounding quotes
+// removed, or "" if it has none.
+func fieldTag(field *ast.Field) string {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return field.Tag.Value
+	}
+	return tag
+}
+
 func (v *TypeVisitor) handleNonStructTypeSpec(nd *ast.GenDecl, tSpec *ast.TypeSpec, pos token.Pos, end token.Pos) {
 	if tSpec == nil {
 		return
@@ -0,0 +1,319 @@
+package python
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+// extractScript runs under python3 and does the extraction with the
+// standard library ast module, so the project's dependencies don't need to
+// be installed.
+//
+//go:embed extract.py
+var extractScript string
+
+// projectMarkers are the files that make a directory its own Python project.
+var projectMarkers = []string{"pyproject.toml", "setup.py", "setup.cfg"}
+
+type PyExtractor struct {
+	scope  extract.Scope
+	python string // python3 binary
+}
+
+func NewPyExtractor() *PyExtractor {
+	return &PyExtractor{python: "python3"}
+}
+
+// WithScope restricts extraction to files allowed by scope. Paths are
+// matched relative to the directory passed to Extract.
+func (x *PyExtractor) WithScope(scope extract.Scope) *PyExtractor {
+	x.scope = scope
+	return x
+}
+
+// Extract indexes the Python code under dir, which must be an import root
+// (the directory that would be on sys.path, e.g. a project's src/). Qnames
+// are dotted import paths: app/billing/invoice.py is namespace
+// "app.billing.invoice", and method Invoice.total in it
+// "app.billing.invoice.Invoice.total". pkgstr, if set, is the import path
+// of dir itself and prefixes every namespace.
+func (x *PyExtractor) Extract(pkgstr string, dir string) (extract.ExtractNodesResult, error) {
+	start := time.Now()
+	slog.Info("Python extraction requested for", "package", pkgstr, "dir", dir)
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return extract.ExtractNodesResult{}, fmt.Errorf("resolve dir: %w", err)
+	}
+	files, err := x.sourceFiles(absDir)
+	if err != nil {
+		return extract.ExtractNodesResult{}, err
+	}
+	if len(files) == 0 {
+		slog.Info("No Python files found", "dir", dir)
+		return newResult(), nil
+	}
+
+	output, err := x.runScript(absDir, pkgstr, files)
+	if err != nil {
+		return extract.ExtractNodesResult{}, err
+	}
+
+	var out scriptOutput
+	if err := json.Unmarshal(output, &out); err != nil {
+		return extract.ExtractNodesResult{}, fmt.Errorf("decode extractor output: %w", err)
+	}
+	res := out.result()
+
+	slog.Info("Python extraction completed",
+		"dir", dir,
+		"files", len(res.Files),
+		"functions", len(res.Functions),
+		"duration", time.Since(start))
+	return res, nil
+}
+
+func (x *PyExtractor) runScript(dir, pkgstr string, files []string) ([]byte, error) {
+	script, err := os.CreateTemp("", "codegraph-py-*.py")
+	if err != nil {
+		return nil, fmt.Errorf("create extractor script: %w", err)
+	}
+	defer os.Remove(script.Name())
+	if _, err := script.WriteString(extractScript); err != nil {
+		script.Close()
+		return nil, fmt.Errorf("write extractor script: %w", err)
+	}
+	if err := script.Close(); err != nil {
+		return nil, fmt.Errorf("write extractor script: %w", err)
+	}
+
+	input, err := json.Marshal(map[string]any{"dir": dir, "pkg": pkgstr, "files": files})
+	if err != nil {
+		return nil, fmt.Errorf("encode extractor input: %w", err)
+	}
+
+	// -B keeps the run from leaving __pycache__ behind.
+	cmd := exec.Command(x.python, "-B", script.Name())
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("run %s: %w: %s", x.python, err, msg)
+		}
+		return nil, fmt.Errorf("run %s: %w", x.python, err)
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		slog.Warn("python extractor reported problems", "dir", dir, "stderr", msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// sourceFiles lists the in-scope .py files under dir, skipping hidden
+// directories, __pycache__, virtualenvs (directories with a pyvenv.cfg),
+// site-packages, node_modules and nested projects.
+func (x *PyExtractor) sourceFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path == dir {
+				return nil
+			}
+			if name == "__pycache__" || name == "site-packages" || name == "node_modules" || (strings.HasPrefix(name, ".") && len(name) > 1) {
+				return fs.SkipDir
+			}
+			if _, statErr := os.Stat(filepath.Join(path, "pyvenv.cfg")); statErr == nil {
+				return fs.SkipDir
+			}
+			if IsProjectDir(path) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(d.Name(), ".py") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if !x.scope.Allows(filepath.ToSlash(rel)) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list python files: %w", err)
+	}
+	return files, nil
+}
+
+// IsProjectDir reports whether dir holds a pyproject.toml, setup.py or
+// setup.cfg.
+func IsProjectDir(dir string) bool {
+	for _, marker := range projectMarkers {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// scriptOutput is what extract.py writes to stdout.
+type scriptOutput struct {
+	Files []struct {
+		File      string `json:"file"`
+		Namespace string `json:"namespace"`
+		Hash      string `json:"hash"`
+		Imports   []struct {
+			Name string `json:"name"`
+			Path string `json:"path"`
+		} `json:"imports"`
+	} `json:"files"`
+	Functions []struct {
+		Name      string   `json:"name"`
+		QName     string   `json:"qname"`
+		Namespace string   `json:"namespace"`
+		Parent    string   `json:"parent"`
+		File      string   `json:"file"`
+		Pos       int      `json:"pos"`
+		End       int      `json:"end"`
+		Signature string   `json:"signature"`
+		Doc       string   `json:"doc"`
+		Calls     []string `json:"calls"`
+	} `json:"functions"`
+	Classes    []scriptType `json:"classes"`
+	Interfaces []scriptType `json:"interfaces"`
+	Members    []struct {
+		Name      string `json:"name"`
+		QName     string `json:"qname"`
+		Namespace string `json:"namespace"`
+		Parent    string `json:"parent"`
+		Type      string `json:"type"`
+		File      string `json:"file"`
+		Pos       int    `json:"pos"`
+		End       int    `json:"end"`
+		Doc       string `json:"doc"`
+	} `json:"members"`
+}
+
+type scriptType struct {
+	Name       string   `json:"name"`
+	QName      string   `json:"qname"`
+	Namespace  string   `json:"namespace"`
+	File       string   `json:"file"`
+	Pos        int      `json:"pos"`
+	End        int      `json:"end"`
+	Doc        string   `json:"doc"`
+	Implements []string `json:"implements"`
+}
+
+func newResult() extract.ExtractNodesResult {
+	return extract.ExtractNodesResult{
+		TypeDecls:  make(map[string]extract.TypeDecl),
+		Interfaces: make(map[string]extract.TypeDecl),
+		NamedTypes: make(map[string]extract.Named),
+		Members:    make(map[string]extract.Member),
+		Functions:  make(map[string]extract.Function),
+		Files:      make(map[string]extract.File),
+		Vars:       make(map[string]extract.Variable),
+	}
+}
+
+// result converts the script output into the shape the Go extractor
+// produces. Classes are TypeDecls of kind class, except ABCs and Protocols,
+// which are interfaces; a module is its own namespace.
+func (o scriptOutput) result() extract.ExtractNodesResult {
+	res := newResult()
+
+	seen := make(map[string]bool)
+	for _, f := range o.Files {
+		ns := extract.Namespace{Name: f.Namespace}
+		file := extract.File{
+			Filename:  f.File,
+			Namespace: ns,
+			Language:  extract.Python,
+			Imports:   make([]extract.Import, 0, len(f.Imports)),
+			Hash:      f.Hash,
+		}
+		for _, imp := range f.Imports {
+			file.Imports = append(file.Imports, extract.Import{Name: imp.Name, Path: imp.Path})
+		}
+		res.Files[ns.Name+"."+f.File] = file
+		if !seen[ns.Name] {
+			seen[ns.Name] = true
+			res.Namespaces = append(res.Namespaces, ns)
+		}
+	}
+
+	for _, fn := range o.Functions {
+		res.Functions[fn.QName] = extract.Function{
+			Name:        fn.Name,
+			QName:       fn.QName,
+			Namespace:   extract.Namespace{Name: fn.Namespace},
+			ParentQName: fn.Parent,
+			Calls:       fn.Calls,
+			Doc:         extract.Doc{Comment: fn.Doc, OfQName: fn.QName},
+			Pos:         fn.Pos,
+			End:         fn.End,
+			Filepath:    fn.File,
+			Signature:   fn.Signature,
+		}
+	}
+
+	for _, c := range o.Classes {
+		res.TypeDecls[c.QName] = c.typeDecl(extract.Class)
+	}
+	for _, i := range o.Interfaces {
+		res.Interfaces[i.QName] = i.typeDecl(extract.Interface)
+	}
+
+	for _, m := range o.Members {
+		res.Members[m.QName] = extract.Member{
+			Name:        m.Name,
+			QName:       m.QName,
+			Namespace:   extract.Namespace{Name: m.Namespace},
+			TypeQName:   m.Type,
+			ParentQName: m.Parent,
+			Doc:         extract.Doc{Comment: m.Doc, OfQName: m.QName},
+			Pos:         m.Pos,
+			End:         m.End,
+			Filepath:    m.File,
+		}
+	}
+
+	return res
+}
+
+func (t scriptType) typeDecl(kind extract.Kind) extract.TypeDecl {
+	return extract.TypeDecl{
+		Name:            t.Name,
+		QName:           t.QName,
+		ImplementsQName: t.Implements,
+		Doc:             extract.Doc{Comment: t.Doc, OfQName: t.QName},
+		Kind:            kind,
+		Pos:             t.Pos,
+		End:             t.End,
+		Filepath:        t.File,
+		Namespace:       extract.Namespace{Name: t.Namespace},
+	}
+}
@@ -0,0 +1,452 @@
+# Extracts functions, methods, classes, interfaces and call edges from a
+# Python project with the standard library ast module.
+#
+# Input (stdin):  {"dir": "/abs/root", "pkg": "", "files": ["/abs/root/app/a.py", ...]}
+# Output (stdout): {"files": [...], "functions": [...], "classes": [...], "interfaces": [...], "members": [...]}
+#
+# dir is an import root (a directory on sys.path), so a file's module is its
+# dotted path below dir: app/billing/invoice.py is app.billing.invoice and
+# app/billing/__init__.py is app.billing. pkg, if set, is prepended.
+#
+# Calls are resolved statically: module-level names, imports (absolute and
+# relative, including re-exports through __init__), self/cls/super(),
+# ClassName.method, and locals or attributes whose class is known from an
+# annotation or a constructor call. Anything else is left out rather than
+# guessed.
+
+import ast
+import hashlib
+import json
+import os
+import sys
+
+INTERFACE_BASES = {
+    "abc.ABC",
+    "typing.Protocol",
+    "typing_extensions.Protocol",
+}
+INTERFACE_METACLASSES = {"abc.ABCMeta"}
+MAX_RESOLVE_DEPTH = 8
+
+
+def module_of(root, pkg, filename):
+    rel = os.path.relpath(filename, root)
+    parts = rel[: -len(".py")].split(os.sep)
+    if parts[-1] == "__init__":
+        parts = parts[:-1]
+    if pkg:
+        parts = pkg.split(".") + parts
+    return ".".join(parts)
+
+
+def unparse(node):
+    return ast.unparse(node) if node is not None else ""
+
+
+def start_line(node):
+    lines = [node.lineno] + [d.lineno for d in getattr(node, "decorator_list", [])]
+    return min(lines)
+
+
+def dotted(node):
+    """Returns "a.b.c" for a Name/Attribute chain, or None."""
+    parts = []
+    while isinstance(node, ast.Attribute):
+        parts.append(node.attr)
+        node = node.value
+    if isinstance(node, ast.Name):
+        parts.append(node.id)
+        return ".".join(reversed(parts))
+    return None
+
+
+class Module:
+    def __init__(self, name, filename, tree, is_package):
+        self.name = name
+        self.filename = filename
+        self.tree = tree
+        self.is_package = is_package
+        self.bindings = {}  # local name -> dotted target (module, class, function or re-export)
+        self.imports = []
+
+    def package(self):
+        if self.is_package:
+            return self.name
+        return self.name.rpartition(".")[0]
+
+    def absolute(self, node):
+        """Resolves the module named by an ImportFrom, relative or not."""
+        if node.level == 0:
+            return node.module or ""
+        base = self.package().split(".") if self.package() else []
+        if node.level > 1:
+            base = base[: len(base) - (node.level - 1)]
+        if node.module:
+            base = base + node.module.split(".")
+        return ".".join(base)
+
+
+class Project:
+    def __init__(self, root, pkg, files):
+        self.modules = {}
+        self.functions = {}  # qname -> function record
+        self.classes = {}  # qname -> {"node", "module", "bases"}
+        for filename in files:
+            with open(filename, "rb") as f:
+                data = f.read()
+            try:
+                tree = ast.parse(data, filename=filename)
+            except SyntaxError as err:
+                print(f"skipping {filename}: {err}", file=sys.stderr)
+                continue
+            name = module_of(root, pkg, filename)
+            mod = Module(name, filename, tree, os.path.basename(filename) == "__init__.py")
+            mod.hash = hashlib.sha256(data).hexdigest()
+            self.modules[name] = mod
+
+        for mod in self.modules.values():
+            self.collect_bindings(mod)
+        for mod in self.modules.values():
+            self.collect_definitions(mod, mod.tree.body, mod.name)
+
+    # -- pass 1: what each module-level name refers to --
+
+    def collect_bindings(self, mod):
+        for stmt in mod.tree.body:
+            if isinstance(stmt, ast.Import):
+                for alias in stmt.names:
+                    if alias.asname:
+                        mod.bindings[alias.asname] = alias.name
+                        mod.imports.append({"name": alias.asname, "path": alias.name})
+                    else:
+                        top = alias.name.split(".")[0]
+                        mod.bindings[top] = top
+                        mod.imports.append({"name": "", "path": alias.name})
+            elif isinstance(stmt, ast.ImportFrom):
+                source = mod.absolute(stmt)
+                mod.imports.append({"name": "", "path": source})
+                for alias in stmt.names:
+                    if alias.name == "*":
+                        continue
+                    target = f"{source}.{alias.name}" if source else alias.name
+                    mod.bindings[alias.asname or alias.name] = target
+            elif isinstance(stmt, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+                mod.bindings[stmt.name] = f"{mod.name}.{stmt.name}"
+
+    def collect_definitions(self, mod, body, prefix, parent=None):
+        for stmt in body:
+            if isinstance(stmt, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                qname = f"{prefix}.{stmt.name}"
+                self.functions[qname] = {"node": stmt, "module": mod, "parent": parent}
+            elif isinstance(stmt, ast.ClassDef):
+                qname = f"{prefix}.{stmt.name}"
+                self.classes[qname] = {"node": stmt, "module": mod, "bases": []}
+                self.collect_definitions(mod, stmt.body, qname, parent=qname)
+
+    # -- name resolution --
+
+    def resolve(self, target, depth=0):
+        """Follows re-exports until target names a project function, class or
+        module, or returns None if it leaves the project."""
+        if target in self.functions or target in self.classes or target in self.modules:
+            return target
+        if depth >= MAX_RESOLVE_DEPTH:
+            return None
+        parts = target.split(".")
+        for i in range(len(parts) - 1, 0, -1):
+            prefix = ".".join(parts[:i])
+            rest = parts[i:]
+            if prefix in self.classes:
+                method = self.lookup_method(prefix, rest[0])
+                return method if method and len(rest) == 1 else None
+            if prefix in self.modules:
+                binding = self.modules[prefix].bindings.get(rest[0])
+                if binding is None or binding == target:
+                    return None
+                return self.resolve(".".join([binding] + rest[1:]), depth + 1)
+        return None
+
+    def resolve_in(self, mod, name):
+        """Resolves a dotted expression as written in module mod."""
+        head, _, rest = name.partition(".")
+        binding = mod.bindings.get(head)
+        if binding is None:
+            return None
+        return self.resolve(f"{binding}.{rest}" if rest else binding)
+
+    def lookup_method(self, class_qname, name, seen=None):
+        """Finds name on the class or its project bases, depth-first in base order."""
+        seen = seen or set()
+        if class_qname in seen or class_qname not in self.classes:
+            return None
+        seen.add(class_qname)
+        qname = f"{class_qname}.{name}"
+        if qname in self.functions:
+            return qname
+        for base in self.classes[class_qname]["bases"]:
+            found = self.lookup_method(base, name, seen)
+            if found:
+                return found
+        return None
+
+    def class_of(self, mod, annotation):
+        """Returns the project class an annotation names, if any."""
+        if annotation is None:
+            return None
+        if isinstance(annotation, ast.Constant) and isinstance(annotation.value, str):
+            try:
+                annotation = ast.parse(annotation.value, mode="eval").body
+            except SyntaxError:
+                return None
+        if isinstance(annotation, ast.Subscript):
+            # Optional[X] and friends
+            inner = annotation.slice
+            return self.class_of(mod, inner) if not isinstance(inner, ast.Tuple) else None
+        if isinstance(annotation, ast.BinOp):
+            # X | None
+            return self.class_of(mod, annotation.left) or self.class_of(mod, annotation.right)
+        name = dotted(annotation)
+        resolved = self.resolve_in(mod, name) if name else None
+        return resolved if resolved in self.classes else None
+
+    def constructed_class(self, mod, value):
+        if isinstance(value, ast.Call):
+            name = dotted(value.func)
+            resolved = self.resolve_in(mod, name) if name else None
+            if resolved in self.classes:
+                return resolved
+        return None
+
+    # -- pass 2: classes --
+
+    def resolve_bases(self):
+        for qname, cls in self.classes.items():
+            mod = cls["module"]
+            for base in cls["node"].bases:
+                name = dotted(base.value if isinstance(base, ast.Subscript) else base)
+                resolved = self.resolve_in(mod, name) if name else None
+                if resolved in self.classes:
+                    cls["bases"].append(resolved)
+
+    def is_interface(self, qname):
+        cls = self.classes[qname]
+        mod = cls["module"]
+        for base in cls["node"].bases:
+            name = dotted(base.value if isinstance(base, ast.Subscript) else base)
+            if name and self.external_name(mod, name) in INTERFACE_BASES:
+                return True
+        for kw in cls["node"].keywords:
+            if kw.arg == "metaclass":
+                name = dotted(kw.value)
+                if name and self.external_name(mod, name) in INTERFACE_METACLASSES:
+                    return True
+        return False
+
+    def external_name(self, mod, name):
+        head, _, rest = name.partition(".")
+        binding = mod.bindings.get(head, head)
+        return f"{binding}.{rest}" if rest else binding
+
+    def implements(self, qname):
+        """Interfaces among the class's project ancestors."""
+        found = []
+        stack = list(self.classes[qname]["bases"])
+        seen = set()
+        while stack:
+            base = stack.pop(0)
+            if base in seen:
+                continue
+            seen.add(base)
+            if self.is_interface(base):
+                found.append(base)
+            stack.extend(self.classes[base]["bases"])
+        return found
+
+    def attribute_types(self, class_qname):
+        """Maps self attributes to project classes, from class-level
+        annotations and assignments in the class's methods."""
+        cls = self.classes[class_qname]
+        mod = cls["module"]
+        types = {}
+        for stmt in cls["node"].body:
+            if isinstance(stmt, ast.AnnAssign) and isinstance(stmt.target, ast.Name):
+                t = self.class_of(mod, stmt.annotation)
+                if t:
+                    types[stmt.target.id] = t
+        for stmt in cls["node"].body:
+            if not isinstance(stmt, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                continue
+            params = self.param_types(mod, stmt)
+            for node in ast.walk(stmt):
+                targets, value, annotation = [], None, None
+                if isinstance(node, ast.Assign):
+                    targets, value = node.targets, node.value
+                elif isinstance(node, ast.AnnAssign):
+                    targets, value, annotation = [node.target], node.value, node.annotation
+                for target in targets:
+                    if not (isinstance(target, ast.Attribute) and isinstance(target.value, ast.Name) and target.value.id == "self"):
+                        continue
+                    t = self.class_of(mod, annotation) or self.constructed_class(mod, value)
+                    if t is None and isinstance(value, ast.Name):
+                        t = params.get(value.id)
+                    if t and target.attr not in types:
+                        types[target.attr] = t
+        return types
+
+    def param_types(self, mod, fn):
+        types = {}
+        args = fn.args
+        for arg in args.posonlyargs + args.args + args.kwonlyargs:
+            t = self.class_of(mod, arg.annotation)
+            if t:
+                types[arg.arg] = t
+        return types
+
+    # -- pass 3: calls --
+
+    def calls_of(self, qname, attr_types):
+        fn = self.functions[qname]
+        node, mod, parent = fn["node"], fn["module"], fn["parent"]
+        local_types = self.param_types(mod, node)
+        for stmt in ast.walk(node):
+            if isinstance(stmt, ast.Assign) and len(stmt.targets) == 1 and isinstance(stmt.targets[0], ast.Name):
+                t = self.constructed_class(mod, stmt.value)
+                if t:
+                    local_types.setdefault(stmt.targets[0].id, t)
+            elif isinstance(stmt, ast.AnnAssign) and isinstance(stmt.target, ast.Name):
+                t = self.class_of(mod, stmt.annotation)
+                if t:
+                    local_types.setdefault(stmt.target.id, t)
+
+        receiver = None
+        static = any(dotted(d) == "staticmethod" for d in node.decorator_list)
+        if parent and not static and (node.args.posonlyargs or node.args.args):
+            receiver = (node.args.posonlyargs + node.args.args)[0].arg
+
+        calls = []
+        seen = set()
+
+        def add(target):
+            if target and target not in seen:
+                seen.add(target)
+                calls.append(target)
+
+        for call in ast.walk(node):
+            if not isinstance(call, ast.Call):
+                continue
+            func = call.func
+            target = None
+            if isinstance(func, ast.Attribute) and isinstance(func.value, ast.Call) and dotted(func.value.func) == "super" and parent:
+                for base in self.classes[parent]["bases"]:
+                    target = self.lookup_method(base, func.attr)
+                    if target:
+                        break
+            elif isinstance(func, ast.Attribute) and isinstance(func.value, ast.Name) and func.value.id == receiver:
+                target = self.lookup_method(parent, func.attr)
+            elif (
+                isinstance(func, ast.Attribute)
+                and isinstance(func.value, ast.Attribute)
+                and isinstance(func.value.value, ast.Name)
+                and func.value.value.id == receiver
+                and func.value.attr in attr_types
+            ):
+                target = self.lookup_method(attr_types[func.value.attr], func.attr)
+            elif isinstance(func, ast.Attribute) and isinstance(func.value, ast.Name) and func.value.id in local_types:
+                target = self.lookup_method(local_types[func.value.id], func.attr)
+            else:
+                name = dotted(func)
+                target = self.resolve_in(mod, name) if name else None
+                if target in self.classes:
+                    target = self.lookup_method(target, "__init__")
+                elif target in self.modules:
+                    target = None
+            add(target)
+        return calls
+
+
+def signature(name, fn):
+    ret = f" -> {unparse(fn.returns)}" if fn.returns is not None else ""
+    prefix = "async " if isinstance(fn, ast.AsyncFunctionDef) else ""
+    return f"{prefix}{name}({unparse(fn.args)}){ret}"
+
+
+def main():
+    data = json.load(sys.stdin)
+    project = Project(data["dir"], data.get("pkg", ""), data["files"])
+    project.resolve_bases()
+
+    out = {"files": [], "functions": [], "classes": [], "interfaces": [], "members": []}
+
+    for mod in project.modules.values():
+        out["files"].append({"file": mod.filename, "namespace": mod.name, "hash": mod.hash, "imports": mod.imports})
+
+    attr_types = {qname: project.attribute_types(qname) for qname in project.classes}
+
+    for qname, fn in project.functions.items():
+        node, mod, parent = fn["node"], fn["module"], fn["parent"]
+        display = f"{parent.rpartition('.')[2]}.{node.name}" if parent else node.name
+        out["functions"].append({
+            "name": node.name,
+            "qname": qname,
+            "namespace": mod.name,
+            "parent": parent or "",
+            "file": mod.filename,
+            "pos": start_line(node),
+            "end": node.end_lineno,
+            "signature": signature(display, node),
+            "doc": ast.get_docstring(node) or "",
+            "calls": project.calls_of(qname, attr_types.get(parent, {})),
+        })
+
+    for qname, cls in project.classes.items():
+        node, mod = cls["node"], cls["module"]
+        interface = project.is_interface(qname)
+        out["interfaces" if interface else "classes"].append({
+            "name": node.name,
+            "qname": qname,
+            "namespace": mod.name,
+            "file": mod.filename,
+            "pos": start_line(node),
+            "end": node.end_lineno,
+            "doc": ast.get_docstring(node) or "",
+            "implements": [] if interface else project.implements(qname),
+        })
+
+        members = {}
+        for stmt in node.body:
+            if isinstance(stmt, ast.AnnAssign) and isinstance(stmt.target, ast.Name):
+                members.setdefault(stmt.target.id, (stmt, unparse(stmt.annotation)))
+            elif isinstance(stmt, ast.Assign):
+                for target in stmt.targets:
+                    if isinstance(target, ast.Name):
+                        members.setdefault(target.id, (stmt, ""))
+        init = next((s for s in node.body if isinstance(s, ast.FunctionDef) and s.name == "__init__"), None)
+        if init is not None:
+            for stmt in ast.walk(init):
+                targets, annotation = [], ""
+                if isinstance(stmt, ast.Assign):
+                    targets = stmt.targets
+                elif isinstance(stmt, ast.AnnAssign):
+                    targets, annotation = [stmt.target], unparse(stmt.annotation)
+                for target in targets:
+                    if isinstance(target, ast.Attribute) and isinstance(target.value, ast.Name) and target.value.id == "self":
+                        members.setdefault(target.attr, (stmt, annotation))
+        for name, (stmt, annotation) in members.items():
+            if f"{qname}.{name}" in project.functions:
+                continue
+            out["members"].append({
+                "name": name,
+                "qname": f"{qname}.{name}",
+                "namespace": mod.name,
+                "parent": qname,
+                "type": annotation,
+                "file": mod.filename,
+                "pos": stmt.lineno,
+                "end": stmt.end_lineno,
+                "doc": "",
+            })
+
+    json.dump(out, sys.stdout)
+
+
+main()
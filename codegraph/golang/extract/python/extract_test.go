@@ -0,0 +1,309 @@
+package python
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+func writeFile(t *testing.T, filename, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatalf("mkdir failed for %s: %v", filename, err)
+	}
+	if err := os.WriteFile(filename, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write file failed for %s: %v", filename, err)
+	}
+}
+
+func requirePython(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not installed")
+	}
+}
+
+// extractDir writes files (path -> contents) under a temp dir and runs the
+// extractor on it.
+func extractDir(t *testing.T, files map[string]string) extract.ExtractNodesResult {
+	t.Helper()
+	requirePython(t)
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		writeFile(t, filepath.Join(dir, name), contents)
+	}
+	res, err := NewPyExtractor().Extract("", dir)
+	if err != nil {
+		t.Fatalf("extract failed: %v", err)
+	}
+	return res
+}
+
+func TestExtractScriptParses(t *testing.T) {
+	requirePython(t)
+
+	script := filepath.Join(t.TempDir(), "extract.py")
+	writeFile(t, script, extractScript)
+
+	code := "import ast, sys; ast.parse(open(sys.argv[1]).read())"
+	if out, err := exec.Command("python3", "-c", code, script).CombinedOutput(); err != nil {
+		t.Fatalf("extract.py does not parse: %v\n%s", err, out)
+	}
+}
+
+func TestSourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"app/__init__.py",
+		"app/models.py",
+		"app/gen/api_pb2.py",
+		"app/__pycache__/models.cpython-312.py",
+		"app/README.md",
+		".venv/lib/site.py",
+		"env/lib/os.py",
+		"plugins/extra/mod.py",
+	} {
+		writeFile(t, filepath.Join(dir, name), "\n")
+	}
+	writeFile(t, filepath.Join(dir, "env", "pyvenv.cfg"), "home = /usr/bin\n")
+	writeFile(t, filepath.Join(dir, "plugins", "extra", "pyproject.toml"), "[project]\nname = \"extra\"\n")
+
+	files, err := NewPyExtractor().WithScope(extract.Scope{Exclude: []string{"app/gen/"}}).sourceFiles(dir)
+	if err != nil {
+		t.Fatalf("sourceFiles failed: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "app", "__init__.py"), filepath.Join(dir, "app", "models.py")}
+	if !slices.Equal(files, want) {
+		t.Errorf("sourceFiles = %v, want %v", files, want)
+	}
+}
+
+func TestCallGraphExtraction(t *testing.T) {
+	res := extractDir(t, map[string]string{
+		"app/__init__.py": "",
+		"app/util.py": `def helper(x):
+    """Doubles x."""
+    return x * 2
+
+
+def compute(x):
+    return helper(x) + 1
+
+
+async def run():
+    def inner():
+        return compute(3)
+    return inner()
+`,
+	})
+
+	compute, ok := res.Functions["app.util.compute"]
+	if !ok {
+		t.Fatalf("missing function app.util.compute, functions: %v", res.Functions)
+	}
+	if !slices.Equal(compute.Calls, []string{"app.util.helper"}) {
+		t.Errorf("compute calls = %v, want [app.util.helper]", compute.Calls)
+	}
+	if compute.Namespace.Name != "app.util" || compute.ParentQName != "" {
+		t.Errorf("compute = %+v", compute)
+	}
+	if compute.Pos != 6 || compute.End != 7 {
+		t.Errorf("compute lines = %d-%d, want 6-7", compute.Pos, compute.End)
+	}
+
+	helper := res.Functions["app.util.helper"]
+	if helper.Doc.Comment != "Doubles x." || helper.Signature != "helper(x)" {
+		t.Errorf("helper doc = %q, signature = %q", helper.Doc.Comment, helper.Signature)
+	}
+
+	run := res.Functions["app.util.run"]
+	if !slices.Equal(run.Calls, []string{"app.util.compute"}) {
+		t.Errorf("run calls = %v, want calls from nested functions attributed to run", run.Calls)
+	}
+	if run.Signature != "async run()" {
+		t.Errorf("run signature = %q", run.Signature)
+	}
+	if _, ok := res.Functions["app.util.run.inner"]; ok {
+		t.Error("nested function should not be extracted on its own")
+	}
+
+	file, ok := res.Files["app.util."+compute.Filepath]
+	if !ok || file.Language != extract.Python || file.Hash == "" {
+		t.Errorf("file = %+v (found %v)", file, ok)
+	}
+}
+
+func TestMethodParentRelationship(t *testing.T) {
+	res := extractDir(t, map[string]string{
+		"shop/cart.py": `class Cart:
+    """A shopping cart."""
+
+    currency: str = "EUR"
+
+    def __init__(self):
+        self.items = []
+
+    def add(self, item):
+        self.items.append(item)
+        self._touch()
+
+    def _touch(self):
+        pass
+
+    @staticmethod
+    def empty():
+        return Cart()
+
+
+class Basket(Cart):
+    def add(self, item):
+        super().add(item)
+        self._touch()
+`,
+	})
+
+	cart, ok := res.TypeDecls["shop.cart.Cart"]
+	if !ok || cart.Kind != extract.Class || cart.Doc.Comment != "A shopping cart." {
+		t.Fatalf("class Cart = %+v (found %v)", cart, ok)
+	}
+
+	add, ok := res.Functions["shop.cart.Cart.add"]
+	if !ok {
+		t.Fatalf("missing method Cart.add, functions: %v", res.Functions)
+	}
+	if add.ParentQName != "shop.cart.Cart" || add.Signature != "Cart.add(self, item)" {
+		t.Errorf("Cart.add = %+v", add)
+	}
+	if !slices.Equal(add.Calls, []string{"shop.cart.Cart._touch"}) {
+		t.Errorf("Cart.add calls = %v, want [shop.cart.Cart._touch]", add.Calls)
+	}
+	if empty := res.Functions["shop.cart.Cart.empty"]; !slices.Equal(empty.Calls, []string{"shop.cart.Cart.__init__"}) {
+		t.Errorf("Cart.empty calls = %v, want the constructor", empty.Calls)
+	}
+
+	basketAdd := res.Functions["shop.cart.Basket.add"]
+	if basketAdd.ParentQName != "shop.cart.Basket" {
+		t.Errorf("Basket.add parent = %q", basketAdd.ParentQName)
+	}
+	if !slices.Equal(basketAdd.Calls, []string{"shop.cart.Cart.add", "shop.cart.Cart._touch"}) {
+		t.Errorf("Basket.add calls = %v, want super() and inherited methods", basketAdd.Calls)
+	}
+
+	if m, ok := res.Members["shop.cart.Cart.currency"]; !ok || m.ParentQName != "shop.cart.Cart" || m.TypeQName != "str" {
+		t.Errorf("member currency = %+v (found %v)", m, ok)
+	}
+	if _, ok := res.Members["shop.cart.Cart.items"]; !ok {
+		t.Error("missing member Cart.items assigned in __init__")
+	}
+}
+
+func TestCrossModuleCalls(t *testing.T) {
+	res := extractDir(t, map[string]string{
+		"app/__init__.py":          "",
+		"app/db/__init__.py":       "from .conn import connect\n",
+		"app/db/conn.py":           "def connect(url):\n    return url\n",
+		"app/services/__init__.py": "",
+		"app/services/users.py": `import app.db.conn
+from app.db import connect
+from ..db import conn as c
+from .repo import UserRepo
+
+
+def load(url):
+    connect(url)
+    c.connect(url)
+    app.db.conn.connect(url)
+
+
+def save(repo: UserRepo, user):
+    repo.insert(user)
+
+
+def save_new(user):
+    fresh = UserRepo()
+    fresh.insert(user)
+`,
+		"app/services/repo.py": `class UserRepo:
+    def insert(self, user):
+        pass
+`,
+	})
+
+	load := res.Functions["app.services.users.load"]
+	if !slices.Equal(load.Calls, []string{"app.db.conn.connect"}) {
+		t.Errorf("load calls = %v, want the re-exported, aliased and dotted forms to resolve to app.db.conn.connect", load.Calls)
+	}
+
+	save := res.Functions["app.services.users.save"]
+	if !slices.Equal(save.Calls, []string{"app.services.repo.UserRepo.insert"}) {
+		t.Errorf("save calls = %v, want UserRepo.insert via the parameter annotation", save.Calls)
+	}
+	// UserRepo has no __init__, so constructing it adds no edge.
+	if saveNew := res.Functions["app.services.users.save_new"]; !slices.Equal(saveNew.Calls, []string{"app.services.repo.UserRepo.insert"}) {
+		t.Errorf("save_new calls = %v, want UserRepo.insert via the constructed local", saveNew.Calls)
+	}
+
+	file := res.Files["app.services.users."+save.Filepath]
+	var paths []string
+	for _, imp := range file.Imports {
+		paths = append(paths, imp.Path)
+	}
+	if !slices.Contains(paths, "app.db") || !slices.Contains(paths, "app.services.repo") {
+		t.Errorf("imports = %v, want relative imports made absolute", paths)
+	}
+}
+
+func TestInterfaceImplementation(t *testing.T) {
+	res := extractDir(t, map[string]string{
+		"store/base.py": `from abc import ABC, abstractmethod
+from typing import Protocol
+
+
+class Store(ABC):
+    @abstractmethod
+    def get(self, key): ...
+
+
+class Closer(Protocol):
+    def close(self) -> None: ...
+`,
+		"store/memory.py": `from store.base import Store
+
+
+class MemoryStore(Store):
+    def get(self, key):
+        return None
+
+    def close(self) -> None:
+        pass
+
+
+class CachedStore(MemoryStore):
+    pass
+`,
+	})
+
+	for _, qname := range []string{"store.base.Store", "store.base.Closer"} {
+		if iface, ok := res.Interfaces[qname]; !ok || iface.Kind != extract.Interface {
+			t.Errorf("interface %s = %+v (found %v)", qname, iface, ok)
+		}
+	}
+	if get, ok := res.Functions["store.base.Store.get"]; !ok || get.Pos != 6 {
+		t.Errorf("abstract method Store.get = %+v (found %v), want Pos at the decorator", get, ok)
+	}
+
+	memory, ok := res.TypeDecls["store.memory.MemoryStore"]
+	if !ok || !slices.Equal(memory.ImplementsQName, []string{"store.base.Store"}) {
+		t.Errorf("MemoryStore = %+v (found %v)", memory, ok)
+	}
+	cached := res.TypeDecls["store.memory.CachedStore"]
+	if !slices.Equal(cached.ImplementsQName, []string{"store.base.Store"}) {
+		t.Errorf("CachedStore implements = %v, want the interface inherited through MemoryStore", cached.ImplementsQName)
+	}
+}
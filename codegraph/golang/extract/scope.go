@@ -0,0 +1,95 @@
+package extract
+
+import (
+	"path"
+	"strings"
+)
+
+// Scope limits which source files are indexed. Patterns are matched against
+// slash-separated paths relative to the module root:
+//   - a pattern without "/" matches the file's base name ("*_gen.go")
+//   - a pattern ending in "/" matches everything under that directory at any
+//     depth ("vendor/", "testdata/")
+//   - "**" matches any number of path segments ("internal/**/mock_*.go")
+//
+// Exclude wins over Include. An empty Include matches every file.
+type Scope struct {
+	Include []string
+	Exclude []string
+}
+
+// Allows reports whether relPath is in scope.
+func (s Scope) Allows(relPath string) bool {
+	relPath = strings.TrimPrefix(path.Clean(strings.ReplaceAll(relPath, "\\", "/")), "./")
+
+	for _, pattern := range s.Exclude {
+		if matchScopePattern(pattern, relPath) {
+			return false
+		}
+	}
+
+	if len(s.Include) == 0 {
+		return true
+	}
+	for _, pattern := range s.Include {
+		if matchScopePattern(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZero reports whether the scope has no patterns and therefore allows everything.
+func (s Scope) IsZero() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+func matchScopePattern(pattern, relPath string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return false
+	}
+
+	if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+		segments := strings.Split(relPath, "/")
+		for i := range segments[:len(segments)-1] {
+			if matchSegments(strings.Split(dir, "/"), segments[i:len(segments)-1], true) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(relPath))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(relPath, "/"), false)
+}
+
+// matchSegments matches glob segments against path segments, with "**"
+// spanning zero or more segments. If prefix is set, the pattern only has to
+// match a leading run of segments.
+func matchSegments(pattern, segments []string, prefix bool) bool {
+	if len(pattern) == 0 {
+		return prefix || len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:], prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:], prefix)
+}
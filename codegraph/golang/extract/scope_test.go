@@ -0,0 +1,33 @@
+package extract
+
+import "testing"
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope Scope
+		path  string
+		want  bool
+	}{
+		{name: "empty scope", scope: Scope{}, path: "a/b.go", want: true},
+		{name: "base name glob", scope: Scope{Exclude: []string{"*_gen.go"}}, path: "api/types_gen.go", want: false},
+		{name: "base name glob miss", scope: Scope{Exclude: []string{"*_gen.go"}}, path: "api/types.go", want: true},
+		{name: "top-level dir", scope: Scope{Exclude: []string{"vendor/"}}, path: "vendor/x/y.go", want: false},
+		{name: "nested dir", scope: Scope{Exclude: []string{"testdata/"}}, path: "pkg/testdata/fixture.go", want: false},
+		{name: "dir name is not a file", scope: Scope{Exclude: []string{"vendor/"}}, path: "vendor.go", want: true},
+		{name: "double star", scope: Scope{Exclude: []string{"internal/**/mock_*.go"}}, path: "internal/a/b/mock_store.go", want: false},
+		{name: "double star zero segments", scope: Scope{Exclude: []string{"internal/**/mock_*.go"}}, path: "internal/mock_store.go", want: false},
+		{name: "include miss", scope: Scope{Include: []string{"cmd/**"}}, path: "internal/a.go", want: false},
+		{name: "include hit", scope: Scope{Include: []string{"cmd/**"}}, path: "cmd/app/main.go", want: true},
+		{name: "exclude wins", scope: Scope{Include: []string{"cmd/**"}, Exclude: []string{"*_test.go"}}, path: "cmd/app/main_test.go", want: false},
+		{name: "leading dot slash", scope: Scope{Exclude: []string{"gen/"}}, path: "./gen/a.go", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.Allows(tt.path); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
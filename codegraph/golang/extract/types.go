@@ -9,6 +9,7 @@ const (
 	Java       string = "java"
 	JavaScript string = "javascript"
 	TypeScript string = "typescript"
+	Python     string = "python"
 )
 
 type ExtractNodesResult struct {
@@ -39,6 +40,7 @@ type Kind string
 const (
 	Interface Kind = "interface"
 	Struct    Kind = "struct"
+	Class     Kind = "class"
 	Alias     Kind = "alias"
 )
 
@@ -106,6 +108,8 @@ type File struct {
 	Namespace Namespace
 	Imports   []Import
 	Language  string
+	// Hash is the hex SHA-256 of the file's contents.
+	Hash string
 }
 
 type Namespace struct {
@@ -118,6 +122,7 @@ type Member struct {
 	Namespace   Namespace
 	TypeQName   string
 	ParentQName string
+	Tag         string // Struct field tag without quotes, e.g. json:"email"
 	Code        string
 	Doc         Doc
 	Pos         int
@@ -138,7 +143,9 @@ type Function struct {
 	Filepath     string
 	ReturnQNames []string
 	ParamQNames  []string
-	Signature    string // Human-readable signature, e.g., "(p *Planner) Plan(ctx context.Context, issue Issue) ([]Action, error)"
+	Signature    string   // Human-readable signature, e.g., "(p *Planner) Plan(ctx context.Context, issue Issue) ([]Action, error)"
+	Enqueues     []string // Async boundaries this function puts work on (see LinkAsync)
+	Dequeues     []string // Async boundaries this function takes work off
 }
 
 type DocType byte
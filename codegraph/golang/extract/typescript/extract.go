@@ -0,0 +1,296 @@
+package typescript
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+// extractScript runs under node and does the extraction with the TypeScript
+// compiler API, which it loads from the project's node_modules.
+//
+//go:embed extract.js
+var extractScript string
+
+type TSExtractor struct {
+	scope extract.Scope
+	node  string // node binary
+}
+
+func NewTSExtractor() *TSExtractor {
+	return &TSExtractor{node: "node"}
+}
+
+// WithScope restricts extraction to files allowed by scope. Paths are
+// matched relative to the directory passed to Extract.
+func (x *TSExtractor) WithScope(scope extract.Scope) *TSExtractor {
+	x.scope = scope
+	return x
+}
+
+// Extract indexes the TypeScript project rooted at dir. pkgstr prefixes the
+// namespace of every file: src/api/client.ts in project "web" becomes
+// namespace "web/src/api/client", and its exported function fetchUser
+// "web/src/api/client.fetchUser".
+func (x *TSExtractor) Extract(pkgstr string, dir string) (extract.ExtractNodesResult, error) {
+	start := time.Now()
+	slog.Info("TypeScript extraction requested for", "project", pkgstr, "dir", dir)
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return extract.ExtractNodesResult{}, fmt.Errorf("resolve dir: %w", err)
+	}
+	files, err := x.sourceFiles(absDir)
+	if err != nil {
+		return extract.ExtractNodesResult{}, err
+	}
+	if len(files) == 0 {
+		slog.Info("No TypeScript files found", "project", pkgstr)
+		return newResult(), nil
+	}
+
+	output, err := x.runScript(absDir, pkgstr, files)
+	if err != nil {
+		return extract.ExtractNodesResult{}, err
+	}
+
+	var out scriptOutput
+	if err := json.Unmarshal(output, &out); err != nil {
+		return extract.ExtractNodesResult{}, fmt.Errorf("decode extractor output: %w", err)
+	}
+	res := out.result()
+
+	slog.Info("TypeScript extraction completed",
+		"project", pkgstr,
+		"files", len(res.Files),
+		"functions", len(res.Functions),
+		"duration", time.Since(start))
+	return res, nil
+}
+
+func (x *TSExtractor) runScript(dir, pkgstr string, files []string) ([]byte, error) {
+	script, err := os.CreateTemp("", "codegraph-ts-*.js")
+	if err != nil {
+		return nil, fmt.Errorf("create extractor script: %w", err)
+	}
+	defer os.Remove(script.Name())
+	if _, err := script.WriteString(extractScript); err != nil {
+		script.Close()
+		return nil, fmt.Errorf("write extractor script: %w", err)
+	}
+	if err := script.Close(); err != nil {
+		return nil, fmt.Errorf("write extractor script: %w", err)
+	}
+
+	input, err := json.Marshal(map[string]any{"dir": dir, "pkg": pkgstr, "files": files})
+	if err != nil {
+		return nil, fmt.Errorf("encode extractor input: %w", err)
+	}
+
+	cmd := exec.Command(x.node, script.Name())
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("run %s: %w: %s", x.node, err, msg)
+		}
+		return nil, fmt.Errorf("run %s: %w", x.node, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// sourceFiles lists the in-scope .ts/.tsx files under dir, skipping
+// declaration files, node_modules, hidden directories and nested projects
+// (directories with their own tsconfig.json).
+func (x *TSExtractor) sourceFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path == dir {
+				return nil
+			}
+			if name == "node_modules" || (strings.HasPrefix(name, ".") && len(name) > 1) {
+				return fs.SkipDir
+			}
+			if _, statErr := os.Stat(filepath.Join(path, "tsconfig.json")); statErr == nil {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		name := d.Name()
+		if !(strings.HasSuffix(name, ".ts") || strings.HasSuffix(name, ".tsx")) || strings.HasSuffix(name, ".d.ts") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if !x.scope.Allows(filepath.ToSlash(rel)) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list typescript files: %w", err)
+	}
+	return files, nil
+}
+
+// scriptOutput is what extract.js writes to stdout.
+type scriptOutput struct {
+	Files []struct {
+		File      string `json:"file"`
+		Namespace string `json:"namespace"`
+		Hash      string `json:"hash"`
+		Imports   []struct {
+			Name string `json:"name"`
+			Path string `json:"path"`
+		} `json:"imports"`
+	} `json:"files"`
+	Functions []struct {
+		Name      string   `json:"name"`
+		QName     string   `json:"qname"`
+		Namespace string   `json:"namespace"`
+		Parent    string   `json:"parent"`
+		File      string   `json:"file"`
+		Pos       int      `json:"pos"`
+		End       int      `json:"end"`
+		Signature string   `json:"signature"`
+		Doc       string   `json:"doc"`
+		Calls     []string `json:"calls"`
+	} `json:"functions"`
+	Classes    []scriptType `json:"classes"`
+	Interfaces []scriptType `json:"interfaces"`
+	Members    []struct {
+		Name      string `json:"name"`
+		QName     string `json:"qname"`
+		Namespace string `json:"namespace"`
+		Parent    string `json:"parent"`
+		Type      string `json:"type"`
+		File      string `json:"file"`
+		Pos       int    `json:"pos"`
+		End       int    `json:"end"`
+		Doc       string `json:"doc"`
+	} `json:"members"`
+}
+
+type scriptType struct {
+	Name       string   `json:"name"`
+	QName      string   `json:"qname"`
+	Namespace  string   `json:"namespace"`
+	File       string   `json:"file"`
+	Pos        int      `json:"pos"`
+	End        int      `json:"end"`
+	Doc        string   `json:"doc"`
+	Implements []string `json:"implements"`
+}
+
+func newResult() extract.ExtractNodesResult {
+	return extract.ExtractNodesResult{
+		TypeDecls:  make(map[string]extract.TypeDecl),
+		Interfaces: make(map[string]extract.TypeDecl),
+		NamedTypes: make(map[string]extract.Named),
+		Members:    make(map[string]extract.Member),
+		Functions:  make(map[string]extract.Function),
+		Files:      make(map[string]extract.File),
+		Vars:       make(map[string]extract.Variable),
+	}
+}
+
+// result converts the script output into the shape the Go extractor
+// produces. Classes are TypeDecls of kind class; a file is its own
+// namespace, since TypeScript modules are files.
+func (o scriptOutput) result() extract.ExtractNodesResult {
+	res := newResult()
+
+	seen := make(map[string]bool)
+	for _, f := range o.Files {
+		ns := extract.Namespace{Name: f.Namespace}
+		file := extract.File{
+			Filename:  f.File,
+			Namespace: ns,
+			Language:  extract.TypeScript,
+			Imports:   make([]extract.Import, 0, len(f.Imports)),
+			Hash:      f.Hash,
+		}
+		for _, imp := range f.Imports {
+			file.Imports = append(file.Imports, extract.Import{Name: imp.Name, Path: imp.Path})
+		}
+		res.Files[ns.Name+"."+f.File] = file
+		if !seen[ns.Name] {
+			seen[ns.Name] = true
+			res.Namespaces = append(res.Namespaces, ns)
+		}
+	}
+
+	for _, fn := range o.Functions {
+		res.Functions[fn.QName] = extract.Function{
+			Name:        fn.Name,
+			QName:       fn.QName,
+			Namespace:   extract.Namespace{Name: fn.Namespace},
+			ParentQName: fn.Parent,
+			Calls:       fn.Calls,
+			Doc:         extract.Doc{Comment: fn.Doc, OfQName: fn.QName},
+			Pos:         fn.Pos,
+			End:         fn.End,
+			Filepath:    fn.File,
+			Signature:   fn.Signature,
+		}
+	}
+
+	for _, c := range o.Classes {
+		res.TypeDecls[c.QName] = c.typeDecl(extract.Class)
+	}
+	for _, i := range o.Interfaces {
+		res.Interfaces[i.QName] = i.typeDecl(extract.Interface)
+	}
+
+	for _, m := range o.Members {
+		res.Members[m.QName] = extract.Member{
+			Name:        m.Name,
+			QName:       m.QName,
+			Namespace:   extract.Namespace{Name: m.Namespace},
+			TypeQName:   m.Type,
+			ParentQName: m.Parent,
+			Doc:         extract.Doc{Comment: m.Doc, OfQName: m.QName},
+			Pos:         m.Pos,
+			End:         m.End,
+			Filepath:    m.File,
+		}
+	}
+
+	return res
+}
+
+func (t scriptType) typeDecl(kind extract.Kind) extract.TypeDecl {
+	return extract.TypeDecl{
+		Name:            t.Name,
+		QName:           t.QName,
+		ImplementsQName: t.Implements,
+		Doc:             extract.Doc{Comment: t.Doc, OfQName: t.QName},
+		Kind:            kind,
+		Pos:             t.Pos,
+		End:             t.End,
+		Filepath:        t.File,
+		Namespace:       extract.Namespace{Name: t.Namespace},
+	}
+}
@@ -0,0 +1,342 @@
+'use strict';
+
+// Extracts functions, methods, classes, interfaces and call edges from a
+// TypeScript project with the TypeScript compiler API.
+//
+// Input (stdin):  {"dir": "/abs/project", "pkg": "web", "files": ["/abs/project/src/a.ts", ...]}
+// Output (stdout): {"files": [...], "functions": [...], "classes": [...], "interfaces": [...], "members": [...]}
+//
+// The typescript package is resolved from the project first, then NODE_PATH.
+
+const crypto = require('crypto');
+const fs = require('fs');
+const path = require('path');
+
+function loadTypeScript(dir) {
+  try {
+    return require(require.resolve('typescript', { paths: [dir] }));
+  } catch (err) {
+    try {
+      return require('typescript');
+    } catch (err2) {
+      process.stderr.write(`typescript package not found from ${dir}; install it in the project (npm i -D typescript) or set NODE_PATH\n`);
+      process.exit(3);
+    }
+  }
+}
+
+const input = JSON.parse(fs.readFileSync(0, 'utf8'));
+const ts = loadTypeScript(input.dir);
+
+function compilerOptions(dir) {
+  const defaults = { noEmit: true, target: ts.ScriptTarget.Latest, jsx: ts.JsxEmit.Preserve, allowJs: false };
+  const configPath = ts.findConfigFile(dir, ts.sys.fileExists, 'tsconfig.json');
+  if (!configPath) {
+    return defaults;
+  }
+  const read = ts.readConfigFile(configPath, ts.sys.readFile);
+  if (read.error) {
+    return defaults;
+  }
+  const parsed = ts.parseJsonConfigFileContent(read.config, ts.sys, path.dirname(configPath));
+  return Object.assign({}, parsed.options, { noEmit: true });
+}
+
+const options = compilerOptions(input.dir);
+const projectFiles = new Set(input.files.map((f) => path.resolve(f)));
+const program = ts.createProgram(input.files, options);
+const checker = program.getTypeChecker();
+
+function isProjectFile(sf) {
+  return projectFiles.has(path.resolve(sf.fileName));
+}
+
+// namespaceOf maps a file to its module path: pkg + "/" + path without the
+// extension, e.g. "web/src/api/client".
+function namespaceOf(fileName) {
+  const rel = path.relative(input.dir, path.resolve(fileName)).split(path.sep).join('/');
+  const mod = rel.replace(/\.(d\.)?tsx?$/, '');
+  return input.pkg ? `${input.pkg}/${mod}` : mod;
+}
+
+function line(sf, pos) {
+  return sf.getLineAndCharacterOfPosition(pos).line + 1;
+}
+
+function nameText(name) {
+  if (ts.isIdentifier(name) || ts.isPrivateIdentifier(name) || ts.isStringLiteral(name) || ts.isNumericLiteral(name)) {
+    return name.text;
+  }
+  return name.getText();
+}
+
+function isFunctionValue(node) {
+  return !!node && (ts.isArrowFunction(node) || ts.isFunctionExpression(node));
+}
+
+function isTopLevelVariable(decl) {
+  const list = decl.parent;
+  const stmt = list && list.parent;
+  return !!stmt && ts.isVariableStatement(stmt) && ts.isSourceFile(stmt.parent);
+}
+
+// qnameOf returns the qname a declaration is extracted under, or "" if it
+// isn't one the extractor records (or lives outside the project).
+function qnameOf(decl) {
+  const sf = decl.getSourceFile();
+  if (!isProjectFile(sf)) {
+    return '';
+  }
+  const ns = namespaceOf(sf.fileName);
+
+  if (ts.isFunctionDeclaration(decl) || ts.isClassDeclaration(decl) || ts.isInterfaceDeclaration(decl)) {
+    return decl.name ? `${ns}.${decl.name.text}` : '';
+  }
+  if (ts.isVariableDeclaration(decl)) {
+    return ts.isIdentifier(decl.name) && isTopLevelVariable(decl) ? `${ns}.${decl.name.text}` : '';
+  }
+  if (ts.isConstructorDeclaration(decl)) {
+    const owner = decl.parent;
+    return ts.isClassDeclaration(owner) && owner.name ? `${ns}.${owner.name.text}.constructor` : '';
+  }
+  const isMember = ts.isMethodDeclaration(decl) || ts.isPropertyDeclaration(decl) ||
+    ts.isGetAccessorDeclaration(decl) || ts.isSetAccessorDeclaration(decl) ||
+    ts.isMethodSignature(decl) || ts.isPropertySignature(decl);
+  if (isMember && decl.name) {
+    const owner = decl.parent;
+    if ((ts.isClassDeclaration(owner) || ts.isInterfaceDeclaration(owner)) && owner.name) {
+      return `${ns}.${owner.name.text}.${nameText(decl.name)}`;
+    }
+  }
+  return '';
+}
+
+function resolveSymbol(node) {
+  let symbol = checker.getSymbolAtLocation(node);
+  if (symbol && (symbol.flags & ts.SymbolFlags.Alias)) {
+    symbol = checker.getAliasedSymbol(symbol);
+  }
+  return symbol;
+}
+
+function declarationOf(symbol) {
+  if (!symbol) {
+    return undefined;
+  }
+  return symbol.valueDeclaration || (symbol.declarations && symbol.declarations[0]);
+}
+
+function docOf(nameNode) {
+  const symbol = nameNode && checker.getSymbolAtLocation(nameNode);
+  return symbol ? ts.displayPartsToString(symbol.getDocumentationComment(checker)) : '';
+}
+
+// collectCalls returns the qnames of the project functions called anywhere
+// in body, nested callbacks included.
+function collectCalls(body) {
+  const calls = [];
+  const seen = new Set();
+  const add = (qname) => {
+    if (qname && !seen.has(qname)) {
+      seen.add(qname);
+      calls.push(qname);
+    }
+  };
+
+  const visit = (node) => {
+    if (ts.isCallExpression(node)) {
+      let target = node.expression;
+      if (ts.isPropertyAccessExpression(target)) {
+        target = target.name;
+      }
+      const decl = declarationOf(resolveSymbol(target));
+      if (decl) {
+        add(qnameOf(decl));
+      }
+    } else if (ts.isNewExpression(node)) {
+      const decl = declarationOf(resolveSymbol(node.expression));
+      if (decl && ts.isClassDeclaration(decl) && decl.members.some(ts.isConstructorDeclaration)) {
+        const cls = qnameOf(decl);
+        if (cls) {
+          add(`${cls}.constructor`);
+        }
+      }
+    }
+    ts.forEachChild(node, visit);
+  };
+  if (body) {
+    visit(body);
+  }
+  return calls;
+}
+
+function signatureOf(name, fn) {
+  const typeParams = fn.typeParameters ? `<${fn.typeParameters.map((t) => t.getText()).join(', ')}>` : '';
+  const params = fn.parameters.map((p) => p.getText()).join(', ');
+  const ret = fn.type ? `: ${fn.type.getText()}` : '';
+  return `${name}${typeParams}(${params})${ret}`;
+}
+
+const out = { files: [], functions: [], classes: [], interfaces: [], members: [] };
+
+function addFunction(sf, ns, decl, fn, name, qname, parent, parentName) {
+  out.functions.push({
+    name,
+    qname,
+    namespace: ns,
+    parent,
+    file: sf.fileName,
+    pos: line(sf, decl.getStart(sf)),
+    end: line(sf, decl.getEnd()),
+    signature: signatureOf(parentName ? `${parentName}.${name}` : name, fn),
+    doc: docOf(decl.name),
+    calls: collectCalls(fn.body),
+  });
+}
+
+function addMember(sf, ns, decl, parent) {
+  const name = nameText(decl.name);
+  out.members.push({
+    name,
+    qname: `${parent}.${name}`,
+    namespace: ns,
+    parent,
+    type: decl.type ? decl.type.getText() : '',
+    file: sf.fileName,
+    pos: line(sf, decl.getStart(sf)),
+    end: line(sf, decl.getEnd()),
+    doc: docOf(decl.name),
+  });
+}
+
+function heritageQNames(node, token) {
+  const qnames = [];
+  for (const clause of node.heritageClauses || []) {
+    if (clause.token !== token) {
+      continue;
+    }
+    for (const t of clause.types) {
+      const decl = declarationOf(resolveSymbol(t.expression));
+      const qname = decl ? qnameOf(decl) : '';
+      if (qname) {
+        qnames.push(qname);
+      }
+    }
+  }
+  return qnames;
+}
+
+function visitClass(sf, ns, node) {
+  const className = node.name.text;
+  const qname = `${ns}.${className}`;
+  out.classes.push({
+    name: className,
+    qname,
+    namespace: ns,
+    file: sf.fileName,
+    pos: line(sf, node.getStart(sf)),
+    end: line(sf, node.getEnd()),
+    doc: docOf(node.name),
+    implements: heritageQNames(node, ts.SyntaxKind.ImplementsKeyword),
+  });
+
+  for (const member of node.members) {
+    if (ts.isConstructorDeclaration(member) && member.body) {
+      out.functions.push({
+        name: 'constructor',
+        qname: `${qname}.constructor`,
+        namespace: ns,
+        parent: qname,
+        file: sf.fileName,
+        pos: line(sf, member.getStart(sf)),
+        end: line(sf, member.getEnd()),
+        signature: signatureOf(`${className}.constructor`, member),
+        doc: '',
+        calls: collectCalls(member.body),
+      });
+    } else if ((ts.isMethodDeclaration(member) || ts.isGetAccessorDeclaration(member) || ts.isSetAccessorDeclaration(member)) && member.body && member.name) {
+      const name = nameText(member.name);
+      addFunction(sf, ns, member, member, name, `${qname}.${name}`, qname, className);
+    } else if (ts.isPropertyDeclaration(member) && member.name) {
+      if (isFunctionValue(member.initializer)) {
+        const name = nameText(member.name);
+        addFunction(sf, ns, member, member.initializer, name, `${qname}.${name}`, qname, className);
+      } else {
+        addMember(sf, ns, member, qname);
+      }
+    }
+  }
+}
+
+function visitInterface(sf, ns, node) {
+  const qname = `${ns}.${node.name.text}`;
+  out.interfaces.push({
+    name: node.name.text,
+    qname,
+    namespace: ns,
+    file: sf.fileName,
+    pos: line(sf, node.getStart(sf)),
+    end: line(sf, node.getEnd()),
+    doc: docOf(node.name),
+    implements: [],
+  });
+  for (const member of node.members) {
+    if (ts.isPropertySignature(member) && member.name) {
+      addMember(sf, ns, member, qname);
+    }
+  }
+}
+
+function importsOf(sf) {
+  const imports = [];
+  for (const stmt of sf.statements) {
+    if (!ts.isImportDeclaration(stmt) || !ts.isStringLiteral(stmt.moduleSpecifier)) {
+      continue;
+    }
+    let spec = stmt.moduleSpecifier.text;
+    const resolved = ts.resolveModuleName(spec, sf.fileName, options, ts.sys).resolvedModule;
+    if (resolved && projectFiles.has(path.resolve(resolved.resolvedFileName))) {
+      spec = namespaceOf(resolved.resolvedFileName);
+    }
+    let name = '';
+    const clause = stmt.importClause;
+    if (clause && clause.name) {
+      name = clause.name.text;
+    } else if (clause && clause.namedBindings && ts.isNamespaceImport(clause.namedBindings)) {
+      name = clause.namedBindings.name.text;
+    }
+    imports.push({ name, path: spec });
+  }
+  return imports;
+}
+
+for (const sf of program.getSourceFiles()) {
+  if (sf.isDeclarationFile || !isProjectFile(sf)) {
+    continue;
+  }
+  const ns = namespaceOf(sf.fileName);
+  out.files.push({
+    file: sf.fileName,
+    namespace: ns,
+    hash: crypto.createHash('sha256').update(fs.readFileSync(sf.fileName)).digest('hex'),
+    imports: importsOf(sf),
+  });
+
+  for (const stmt of sf.statements) {
+    if (ts.isFunctionDeclaration(stmt) && stmt.name && stmt.body) {
+      addFunction(sf, ns, stmt, stmt, stmt.name.text, `${ns}.${stmt.name.text}`, '', '');
+    } else if (ts.isVariableStatement(stmt)) {
+      for (const decl of stmt.declarationList.declarations) {
+        if (ts.isIdentifier(decl.name) && isFunctionValue(decl.initializer)) {
+          addFunction(sf, ns, decl, decl.initializer, decl.name.text, `${ns}.${decl.name.text}`, '', '');
+        }
+      }
+    } else if (ts.isClassDeclaration(stmt) && stmt.name) {
+      visitClass(sf, ns, stmt);
+    } else if (ts.isInterfaceDeclaration(stmt)) {
+      visitInterface(sf, ns, stmt);
+    }
+  }
+}
+
+process.stdout.write(JSON.stringify(out));
@@ -0,0 +1,192 @@
+package typescript
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+func writeFile(t *testing.T, filename, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatalf("mkdir failed for %s: %v", filename, err)
+	}
+	if err := os.WriteFile(filename, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write file failed for %s: %v", filename, err)
+	}
+}
+
+func TestExtractScriptParses(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not installed")
+	}
+
+	script := filepath.Join(t.TempDir(), "extract.js")
+	writeFile(t, script, extractScript)
+
+	if out, err := exec.Command("node", "--check", script).CombinedOutput(); err != nil {
+		t.Fatalf("extract.js does not parse: %v\n%s", err, out)
+	}
+}
+
+func TestSourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"src/app.ts",
+		"src/view.tsx",
+		"src/types.d.ts",
+		"src/app.js",
+		"src/gen/api.ts",
+		"node_modules/lib/index.ts",
+		".cache/tmp.ts",
+		"packages/ui/button.ts",
+	} {
+		writeFile(t, filepath.Join(dir, name), "export {};\n")
+	}
+	writeFile(t, filepath.Join(dir, "packages", "ui", "tsconfig.json"), "{}\n")
+
+	files, err := NewTSExtractor().WithScope(extract.Scope{Exclude: []string{"src/gen/"}}).sourceFiles(dir)
+	if err != nil {
+		t.Fatalf("sourceFiles failed: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "src", "app.ts"), filepath.Join(dir, "src", "view.tsx")}
+	if !slices.Equal(files, want) {
+		t.Errorf("sourceFiles = %v, want %v", files, want)
+	}
+}
+
+func TestScriptOutputResult(t *testing.T) {
+	raw := `{
+		"files": [{"file": "/web/src/store.ts", "namespace": "web/src/store", "hash": "abc", "imports": [{"name": "", "path": "web/src/db"}]}],
+		"functions": [
+			{"name": "save", "qname": "web/src/store.UserStore.save", "namespace": "web/src/store", "parent": "web/src/store.UserStore",
+			 "file": "/web/src/store.ts", "pos": 8, "end": 10, "signature": "UserStore.save(u: User): void", "doc": "Persists a user.",
+			 "calls": ["web/src/db.insert"]}
+		],
+		"classes": [{"name": "UserStore", "qname": "web/src/store.UserStore", "namespace": "web/src/store", "file": "/web/src/store.ts",
+			"pos": 5, "end": 11, "doc": "", "implements": ["web/src/store.Store"]}],
+		"interfaces": [{"name": "Store", "qname": "web/src/store.Store", "namespace": "web/src/store", "file": "/web/src/store.ts",
+			"pos": 1, "end": 3, "doc": "", "implements": []}],
+		"members": [{"name": "table", "qname": "web/src/store.UserStore.table", "namespace": "web/src/store", "parent": "web/src/store.UserStore",
+			"type": "string", "file": "/web/src/store.ts", "pos": 6, "end": 6, "doc": ""}]
+	}`
+
+	var out scriptOutput
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	res := out.result()
+
+	save, ok := res.Functions["web/src/store.UserStore.save"]
+	if !ok {
+		t.Fatalf("missing method, functions: %v", res.Functions)
+	}
+	if save.ParentQName != "web/src/store.UserStore" || !slices.Equal(save.Calls, []string{"web/src/db.insert"}) {
+		t.Errorf("method = %+v", save)
+	}
+	if save.Doc.Comment != "Persists a user." {
+		t.Errorf("method doc = %q", save.Doc.Comment)
+	}
+
+	class, ok := res.TypeDecls["web/src/store.UserStore"]
+	if !ok || class.Kind != extract.Class || !slices.Equal(class.ImplementsQName, []string{"web/src/store.Store"}) {
+		t.Errorf("class = %+v (found %v)", class, ok)
+	}
+	if iface, ok := res.Interfaces["web/src/store.Store"]; !ok || iface.Kind != extract.Interface {
+		t.Errorf("interface = %+v (found %v)", iface, ok)
+	}
+	if member := res.Members["web/src/store.UserStore.table"]; member.ParentQName != "web/src/store.UserStore" {
+		t.Errorf("member = %+v", member)
+	}
+
+	file, ok := res.Files["web/src/store./web/src/store.ts"]
+	if !ok || file.Language != extract.TypeScript || file.Hash != "abc" || len(file.Imports) != 1 {
+		t.Errorf("file = %+v (found %v)", file, ok)
+	}
+	if len(res.Namespaces) != 1 || res.Namespaces[0].Name != "web/src/store" {
+		t.Errorf("namespaces = %v", res.Namespaces)
+	}
+}
+
+// TestExtractTypeScriptProject runs the real extractor; it needs node and a
+// resolvable typescript package (e.g. via NODE_PATH).
+func TestExtractTypeScriptProject(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not installed")
+	}
+	dir := t.TempDir()
+	if err := exec.Command("node", "-e", "require.resolve('typescript')").Run(); err != nil {
+		t.Skip("typescript package not resolvable")
+	}
+
+	writeFile(t, filepath.Join(dir, "tsconfig.json"), `{"compilerOptions": {"strict": true, "target": "ES2020", "module": "commonjs"}}`)
+	writeFile(t, filepath.Join(dir, "src", "db.ts"), `export function insert(table: string, row: object): void {}
+`)
+	writeFile(t, filepath.Join(dir, "src", "store.ts"), `import { insert } from "./db";
+
+export interface Store {
+  save(u: User): void;
+}
+
+export interface User {
+  id: string;
+}
+
+/** UserStore persists users. */
+export class UserStore implements Store {
+  table = "users";
+
+  save(u: User): void {
+    insert(this.table, u);
+  }
+}
+
+export const newStore = (): Store => new UserStore();
+
+export function run(): void {
+  newStore().save({ id: "1" });
+}
+`)
+
+	res, err := NewTSExtractor().Extract("web", dir)
+	if err != nil {
+		t.Fatalf("extract failed: %v", err)
+	}
+
+	save, ok := res.Functions["web/src/store.UserStore.save"]
+	if !ok {
+		t.Fatalf("missing method UserStore.save, functions: %v", res.Functions)
+	}
+	if save.ParentQName != "web/src/store.UserStore" {
+		t.Errorf("save ParentQName = %q", save.ParentQName)
+	}
+	if !slices.Contains(save.Calls, "web/src/db.insert") {
+		t.Errorf("save calls = %v, want web/src/db.insert", save.Calls)
+	}
+	if run := res.Functions["web/src/store.run"]; !slices.Contains(run.Calls, "web/src/store.newStore") || !slices.Contains(run.Calls, "web/src/store.Store.save") {
+		t.Errorf("run calls = %v", run.Calls)
+	}
+	if _, ok := res.Functions["web/src/store.newStore"]; !ok {
+		t.Error("missing arrow function newStore")
+	}
+
+	class, ok := res.TypeDecls["web/src/store.UserStore"]
+	if !ok || !slices.Equal(class.ImplementsQName, []string{"web/src/store.Store"}) {
+		t.Errorf("class UserStore = %+v (found %v)", class, ok)
+	}
+	if class.Doc.Comment != "UserStore persists users." {
+		t.Errorf("class doc = %q", class.Doc.Comment)
+	}
+	if _, ok := res.Interfaces["web/src/store.Store"]; !ok {
+		t.Error("missing interface Store")
+	}
+	if _, ok := res.Members["web/src/store.User.id"]; !ok {
+		t.Error("missing interface member User.id")
+	}
+}
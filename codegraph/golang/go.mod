@@ -7,6 +7,7 @@ toolchain go1.24.5
 require (
 	basegraph.co/relay v0.0.0
 	github.com/joho/godotenv v1.5.1
+	golang.org/x/sync v0.18.0
 	golang.org/x/tools v0.38.0
 )
 
@@ -22,7 +23,6 @@ require (
 	github.com/rs/zerolog v1.34.0 // indirect
 	golang.org/x/mod v0.29.0 // indirect
 	golang.org/x/net v0.47.0 // indirect
-	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
 )
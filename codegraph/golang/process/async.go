@@ -0,0 +1,58 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+// defaultAsyncBoundaries is relay's task queue: the webhook and API side
+// enqueue through queue.Producer, and the worker reads the stream and runs
+// the queue.MessageProcessor built in cmd/worker.
+var defaultAsyncBoundaries = []extract.AsyncBoundary{{
+	Name:    "relay-tasks",
+	Produce: []string{"basegraph.co/relay/internal/queue.Producer.Enqueue"},
+	Consume: []string{
+		"basegraph.co/relay/internal/queue.RedisConsumer.Read",
+		"basegraph.co/relay/internal/queue.MessageProcessor",
+	},
+}}
+
+// asyncBoundariesFromEnv returns the async boundaries to link. Unset
+// CODEGRAPH_ASYNC_BOUNDARIES uses defaultAsyncBoundaries, "none" disables
+// linking, and anything else is the path of a JSON file holding a list of
+// {"name", "produce", "consume"} objects.
+func asyncBoundariesFromEnv() ([]extract.AsyncBoundary, error) {
+	val := strings.TrimSpace(os.Getenv("CODEGRAPH_ASYNC_BOUNDARIES"))
+	switch val {
+	case "":
+		return defaultAsyncBoundaries, nil
+	case "none":
+		return nil, nil
+	}
+	return loadAsyncBoundaries(val)
+}
+
+func loadAsyncBoundaries(path string) ([]extract.AsyncBoundary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read async boundaries: %w", err)
+	}
+
+	var boundaries []extract.AsyncBoundary
+	if err := json.Unmarshal(data, &boundaries); err != nil {
+		return nil, fmt.Errorf("parse async boundaries %s: %w", path, err)
+	}
+	for i, b := range boundaries {
+		if strings.TrimSpace(b.Name) == "" {
+			return nil, fmt.Errorf("async boundary %d in %s has no name", i, path)
+		}
+		if len(b.Produce) == 0 && len(b.Consume) == 0 {
+			return nil, fmt.Errorf("async boundary %q in %s has no produce or consume symbols", b.Name, path)
+		}
+	}
+	return boundaries, nil
+}
@@ -0,0 +1,80 @@
+package process
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+func TestLoadAsyncBoundaries(t *testing.T) {
+	dir := t.TempDir()
+
+	good := filepath.Join(dir, "async.json")
+	if err := os.WriteFile(good, []byte(`[{"name": "jobs", "produce": ["app/jobs.Client.Publish"], "consume": ["app/jobs.Handler"]}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	boundaries, err := loadAsyncBoundaries(good)
+	if err != nil {
+		t.Fatalf("loadAsyncBoundaries: %v", err)
+	}
+	if len(boundaries) != 1 || boundaries[0].Name != "jobs" || boundaries[0].Produce[0] != "app/jobs.Client.Publish" || boundaries[0].Consume[0] != "app/jobs.Handler" {
+		t.Errorf("boundaries = %+v", boundaries)
+	}
+
+	for name, contents := range map[string]string{
+		"unnamed.json": `[{"produce": ["app/jobs.Client.Publish"]}]`,
+		"empty.json":   `[{"name": "jobs"}]`,
+		"broken.json":  `{"name": "jobs"`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadAsyncBoundaries(path); err == nil {
+			t.Errorf("loadAsyncBoundaries(%s) succeeded, want an error", name)
+		}
+	}
+
+	t.Setenv("CODEGRAPH_ASYNC_BOUNDARIES", "none")
+	if boundaries, err := asyncBoundariesFromEnv(); err != nil || boundaries != nil {
+		t.Errorf("none = %+v, %v; want no boundaries", boundaries, err)
+	}
+	t.Setenv("CODEGRAPH_ASYNC_BOUNDARIES", "")
+	if boundaries, err := asyncBoundariesFromEnv(); err != nil || len(boundaries) != len(defaultAsyncBoundaries) {
+		t.Errorf("unset = %+v, %v; want the defaults", boundaries, err)
+	}
+}
+
+func TestIngestAsyncEdges(t *testing.T) {
+	ns := extract.Namespace{Name: "example.com/app"}
+	res := newExtractAccumulator()
+	res.Functions["example.com/app.HandleWebhook"] = extract.Function{
+		Name: "HandleWebhook", QName: "example.com/app.HandleWebhook", Namespace: ns, Filepath: "webhook.go",
+		Enqueues: []string{"tasks"},
+	}
+	res.Functions["example.com/app.Worker.run"] = extract.Function{
+		Name: "run", QName: "example.com/app.Worker.run", Namespace: ns, Filepath: "worker.go",
+		ParentQName: "example.com/app.Worker", Dequeues: []string{"tasks"},
+	}
+
+	client := newRecordingClient(1)
+	if err := NewIngestor(client).Ingest(context.Background(), res); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	if got := client.nodeCalls["queues"]; got != 1 {
+		t.Errorf("queue node batches = %d, want 1", got)
+	}
+
+	enqueue := client.edges["async_enqueue"]
+	if len(enqueue) != 1 || enqueue[0].From != "example.com/app.HandleWebhook" || enqueue[0].To != "tasks" || enqueue[0].ToKind != "queue" {
+		t.Errorf("async_enqueue edges = %+v", enqueue)
+	}
+	dequeue := client.edges["async_dequeue"]
+	if len(dequeue) != 1 || dequeue[0].From != "tasks" || dequeue[0].To != "example.com/app.Worker.run" || dequeue[0].FromKind != "queue" || dequeue[0].ToKind != "method" {
+		t.Errorf("async_dequeue edges = %+v", dequeue)
+	}
+}
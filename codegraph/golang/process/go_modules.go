@@ -3,6 +3,7 @@ package process
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -11,6 +12,8 @@ import (
 	"strings"
 )
 
+var errNoGoModules = errors.New("no go modules found")
+
 type goModule struct {
 	ModulePath string
 	Dir        string
@@ -64,7 +67,7 @@ func discoverGoModules(root string) ([]goModule, error) {
 	}
 
 	if len(entries) == 0 {
-		return nil, fmt.Errorf("no go modules found under %s", root)
+		return nil, fmt.Errorf("%w under %s", errNoGoModules, root)
 	}
 
 	mods := make([]goModule, 0, len(entries))
@@ -0,0 +1,235 @@
+package process
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+// extractCache is a sidecar store of per-file content hashes and the
+// extraction result they produced, one JSON file per module. Extraction is
+// type-checked per package, so a module is reused only when every one of its
+// files hashes the same as on the last run.
+type extractCache struct {
+	dir string
+}
+
+// scopeHashKey and versionHashKey can't collide with a relative file path.
+const (
+	scopeHashKey   = "\x00scope"
+	versionHashKey = "\x00version"
+)
+
+// extractCacheVersion is recorded with every cached module. Bump it when the
+// extractor or the node schema changes, so results cached by an older build
+// aren't reused.
+const extractCacheVersion = "2"
+
+type cachedModule struct {
+	Hashes map[string]string          `json:"hashes"`
+	Result extract.ExtractNodesResult `json:"result"`
+}
+
+func newExtractCache(dir string) *extractCache {
+	if dir == "" {
+		return nil
+	}
+	return &extractCache{dir: dir}
+}
+
+func (c *extractCache) path(modulePath string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(modulePath)
+	return filepath.Join(c.dir, name+".json")
+}
+
+func (c *extractCache) load(modulePath string) (cachedModule, bool, error) {
+	data, err := os.ReadFile(c.path(modulePath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cachedModule{}, false, nil
+	}
+	if err != nil {
+		return cachedModule{}, false, fmt.Errorf("read hash cache: %w", err)
+	}
+
+	var cm cachedModule
+	if err := json.Unmarshal(data, &cm); err != nil {
+		return cachedModule{}, false, fmt.Errorf("decode hash cache: %w", err)
+	}
+	return cm, true, nil
+}
+
+func (c *extractCache) store(modulePath string, cm cachedModule) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("create hash cache dir: %w", err)
+	}
+
+	data, err := json.Marshal(cm)
+	if err != nil {
+		return fmt.Errorf("encode hash cache: %w", err)
+	}
+
+	tmp := c.path(modulePath) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write hash cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.path(modulePath)); err != nil {
+		return fmt.Errorf("replace hash cache: %w", err)
+	}
+	return nil
+}
+
+// hashModuleFiles hashes every in-scope Go source file owned by the module
+// rooted at dir, keyed by path relative to dir. Nested modules are left to
+// their own entry. go.mod and go.sum are hashed verbatim since dependency
+// changes can alter resolved types, and the scope and extractCacheVersion are
+// recorded so that changing include/exclude patterns or the extractor
+// invalidates the cache.
+func hashModuleFiles(dir string, scope extract.Scope) (map[string]string, error) {
+	hashes := map[string]string{versionHashKey: extractCacheVersion}
+	if !scope.IsZero() {
+		hashes[scopeHashKey] = fmt.Sprintf("include=%q exclude=%q", scope.Include, scope.Exclude)
+	}
+
+	walkFn := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir {
+				if name == "vendor" || name == "testdata" || (strings.HasPrefix(name, ".") && len(name) > 1) {
+					return fs.SkipDir
+				}
+				if _, statErr := os.Stat(filepath.Join(path, "go.mod")); statErr == nil {
+					return fs.SkipDir
+				}
+			}
+			return nil
+		}
+
+		name := d.Name()
+		isGoFile := strings.HasSuffix(name, ".go")
+		isModFile := filepath.Dir(path) == dir && (name == "go.mod" || name == "go.sum")
+		if !isGoFile && !isModFile {
+			return nil
+		}
+
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return fmt.Errorf("relativize %s: %w", path, relErr)
+		}
+		if isGoFile && !scope.Allows(filepath.ToSlash(rel)) {
+			return nil
+		}
+
+		src, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return fmt.Errorf("read %s: %w", path, readErr)
+		}
+
+		if isGoFile {
+			hashes[rel] = semanticHash(src)
+		} else {
+			sum := sha256.Sum256(src)
+			hashes[rel] = hex.EncodeToString(sum[:])
+		}
+		return nil
+	}
+
+	if err := filepath.WalkDir(dir, walkFn); err != nil {
+		return nil, fmt.Errorf("hash module files: %w", err)
+	}
+	return hashes, nil
+}
+
+// semanticHash hashes a Go file's token stream, comments included, ignoring
+// intra-line whitespace. Comments count because doc comments are extracted
+// onto nodes, and line numbers because nodes record line positions; a change
+// to either must re-extract. Files that don't scan cleanly fall back to a raw
+// content hash.
+func semanticHash(src []byte) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	invalid := false
+	s.Init(file, src, func(token.Position, string) { invalid = true }, scanner.ScanComments)
+
+	h := sha256.New()
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		// Automatic semicolons carry "\n" as their literal; normalise so
+		// trailing whitespace before a newline can't matter.
+		switch tok {
+		case token.SEMICOLON:
+			lit = ";"
+		case token.COMMENT:
+			lit = strings.TrimRight(lit, " \t")
+		}
+		fmt.Fprintf(h, "%d\x00%d\x00%s\x00", fset.Position(pos).Line, tok, lit)
+	}
+
+	if invalid {
+		sum := sha256.Sum256(src)
+		return hex.EncodeToString(sum[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sameHashes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// extractModule runs e on mod unless the cache holds a result for identical
+// file hashes. It reports whether extraction was skipped.
+func extractModule(e extract.Extractor, mod goModule, cache *extractCache, scope extract.Scope) (extract.ExtractNodesResult, bool, error) {
+	if cache == nil {
+		res, err := e.Extract(mod.ModulePath, mod.Dir)
+		return res, false, err
+	}
+
+	hashes, err := hashModuleFiles(mod.Dir, scope)
+	if err != nil {
+		return extract.ExtractNodesResult{}, false, err
+	}
+
+	cached, ok, err := cache.load(mod.ModulePath)
+	if err != nil {
+		return extract.ExtractNodesResult{}, false, err
+	}
+	if ok && sameHashes(cached.Hashes, hashes) {
+		return cached.Result, true, nil
+	}
+
+	res, err := e.Extract(mod.ModulePath, mod.Dir)
+	if err != nil {
+		return extract.ExtractNodesResult{}, false, err
+	}
+
+	if err := cache.store(mod.ModulePath, cachedModule{Hashes: hashes, Result: res}); err != nil {
+		return extract.ExtractNodesResult{}, false, err
+	}
+	return res, false, nil
+}
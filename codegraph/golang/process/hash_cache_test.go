@@ -0,0 +1,178 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+type countingExtractor struct {
+	calls int
+}
+
+func (c *countingExtractor) Extract(pkgstr string, dir string) (extract.ExtractNodesResult, error) {
+	c.calls++
+	res := newExtractAccumulator()
+	res.Functions[pkgstr+".Run"] = extract.Function{Name: "Run", QName: pkgstr + ".Run"}
+	return res, nil
+}
+
+func writeSource(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestExtractModuleSkipsUnchangedHashes(t *testing.T) {
+	root := t.TempDir()
+	writeGoMod(t, root, "module example.com/app\n\ngo 1.21\n")
+	writeSource(t, root, "run.go", "package app\n\n// Run runs.\nfunc Run() int {\n\treturn 1\n}\n")
+
+	mod := goModule{ModulePath: "example.com/app", Dir: root}
+	cache := newExtractCache(filepath.Join(t.TempDir(), "hashes"))
+	e := &countingExtractor{}
+
+	if _, skipped, err := extractModule(e, mod, cache, extract.Scope{}); err != nil || skipped {
+		t.Fatalf("first run: skipped=%v err=%v, want extraction", skipped, err)
+	}
+
+	// Only whitespace changes; tokens, comments and lines don't.
+	writeSource(t, root, "run.go", "package app\n\n// Run runs.   \nfunc Run() int {\n\treturn   1\n}\n")
+
+	res, skipped, err := extractModule(e, mod, cache, extract.Scope{})
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if !skipped {
+		t.Fatalf("expected unchanged module to be skipped")
+	}
+	if e.calls != 1 {
+		t.Fatalf("expected 1 extraction, got %d", e.calls)
+	}
+	if _, ok := res.Functions["example.com/app.Run"]; !ok {
+		t.Fatalf("expected cached result to be returned, got %+v", res.Functions)
+	}
+}
+
+func TestExtractModuleReextractsDocCommentEdit(t *testing.T) {
+	root := t.TempDir()
+	writeGoMod(t, root, "module example.com/app\n\ngo 1.21\n")
+	writeSource(t, root, "run.go", "package app\n\n// Run runs.\nfunc Run() int {\n\treturn 1\n}\n")
+
+	mod := goModule{ModulePath: "example.com/app", Dir: root}
+	cache := newExtractCache(filepath.Join(t.TempDir(), "hashes"))
+	e := &countingExtractor{}
+
+	if _, _, err := extractModule(e, mod, cache, extract.Scope{}); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+
+	// Doc comments end up on the extracted nodes, so a comment-only edit
+	// must not serve the old result.
+	writeSource(t, root, "run.go", "package app\n\n// Run runs the app.\nfunc Run() int {\n\treturn 1\n}\n")
+
+	_, skipped, err := extractModule(e, mod, cache, extract.Scope{})
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if skipped || e.calls != 2 {
+		t.Fatalf("expected re-extraction after a doc comment edit, skipped=%v calls=%d", skipped, e.calls)
+	}
+}
+
+func TestExtractModuleIgnoresCacheFromOtherVersion(t *testing.T) {
+	root := t.TempDir()
+	writeGoMod(t, root, "module example.com/app\n\ngo 1.21\n")
+	writeSource(t, root, "run.go", "package app\n")
+
+	mod := goModule{ModulePath: "example.com/app", Dir: root}
+	cache := newExtractCache(filepath.Join(t.TempDir(), "hashes"))
+	e := &countingExtractor{}
+
+	hashes, err := hashModuleFiles(root, extract.Scope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes[versionHashKey] = "0"
+	if err := cache.store(mod.ModulePath, cachedModule{Hashes: hashes, Result: newExtractAccumulator()}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, skipped, err := extractModule(e, mod, cache, extract.Scope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped || e.calls != 1 {
+		t.Fatalf("expected re-extraction for a cache from another version, skipped=%v calls=%d", skipped, e.calls)
+	}
+}
+
+func TestExtractModuleReextractsModifiedFile(t *testing.T) {
+	root := t.TempDir()
+	writeGoMod(t, root, "module example.com/app\n\ngo 1.21\n")
+	writeSource(t, root, "run.go", "package app\n\nfunc Run() int {\n\treturn 1\n}\n")
+
+	mod := goModule{ModulePath: "example.com/app", Dir: root}
+	cache := newExtractCache(filepath.Join(t.TempDir(), "hashes"))
+	e := &countingExtractor{}
+
+	if _, _, err := extractModule(e, mod, cache, extract.Scope{}); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func()
+	}{
+		{
+			name:   "body change",
+			mutate: func() { writeSource(t, root, "run.go", "package app\n\nfunc Run() int {\n\treturn 2\n}\n") },
+		},
+		{
+			name:   "line shift",
+			mutate: func() { writeSource(t, root, "run.go", "package app\n\n\nfunc Run() int {\n\treturn 2\n}\n") },
+		},
+		{
+			name:   "new file",
+			mutate: func() { writeSource(t, root, "extra.go", "package app\n\nfunc Extra() {}\n") },
+		},
+		{
+			name:   "deleted file",
+			mutate: func() { _ = os.Remove(filepath.Join(root, "extra.go")) },
+		},
+	}
+
+	for _, tt := range tests {
+		before := e.calls
+		tt.mutate()
+
+		_, skipped, err := extractModule(e, mod, cache, extract.Scope{})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if skipped || e.calls != before+1 {
+			t.Fatalf("%s: expected re-extraction, skipped=%v calls=%d", tt.name, skipped, e.calls)
+		}
+	}
+}
+
+func TestExtractModuleWithoutCacheAlwaysExtracts(t *testing.T) {
+	root := t.TempDir()
+	writeGoMod(t, root, "module example.com/app\n\ngo 1.21\n")
+	writeSource(t, root, "run.go", "package app\n")
+
+	mod := goModule{ModulePath: "example.com/app", Dir: root}
+	e := &countingExtractor{}
+
+	for i := 0; i < 2; i++ {
+		if _, skipped, err := extractModule(e, mod, nil, extract.Scope{}); err != nil || skipped {
+			t.Fatalf("run %d: skipped=%v err=%v", i, skipped, err)
+		}
+	}
+	if e.calls != 2 {
+		t.Fatalf("expected 2 extractions, got %d", e.calls)
+	}
+}
@@ -0,0 +1,232 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+// IngestIncremental updates the graph in place instead of rebuilding it.
+// Files whose content hash differs from the one stored on their file node
+// are re-ingested, and files the graph has but res doesn't are removed when
+// owns reports them as belonging to the extracted modules. When the graph
+// has no file hashes yet (first run, or built before hashes were recorded)
+// it falls back to a full Ingest.
+func (i *Ingestor) IngestIncremental(ctx context.Context, res extract.ExtractNodesResult, owns func(filename string) bool) error {
+	start := time.Now()
+
+	if err := i.arango.EnsureDatabase(ctx); err != nil {
+		return fmt.Errorf("ensure database: %w", err)
+	}
+	if err := i.arango.EnsureCollections(ctx); err != nil {
+		return fmt.Errorf("ensure collections: %w", err)
+	}
+	if err := i.arango.EnsureGraph(ctx); err != nil {
+		return fmt.Errorf("ensure graph: %w", err)
+	}
+
+	stored, err := i.arango.GetFileHashes(ctx)
+	if err != nil {
+		return fmt.Errorf("get file hashes: %w", err)
+	}
+	if !hasFileHashes(stored) {
+		slog.Info("No file hashes in graph, running full ingestion")
+		return i.Ingest(ctx, res)
+	}
+
+	changed, deleted := diffFileHashes(stored, res.Files, owns)
+	if len(changed) == 0 && len(deleted) == 0 {
+		slog.Info("No files changed since last ingestion")
+		return nil
+	}
+
+	stale := append(append([]string{}, changed...), deleted...)
+	slog.Info("Removing nodes of changed and deleted files",
+		"changed", len(changed),
+		"deleted", len(deleted))
+	if err := i.arango.DeleteFileNodes(ctx, stale); err != nil {
+		return fmt.Errorf("delete file nodes: %w", err)
+	}
+	if len(changed) == 0 {
+		slog.Info("Incremental ingestion completed",
+			"duration_ms", time.Since(start).Milliseconds())
+		return nil
+	}
+
+	subset := incrementalSubset(res, changed)
+	slog.Info("Ingesting changed files",
+		"functions", len(subset.Functions),
+		"types", len(subset.TypeDecls)+len(subset.Interfaces)+len(subset.NamedTypes),
+		"members", len(subset.Members)+len(subset.Vars))
+	if err := i.ingestToArangoDB(ctx, subset); err != nil {
+		return fmt.Errorf("arangodb ingestion: %w", err)
+	}
+
+	slog.Info("Incremental ingestion completed",
+		"duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// hasFileHashes reports whether every stored file node carries a hash.
+func hasFileHashes(stored map[string]string) bool {
+	if len(stored) == 0 {
+		return false
+	}
+	for _, hash := range stored {
+		if hash == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// diffFileHashes returns the extracted files that are new or whose hash
+// changed, and the stored files owned by the extracted modules that are no
+// longer extracted. Both are sorted.
+func diffFileHashes(stored map[string]string, files map[string]extract.File, owns func(string) bool) (changed, deleted []string) {
+	current := make(map[string]bool, len(files))
+	for _, f := range files {
+		if f.Filename == "" {
+			continue
+		}
+		current[f.Filename] = true
+		if f.Hash == "" || stored[f.Filename] != f.Hash {
+			changed = append(changed, f.Filename)
+		}
+	}
+	for filename := range stored {
+		if !current[filename] && owns(filename) {
+			deleted = append(deleted, filename)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	return changed, deleted
+}
+
+// incrementalSubset returns what has to be re-ingested after the nodes of
+// changed were deleted: every node defined in a changed file, plus the nodes
+// elsewhere whose edges touch one of them so those edges are written again.
+// Re-ingesting an existing node or edge is a no-op.
+func incrementalSubset(res extract.ExtractNodesResult, changed []string) extract.ExtractNodesResult {
+	inChanged := make(map[string]bool, len(changed))
+	for _, f := range changed {
+		inChanged[f] = true
+	}
+
+	// Symbols defined in changed files; their edges were deleted with them.
+	touched := make(map[string]bool)
+	for qname, fn := range res.Functions {
+		if inChanged[fn.Filepath] {
+			touched[qname] = true
+		}
+	}
+	for qname, t := range res.TypeDecls {
+		if inChanged[t.Filepath] {
+			touched[qname] = true
+		}
+	}
+	for qname, t := range res.Interfaces {
+		if inChanged[t.Filepath] {
+			touched[qname] = true
+		}
+	}
+	for qname, n := range res.NamedTypes {
+		if inChanged[n.Filepath] {
+			touched[qname] = true
+		}
+	}
+	for qname, m := range res.Members {
+		if inChanged[m.Filepath] {
+			touched[qname] = true
+		}
+	}
+
+	anyTouched := func(qnames []string) bool {
+		for _, q := range qnames {
+			if touched[q] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Types whose implements edges were dropped because a method changed.
+	receivers := make(map[string]bool)
+
+	sub := newExtractAccumulator()
+	sub.Namespaces = res.Namespaces
+	for qname, fn := range res.Functions {
+		if touched[qname] || touched[fn.ParentQName] || anyTouched(fn.Calls) || anyTouched(fn.ReturnQNames) || anyTouched(fn.ParamQNames) {
+			sub.Functions[qname] = fn
+		}
+		if touched[qname] && fn.ParentQName != "" {
+			receivers[fn.ParentQName] = true
+		}
+	}
+	for qname, t := range res.TypeDecls {
+		if touched[qname] || receivers[qname] || anyTouched(t.ImplementsQName) {
+			sub.TypeDecls[qname] = t
+		}
+	}
+	for qname, t := range res.Interfaces {
+		if touched[qname] {
+			sub.Interfaces[qname] = t
+		}
+	}
+	for qname, n := range res.NamedTypes {
+		if touched[qname] {
+			sub.NamedTypes[qname] = n
+		}
+	}
+	for qname, m := range res.Members {
+		if touched[qname] || touched[m.ParentQName] {
+			sub.Members[qname] = m
+		}
+	}
+	for qname, v := range res.Vars {
+		if inChanged[v.Filepath] {
+			sub.Vars[qname] = v
+		}
+	}
+	for key, f := range res.Files {
+		if inChanged[f.Filename] {
+			sub.Files[key] = f
+		}
+	}
+	return sub
+}
+
+// moduleOwner reports whether a file belongs to one of extracted: the
+// module whose directory most closely contains it must have been extracted,
+// so files of nested or filtered-out modules are never treated as deleted.
+func moduleOwner(all, extracted []goModule) func(filename string) bool {
+	selected := make(map[string]bool, len(extracted))
+	for _, mod := range extracted {
+		selected[absDir(mod.Dir)] = true
+	}
+
+	return func(filename string) bool {
+		owner := ""
+		for _, mod := range all {
+			dir := absDir(mod.Dir)
+			if strings.HasPrefix(filename, dir+string(filepath.Separator)) && len(dir) > len(owner) {
+				owner = dir
+			}
+		}
+		return owner != "" && selected[owner]
+	}
+}
+
+func absDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return filepath.Clean(dir)
+}
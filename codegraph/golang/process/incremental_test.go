@@ -0,0 +1,206 @@
+package process
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"basegraph.co/relay/common/arangodb"
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+// incrementalClient serves stored file hashes and records deletes and
+// writes.
+type incrementalClient struct {
+	arangodb.Client
+
+	hashes    map[string]string
+	deleted   []string
+	truncated bool
+	nodes     map[string][]string // collection -> qnames written
+	edges     map[string][]arangodb.Edge
+}
+
+func newIncrementalClient(hashes map[string]string) *incrementalClient {
+	return &incrementalClient{
+		hashes: hashes,
+		nodes:  map[string][]string{},
+		edges:  map[string][]arangodb.Edge{},
+	}
+}
+
+func (c *incrementalClient) EnsureDatabase(ctx context.Context) error    { return nil }
+func (c *incrementalClient) EnsureCollections(ctx context.Context) error { return nil }
+func (c *incrementalClient) EnsureGraph(ctx context.Context) error       { return nil }
+func (c *incrementalClient) TruncateCollections(ctx context.Context) error {
+	c.truncated = true
+	return nil
+}
+
+func (c *incrementalClient) GetFileHashes(ctx context.Context) (map[string]string, error) {
+	return c.hashes, nil
+}
+
+func (c *incrementalClient) DeleteFileNodes(ctx context.Context, filepaths []string) error {
+	c.deleted = append(c.deleted, filepaths...)
+	return nil
+}
+
+func (c *incrementalClient) IngestNodes(ctx context.Context, collection string, nodes []arangodb.Node) error {
+	for _, n := range nodes {
+		c.nodes[collection] = append(c.nodes[collection], n.QName)
+	}
+	sort.Strings(c.nodes[collection])
+	return nil
+}
+
+func (c *incrementalClient) IngestEdges(ctx context.Context, collection string, edges []arangodb.Edge) error {
+	c.edges[collection] = append(c.edges[collection], edges...)
+	return nil
+}
+
+// incrementalResult is a module of three files: main.go calls into store.go,
+// util.go is independent of both.
+func incrementalResult() extract.ExtractNodesResult {
+	ns := extract.Namespace{Name: "example.com/app"}
+	res := newExtractAccumulator()
+	res.Namespaces = []extract.Namespace{ns}
+	res.Functions["example.com/app.Run"] = extract.Function{
+		Name: "Run", QName: "example.com/app.Run", Namespace: ns, Filepath: "/repo/main.go",
+		Calls: []string{"example.com/app.Store.Save"},
+	}
+	res.Functions["example.com/app.Store.Save"] = extract.Function{
+		Name: "Save", QName: "example.com/app.Store.Save", Namespace: ns, Filepath: "/repo/store.go",
+		ParentQName: "example.com/app.Store",
+	}
+	res.Functions["example.com/app.Double"] = extract.Function{
+		Name: "Double", QName: "example.com/app.Double", Namespace: ns, Filepath: "/repo/util.go",
+	}
+	res.TypeDecls["example.com/app.Store"] = extract.TypeDecl{Name: "Store", QName: "example.com/app.Store", Namespace: ns, Filepath: "/repo/store.go"}
+	res.Members["example.com/app.Store.db"] = extract.Member{
+		Name: "db", QName: "example.com/app.Store.db", Namespace: ns, Filepath: "/repo/store.go",
+		ParentQName: "example.com/app.Store",
+	}
+	for _, name := range []string{"main.go", "store.go", "util.go"} {
+		filename := "/repo/" + name
+		res.Files[ns.Name+"."+filename] = extract.File{Filename: filename, Namespace: ns, Hash: name + "-v1"}
+	}
+	return res
+}
+
+func ownsAll(string) bool { return true }
+
+func TestIngestIncrementalReingestsOnlyChangedFile(t *testing.T) {
+	client := newIncrementalClient(map[string]string{
+		"/repo/main.go":  "main.go-v1",
+		"/repo/store.go": "store.go-v0",
+		"/repo/util.go":  "util.go-v1",
+	})
+
+	if err := NewIngestor(client).IngestIncremental(context.Background(), incrementalResult(), ownsAll); err != nil {
+		t.Fatalf("IngestIncremental() error = %v", err)
+	}
+
+	if client.truncated {
+		t.Error("incremental ingestion truncated the graph")
+	}
+	if !slices.Equal(client.deleted, []string{"/repo/store.go"}) {
+		t.Errorf("deleted files = %v, want [/repo/store.go]", client.deleted)
+	}
+
+	// Run is resent only to restore its call edge into store.go.
+	if got := client.nodes["functions"]; !slices.Equal(got, []string{"example.com/app.Run", "example.com/app.Store.Save"}) {
+		t.Errorf("function nodes = %v", got)
+	}
+	if got := client.nodes["types"]; !slices.Equal(got, []string{"example.com/app.Store"}) {
+		t.Errorf("type nodes = %v", got)
+	}
+	if got := client.nodes["members"]; !slices.Equal(got, []string{"example.com/app.Store.db"}) {
+		t.Errorf("member nodes = %v", got)
+	}
+	if got := client.nodes["files"]; !slices.Equal(got, []string{"example.com/app./repo/store.go"}) {
+		t.Errorf("file nodes = %v", got)
+	}
+
+	calls := client.edges["calls"]
+	if len(calls) != 1 || calls[0].From != "example.com/app.Run" || calls[0].To != "example.com/app.Store.Save" {
+		t.Errorf("call edges = %+v", calls)
+	}
+}
+
+func TestIngestIncrementalRemovesDeletedFiles(t *testing.T) {
+	client := newIncrementalClient(map[string]string{
+		"/repo/main.go":   "main.go-v1",
+		"/repo/store.go":  "store.go-v1",
+		"/repo/util.go":   "util.go-v1",
+		"/repo/old.go":    "old.go-v1",
+		"/other/other.go": "other.go-v1",
+	})
+	owns := moduleOwner(
+		[]goModule{{ModulePath: "example.com/app", Dir: "/repo"}, {ModulePath: "example.com/other", Dir: "/other"}},
+		[]goModule{{ModulePath: "example.com/app", Dir: "/repo"}},
+	)
+
+	if err := NewIngestor(client).IngestIncremental(context.Background(), incrementalResult(), owns); err != nil {
+		t.Fatalf("IngestIncremental() error = %v", err)
+	}
+
+	if !slices.Equal(client.deleted, []string{"/repo/old.go"}) {
+		t.Errorf("deleted files = %v, want [/repo/old.go]", client.deleted)
+	}
+	if len(client.nodes) != 0 || len(client.edges) != 0 {
+		t.Errorf("unexpected writes: nodes %v, edges %v", client.nodes, client.edges)
+	}
+}
+
+func TestIngestIncrementalUnchanged(t *testing.T) {
+	client := newIncrementalClient(map[string]string{
+		"/repo/main.go":  "main.go-v1",
+		"/repo/store.go": "store.go-v1",
+		"/repo/util.go":  "util.go-v1",
+	})
+
+	if err := NewIngestor(client).IngestIncremental(context.Background(), incrementalResult(), ownsAll); err != nil {
+		t.Fatalf("IngestIncremental() error = %v", err)
+	}
+
+	if client.truncated || len(client.deleted) != 0 || len(client.nodes) != 0 {
+		t.Errorf("unchanged files were rewritten: truncated=%v deleted=%v nodes=%v", client.truncated, client.deleted, client.nodes)
+	}
+}
+
+func TestIngestIncrementalFallsBackWithoutHashes(t *testing.T) {
+	client := newIncrementalClient(map[string]string{"/repo/main.go": ""})
+
+	if err := NewIngestor(client).IngestIncremental(context.Background(), incrementalResult(), ownsAll); err != nil {
+		t.Fatalf("IngestIncremental() error = %v", err)
+	}
+
+	if !client.truncated {
+		t.Error("expected a full rebuild when the graph has no file hashes")
+	}
+	if got := len(client.nodes["functions"]); got != 3 {
+		t.Errorf("function nodes = %d, want 3", got)
+	}
+}
+
+func TestModuleOwnerSkipsNestedModules(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	nested := filepath.FromSlash("/repo/tools")
+	owns := moduleOwner(
+		[]goModule{{ModulePath: "example.com/app", Dir: root}, {ModulePath: "example.com/app/tools", Dir: nested}},
+		[]goModule{{ModulePath: "example.com/app", Dir: root}},
+	)
+
+	if !owns(filepath.Join(root, "main.go")) {
+		t.Error("file of the extracted module not owned")
+	}
+	if owns(filepath.Join(nested, "gen.go")) {
+		t.Error("file of a nested, unextracted module owned")
+	}
+	if owns(filepath.FromSlash("/repository/main.go")) {
+		t.Error("file outside the module directory owned")
+	}
+}
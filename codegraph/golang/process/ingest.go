@@ -4,22 +4,68 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"basegraph.co/relay/common/arangodb"
 	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultIngestConcurrency = 4
+	defaultIngestBatchSize   = 10000
 )
 
 // Ingestor handles ingestion of extracted code into ArangoDB.
 type Ingestor struct {
-	arango arangodb.Client
+	arango      arangodb.Client
+	writer      arangodb.GraphWriter // arango, or the transaction of an atomic run
+	concurrency int                  // Stages run at once within a phase
+	batchSize   int                  // Documents per IngestNodes/IngestEdges call
+	atomic      bool                 // Rebuild inside one transaction
+
+	plan *planRecorder // Set for dry runs: writes are counted here instead
 }
 
 // NewIngestor creates a new Ingestor with the provided clients.
 func NewIngestor(arango arangodb.Client) *Ingestor {
 	return &Ingestor{
-		arango: arango,
+		arango:      arango,
+		writer:      arango,
+		concurrency: defaultIngestConcurrency,
+		batchSize:   defaultIngestBatchSize,
+	}
+}
+
+// WithConcurrency sets how many stages of a phase run at once. 1 runs the
+// stages one after another; values <= 0 use the default.
+func (i *Ingestor) WithConcurrency(n int) *Ingestor {
+	if n <= 0 {
+		n = defaultIngestConcurrency
 	}
+	i.concurrency = n
+	return i
+}
+
+// WithBatchSize sets how many documents go into one ingest call. Values <= 0
+// use the default.
+func (i *Ingestor) WithBatchSize(n int) *Ingestor {
+	if n <= 0 {
+		n = defaultIngestBatchSize
+	}
+	i.batchSize = n
+	return i
+}
+
+// WithAtomic makes Ingest rebuild the graph inside one transaction, so a
+// failed run leaves the previous graph intact instead of a half-built one.
+// Stages then run one at a time. IngestIncremental only uses it when it falls
+// back to a full rebuild.
+func (i *Ingestor) WithAtomic(atomic bool) *Ingestor {
+	i.atomic = atomic
+	return i
 }
 
 // Ingest processes the extraction result and ingests it into ArangoDB.
@@ -39,16 +85,13 @@ func (i *Ingestor) Ingest(ctx context.Context, res extract.ExtractNodesResult) e
 		return fmt.Errorf("ensure graph: %w", err)
 	}
 
-	// Step 2: Wipe existing data for clean rebuild
-	slog.Info("Truncating existing collections")
-	if err := i.arango.TruncateCollections(ctx); err != nil {
-		return fmt.Errorf("truncate collections: %w", err)
+	// Step 2: Wipe existing data and rebuild
+	rebuild := i.rebuild
+	if i.atomic {
+		rebuild = i.rebuildAtomically
 	}
-
-	// Step 3: Ingest nodes and edges into ArangoDB
-	slog.Info("Ingesting nodes and edges into ArangoDB")
-	if err := i.ingestToArangoDB(ctx, res); err != nil {
-		return fmt.Errorf("arangodb ingestion: %w", err)
+	if err := rebuild(ctx, res); err != nil {
+		return err
 	}
 
 	slog.Info("Ingestion completed",
@@ -56,53 +99,164 @@ func (i *Ingestor) Ingest(ctx context.Context, res extract.ExtractNodesResult) e
 	return nil
 }
 
-func (i *Ingestor) ingestToArangoDB(ctx context.Context, res extract.ExtractNodesResult) error {
-	// Ingest nodes by collection type
-	if err := i.ingestFunctionNodes(ctx, res.Functions); err != nil {
-		return fmt.Errorf("ingest function nodes: %w", err)
+// rebuild truncates every collection and ingests res.
+func (i *Ingestor) rebuild(ctx context.Context, res extract.ExtractNodesResult) error {
+	slog.Info("Truncating existing collections")
+	if err := i.writer.TruncateCollections(ctx); err != nil {
+		return fmt.Errorf("truncate collections: %w", err)
 	}
 
-	if err := i.ingestTypeNodes(ctx, res.TypeDecls, res.Interfaces, res.NamedTypes); err != nil {
-		return fmt.Errorf("ingest type nodes: %w", err)
+	slog.Info("Ingesting nodes and edges into ArangoDB",
+		"concurrency", i.concurrency,
+		"batch_size", i.batchSize)
+	if err := i.ingestToArangoDB(ctx, res); err != nil {
+		return fmt.Errorf("arangodb ingestion: %w", err)
 	}
+	return nil
+}
 
-	if err := i.ingestMemberNodes(ctx, res.Members, res.Vars); err != nil {
-		return fmt.Errorf("ingest member nodes: %w", err)
+// rebuildAtomically runs rebuild in a transaction, committing only if every
+// stage succeeded.
+func (i *Ingestor) rebuildAtomically(ctx context.Context, res extract.ExtractNodesResult) error {
+	tx, err := i.arango.BeginIngest(ctx)
+	if err != nil {
+		return fmt.Errorf("begin ingest: %w", err)
+	}
+
+	txi := *i
+	txi.writer = tx
+	txi.concurrency = 1 // A transaction takes one request at a time
+	if err := txi.rebuild(ctx, res); err != nil {
+		// Abort even when ctx was cancelled, or the server holds the
+		// transaction's locks until it times out.
+		if abortErr := tx.Abort(context.WithoutCancel(ctx)); abortErr != nil {
+			slog.Error("failed aborting ingest", "err", abortErr)
+		}
+		return err
 	}
 
-	if err := i.ingestFileNodes(ctx, res.Files); err != nil {
-		return fmt.Errorf("ingest file nodes: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit ingest: %w", err)
 	}
+	return nil
+}
 
-	if err := i.ingestModuleNodes(ctx, res.Namespaces, res.Files); err != nil {
-		return fmt.Errorf("ingest module nodes: %w", err)
-	}
+// IngestPlan is what a run would write, per collection.
+type IngestPlan struct {
+	Nodes map[string]int // Collection -> node count
+	Edges map[string]int // Collection -> edge count
+}
 
-	// Ingest edges
-	if err := i.ingestCallEdges(ctx, res.Functions); err != nil {
-		return fmt.Errorf("ingest call edges: %w", err)
-	}
+// planRecorder collects an IngestPlan from concurrently running stages.
+type planRecorder struct {
+	mu   sync.Mutex
+	plan IngestPlan
+}
 
-	if err := i.ingestReturnEdges(ctx, res.Functions); err != nil {
-		return fmt.Errorf("ingest return edges: %w", err)
+func (r *planRecorder) add(counts map[string]int, collection string, n int) {
+	if n == 0 {
+		return
 	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts[collection] += n
+}
 
-	if err := i.ingestParamEdges(ctx, res.Functions); err != nil {
-		return fmt.Errorf("ingest param edges: %w", err)
-	}
+// IngestDryRun prepares every stage's documents like Ingest but writes
+// nothing, not even the schema, and returns how many would be written to each
+// collection. Compare plans across extractor changes to catch regressions
+// before they reach the graph.
+func (i *Ingestor) IngestDryRun(ctx context.Context, res extract.ExtractNodesResult) (IngestPlan, error) {
+	rec := &planRecorder{plan: IngestPlan{Nodes: map[string]int{}, Edges: map[string]int{}}}
+	dry := *i
+	dry.plan = rec
+	if err := dry.ingestToArangoDB(ctx, res); err != nil {
+		return IngestPlan{}, err
+	}
+	return rec.plan, nil
+}
 
-	if err := i.ingestImplementsEdges(ctx, res.TypeDecls); err != nil {
-		return fmt.Errorf("ingest implements edges: %w", err)
-	}
+// ingestStage writes one collection.
+type ingestStage struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// ingestToArangoDB runs the node stages, which are independent of each
+// other, then the edge stages once every node collection is written. Stages
+// within a phase run concurrently.
+func (i *Ingestor) ingestToArangoDB(ctx context.Context, res extract.ExtractNodesResult) error {
+	nodeStages := []ingestStage{
+		{"function nodes", func(ctx context.Context) error { return i.ingestFunctionNodes(ctx, res.Functions) }},
+		{"type nodes", func(ctx context.Context) error {
+			return i.ingestTypeNodes(ctx, res.TypeDecls, res.Interfaces, res.NamedTypes)
+		}},
+		{"member nodes", func(ctx context.Context) error { return i.ingestMemberNodes(ctx, res.Members, res.Vars) }},
+		{"file nodes", func(ctx context.Context) error { return i.ingestFileNodes(ctx, res.Files) }},
+		{"module nodes", func(ctx context.Context) error { return i.ingestModuleNodes(ctx, res.Namespaces, res.Files) }},
+		{"queue nodes", func(ctx context.Context) error { return i.ingestQueueNodes(ctx, res.Functions) }},
+	}
+	if err := i.runStages(ctx, nodeStages); err != nil {
+		return err
+	}
+
+	edgeStages := []ingestStage{
+		{"call edges", func(ctx context.Context) error { return i.ingestCallEdges(ctx, res.Functions) }},
+		{"return edges", func(ctx context.Context) error { return i.ingestReturnEdges(ctx, res.Functions) }},
+		{"param edges", func(ctx context.Context) error { return i.ingestParamEdges(ctx, res.Functions) }},
+		{"implements edges", func(ctx context.Context) error { return i.ingestImplementsEdges(ctx, res.TypeDecls) }},
+		{"parent edges", func(ctx context.Context) error { return i.ingestParentEdges(ctx, res.Functions, res.Members) }},
+		{"import edges", func(ctx context.Context) error { return i.ingestImportEdges(ctx, res.Files) }},
+		{"async edges", func(ctx context.Context) error { return i.ingestAsyncEdges(ctx, res.Functions) }},
+	}
+	return i.runStages(ctx, edgeStages)
+}
 
-	if err := i.ingestParentEdges(ctx, res.Functions, res.Members); err != nil {
-		return fmt.Errorf("ingest parent edges: %w", err)
+// runStages runs stages with at most i.concurrency at once and returns the
+// first error, cancelling the stages still running.
+func (i *Ingestor) runStages(ctx context.Context, stages []ingestStage) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(i.concurrency)
+	for _, stage := range stages {
+		g.Go(func() error {
+			if err := stage.run(ctx); err != nil {
+				return fmt.Errorf("ingest %s: %w", stage.name, err)
+			}
+			return nil
+		})
 	}
+	return g.Wait()
+}
 
-	if err := i.ingestImportEdges(ctx, res.Files); err != nil {
-		return fmt.Errorf("ingest import edges: %w", err)
+// ingestNodes writes nodes in batches of i.batchSize, or counts them in a dry
+// run.
+func (i *Ingestor) ingestNodes(ctx context.Context, collection string, nodes []arangodb.Node) error {
+	if i.plan != nil {
+		i.plan.add(i.plan.plan.Nodes, collection, len(nodes))
+		return nil
 	}
+	for start := 0; start < len(nodes); start += i.batchSize {
+		end := min(start+i.batchSize, len(nodes))
+		if err := i.writer.IngestNodes(ctx, collection, nodes[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// ingestEdges writes edges in batches of i.batchSize, or counts them in a dry
+// run.
+func (i *Ingestor) ingestEdges(ctx context.Context, collection string, edges []arangodb.Edge) error {
+	if i.plan != nil {
+		i.plan.add(i.plan.plan.Edges, collection, len(edges))
+		return nil
+	}
+	for start := 0; start < len(edges); start += i.batchSize {
+		end := min(start+i.batchSize, len(edges))
+		if err := i.writer.IngestEdges(ctx, collection, edges[start:end]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -124,7 +278,7 @@ func (i *Ingestor) ingestFunctionNodes(ctx context.Context, functions map[string
 			Doc:       fn.Doc.Comment,
 			Filepath:  fn.Filepath,
 			Namespace: fn.Namespace.Name,
-			Language:  extract.Go,
+			Language:  languageOf(fn.Filepath),
 			Pos:       fn.Pos,
 			End:       fn.End,
 			IsMethod:  isMethod,
@@ -133,7 +287,7 @@ func (i *Ingestor) ingestFunctionNodes(ctx context.Context, functions map[string
 	}
 
 	slog.Info("Ingesting function nodes", "count", len(nodes))
-	return i.arango.IngestNodes(ctx, "functions", nodes)
+	return i.ingestNodes(ctx, "functions", nodes)
 }
 
 func (i *Ingestor) ingestTypeNodes(ctx context.Context, decls, interfaces map[string]extract.TypeDecl, named map[string]extract.Named) error {
@@ -150,7 +304,7 @@ func (i *Ingestor) ingestTypeNodes(ctx context.Context, decls, interfaces map[st
 			Doc:       decl.Doc.Comment,
 			Filepath:  decl.Filepath,
 			Namespace: decl.Namespace.Name,
-			Language:  extract.Go,
+			Language:  languageOf(decl.Filepath),
 			Pos:       decl.Pos,
 			End:       decl.End,
 		})
@@ -167,7 +321,7 @@ func (i *Ingestor) ingestTypeNodes(ctx context.Context, decls, interfaces map[st
 			Doc:       iface.Doc.Comment,
 			Filepath:  iface.Filepath,
 			Namespace: iface.Namespace.Name,
-			Language:  extract.Go,
+			Language:  languageOf(iface.Filepath),
 			Pos:       iface.Pos,
 			End:       iface.End,
 		})
@@ -184,7 +338,7 @@ func (i *Ingestor) ingestTypeNodes(ctx context.Context, decls, interfaces map[st
 			Doc:       n.Doc.Comment,
 			Filepath:  n.Filepath,
 			Namespace: n.Namespace.Name,
-			Language:  extract.Go,
+			Language:  languageOf(n.Filepath),
 			Pos:       n.Pos,
 			End:       n.End,
 		})
@@ -195,7 +349,7 @@ func (i *Ingestor) ingestTypeNodes(ctx context.Context, decls, interfaces map[st
 	}
 
 	slog.Info("Ingesting type nodes", "count", len(nodes))
-	return i.arango.IngestNodes(ctx, "types", nodes)
+	return i.ingestNodes(ctx, "types", nodes)
 }
 
 func (i *Ingestor) ingestMemberNodes(ctx context.Context, members map[string]extract.Member, vars map[string]extract.Variable) error {
@@ -212,10 +366,11 @@ func (i *Ingestor) ingestMemberNodes(ctx context.Context, members map[string]ext
 			Doc:       member.Doc.Comment,
 			Filepath:  member.Filepath,
 			Namespace: member.Namespace.Name,
-			Language:  extract.Go,
+			Language:  languageOf(member.Filepath),
 			Pos:       member.Pos,
 			End:       member.End,
 			TypeQName: member.TypeQName,
+			Tag:       member.Tag,
 		})
 	}
 
@@ -230,7 +385,7 @@ func (i *Ingestor) ingestMemberNodes(ctx context.Context, members map[string]ext
 			Doc:       v.Doc.Comment,
 			Filepath:  v.Filepath,
 			Namespace: v.Namespace.Name,
-			Language:  extract.Go,
+			Language:  languageOf(v.Filepath),
 			Pos:       v.Pos,
 			End:       v.End,
 			TypeQName: v.TypeQName,
@@ -242,7 +397,7 @@ func (i *Ingestor) ingestMemberNodes(ctx context.Context, members map[string]ext
 	}
 
 	slog.Info("Ingesting member nodes", "count", len(nodes))
-	return i.arango.IngestNodes(ctx, "members", nodes)
+	return i.ingestNodes(ctx, "members", nodes)
 }
 
 func (i *Ingestor) ingestFileNodes(ctx context.Context, files map[string]extract.File) error {
@@ -259,13 +414,15 @@ func (i *Ingestor) ingestFileNodes(ctx context.Context, files map[string]extract
 			QName:     filename,
 			Name:      filename,
 			Kind:      "file",
+			Filepath:  file.Filename,
 			Namespace: file.Namespace.Name,
 			Language:  file.Language,
+			Hash:      file.Hash,
 		})
 	}
 
 	slog.Info("Ingesting file nodes", "count", len(nodes))
-	return i.arango.IngestNodes(ctx, "files", nodes)
+	return i.ingestNodes(ctx, "files", nodes)
 }
 
 func (i *Ingestor) ingestModuleNodes(ctx context.Context, namespaces []extract.Namespace, files map[string]extract.File) error {
@@ -301,7 +458,37 @@ func (i *Ingestor) ingestModuleNodes(ctx context.Context, namespaces []extract.N
 	}
 
 	slog.Info("Ingesting module nodes", "count", len(nodes))
-	return i.arango.IngestNodes(ctx, "modules", nodes)
+	return i.ingestNodes(ctx, "modules", nodes)
+}
+
+// ingestQueueNodes writes a queues vertex for every async boundary a
+// function enqueues to or dequeues from.
+func (i *Ingestor) ingestQueueNodes(ctx context.Context, functions map[string]extract.Function) error {
+	queues := make(map[string]bool)
+	for _, fn := range functions {
+		for _, q := range fn.Enqueues {
+			queues[q] = true
+		}
+		for _, q := range fn.Dequeues {
+			queues[q] = true
+		}
+	}
+
+	if len(queues) == 0 {
+		return nil
+	}
+
+	nodes := make([]arangodb.Node, 0, len(queues))
+	for q := range queues {
+		nodes = append(nodes, arangodb.Node{
+			QName: q,
+			Name:  q,
+			Kind:  "queue",
+		})
+	}
+
+	slog.Info("Ingesting queue nodes", "count", len(nodes))
+	return i.ingestNodes(ctx, "queues", nodes)
 }
 
 func (i *Ingestor) ingestCallEdges(ctx context.Context, functions map[string]extract.Function) error {
@@ -329,7 +516,45 @@ func (i *Ingestor) ingestCallEdges(ctx context.Context, functions map[string]ext
 	}
 
 	slog.Info("Ingesting call edges", "count", len(edges))
-	return i.arango.IngestEdges(ctx, "calls", edges)
+	return i.ingestEdges(ctx, "calls", edges)
+}
+
+// ingestAsyncEdges links enqueuing functions to their queue (async_enqueue)
+// and each queue to the functions dequeuing from it (async_dequeue).
+func (i *Ingestor) ingestAsyncEdges(ctx context.Context, functions map[string]extract.Function) error {
+	var enqueue, dequeue []arangodb.Edge
+
+	for _, fn := range functions {
+		if fn.QName == "" {
+			continue
+		}
+		for _, q := range fn.Enqueues {
+			enqueue = append(enqueue, arangodb.Edge{
+				From:     fn.QName,
+				To:       q,
+				FromKind: kindForFunction(fn),
+				ToKind:   "queue",
+			})
+		}
+		for _, q := range fn.Dequeues {
+			dequeue = append(dequeue, arangodb.Edge{
+				From:     q,
+				To:       fn.QName,
+				FromKind: "queue",
+				ToKind:   kindForFunction(fn),
+			})
+		}
+	}
+
+	if len(enqueue) == 0 && len(dequeue) == 0 {
+		return nil
+	}
+
+	slog.Info("Ingesting async edges", "enqueue", len(enqueue), "dequeue", len(dequeue))
+	if err := i.ingestEdges(ctx, "async_enqueue", enqueue); err != nil {
+		return err
+	}
+	return i.ingestEdges(ctx, "async_dequeue", dequeue)
 }
 
 func (i *Ingestor) ingestReturnEdges(ctx context.Context, functions map[string]extract.Function) error {
@@ -357,7 +582,7 @@ func (i *Ingestor) ingestReturnEdges(ctx context.Context, functions map[string]e
 	}
 
 	slog.Info("Ingesting return edges", "count", len(edges))
-	return i.arango.IngestEdges(ctx, "returns", edges)
+	return i.ingestEdges(ctx, "returns", edges)
 }
 
 func (i *Ingestor) ingestParamEdges(ctx context.Context, functions map[string]extract.Function) error {
@@ -385,7 +610,7 @@ func (i *Ingestor) ingestParamEdges(ctx context.Context, functions map[string]ex
 	}
 
 	slog.Info("Ingesting param edges", "count", len(edges))
-	return i.arango.IngestEdges(ctx, "param_of", edges)
+	return i.ingestEdges(ctx, "param_of", edges)
 }
 
 func (i *Ingestor) ingestImplementsEdges(ctx context.Context, decls map[string]extract.TypeDecl) error {
@@ -413,7 +638,7 @@ func (i *Ingestor) ingestImplementsEdges(ctx context.Context, decls map[string]e
 	}
 
 	slog.Info("Ingesting implements edges", "count", len(edges))
-	return i.arango.IngestEdges(ctx, "implements", edges)
+	return i.ingestEdges(ctx, "implements", edges)
 }
 
 func (i *Ingestor) ingestParentEdges(ctx context.Context, functions map[string]extract.Function, members map[string]extract.Member) error {
@@ -450,7 +675,7 @@ func (i *Ingestor) ingestParentEdges(ctx context.Context, functions map[string]e
 	}
 
 	slog.Info("Ingesting parent edges", "count", len(edges))
-	return i.arango.IngestEdges(ctx, "parent", edges)
+	return i.ingestEdges(ctx, "parent", edges)
 }
 
 func (i *Ingestor) ingestImportEdges(ctx context.Context, files map[string]extract.File) error {
@@ -478,7 +703,18 @@ func (i *Ingestor) ingestImportEdges(ctx context.Context, files map[string]extra
 	}
 
 	slog.Info("Ingesting import edges", "count", len(edges))
-	return i.arango.IngestEdges(ctx, "imports", edges)
+	return i.ingestEdges(ctx, "imports", edges)
+}
+
+// languageOf returns the language of a source file by its extension.
+func languageOf(filename string) string {
+	if strings.HasSuffix(filename, ".ts") || strings.HasSuffix(filename, ".tsx") {
+		return extract.TypeScript
+	}
+	if strings.HasSuffix(filename, ".py") {
+		return extract.Python
+	}
+	return extract.Go
 }
 
 // kindForFunction returns "method" if the function has a receiver, otherwise "function".
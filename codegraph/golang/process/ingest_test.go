@@ -0,0 +1,276 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"basegraph.co/relay/common/arangodb"
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
+)
+
+// recordingClient records ingest calls. Node writes block until
+// nodeBarrier of them are in flight (or a timeout passes), so a test can
+// observe how many node stages overlap.
+type recordingClient struct {
+	arangodb.Client
+
+	nodeBarrier int
+
+	mu            sync.Mutex
+	inFlight      int
+	maxInFlight   int
+	edgesEarly    bool // An edge write started before all node writes finished
+	nodeCalls     map[string]int
+	edges         map[string][]arangodb.Edge
+	barrierClosed chan struct{}
+	closeBarrier  sync.Once
+}
+
+func newRecordingClient(nodeBarrier int) *recordingClient {
+	return &recordingClient{
+		nodeBarrier:   nodeBarrier,
+		nodeCalls:     map[string]int{},
+		edges:         map[string][]arangodb.Edge{},
+		barrierClosed: make(chan struct{}),
+	}
+}
+
+func (c *recordingClient) EnsureDatabase(ctx context.Context) error      { return nil }
+func (c *recordingClient) EnsureCollections(ctx context.Context) error   { return nil }
+func (c *recordingClient) EnsureGraph(ctx context.Context) error         { return nil }
+func (c *recordingClient) TruncateCollections(ctx context.Context) error { return nil }
+
+func (c *recordingClient) IngestNodes(ctx context.Context, collection string, nodes []arangodb.Node) error {
+	c.mu.Lock()
+	c.inFlight++
+	c.maxInFlight = max(c.maxInFlight, c.inFlight)
+	c.nodeCalls[collection]++
+	if c.inFlight == c.nodeBarrier {
+		c.closeBarrier.Do(func() { close(c.barrierClosed) })
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.barrierClosed:
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *recordingClient) IngestEdges(ctx context.Context, collection string, edges []arangodb.Edge) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inFlight > 0 {
+		c.edgesEarly = true
+	}
+	c.edges[collection] = append(c.edges[collection], edges...)
+	return nil
+}
+
+func sampleExtractResult() extract.ExtractNodesResult {
+	ns := extract.Namespace{Name: "example.com/app"}
+	res := newExtractAccumulator()
+	res.Namespaces = []extract.Namespace{ns}
+	res.Functions["example.com/app.Run"] = extract.Function{
+		Name: "Run", QName: "example.com/app.Run", Namespace: ns, Filepath: "main.go",
+		Calls: []string{"example.com/app.Store.Save"},
+	}
+	res.Functions["example.com/app.Store.Save"] = extract.Function{
+		Name: "Save", QName: "example.com/app.Store.Save", Namespace: ns, Filepath: "store.go",
+		ParentQName: "example.com/app.Store",
+	}
+	res.TypeDecls["example.com/app.Store"] = extract.TypeDecl{Name: "Store", QName: "example.com/app.Store", Namespace: ns}
+	res.Members["example.com/app.Store.db"] = extract.Member{Name: "db", QName: "example.com/app.Store.db", Namespace: ns}
+	res.Files["main.go"] = extract.File{Namespace: ns}
+	return res
+}
+
+func TestIngestRunsNodeStagesConcurrently(t *testing.T) {
+	// functions, types, members, files, modules
+	client := newRecordingClient(5)
+
+	err := NewIngestor(client).WithConcurrency(5).Ingest(context.Background(), sampleExtractResult())
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	if client.maxInFlight != 5 {
+		t.Errorf("max concurrent node stages = %d, want 5", client.maxInFlight)
+	}
+	if client.edgesEarly {
+		t.Error("edge stage started before node stages finished")
+	}
+
+	calls := client.edges["calls"]
+	if len(calls) != 1 || calls[0].From != "example.com/app.Run" || calls[0].To != "example.com/app.Store.Save" {
+		t.Errorf("call edges = %+v", calls)
+	}
+	if parents := client.edges["parent"]; len(parents) != 1 || parents[0].To != "example.com/app.Store" {
+		t.Errorf("parent edges = %+v", parents)
+	}
+}
+
+func TestIngestSequentialAndBatched(t *testing.T) {
+	client := newRecordingClient(1)
+
+	err := NewIngestor(client).WithConcurrency(1).WithBatchSize(1).Ingest(context.Background(), sampleExtractResult())
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+
+	if client.maxInFlight != 1 {
+		t.Errorf("max concurrent node stages = %d, want 1", client.maxInFlight)
+	}
+	if got := client.nodeCalls["functions"]; got != 2 {
+		t.Errorf("function node batches = %d, want 2", got)
+	}
+}
+
+func TestIngestDryRunCountsWithoutWriting(t *testing.T) {
+	client := newRecordingClient(1)
+	res := sampleExtractResult()
+	res.Functions["example.com/app.Run"] = extract.Function{
+		Name: "Run", QName: "example.com/app.Run", Namespace: extract.Namespace{Name: "example.com/app"}, Filepath: "main.go",
+		Calls:       []string{"example.com/app.Store.Save"},
+		ParamQNames: []string{"example.com/app.Store"},
+		Enqueues:    []string{"jobs"},
+	}
+
+	plan, err := NewIngestor(client).WithBatchSize(1).IngestDryRun(context.Background(), res)
+	if err != nil {
+		t.Fatalf("IngestDryRun() error = %v", err)
+	}
+
+	wantNodes := map[string]int{"functions": 2, "types": 1, "members": 1, "files": 1, "modules": 1, "queues": 1}
+	wantEdges := map[string]int{"calls": 1, "param_of": 1, "parent": 1, "async_enqueue": 1}
+	if !maps.Equal(plan.Nodes, wantNodes) {
+		t.Errorf("plan nodes = %v, want %v", plan.Nodes, wantNodes)
+	}
+	if !maps.Equal(plan.Edges, wantEdges) {
+		t.Errorf("plan edges = %v, want %v", plan.Edges, wantEdges)
+	}
+	if len(client.nodeCalls) != 0 || len(client.edges) != 0 {
+		t.Errorf("dry run wrote nodes %v, edges %v", client.nodeCalls, client.edges)
+	}
+}
+
+// txClient holds a committed graph of node and edge counts per collection.
+// Writes made through BeginIngest land in the graph only on Commit; IngestEdges
+// fails for failEdges.
+type txClient struct {
+	arangodb.Client
+
+	failEdges string
+	graph     map[string]int
+	direct    int // Writes that bypassed the transaction
+	aborted   bool
+}
+
+func (c *txClient) EnsureDatabase(ctx context.Context) error    { return nil }
+func (c *txClient) EnsureCollections(ctx context.Context) error { return nil }
+func (c *txClient) EnsureGraph(ctx context.Context) error       { return nil }
+
+func (c *txClient) TruncateCollections(ctx context.Context) error {
+	c.direct++
+	return nil
+}
+
+func (c *txClient) IngestNodes(ctx context.Context, collection string, nodes []arangodb.Node) error {
+	c.direct++
+	return nil
+}
+
+func (c *txClient) IngestEdges(ctx context.Context, collection string, edges []arangodb.Edge) error {
+	c.direct++
+	return nil
+}
+
+func (c *txClient) BeginIngest(ctx context.Context) (arangodb.IngestTx, error) {
+	return &fakeTx{client: c, pending: map[string]int{}}, nil
+}
+
+type fakeTx struct {
+	client   *txClient
+	pending  map[string]int
+	inFlight atomic.Int32
+}
+
+func (t *fakeTx) enter() func() {
+	if t.inFlight.Add(1) > 1 {
+		panic("concurrent requests on one transaction")
+	}
+	return func() { t.inFlight.Add(-1) }
+}
+
+func (t *fakeTx) TruncateCollections(ctx context.Context) error {
+	defer t.enter()()
+	t.pending = map[string]int{}
+	return nil
+}
+
+func (t *fakeTx) IngestNodes(ctx context.Context, collection string, nodes []arangodb.Node) error {
+	defer t.enter()()
+	t.pending[collection] += len(nodes)
+	return nil
+}
+
+func (t *fakeTx) IngestEdges(ctx context.Context, collection string, edges []arangodb.Edge) error {
+	defer t.enter()()
+	if collection == t.client.failEdges {
+		return errors.New("connection reset")
+	}
+	t.pending[collection] += len(edges)
+	return nil
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	t.client.graph = t.pending
+	return nil
+}
+
+func (t *fakeTx) Abort(ctx context.Context) error {
+	t.client.aborted = true
+	return nil
+}
+
+func TestIngestAtomicKeepsGraphOnFailure(t *testing.T) {
+	res := sampleExtractResult()
+	store := res.TypeDecls["example.com/app.Store"]
+	store.ImplementsQName = []string{"example.com/app.Saver"}
+	res.TypeDecls["example.com/app.Store"] = store
+
+	previous := map[string]int{"functions": 7, "calls": 3}
+	client := &txClient{failEdges: "implements", graph: maps.Clone(previous)}
+
+	err := NewIngestor(client).WithConcurrency(5).WithAtomic(true).Ingest(context.Background(), res)
+	if err == nil || !strings.Contains(err.Error(), "ingest implements edges: connection reset") {
+		t.Fatalf("Ingest() error = %v, want implements failure", err)
+	}
+	if !client.aborted {
+		t.Error("transaction not aborted")
+	}
+	if !maps.Equal(client.graph, previous) {
+		t.Errorf("graph = %v, want unchanged %v", client.graph, previous)
+	}
+	if client.direct != 0 {
+		t.Errorf("%d writes bypassed the transaction", client.direct)
+	}
+
+	client.failEdges = ""
+	if err := NewIngestor(client).WithAtomic(true).Ingest(context.Background(), res); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if client.graph["functions"] != 2 || client.graph["implements"] != 1 {
+		t.Errorf("graph after commit = %v", client.graph)
+	}
+}
@@ -2,9 +2,11 @@ package process
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,8 +14,12 @@ import (
 	"github.com/humanbeeng/lepo/prototypes/codegraph/extract"
 )
 
-// Orchestrate runs the code extraction and ingestion pipeline.
-func Orchestrate(e extract.Extractor) {
+// Orchestrate runs the code extraction and ingestion pipeline. scope should
+// match the one e was configured with; it keys the hash cache. tsExtractor
+// indexes the TypeScript projects (directories with a tsconfig.json) and
+// pyExtractor the Python projects (directories with a pyproject.toml,
+// setup.py or setup.cfg) found alongside the Go modules; nil skips them.
+func Orchestrate(e extract.Extractor, tsExtractor extract.Extractor, pyExtractor extract.Extractor, scope extract.Scope) {
 	slog.Info("Begin orchestration")
 	start := time.Now()
 	defer func() {
@@ -26,39 +32,106 @@ func Orchestrate(e extract.Extractor) {
 	repoRoot := envOrDefault("TARGET_REPO_PATH", "/Users/nithin/basegraph/relay")
 	targetModule := strings.TrimSpace(os.Getenv("TARGET_MODULE"))
 
+	var tsProjects []goModule
+	if tsExtractor != nil {
+		projects, err := discoverTSProjects(repoRoot)
+		if err != nil {
+			slog.Error("discover typescript projects failed", "root", repoRoot, "err", err)
+			return
+		}
+		tsProjects = projects
+	}
+
+	var pyProjects []goModule
+	if pyExtractor != nil {
+		projects, err := discoverPyProjects(repoRoot)
+		if err != nil {
+			slog.Error("discover python projects failed", "root", repoRoot, "err", err)
+			return
+		}
+		pyProjects = projects
+	}
+
 	mods, err := discoverGoModules(repoRoot)
+	if errors.Is(err, errNoGoModules) && len(tsProjects)+len(pyProjects) > 0 {
+		err = nil
+	}
 	if err != nil {
 		slog.Error("discover go modules failed", "root", repoRoot, "err", err)
 		return
 	}
 
+	// Owner lookup for incremental ingestion spans every module and project,
+	// including the ones filtered out below.
+	allMods := append(append(append([]goModule{}, mods...), tsProjects...), pyProjects...)
 	if targetModule != "" {
-		filtered := make([]goModule, 0, len(mods))
-		for _, mod := range mods {
-			if strings.Contains(mod.ModulePath, targetModule) {
-				filtered = append(filtered, mod)
-			}
-		}
-		if len(filtered) == 0 {
+		mods = filterModules(mods, targetModule)
+		tsProjects = filterModules(tsProjects, targetModule)
+		pyProjects = filterModules(pyProjects, targetModule)
+		if len(mods) == 0 && len(tsProjects) == 0 && len(pyProjects) == 0 {
 			slog.Warn("no modules matched TARGET_MODULE filter, skipping extraction", "module", targetModule)
 			return
 		}
-		mods = filtered
 	}
 
-	slog.Info("Modules ready for extraction", "count", len(mods))
+	slog.Info("Modules ready for extraction", "go", len(mods), "typescript", len(tsProjects), "python", len(pyProjects))
+
+	boundaries, err := asyncBoundariesFromEnv()
+	if err != nil {
+		slog.Error("invalid CODEGRAPH_ASYNC_BOUNDARIES", "err", err)
+		return
+	}
+
+	// Optional sidecar of per-file hashes; unchanged modules reuse their last extraction.
+	cache := newExtractCache(strings.TrimSpace(os.Getenv("CODEGRAPH_HASH_CACHE_DIR")))
 
 	acc := newExtractAccumulator()
 
 	for _, mod := range mods {
 		slog.Info("Extracting module", "module", mod.ModulePath, "dir", mod.Dir)
-		moduleRes, extractErr := e.Extract(mod.ModulePath, mod.Dir)
+		moduleRes, skipped, extractErr := extractModule(e, mod, cache, scope)
 		if extractErr != nil {
 			slog.Error("module extraction failed", "module", mod.ModulePath, "dir", mod.Dir, "err", extractErr)
 			return
 		}
+		if skipped {
+			slog.Info("Module unchanged since last run, reusing cached extraction", "module", mod.ModulePath)
+		}
 		mergeExtractResults(&acc, moduleRes)
 	}
+	extracted := mods
+
+	// TypeScript needs node and the project's typescript package; a project
+	// that can't be extracted is skipped so the Go graph is still built.
+	for _, project := range tsProjects {
+		slog.Info("Extracting TypeScript project", "project", project.ModulePath, "dir", project.Dir)
+		projectRes, extractErr := tsExtractor.Extract(project.ModulePath, project.Dir)
+		if extractErr != nil {
+			slog.Warn("typescript extraction failed, skipping project", "project", project.ModulePath, "dir", project.Dir, "err", extractErr)
+			continue
+		}
+		mergeExtractResults(&acc, projectRes)
+		extracted = append(extracted, project)
+	}
+
+	// Python projects are extracted from their import root, so qnames are
+	// the dotted module paths code imports them by.
+	for _, project := range pyProjects {
+		slog.Info("Extracting Python project", "project", project.ModulePath, "dir", project.Dir)
+		projectRes, extractErr := pyExtractor.Extract("", project.Dir)
+		if extractErr != nil {
+			slog.Warn("python extraction failed, skipping project", "project", project.ModulePath, "dir", project.Dir, "err", extractErr)
+			continue
+		}
+		mergeExtractResults(&acc, projectRes)
+		extracted = append(extracted, project)
+	}
+
+	// Async boundaries are linked on the merged result, so a producer and a
+	// consumer in different modules still meet at their queue.
+	if linked := extract.LinkAsync(acc.Functions, boundaries); linked > 0 {
+		slog.Info("Linked functions across async boundaries", "functions", linked, "boundaries", len(boundaries))
+	}
 
 	extractRes := acc
 	dataSize := float64(len(fmt.Sprintf("%+v", extractRes))) / (1024 * 1024)
@@ -70,6 +143,7 @@ func Orchestrate(e extract.Extractor) {
 		Username: envOrDefault("ARANGO_USERNAME", "root"),
 		Password: envOrDefault("ARANGO_PASSWORD", ""),
 		Database: envOrDefault("ARANGO_DATABASE", "codegraph"),
+		RepoID:   strings.TrimSpace(os.Getenv("ARANGO_REPO_ID")),
 	})
 	if err != nil {
 		slog.Error("unable to create arangodb client", "err", err)
@@ -82,15 +156,79 @@ func Orchestrate(e extract.Extractor) {
 	}()
 
 	// Step 3: Ingest into database
-	ingestor := NewIngestor(arangoClient)
-	slog.Info("Ingesting extract result into ArangoDB")
-	if err := ingestor.Ingest(ctx, extractRes); err != nil {
-		slog.Error("ingestion failed", "err", err)
+	concurrency, err := envInt("CODEGRAPH_INGEST_CONCURRENCY")
+	if err != nil {
+		slog.Error("invalid CODEGRAPH_INGEST_CONCURRENCY", "err", err)
 		return
 	}
+	batchSize, err := envInt("CODEGRAPH_INGEST_BATCH_SIZE")
+	if err != nil {
+		slog.Error("invalid CODEGRAPH_INGEST_BATCH_SIZE", "err", err)
+		return
+	}
+	// CODEGRAPH_INGEST_ATOMIC=1 rebuilds inside one transaction so a failed
+	// run keeps the previous graph.
+	ingestor := NewIngestor(arangoClient).
+		WithConcurrency(concurrency).
+		WithBatchSize(batchSize).
+		WithAtomic(strings.TrimSpace(os.Getenv("CODEGRAPH_INGEST_ATOMIC")) == "1")
+	// CODEGRAPH_INCREMENTAL=1 updates only the files whose hash changed
+	// instead of truncating and rebuilding the graph.
+	if strings.TrimSpace(os.Getenv("CODEGRAPH_INCREMENTAL")) == "1" {
+		slog.Info("Incrementally ingesting extract result into ArangoDB")
+		if err := ingestor.IngestIncremental(ctx, extractRes, moduleOwner(allMods, extracted)); err != nil {
+			slog.Error("incremental ingestion failed", "err", err)
+			return
+		}
+	} else {
+		slog.Info("Ingesting extract result into ArangoDB")
+		if err := ingestor.Ingest(ctx, extractRes); err != nil {
+			slog.Error("ingestion failed", "err", err)
+			return
+		}
+	}
 	slog.Info("Ingestion finished successfully")
 }
 
+// filterModules keeps the modules whose path contains target.
+func filterModules(mods []goModule, target string) []goModule {
+	filtered := make([]goModule, 0, len(mods))
+	for _, mod := range mods {
+		if strings.Contains(mod.ModulePath, target) {
+			filtered = append(filtered, mod)
+		}
+	}
+	return filtered
+}
+
+// ExtractScopeFromEnv reads comma-separated include/exclude globs from
+// CODEGRAPH_INCLUDE and CODEGRAPH_EXCLUDE (e.g. "vendor/,testdata/,*_gen.go").
+func ExtractScopeFromEnv() extract.Scope {
+	return extract.Scope{
+		Include: splitEnvList(os.Getenv("CODEGRAPH_INCLUDE")),
+		Exclude: splitEnvList(os.Getenv("CODEGRAPH_EXCLUDE")),
+	}
+}
+
+func splitEnvList(val string) []string {
+	var out []string
+	for _, part := range strings.Split(val, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// envInt parses an integer env var, returning 0 when it's unset.
+func envInt(key string) (int, error) {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(val)
+}
+
 func envOrDefault(key, fallback string) string {
 	if val, ok := os.LookupEnv(key); ok && val != "" {
 		return val
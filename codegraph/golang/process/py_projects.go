@@ -0,0 +1,120 @@
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/humanbeeng/lepo/prototypes/codegraph/extract/python"
+)
+
+// discoverPyProjects finds the Python projects under root: every directory
+// with a pyproject.toml, setup.py or setup.cfg. A project is returned as a
+// goModule whose Dir is its import root (src/ for a src layout, otherwise
+// the project directory) and whose ModulePath is the project name from
+// pyproject.toml, or the directory relative to root when there is none.
+func discoverPyProjects(root string) ([]goModule, error) {
+	var projects []goModule
+
+	walkFn := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		name := d.Name()
+		if path != root {
+			if name == "node_modules" || name == "vendor" || name == "__pycache__" || name == "site-packages" {
+				return fs.SkipDir
+			}
+			if strings.HasPrefix(name, ".") && len(name) > 1 {
+				return fs.SkipDir
+			}
+			if _, statErr := os.Stat(filepath.Join(path, "pyvenv.cfg")); statErr == nil {
+				return fs.SkipDir
+			}
+		}
+
+		if !python.IsProjectDir(path) {
+			return nil
+		}
+		projectName, nameErr := pyProjectName(root, path)
+		if nameErr != nil {
+			return nameErr
+		}
+		projects = append(projects, goModule{ModulePath: projectName, Dir: pyImportRoot(path)})
+		return nil
+	}
+
+	if err := filepath.WalkDir(root, walkFn); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Dir < projects[j].Dir })
+	return projects, nil
+}
+
+// pyImportRoot returns dir/src for a src layout (a src directory that is not
+// itself a package), otherwise dir.
+func pyImportRoot(dir string) string {
+	src := filepath.Join(dir, "src")
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return dir
+	}
+	if _, err := os.Stat(filepath.Join(src, "__init__.py")); err == nil {
+		return dir
+	}
+	return src
+}
+
+// pyProjectName reads name from the [project] or [tool.poetry] table of
+// dir/pyproject.toml, falling back to the directory relative to root.
+func pyProjectName(root, dir string) (string, error) {
+	if name := pyprojectTOMLName(filepath.Join(dir, "pyproject.toml")); name != "" {
+		return name, nil
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", fmt.Errorf("relativize %s: %w", dir, err)
+	}
+	if rel == "." {
+		return filepath.Base(root), nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// pyprojectTOMLName does just enough TOML to find a top-level name key
+// under [project] or [tool.poetry].
+func pyprojectTOMLName(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inTable := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inTable = line == "[project]" || line == "[tool.poetry]"
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) != "name" {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package process
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverPyProjects(t *testing.T) {
+	root := t.TempDir()
+
+	writeFile(t, filepath.Join(root, "api", "pyproject.toml"), "[build-system]\nrequires = [\"hatchling\"]\n\n[project]\nname = \"acme-api\"\nversion = \"0.1.0\"\n")
+	writeFile(t, filepath.Join(root, "api", "src", "acme_api", "__init__.py"), "")
+	writeFile(t, filepath.Join(root, "scripts", "setup.py"), "from setuptools import setup\n")
+	writeFile(t, filepath.Join(root, "scripts", "src", "__init__.py"), "")
+	writeFile(t, filepath.Join(root, "api", ".venv", "lib", "dep", "pyproject.toml"), "[project]\nname = \"dep\"\n")
+	writeFile(t, filepath.Join(root, "env", "pyvenv.cfg"), "home = /usr/bin\n")
+	writeFile(t, filepath.Join(root, "env", "lib", "dep", "setup.py"), "")
+
+	projects, err := discoverPyProjects(root)
+	if err != nil {
+		t.Fatalf("discoverPyProjects returned error: %v", err)
+	}
+
+	want := []goModule{
+		{ModulePath: "acme-api", Dir: filepath.Join(root, "api", "src")},
+		{ModulePath: "scripts", Dir: filepath.Join(root, "scripts")},
+	}
+	if len(projects) != len(want) {
+		t.Fatalf("projects = %+v, want %+v", projects, want)
+	}
+	for i := range want {
+		if projects[i] != want[i] {
+			t.Errorf("projects[%d] = %+v, want %+v", i, projects[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// discoverTSProjects finds the TypeScript projects under root: every
+// directory with a tsconfig.json. A project is returned as a goModule whose
+// ModulePath is the "name" from the package.json next to the tsconfig, or
+// the directory relative to root when there is none.
+func discoverTSProjects(root string) ([]goModule, error) {
+	var projects []goModule
+
+	walkFn := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if name == "node_modules" || name == "vendor" {
+				return fs.SkipDir
+			}
+			if strings.HasPrefix(name, ".") && len(name) > 1 {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if d.Name() != "tsconfig.json" {
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		name, nameErr := tsProjectName(root, dir)
+		if nameErr != nil {
+			return nameErr
+		}
+		projects = append(projects, goModule{ModulePath: name, Dir: dir})
+		return nil
+	}
+
+	if err := filepath.WalkDir(root, walkFn); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Dir < projects[j].Dir })
+	return projects, nil
+}
+
+func tsProjectName(root, dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err == nil {
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if jsonErr := json.Unmarshal(data, &pkg); jsonErr != nil {
+			return "", fmt.Errorf("parse %s: %w", filepath.Join(dir, "package.json"), jsonErr)
+		}
+		if pkg.Name != "" {
+			return pkg.Name, nil
+		}
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", fmt.Errorf("relativize %s: %w", dir, err)
+	}
+	if rel == "." {
+		return filepath.Base(root), nil
+	}
+	return filepath.ToSlash(rel), nil
+}
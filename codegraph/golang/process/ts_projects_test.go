@@ -0,0 +1,44 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverTSProjects(t *testing.T) {
+	root := t.TempDir()
+
+	writeFile(t, filepath.Join(root, "web", "tsconfig.json"), "{}\n")
+	writeFile(t, filepath.Join(root, "web", "package.json"), `{"name": "@acme/web"}`)
+	writeFile(t, filepath.Join(root, "tools", "scripts", "tsconfig.json"), "{}\n")
+	writeFile(t, filepath.Join(root, "web", "node_modules", "dep", "tsconfig.json"), "{}\n")
+
+	projects, err := discoverTSProjects(root)
+	if err != nil {
+		t.Fatalf("discoverTSProjects returned error: %v", err)
+	}
+
+	want := []goModule{
+		{ModulePath: "tools/scripts", Dir: filepath.Join(root, "tools", "scripts")},
+		{ModulePath: "@acme/web", Dir: filepath.Join(root, "web")},
+	}
+	if len(projects) != len(want) {
+		t.Fatalf("projects = %+v, want %+v", projects, want)
+	}
+	for i := range want {
+		if projects[i] != want[i] {
+			t.Errorf("projects[%d] = %+v, want %+v", i, projects[i], want[i])
+		}
+	}
+}
+
+func writeFile(t *testing.T, filename, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", filepath.Dir(filename), err)
+	}
+	if err := os.WriteFile(filename, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write %s: %v", filename, err)
+	}
+}
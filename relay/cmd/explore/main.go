@@ -3,12 +3,15 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"basegraph.co/relay/common/arangodb"
 	"basegraph.co/relay/common/llm"
+	"basegraph.co/relay/core/bootstrap"
 	"basegraph.co/relay/internal/brain"
 	"github.com/joho/godotenv"
 )
@@ -16,16 +19,41 @@ import (
 func main() {
 	ctx := context.Background()
 
+	exportPath := flag.String("export-callgraph", "", "write the call graph as JSON lines (qname -> callees) to this file and exit")
+	namespace := flag.String("namespace", "", "only export callers whose qname starts with this prefix (used with -export-callgraph)")
+	thoroughness := flag.String("thoroughness", string(brain.ThoroughnessMedium), "exploration depth: quick, medium or thorough")
+	stream := flag.Bool("stream", false, "print the model's text to stderr as it is generated (openai provider only)")
+	jsonOut := flag.Bool("json", false, "print each report as a structured JSON object instead of markdown")
+	flag.Parse()
+
+	switch brain.Thoroughness(*thoroughness) {
+	case brain.ThoroughnessQuick, brain.ThoroughnessMedium, brain.ThoroughnessThorough:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid -thoroughness %q: want quick, medium or thorough\n", *thoroughness)
+		os.Exit(2)
+	}
+
 	// Load .env file (ignore error if not found)
 	_ = godotenv.Load()
 
+	if *exportPath != "" {
+		if err := exportCallGraph(ctx, *exportPath, *namespace); err != nil {
+			fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Repo config - defaults to relay codebase for easy testing
 	repoRoot := getEnv("REPO_ROOT", "/Users/nithin/basegraph/relay")
 	modulePath := getEnv("MODULE_PATH", "basegraph.co/relay")
 
 	// LLM client - uses EXPLORE_LLM_* env vars (consistent with worker)
 	provider := getEnv("EXPLORE_LLM_PROVIDER", "openai")
-	model := getEnv("EXPLORE_LLM_MODEL", "gpt-4o")
+	model := os.Getenv("EXPLORE_LLM_MODEL")
+	if model == "" && provider == llm.ProviderOpenAI {
+		model = "gpt-4o" // Anthropic picks its own default in llm.NewAnthropicClient
+	}
 	baseURL := os.Getenv("EXPLORE_BASE_URL")
 	apiKey := os.Getenv("EXPLORE_LLM_API_KEY")
 	if apiKey == "" {
@@ -45,36 +73,53 @@ func main() {
 	}
 
 	// ArangoDB client (optional - uses defaults matching config.go)
-	var arangoClient arangodb.Client
-	arangoURL := getEnv("ARANGO_URL", "http://localhost:8529")
-	arangoClient, err = arangodb.New(ctx, arangodb.Config{
-		URL:      arangoURL,
-		Username: getEnv("ARANGO_USERNAME", "root"),
-		Password: getEnv("ARANGO_PASSWORD", ""),
-		Database: getEnv("ARANGO_DATABASE", "codegraph"),
-	})
+	arangoClient, err := newArangoClient(ctx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Codegraph: disabled (%v)\n", err)
 		arangoClient = nil
 	} else {
-		if err := arangoClient.EnsureDatabase(ctx); err != nil {
-			fmt.Fprintf(os.Stderr, "Codegraph: disabled (%v)\n", err)
-			arangoClient = nil
-		} else {
-			fmt.Fprintf(os.Stderr, "Codegraph: connected (%s)\n", arangoURL)
-		}
+		fmt.Fprintf(os.Stderr, "Codegraph: connected (%s)\n", getEnv("ARANGO_URL", "http://localhost:8529"))
 	}
 
 	// Debug dir (optional)
 	debugDir := os.Getenv("BRAIN_DEBUG_DIR")
 	if debugDir != "" {
-		debugDir = brain.SetupDebugRunDir(debugDir)
+		retention, err := brain.ParseDebugRetention(os.Getenv("BRAIN_DEBUG_MAX_RUNS"), os.Getenv("BRAIN_DEBUG_MAX_MB"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		debugDir = brain.SetupDebugRunDir(debugDir, retention)
 		fmt.Fprintf(os.Stderr, "Debug logs: %s\n", debugDir)
 	}
 
 	// Create explore agent
-	tools := brain.NewExploreTools(repoRoot, arangoClient)
+	toolsCfg, err := brain.ParseExploreToolsConfig(os.Getenv("EXPLORE_BASH_ALLOWED_PREFIXES"), os.Getenv("EXPLORE_BASH_BLOCKED_PREFIXES"), os.Getenv("EXPLORE_BASH_TIMEOUT"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	toolsCfg.RepoID = os.Getenv("ARANGO_REPO_ID")
+	toolsCfg.SecretPatterns, err = brain.LoadSecretPatterns(os.Getenv("EXPLORE_SECRET_PATTERNS_FILE"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	rawQuery := os.Getenv("EXPLORE_RAW_QUERY") == "true"
+	if rawQuery && os.Getenv("ARANGO_RAW_QUERY_USERNAME") == "" {
+		fmt.Fprintln(os.Stderr, "Error: EXPLORE_RAW_QUERY requires a read-only ArangoDB user in ARANGO_RAW_QUERY_USERNAME")
+		os.Exit(1)
+	}
+	tools := brain.NewExploreTools(repoRoot, arangoClient, toolsCfg).
+		WithGitDisabled(os.Getenv("EXPLORE_DISABLE_GIT") == "true").
+		WithPathRedaction(os.Getenv("EXPLORE_REDACT_PATHS") == "true").
+		WithRawQuery(rawQuery)
 	explorer := brain.NewExploreAgent(agentClient, tools, modulePath, debugDir)
+	if *stream {
+		explorer = explorer.WithContentStream(func(delta string) {
+			fmt.Fprint(os.Stderr, delta)
+		})
+	}
 
 	// Mock mode support for A/B testing
 	mockFixtureFile := os.Getenv("MOCK_EXPLORE_FIXTURES")
@@ -123,7 +168,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "\nExploring: %s\n", query)
 		fmt.Fprintln(os.Stderr, "---")
 
-		report, err := explorer.Explore(ctx, query)
+		report, err := explore(ctx, explorer, query, brain.Thoroughness(*thoroughness), *jsonOut)
+		if *stream {
+			fmt.Fprintln(os.Stderr, "\n---")
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			continue
@@ -136,6 +184,79 @@ func main() {
 	fmt.Fprintln(os.Stderr, "Goodbye!")
 }
 
+// explore runs one exploration and returns the markdown report, or the
+// structured report as indented JSON when asJSON is set.
+func explore(ctx context.Context, explorer *brain.ExploreAgent, query string, thoroughness brain.Thoroughness, asJSON bool) (string, error) {
+	if !asJSON {
+		return explorer.Explore(ctx, query, thoroughness)
+	}
+
+	report, err := explorer.ExploreJSON(ctx, query, thoroughness)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode report: %w", err)
+	}
+	return string(data), nil
+}
+
+func newArangoClient(ctx context.Context) (arangodb.Client, error) {
+	return bootstrap.Connect(ctx, "arangodb", bootstrap.DefaultPolicy(), func(ctx context.Context) (arangodb.Client, error) {
+		client, err := arangodb.New(ctx, arangodb.Config{
+			URL:      getEnv("ARANGO_URL", "http://localhost:8529"),
+			Username: getEnv("ARANGO_USERNAME", "root"),
+			Password: getEnv("ARANGO_PASSWORD", ""),
+			Database: getEnv("ARANGO_DATABASE", "codegraph"),
+
+			RawQueryUsername: os.Getenv("ARANGO_RAW_QUERY_USERNAME"),
+			RawQueryPassword: os.Getenv("ARANGO_RAW_QUERY_PASSWORD"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := client.EnsureDatabase(ctx); err != nil {
+			client.Close()
+			return nil, err
+		}
+		return client, nil
+	})
+}
+
+// exportCallGraph writes one JSON object per caller so the file can be
+// processed line by line without loading the whole graph.
+func exportCallGraph(ctx context.Context, path, namespacePrefix string) error {
+	client, err := newArangoClient(ctx)
+	if err != nil {
+		return fmt.Errorf("connect arangodb: %w", err)
+	}
+	defer client.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	count := 0
+	err = client.ExportCallGraph(ctx, namespacePrefix, func(entry arangodb.CallGraphEntry) error {
+		count++
+		return enc.Encode(entry)
+	})
+	if err != nil {
+		return fmt.Errorf("export call graph: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush %s: %w", path, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d callers to %s\n", count, path)
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -13,8 +13,10 @@ import (
 	"basegraph.co/relay/common/id"
 	"basegraph.co/relay/common/logger"
 	"basegraph.co/relay/common/otel"
+	"basegraph.co/relay/core/bootstrap"
 	"basegraph.co/relay/core/config"
 	"basegraph.co/relay/core/db"
+	"basegraph.co/relay/internal/http/handler"
 	"basegraph.co/relay/internal/http/middleware"
 	httprouter "basegraph.co/relay/internal/http/router"
 	"basegraph.co/relay/internal/queue"
@@ -57,7 +59,11 @@ func main() {
 		os.Exit(1)
 	}
 
-	database, err := db.New(ctx, cfg.DB)
+	startup := bootstrap.DefaultPolicy()
+
+	database, err := bootstrap.Connect(ctx, "postgres", startup, func(ctx context.Context) (*db.DB, error) {
+		return db.New(ctx, cfg.DB)
+	})
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to connect to database", "error", err)
 		os.Exit(1)
@@ -72,7 +78,9 @@ func main() {
 	}
 
 	redisClient := redis.NewClient(redisOpts)
-	if err := redisClient.Ping(ctx).Err(); err != nil {
+	if err := bootstrap.Wait(ctx, "redis", startup, func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	}); err != nil {
 		slog.ErrorContext(ctx, "failed to connect to redis", "error", err)
 		os.Exit(1)
 	}
@@ -103,7 +111,11 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := setupRouter(cfg, services, redisClient)
+	router, err := setupRouter(cfg, services, database, redisClient)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to set up router", "error", err)
+		os.Exit(1)
+	}
 	server := &http.Server{
 		Addr:              ":" + cfg.Port,
 		Handler:           router,
@@ -143,8 +155,13 @@ func main() {
 	slog.InfoContext(shutdownCtx, "shutdown complete")
 }
 
-func setupRouter(cfg config.Config, services *service.Services, redisClient *redis.Client) *gin.Engine {
+func setupRouter(cfg config.Config, services *service.Services, database *db.DB, redisClient *redis.Client) (*gin.Engine, error) {
 	router := gin.New()
+	// ClientIP, which the rate limiter keys on, only reads X-Forwarded-For
+	// from these proxies; gin trusts every peer by default.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
 
 	// Order matters: OTel creates span → Recovery catches panics → Logger logs with trace context
 	if cfg.OTel.Enabled() {
@@ -153,15 +170,32 @@ func setupRouter(cfg config.Config, services *service.Services, redisClient *red
 	router.Use(middleware.Recovery())
 	router.Use(middleware.Logger())
 
+	var rateLimit *middleware.RateLimitConfig
+	if cfg.RateLimit.Enabled {
+		rateLimit = &middleware.RateLimitConfig{
+			Redis:             redisClient,
+			RequestsPerMinute: cfg.RateLimit.RequestsPerMinute,
+			Burst:             cfg.RateLimit.Burst,
+		}
+	}
+
 	httprouter.SetupRoutes(router, services, httprouter.RouterConfig{
 		DashboardURL:    cfg.DashboardURL,
 		IsProduction:    cfg.IsProduction(),
 		TraceHeaderName: cfg.Pipeline.TraceHeaderName,
 		AdminAPIKey:     cfg.AdminAPIKey,
 		RedisClient:     redisClient,
+		DLQ:             queue.NewDLQManager(redisClient, cfg.Pipeline.RedisStream, cfg.Pipeline.RedisDLQStream),
+		RateLimit:       rateLimit,
+		HealthChecks: map[string]handler.HealthCheck{
+			"postgres": database.Ping,
+			"redis": func(ctx context.Context) error {
+				return redisClient.Ping(ctx).Err()
+			},
+		},
 	})
 
-	return router
+	return router, nil
 }
 
 const banner = `
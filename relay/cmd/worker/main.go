@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -18,14 +20,19 @@ import (
 	"basegraph.co/relay/common/id"
 	"basegraph.co/relay/common/llm"
 	"basegraph.co/relay/common/logger"
+	"basegraph.co/relay/core/bootstrap"
 	"basegraph.co/relay/core/config"
 	"basegraph.co/relay/core/db"
 	"basegraph.co/relay/internal/brain"
+	"basegraph.co/relay/internal/http/handler"
+	"basegraph.co/relay/internal/http/middleware"
+	httprouter "basegraph.co/relay/internal/http/router"
 	"basegraph.co/relay/internal/model"
 	"basegraph.co/relay/internal/queue"
 	"basegraph.co/relay/internal/service/issue_tracker"
 	"basegraph.co/relay/internal/store"
 	"basegraph.co/relay/internal/worker"
+	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -59,7 +66,11 @@ func main() {
 		os.Exit(1)
 	}
 
-	database, err := db.New(ctx, cfg.DB)
+	startup := bootstrap.DefaultPolicy()
+
+	database, err := bootstrap.Connect(ctx, "postgres", startup, func(ctx context.Context) (*db.DB, error) {
+		return db.New(ctx, cfg.DB)
+	})
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to connect to database", "error", err)
 		os.Exit(1)
@@ -73,21 +84,25 @@ func main() {
 	}
 
 	redisClient := redis.NewClient(redisOpts)
-	if err := redisClient.Ping(ctx).Err(); err != nil {
+	if err := bootstrap.Wait(ctx, "redis", startup, func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	}); err != nil {
 		slog.ErrorContext(ctx, "failed to connect to redis", "error", err)
 		os.Exit(1)
 	}
 	slog.InfoContext(ctx, "redis connected", "stream", cfg.Pipeline.RedisStream)
 
 	consumer, err := queue.NewRedisConsumer(redisClient, queue.ConsumerConfig{
-		Stream:       cfg.Pipeline.RedisStream,
-		Group:        cfg.Pipeline.RedisGroup,
-		Consumer:     cfg.Pipeline.RedisConsumer,
-		DLQStream:    cfg.Pipeline.RedisDLQStream,
-		BatchSize:    1,
-		Block:        5 * time.Second,
-		MaxAttempts:  3,
-		RequeueDelay: time.Second,
+		Stream:           cfg.Pipeline.RedisStream,
+		Group:            cfg.Pipeline.RedisGroup,
+		Consumer:         cfg.Pipeline.RedisConsumer,
+		DLQStream:        cfg.Pipeline.RedisDLQStream,
+		BatchSize:        1,
+		Block:            5 * time.Second,
+		MaxAttempts:      3,
+		RequeueBaseDelay: time.Second,
+		RequeueMaxDelay:  30 * time.Second,
+		RequeueJitter:    0.5,
 	})
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to create consumer", "error", err)
@@ -122,13 +137,19 @@ func main() {
 		os.Exit(1)
 	}
 
-	exploreClient, err := llm.NewAgentClient(llm.Config{
+	exploreLLMCfg := llm.Config{
 		Provider:        cfg.ExploreLLM.Provider,
 		APIKey:          cfg.ExploreLLM.APIKey,
 		BaseURL:         cfg.ExploreLLM.BaseURL,
 		Model:           cfg.ExploreLLM.Model,
 		ReasoningEffort: llm.ReasoningEffort(cfg.ExploreLLM.ReasoningEffort),
-	})
+	}
+	// Fail explore calls fast while the provider keeps erroring, instead of
+	// spending each message's timeout on retries.
+	if os.Getenv("EXPLORE_LLM_BREAKER") == "true" {
+		exploreLLMCfg.Breaker = llm.DefaultBreakerConfig()
+	}
+	exploreClient, err := llm.NewAgentClient(exploreLLMCfg)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to create explore client", "error", err)
 		os.Exit(1)
@@ -213,18 +234,27 @@ func main() {
 
 	var arangoClient arangodb.Client
 	if cfg.ArangoDB.Enabled() {
-		arangoClient, err = arangodb.New(ctx, arangodb.Config{
-			URL:      cfg.ArangoDB.URL,
-			Username: cfg.ArangoDB.Username,
-			Password: cfg.ArangoDB.Password,
-			Database: cfg.ArangoDB.Database,
+		arangoClient, err = bootstrap.Connect(ctx, "arangodb", startup, func(ctx context.Context) (arangodb.Client, error) {
+			client, err := arangodb.New(ctx, arangodb.Config{
+				URL:      cfg.ArangoDB.URL,
+				Username: cfg.ArangoDB.Username,
+				Password: cfg.ArangoDB.Password,
+				Database: cfg.ArangoDB.Database,
+
+				RawQueryUsername: cfg.ArangoDB.RawQueryUsername,
+				RawQueryPassword: cfg.ArangoDB.RawQueryPassword,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("creating client: %w", err)
+			}
+			if err := client.EnsureDatabase(ctx); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("ensuring database: %w", err)
+			}
+			return client, nil
 		})
 		if err != nil {
-			slog.ErrorContext(ctx, "failed to create ArangoDB client", "error", err)
-			os.Exit(1)
-		}
-		if err := arangoClient.EnsureDatabase(ctx); err != nil {
-			slog.ErrorContext(ctx, "failed to ensure ArangoDB database", "error", err)
+			slog.ErrorContext(ctx, "failed to connect to ArangoDB", "error", err)
 			os.Exit(1)
 		}
 		slog.InfoContext(ctx, "arangodb connected", "database", cfg.ArangoDB.Database)
@@ -232,6 +262,20 @@ func main() {
 		slog.InfoContext(ctx, "arangodb disabled; codegraph unavailable")
 	}
 
+	healthChecks := map[string]handler.HealthCheck{
+		"postgres": database.Ping,
+		"redis": func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		},
+	}
+	if arangoClient != nil {
+		healthChecks["arangodb"] = func(ctx context.Context) error {
+			_, err := arangoClient.NodeCount(ctx)
+			return err
+		}
+	}
+	healthServer := startHealthServer(ctx, cfg, healthChecks)
+
 	if err := os.MkdirAll(repoRoot, 0o755); err != nil {
 		slog.ErrorContext(ctx, "failed to create repo root", "error", err, "path", repoRoot)
 		os.Exit(1)
@@ -246,6 +290,57 @@ func main() {
 		),
 	}
 
+	// Optional repo conventions snippet for the explore and spec prompts
+	var repoConventions string
+	if path := os.Getenv("REPO_CONVENTIONS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to read repo conventions file", "error", err, "path", path)
+			os.Exit(1)
+		}
+		repoConventions = string(data)
+		slog.InfoContext(ctx, "repo conventions loaded", "path", path, "bytes", len(data))
+	}
+
+	debugRetention, err := brain.ParseDebugRetention(os.Getenv("BRAIN_DEBUG_MAX_RUNS"), os.Getenv("BRAIN_DEBUG_MAX_MB"))
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid debug retention", "error", err)
+		os.Exit(1)
+	}
+
+	exploreToolsCfg, err := brain.ParseExploreToolsConfig(os.Getenv("EXPLORE_BASH_ALLOWED_PREFIXES"), os.Getenv("EXPLORE_BASH_BLOCKED_PREFIXES"), os.Getenv("EXPLORE_BASH_TIMEOUT"))
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid explore tools config", "error", err)
+		os.Exit(1)
+	}
+	if maxBytes := os.Getenv("EXPLORE_READ_MAX_FILE_BYTES"); maxBytes != "" {
+		n, err := strconv.ParseInt(maxBytes, 10, 64)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid EXPLORE_READ_MAX_FILE_BYTES", "error", err, "value", maxBytes)
+			os.Exit(1)
+		}
+		exploreToolsCfg.MaxReadFileBytes = n
+	}
+	if kinds := os.Getenv("EXPLORE_CODEGRAPH_KINDS"); kinds != "" {
+		exploreToolsCfg.CodegraphKinds = strings.Split(kinds, ",")
+	}
+	rawQuery := os.Getenv("EXPLORE_RAW_QUERY") == "true"
+	if rawQuery && cfg.ArangoDB.RawQueryUsername == "" {
+		slog.ErrorContext(ctx, "EXPLORE_RAW_QUERY requires a read-only ArangoDB user in ARANGO_RAW_QUERY_USERNAME")
+		os.Exit(1)
+	}
+	// One repo id per worker: issues don't say which repo they're about, so
+	// deployments sharing a graph database run a worker per repo.
+	exploreToolsCfg.RepoID = cfg.ArangoDB.RepoID
+	if exploreToolsCfg.RepoID != "" {
+		slog.InfoContext(ctx, "codegraph scoped to one repo", "repo_id", exploreToolsCfg.RepoID)
+	}
+	exploreToolsCfg.SecretPatterns, err = brain.LoadSecretPatterns(os.Getenv("EXPLORE_SECRET_PATTERNS_FILE"))
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid EXPLORE_SECRET_PATTERNS_FILE", "error", err)
+		os.Exit(1)
+	}
+
 	// TODO(cleanup): Remove DebugDir once product goes live.
 	// It creates debug_logs/YYYY-MM-DD/NNN/ folders for each worker run.
 	// Related: brain.SetupDebugRunDir, Planner.debugDir, ExploreAgent.debugDir
@@ -253,7 +348,157 @@ func main() {
 		RepoRoot:            repoRoot,
 		ModulePath:          modulePath,
 		DebugDir:            os.Getenv("BRAIN_DEBUG_DIR"),
+		DebugRetention:      debugRetention,
 		SpecGeneratorClient: specGeneratorClient,
+
+		ExploreConfidenceGating: os.Getenv("EXPLORE_CONFIDENCE_GATING") == "true",
+		ExploreGitDisabled:      os.Getenv("EXPLORE_DISABLE_GIT") == "true",
+		ExploreRedactPaths:      os.Getenv("EXPLORE_REDACT_PATHS") == "true",
+		ExploreRawQuery:         rawQuery,
+		ExploreTools:            exploreToolsCfg,
+		SpecFrontmatter:         os.Getenv("SPEC_FRONTMATTER") == "true",
+		RepoConventions:         repoConventions,
+	}
+
+	// Team-specific spec template (JSON: body, required, recommended sections).
+	if path := os.Getenv("SPEC_TEMPLATE_FILE"); path != "" {
+		tmpl, err := brain.LoadSpecTemplate(path)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to load spec template", "error", err, "path", path)
+			os.Exit(1)
+		}
+		orchestratorCfg.SpecTemplate = &tmpl
+		slog.InfoContext(ctx, "spec template loaded", "path", path, "required_sections", len(tmpl.Required))
+	}
+
+	// Spec generator locate budget. SPEC_LOCATE_MAX_CALLS=0 disables locate.
+	// Either variable may be set alone; the other keeps its default.
+	maxCalls, thoroughness := os.Getenv("SPEC_LOCATE_MAX_CALLS"), os.Getenv("SPEC_LOCATE_THOROUGHNESS")
+	if maxCalls != "" || thoroughness != "" {
+		allowance := brain.DefaultLocateAllowance()
+		if maxCalls != "" {
+			n, err := strconv.Atoi(maxCalls)
+			if err != nil {
+				slog.ErrorContext(ctx, "invalid SPEC_LOCATE_MAX_CALLS", "error", err, "value", maxCalls)
+				os.Exit(1)
+			}
+			allowance.MaxCalls = n
+		}
+		if thoroughness != "" {
+			switch brain.Thoroughness(thoroughness) {
+			case brain.ThoroughnessQuick, brain.ThoroughnessMedium, brain.ThoroughnessThorough:
+				allowance.Thoroughness = brain.Thoroughness(thoroughness)
+			default:
+				slog.ErrorContext(ctx, "invalid SPEC_LOCATE_THOROUGHNESS: want quick, medium or thorough", "value", thoroughness)
+				os.Exit(1)
+			}
+		}
+		orchestratorCfg.SpecLocateAllowance = &allowance
+	}
+
+	// Explore tool concurrency per turn. EXPLORE_TOOL_CONCURRENCY=1 runs calls in order.
+	if concurrency := os.Getenv("EXPLORE_TOOL_CONCURRENCY"); concurrency != "" {
+		n, err := strconv.Atoi(concurrency)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid EXPLORE_TOOL_CONCURRENCY", "error", err, "value", concurrency)
+			os.Exit(1)
+		}
+		orchestratorCfg.ExploreToolConcurrency = n
+	}
+
+	// Symbols a codegraph *name* retry may match before it's reported as too broad.
+	if maxCandidates := os.Getenv("EXPLORE_WILDCARD_MAX_CANDIDATES"); maxCandidates != "" {
+		n, err := strconv.Atoi(maxCandidates)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid EXPLORE_WILDCARD_MAX_CANDIDATES", "error", err, "value", maxCandidates)
+			os.Exit(1)
+		}
+		orchestratorCfg.ExploreWildcardMaxCandidates = n
+	}
+
+	// Tool-using iterations required before an explore report is accepted. Unset = 0.
+	if minIters := os.Getenv("EXPLORE_MIN_TOOL_ITERATIONS"); minIters != "" {
+		n, err := strconv.Atoi(minIters)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid EXPLORE_MIN_TOOL_ITERATIONS", "error", err, "value", minIters)
+			os.Exit(1)
+		}
+		orchestratorCfg.ExploreMinToolIterations = n
+	}
+
+	// Explore report cache keyed on query + HEAD commit.
+	// EXPLORE_CACHE=memory|redis enables it; EXPLORE_CACHE_TTL defaults to 24h.
+	if backend := os.Getenv("EXPLORE_CACHE"); backend != "" {
+		var ttl time.Duration
+		if raw := os.Getenv("EXPLORE_CACHE_TTL"); raw != "" {
+			ttl, err = time.ParseDuration(raw)
+			if err != nil {
+				slog.ErrorContext(ctx, "invalid EXPLORE_CACHE_TTL", "error", err, "value", raw)
+				os.Exit(1)
+			}
+		}
+		switch backend {
+		case "memory":
+			orchestratorCfg.ExploreCache = brain.NewMemoryExploreCache(ttl)
+		case "redis":
+			orchestratorCfg.ExploreCache = brain.NewRedisExploreCache(redisClient, ttl)
+		default:
+			slog.ErrorContext(ctx, "invalid EXPLORE_CACHE (want memory or redis)", "value", backend)
+			os.Exit(1)
+		}
+	}
+
+	// Persist every exploration's metrics for dashboards.
+	if os.Getenv("EXPLORE_METRICS_STORE") == "true" {
+		orchestratorCfg.ExploreMetricsSink = brain.NewStoreMetricsSink(stores.ExploreMetrics())
+	}
+
+	// Tool results kept verbatim once explore compacts its context. Unset = no compaction.
+	if keep := os.Getenv("EXPLORE_COMPACT_KEEP"); keep != "" {
+		n, err := strconv.Atoi(keep)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid EXPLORE_COMPACT_KEEP", "error", err, "value", keep)
+			os.Exit(1)
+		}
+		orchestratorCfg.ExploreCompactKeep = n
+	}
+
+	// Cap on code findings in the spec prompt. Unset = keep all.
+	if maxFindings := os.Getenv("SPEC_MAX_FINDINGS"); maxFindings != "" {
+		n, err := strconv.Atoi(maxFindings)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid SPEC_MAX_FINDINGS", "error", err, "value", maxFindings)
+			os.Exit(1)
+		}
+		orchestratorCfg.SpecMaxFindings = n
+	}
+
+	if maxLearnings := os.Getenv("SPEC_MAX_LEARNINGS"); maxLearnings != "" {
+		n, err := strconv.Atoi(maxLearnings)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid SPEC_MAX_LEARNINGS", "error", err, "value", maxLearnings)
+			os.Exit(1)
+		}
+		orchestratorCfg.SpecMaxLearnings = n
+	}
+
+	if maxTokens := os.Getenv("SPEC_MAX_CONTEXT_TOKENS"); maxTokens != "" {
+		n, err := strconv.Atoi(maxTokens)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid SPEC_MAX_CONTEXT_TOKENS", "error", err, "value", maxTokens)
+			os.Exit(1)
+		}
+		orchestratorCfg.SpecMaxContextTokens = n
+	}
+
+	// Past issues citing the same files, referenced in the spec prompt. Unset = off.
+	if related := os.Getenv("SPEC_RELATED_ISSUES"); related != "" {
+		n, err := strconv.Atoi(related)
+		if err != nil {
+			slog.ErrorContext(ctx, "invalid SPEC_RELATED_ISSUES", "error", err, "value", related)
+			os.Exit(1)
+		}
+		orchestratorCfg.SpecRelatedIssues = n
 	}
 
 	// Mock explore mode for A/B testing planner prompts
@@ -302,6 +547,7 @@ func main() {
 		stores.Integrations(),
 		stores.IntegrationConfigs(),
 		stores.Learnings(),
+		stores.SpecLocks(),
 		issueTrackers,
 	)
 
@@ -337,21 +583,34 @@ func main() {
 	cancel()
 
 	// Wait for in-flight work to complete with timeout
+	shutdownTimeout := 30 * time.Second
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+
 	shutdownComplete := make(chan struct{})
 	go func() {
-		reclaimer.Stop()
+		if err := reclaimer.Stop(shutdownCtx); err != nil {
+			slog.WarnContext(ctx, "reclaimer did not finish in-flight message", "error", err)
+		}
 		wg.Wait()
 		close(shutdownComplete)
 	}()
 
-	shutdownTimeout := 30 * time.Second
 	select {
 	case <-shutdownComplete:
 		slog.InfoContext(ctx, "graceful shutdown completed")
-	case <-time.After(shutdownTimeout):
+	case <-shutdownCtx.Done():
 		slog.WarnContext(ctx, "shutdown timeout exceeded, forcing exit", "timeout", shutdownTimeout)
 	}
 
+	if healthServer != nil {
+		healthCtx, healthCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := healthServer.Shutdown(healthCtx); err != nil {
+			slog.ErrorContext(ctx, "health server shutdown error", "error", err)
+		}
+		healthCancel()
+	}
+
 	// Close connections explicitly (defers will also run, but explicit close provides better logging)
 	slog.InfoContext(ctx, "closing database connection")
 	database.Close()
@@ -371,14 +630,45 @@ func main() {
 	slog.InfoContext(ctx, "shutdown complete")
 }
 
+// startHealthServer serves /healthz and /readyz on HEALTH_PORT so
+// orchestrators can probe the worker. Returns nil when the port is unset.
+func startHealthServer(ctx context.Context, cfg config.Config, checks map[string]handler.HealthCheck) *http.Server {
+	if cfg.HealthPort == "" {
+		slog.InfoContext(ctx, "health server disabled (no HEALTH_PORT)")
+		return nil
+	}
+
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	router := gin.New()
+	router.Use(middleware.Recovery())
+	httprouter.HealthRouter(router, handler.NewHealthHandler(checks, 0))
+
+	server := &http.Server{
+		Addr:              ":" + cfg.HealthPort,
+		Handler:           router,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		slog.InfoContext(ctx, "health server starting", "port", cfg.HealthPort)
+		// A dead probe listener shouldn't stop task processing
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.ErrorContext(ctx, "health server error", "error", err)
+		}
+	}()
+	return server
+}
+
 func checkExternalDependencies(ctx context.Context) error {
 	ctx = logger.WithLogFields(ctx, logger.LogFields{
 		Component: "relay.worker.deps",
 	})
 
 	type dep struct {
-		name string
-		args []string
+		name     string
+		args     []string
+		optional bool // Explore tools fall back to grep/find without it
 	}
 
 	deps := []dep{
@@ -386,11 +676,16 @@ func checkExternalDependencies(ctx context.Context) error {
 		{name: "ssh", args: []string{"-V"}},
 		{name: "ssh-keygen"},
 		{name: "ssh-keyscan"},
-		{name: "rg", args: []string{"--version"}},
+		{name: "rg", args: []string{"--version"}, optional: true},
+		{name: "fd", args: []string{"--version"}, optional: true},
 	}
 
 	for _, d := range deps {
 		if _, err := exec.LookPath(d.name); err != nil {
+			if d.optional {
+				slog.WarnContext(ctx, "optional dependency missing", "name", d.name)
+				continue
+			}
 			return fmt.Errorf("%s not found in PATH: %w", d.name, err)
 		}
 
@@ -573,6 +868,9 @@ func newMessageProcessor(consumer *queue.RedisConsumer, orchestrator *brain.Orch
 			return fmt.Errorf("unsupported task_type: %s", msg.TaskType)
 		}
 
+		if err := consumer.MarkProcessed(ctx, msg); err != nil {
+			slog.WarnContext(ctx, "failed to record processed message", "error", err)
+		}
 		if err := consumer.Ack(ctx, msg); err != nil {
 			slog.WarnContext(ctx, "failed to ack message", "error", err)
 		}
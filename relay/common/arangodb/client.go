@@ -7,15 +7,39 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
 	"github.com/arangodb/go-driver/v2/connection"
 )
 
 var ErrNotFound = errors.New("document not found")
 
+// Codegraph collections. Nodes are symbols and files; edges are relationships.
+var (
+	nodeCollections = []string{"functions", "types", "members", "files", "modules", "queues"}
+	edgeCollections = []string{"calls", "implements", "inherits", "returns", "param_of", "parent", "imports", "decorated_by", "async_enqueue", "async_dequeue"}
+
+	// graphEdgeDefinitions are the edge definitions of the codegraph graph.
+	// async_enqueue and async_dequeue link the functions that put work on a
+	// queue and take it off through a queues vertex.
+	graphEdgeDefinitions = []arangodb.EdgeDefinition{
+		{Collection: "calls", From: []string{"functions"}, To: []string{"functions"}},
+		{Collection: "implements", From: []string{"types"}, To: []string{"types"}},
+		{Collection: "inherits", From: []string{"types"}, To: []string{"types"}},
+		{Collection: "returns", From: []string{"functions"}, To: []string{"types"}},
+		{Collection: "param_of", From: []string{"types"}, To: []string{"functions"}},
+		{Collection: "parent", From: []string{"functions", "members"}, To: []string{"types", "files"}},
+		{Collection: "imports", From: []string{"files"}, To: []string{"modules"}},
+		{Collection: "decorated_by", From: []string{"functions", "types"}, To: []string{"functions"}},
+		{Collection: "async_enqueue", From: []string{"functions"}, To: []string{"queues"}},
+		{Collection: "async_dequeue", From: []string{"queues"}, To: []string{"functions"}},
+	}
+)
+
 type Client interface {
 	// Setup operations
 	EnsureDatabase(ctx context.Context) error
@@ -23,35 +47,90 @@ type Client interface {
 	EnsureGraph(ctx context.Context) error
 
 	// Write operations (for ingestion)
-	IngestNodes(ctx context.Context, collection string, nodes []Node) error
-	IngestEdges(ctx context.Context, collection string, edges []Edge) error
-	TruncateCollections(ctx context.Context) error
+	GraphWriter
+	BeginIngest(ctx context.Context) (IngestTx, error) // stream transaction over every codegraph collection
+
+	// Incremental ingestion
+	GetFileHashes(ctx context.Context) (map[string]string, error)  // content hash per file path; "" if not recorded
+	DeleteFileNodes(ctx context.Context, filepaths []string) error // nodes defined in filepaths and every edge touching them
+
+	// NodeCount returns the number of symbol nodes. Zero means the index
+	// hasn't been built (or is still being ingested).
+	NodeCount(ctx context.Context) (int64, error)
 
 	// Read operations (for explore agent)
-	GetCallers(ctx context.Context, qname string, depth int) ([]GraphNode, error)
-	GetCallees(ctx context.Context, qname string, depth int) ([]GraphNode, error)
+	GetCallers(ctx context.Context, qname string, depth int, page Page) ([]GraphNode, int, error)
+	GetCallees(ctx context.Context, qname string, depth int, page Page) ([]GraphNode, int, error)
 	FindCallPath(ctx context.Context, fromQName string, toQName string, maxDepth int) ([]GraphNode, error)
+	FindCallPaths(ctx context.Context, fromQName string, toQName string, opts CallPathOptions) (CallPaths, error)
 	GetChildren(ctx context.Context, qname string) ([]GraphNode, error)
 	GetImplementations(ctx context.Context, qname string) ([]GraphNode, error)
 	GetMethods(ctx context.Context, qname string) ([]GraphNode, error)
-	GetUsages(ctx context.Context, qname string) ([]GraphNode, error)
+	GetUsages(ctx context.Context, qname string, kind UsageKind) ([]GraphNode, error) // UsageAny for all kinds
 	GetInheritors(ctx context.Context, qname string) ([]GraphNode, error)
+	GetEmbedded(ctx context.Context, qname string) ([]GraphNode, error) // types qname embeds (outbound inherits)
 	TraverseFrom(ctx context.Context, qnames []string, opts TraversalOptions) ([]GraphNode, []GraphEdge, error)
+	GetDependents(ctx context.Context, opts DependentsOptions) (DependentsResult, error) // files importing a package; ErrNotFound if it isn't indexed
 
 	// Symbol discovery operations
 	GetFileSymbols(ctx context.Context, opts FileSymbolsOptions) ([]FileSymbol, error)
 	SearchSymbols(ctx context.Context, opts SearchOptions) ([]SearchResult, int, error) // returns results, total count, error
 	ResolveSymbol(ctx context.Context, opts SearchOptions) (ResolvedSymbol, error)      // returns single symbol or error
+	ResolveSymbols(ctx context.Context, opts []SearchOptions) ([]ResolveResult, error)  // ResolveSymbol for each of opts in one query
+
+	// RawQuery runs a custom AQL query that passes ValidateReadOnlyQuery.
+	RawQuery(ctx context.Context, query string) (RawQueryResult, error)
+
+	// Export operations (offline analysis)
+	ExportCallGraph(ctx context.Context, namespacePrefix string, emit func(CallGraphEntry) error) error
+
+	// ForRepo returns a client sharing this one's connection that reads and
+	// writes repoID's subgraph; see Config.RepoID.
+	ForRepo(repoID string) Client
 
 	// Utility
 	Close() error
 }
 
+// GraphWriter writes codegraph documents, either directly or inside an
+// IngestTx.
+type GraphWriter interface {
+	IngestNodes(ctx context.Context, collection string, nodes []Node) error
+	IngestEdges(ctx context.Context, collection string, edges []Edge) error
+	TruncateCollections(ctx context.Context) error
+}
+
+// IngestTx is a GraphWriter whose writes become visible together on Commit
+// and are discarded on Abort. ArangoDB rejects concurrent requests on one
+// stream transaction, so writes must not overlap. Unlike the client's own
+// writes, a document that fails to insert (other than a duplicate key) fails
+// the call, so the caller can Abort rather than commit a partial graph.
+type IngestTx interface {
+	GraphWriter
+	Commit(ctx context.Context) error
+	Abort(ctx context.Context) error
+}
+
 type Config struct {
 	URL      string
 	Username string
 	Password string
 	Database string
+	Retry    RetryPolicy // Retries of read queries; zero value means DefaultRetryPolicy
+
+	// RepoID scopes the client to one repo's subgraph when several repos are
+	// indexed into the same database: nodes and edges it writes are tagged
+	// with it and keyed by it, and its queries only see documents tagged with
+	// it. Empty means the untagged graph of a single-repo database.
+	RepoID string
+
+	// RawQueryUsername and RawQueryPassword are the credentials RawQuery runs
+	// as. Grant this user read-only access to the codegraph collections and
+	// nothing else: ValidateReadOnlyQuery is a first filter, the database's
+	// permissions are the boundary. Empty disables RawQuery, which never runs
+	// as Username.
+	RawQueryUsername string
+	RawQueryPassword string
 }
 
 func (c Config) Validate() error {
@@ -64,6 +143,9 @@ func (c Config) Validate() error {
 	if c.Database == "" {
 		return fmt.Errorf("arangodb database name is required")
 	}
+	if err := c.Retry.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -72,6 +154,10 @@ type client struct {
 	arangoClient arangodb.Client
 	db           arangodb.Database
 	cfg          Config
+	repoID       string // Subgraph this client reads and writes, see Config.RepoID
+
+	rawClient arangodb.Client   // Authenticated as Config.RawQueryUsername; nil when unset
+	rawDB     arangodb.Database // RawQuery's database handle, set by EnsureDatabase; nil disables RawQuery
 }
 
 func New(ctx context.Context, cfg Config) (Client, error) {
@@ -93,11 +179,34 @@ func New(ctx context.Context, cfg Config) (Client, error) {
 		conn:         conn,
 		arangoClient: arangoClient,
 		cfg:          cfg,
+		repoID:       cfg.RepoID,
+	}
+
+	if cfg.RawQueryUsername != "" {
+		rawConn := connection.NewHttp2Connection(connection.DefaultHTTP2ConfigurationWrapper(endpoint, true))
+		if err := rawConn.SetAuthentication(connection.NewBasicAuth(cfg.RawQueryUsername, cfg.RawQueryPassword)); err != nil {
+			return nil, fmt.Errorf("arangodb raw query auth: %w", err)
+		}
+		c.rawClient = arangodb.NewClient(rawConn)
 	}
 
 	return c, nil
 }
 
+// ForRepo returns a copy of the client scoped to repoID. Call it after
+// EnsureDatabase, since the copy keeps the database handle it had then.
+func (c *client) ForRepo(repoID string) Client {
+	scoped := *c
+	scoped.repoID = repoID
+	scoped.cfg.RepoID = repoID
+	return &scoped
+}
+
+// key returns the _key of qname's node in the client's subgraph.
+func (c *client) key(qname string) string {
+	return nodeKey(c.repoID, qname)
+}
+
 func (c *client) Close() error {
 	return nil
 }
@@ -126,6 +235,14 @@ func (c *client) EnsureDatabase(ctx context.Context) error {
 	}
 	c.db = db
 
+	if c.rawClient != nil {
+		rawDB, err := c.rawClient.GetDatabase(ctx, c.cfg.Database, nil)
+		if err != nil {
+			return fmt.Errorf("get database as raw query user: %w", err)
+		}
+		c.rawDB = rawDB
+	}
+
 	return nil
 }
 
@@ -134,9 +251,6 @@ func (c *client) EnsureCollections(ctx context.Context) error {
 		return fmt.Errorf("database not initialized, call EnsureDatabase first")
 	}
 
-	nodeCollections := []string{"functions", "types", "members", "files", "modules"}
-	edgeCollections := []string{"calls", "implements", "inherits", "returns", "param_of", "parent", "imports", "decorated_by"}
-
 	for _, name := range nodeCollections {
 		if err := c.ensureCollection(ctx, name, false); err != nil {
 			return err
@@ -192,6 +306,21 @@ func (c *client) ensureIndexes(ctx context.Context) error {
 		}
 	}
 
+	// Type index on members - for field usages in GetUsages
+	members, err := c.db.GetCollection(ctx, "members", nil)
+	if err != nil {
+		return fmt.Errorf("get collection members: %w", err)
+	}
+	_, isNew, err := members.EnsurePersistentIndex(ctx, []string{"type_qname"}, &arangodb.CreatePersistentIndexOptions{
+		Name: "idx_type_qname",
+	})
+	if err != nil {
+		return fmt.Errorf("ensure type_qname index on members: %w", err)
+	}
+	if isNew {
+		slog.InfoContext(ctx, "arangodb index created", "collection", "members", "index", "idx_type_qname")
+	}
+
 	return nil
 }
 
@@ -235,21 +364,12 @@ func (c *client) EnsureGraph(ctx context.Context) error {
 	}
 
 	if exists {
-		return nil
+		return c.ensureEdgeDefinitions(ctx, graphName)
 	}
 
 	graphDef := &arangodb.GraphDefinition{
-		Name: graphName,
-		EdgeDefinitions: []arangodb.EdgeDefinition{
-			{Collection: "calls", From: []string{"functions"}, To: []string{"functions"}},
-			{Collection: "implements", From: []string{"types"}, To: []string{"types"}},
-			{Collection: "inherits", From: []string{"types"}, To: []string{"types"}},
-			{Collection: "returns", From: []string{"functions"}, To: []string{"types"}},
-			{Collection: "param_of", From: []string{"types"}, To: []string{"functions"}},
-			{Collection: "parent", From: []string{"functions", "members"}, To: []string{"types", "files"}},
-			{Collection: "imports", From: []string{"files"}, To: []string{"modules"}},
-			{Collection: "decorated_by", From: []string{"functions", "types"}, To: []string{"functions"}},
-		},
+		Name:            graphName,
+		EdgeDefinitions: graphEdgeDefinitions,
 	}
 
 	_, err = c.db.CreateGraph(ctx, graphName, graphDef, nil)
@@ -261,24 +381,122 @@ func (c *client) EnsureGraph(ctx context.Context) error {
 	return nil
 }
 
+// ensureEdgeDefinitions adds the edge definitions a graph created by an
+// older version is missing; traversals over the graph ignore edge
+// collections it doesn't define.
+func (c *client) ensureEdgeDefinitions(ctx context.Context, graphName string) error {
+	graph, err := c.db.Graph(ctx, graphName, nil)
+	if err != nil {
+		return fmt.Errorf("get graph: %w", err)
+	}
+
+	defined := make(map[string]bool)
+	for _, def := range graph.EdgeDefinitions() {
+		defined[def.Collection] = true
+	}
+	for _, def := range graphEdgeDefinitions {
+		if defined[def.Collection] {
+			continue
+		}
+		if _, err := graph.CreateEdgeDefinition(ctx, def.Collection, def.From, def.To, nil); err != nil {
+			return fmt.Errorf("add edge definition %s: %w", def.Collection, err)
+		}
+		slog.InfoContext(ctx, "arangodb edge definition added", "graph", graphName, "collection", def.Collection)
+	}
+	return nil
+}
+
+func (c *client) NodeCount(ctx context.Context) (int64, error) {
+	if c.db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	var total int64
+	for _, name := range nodeCollections {
+		col, err := c.db.GetCollection(ctx, name, nil)
+		if shared.IsNotFound(err) {
+			continue // Not created until the first ingest
+		}
+		if err != nil {
+			return 0, fmt.Errorf("get collection %s: %w", name, err)
+		}
+		n, err := c.countRepoDocuments(ctx, col)
+		if err != nil {
+			return 0, fmt.Errorf("count collection %s: %w", name, err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// countRepoDocuments counts col's documents in the client's subgraph.
+func (c *client) countRepoDocuments(ctx context.Context, col arangodb.Collection) (int64, error) {
+	if c.repoID == "" {
+		return col.Count(ctx)
+	}
+
+	bindVars := map[string]any{"@col": col.Name()}
+	query := fmt.Sprintf(`
+		FOR d IN @@col
+			FILTER %s
+			COLLECT WITH COUNT INTO n
+			RETURN n
+	`, repoFilter(c.repoID, "d", bindVars))
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{BindVars: bindVars})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	var n int64
+	if cursor.HasMore() {
+		if _, err := cursor.ReadDocument(ctx, &n); err != nil {
+			return 0, fmt.Errorf("read document: %w", err)
+		}
+	}
+	return n, nil
+}
+
 func (c *client) TruncateCollections(ctx context.Context) error {
 	if c.db == nil {
 		return fmt.Errorf("database not initialized")
 	}
+	return truncateCollections(ctx, c.db, c.repoID)
+}
 
-	start := time.Now()
+// graphDB is what the write helpers need from a database or a stream
+// transaction.
+type graphDB interface {
+	arangodb.DatabaseCollection
+	arangodb.DatabaseQuery
+}
 
-	nodeCollections := []string{"functions", "types", "members", "files", "modules"}
-	edgeCollections := []string{"calls", "implements", "inherits", "returns", "param_of", "parent", "imports", "decorated_by"}
+// truncateCollections empties every codegraph collection, or with a repoID
+// removes only that repo's documents and leaves other repos' subgraphs alone.
+func truncateCollections(ctx context.Context, db graphDB, repoID string) error {
+	start := time.Now()
 
-	allCollections := append(nodeCollections, edgeCollections...)
+	allCollections := append(append([]string{}, nodeCollections...), edgeCollections...)
 
 	for _, name := range allCollections {
-		col, err := c.db.GetCollection(ctx, name, nil)
+		col, err := db.GetCollection(ctx, name, nil)
 		if err != nil {
 			return fmt.Errorf("get collection %s: %w", name, err)
 		}
 
+		if repoID != "" {
+			bindVars := map[string]any{"@col": name}
+			query := fmt.Sprintf("FOR d IN @@col FILTER %s REMOVE d IN @@col", repoFilter(repoID, "d", bindVars))
+			cursor, err := db.Query(ctx, query, &arangodb.QueryOptions{BindVars: bindVars})
+			if err != nil {
+				return fmt.Errorf("remove repo %s from %s: %w", repoID, name, err)
+			}
+			if err := cursor.Close(); err != nil {
+				return fmt.Errorf("remove repo %s from %s: %w", repoID, name, err)
+			}
+			continue
+		}
+
 		if err := col.Truncate(ctx); err != nil {
 			return fmt.Errorf("truncate collection %s: %w", name, err)
 		}
@@ -286,6 +504,7 @@ func (c *client) TruncateCollections(ctx context.Context) error {
 
 	slog.InfoContext(ctx, "arangodb collections truncated",
 		"collections", len(allCollections),
+		"repo_id", repoID,
 		"duration_ms", time.Since(start).Milliseconds())
 
 	return nil
@@ -298,13 +517,16 @@ func (c *client) IngestNodes(ctx context.Context, collection string, nodes []Nod
 	if c.db == nil {
 		return fmt.Errorf("database not initialized")
 	}
+	return ingestNodes(ctx, c.db, c.repoID, collection, nodes, false)
+}
 
+func ingestNodes(ctx context.Context, db arangodb.DatabaseCollection, repoID, collection string, nodes []Node, inTx bool) error {
 	if len(nodes) == 0 {
 		return nil
 	}
 
 	start := time.Now()
-	col, err := c.db.GetCollection(ctx, collection, nil)
+	col, err := db.GetCollection(ctx, collection, nil)
 	if err != nil {
 		return fmt.Errorf("get collection %s: %w", collection, err)
 	}
@@ -312,7 +534,7 @@ func (c *client) IngestNodes(ctx context.Context, collection string, nodes []Nod
 	docs := make([]map[string]any, len(nodes))
 	for i, node := range nodes {
 		doc := map[string]any{
-			"_key":      makeKey(node.QName),
+			"_key":      nodeKey(repoID, node.QName),
 			"qname":     node.QName,
 			"name":      node.Name,
 			"kind":      node.Kind,
@@ -330,9 +552,18 @@ func (c *client) IngestNodes(ctx context.Context, collection string, nodes []Nod
 		if node.TypeQName != "" {
 			doc["type_qname"] = node.TypeQName
 		}
+		if node.Tag != "" {
+			doc["tag"] = node.Tag
+		}
+		if node.Hash != "" {
+			doc["hash"] = node.Hash
+		}
 		if node.Signature != "" {
 			doc["signature"] = node.Signature
 		}
+		if repoID != "" {
+			doc["repo_id"] = repoID
+		}
 		docs[i] = doc
 	}
 
@@ -340,13 +571,8 @@ func (c *client) IngestNodes(ctx context.Context, collection string, nodes []Nod
 	if err != nil {
 		return fmt.Errorf("create documents: %w", err)
 	}
-
-	// Consume all responses (ignoring errors for duplicate keys)
-	for {
-		_, readErr := reader.Read()
-		if readErr != nil {
-			break
-		}
+	if err := readCreateResults(reader, inTx); err != nil {
+		return fmt.Errorf("create documents in %s: %w", collection, err)
 	}
 
 	slog.DebugContext(ctx, "arangodb nodes ingested",
@@ -364,13 +590,16 @@ func (c *client) IngestEdges(ctx context.Context, collection string, edges []Edg
 	if c.db == nil {
 		return fmt.Errorf("database not initialized")
 	}
+	return ingestEdges(ctx, c.db, c.repoID, collection, edges, false)
+}
 
+func ingestEdges(ctx context.Context, db arangodb.DatabaseCollection, repoID, collection string, edges []Edge, inTx bool) error {
 	if len(edges) == 0 {
 		return nil
 	}
 
 	start := time.Now()
-	col, err := c.db.GetCollection(ctx, collection, nil)
+	col, err := db.GetCollection(ctx, collection, nil)
 	if err != nil {
 		return fmt.Errorf("get collection %s: %w", collection, err)
 	}
@@ -381,27 +610,25 @@ func (c *client) IngestEdges(ctx context.Context, collection string, edges []Edg
 		toCol := nodeCollectionForKind(edge.ToKind)
 
 		docs[i] = map[string]any{
-			"_key":  makeEdgeKey(edge.From, edge.To),
-			"_from": fmt.Sprintf("%s/%s", fromCol, makeKey(edge.From)),
-			"_to":   fmt.Sprintf("%s/%s", toCol, makeKey(edge.To)),
+			"_key":  edgeKey(repoID, edge.From, edge.To),
+			"_from": fmt.Sprintf("%s/%s", fromCol, nodeKey(repoID, edge.From)),
+			"_to":   fmt.Sprintf("%s/%s", toCol, nodeKey(repoID, edge.To)),
 		}
 
 		for k, v := range edge.Properties {
 			docs[i][k] = v
 		}
+		if repoID != "" {
+			docs[i]["repo_id"] = repoID
+		}
 	}
 
 	reader, err := col.CreateDocuments(ctx, docs)
 	if err != nil {
 		return fmt.Errorf("create edge documents: %w", err)
 	}
-
-	// Consume all responses (ignoring errors for duplicate keys)
-	for {
-		_, readErr := reader.Read()
-		if readErr != nil {
-			break
-		}
+	if err := readCreateResults(reader, inTx); err != nil {
+		return fmt.Errorf("create edge documents in %s: %w", collection, err)
 	}
 
 	slog.DebugContext(ctx, "arangodb edges ingested",
@@ -412,37 +639,237 @@ func (c *client) IngestEdges(ctx context.Context, collection string, edges []Edg
 	return nil
 }
 
-func (c *client) GetCallers(ctx context.Context, qname string, depth int) ([]GraphNode, error) {
-	if depth <= 0 {
-		depth = 1
+// errUniqueConstraintViolated is ArangoDB's errorNum for an insert whose
+// _key already exists.
+const errUniqueConstraintViolated = 1210
+
+// createResultReader is the part of a CreateDocuments response reader that
+// readCreateResults needs.
+type createResultReader interface {
+	Read() (arangodb.CollectionDocumentCreateResponse, error)
+}
+
+// readCreateResults consumes every per-document result of a CreateDocuments
+// call. Duplicate keys are expected and skipped. Other per-document failures
+// are skipped too outside a transaction, but inside one (strict) the first is
+// returned so the caller aborts instead of committing a partial graph.
+func readCreateResults(reader createResultReader, strict bool) error {
+	for {
+		_, err := reader.Read()
+		if err == nil {
+			continue
+		}
+		if shared.IsNoMoreDocuments(err) {
+			return nil
+		}
+		if ok, _ := shared.IsArangoError(err); !ok {
+			if strict {
+				return err
+			}
+			return nil // Best effort outside a transaction
+		}
+		if strict && !shared.IsArangoErrorWithErrorNum(err, errUniqueConstraintViolated) {
+			return err
+		}
 	}
+}
 
-	query := `
-		FOR v IN 1..@depth INBOUND @start GRAPH "codegraph"
-			OPTIONS { edgeCollections: ["calls"] }
-			LIMIT 30
-			RETURN { qname: v.qname, name: v.name, kind: v.is_method ? "method" : v.kind, filepath: v.filepath, pos: v.pos, signature: v.signature }
-	`
+// BeginIngest starts a stream transaction that can write every codegraph
+// collection. Readers keep seeing the graph as it was until Commit. The
+// server caps a stream transaction's size and idle time
+// (--transaction.streaming-max-transaction-size, -idle-timeout), so very
+// large graphs may need a non-atomic rebuild.
+func (c *client) BeginIngest(ctx context.Context) (IngestTx, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	allCollections := append(append([]string{}, nodeCollections...), edgeCollections...)
+	tx, err := c.db.BeginTransaction(ctx, arangodb.TransactionCollections{Write: allCollections}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	slog.InfoContext(ctx, "arangodb ingest transaction started", "id", tx.ID())
+	return &ingestTx{tx: tx, repoID: c.repoID}, nil
+}
+
+// ingestTx runs the client's write operations inside a stream transaction.
+type ingestTx struct {
+	tx     arangodb.Transaction
+	repoID string
+}
+
+func (t *ingestTx) IngestNodes(ctx context.Context, collection string, nodes []Node) error {
+	return ingestNodes(ctx, t.tx, t.repoID, collection, nodes, true)
+}
+
+func (t *ingestTx) IngestEdges(ctx context.Context, collection string, edges []Edge) error {
+	return ingestEdges(ctx, t.tx, t.repoID, collection, edges, true)
+}
 
-	return c.executeTraversal(ctx, query, qname, depth)
+func (t *ingestTx) TruncateCollections(ctx context.Context) error {
+	return truncateCollections(ctx, t.tx, t.repoID)
 }
 
-func (c *client) GetCallees(ctx context.Context, qname string, depth int) ([]GraphNode, error) {
+func (t *ingestTx) Commit(ctx context.Context) error {
+	if err := t.tx.Commit(ctx, nil); err != nil {
+		return fmt.Errorf("commit transaction %s: %w", t.tx.ID(), err)
+	}
+	return nil
+}
+
+func (t *ingestTx) Abort(ctx context.Context) error {
+	if err := t.tx.Abort(ctx, nil); err != nil {
+		return fmt.Errorf("abort transaction %s: %w", t.tx.ID(), err)
+	}
+	return nil
+}
+
+// defaultCallPageSize is the page size for GetCallers/GetCallees when the
+// caller doesn't set one.
+const defaultCallPageSize = 30
+
+// GetCallers returns a page of the functions that reach qname through calls
+// edges only, ordered by file and position, and how many there are in total.
+func (c *client) GetCallers(ctx context.Context, qname string, depth int, page Page) ([]GraphNode, int, error) {
+	return c.executeCallTraversal(ctx, "INBOUND", qname, depth, page)
+}
+
+// GetCallees returns a page of the functions reached from qname through calls
+// edges only, ordered by file and position, and how many there are in total.
+func (c *client) GetCallees(ctx context.Context, qname string, depth int, page Page) ([]GraphNode, int, error) {
+	return c.executeCallTraversal(ctx, "OUTBOUND", qname, depth, page)
+}
+
+func (c *client) executeCallTraversal(ctx context.Context, direction, qname string, depth int, page Page) ([]GraphNode, int, error) {
+	if c.db == nil {
+		return nil, 0, fmt.Errorf("database not initialized")
+	}
 	if depth <= 0 {
 		depth = 1
 	}
+	if page.Limit <= 0 {
+		page.Limit = defaultCallPageSize
+	}
+	page.Offset = max(page.Offset, 0)
 
-	query := `
-		FOR v IN 1..@depth OUTBOUND @start GRAPH "codegraph"
-			OPTIONS { edgeCollections: ["calls"] }
-			LIMIT 30
+	start := time.Now()
+
+	// Nodes without a qname are unresolved external/stdlib references; they
+	// are filtered in the query so they don't count toward the total.
+	query := fmt.Sprintf(`
+		LET all_results = (
+			FOR v, e, p IN 1..@depth %s @start GRAPH "codegraph"
+				OPTIONS { edgeCollections: ["calls"] }
+				FILTER p.edges[* RETURN PARSE_IDENTIFIER(CURRENT._id).collection] ALL == "calls"
+				FILTER v.qname != null AND v.qname != ""
+				RETURN v
+		)
+		LET total = LENGTH(all_results)
+		LET limited = (
+			FOR v IN all_results
+			SORT v.filepath, v.pos, v.qname
+			LIMIT @offset, @limit
 			RETURN { qname: v.qname, name: v.name, kind: v.is_method ? "method" : v.kind, filepath: v.filepath, pos: v.pos, signature: v.signature }
-	`
+		)
+		RETURN { results: limited, total: total }
+	`, direction)
 
-	return c.executeTraversal(ctx, query, qname, depth)
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
+		BindVars: map[string]any{
+			"start":  fmt.Sprintf("functions/%s", c.key(qname)),
+			"depth":  depth,
+			"offset": page.Offset,
+			"limit":  page.Limit,
+		},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("execute query: %w", err)
+	}
+	defer cursor.Close()
+
+	var response struct {
+		Results []GraphNode `json:"results"`
+		Total   int         `json:"total"`
+	}
+	if cursor.HasMore() {
+		if _, err := cursor.ReadDocument(ctx, &response); err != nil {
+			return nil, 0, fmt.Errorf("read document: %w", err)
+		}
+	}
+
+	slog.DebugContext(ctx, "arangodb call traversal completed",
+		"qname", qname,
+		"direction", direction,
+		"depth", depth,
+		"results", len(response.Results),
+		"total", response.Total,
+		"duration_ms", time.Since(start).Milliseconds())
+
+	return response.Results, response.Total, nil
 }
 
+// callEdgeCollection is the only edge collection call traversals may follow.
+// Other edges (returns, param_of, parent, ...) connect the same vertices but
+// are not calls, so a path through them is not a call path.
+const callEdgeCollection = "calls"
+
+// asyncEdgeCollections link a function enqueuing work to a queues vertex and
+// the queue to the functions dequeuing it. Call paths follow them only when
+// asked to.
+var asyncEdgeCollections = []string{"async_enqueue", "async_dequeue"}
+
+// pathEdgeCollections returns the edge collections a call path may follow.
+func pathEdgeCollections(includeAsync bool) []string {
+	if includeAsync {
+		return append([]string{callEdgeCollection}, asyncEdgeCollections...)
+	}
+	return []string{callEdgeCollection}
+}
+
+// callPathRow is a traversal path as returned by the FindCallPath query.
+type callPathRow struct {
+	Vertices []GraphNode `json:"vertices"`
+	Edges    []string    `json:"edges"` // edge _ids, e.g. "calls/abc123"
+}
+
+// FindCallPath returns the shortest call chain from fromQName to toQName.
+// Only edges in the calls collection are followed: the traversal is limited
+// to it and every edge on the path is checked again, so edge collections
+// added to the graph later can't leak into call paths.
 func (c *client) FindCallPath(ctx context.Context, fromQName string, toQName string, maxDepth int) ([]GraphNode, error) {
+	paths, err := c.findCallPaths(ctx, fromQName, toQName, maxDepth, 1, pathEdgeCollections(false))
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+	return paths[0], nil
+}
+
+// FindCallPaths returns up to opts.MaxPaths distinct call chains from
+// fromQName to toQName, shortest first, following call edges only (see
+// FindCallPath) unless opts.IncludeAsync also lets them cross queues.
+// When there is none, it also looks for the closest partial chain: among the
+// functions reachable from fromQName, one that reaches toQName in more steps
+// than opts.MaxDepth allows, else the one whose qname shares the longest
+// prefix with toQName.
+func (c *client) FindCallPaths(ctx context.Context, fromQName string, toQName string, opts CallPathOptions) (CallPaths, error) {
+	collections := pathEdgeCollections(opts.IncludeAsync)
+	paths, err := c.findCallPaths(ctx, fromQName, toQName, opts.MaxDepth, max(opts.MaxPaths, 1), collections)
+	if err != nil || len(paths) > 0 {
+		return CallPaths{Paths: paths}, err
+	}
+	if fromQName == "" || toQName == "" {
+		return CallPaths{}, nil
+	}
+
+	partial, gap, err := c.findPartialCallPath(ctx, fromQName, toQName, opts.MaxDepth, collections)
+	if err != nil {
+		return CallPaths{}, err
+	}
+	return CallPaths{Partial: partial, PartialGap: gap}, nil
+}
+
+func (c *client) findCallPaths(ctx context.Context, fromQName string, toQName string, maxDepth int, maxPaths int, collections []string) ([][]GraphNode, error) {
 	if c.db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
@@ -458,58 +885,251 @@ func (c *client) FindCallPath(ctx context.Context, fromQName string, toQName str
 
 	start := time.Now()
 
-	startVertex := fmt.Sprintf("functions/%s", makeKey(fromQName))
-	targetVertex := fmt.Sprintf("functions/%s", makeKey(toQName))
+	startVertex := fmt.Sprintf("functions/%s", c.key(fromQName))
+	targetVertex := fmt.Sprintf("functions/%s", c.key(toQName))
 
 	query := `
 		FOR v, e, p IN 0..@depth OUTBOUND @start GRAPH "codegraph"
-			OPTIONS { edgeCollections: ["calls"], bfs: true, uniqueVertices: "path" }
+			OPTIONS { edgeCollections: @edgeCollections, bfs: true, uniqueVertices: "path" }
 			FILTER v._id == @target
-			LIMIT 1
-			RETURN p.vertices[* RETURN {
-				qname: CURRENT.qname,
-				name: CURRENT.name,
-				kind: CURRENT.is_method ? "method" : CURRENT.kind,
-				filepath: CURRENT.filepath,
-				pos: CURRENT.pos,
-				signature: CURRENT.signature
-			}]
+			FILTER p.edges[* RETURN PARSE_IDENTIFIER(CURRENT._id).collection] ALL IN @edgeCollections
+			LIMIT @limit
+			RETURN {
+				vertices: p.vertices[* RETURN {
+					qname: CURRENT.qname,
+					name: CURRENT.name,
+					kind: CURRENT.is_method ? "method" : CURRENT.kind,
+					filepath: CURRENT.filepath,
+					pos: CURRENT.pos,
+					signature: CURRENT.signature
+				}],
+				edges: p.edges[*]._id
+			}
 	`
 
-	cursor, err := c.db.Query(ctx, query, &arangodb.QueryOptions{BindVars: map[string]any{
-		"start":  startVertex,
-		"target": targetVertex,
-		"depth":  depth,
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{BindVars: map[string]any{
+		"start":           startVertex,
+		"target":          targetVertex,
+		"depth":           depth,
+		"limit":           maxPaths,
+		"edgeCollections": collections,
 	}})
 	if err != nil {
 		return nil, fmt.Errorf("execute query: %w", err)
 	}
 	defer cursor.Close()
 
-	var path []GraphNode
+	results, err := readCallPaths(ctx, cursor, maxPaths, collections)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "arangodb call path query completed",
+		"from", fromQName,
+		"to", toQName,
+		"depth", depth,
+		"paths", len(results),
+		"duration_ms", time.Since(start).Milliseconds())
+
+	return results, nil
+}
+
+// partialPathLimit caps how many vertices each direction of the partial
+// path search visits.
+const partialPathLimit = 2000
+
+// reachRow is a function that can reach the trace target, and in how many calls.
+type reachRow struct {
+	QName string `json:"qname"`
+	Dist  int    `json:"dist"`
+}
+
+// findPartialCallPath explores forward from fromQName and backward from
+// toQName (each up to maxDepth calls) and picks the closest partial path.
+func (c *client) findPartialCallPath(ctx context.Context, fromQName string, toQName string, maxDepth int, collections []string) ([]GraphNode, int, error) {
+	depth := maxDepth
+	if depth <= 0 {
+		depth = 4
+	}
+
+	query := `
+		LET forward = (
+			FOR v, e, p IN 1..@depth OUTBOUND @start GRAPH "codegraph"
+				OPTIONS { edgeCollections: @edgeCollections, bfs: true, uniqueVertices: "global" }
+				LIMIT @limit
+				RETURN {
+					vertices: p.vertices[* RETURN {
+						qname: CURRENT.qname,
+						name: CURRENT.name,
+						kind: CURRENT.is_method ? "method" : CURRENT.kind,
+						filepath: CURRENT.filepath,
+						pos: CURRENT.pos,
+						signature: CURRENT.signature
+					}],
+					edges: p.edges[*]._id
+				}
+		)
+		LET backward = (
+			FOR v, e, p IN 1..@depth INBOUND @target GRAPH "codegraph"
+				OPTIONS { edgeCollections: @edgeCollections, bfs: true, uniqueVertices: "global" }
+				LIMIT @limit
+				RETURN { qname: v.qname, dist: LENGTH(p.edges) }
+		)
+		RETURN { forward: forward, backward: backward }
+	`
+
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{BindVars: map[string]any{
+		"start":           fmt.Sprintf("functions/%s", c.key(fromQName)),
+		"target":          fmt.Sprintf("functions/%s", c.key(toQName)),
+		"depth":           depth,
+		"limit":           partialPathLimit,
+		"edgeCollections": collections,
+	}})
+	if err != nil {
+		return nil, 0, fmt.Errorf("execute partial path query: %w", err)
+	}
+	defer cursor.Close()
+
+	var row struct {
+		Forward  []callPathRow `json:"forward"`
+		Backward []reachRow    `json:"backward"`
+	}
 	if cursor.HasMore() {
-		_, err := cursor.ReadDocument(ctx, &path)
-		if err != nil {
-			return nil, fmt.Errorf("read document: %w", err)
+		if _, err := cursor.ReadDocument(ctx, &row); err != nil {
+			return nil, 0, fmt.Errorf("read document: %w", err)
 		}
 	}
 
-	results := make([]GraphNode, 0, len(path))
-	for _, node := range path {
+	path, gap := closestPartialPath(row.Forward, row.Backward, toQName, collections)
+	return path, gap, nil
+}
+
+// closestPartialPath picks, among paths from the trace start that only follow
+// edges in collections, the
+// one ending closest to toQName: first any path ending at a function known
+// to reach toQName (fewest remaining calls, then shortest path), otherwise
+// the path whose end shares the longest qname prefix with toQName. gap is
+// the remaining call count, or 0 when the end isn't known to reach toQName.
+func closestPartialPath(forward []callPathRow, backward []reachRow, toQName string, collections []string) (path []GraphNode, gap int) {
+	reaches := make(map[string]int, len(backward))
+	for _, r := range backward {
+		if d, ok := reaches[r.QName]; !ok || r.Dist < d {
+			reaches[r.QName] = r.Dist
+		}
+	}
+
+	var best []GraphNode
+	bestGap, bestPrefix := 0, -1
+	for _, row := range forward {
+		if !edgesWithin(row.Edges, collections) {
+			continue
+		}
+		nodes := knownVertices(row.Vertices)
+		if len(nodes) < 2 || nodes[len(nodes)-1].Kind == queueKind {
+			continue
+		}
+		end := nodes[len(nodes)-1].QName
+		if end == toQName {
+			continue
+		}
+
+		g, reachable := reaches[end]
+		prefix := commonPrefixLen(end, toQName)
+		better := false
+		switch {
+		case best == nil:
+			better = true
+		case reachable && bestGap == 0:
+			better = true
+		case reachable && (g < bestGap || (g == bestGap && len(nodes) < len(best))):
+			better = true
+		case !reachable && bestGap == 0 && prefix > bestPrefix:
+			better = true
+		}
+		if better {
+			best, bestPrefix = nodes, prefix
+			bestGap = 0
+			if reachable {
+				bestGap = g
+			}
+		}
+	}
+	return best, bestGap
+}
+
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// knownVertices drops vertices that weren't found (external references).
+func knownVertices(vertices []GraphNode) []GraphNode {
+	results := make([]GraphNode, 0, len(vertices))
+	for _, node := range vertices {
 		if node.QName == "" {
 			continue
 		}
 		results = append(results, node)
 	}
+	return results
+}
 
-	slog.DebugContext(ctx, "arangodb call path query completed",
-		"from", fromQName,
-		"to", toQName,
-		"depth", depth,
-		"nodes", len(results),
-		"duration_ms", time.Since(start).Milliseconds())
+// readCallPath returns the vertices of the first path whose edges are all
+// call edges, skipping vertices that weren't found (external references).
+func readCallPath(ctx context.Context, reader documentReader) ([]GraphNode, error) {
+	paths, err := readCallPaths(ctx, reader, 1, pathEdgeCollections(false))
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+	return paths[0], nil
+}
 
-	return results, nil
+// readCallPaths returns up to limit distinct paths that only follow edges in
+// collections, skipping vertices that weren't found. Paths that visit the
+// same vertices in the same order count once.
+func readCallPaths(ctx context.Context, reader documentReader, limit int, collections []string) ([][]GraphNode, error) {
+	var paths [][]GraphNode
+	seen := make(map[string]bool)
+	for reader.HasMore() && len(paths) < limit {
+		var row callPathRow
+		if _, err := reader.ReadDocument(ctx, &row); err != nil {
+			return nil, fmt.Errorf("read document: %w", err)
+		}
+		if !edgesWithin(row.Edges, collections) {
+			continue
+		}
+
+		nodes := knownVertices(row.Vertices)
+		qnames := make([]string, len(nodes))
+		for i, node := range nodes {
+			qnames[i] = node.QName
+		}
+		key := strings.Join(qnames, "\x00")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		paths = append(paths, nodes)
+	}
+	return paths, nil
+}
+
+func onlyCallEdges(edgeIDs []string) bool {
+	return edgesWithin(edgeIDs, pathEdgeCollections(false))
+}
+
+// edgesWithin reports whether every edge id belongs to one of collections.
+func edgesWithin(edgeIDs []string, collections []string) bool {
+	for _, id := range edgeIDs {
+		collection, _, _ := strings.Cut(id, "/")
+		if !slices.Contains(collections, collection) {
+			return false
+		}
+	}
+	return true
 }
 
 func (c *client) GetChildren(ctx context.Context, qname string) ([]GraphNode, error) {
@@ -542,19 +1162,122 @@ func (c *client) GetMethods(ctx context.Context, qname string) ([]GraphNode, err
 	return c.executeTraversalFrom(ctx, query, "types", qname, 1)
 }
 
-func (c *client) GetUsages(ctx context.Context, qname string) ([]GraphNode, error) {
+// usageSubqueries select the usages of @start (a types vertex) of one kind,
+// labelled with that kind. param_of edges run type -> function and returns
+// edges function -> type. Fields are members whose type is the type itself,
+// a pointer to it or a slice of either (@field_types); the field subquery
+// scans members, so it takes the repo filter as its format argument.
+var usageSubqueries = []struct {
+	kind  UsageKind
+	query string
+}{
+	{UsageParam, `FOR v IN 1..1 OUTBOUND @start GRAPH "codegraph" OPTIONS { edgeCollections: ["param_of"] }
+			RETURN { qname: v.qname, name: v.name, kind: v.is_method ? "method" : v.kind, filepath: v.filepath, pos: v.pos, signature: v.signature, usage: "param" }`},
+	{UsageReturn, `FOR v IN 1..1 INBOUND @start GRAPH "codegraph" OPTIONS { edgeCollections: ["returns"] }
+			RETURN { qname: v.qname, name: v.name, kind: v.is_method ? "method" : v.kind, filepath: v.filepath, pos: v.pos, signature: v.signature, usage: "return" }`},
+	{UsageField, `FOR v IN members FILTER v.kind == "member" AND v.type_qname IN @field_types AND %s
+			RETURN { qname: v.qname, name: v.name, kind: v.kind, filepath: v.filepath, pos: v.pos, usage: "field" }`},
+}
+
+// usagesQuery builds the GetUsages query for kind in repoID's subgraph and
+// its bind variables.
+func usagesQuery(repoID, qname string, kind UsageKind) (string, map[string]any, error) {
+	bindVars := map[string]any{}
+	var lets, names []string
+	for _, sub := range usageSubqueries {
+		if kind != UsageAny && kind != sub.kind {
+			continue
+		}
+		name := "u_" + string(sub.kind)
+		subquery := sub.query
+		if sub.kind == UsageField {
+			subquery = fmt.Sprintf(subquery, repoFilter(repoID, "v", bindVars))
+			bindVars["field_types"] = []string{qname, "*" + qname, "[]" + qname, "[]*" + qname}
+		} else {
+			bindVars["start"] = fmt.Sprintf("types/%s", nodeKey(repoID, qname))
+		}
+		lets = append(lets, fmt.Sprintf("LET %s = (\n\t\t\t%s\n\t\t)", name, subquery))
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("unknown usage kind %q", kind)
+	}
+	// UNION takes at least two arrays.
+	query := fmt.Sprintf("\n\t\t%s\n\t\tFOR u IN UNION(%s, [])\n\t\t\tRETURN u\n\t", strings.Join(lets, "\n\t\t"), strings.Join(names, ", "))
+	return query, bindVars, nil
+}
+
+// GetUsages returns the functions/methods taking or returning qname and the
+// struct fields of its type, each labelled with its UsageKind. kind narrows
+// the result to one kind of usage.
+func (c *client) GetUsages(ctx context.Context, qname string, kind UsageKind) ([]GraphNode, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	start := time.Now()
+	query, bindVars, err := usagesQuery(c.repoID, qname, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{BindVars: bindVars})
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+	defer cursor.Close()
+
+	var results []GraphNode
+	for cursor.HasMore() {
+		var doc struct {
+			QName     string `json:"qname"`
+			Name      string `json:"name"`
+			Kind      string `json:"kind"`
+			Filepath  string `json:"filepath"`
+			Pos       int    `json:"pos"`
+			Signature string `json:"signature"`
+			Usage     string `json:"usage"`
+		}
+		if _, err := cursor.ReadDocument(ctx, &doc); err != nil {
+			return nil, fmt.Errorf("read document: %w", err)
+		}
+		// Skip nodes that weren't found (external/stdlib references)
+		if doc.QName == "" {
+			continue
+		}
+		results = append(results, GraphNode{
+			QName:     doc.QName,
+			Name:      doc.Name,
+			Kind:      doc.Kind,
+			Filepath:  doc.Filepath,
+			Pos:       doc.Pos,
+			Signature: doc.Signature,
+			Usage:     UsageKind(doc.Usage),
+		})
+	}
+
+	slog.DebugContext(ctx, "arangodb usages completed",
+		"qname", qname,
+		"kind", kind,
+		"results", len(results),
+		"duration_ms", time.Since(start).Milliseconds())
+
+	return results, nil
+}
+
+func (c *client) GetInheritors(ctx context.Context, qname string) ([]GraphNode, error) {
 	query := `
 		FOR v IN 1..1 INBOUND @start GRAPH "codegraph"
-			OPTIONS { edgeCollections: ["param_of", "returns"] }
+			OPTIONS { edgeCollections: ["inherits"] }
 			RETURN { qname: v.qname, name: v.name, kind: v.is_method ? "method" : v.kind, filepath: v.filepath, pos: v.pos, signature: v.signature }
 	`
 
 	return c.executeTraversalFrom(ctx, query, "types", qname, 1)
 }
 
-func (c *client) GetInheritors(ctx context.Context, qname string) ([]GraphNode, error) {
+func (c *client) GetEmbedded(ctx context.Context, qname string) ([]GraphNode, error) {
 	query := `
-		FOR v IN 1..1 INBOUND @start GRAPH "codegraph"
+		FOR v IN 1..1 OUTBOUND @start GRAPH "codegraph"
 			OPTIONS { edgeCollections: ["inherits"] }
 			RETURN { qname: v.qname, name: v.name, kind: v.is_method ? "method" : v.kind, filepath: v.filepath, pos: v.pos, signature: v.signature }
 	`
@@ -562,8 +1285,104 @@ func (c *client) GetInheritors(ctx context.Context, qname string) ([]GraphNode,
 	return c.executeTraversalFrom(ctx, query, "types", qname, 1)
 }
 
-func (c *client) executeTraversal(ctx context.Context, query string, qname string, depth int) ([]GraphNode, error) {
-	return c.executeTraversalFrom(ctx, query, "functions", qname, depth)
+// GetDependents returns the files with an imports edge to the package, sorted
+// by path. With opts.File set, the package is the one that file belongs to.
+// Returns ErrNotFound when the package (or file) isn't in the graph.
+func (c *client) GetDependents(ctx context.Context, opts DependentsOptions) (DependentsResult, error) {
+	if c.db == nil {
+		return DependentsResult{}, fmt.Errorf("database not initialized")
+	}
+
+	start := time.Now()
+	pkg := opts.Package
+	if opts.File != "" {
+		var err error
+		pkg, err = c.filePackage(ctx, opts.File)
+		if err != nil {
+			return DependentsResult{}, err
+		}
+	}
+
+	query := `
+		LET module = DOCUMENT(@module)
+		RETURN {
+			found: module != null,
+			files: (
+				FOR f IN 1..1 INBOUND @module imports
+					SORT f.qname
+					RETURN { filepath: f.qname, namespace: f.namespace }
+			)
+		}
+	`
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
+		BindVars: map[string]any{"module": "modules/" + c.key(pkg)},
+	})
+	if err != nil {
+		return DependentsResult{}, fmt.Errorf("execute query: %w", err)
+	}
+	defer cursor.Close()
+
+	var response struct {
+		Found bool            `json:"found"`
+		Files []DependentFile `json:"files"`
+	}
+	if cursor.HasMore() {
+		if _, err := cursor.ReadDocument(ctx, &response); err != nil {
+			return DependentsResult{}, fmt.Errorf("read document: %w", err)
+		}
+	}
+	if !response.Found {
+		return DependentsResult{}, ErrNotFound
+	}
+
+	slog.DebugContext(ctx, "arangodb dependents query completed",
+		"package", pkg,
+		"results", len(response.Files),
+		"duration_ms", time.Since(start).Milliseconds())
+
+	return DependentsResult{Package: pkg, Files: response.Files}, nil
+}
+
+// filePackage returns the package of the file node matching path (exact or
+// suffix match).
+func (c *client) filePackage(ctx context.Context, path string) (string, error) {
+	// Suffix match on a path boundary; absolute paths only match exactly
+	pathPattern := "%/" + path
+	if strings.HasPrefix(path, "/") {
+		pathPattern = path
+	}
+	bindVars := map[string]any{
+		"path":        path,
+		"pathPattern": pathPattern,
+	}
+
+	query := fmt.Sprintf(`
+		FOR f IN files
+			FILTER f.qname == @path OR f.qname LIKE @pathPattern
+			FILTER %s
+			SORT LENGTH(f.qname)
+			LIMIT 1
+			RETURN f.namespace
+	`, repoFilter(c.repoID, "f", bindVars))
+
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
+		BindVars: bindVars,
+	})
+	if err != nil {
+		return "", fmt.Errorf("execute query: %w", err)
+	}
+	defer cursor.Close()
+
+	var pkg string
+	if cursor.HasMore() {
+		if _, err := cursor.ReadDocument(ctx, &pkg); err != nil {
+			return "", fmt.Errorf("read document: %w", err)
+		}
+	}
+	if pkg == "" {
+		return "", ErrNotFound
+	}
+	return pkg, nil
 }
 
 func (c *client) executeTraversalFrom(ctx context.Context, query string, collection string, qname string, depth int) ([]GraphNode, error) {
@@ -573,7 +1392,7 @@ func (c *client) executeTraversalFrom(ctx context.Context, query string, collect
 
 	start := time.Now()
 
-	startVertex := fmt.Sprintf("%s/%s", collection, makeKey(qname))
+	startVertex := fmt.Sprintf("%s/%s", collection, c.key(qname))
 
 	bindVars := map[string]any{
 		"start": startVertex,
@@ -583,7 +1402,7 @@ func (c *client) executeTraversalFrom(ctx context.Context, query string, collect
 		bindVars["depth"] = depth
 	}
 
-	cursor, err := c.db.Query(ctx, query, &arangodb.QueryOptions{
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
 		BindVars: bindVars,
 	})
 	if err != nil {
@@ -640,6 +1459,7 @@ func (c *client) TraverseFrom(ctx context.Context, qnames []string, opts Travers
 	start := time.Now()
 
 	direction := "OUTBOUND"
+	//exhaustive:ignore // DirectionOutbound keeps the default above
 	switch opts.Direction {
 	case DirectionInbound:
 		direction = "INBOUND"
@@ -659,7 +1479,7 @@ func (c *client) TraverseFrom(ctx context.Context, qnames []string, opts Travers
 
 	startVertices := make([]string, len(qnames))
 	for i, qname := range qnames {
-		startVertices[i] = fmt.Sprintf("functions/%s", makeKey(qname))
+		startVertices[i] = fmt.Sprintf("functions/%s", c.key(qname))
 	}
 
 	query := fmt.Sprintf(`
@@ -668,7 +1488,7 @@ func (c *client) TraverseFrom(ctx context.Context, qnames []string, opts Travers
 				RETURN { vertex: { qname: v.qname, name: v.name, kind: v.is_method ? "method" : v.kind }, edge: e }
 	`, direction, edgeFilter)
 
-	cursor, err := c.db.Query(ctx, query, &arangodb.QueryOptions{
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
 		BindVars: map[string]any{
 			"starts": startVertices,
 			"depth":  depth,
@@ -729,6 +1549,34 @@ func (c *client) TraverseFrom(ctx context.Context, qnames []string, opts Travers
 	return nodes, edges, nil
 }
 
+// nodeKey returns the _key of qname's node in repoID's subgraph. Unscoped
+// keys are plain makeKey, so single-repo graphs keep their keys.
+func nodeKey(repoID, qname string) string {
+	if repoID == "" {
+		return makeKey(qname)
+	}
+	return makeKey(repoID + "\x00" + qname)
+}
+
+// edgeKey is nodeKey for the edge from -> to.
+func edgeKey(repoID, from, to string) string {
+	if repoID == "" {
+		return makeEdgeKey(from, to)
+	}
+	return makeEdgeKey(repoID+"\x00"+from, to)
+}
+
+// repoFilter returns an AQL condition keeping the document v when it is in
+// repoID's subgraph, adding its bind variable to bindVars. It is "true" for
+// an unscoped client.
+func repoFilter(repoID, v string, bindVars map[string]any) string {
+	if repoID == "" {
+		return "true"
+	}
+	bindVars["repo_id"] = repoID
+	return v + ".repo_id == @repo_id"
+}
+
 func makeKey(qname string) string {
 	hash := md5.Sum([]byte(qname))
 	return hex.EncodeToString(hash[:])[:16]
@@ -752,6 +1600,8 @@ func nodeCollectionForKind(kind string) string {
 		return "files"
 	case "module", "package", "namespace":
 		return "modules"
+	case queueKind:
+		return "queues"
 	default:
 		return "functions"
 	}
@@ -792,12 +1642,14 @@ func (c *client) GetFileSymbols(ctx context.Context, opts FileSymbolsOptions) ([
 	// Query all collections for symbols in this file
 	// Note: is_method=true means it's a method, so we return "method" as kind for display
 	// Use suffix matching to handle relative vs absolute paths
+	bindVars := map[string]any{}
 	query := fmt.Sprintf(`
 		FOR doc IN UNION(
 			(FOR f IN functions FILTER f.filepath == @filepath OR f.filepath LIKE @pathPattern RETURN f),
 			(FOR t IN types FILTER t.filepath == @filepath OR t.filepath LIKE @pathPattern RETURN t),
 			(FOR m IN members FILTER m.filepath == @filepath OR m.filepath LIKE @pathPattern RETURN m)
 		)
+		FILTER %s
 		%s
 		SORT doc.pos ASC
 		RETURN { 
@@ -805,10 +1657,12 @@ func (c *client) GetFileSymbols(ctx context.Context, opts FileSymbolsOptions) ([
 			name: doc.name, 
 			kind: doc.is_method ? "method" : doc.kind, 
 			signature: doc.signature,
+			doc: doc.doc,
 			pos: doc.pos, 
-			end: doc.end 
+			end: doc.end,
+			parent_qname: FIRST(FOR p IN 1..1 OUTBOUND doc parent RETURN p.qname)
 		}
-	`, kindFilter)
+	`, repoFilter(c.repoID, "doc", bindVars), kindFilter)
 
 	// Create suffix pattern for matching: "%" + "/path/to/file.go"
 	pathPattern := "%" + filepath
@@ -817,15 +1671,13 @@ func (c *client) GetFileSymbols(ctx context.Context, opts FileSymbolsOptions) ([
 		pathPattern = filepath
 	}
 
-	bindVars := map[string]any{
-		"filepath":    filepath,
-		"pathPattern": pathPattern,
-	}
+	bindVars["filepath"] = filepath
+	bindVars["pathPattern"] = pathPattern
 	if opts.Kind != "" && opts.Kind != "method" && opts.Kind != "function" {
 		bindVars["kind"] = opts.Kind
 	}
 
-	cursor, err := c.db.Query(ctx, query, &arangodb.QueryOptions{
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
 		BindVars: bindVars,
 	})
 	if err != nil {
@@ -836,24 +1688,28 @@ func (c *client) GetFileSymbols(ctx context.Context, opts FileSymbolsOptions) ([
 	var results []FileSymbol
 	for cursor.HasMore() {
 		var doc struct {
-			QName     string `json:"qname"`
-			Name      string `json:"name"`
-			Kind      string `json:"kind"`
-			Signature string `json:"signature"`
-			Pos       int    `json:"pos"`
-			End       int    `json:"end"`
+			QName       string `json:"qname"`
+			Name        string `json:"name"`
+			Kind        string `json:"kind"`
+			Signature   string `json:"signature"`
+			Doc         string `json:"doc"`
+			Pos         int    `json:"pos"`
+			End         int    `json:"end"`
+			ParentQName string `json:"parent_qname"`
 		}
 		_, err := cursor.ReadDocument(ctx, &doc)
 		if err != nil {
 			return nil, fmt.Errorf("read document: %w", err)
 		}
 		results = append(results, FileSymbol{
-			QName:     doc.QName,
-			Name:      doc.Name,
-			Kind:      doc.Kind,
-			Signature: doc.Signature,
-			Pos:       doc.Pos,
-			End:       doc.End,
+			QName:       doc.QName,
+			Name:        doc.Name,
+			Kind:        doc.Kind,
+			Signature:   doc.Signature,
+			Doc:         doc.Doc,
+			Pos:         doc.Pos,
+			End:         doc.End,
+			ParentQName: doc.ParentQName,
 		})
 	}
 
@@ -875,17 +1731,11 @@ func (c *client) SearchSymbols(ctx context.Context, opts SearchOptions) ([]Searc
 
 	start := time.Now()
 
-	// Convert glob pattern to AQL LIKE pattern: * -> %
-	pattern := globToLike(opts.Name)
-
 	// Build dynamic filter clauses
-	var filters []string
-	bindVars := map[string]any{
-		"pattern": pattern,
-	}
+	bindVars := map[string]any{}
 
-	// Always filter by name pattern
-	filters = append(filters, "LIKE(doc.name, @pattern, true)")
+	// Always filter by name
+	filters := []string{searchNameFilter(opts, bindVars)}
 
 	// Handle kind filter - "method" is stored as kind="function" with is_method=true
 	if opts.Kind != "" {
@@ -916,10 +1766,19 @@ func (c *client) SearchSymbols(ctx context.Context, opts SearchOptions) ([]Searc
 		filters = append(filters, "doc.namespace == @namespace")
 		bindVars["namespace"] = opts.Namespace
 	}
+	if opts.QName != "" {
+		filters = append(filters, "doc.qname == @qname")
+		bindVars["qname"] = opts.QName
+	}
+	if c.repoID != "" {
+		filters = append(filters, repoFilter(c.repoID, "doc", bindVars))
+	}
 
 	filterClause := strings.Join(filters, " AND ")
+	bindVars["term"] = strings.ToLower(strings.ReplaceAll(opts.Name, "*", ""))
 
-	// Query with limit, but also get total count
+	// Query with limit, but also get total count. Exact name matches come
+	// first, then prefix matches, then the rest, so LIMIT keeps the best ones.
 	// Note: is_method=true means it's a method, so we return "method" as kind for display
 	query := fmt.Sprintf(`
 		LET all_results = (
@@ -934,13 +1793,16 @@ func (c *client) SearchSymbols(ctx context.Context, opts SearchOptions) ([]Searc
 		LET total = LENGTH(all_results)
 		LET limited = (
 			FOR doc IN all_results
-			SORT doc.filepath, doc.pos
+			LET name = LOWER(doc.name)
+			LET rank = name == @term ? 0 : (STARTS_WITH(name, @term) ? 1 : 2)
+			SORT rank, doc.filepath, doc.pos
 			LIMIT 30
 			RETURN {
 				qname: doc.qname,
 				name: doc.name,
 				kind: doc.is_method ? "method" : doc.kind,
 				signature: doc.signature,
+				doc: doc.doc,
 				filepath: doc.filepath,
 				pos: doc.pos
 			}
@@ -948,7 +1810,7 @@ func (c *client) SearchSymbols(ctx context.Context, opts SearchOptions) ([]Searc
 		RETURN { results: limited, total: total }
 	`, filterClause)
 
-	cursor, err := c.db.Query(ctx, query, &arangodb.QueryOptions{
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
 		BindVars: bindVars,
 	})
 	if err != nil {
@@ -962,6 +1824,7 @@ func (c *client) SearchSymbols(ctx context.Context, opts SearchOptions) ([]Searc
 			Name      string `json:"name"`
 			Kind      string `json:"kind"`
 			Signature string `json:"signature"`
+			Doc       string `json:"doc"`
 			Filepath  string `json:"filepath"`
 			Pos       int    `json:"pos"`
 		} `json:"results"`
@@ -982,6 +1845,7 @@ func (c *client) SearchSymbols(ctx context.Context, opts SearchOptions) ([]Searc
 			Name:      doc.Name,
 			Kind:      doc.Kind,
 			Signature: doc.Signature,
+			Doc:       doc.Doc,
 			Filepath:  doc.Filepath,
 			Pos:       doc.Pos,
 		}
@@ -1003,6 +1867,22 @@ func globToLike(pattern string) string {
 	return strings.ReplaceAll(pattern, "*", "%")
 }
 
+// searchNameFilter returns the AQL name clause for opts, adding its bind
+// variables to bindVars.
+func searchNameFilter(opts SearchOptions, bindVars map[string]any) string {
+	if opts.Exact {
+		bindVars["name"] = opts.Name
+		if opts.CaseInsensitive {
+			return "LOWER(doc.name) == LOWER(@name)"
+		}
+		return "doc.name == @name"
+	}
+	// Convert glob pattern to AQL LIKE pattern: * -> %
+	bindVars["pattern"] = globToLike(opts.Name)
+	bindVars["caseInsensitive"] = opts.CaseInsensitive
+	return "LIKE(doc.name, @pattern, @caseInsensitive)"
+}
+
 // ResolveSymbol finds a single symbol matching the query.
 // Returns AmbiguousSymbolError if multiple matches found (with up to 5 candidates).
 // Returns ErrNotFound if no matches found.
@@ -1012,6 +1892,11 @@ func (c *client) ResolveSymbol(ctx context.Context, opts SearchOptions) (Resolve
 		return ResolvedSymbol{}, fmt.Errorf("search symbols: %w", err)
 	}
 
+	return resolvedFromResults(opts.Name, results, total)
+}
+
+// resolvedFromResults turns a search for query into ResolveSymbol's result.
+func resolvedFromResults(query string, results []SearchResult, total int) (ResolvedSymbol, error) {
 	if total == 0 {
 		return ResolvedSymbol{}, ErrNotFound
 	}
@@ -1025,6 +1910,7 @@ func (c *client) ResolveSymbol(ctx context.Context, opts SearchOptions) (Resolve
 			Filepath:  r.Filepath,
 			Pos:       r.Pos,
 			Signature: r.Signature,
+			Doc:       r.Doc,
 		}, nil
 	}
 
@@ -1035,7 +1921,133 @@ func (c *client) ResolveSymbol(ctx context.Context, opts SearchOptions) (Resolve
 	}
 
 	return ResolvedSymbol{}, AmbiguousSymbolError{
-		Query:      opts.Name,
+		Query:      query,
 		Candidates: candidates,
+		Total:      total,
+	}
+}
+
+// ResolveSymbols resolves every entry of opts in a single query, with the
+// same matching as SearchSymbols. Results are in opts order; each holds the
+// symbol, ErrNotFound, or an AmbiguousSymbolError with up to 5 candidates.
+func (c *client) ResolveSymbols(ctx context.Context, opts []SearchOptions) ([]ResolveResult, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("database not initialized")
 	}
+	if len(opts) == 0 {
+		return nil, nil
+	}
+
+	start := time.Now()
+
+	queries := make([]map[string]any, len(opts))
+	for i, o := range opts {
+		queries[i] = map[string]any{
+			"name":             o.Name,
+			"pattern":          globToLike(o.Name),
+			"exact":            o.Exact,
+			"case_insensitive": o.CaseInsensitive,
+			"kind":             o.Kind,
+			"file":             o.File,
+			"absolute_file":    strings.HasPrefix(o.File, "/"),
+			"namespace":        o.Namespace,
+			"term":             strings.ToLower(strings.ReplaceAll(o.Name, "*", "")),
+		}
+	}
+
+	// The per-query filters mirror SearchSymbols' dynamic clauses.
+	bindVars := map[string]any{"queries": queries}
+	query := fmt.Sprintf(`
+		FOR q IN @queries
+			LET matches = (
+				FOR doc IN UNION(
+					(FOR f IN functions RETURN f),
+					(FOR t IN types RETURN t),
+					(FOR m IN members RETURN m)
+				)
+				FILTER q.exact
+					? (q.case_insensitive ? LOWER(doc.name) == LOWER(q.name) : doc.name == q.name)
+					: LIKE(doc.name, q.pattern, q.case_insensitive)
+				FILTER q.kind == "" OR (q.kind == "method"
+					? (doc.kind == 'function' AND doc.is_method == true)
+					: (q.kind == "function"
+						? (doc.kind == 'function' AND (doc.is_method == null OR doc.is_method == false))
+						: doc.kind == q.kind))
+				FILTER q.file == "" OR doc.filepath == q.file OR (NOT q.absolute_file AND LIKE(doc.filepath, CONCAT("%%", q.file)))
+				FILTER q.namespace == "" OR doc.namespace == q.namespace
+				FILTER %s
+				RETURN doc
+			)
+			LET candidates = (
+				FOR doc IN matches
+				LET name = LOWER(doc.name)
+				LET rank = name == q.term ? 0 : (STARTS_WITH(name, q.term) ? 1 : 2)
+				SORT rank, doc.filepath, doc.pos
+				LIMIT 5
+				RETURN {
+					qname: doc.qname,
+					name: doc.name,
+					kind: doc.is_method ? "method" : doc.kind,
+					signature: doc.signature,
+					doc: doc.doc,
+					filepath: doc.filepath,
+					pos: doc.pos
+				}
+			)
+			RETURN { results: candidates, total: LENGTH(matches) }
+	`, repoFilter(c.repoID, "doc", bindVars))
+
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
+		BindVars: bindVars,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+	defer cursor.Close()
+
+	out := make([]ResolveResult, 0, len(opts))
+	for cursor.HasMore() {
+		var response struct {
+			Results []struct {
+				QName     string `json:"qname"`
+				Name      string `json:"name"`
+				Kind      string `json:"kind"`
+				Signature string `json:"signature"`
+				Doc       string `json:"doc"`
+				Filepath  string `json:"filepath"`
+				Pos       int    `json:"pos"`
+			} `json:"results"`
+			Total int `json:"total"`
+		}
+		if _, err := cursor.ReadDocument(ctx, &response); err != nil {
+			return nil, fmt.Errorf("read document: %w", err)
+		}
+		if len(out) == len(opts) {
+			return nil, fmt.Errorf("resolve symbols: more results than queries")
+		}
+
+		results := make([]SearchResult, len(response.Results))
+		for i, doc := range response.Results {
+			results[i] = SearchResult{
+				QName:     doc.QName,
+				Name:      doc.Name,
+				Kind:      doc.Kind,
+				Signature: doc.Signature,
+				Doc:       doc.Doc,
+				Filepath:  doc.Filepath,
+				Pos:       doc.Pos,
+			}
+		}
+		symbol, err := resolvedFromResults(opts[len(out)].Name, results, response.Total)
+		out = append(out, ResolveResult{Symbol: symbol, Err: err})
+	}
+	if len(out) != len(opts) {
+		return nil, fmt.Errorf("resolve symbols: got %d results for %d queries", len(out), len(opts))
+	}
+
+	slog.DebugContext(ctx, "arangodb resolve symbols completed",
+		"queries", len(opts),
+		"duration_ms", time.Since(start).Milliseconds())
+
+	return out, nil
 }
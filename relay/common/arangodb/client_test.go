@@ -0,0 +1,504 @@
+package arangodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+)
+
+type fakePathReader struct {
+	rows []callPathRow
+}
+
+func (r *fakePathReader) HasMore() bool { return len(r.rows) > 0 }
+
+func (r *fakePathReader) ReadDocument(_ context.Context, result any) (arangodb.DocumentMeta, error) {
+	data, err := json.Marshal(r.rows[0])
+	if err != nil {
+		return arangodb.DocumentMeta{}, err
+	}
+	r.rows = r.rows[1:]
+	return arangodb.DocumentMeta{}, json.Unmarshal(data, result)
+}
+
+func TestReadCallPathIgnoresNonCallEdges(t *testing.T) {
+	t.Parallel()
+
+	handle := GraphNode{QName: "app/api.Handle", Name: "Handle", Kind: "function"}
+	user := GraphNode{QName: "app/model.User", Name: "User", Kind: "struct"}
+	save := GraphNode{QName: "app/store.Save", Name: "Save", Kind: "function"}
+
+	// Handle takes a User param and Save takes one too: a param_of edge joins
+	// them, but Handle never calls Save.
+	reader := &fakePathReader{rows: []callPathRow{
+		{
+			Vertices: []GraphNode{handle, user, save},
+			Edges:    []string{"param_of/1a2b", "param_of/3c4d"},
+		},
+	}}
+
+	path, err := readCallPath(context.Background(), reader)
+	if err != nil {
+		t.Fatalf("readCallPath: %v", err)
+	}
+	if len(path) != 0 {
+		t.Fatalf("expected no call path through param_of edges, got %+v", path)
+	}
+}
+
+func TestReadCallPathReturnsFirstCallOnlyPath(t *testing.T) {
+	t.Parallel()
+
+	handle := GraphNode{QName: "app/api.Handle", Name: "Handle", Kind: "function"}
+	create := GraphNode{QName: "app/svc.Create", Name: "Create", Kind: "function"}
+	save := GraphNode{QName: "app/store.Save", Name: "Save", Kind: "function"}
+
+	reader := &fakePathReader{rows: []callPathRow{
+		{Vertices: []GraphNode{handle, save}, Edges: []string{"param_of/1a2b"}},
+		{Vertices: []GraphNode{handle, create, {}, save}, Edges: []string{"calls/1", "calls/2", "calls/3"}},
+	}}
+
+	path, err := readCallPath(context.Background(), reader)
+	if err != nil {
+		t.Fatalf("readCallPath: %v", err)
+	}
+
+	want := []string{handle.QName, create.QName, save.QName}
+	if len(path) != len(want) {
+		t.Fatalf("path = %+v, want qnames %v", path, want)
+	}
+	for i, node := range path {
+		if node.QName != want[i] {
+			t.Errorf("path[%d] = %s, want %s", i, node.QName, want[i])
+		}
+	}
+}
+
+func TestReadCallPathsDedupesAndLimits(t *testing.T) {
+	t.Parallel()
+
+	handle := GraphNode{QName: "app/api.Handle", Kind: "function"}
+	create := GraphNode{QName: "app/svc.Create", Kind: "function"}
+	update := GraphNode{QName: "app/svc.Update", Kind: "function"}
+	save := GraphNode{QName: "app/store.Save", Kind: "function"}
+
+	// Handle calls Create from two call sites: two edges, one path.
+	reader := &fakePathReader{rows: []callPathRow{
+		{Vertices: []GraphNode{handle, create, save}, Edges: []string{"calls/1", "calls/2"}},
+		{Vertices: []GraphNode{handle, create, save}, Edges: []string{"calls/3", "calls/2"}},
+		{Vertices: []GraphNode{handle, user(), save}, Edges: []string{"param_of/1", "param_of/2"}},
+		{Vertices: []GraphNode{handle, update, save}, Edges: []string{"calls/4", "calls/5"}},
+		{Vertices: []GraphNode{handle, update, create, save}, Edges: []string{"calls/4", "calls/6", "calls/2"}},
+	}}
+
+	paths, err := readCallPaths(context.Background(), reader, 2, pathEdgeCollections(false))
+	if err != nil {
+		t.Fatalf("readCallPaths: %v", err)
+	}
+
+	want := [][]string{
+		{handle.QName, create.QName, save.QName},
+		{handle.QName, update.QName, save.QName},
+	}
+	if got := pathQNames(paths); !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+	if !reader.HasMore() {
+		t.Error("expected reading to stop once the limit was reached")
+	}
+}
+
+func TestReadCallPathsFollowsAsyncEdgesOnlyWhenIncluded(t *testing.T) {
+	t.Parallel()
+
+	webhook := GraphNode{QName: "app/api.HandleWebhook", Kind: "function"}
+	tasks := GraphNode{QName: "tasks", Kind: queueKind}
+	process := GraphNode{QName: "app/worker.process", Kind: "function"}
+	rows := []callPathRow{
+		{Vertices: []GraphNode{webhook, tasks, process}, Edges: []string{"async_enqueue/1", "async_dequeue/2"}},
+	}
+
+	paths, err := readCallPaths(context.Background(), &fakePathReader{rows: rows}, 1, pathEdgeCollections(false))
+	if err != nil {
+		t.Fatalf("readCallPaths: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("paths = %v, want none without async edges", pathQNames(paths))
+	}
+
+	paths, err = readCallPaths(context.Background(), &fakePathReader{rows: rows}, 1, pathEdgeCollections(true))
+	if err != nil {
+		t.Fatalf("readCallPaths: %v", err)
+	}
+	want := [][]string{{webhook.QName, tasks.QName, process.QName}}
+	if got := pathQNames(paths); !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+}
+
+func TestClosestPartialPath(t *testing.T) {
+	t.Parallel()
+
+	handle := GraphNode{QName: "app/api.Handle", Kind: "function"}
+	queue := GraphNode{QName: "app/jobs.Enqueue", Kind: "function"}
+	store := GraphNode{QName: "app/store.Open", Kind: "function"}
+	dispatch := GraphNode{QName: "app/jobs.Dispatch", Kind: "function"}
+	run := GraphNode{QName: "app/jobs.Run", Kind: "function"}
+	const target = "app/store.Save"
+
+	forward := []callPathRow{
+		{Vertices: []GraphNode{handle, queue}, Edges: []string{"calls/1"}},
+		{Vertices: []GraphNode{handle, store}, Edges: []string{"calls/2"}},
+		{Vertices: []GraphNode{handle, queue, dispatch}, Edges: []string{"calls/1", "calls/3"}},
+		{Vertices: []GraphNode{handle, queue, dispatch, run}, Edges: []string{"calls/1", "calls/3", "calls/4"}},
+		{Vertices: []GraphNode{handle, user()}, Edges: []string{"param_of/1"}},
+	}
+
+	t.Run("prefers a function that reaches the target", func(t *testing.T) {
+		backward := []reachRow{{QName: run.QName, Dist: 2}, {QName: dispatch.QName, Dist: 3}}
+		path, gap := closestPartialPath(forward, backward, target, pathEdgeCollections(false))
+		want := [][]string{{handle.QName, queue.QName, dispatch.QName, run.QName}}
+		if got := pathQNames([][]GraphNode{path}); !reflect.DeepEqual(got, want) || gap != 2 {
+			t.Errorf("path = %v (gap %d), want %v (gap 2)", got, gap, want)
+		}
+	})
+
+	t.Run("falls back to the longest qname prefix", func(t *testing.T) {
+		path, gap := closestPartialPath(forward, nil, target, pathEdgeCollections(false))
+		want := [][]string{{handle.QName, store.QName}}
+		if got := pathQNames([][]GraphNode{path}); !reflect.DeepEqual(got, want) || gap != 0 {
+			t.Errorf("path = %v (gap %d), want %v (gap 0)", got, gap, want)
+		}
+	})
+
+	t.Run("nothing reachable", func(t *testing.T) {
+		if path, _ := closestPartialPath(nil, nil, target, pathEdgeCollections(false)); path != nil {
+			t.Errorf("path = %+v, want nil", path)
+		}
+	})
+}
+
+func user() GraphNode {
+	return GraphNode{QName: "app/model.User", Name: "User", Kind: "struct"}
+}
+
+func pathQNames(paths [][]GraphNode) [][]string {
+	var out [][]string
+	for _, path := range paths {
+		qnames := make([]string, len(path))
+		for i, node := range path {
+			qnames[i] = node.QName
+		}
+		out = append(out, qnames)
+	}
+	return out
+}
+
+func TestOnlyCallEdges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		edges []string
+		want  bool
+	}{
+		{nil, true},
+		{[]string{"calls/1"}, true},
+		{[]string{"calls/1", "returns/2"}, false},
+		{[]string{"param_of/1"}, false},
+		{[]string{"callsx/1"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := onlyCallEdges(tt.edges); got != tt.want {
+			t.Errorf("onlyCallEdges(%v) = %v, want %v", tt.edges, got, tt.want)
+		}
+	}
+}
+
+func TestSearchNameFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     SearchOptions
+		want     string
+		wantBind map[string]any
+	}{
+		{
+			name:     "glob, case-sensitive",
+			opts:     SearchOptions{Name: "Issue*"},
+			want:     "LIKE(doc.name, @pattern, @caseInsensitive)",
+			wantBind: map[string]any{"pattern": "Issue%", "caseInsensitive": false},
+		},
+		{
+			name:     "glob, case-insensitive",
+			opts:     SearchOptions{Name: "issue", CaseInsensitive: true},
+			want:     "LIKE(doc.name, @pattern, @caseInsensitive)",
+			wantBind: map[string]any{"pattern": "issue", "caseInsensitive": true},
+		},
+		{
+			name:     "exact keeps wildcards literal",
+			opts:     SearchOptions{Name: "Issue*", Exact: true},
+			want:     "doc.name == @name",
+			wantBind: map[string]any{"name": "Issue*"},
+		},
+		{
+			name:     "exact, case-insensitive",
+			opts:     SearchOptions{Name: "issue", Exact: true, CaseInsensitive: true},
+			want:     "LOWER(doc.name) == LOWER(@name)",
+			wantBind: map[string]any{"name": "issue"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			bind := map[string]any{}
+			if got := searchNameFilter(tt.opts, bind); got != tt.want {
+				t.Errorf("searchNameFilter() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(bind, tt.wantBind) {
+				t.Errorf("bind vars = %v, want %v", bind, tt.wantBind)
+			}
+		})
+	}
+}
+
+func TestUsagesQuery(t *testing.T) {
+	t.Parallel()
+
+	const qname = "app/model.Issue"
+	start := "types/" + makeKey(qname)
+	fieldTypes := []string{qname, "*" + qname, "[]" + qname, "[]*" + qname}
+
+	tests := []struct {
+		name     string
+		kind     UsageKind
+		want     []string
+		wantNot  []string
+		wantBind map[string]any
+	}{
+		{
+			name:     "any",
+			kind:     UsageAny,
+			want:     []string{`OUTBOUND @start GRAPH "codegraph" OPTIONS { edgeCollections: ["param_of"] }`, `INBOUND @start GRAPH "codegraph" OPTIONS { edgeCollections: ["returns"] }`, "FOR v IN members", "UNION(u_param, u_return, u_field, [])"},
+			wantBind: map[string]any{"start": start, "field_types": fieldTypes},
+		},
+		{
+			name:     "param only",
+			kind:     UsageParam,
+			want:     []string{`usage: "param"`, "UNION(u_param, [])"},
+			wantNot:  []string{"returns", "members"},
+			wantBind: map[string]any{"start": start},
+		},
+		{
+			name:     "field only",
+			kind:     UsageField,
+			want:     []string{`v.type_qname IN @field_types`, "UNION(u_field, [])"},
+			wantNot:  []string{"@start"},
+			wantBind: map[string]any{"field_types": fieldTypes},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			query, bind, err := usagesQuery("", qname, tt.kind)
+			if err != nil {
+				t.Fatalf("usagesQuery() error = %v", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(query, s) {
+					t.Errorf("query missing %q:\n%s", s, query)
+				}
+			}
+			for _, s := range tt.wantNot {
+				if strings.Contains(query, s) {
+					t.Errorf("query contains %q:\n%s", s, query)
+				}
+			}
+			if !reflect.DeepEqual(bind, tt.wantBind) {
+				t.Errorf("bind vars = %v, want %v", bind, tt.wantBind)
+			}
+		})
+	}
+
+	if _, _, err := usagesQuery("", qname, "embed"); err == nil {
+		t.Error("usagesQuery() with an unknown kind: want error")
+	}
+
+	query, bind, err := usagesQuery("repo-a", qname, UsageAny)
+	if err != nil {
+		t.Fatalf("usagesQuery(repo-a) error = %v", err)
+	}
+	if !strings.Contains(query, "v.type_qname IN @field_types AND v.repo_id == @repo_id") {
+		t.Errorf("scoped field query missing repo filter:\n%s", query)
+	}
+	if bind["repo_id"] != "repo-a" || bind["start"] != "types/"+nodeKey("repo-a", qname) {
+		t.Errorf("scoped bind vars = %v", bind)
+	}
+}
+
+func TestNodeKeyIsPerRepo(t *testing.T) {
+	t.Parallel()
+
+	const qname = "app/store.Save"
+	if got := nodeKey("", qname); got != makeKey(qname) {
+		t.Errorf("unscoped nodeKey = %q, want makeKey %q", got, makeKey(qname))
+	}
+	if nodeKey("repo-a", qname) == nodeKey("repo-b", qname) || nodeKey("repo-a", qname) == makeKey(qname) {
+		t.Errorf("nodeKey doesn't separate repos: %q", nodeKey("repo-a", qname))
+	}
+	if edgeKey("repo-a", "a.F", "a.G") == edgeKey("repo-b", "a.F", "a.G") {
+		t.Error("edgeKey doesn't separate repos")
+	}
+}
+
+// repoTransport serves cursor requests from symbols, applying the
+// repo_id filter when the query carries one.
+type repoTransport struct {
+	symbols []map[string]any // Each with a repo_id
+}
+
+func (t *repoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/_api/database/current"):
+		return jsonResponse(req, http.StatusOK, `{"error":false,"code":200,"result":{"name":"codegraph","id":"1","path":"","isSystem":false}}`), nil
+	case strings.HasSuffix(req.URL.Path, "/_api/cursor"):
+		var body struct {
+			Query    string         `json:"query"`
+			BindVars map[string]any `json:"bindVars"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		var results []map[string]any
+		for _, s := range t.symbols {
+			if strings.Contains(body.Query, "doc.repo_id == @repo_id") && s["repo_id"] != body.BindVars["repo_id"] {
+				continue
+			}
+			if strings.Contains(body.Query, "doc.qname == @qname") && s["qname"] != body.BindVars["qname"] {
+				continue
+			}
+			results = append(results, s)
+		}
+		out, _ := json.Marshal(map[string]any{
+			"error": false, "code": 201, "hasMore": false,
+			"result": []any{map[string]any{"results": results, "total": len(results)}},
+		})
+		return jsonResponse(req, http.StatusCreated, string(out)), nil
+	}
+	return jsonResponse(req, http.StatusNotFound, `{"error":true,"code":404,"errorNum":1203,"errorMessage":"not found"}`), nil
+}
+
+func TestSearchSymbolsIsScopedToRepo(t *testing.T) {
+	t.Parallel()
+
+	transport := &repoTransport{symbols: []map[string]any{
+		{"qname": "acme/a/store.Save", "name": "Save", "kind": "function", "filepath": "store/save.go", "pos": 3, "repo_id": "repo-a"},
+		{"qname": "acme/b/store.Save", "name": "Save", "kind": "function", "filepath": "store/save.go", "pos": 7, "repo_id": "repo-b"},
+	}}
+	unscoped := newFlakyClient(t, transport, RetryPolicy{MaxAttempts: 1})
+	scoped := unscoped.ForRepo("repo-a")
+
+	results, total, err := scoped.SearchSymbols(context.Background(), SearchOptions{Name: "Save"})
+	if err != nil {
+		t.Fatalf("SearchSymbols: %v", err)
+	}
+	if total != 1 || len(results) != 1 || results[0].QName != "acme/a/store.Save" {
+		t.Errorf("scoped search = %+v (total %d), want only repo-a's Save", results, total)
+	}
+
+	if _, total, _ := unscoped.SearchSymbols(context.Background(), SearchOptions{Name: "Save"}); total != 2 {
+		t.Errorf("unscoped search total = %d, want 2", total)
+	}
+
+	resolved, err := scoped.ResolveSymbols(context.Background(), []SearchOptions{{Name: "Save"}})
+	if err != nil {
+		t.Fatalf("ResolveSymbols: %v", err)
+	}
+	if resolved[0].Err != nil || resolved[0].Symbol.QName != "acme/a/store.Save" {
+		t.Errorf("scoped resolve = %+v, want repo-a's Save", resolved[0])
+	}
+
+	if _, err := scoped.RawQuery(context.Background(), "FOR f IN functions RETURN f"); !errors.Is(err, ErrQueryNotAllowed) {
+		t.Errorf("scoped RawQuery error = %v, want ErrQueryNotAllowed", err)
+	}
+}
+
+func TestSearchSymbolsFiltersByQName(t *testing.T) {
+	t.Parallel()
+
+	transport := &repoTransport{symbols: []map[string]any{
+		{"qname": "acme/store.Save", "name": "Save", "kind": "function", "filepath": "store/save.go", "pos": 3},
+		{"qname": "acme/cache.Save", "name": "Save", "kind": "function", "filepath": "cache/save.go", "pos": 9},
+	}}
+	c := newFlakyClient(t, transport, RetryPolicy{MaxAttempts: 1})
+
+	results, total, err := c.SearchSymbols(context.Background(), SearchOptions{Name: "Save", Exact: true, QName: "acme/cache.Save"})
+	if err != nil {
+		t.Fatalf("SearchSymbols: %v", err)
+	}
+	if total != 1 || len(results) != 1 || results[0].QName != "acme/cache.Save" {
+		t.Errorf("search by qname = %+v (total %d), want only acme/cache.Save", results, total)
+	}
+}
+
+type fakeCreateReader struct {
+	results []error
+}
+
+func (r *fakeCreateReader) Read() (arangodb.CollectionDocumentCreateResponse, error) {
+	if len(r.results) == 0 {
+		return arangodb.CollectionDocumentCreateResponse{}, shared.NoMoreDocumentsError{}
+	}
+	err := r.results[0]
+	r.results = r.results[1:]
+	return arangodb.CollectionDocumentCreateResponse{}, err
+}
+
+func TestReadCreateResults(t *testing.T) {
+	t.Parallel()
+
+	duplicate := shared.ArangoError{HasError: true, Code: http.StatusConflict, ErrorNum: errUniqueConstraintViolated, ErrorMessage: "unique constraint violated"}
+	tooLarge := shared.ArangoError{HasError: true, Code: http.StatusBadRequest, ErrorNum: 1216, ErrorMessage: "document too large"}
+
+	tests := []struct {
+		name    string
+		results []error
+		strict  bool
+		wantErr bool
+	}{
+		{name: "all created", results: []error{nil, nil}, strict: true},
+		{name: "duplicate key in a transaction", results: []error{nil, duplicate, nil}, strict: true},
+		{name: "insert failure in a transaction", results: []error{nil, tooLarge, nil}, strict: true, wantErr: true},
+		{name: "insert failure outside a transaction", results: []error{nil, tooLarge, nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reader := &fakeCreateReader{results: tt.results}
+			err := readCreateResults(reader, tt.strict)
+			if tt.wantErr {
+				if !shared.IsArangoErrorWithErrorNum(err, 1216) {
+					t.Fatalf("readCreateResults() = %v, want the insert failure", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readCreateResults() = %v, want nil", err)
+			}
+			if len(reader.results) != 0 {
+				t.Errorf("%d results left unread", len(reader.results))
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package arangodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+)
+
+// Query errors wrap the driver error in one of these, so callers can tell
+// the graph being down from a bad query with errors.Is, and still reach the
+// driver error with errors.As.
+var (
+	// ErrTimeout means the query or the connection timed out, either on the
+	// caller's deadline or in ArangoDB.
+	ErrTimeout = errors.New("arangodb timeout")
+	// ErrUnavailable means ArangoDB couldn't be reached or refused the
+	// request: connection refused or dropped, or a 502/503/429 response.
+	ErrUnavailable = errors.New("arangodb unavailable")
+	// ErrQuery means ArangoDB rejected or failed the query itself: a parse
+	// error, bad bind parameters, a missing collection.
+	ErrQuery = errors.New("arangodb query failed")
+)
+
+// errorKind returns the sentinel err falls under, or nil for errors that
+// aren't driver failures (ErrNotFound, AmbiguousSymbolError, cancellation).
+func errorKind(err error) error {
+	var ambiguous AmbiguousSymbolError
+	switch {
+	case err == nil,
+		errors.Is(err, ErrNotFound),
+		errors.As(err, &ambiguous),
+		errors.Is(err, ErrQueryNotAllowed),
+		errors.Is(err, context.Canceled):
+		return nil
+	case errors.Is(err, ErrTimeout):
+		return ErrTimeout
+	case errors.Is(err, ErrUnavailable):
+		return ErrUnavailable
+	case errors.Is(err, ErrQuery):
+		return ErrQuery
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	}
+
+	// ArangoError satisfies net.Error, so it has to be classified first.
+	if ok, arangoErr := shared.IsArangoError(err); ok {
+		switch arangoErr.Code {
+		case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+			return ErrTimeout
+		case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusTooManyRequests:
+			return ErrUnavailable
+		}
+		return ErrQuery
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrTimeout
+	}
+	if errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// wrapDriverError wraps err in its errorKind sentinel. Errors without one,
+// and errors already wrapped, are returned as is.
+func wrapDriverError(err error) error {
+	kind := errorKind(err)
+	if kind == nil || errors.Is(err, kind) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", kind, err)
+}
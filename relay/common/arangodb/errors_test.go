@@ -0,0 +1,145 @@
+package arangodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+)
+
+// cursorTransport serves a minimal ArangoDB HTTP API whose cursor requests
+// are answered by cursor.
+type cursorTransport struct {
+	cursor func(req *http.Request) (*http.Response, error)
+}
+
+func (t cursorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/_api/database/current"):
+		return jsonResponse(req, http.StatusOK, `{"error":false,"code":200,"result":{"name":"codegraph","id":"1","path":"","isSystem":false}}`), nil
+	case strings.HasSuffix(req.URL.Path, "/_api/cursor"):
+		return t.cursor(req)
+	}
+	return jsonResponse(req, http.StatusNotFound, `{"error":true,"code":404,"errorNum":1203,"errorMessage":"not found"}`), nil
+}
+
+func TestQueryErrorsAreTyped(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		cursor func(req *http.Request) (*http.Response, error)
+		want   error
+	}{
+		{"connection reset", func(*http.Request) (*http.Response, error) {
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+		}, ErrUnavailable},
+		{"connection refused", func(*http.Request) (*http.Response, error) {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+		}, ErrUnavailable},
+		{"i/o timeout", func(*http.Request) (*http.Response, error) {
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+		}, ErrTimeout},
+		{"service unavailable", func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(req, http.StatusServiceUnavailable, `{"error":true,"code":503,"errorNum":503,"errorMessage":"service unavailable"}`), nil
+		}, ErrUnavailable},
+		{"gateway timeout", func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(req, http.StatusGatewayTimeout, `{"error":true,"code":504,"errorNum":504,"errorMessage":"timeout"}`), nil
+		}, ErrTimeout},
+		{"parse error", func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(req, http.StatusBadRequest, `{"error":true,"code":400,"errorNum":1501,"errorMessage":"syntax error"}`), nil
+		}, ErrQuery},
+		{"missing collection", func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(req, http.StatusNotFound, `{"error":true,"code":404,"errorNum":1203,"errorMessage":"collection or view not found"}`), nil
+		}, ErrQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newFlakyClient(t, cursorTransport{cursor: tt.cursor}, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+			_, _, err := c.GetCallers(context.Background(), "app.Save", 1, Page{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("GetCallers error = %v, want %v", err, tt.want)
+			}
+			for _, other := range []error{ErrTimeout, ErrUnavailable, ErrQuery} {
+				if other != tt.want && errors.Is(err, other) {
+					t.Errorf("GetCallers error = %v, also matches %v", err, other)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryErrorKeepsDriverError(t *testing.T) {
+	t.Parallel()
+
+	c := newFlakyClient(t, cursorTransport{cursor: func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, http.StatusBadRequest, `{"error":true,"code":400,"errorNum":1501,"errorMessage":"syntax error"}`), nil
+	}}, RetryPolicy{MaxAttempts: 1})
+
+	_, _, err := c.GetCallers(context.Background(), "app.Save", 1, Page{})
+	var arangoErr shared.ArangoError
+	if !errors.As(err, &arangoErr) || arangoErr.ErrorNum != 1501 {
+		t.Fatalf("GetCallers error = %v, want ArangoError 1501", err)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"connection reset", fmt.Errorf("execute query: %w", &net.OpError{Op: "read", Err: syscall.ECONNRESET}), ErrUnavailable},
+		{"broken pipe", syscall.EPIPE, ErrUnavailable},
+		{"unexpected eof", io.ErrUnexpectedEOF, ErrUnavailable},
+		{"too many requests", shared.ArangoError{HasError: true, Code: http.StatusTooManyRequests}, ErrUnavailable},
+		{"net timeout", &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, ErrTimeout},
+		{"deadline", context.DeadlineExceeded, ErrTimeout},
+		{"request timeout", shared.ArangoError{HasError: true, Code: http.StatusRequestTimeout}, ErrTimeout},
+		{"bad parameter", shared.ArangoError{HasError: true, Code: http.StatusBadRequest, ErrorNum: 1552}, ErrQuery},
+		{"already wrapped", fmt.Errorf("get callers: %w", wrapDriverError(syscall.ECONNRESET)), ErrUnavailable},
+		{"not found", ErrNotFound, nil},
+		{"ambiguous symbol", AmbiguousSymbolError{Query: "Save"}, nil},
+		{"query not allowed", ErrQueryNotAllowed, nil},
+		{"cancelled", context.Canceled, nil},
+		{"other", errors.New("decode document"), nil},
+	}
+	for _, tt := range tests {
+		if got := errorKind(tt.err); got != tt.want {
+			t.Errorf("errorKind(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWrapDriverError(t *testing.T) {
+	t.Parallel()
+
+	if err := wrapDriverError(nil); err != nil {
+		t.Errorf("wrapDriverError(nil) = %v", err)
+	}
+	if err := wrapDriverError(ErrNotFound); err != ErrNotFound {
+		t.Errorf("wrapDriverError(ErrNotFound) = %v, want it unchanged", err)
+	}
+
+	err := wrapDriverError(syscall.ECONNREFUSED)
+	if !errors.Is(err, ErrUnavailable) || !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("wrapDriverError(ECONNREFUSED) = %v, want ErrUnavailable wrapping it", err)
+	}
+	if again := wrapDriverError(err); again != err {
+		t.Errorf("wrapDriverError wrapped twice: %v", again)
+	}
+}
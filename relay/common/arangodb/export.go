@@ -0,0 +1,123 @@
+package arangodb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// callEdgeRow is a single caller→callee pair as returned by the export query.
+type callEdgeRow struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// documentReader is the subset of arangodb.Cursor the export loop needs.
+type documentReader interface {
+	HasMore() bool
+	ReadDocument(ctx context.Context, result any) (arangodb.DocumentMeta, error)
+}
+
+// ExportCallGraph streams the call graph as an adjacency list, one entry per
+// caller, sorted by caller qname. If namespacePrefix is set, only callers whose
+// qname starts with it are exported. Callees are not filtered, so edges leaving
+// the namespace are kept.
+//
+// The query runs as a streaming cursor and entries are emitted as soon as the
+// caller's edges are complete, so memory use is bounded by the widest fan-out
+// rather than the size of the graph.
+func (c *client) ExportCallGraph(ctx context.Context, namespacePrefix string, emit func(CallGraphEntry) error) error {
+	if c.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	start := time.Now()
+
+	bindVars := map[string]any{"prefix": namespacePrefix}
+	query := fmt.Sprintf(`
+		FOR e IN calls
+			FILTER %s
+			LET caller = DOCUMENT(e._from)
+			LET callee = DOCUMENT(e._to)
+			FILTER caller != null AND callee != null
+			FILTER @prefix == "" OR STARTS_WITH(caller.qname, @prefix)
+			SORT caller.qname, callee.qname
+			RETURN { from: caller.qname, to: callee.qname }
+	`, repoFilter(c.repoID, "e", bindVars))
+
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
+		BindVars: bindVars,
+		Options:  arangodb.QuerySubOptions{Stream: true},
+	})
+	if err != nil {
+		return fmt.Errorf("execute export query: %w", err)
+	}
+	defer cursor.Close()
+
+	entries, err := streamAdjacency(ctx, cursor, namespacePrefix, emit)
+	if err != nil {
+		return err
+	}
+
+	slog.DebugContext(ctx, "arangodb call graph exported",
+		"namespace_prefix", namespacePrefix,
+		"entries", entries,
+		"duration_ms", time.Since(start).Milliseconds())
+
+	return nil
+}
+
+// streamAdjacency groups caller-sorted edge rows into adjacency entries.
+// Duplicate callees (multiple call sites) are collapsed.
+func streamAdjacency(ctx context.Context, reader documentReader, namespacePrefix string, emit func(CallGraphEntry) error) (int, error) {
+	var (
+		current CallGraphEntry
+		seen    map[string]struct{}
+		emitted int
+	)
+
+	flush := func() error {
+		if current.QName == "" {
+			return nil
+		}
+		if err := emit(current); err != nil {
+			return fmt.Errorf("emit %s: %w", current.QName, err)
+		}
+		emitted++
+		return nil
+	}
+
+	for reader.HasMore() {
+		var row callEdgeRow
+		if _, err := reader.ReadDocument(ctx, &row); err != nil {
+			return emitted, fmt.Errorf("read document: %w", err)
+		}
+		if row.From == "" || row.To == "" || !strings.HasPrefix(row.From, namespacePrefix) {
+			continue
+		}
+
+		if row.From != current.QName {
+			if err := flush(); err != nil {
+				return emitted, err
+			}
+			current = CallGraphEntry{QName: row.From}
+			seen = make(map[string]struct{})
+		}
+
+		if _, ok := seen[row.To]; ok {
+			continue
+		}
+		seen[row.To] = struct{}{}
+		current.Callees = append(current.Callees, row.To)
+	}
+
+	if err := flush(); err != nil {
+		return emitted, err
+	}
+
+	return emitted, nil
+}
@@ -0,0 +1,109 @@
+package arangodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+type fakeRowReader struct {
+	rows []callEdgeRow
+}
+
+func (r *fakeRowReader) HasMore() bool { return len(r.rows) > 0 }
+
+func (r *fakeRowReader) ReadDocument(_ context.Context, result any) (arangodb.DocumentMeta, error) {
+	data, err := json.Marshal(r.rows[0])
+	if err != nil {
+		return arangodb.DocumentMeta{}, err
+	}
+	r.rows = r.rows[1:]
+	return arangodb.DocumentMeta{}, json.Unmarshal(data, result)
+}
+
+func seededCallRows() []callEdgeRow {
+	return []callEdgeRow{
+		{From: "app/api.Handle", To: "app/svc.Create"},
+		{From: "app/api.Handle", To: "app/svc.Create"},
+		{From: "app/api.Handle", To: "fmt.Sprintf"},
+		{From: "app/svc.Create", To: "app/store.Insert"},
+		{From: "app/store.Insert", To: "database/sql.Exec"},
+		{From: "other/cli.Main", To: "app/api.Handle"},
+	}
+}
+
+func TestStreamAdjacency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []CallGraphEntry
+	}{
+		{
+			name:   "whole graph",
+			prefix: "",
+			want: []CallGraphEntry{
+				{QName: "app/api.Handle", Callees: []string{"app/svc.Create", "fmt.Sprintf"}},
+				{QName: "app/svc.Create", Callees: []string{"app/store.Insert"}},
+				{QName: "app/store.Insert", Callees: []string{"database/sql.Exec"}},
+				{QName: "other/cli.Main", Callees: []string{"app/api.Handle"}},
+			},
+		},
+		{
+			name:   "namespace prefix keeps outbound edges",
+			prefix: "app/s",
+			want: []CallGraphEntry{
+				{QName: "app/svc.Create", Callees: []string{"app/store.Insert"}},
+				{QName: "app/store.Insert", Callees: []string{"database/sql.Exec"}},
+			},
+		},
+		{
+			name:   "no matching namespace",
+			prefix: "missing/",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []CallGraphEntry
+			n, err := streamAdjacency(context.Background(), &fakeRowReader{rows: seededCallRows()}, tt.prefix, func(e CallGraphEntry) error {
+				got = append(got, e)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("streamAdjacency() error = %v", err)
+			}
+			if n != len(tt.want) {
+				t.Errorf("streamAdjacency() emitted %d entries, want %d", n, len(tt.want))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("streamAdjacency() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamAdjacencyStopsOnEmitError(t *testing.T) {
+	t.Parallel()
+
+	errStop := errors.New("disk full")
+	calls := 0
+	_, err := streamAdjacency(context.Background(), &fakeRowReader{rows: seededCallRows()}, "", func(CallGraphEntry) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("streamAdjacency() error = %v, want %v", err, errStop)
+	}
+	if calls != 1 {
+		t.Errorf("emit called %d times, want 1", calls)
+	}
+}
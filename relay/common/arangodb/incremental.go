@@ -0,0 +1,144 @@
+package arangodb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// symbolCollections hold the nodes that record the file they're defined in.
+var symbolCollections = []string{"functions", "types", "members", "files"}
+
+// GetFileHashes returns the content hash recorded on each file node, keyed
+// by file path. Files ingested before hashes were recorded map to "".
+func (c *client) GetFileHashes(ctx context.Context) (map[string]string, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	bindVars := map[string]any{}
+	query := fmt.Sprintf(`
+		FOR f IN files
+			FILTER f.filepath != null AND f.filepath != ""
+			FILTER %s
+			RETURN { filepath: f.filepath, hash: f.hash }
+	`, repoFilter(c.repoID, "f", bindVars))
+
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{BindVars: bindVars})
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+	defer cursor.Close()
+
+	hashes := make(map[string]string)
+	for cursor.HasMore() {
+		var doc struct {
+			Filepath string `json:"filepath"`
+			Hash     string `json:"hash"`
+		}
+		if _, err := cursor.ReadDocument(ctx, &doc); err != nil {
+			return nil, fmt.Errorf("read document: %w", err)
+		}
+		hashes[doc.Filepath] = doc.Hash
+	}
+	return hashes, nil
+}
+
+// DeleteFileNodes removes the symbol and file nodes recorded under filepaths
+// together with every edge touching them. Implements edges of types with a
+// method in those files are removed as well, since a method set can span
+// files; the caller re-ingests them for the types it still has.
+func (c *client) DeleteFileNodes(ctx context.Context, filepaths []string) error {
+	if c.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if len(filepaths) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+
+	// Resolve ids first: edges are removed before the nodes they point to.
+	bindVars := map[string]any{"files": filepaths}
+	inFiles := "d.filepath IN @files AND " + repoFilter(c.repoID, "d", bindVars)
+	query := fmt.Sprintf(`
+		LET removed = UNION(
+			(FOR d IN functions FILTER %[1]s RETURN d._id),
+			(FOR d IN types FILTER %[1]s RETURN d._id),
+			(FOR d IN members FILTER %[1]s RETURN d._id),
+			(FOR d IN files FILTER %[1]s RETURN d._id)
+		)
+		LET receivers = UNIQUE(
+			FOR d IN functions
+				FILTER %[1]s AND d.is_method == true
+				FOR e IN parent
+					FILTER e._from == d._id
+					RETURN e._to
+		)
+		RETURN { removed: removed, receivers: receivers }
+	`, inFiles)
+	cursor, err := c.query(ctx, query, &arangodb.QueryOptions{
+		BindVars: bindVars,
+	})
+	if err != nil {
+		return fmt.Errorf("execute query: %w", err)
+	}
+	var ids struct {
+		Removed   []string `json:"removed"`
+		Receivers []string `json:"receivers"`
+	}
+	if cursor.HasMore() {
+		if _, err := cursor.ReadDocument(ctx, &ids); err != nil {
+			cursor.Close()
+			return fmt.Errorf("read document: %w", err)
+		}
+	}
+	cursor.Close()
+
+	for _, col := range edgeCollections {
+		if err := c.removeWhere(ctx, col, "d._from IN @ids OR d._to IN @ids", map[string]any{"ids": ids.Removed}); err != nil {
+			return err
+		}
+	}
+	if len(ids.Receivers) > 0 {
+		if err := c.removeWhere(ctx, "implements", "d._from IN @ids", map[string]any{"ids": ids.Receivers}); err != nil {
+			return err
+		}
+	}
+	for _, col := range symbolCollections {
+		if err := c.removeWhere(ctx, col, inFiles, bindVars); err != nil {
+			return err
+		}
+	}
+
+	slog.DebugContext(ctx, "arangodb file nodes deleted",
+		"files", len(filepaths),
+		"nodes", len(ids.Removed),
+		"duration_ms", time.Since(start).Milliseconds())
+
+	return nil
+}
+
+// removeWhere removes the documents of collection matching filter, which
+// refers to the document as d.
+func (c *client) removeWhere(ctx context.Context, collection, filter string, bindVars map[string]any) error {
+	query := fmt.Sprintf(`
+		FOR d IN @@col
+			FILTER %s
+			REMOVE d IN @@col
+	`, filter)
+
+	vars := map[string]any{"@col": collection}
+	for k, v := range bindVars {
+		vars[k] = v
+	}
+
+	cursor, err := c.db.Query(ctx, query, &arangodb.QueryOptions{BindVars: vars})
+	if err != nil {
+		return fmt.Errorf("remove from %s: %w", collection, wrapDriverError(err))
+	}
+	return cursor.Close()
+}
@@ -0,0 +1,272 @@
+package arangodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+const (
+	// MaxRawQueryRows caps the rows returned by RawQuery.
+	MaxRawQueryRows = 200
+	rawQueryTimeout = 10 * time.Second
+)
+
+var (
+	// ErrQueryNotAllowed is returned for queries that fail the read-only checks.
+	ErrQueryNotAllowed = errors.New("query not allowed")
+	// ErrRawQueryUserRequired is returned by RawQuery when no read-only user
+	// is configured (Config.RawQueryUsername).
+	ErrRawQueryUserRequired = errors.New("raw queries need a read-only arangodb user (RawQueryUsername)")
+)
+
+// RawQueryResult holds the rows of a raw AQL query.
+type RawQueryResult struct {
+	Rows      []any
+	Truncated bool // More rows matched than MaxRawQueryRows
+}
+
+var (
+	// aqlLiteralPattern matches string literals and comments, leftmost first,
+	// so quotes inside comments and comment markers inside strings are handled.
+	aqlLiteralPattern = regexp.MustCompile(`(?s)"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|//[^\n]*|/\*.*?\*/`)
+
+	// aqlWritePattern matches AQL data-modification operations. A leading dot
+	// (attribute access such as v.update) doesn't count.
+	aqlWritePattern = regexp.MustCompile(`(?i)(^|[^.\w])(INSERT|UPDATE|REPLACE|REMOVE|UPSERT)\b`)
+
+	// aqlSystemNamePattern matches underscore-prefixed names, which are system
+	// collections unless they follow a dot (document attributes like v._key).
+	aqlSystemNamePattern = regexp.MustCompile(`(^|[^.\w])(_\w+)`)
+
+	aqlBindParamPattern = regexp.MustCompile(`@@?\w+`)
+
+	// aqlForPattern captures a FOR loop's variables and the bare name it
+	// iterates; expressions (calls, attribute paths, ranges) aren't captured.
+	aqlForPattern  = regexp.MustCompile(`(?i)\bFOR\s+([\w\s,]+?)\s+IN\s+(\w+)\s*($|[^\w(.\[])`)
+	aqlBindPattern = regexp.MustCompile(`(?i)\b(?:LET|COLLECT|AGGREGATE|INTO)\s+(\w+)`)
+	aqlWithPattern = regexp.MustCompile(`(?i)^\s*WITH\s+([\w\s,]+?)\s+(?:FOR|LET|RETURN)\b`)
+
+	// aqlCallPattern matches function calls, to check them against
+	// aqlDeniedFunctions.
+	aqlCallPattern = regexp.MustCompile(`(?i)(^|[^.\w])(\w+)\s*\(`)
+
+	// aqlComputedStartPattern matches a traversal or shortest path whose start
+	// or target vertex is computed by a function call, which could build a
+	// system collection's document handle ("_us" + "ers/x") at run time.
+	aqlComputedStartPattern = regexp.MustCompile(`(?i)\b(?:OUTBOUND|INBOUND|ANY|TO)\s+(?:(?:K_|ALL_)?SHORTEST_PATHS?\s+|K_PATHS\s+)?(\w+)\s*\(`)
+
+	// aqlStartPattern captures a traversal or shortest path's start or target
+	// vertex (after literals are blanked to ""), and whether it goes on to an
+	// attribute or index.
+	aqlStartPattern = regexp.MustCompile(`(?i)\b(?:OUTBOUND|INBOUND|ANY|TO)\s+(?:(?:K_|ALL_)?SHORTEST_PATHS?\s+|K_PATHS\s+)?(""|\w+)(\s*[.\[])?`)
+
+	// aqlTraversalForPattern captures the variables of a FOR loop over a
+	// traversal, which are vertices, edges and paths of the graph.
+	aqlTraversalForPattern = regexp.MustCompile(`(?i)\bFOR\s+([\w\s,]+?)\s+IN\s+[\d.\s]*(?:OUTBOUND|INBOUND|ANY)\b`)
+)
+
+// aqlDeniedFunctions read documents or collections named by a run-time
+// string, or call other functions by name, so the collection checks on the
+// query text can't see what they touch.
+var aqlDeniedFunctions = map[string]bool{
+	"DOCUMENT":         true,
+	"COLLECTIONS":      true,
+	"COLLECTION_COUNT": true,
+	"CALL":             true,
+	"APPLY":            true,
+	"FULLTEXT":         true,
+	"NEAR":             true,
+	"WITHIN":           true,
+	"WITHIN_RECTANGLE": true,
+	"PREGEL_RESULT":    true,
+	"SCHEMA_GET":       true,
+	"V8":               true,
+}
+
+// aqlTraversalDirections start graph traversals where a FOR source would be.
+var aqlTraversalDirections = map[string]bool{"OUTBOUND": true, "INBOUND": true, "ANY": true}
+
+// rawQueryCollections are the collections a raw query may read.
+func rawQueryCollections() map[string]bool {
+	allowed := make(map[string]bool, len(nodeCollections)+len(edgeCollections))
+	for _, name := range nodeCollections {
+		allowed[name] = true
+	}
+	for _, name := range edgeCollections {
+		allowed[name] = true
+	}
+	return allowed
+}
+
+// ValidateReadOnlyQuery checks that query only reads codegraph collections:
+// no data-modification operations, no bind parameters, no system collections
+// (by name or in a document handle string), no functions that look documents
+// or collections up by a run-time string (DOCUMENT, COLLECTIONS, CALL), no
+// traversal from a computed vertex, and every FOR ... IN / WITH source is a
+// codegraph collection or a variable bound earlier in the query.
+//
+// These checks are a filter on the query text and only defence in depth: a
+// denylist can't anticipate every way AQL can name a collection at run time.
+// The actual boundary is the read-only user RawQuery runs as
+// (Config.RawQueryUsername); RawQuery refuses to run without one.
+func ValidateReadOnlyQuery(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("%w: empty query", ErrQueryNotAllowed)
+	}
+
+	systemHandle := ""
+	code := aqlLiteralPattern.ReplaceAllStringFunc(query, func(lit string) string {
+		if strings.HasPrefix(lit, "/") {
+			return " "
+		}
+		if name := strings.Trim(lit, `"'`); strings.HasPrefix(name, "_") && systemHandle == "" {
+			systemHandle = name
+		}
+		return `""`
+	})
+	if systemHandle != "" {
+		return fmt.Errorf("%w: system collection in %q", ErrQueryNotAllowed, systemHandle)
+	}
+
+	if aqlBindParamPattern.MatchString(code) {
+		return fmt.Errorf("%w: bind parameters are not supported", ErrQueryNotAllowed)
+	}
+	if m := aqlWritePattern.FindStringSubmatch(code); m != nil {
+		return fmt.Errorf("%w: %s is a write operation", ErrQueryNotAllowed, strings.ToUpper(m[2]))
+	}
+	if m := aqlSystemNamePattern.FindStringSubmatch(code); m != nil {
+		return fmt.Errorf("%w: system collection %s", ErrQueryNotAllowed, m[2])
+	}
+	for _, m := range aqlCallPattern.FindAllStringSubmatch(code, -1) {
+		if name := strings.ToUpper(m[2]); aqlDeniedFunctions[name] {
+			return fmt.Errorf("%w: %s is not supported in raw queries", ErrQueryNotAllowed, name)
+		}
+	}
+	if m := aqlComputedStartPattern.FindStringSubmatch(code); m != nil {
+		return fmt.Errorf("%w: traversal from computed vertex %s(...); start from a literal handle or a loop variable", ErrQueryNotAllowed, m[1])
+	}
+
+	allowed := rawQueryCollections()
+	bound := make(map[string]bool)
+	for _, m := range aqlBindPattern.FindAllStringSubmatch(code, -1) {
+		bound[m[1]] = true
+	}
+	loops := aqlForPattern.FindAllStringSubmatch(code, -1)
+	for _, m := range loops {
+		for _, v := range strings.Split(m[1], ",") {
+			bound[strings.TrimSpace(v)] = true
+		}
+	}
+	for _, m := range loops {
+		source := m[2]
+		if unicode.IsDigit(rune(source[0])) {
+			continue // traversal depth, as in FOR v IN 1 OUTBOUND ...
+		}
+		if !allowed[source] && !bound[source] && !aqlTraversalDirections[strings.ToUpper(source)] {
+			return fmt.Errorf("%w: unknown collection %s", ErrQueryNotAllowed, source)
+		}
+	}
+	// A start vertex must be a literal handle (checked for system collections
+	// above) or a document read from the codegraph. A variable bound by LET
+	// could hold any string, such as CONCAT("_us", "ers/x").
+	vertices := make(map[string]bool)
+	for _, m := range loops {
+		if allowed[m[2]] {
+			for _, v := range strings.Split(m[1], ",") {
+				vertices[strings.TrimSpace(v)] = true
+			}
+		}
+	}
+	for _, m := range aqlTraversalForPattern.FindAllStringSubmatch(code, -1) {
+		for _, v := range strings.Split(m[1], ",") {
+			vertices[strings.TrimSpace(v)] = true
+		}
+	}
+	for _, m := range aqlStartPattern.FindAllStringSubmatch(code, -1) {
+		start := m[1]
+		if start == `""` || aqlTraversalDirections[strings.ToUpper(start)] {
+			continue
+		}
+		if m[2] != "" || !vertices[start] {
+			return fmt.Errorf("%w: traversal from %s; start from a literal handle or a variable looping over a codegraph collection", ErrQueryNotAllowed, start)
+		}
+	}
+
+	if m := aqlWithPattern.FindStringSubmatch(code); m != nil {
+		for _, name := range strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			if !allowed[name] {
+				return fmt.Errorf("%w: unknown collection %s", ErrQueryNotAllowed, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RawQuery runs a read-only AQL query after ValidateReadOnlyQuery. The query
+// is killed server-side after rawQueryTimeout and at most MaxRawQueryRows rows
+// are read. A repo-scoped client refuses raw queries, which could read other
+// repos' subgraphs.
+func (c *client) RawQuery(ctx context.Context, query string) (RawQueryResult, error) {
+	if err := ValidateReadOnlyQuery(query); err != nil {
+		return RawQueryResult{}, err
+	}
+	if c.repoID != "" {
+		return RawQueryResult{}, fmt.Errorf("%w: raw queries can't be scoped to repo %s", ErrQueryNotAllowed, c.repoID)
+	}
+	if c.rawDB == nil {
+		return RawQueryResult{}, ErrRawQueryUserRequired
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, rawQueryTimeout)
+	defer cancel()
+
+	cursor, err := c.queryOn(ctx, c.rawDB, query, &arangodb.QueryOptions{
+		Options: arangodb.QuerySubOptions{
+			Stream:     true,
+			MaxRuntime: rawQueryTimeout.Seconds(),
+		},
+	})
+	if err != nil {
+		return RawQueryResult{}, fmt.Errorf("execute raw query: %w", err)
+	}
+	defer cursor.Close()
+
+	result, err := readRawRows(ctx, cursor, MaxRawQueryRows)
+	if err != nil {
+		return RawQueryResult{}, err
+	}
+
+	slog.DebugContext(ctx, "arangodb raw query completed",
+		"rows", len(result.Rows),
+		"truncated", result.Truncated,
+		"duration_ms", time.Since(start).Milliseconds())
+
+	return result, nil
+}
+
+// readRawRows reads up to limit rows, noting whether more were available.
+func readRawRows(ctx context.Context, reader documentReader, limit int) (RawQueryResult, error) {
+	var result RawQueryResult
+	for reader.HasMore() {
+		if len(result.Rows) == limit {
+			result.Truncated = true
+			break
+		}
+		var row any
+		if _, err := reader.ReadDocument(ctx, &row); err != nil {
+			return RawQueryResult{}, fmt.Errorf("read document: %w", err)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
@@ -0,0 +1,142 @@
+package arangodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+type fakeJSONReader struct {
+	rows []string
+}
+
+func (r *fakeJSONReader) HasMore() bool { return len(r.rows) > 0 }
+
+func (r *fakeJSONReader) ReadDocument(_ context.Context, result any) (arangodb.DocumentMeta, error) {
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return arangodb.DocumentMeta{}, json.Unmarshal([]byte(row), result)
+}
+
+func TestValidateReadOnlyQueryRejects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "empty", query: "  "},
+		{name: "insert", query: `INSERT {name: "x"} INTO functions`},
+		{name: "update", query: `FOR f IN functions UPDATE f WITH {name: "x"} IN functions`},
+		{name: "remove lowercase", query: `for f in functions remove f in functions`},
+		{name: "upsert", query: `UPSERT {qname: "a"} INSERT {qname: "a"} UPDATE {} IN functions`},
+		{name: "system collection", query: `FOR u IN _users RETURN u`},
+		{name: "system document handle", query: `RETURN DOCUMENT("_users/root")`},
+		{name: "unknown collection", query: `FOR d IN secrets RETURN d`},
+		{name: "unknown WITH collection", query: `WITH secrets FOR v IN 1 OUTBOUND "functions/a" calls RETURN v`},
+		{name: "bind parameter", query: `FOR d IN @@coll RETURN d`},
+		{name: "write hidden after comment", query: "FOR f IN functions // read\nREMOVE f IN functions"},
+		{name: "computed document handle", query: `RETURN DOCUMENT(CONCAT("_us", "ers/root"))`},
+		{name: "document lowercase", query: `RETURN document("functions/app.Handle")`},
+		{name: "collections", query: `RETURN COLLECTIONS()`},
+		{name: "call by name", query: `RETURN CALL("DOCUMENT", "x")`},
+		{name: "apply by name", query: `RETURN APPLY("COLLECTIONS", [])`},
+		{name: "computed traversal start", query: `FOR v, e, p IN 1 OUTBOUND CONCAT("_us", "ers/x") calls RETURN p.vertices[0]`},
+		{name: "computed shortest path target", query: `FOR v IN OUTBOUND SHORTEST_PATH "functions/a" TO CONCAT("_us", "ers/x") calls RETURN v`},
+		{name: "traversal start computed in a variable", query: `LET s = CONCAT("_us","ers/x") FOR v IN 0..1 OUTBOUND s calls RETURN v`},
+		{name: "shortest path target computed in a variable", query: `LET t = CONCAT("_us","ers/x") FOR v IN OUTBOUND SHORTEST_PATH "functions/a" TO t calls RETURN v`},
+		{name: "traversal start from an attribute", query: `FOR f IN functions FOR v IN 1 OUTBOUND f.filepath calls RETURN v`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateReadOnlyQuery(tt.query)
+			if !errors.Is(err, ErrQueryNotAllowed) {
+				t.Fatalf("ValidateReadOnlyQuery(%q) = %v, want ErrQueryNotAllowed", tt.query, err)
+			}
+		})
+	}
+}
+
+func TestValidateReadOnlyQueryAccepts(t *testing.T) {
+	t.Parallel()
+
+	queries := []string{
+		`FOR f IN functions FILTER f.name == "update" RETURN f.qname`,
+		`FOR f IN functions FILTER f.name LIKE 'Remove%' LIMIT 10 RETURN {key: f._key, id: f._id}`,
+		`FOR v, e IN 1..2 OUTBOUND "functions/app.Handle" calls RETURN v.qname`,
+		`WITH functions, types FOR v IN 1 ANY "types/app.Store" implements RETURN v`,
+		`LET names = (FOR t IN types RETURN t.name) FOR n IN names RETURN n`,
+		`FOR f IN functions COLLECT file = f.filepath WITH COUNT INTO n SORT n DESC RETURN {file, n}`,
+		`FOR f IN functions /* REMOVE later */ RETURN f.update`,
+		`FOR f IN functions RETURN CONCAT(f.filepath, ":", f.pos)`,
+		`FOR f IN functions FOR v IN 1 OUTBOUND f calls RETURN {from: f.qname, to: v.qname, n: LENGTH(f.name)}`,
+		`FOR v IN OUTBOUND SHORTEST_PATH "functions/a" TO "functions/b" calls RETURN v.qname`,
+		`FOR f IN functions FILTER f.name ANY == "x" RETURN f`,
+	}
+
+	for _, query := range queries {
+		if err := ValidateReadOnlyQuery(query); err != nil {
+			t.Errorf("ValidateReadOnlyQuery(%q) = %v, want nil", query, err)
+		}
+	}
+}
+
+func TestReadRawRows(t *testing.T) {
+	t.Parallel()
+
+	reader := &fakeJSONReader{rows: []string{`"app.Handle"`, `{"n":2}`}}
+	got, err := readRawRows(context.Background(), reader, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RawQueryResult{Rows: []any{"app.Handle", map[string]any{"n": float64(2)}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readRawRows() = %#v, want %#v", got, want)
+	}
+
+	reader = &fakeJSONReader{rows: []string{`1`, `2`, `3`}}
+	got, err = readRawRows(context.Background(), reader, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Rows) != 2 || !got.Truncated {
+		t.Errorf("readRawRows(limit 2) = %+v, want 2 rows and truncated", got)
+	}
+}
+
+func TestRawQueryRefusesWithoutReadOnlyUser(t *testing.T) {
+	t.Parallel()
+
+	main := &flakyTransport{}
+	c := newFlakyClient(t, main, RetryPolicy{MaxAttempts: 1})
+
+	_, err := c.RawQuery(context.Background(), `FOR f IN functions RETURN f.qname`)
+	if !errors.Is(err, ErrRawQueryUserRequired) {
+		t.Fatalf("RawQuery without a raw query user = %v, want ErrRawQueryUserRequired", err)
+	}
+	if main.cursors != 0 {
+		t.Errorf("cursor requests on the main connection = %d, want 0", main.cursors)
+	}
+}
+
+func TestRawQueryRunsOnRawQueryHandle(t *testing.T) {
+	t.Parallel()
+
+	main := &flakyTransport{}
+	raw := &flakyTransport{}
+	c := newFlakyClient(t, main, RetryPolicy{MaxAttempts: 1})
+	c.rawDB = newFlakyClient(t, raw, RetryPolicy{MaxAttempts: 1}).db
+
+	if _, err := c.RawQuery(context.Background(), `FOR f IN functions RETURN f.qname`); err != nil {
+		t.Fatalf("RawQuery: %v", err)
+	}
+	if raw.cursors != 1 || main.cursors != 0 {
+		t.Errorf("cursor requests: raw user %d, main user %d; want 1 and 0", raw.cursors, main.cursors)
+	}
+}
@@ -0,0 +1,116 @@
+package arangodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+)
+
+// RetryPolicy controls how read queries are retried after a transient
+// failure: a dropped connection, a timeout, or ArangoDB reporting itself
+// unavailable. Query errors and lookups that find nothing are never retried.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; 1 disables retries
+	BaseDelay   time.Duration // Delay after the first failure; doubles each attempt
+	Jitter      float64       // Fraction of each delay that is randomized, 0 to 1
+}
+
+// DefaultRetryPolicy makes three attempts, backing off from 100ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		Jitter:      0.2,
+	}
+}
+
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("retry max attempts must not be negative")
+	}
+	if p.BaseDelay < 0 {
+		return fmt.Errorf("retry base delay must not be negative")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// withDefaults returns DefaultRetryPolicy for the zero value, so a Config
+// that doesn't set one still retries.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p == (RetryPolicy{}) {
+		return DefaultRetryPolicy()
+	}
+	p.MaxAttempts = max(p.MaxAttempts, 1)
+	return p
+}
+
+// delay returns the wait before attempt+1, with the last Jitter fraction of
+// the backoff drawn at random so concurrent callers don't retry in lockstep.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || p.Jitter == 0 {
+		return max(d, 0)
+	}
+	spread := time.Duration(float64(d) * p.Jitter)
+	return d - spread + time.Duration(rand.Int64N(int64(spread)+1))
+}
+
+// query runs a read-only AQL query, retrying transient failures per the
+// configured policy. Failures are wrapped in ErrTimeout, ErrUnavailable or
+// ErrQuery. Writes go through c.db.Query directly: a write that
+// failed mid-flight may have been applied.
+func (c *client) query(ctx context.Context, query string, opts *arangodb.QueryOptions) (arangodb.Cursor, error) {
+	return c.queryOn(ctx, c.db, query, opts)
+}
+
+// queryOn is query against db, for RawQuery's separately authenticated handle.
+func (c *client) queryOn(ctx context.Context, db arangodb.Database, query string, opts *arangodb.QueryOptions) (arangodb.Cursor, error) {
+	p := c.cfg.Retry.withDefaults()
+
+	for attempt := 1; ; attempt++ {
+		cursor, err := db.Query(ctx, query, opts)
+		if err == nil {
+			if attempt > 1 {
+				slog.InfoContext(ctx, "arangodb query succeeded after retry", "attempts", attempt)
+			}
+			return cursor, nil
+		}
+		if attempt >= p.MaxAttempts || !isTransient(err) || ctx.Err() != nil {
+			return nil, wrapDriverError(err)
+		}
+
+		wait := p.delay(attempt)
+		slog.WarnContext(ctx, "arangodb query failed, retrying",
+			"attempt", attempt,
+			"retry_in", wait,
+			"error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, wrapDriverError(err)
+		case <-timer.C:
+		}
+	}
+}
+
+// isTransient reports whether err is worth retrying: network failures and
+// ArangoDB being unavailable or overloaded, as opposed to errors in the
+// query itself or results callers act on. A timeout on the caller's own
+// deadline isn't retried; there's no time left for it.
+func isTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	kind := errorKind(err)
+	return kind == ErrTimeout || kind == ErrUnavailable
+}
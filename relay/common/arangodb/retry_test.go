@@ -0,0 +1,167 @@
+package arangodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arangodb/go-driver/v2/arangodb"
+	"github.com/arangodb/go-driver/v2/arangodb/shared"
+	"github.com/arangodb/go-driver/v2/connection"
+)
+
+// flakyTransport serves a minimal ArangoDB HTTP API and drops the first
+// failures cursor requests with a connection reset.
+type flakyTransport struct {
+	mu       sync.Mutex
+	failures int
+	cursors  int // cursor requests seen, failed ones included
+}
+
+func (t *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/_api/database/current"):
+		return jsonResponse(req, http.StatusOK, `{"error":false,"code":200,"result":{"name":"codegraph","id":"1","path":"","isSystem":false}}`), nil
+	case strings.HasSuffix(req.URL.Path, "/_api/cursor"):
+		t.mu.Lock()
+		t.cursors++
+		fail := t.cursors <= t.failures
+		t.mu.Unlock()
+		if fail {
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+		}
+		body := `{"error":false,"code":201,"hasMore":false,"result":[{"results":[{"qname":"app.Handle","name":"Handle","kind":"function","filepath":"/repo/app.go","pos":12}],"total":1}]}`
+		return jsonResponse(req, http.StatusCreated, body), nil
+	}
+	return jsonResponse(req, http.StatusNotFound, `{"error":true,"code":404,"errorNum":1203,"errorMessage":"not found"}`), nil
+}
+
+func jsonResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func newFlakyClient(t *testing.T, transport http.RoundTripper, policy RetryPolicy) *client {
+	t.Helper()
+
+	conn := connection.NewHttpConnection(connection.HttpConfiguration{
+		Endpoint:    connection.NewRoundRobinEndpoints([]string{"http://arangodb.test:8529"}),
+		ContentType: connection.ApplicationJSON,
+		Transport:   transport,
+	})
+	arangoClient := arangodb.NewClient(conn)
+
+	c := &client{
+		conn:         conn,
+		arangoClient: arangoClient,
+		cfg:          Config{URL: "http://arangodb.test:8529", Username: "root", Database: "codegraph", Retry: policy},
+	}
+	db, err := arangoClient.GetDatabase(context.Background(), c.cfg.Database, nil)
+	if err != nil {
+		t.Fatalf("GetDatabase: %v", err)
+	}
+	c.db = db
+	return c
+}
+
+func TestGetCallersRetriesTransientFailure(t *testing.T) {
+	t.Parallel()
+
+	transport := &flakyTransport{failures: 1}
+	c := newFlakyClient(t, transport, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	callers, total, err := c.GetCallers(context.Background(), "app.Save", 1, Page{})
+	if err != nil {
+		t.Fatalf("GetCallers: %v", err)
+	}
+	if transport.cursors != 2 {
+		t.Errorf("cursor requests = %d, want 2", transport.cursors)
+	}
+	if total != 1 || len(callers) != 1 || callers[0].QName != "app.Handle" {
+		t.Errorf("callers = %+v, total = %d", callers, total)
+	}
+}
+
+func TestQueryGivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	transport := &flakyTransport{failures: 5}
+	c := newFlakyClient(t, transport, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	if _, _, err := c.GetCallers(context.Background(), "app.Save", 1, Page{}); !errors.Is(err, syscall.ECONNRESET) {
+		t.Fatalf("GetCallers error = %v, want connection reset", err)
+	}
+	if transport.cursors != 2 {
+		t.Errorf("cursor requests = %d, want 2", transport.cursors)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection reset", fmt.Errorf("execute query: %w", &net.OpError{Op: "read", Err: syscall.ECONNRESET}), true},
+		{"connection refused", syscall.ECONNREFUSED, true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"unavailable", shared.ArangoError{HasError: true, Code: http.StatusServiceUnavailable}, true},
+		{"gateway timeout", shared.ArangoError{HasError: true, Code: http.StatusGatewayTimeout}, true},
+		{"query parse error", shared.ArangoError{HasError: true, Code: http.StatusBadRequest, ErrorNum: 1501}, false},
+		{"not found", ErrNotFound, false},
+		{"wrapped not found", fmt.Errorf("resolve: %w", ErrNotFound), false},
+		{"ambiguous symbol", AmbiguousSymbolError{Query: "Save"}, false},
+		{"cancelled", context.Canceled, false},
+		{"deadline", context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	t.Parallel()
+
+	p := RetryPolicy{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond, Jitter: 0.5}
+	for attempt, base := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond} {
+		for range 20 {
+			if d := p.delay(attempt); d < base/2 || d > base {
+				t.Fatalf("delay(%d) = %s, want within [%s, %s]", attempt, d, base/2, base)
+			}
+		}
+	}
+
+	if d := (RetryPolicy{BaseDelay: 50 * time.Millisecond}).delay(2); d != 100*time.Millisecond {
+		t.Errorf("delay without jitter = %s, want 100ms", d)
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	t.Parallel()
+
+	if got := (RetryPolicy{}).withDefaults(); got != DefaultRetryPolicy() {
+		t.Errorf("zero policy = %+v, want defaults", got)
+	}
+	if got := (RetryPolicy{MaxAttempts: 1}).withDefaults(); got.MaxAttempts != 1 {
+		t.Errorf("MaxAttempts 1 not kept: %+v", got)
+	}
+	if err := (Config{URL: "u", Username: "root", Database: "d", Retry: RetryPolicy{Jitter: 2}}).Validate(); err == nil {
+		t.Error("expected jitter above 1 to fail validation")
+	}
+}
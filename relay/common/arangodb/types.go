@@ -20,7 +20,9 @@ type Node struct {
 	End       int
 	IsMethod  bool   // Go: true for receiver functions
 	TypeQName string // For members: the type of the field/variable
+	Tag       string // For members: struct field tag, e.g. json:"email"
 	Signature string // For functions: human-readable signature
+	Hash      string // For files: content hash, compared by incremental ingestion
 }
 
 type Edge struct {
@@ -38,6 +40,39 @@ type GraphNode struct {
 	Filepath  string
 	Pos       int
 	Signature string
+	Usage     UsageKind // Set by GetUsages: how this node uses the queried type
+}
+
+// UsageKind is how a function or struct uses a type, for GetUsages.
+type UsageKind string
+
+const (
+	UsageAny    UsageKind = ""       // Every kind below
+	UsageParam  UsageKind = "param"  // A function/method taking the type as a parameter
+	UsageReturn UsageKind = "return" // A function/method returning the type
+	UsageField  UsageKind = "field"  // A struct field of the type (the member node)
+)
+
+// queueKind is the kind of a queues vertex: an async boundary between the
+// functions enqueuing work and the ones dequeuing it.
+const queueKind = "queue"
+
+// CallPathOptions configures FindCallPaths.
+type CallPathOptions struct {
+	MaxDepth     int  // Max edges per path; <= 0 uses 4
+	MaxPaths     int  // Max distinct paths; <= 0 uses 1
+	IncludeAsync bool // Also follow async_enqueue/async_dequeue edges through queues
+}
+
+// CallPaths is the result of FindCallPaths.
+type CallPaths struct {
+	Paths [][]GraphNode // Distinct call chains from start to target, shortest first
+
+	// When Paths is empty: the call chain from the start to the reachable
+	// function closest to the target, and how many more calls that function
+	// is from the target (0 when it can't reach the target within maxDepth).
+	Partial    []GraphNode
+	PartialGap int
 }
 
 type GraphEdge struct {
@@ -46,6 +81,13 @@ type GraphEdge struct {
 	Type string
 }
 
+// Page selects a window of a result list. Limit <= 0 uses the query's
+// default page size.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
 type TraversalOptions struct {
 	EdgeTypes []string
 	Direction Direction
@@ -54,12 +96,14 @@ type TraversalOptions struct {
 
 // FileSymbol represents a symbol found in a file (for symbols operation).
 type FileSymbol struct {
-	QName     string
-	Name      string
-	Kind      string
-	Signature string
-	Pos       int
-	End       int
+	QName       string
+	Name        string
+	Kind        string
+	Signature   string
+	Doc         string
+	Pos         int
+	End         int
+	ParentQName string // Type a method or field belongs to (parent edge); "" for top-level symbols
 }
 
 // FileSymbolsOptions configures file symbols query parameters.
@@ -68,12 +112,38 @@ type FileSymbolsOptions struct {
 	Kind     string // Optional: filter by kind (function, method, struct, interface)
 }
 
+// DependentsOptions selects the package whose importers GetDependents
+// returns: Package directly, or the package of File (suffix match).
+type DependentsOptions struct {
+	Package string // Import path, e.g. "basegraph.co/relay/common/arangodb"
+	File    string // Alternative to Package: a file in the package
+}
+
+// DependentFile is a file that imports the requested package.
+type DependentFile struct {
+	Filepath  string `json:"filepath"`
+	Namespace string `json:"namespace"` // Package the importing file belongs to
+}
+
+// DependentsResult is the resolved package and the files importing it.
+type DependentsResult struct {
+	Package string
+	Files   []DependentFile
+}
+
 // SearchOptions configures symbol search parameters.
 type SearchOptions struct {
 	Name      string // Glob pattern: "Plan*", "*Issue*"
 	Kind      string // Filter by kind: function, method, struct, interface
 	File      string // Filter by filepath
 	Namespace string // Filter by module path
+	QName     string // Filter by exact qualified name, ahead of the result cap
+
+	// Exact matches Name as the full symbol name, literally (no globbing).
+	// Default false: Name is a glob anchored to the full name.
+	Exact bool
+	// CaseInsensitive ignores case when matching Name. Default false.
+	CaseInsensitive bool
 }
 
 // SearchResult represents a symbol found by search.
@@ -82,6 +152,7 @@ type SearchResult struct {
 	Name      string
 	Kind      string
 	Signature string
+	Doc       string
 	Filepath  string
 	Pos       int
 }
@@ -94,12 +165,27 @@ type ResolvedSymbol struct {
 	Filepath  string
 	Pos       int
 	Signature string
+	Doc       string
+}
+
+// CallGraphEntry is one row of the exported call graph adjacency list.
+type CallGraphEntry struct {
+	QName   string   `json:"qname"`
+	Callees []string `json:"callees"`
+}
+
+// ResolveResult is one entry of ResolveSymbols: Symbol when the query
+// matched exactly one symbol, else Err (ErrNotFound or AmbiguousSymbolError).
+type ResolveResult struct {
+	Symbol ResolvedSymbol
+	Err    error
 }
 
 // AmbiguousSymbolError is returned when multiple symbols match the query.
 type AmbiguousSymbolError struct {
 	Query      string
 	Candidates []SearchResult
+	Total      int // All matches, of which Candidates is a prefix
 }
 
 func (e AmbiguousSymbolError) Error() string {
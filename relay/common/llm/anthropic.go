@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
@@ -112,8 +113,12 @@ func (c *anthropicClient) Model() string {
 func (c *anthropicClient) convertMessages(msgs []Message) ([]anthropic.TextBlockParam, []anthropic.MessageParam) {
 	var systemContent []anthropic.TextBlockParam
 	messages := make([]anthropic.MessageParam, 0, len(msgs))
+	toolResultsOpen := false // Last message holds only tool results, so more can join it
 
 	for _, msg := range msgs {
+		if msg.Role != "tool" {
+			toolResultsOpen = false
+		}
 		switch msg.Role {
 		case "system":
 			systemContent = append(systemContent, anthropic.TextBlockParam{
@@ -138,14 +143,19 @@ func (c *anthropicClient) convertMessages(msgs []Message) ([]anthropic.TextBlock
 				content = append(content, anthropic.NewTextBlock(msg.Content))
 			}
 
-			// Add tool use blocks for any tool calls
+			// Add tool use blocks for any tool calls. Input must be a JSON
+			// object, so calls without arguments send {}.
 			for _, tc := range msg.ToolCalls {
+				input := tc.Arguments
+				if input == "" {
+					input = "{}"
+				}
 				content = append(content, anthropic.ContentBlockParamUnion{
 					OfToolUse: &anthropic.ToolUseBlockParam{
 						Type:  "tool_use",
 						ID:    tc.ID,
 						Name:  tc.Name,
-						Input: []byte(tc.Arguments),
+						Input: json.RawMessage(input),
 					},
 				})
 			}
@@ -156,14 +166,19 @@ func (c *anthropicClient) convertMessages(msgs []Message) ([]anthropic.TextBlock
 			})
 
 		case "tool":
-			// Tool results in Anthropic are user messages with tool_result content blocks
-			content := []anthropic.ContentBlockParamUnion{
-				anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false),
+			// Tool results in Anthropic are user messages with tool_result content
+			// blocks; all results for one assistant turn go in the same message.
+			block := anthropic.NewToolResultBlock(msg.ToolCallID, msg.Content, false)
+			if toolResultsOpen {
+				last := &messages[len(messages)-1]
+				last.Content = append(last.Content, block)
+				continue
 			}
 			messages = append(messages, anthropic.MessageParam{
 				Role:    anthropic.MessageParamRoleUser,
-				Content: content,
+				Content: []anthropic.ContentBlockParamUnion{block},
 			})
+			toolResultsOpen = true
 		}
 	}
 
@@ -174,20 +189,11 @@ func (c *anthropicClient) convertTools(tools []Tool) []anthropic.ToolUnionParam
 	result := make([]anthropic.ToolUnionParam, len(tools))
 
 	for i, t := range tools {
-		// Convert parameters to InputSchema
-		inputSchema := anthropic.ToolInputSchemaParam{
-			Type: "object",
-		}
-
-		if t.Parameters != nil {
-			inputSchema.Properties = t.Parameters
-		}
-
 		result[i] = anthropic.ToolUnionParam{
 			OfTool: &anthropic.ToolParam{
 				Name:        t.Name,
 				Description: anthropic.String(t.Description),
-				InputSchema: inputSchema,
+				InputSchema: anthropicInputSchema(t.Parameters),
 			},
 		}
 	}
@@ -195,6 +201,41 @@ func (c *anthropicClient) convertTools(tools []Tool) []anthropic.ToolUnionParam
 	return result
 }
 
+// anthropicInputSchema maps a tool's JSON schema (see GenerateSchemaFrom) onto
+// Anthropic's input_schema, which takes properties and required as separate
+// fields. Other keywords such as additionalProperties are passed through.
+func anthropicInputSchema(parameters any) anthropic.ToolInputSchemaParam {
+	schema := anthropic.ToolInputSchemaParam{Type: "object"}
+	if parameters == nil {
+		return schema
+	}
+
+	raw, err := json.Marshal(parameters)
+	if err != nil {
+		return schema
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return schema
+	}
+
+	schema.Properties = fields["properties"]
+	if required, ok := fields["required"].([]any); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+	for _, key := range []string{"type", "properties", "required", "$schema", "$id"} {
+		delete(fields, key)
+	}
+	if len(fields) > 0 {
+		schema.ExtraFields = fields
+	}
+	return schema
+}
+
 func (c *anthropicClient) mapStopReason(reason anthropic.StopReason) string {
 	switch reason {
 	case anthropic.StopReasonEndTurn:
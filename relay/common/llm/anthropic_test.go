@@ -0,0 +1,128 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"basegraph.co/relay/common/llm"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type globArgs struct {
+	Pattern string `json:"pattern" jsonschema:"required,description=Glob pattern"`
+	Path    string `json:"path,omitempty" jsonschema:"description=Directory to search in"`
+}
+
+var _ = Describe("Anthropic client", func() {
+	var (
+		server   *httptest.Server
+		captured map[string]any
+		path     string
+	)
+
+	BeforeEach(func() {
+		captured = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(body, &captured)).To(Succeed())
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{
+				"id": "msg_1",
+				"type": "message",
+				"role": "assistant",
+				"model": "claude-test",
+				"content": [
+					{"type": "text", "text": "Let me look."},
+					{"type": "tool_use", "id": "toolu_2", "name": "glob", "input": {"pattern": "**/*.go"}}
+				],
+				"stop_reason": "tool_use",
+				"stop_sequence": null,
+				"usage": {"input_tokens": 120, "output_tokens": 30}
+			}`)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	newClient := func() llm.AgentClient {
+		client, err := llm.NewAgentClient(llm.Config{
+			Provider: llm.ProviderAnthropic,
+			APIKey:   "test-key",
+			BaseURL:  server.URL,
+			Model:    "claude-test",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return client
+	}
+
+	It("round-trips a tool call through the messages API", func() {
+		resp, err := newClient().ChatWithTools(context.Background(), llm.AgentRequest{
+			Messages: []llm.Message{
+				{Role: "system", Content: "You explore code."},
+				{Role: "user", Content: "Find the Go files."},
+				{Role: "assistant", Content: "Searching.", ToolCalls: []llm.ToolCall{
+					{ID: "toolu_0", Name: "glob", Arguments: `{"pattern":"*.go"}`},
+					{ID: "toolu_1", Name: "glob", Arguments: ""},
+				}},
+				{Role: "tool", ToolCallID: "toolu_0", Content: "main.go"},
+				{Role: "tool", ToolCallID: "toolu_1", Content: "Error: pattern is required"},
+			},
+			Tools: []llm.Tool{{
+				Name:        "glob",
+				Description: "Find files by pattern.",
+				Parameters:  llm.GenerateSchemaFrom(globArgs{}),
+			}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("honouring the BaseURL override")
+		Expect(path).To(Equal("/v1/messages"))
+
+		By("mapping the response back to our types")
+		Expect(resp.Content).To(Equal("Let me look."))
+		Expect(resp.FinishReason).To(Equal("tool_calls"))
+		Expect(resp.PromptTokens).To(Equal(120))
+		Expect(resp.CompletionTokens).To(Equal(30))
+		Expect(resp.ToolCalls).To(HaveLen(1))
+		Expect(resp.ToolCalls[0].ID).To(Equal("toolu_2"))
+		Expect(resp.ToolCalls[0].Name).To(Equal("glob"))
+		Expect(resp.ToolCalls[0].Arguments).To(MatchJSON(`{"pattern":"**/*.go"}`))
+
+		By("sending the system prompt separately")
+		Expect(captured["system"]).To(ConsistOf(HaveKeyWithValue("text", "You explore code.")))
+
+		By("splitting our JSON schema into input_schema fields")
+		tools := captured["tools"].([]any)
+		Expect(tools).To(HaveLen(1))
+		schema := tools[0].(map[string]any)["input_schema"].(map[string]any)
+		Expect(schema["type"]).To(Equal("object"))
+		Expect(schema["properties"]).To(HaveKey("pattern"))
+		Expect(schema["properties"]).To(HaveKey("path"))
+		Expect(schema["required"]).To(ConsistOf("pattern"))
+
+		By("sending tool calls as tool_use blocks with JSON object input")
+		messages := captured["messages"].([]any)
+		Expect(messages).To(HaveLen(3))
+		assistant := messages[1].(map[string]any)
+		Expect(assistant["role"]).To(Equal("assistant"))
+		blocks := assistant["content"].([]any)
+		Expect(blocks).To(HaveLen(3))
+		Expect(blocks[1]).To(HaveKeyWithValue("type", "tool_use"))
+		Expect(blocks[1]).To(HaveKeyWithValue("input", HaveKeyWithValue("pattern", "*.go")))
+		Expect(blocks[2]).To(HaveKeyWithValue("input", BeEmpty()))
+
+		By("grouping a turn's tool results into one user message")
+		results := messages[2].(map[string]any)
+		Expect(results["role"]).To(Equal("user"))
+		resultBlocks := results["content"].([]any)
+		Expect(resultBlocks).To(HaveLen(2))
+		Expect(resultBlocks[0]).To(HaveKeyWithValue("tool_use_id", "toolu_0"))
+		Expect(resultBlocks[1]).To(HaveKeyWithValue("tool_use_id", "toolu_1"))
+	})
+})
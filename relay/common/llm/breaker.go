@@ -0,0 +1,189 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while the circuit
+// breaker is open, i.e. after too many recent calls failed.
+var ErrCircuitOpen = errors.New("llm circuit breaker open")
+
+// BreakerConfig configures the circuit breaker NewAgentClient puts in front
+// of the provider. A zero FailureRate disables the breaker; other zero
+// fields take their DefaultBreakerConfig values.
+type BreakerConfig struct {
+	FailureRate float64       // Fraction of failed calls in the window that opens the breaker (0 = off)
+	Window      int           // How many recent calls the failure rate is computed over
+	MinCalls    int           // Calls needed in the window before the breaker can open
+	Cooldown    time.Duration // How long the breaker stays open before letting a probe call through
+}
+
+// DefaultBreakerConfig opens after half of the last 20 calls failed (at
+// least 5 calls seen) and probes again after 30s.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureRate: 0.5,
+		Window:      20,
+		MinCalls:    5,
+		Cooldown:    30 * time.Second,
+	}
+}
+
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	def := DefaultBreakerConfig()
+	if c.Window <= 0 {
+		c.Window = def.Window
+	}
+	if c.MinCalls <= 0 {
+		c.MinCalls = def.MinCalls
+	}
+	if c.MinCalls > c.Window {
+		c.MinCalls = c.Window
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = def.Cooldown
+	}
+	return c
+}
+
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // Calls go through, outcomes are recorded
+	breakerOpen                         // Calls fail fast with ErrCircuitOpen
+	breakerHalfOpen                     // One probe call is in flight
+)
+
+// circuitBreaker wraps an AgentClient. While closed it records the outcome
+// of each call; once the failure rate over the window reaches the threshold
+// it opens and rejects calls for the cooldown. After that a single probe is
+// let through (half-open): success closes the breaker, failure reopens it.
+type circuitBreaker struct {
+	client AgentClient
+	cfg    BreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	outcomes []bool // Ring buffer of recent results, true = failure
+	next     int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker wraps client with a circuit breaker. cfg.FailureRate
+// must be positive.
+func NewCircuitBreaker(client AgentClient, cfg BreakerConfig) AgentClient {
+	return newCircuitBreaker(client, cfg, time.Now)
+}
+
+func newCircuitBreaker(client AgentClient, cfg BreakerConfig, now func() time.Time) *circuitBreaker {
+	cfg = cfg.withDefaults()
+	return &circuitBreaker{
+		client:   client,
+		cfg:      cfg,
+		now:      now,
+		outcomes: make([]bool, 0, cfg.Window),
+	}
+}
+
+func (b *circuitBreaker) Model() string { return b.client.Model() }
+
+func (b *circuitBreaker) ChatWithTools(ctx context.Context, req AgentRequest) (*AgentResponse, error) {
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.client.ChatWithTools(ctx, req)
+	// The caller giving up says nothing about the provider's health.
+	if err != nil && ctx.Err() != nil {
+		if probe {
+			b.releaseProbe()
+		}
+		return resp, err
+	}
+	b.record(ctx, probe, err != nil)
+	return resp, err
+}
+
+// allow decides whether a call may go through. Once an open breaker's
+// cooldown has passed, the next call is the half-open probe.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		wait := b.cfg.Cooldown - b.now().Sub(b.openedAt)
+		if wait > 0 {
+			return false, fmt.Errorf("%w: %s, retry in %s", ErrCircuitOpen, b.client.Model(), wait.Round(time.Second))
+		}
+		b.state = breakerHalfOpen
+		return true, nil
+	case breakerHalfOpen:
+		return false, fmt.Errorf("%w: %s, probe in flight", ErrCircuitOpen, b.client.Model())
+	default:
+		return false, nil
+	}
+}
+
+// releaseProbe puts the breaker back to open with the cooldown already
+// elapsed, so a cancelled probe doesn't leave it stuck half-open.
+func (b *circuitBreaker) releaseProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerOpen
+	b.openedAt = b.now().Add(-b.cfg.Cooldown)
+}
+
+func (b *circuitBreaker) record(ctx context.Context, probe, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		if failed {
+			b.open(ctx, "probe call failed")
+			return
+		}
+		slog.InfoContext(ctx, "llm circuit breaker closed", "model", b.client.Model())
+		b.state = breakerClosed
+		b.outcomes = b.outcomes[:0]
+		b.next = 0
+		return
+	}
+
+	if len(b.outcomes) < b.cfg.Window {
+		b.outcomes = append(b.outcomes, failed)
+	} else {
+		b.outcomes[b.next] = failed
+		b.next = (b.next + 1) % b.cfg.Window
+	}
+
+	if b.state != breakerClosed || len(b.outcomes) < b.cfg.MinCalls {
+		return
+	}
+	failures := 0
+	for _, f := range b.outcomes {
+		if f {
+			failures++
+		}
+	}
+	if float64(failures)/float64(len(b.outcomes)) >= b.cfg.FailureRate {
+		b.open(ctx, fmt.Sprintf("%d of the last %d calls failed", failures, len(b.outcomes)))
+	}
+}
+
+func (b *circuitBreaker) open(ctx context.Context, reason string) {
+	b.state = breakerOpen
+	b.openedAt = b.now()
+	slog.WarnContext(ctx, "llm circuit breaker opened",
+		"model", b.client.Model(),
+		"reason", reason,
+		"cooldown", b.cfg.Cooldown)
+}
@@ -0,0 +1,139 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"basegraph.co/relay/common/llm"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// flakyClient fails while failing is set and counts the calls that reach it.
+type flakyClient struct {
+	mu      sync.Mutex
+	failing bool
+	calls   int
+}
+
+func (f *flakyClient) ChatWithTools(ctx context.Context, req llm.AgentRequest) (*llm.AgentResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.failing {
+		return nil, errors.New("429 too many requests")
+	}
+	return &llm.AgentResponse{Content: "ok"}, nil
+}
+
+func (f *flakyClient) Model() string { return "flaky" }
+
+func (f *flakyClient) setFailing(failing bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failing = failing
+}
+
+func (f *flakyClient) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+var _ = Describe("CircuitBreaker", func() {
+	var (
+		ctx      context.Context
+		provider *flakyClient
+		client   llm.AgentClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		provider = &flakyClient{}
+		client = llm.NewCircuitBreaker(provider, llm.BreakerConfig{
+			FailureRate: 0.5,
+			Window:      4,
+			MinCalls:    4,
+			Cooldown:    50 * time.Millisecond,
+		})
+	})
+
+	failCalls := func(n int) {
+		for range n {
+			_, err := client.ChatWithTools(ctx, llm.AgentRequest{})
+			Expect(err).To(HaveOccurred())
+		}
+	}
+
+	It("passes calls through while the provider is healthy", func() {
+		for range 10 {
+			resp, err := client.ChatWithTools(ctx, llm.AgentRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Content).To(Equal("ok"))
+		}
+		Expect(provider.callCount()).To(Equal(10))
+	})
+
+	It("opens after sustained failures and short-circuits calls", func() {
+		provider.setFailing(true)
+		failCalls(4)
+		Expect(provider.callCount()).To(Equal(4))
+
+		_, err := client.ChatWithTools(ctx, llm.AgentRequest{})
+		Expect(err).To(MatchError(llm.ErrCircuitOpen))
+		Expect(provider.callCount()).To(Equal(4))
+	})
+
+	It("does not open before MinCalls calls", func() {
+		provider.setFailing(true)
+		failCalls(3)
+
+		_, err := client.ChatWithTools(ctx, llm.AgentRequest{})
+		Expect(errors.Is(err, llm.ErrCircuitOpen)).To(BeFalse())
+		Expect(provider.callCount()).To(Equal(4))
+	})
+
+	It("closes again after a successful half-open probe", func() {
+		provider.setFailing(true)
+		failCalls(4)
+
+		provider.setFailing(false)
+		time.Sleep(60 * time.Millisecond)
+
+		resp, err := client.ChatWithTools(ctx, llm.AgentRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Content).To(Equal("ok"))
+
+		_, err = client.ChatWithTools(ctx, llm.AgentRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(provider.callCount()).To(Equal(6))
+	})
+
+	It("reopens when the half-open probe fails", func() {
+		provider.setFailing(true)
+		failCalls(4)
+		time.Sleep(60 * time.Millisecond)
+
+		_, err := client.ChatWithTools(ctx, llm.AgentRequest{})
+		Expect(errors.Is(err, llm.ErrCircuitOpen)).To(BeFalse())
+		Expect(provider.callCount()).To(Equal(5))
+
+		_, err = client.ChatWithTools(ctx, llm.AgentRequest{})
+		Expect(err).To(MatchError(llm.ErrCircuitOpen))
+		Expect(provider.callCount()).To(Equal(5))
+	})
+
+	It("ignores failures caused by the caller cancelling", func() {
+		provider.setFailing(true)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		for range 10 {
+			_, err := client.ChatWithTools(cancelled, llm.AgentRequest{})
+			Expect(errors.Is(err, llm.ErrCircuitOpen)).To(BeFalse())
+		}
+		Expect(provider.callCount()).To(Equal(10))
+	})
+})
@@ -33,6 +33,7 @@ type Config struct {
 	BaseURL         string          // Optional: custom API endpoint
 	Model           string          // Model name (e.g., "gpt-5.1", "claude-sonnet-4-5-20250514")
 	ReasoningEffort ReasoningEffort // Optional: for models that support reasoning (gpt-5.1, o1, o3)
+	Breaker         BreakerConfig   // Optional: fail fast with ErrCircuitOpen while the provider keeps failing
 }
 
 // AgentClient supports tool-calling conversations for agent loops.
@@ -96,14 +97,24 @@ func NewAgentClient(cfg Config) (AgentClient, error) {
 		provider = ProviderAnthropic
 	}
 
+	var client AgentClient
+	var err error
 	switch provider {
 	case ProviderAnthropic:
-		return NewAnthropicClient(cfg)
+		client, err = NewAnthropicClient(cfg)
 	case ProviderOpenAI:
-		return newOpenAIClient(cfg)
+		client, err = newOpenAIClient(cfg)
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %s", provider)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Breaker.FailureRate > 0 {
+		client = NewCircuitBreaker(client, cfg.Breaker)
+	}
+	return client, nil
 }
 
 // ParseToolArguments unmarshals tool arguments into the target struct.
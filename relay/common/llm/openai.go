@@ -52,6 +52,77 @@ func newOpenAIClient(cfg Config) (AgentClient, error) {
 }
 
 func (c *openaiClient) ChatWithTools(ctx context.Context, req AgentRequest) (*AgentResponse, error) {
+	params := c.buildParams(req)
+
+	start := time.Now()
+	resp, err := c.client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("openai chat with tools: %w", err)
+	}
+
+	return c.convertResponse(ctx, resp, start)
+}
+
+// ChatWithToolsStream streams a turn. Content and tool call deltas are sent
+// as they arrive; the chunks are also accumulated into the final response.
+func (c *openaiClient) ChatWithToolsStream(ctx context.Context, req AgentRequest) (<-chan StreamEvent, error) {
+	params := c.buildParams(req)
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+
+	start := time.Now()
+	stream := c.client.Chat.Completions.NewStreaming(ctx, params)
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("openai chat with tools stream: %w", err)
+	}
+
+	events := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+		defer stream.Close()
+
+		var acc openai.ChatCompletionAccumulator
+		for stream.Next() {
+			chunk := stream.Current()
+			acc.AddChunk(chunk)
+			if len(chunk.Choices) == 0 {
+				continue
+			}
+
+			delta := chunk.Choices[0].Delta
+			if delta.Content != "" {
+				if !sendStreamEvent(ctx, events, StreamEvent{ContentDelta: delta.Content}) {
+					return
+				}
+			}
+			for _, tc := range delta.ToolCalls {
+				ev := StreamEvent{ToolCallDelta: &ToolCallDelta{
+					Index:     int(tc.Index),
+					ID:        tc.ID,
+					Name:      tc.Function.Name,
+					Arguments: tc.Function.Arguments,
+				}}
+				if !sendStreamEvent(ctx, events, ev) {
+					return
+				}
+			}
+		}
+		if err := stream.Err(); err != nil {
+			sendStreamEvent(ctx, events, StreamEvent{Err: fmt.Errorf("openai chat with tools stream: %w", err)})
+			return
+		}
+
+		resp, err := c.convertResponse(ctx, &acc.ChatCompletion, start)
+		if err != nil {
+			sendStreamEvent(ctx, events, StreamEvent{Err: err})
+			return
+		}
+		sendStreamEvent(ctx, events, StreamEvent{Response: resp})
+	}()
+
+	return events, nil
+}
+
+func (c *openaiClient) buildParams(req AgentRequest) openai.ChatCompletionNewParams {
 	maxTokens := req.MaxTokens
 	if maxTokens == 0 {
 		maxTokens = 16000
@@ -81,25 +152,23 @@ func (c *openaiClient) ChatWithTools(ctx context.Context, req AgentRequest) (*Ag
 		params.ReasoningEffort = c.reasoningEffort
 	}
 
-	start := time.Now()
-	resp, err := c.client.Chat.Completions.New(ctx, params)
-	if err != nil {
-		return nil, fmt.Errorf("openai chat with tools: %w", err)
+	return params
+}
+
+func (c *openaiClient) convertResponse(ctx context.Context, resp *openai.ChatCompletion, start time.Time) (*AgentResponse, error) {
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
 	}
 
+	choice := resp.Choices[0]
 	slog.DebugContext(ctx, "agent chat completed",
 		"model", c.model,
 		"duration_ms", time.Since(start).Milliseconds(),
 		"prompt_tokens", resp.Usage.PromptTokens,
 		"completion_tokens", resp.Usage.CompletionTokens,
 		"reasoning_tokens", resp.Usage.CompletionTokensDetails.ReasoningTokens,
-		"finish_reason", resp.Choices[0].FinishReason)
+		"finish_reason", choice.FinishReason)
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no choices in response")
-	}
-
-	choice := resp.Choices[0]
 	result := &AgentResponse{
 		Content:          choice.Message.Content,
 		FinishReason:     string(choice.FinishReason),
@@ -0,0 +1,151 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"basegraph.co/relay/common/llm"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenAI client streaming", func() {
+	var (
+		server   *httptest.Server
+		captured map[string]any
+		chunks   []string
+	)
+
+	BeforeEach(func() {
+		captured = nil
+		chunks = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(body, &captured)).To(Succeed())
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, chunk := range chunks {
+				_, _ = fmt.Fprintf(w, "data: %s\n\n", chunk)
+				w.(http.Flusher).Flush()
+			}
+			_, _ = io.WriteString(w, "data: [DONE]\n\n")
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	newClient := func() llm.StreamingClient {
+		client, err := llm.NewAgentClient(llm.Config{
+			Provider: llm.ProviderOpenAI,
+			APIKey:   "test-key",
+			BaseURL:  server.URL,
+			Model:    "gpt-test",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		streamer, ok := client.(llm.StreamingClient)
+		Expect(ok).To(BeTrue())
+		return streamer
+	}
+
+	chunk := func(delta, finish string) string {
+		finishReason := "null"
+		if finish != "" {
+			finishReason = fmt.Sprintf("%q", finish)
+		}
+		return fmt.Sprintf(`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"gpt-test","choices":[{"index":0,"delta":%s,"finish_reason":%s}]}`, delta, finishReason)
+	}
+	usage := `{"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"gpt-test","choices":[],"usage":{"prompt_tokens":42,"completion_tokens":7,"total_tokens":49}}`
+
+	It("streams content deltas and assembles the final response", func() {
+		chunks = []string{
+			chunk(`{"role":"assistant","content":"The entry "}`, ""),
+			chunk(`{"content":"point is main."}`, ""),
+			chunk(`{}`, "stop"),
+			usage,
+		}
+
+		events, err := newClient().ChatWithToolsStream(context.Background(), llm.AgentRequest{
+			Messages: []llm.Message{{Role: "user", Content: "Where does it start?"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var deltas []string
+		resp, err := llm.CollectStream(events, func(delta string) { deltas = append(deltas, delta) })
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(deltas).To(Equal([]string{"The entry ", "point is main."}))
+		Expect(resp.Content).To(Equal("The entry point is main."))
+		Expect(resp.FinishReason).To(Equal("stop"))
+		Expect(resp.PromptTokens).To(Equal(42))
+		Expect(resp.CompletionTokens).To(Equal(7))
+
+		By("asking for a stream with usage")
+		Expect(captured["stream"]).To(BeTrue())
+		Expect(captured["stream_options"]).To(HaveKeyWithValue("include_usage", true))
+	})
+
+	It("streams tool call fragments and joins their arguments", func() {
+		chunks = []string{
+			chunk(`{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"glob","arguments":""}}]}`, ""),
+			chunk(`{"tool_calls":[{"index":0,"function":{"arguments":"{\"pattern\":"}}]}`, ""),
+			chunk(`{"tool_calls":[{"index":0,"function":{"arguments":"\"*.go\"}"}}]}`, ""),
+			chunk(`{}`, "tool_calls"),
+			usage,
+		}
+
+		events, err := newClient().ChatWithToolsStream(context.Background(), llm.AgentRequest{
+			Messages: []llm.Message{{Role: "user", Content: "Find the Go files."}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var fragments []llm.ToolCallDelta
+		var resp *llm.AgentResponse
+		for ev := range events {
+			Expect(ev.Err).NotTo(HaveOccurred())
+			if ev.ToolCallDelta != nil {
+				fragments = append(fragments, *ev.ToolCallDelta)
+			}
+			if ev.Response != nil {
+				resp = ev.Response
+			}
+		}
+
+		Expect(fragments).To(HaveLen(3))
+		Expect(fragments[0].ID).To(Equal("call_1"))
+		Expect(fragments[0].Name).To(Equal("glob"))
+		Expect(fragments[2].Arguments).To(Equal(`"*.go"}`))
+
+		Expect(resp).NotTo(BeNil())
+		Expect(resp.FinishReason).To(Equal("tool_calls"))
+		Expect(resp.ToolCalls).To(HaveLen(1))
+		Expect(resp.ToolCalls[0].ID).To(Equal("call_1"))
+		Expect(resp.ToolCalls[0].Name).To(Equal("glob"))
+		Expect(resp.ToolCalls[0].Arguments).To(MatchJSON(`{"pattern":"*.go"}`))
+	})
+
+	It("reports a failed request as an error", func() {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":{"message":"bad model","type":"invalid_request_error"}}`)
+		}))
+		DeferCleanup(failing.Close)
+
+		client, err := llm.NewAgentClient(llm.Config{
+			Provider: llm.ProviderOpenAI,
+			APIKey:   "test-key",
+			BaseURL:  failing.URL,
+			Model:    "gpt-test",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.(llm.StreamingClient).ChatWithToolsStream(context.Background(), llm.AgentRequest{
+			Messages: []llm.Message{{Role: "user", Content: "hi"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("bad model")))
+	})
+})
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// StreamingClient is implemented by clients that can stream a turn as it is
+// generated. It is optional: callers type-assert for it and fall back to
+// ChatWithTools otherwise. Wrapped clients (e.g. behind the circuit breaker)
+// do not expose it.
+type StreamingClient interface {
+	AgentClient
+	// ChatWithToolsStream starts a turn and returns a channel of deltas. The
+	// channel is closed after a final event carrying either Response (the
+	// assembled turn, as ChatWithTools would return it) or Err.
+	ChatWithToolsStream(ctx context.Context, req AgentRequest) (<-chan StreamEvent, error)
+}
+
+// StreamEvent is one event of a streamed turn. Exactly one field is set.
+type StreamEvent struct {
+	ContentDelta  string         // Next piece of the text response
+	ToolCallDelta *ToolCallDelta // Next piece of a tool call
+	Response      *AgentResponse // Final event: the complete turn
+	Err           error          // Final event: the stream failed
+}
+
+// ToolCallDelta is a fragment of a tool call. ID and Name arrive with the
+// first fragment of a call; Arguments accumulate across fragments with the
+// same Index.
+type ToolCallDelta struct {
+	Index     int
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// CollectStream drains a stream, passing each content delta to onContent
+// (which may be nil), and returns the final response.
+func CollectStream(events <-chan StreamEvent, onContent func(delta string)) (*AgentResponse, error) {
+	for ev := range events {
+		switch {
+		case ev.Err != nil:
+			return nil, ev.Err
+		case ev.Response != nil:
+			return ev.Response, nil
+		case ev.ContentDelta != "" && onContent != nil:
+			onContent(ev.ContentDelta)
+		}
+	}
+	return nil, fmt.Errorf("stream closed without a response")
+}
+
+// sendStreamEvent delivers ev unless the consumer has gone away.
+func sendStreamEvent(ctx context.Context, events chan<- StreamEvent, ev StreamEvent) bool {
+	select {
+	case events <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
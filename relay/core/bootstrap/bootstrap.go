@@ -0,0 +1,77 @@
+// Package bootstrap retries dependency connections at startup, so a service
+// waits for Postgres, Redis or ArangoDB to come up instead of crash-looping
+// when containers start out of order.
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Policy bounds how long and how often a connection is retried.
+type Policy struct {
+	Window       time.Duration // Total time to keep retrying before giving up
+	InitialDelay time.Duration // Delay after the first failure; doubles each attempt
+	MaxDelay     time.Duration // Cap on the delay between attempts
+}
+
+// DefaultPolicy retries for up to a minute, backing off from 500ms to 5s.
+func DefaultPolicy() Policy {
+	return Policy{
+		Window:       time.Minute,
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     5 * time.Second,
+	}
+}
+
+// Connect calls connect until it succeeds, the policy window elapses, or ctx
+// is cancelled. name identifies the dependency in logs and errors.
+func Connect[T any](ctx context.Context, name string, p Policy, connect func(context.Context) (T, error)) (T, error) {
+	deadline := time.Now().Add(p.Window)
+	delay := p.InitialDelay
+
+	for attempt := 1; ; attempt++ {
+		v, err := connect(ctx)
+		if err == nil {
+			if attempt > 1 {
+				slog.InfoContext(ctx, "dependency ready", "dependency", name, "attempts", attempt)
+			}
+			return v, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			var zero T
+			return zero, fmt.Errorf("%s not ready after %d attempts in %s: %w", name, attempt, p.Window, err)
+		}
+		wait := min(delay, remaining)
+
+		slog.WarnContext(ctx, "dependency not ready, retrying",
+			"dependency", name,
+			"attempt", attempt,
+			"retry_in", wait,
+			"error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			var zero T
+			return zero, fmt.Errorf("waiting for %s: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+
+		delay = min(delay*2, p.MaxDelay)
+	}
+}
+
+// Wait is Connect for dependencies that only need a readiness check, such as
+// pinging an already-constructed client.
+func Wait(ctx context.Context, name string, p Policy, check func(context.Context) error) error {
+	_, err := Connect(ctx, name, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, check(ctx)
+	})
+	return err
+}
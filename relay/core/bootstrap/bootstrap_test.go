@@ -0,0 +1,87 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyDep refuses the first refusals connection attempts.
+type flakyDep struct {
+	refusals int
+	attempts int
+}
+
+func (d *flakyDep) connect(context.Context) (string, error) {
+	d.attempts++
+	if d.attempts <= d.refusals {
+		return "", errors.New("connection refused")
+	}
+	return "conn", nil
+}
+
+func testPolicy(window time.Duration) Policy {
+	return Policy{Window: window, InitialDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond}
+}
+
+func TestConnectRetriesUntilReady(t *testing.T) {
+	dep := &flakyDep{refusals: 3}
+
+	conn, err := Connect(context.Background(), "postgres", testPolicy(time.Second), dep.connect)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if conn != "conn" {
+		t.Errorf("Connect() = %q, want conn", conn)
+	}
+	if dep.attempts != 4 {
+		t.Errorf("attempts = %d, want 4", dep.attempts)
+	}
+}
+
+func TestConnectGivesUpAfterWindow(t *testing.T) {
+	dep := &flakyDep{refusals: 1 << 30}
+
+	start := time.Now()
+	_, err := Connect(context.Background(), "redis", testPolicy(20*time.Millisecond), dep.connect)
+	if err == nil {
+		t.Fatal("Connect() succeeded against a dependency that never comes up")
+	}
+	if !strings.Contains(err.Error(), "redis not ready") || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("error = %q, want dependency name and last cause", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Connect() took %s, want it bounded by the window", elapsed)
+	}
+	if dep.attempts < 2 {
+		t.Errorf("attempts = %d, want retries within the window", dep.attempts)
+	}
+}
+
+func TestConnectStopsOnCancel(t *testing.T) {
+	dep := &flakyDep{refusals: 1 << 30}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Connect(ctx, "arangodb", testPolicy(time.Minute), dep.connect)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	dep := &flakyDep{refusals: 2}
+
+	err := Wait(context.Background(), "redis", testPolicy(time.Second), func(ctx context.Context) error {
+		_, err := dep.connect(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if dep.attempts != 3 {
+		t.Errorf("attempts = %d, want 3", dep.attempts)
+	}
+}
@@ -60,6 +60,11 @@ func (db *DB) Close() {
 	db.pool.Close()
 }
 
+// Ping checks that the database is reachable.
+func (db *DB) Ping(ctx context.Context) error {
+	return db.pool.Ping(ctx)
+}
+
 // Queries returns a new Queries instance for non-transactional operations.
 func (db *DB) Queries() *sqlc.Queries {
 	return sqlc.New(db.pool)
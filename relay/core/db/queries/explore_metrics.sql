@@ -0,0 +1,16 @@
+-- name: InsertExploreMetrics :exec
+INSERT INTO explore_metrics (
+    id, session_id, query, thoroughness,
+    started_at, duration_ms, iterations,
+    context_window_tokens, completion_tokens,
+    codegraph_calls, codegraph_invalid_kind, codegraph_ambiguous,
+    codegraph_trace_found, codegraph_trace_not_found,
+    confidence, termination_reason, metrics
+) VALUES (
+    $1, $2, $3, $4,
+    $5, $6, $7,
+    $8, $9,
+    $10, $11, $12,
+    $13, $14,
+    $15, $16, $17
+);
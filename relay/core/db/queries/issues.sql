@@ -143,3 +143,14 @@ UPDATE issues
 SET spec_status = $2,
     updated_at = now()
 WHERE id = $1;
+
+-- name: ListIssuesWithFindings :many
+-- Recent issues in an integration that have code findings, excluding one issue.
+-- Used to surface prior work touching the same files when drafting a spec.
+SELECT * FROM issues
+WHERE integration_id = $1
+  AND id != $2
+  AND jsonb_typeof(code_findings) = 'array'
+  AND jsonb_array_length(code_findings) > 0
+ORDER BY updated_at DESC
+LIMIT $3;
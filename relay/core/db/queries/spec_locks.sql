@@ -0,0 +1,14 @@
+-- name: AcquireSpecLock :execrows
+-- Take the spec lock for an issue. Succeeds (1 row) if no lock exists or the
+-- existing lock has expired; 0 rows means another worker holds it.
+INSERT INTO spec_locks (issue_id, owner, locked_until)
+VALUES ($1, $2, now() + make_interval(secs => sqlc.arg(ttl_seconds)::float8))
+ON CONFLICT (issue_id) DO UPDATE
+SET owner = EXCLUDED.owner,
+    locked_until = EXCLUDED.locked_until,
+    created_at = now()
+WHERE spec_locks.locked_until < now();
+
+-- name: ReleaseSpecLock :exec
+-- Release the spec lock if still held by owner.
+DELETE FROM spec_locks WHERE issue_id = $1 AND owner = $2;
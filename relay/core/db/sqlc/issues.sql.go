@@ -165,6 +165,68 @@ func (q *Queries) GetIssueForUpdate(ctx context.Context, id int64) (Issue, error
 	return i, err
 }
 
+const listIssuesWithFindings = `-- name: ListIssuesWithFindings :many
+SELECT id, integration_id, external_project_id, external_issue_id, provider, title, description, labels, members, assignees, reporter, external_issue_url, keywords, code_findings, learnings, discussions, spec, spec_status, processing_status, processing_started_at, last_processed_at, created_at, updated_at FROM issues
+WHERE integration_id = $1
+  AND id != $2
+  AND jsonb_typeof(code_findings) = 'array'
+  AND jsonb_array_length(code_findings) > 0
+ORDER BY updated_at DESC
+LIMIT $3
+`
+
+type ListIssuesWithFindingsParams struct {
+	IntegrationID int64 `json:"integration_id"`
+	ID            int64 `json:"id"`
+	Limit         int32 `json:"limit"`
+}
+
+// Recent issues in an integration that have code findings, excluding one issue.
+// Used to surface prior work touching the same files when drafting a spec.
+func (q *Queries) ListIssuesWithFindings(ctx context.Context, arg ListIssuesWithFindingsParams) ([]Issue, error) {
+	rows, err := q.db.Query(ctx, listIssuesWithFindings, arg.IntegrationID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Issue
+	for rows.Next() {
+		var i Issue
+		if err := rows.Scan(
+			&i.ID,
+			&i.IntegrationID,
+			&i.ExternalProjectID,
+			&i.ExternalIssueID,
+			&i.Provider,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Members,
+			&i.Assignees,
+			&i.Reporter,
+			&i.ExternalIssueUrl,
+			&i.Keywords,
+			&i.CodeFindings,
+			&i.Learnings,
+			&i.Discussions,
+			&i.Spec,
+			&i.SpecStatus,
+			&i.ProcessingStatus,
+			&i.ProcessingStartedAt,
+			&i.LastProcessedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const queueIssueIfIdle = `-- name: QueueIssueIfIdle :one
 UPDATE issues
 SET processing_status = 'queued',
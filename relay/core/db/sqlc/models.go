@@ -23,6 +23,27 @@ type EventLog struct {
 	CreatedAt           pgtype.Timestamptz `json:"created_at"`
 }
 
+type ExploreMetric struct {
+	ID                     int64              `json:"id"`
+	SessionID              string             `json:"session_id"`
+	Query                  string             `json:"query"`
+	Thoroughness           string             `json:"thoroughness"`
+	StartedAt              pgtype.Timestamptz `json:"started_at"`
+	DurationMs             int64              `json:"duration_ms"`
+	Iterations             int32              `json:"iterations"`
+	ContextWindowTokens    int32              `json:"context_window_tokens"`
+	CompletionTokens       int32              `json:"completion_tokens"`
+	CodegraphCalls         int32              `json:"codegraph_calls"`
+	CodegraphInvalidKind   int32              `json:"codegraph_invalid_kind"`
+	CodegraphAmbiguous     int32              `json:"codegraph_ambiguous"`
+	CodegraphTraceFound    int32              `json:"codegraph_trace_found"`
+	CodegraphTraceNotFound int32              `json:"codegraph_trace_not_found"`
+	Confidence             string             `json:"confidence"`
+	TerminationReason      string             `json:"termination_reason"`
+	Metrics                []byte             `json:"metrics"`
+	CreatedAt              pgtype.Timestamptz `json:"created_at"`
+}
+
 type Gap struct {
 	ID      int64 `json:"id"`
 	ShortID int64 `json:"short_id"`
@@ -189,6 +210,13 @@ type Session struct {
 	ExpiresAt       pgtype.Timestamptz `json:"expires_at"`
 }
 
+type SpecLock struct {
+	IssueID     int64              `json:"issue_id"`
+	Owner       string             `json:"owner"`
+	LockedUntil pgtype.Timestamptz `json:"locked_until"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type User struct {
 	ID        int64              `json:"id"`
 	Name      string             `json:"name"`
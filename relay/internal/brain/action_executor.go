@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
+	"time"
 
 	"basegraph.co/relay/common/id"
 	"basegraph.co/relay/internal/model"
@@ -17,6 +18,10 @@ import (
 
 const maxCodeFindings = 20
 
+// specLockTTL bounds how long a crashed worker can block spec generation for an
+// issue. It must outlast a normal generation run.
+const specLockTTL = 20 * time.Minute
+
 // Comment size limits per provider
 const (
 	gitlabCommentLimit  = 1000000 // ~1M chars
@@ -32,7 +37,10 @@ type actionExecutor struct {
 	gaps          store.GapStore
 	integrations  store.IntegrationStore
 	learnings     store.LearningStore
+	specLocks     store.SpecLockStore // nil = no cross-worker locking
 	specGenerator *SpecGenerator
+
+	relatedIssueLimit int // Max related past issues in the spec prompt (0 = disabled)
 }
 
 func NewActionExecutor(
@@ -42,7 +50,9 @@ func NewActionExecutor(
 	gaps store.GapStore,
 	integrations store.IntegrationStore,
 	learnings store.LearningStore,
+	specLocks store.SpecLockStore,
 	specGenerator *SpecGenerator,
+	relatedIssueLimit int,
 ) actionExecutor {
 	return actionExecutor{
 		issueTracker:  issueTracker,
@@ -51,7 +61,10 @@ func NewActionExecutor(
 		gaps:          gaps,
 		integrations:  integrations,
 		learnings:     learnings,
+		specLocks:     specLocks,
 		specGenerator: specGenerator,
+
+		relatedIssueLimit: relatedIssueLimit,
 	}
 }
 
@@ -355,6 +368,54 @@ func (e *actionExecutor) executeReadyForSpecGeneration(ctx context.Context, issu
 		"has_decisions", hasDecisions,
 		"proceed_signal", data.ProceedSignal)
 
+	ran, err := e.withSpecLock(ctx, issue.ID, func(ctx context.Context) error {
+		return e.generateSpec(ctx, issue, data)
+	})
+	if err != nil {
+		return err
+	}
+	if !ran {
+		slog.InfoContext(ctx, "spec generation already in progress, skipping",
+			"issue_id", issue.ID)
+	}
+	return nil
+}
+
+// withSpecLock runs fn while holding the issue's spec lock. If another worker
+// holds it, fn is skipped and ran is false. The lock is released when fn
+// returns, and expires after specLockTTL if the worker dies first.
+func (e *actionExecutor) withSpecLock(ctx context.Context, issueID int64, fn func(context.Context) error) (ran bool, err error) {
+	if e.specLocks == nil {
+		return true, fn(ctx)
+	}
+
+	owner := strconv.FormatInt(id.New(), 10)
+	acquired, err := e.specLocks.TryAcquire(ctx, issueID, owner, specLockTTL)
+	if err != nil {
+		return false, fmt.Errorf("acquiring spec lock: %w", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	defer func() {
+		// Release even if ctx was cancelled; otherwise the lock lingers until TTL.
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if releaseErr := e.specLocks.Release(releaseCtx, issueID, owner); releaseErr != nil {
+			slog.WarnContext(ctx, "failed to release spec lock",
+				"issue_id", issueID,
+				"error", releaseErr)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, specLockTTL)
+	defer cancel()
+
+	return true, fn(ctx)
+}
+
+func (e *actionExecutor) generateSpec(ctx context.Context, issue model.Issue, data ReadyForSpecGenerationAction) error {
 	// Refresh issue to avoid overwriting concurrent updates (e.g., findings)
 	freshIssue, err := e.issues.GetByID(ctx, issue.ID)
 	if err != nil {
@@ -407,6 +468,7 @@ func (e *actionExecutor) executeReadyForSpecGeneration(ctx context.Context, issu
 		Gaps:           closedGaps,
 		Findings:       issue.CodeFindings,
 		Learnings:      learnings,
+		RelatedIssues:  e.findRelatedIssues(ctx, issue),
 		ProceedSignal:  data.ProceedSignal,
 	}
 
@@ -415,7 +477,8 @@ func (e *actionExecutor) executeReadyForSpecGeneration(ctx context.Context, issu
 		"issue_id", issue.ID,
 		"gaps", len(closedGaps),
 		"findings", len(issue.CodeFindings),
-		"learnings", len(learnings))
+		"learnings", len(learnings),
+		"related_issues", len(input.RelatedIssues))
 
 	output, err := e.specGenerator.Generate(ctx, input)
 	if err != nil {
@@ -425,7 +488,10 @@ func (e *actionExecutor) executeReadyForSpecGeneration(ctx context.Context, issu
 
 	slog.InfoContext(ctx, "spec generated",
 		"issue_id", issue.ID,
-		"spec_length", len(output.Spec))
+		"spec_length", len(output.Spec),
+		"task_count", output.Metadata.TaskCount,
+		"decision_count", output.Metadata.DecisionCount,
+		"estimated_effort", output.Metadata.EstimatedEffort)
 
 	// 6. Post spec to issue tracker (with splitting if needed)
 	if err := e.postSpec(ctx, issue, output.Spec); err != nil {
@@ -433,8 +499,10 @@ func (e *actionExecutor) executeReadyForSpecGeneration(ctx context.Context, issu
 		return fmt.Errorf("posting spec: %w", err)
 	}
 
-	// 7. Store spec without overwriting other fields (mark completed)
-	if err := e.issues.UpdateSpec(ctx, issue.ID, &output.Spec); err != nil {
+	// 7. Store spec without overwriting other fields (mark completed).
+	// Frontmatter, when enabled, is stored but never posted.
+	stored := output.Frontmatter + output.Spec
+	if err := e.issues.UpdateSpec(ctx, issue.ID, &stored); err != nil {
 		e.postSpecError(ctx, issue, ackPosted, "Failed to save spec")
 		return fmt.Errorf("storing spec: %w", err)
 	}
@@ -464,6 +532,28 @@ func (e *actionExecutor) executeSetSpecStatus(ctx context.Context, issue model.I
 	return e.issues.UpdateSpecStatus(ctx, issue.ID, status)
 }
 
+// findRelatedIssues returns past issues whose findings cite the files this
+// issue's findings cite. Best-effort: a lookup failure only drops the section
+// from the spec prompt.
+func (e *actionExecutor) findRelatedIssues(ctx context.Context, issue model.Issue) []RelatedIssue {
+	if e.relatedIssueLimit <= 0 {
+		return nil
+	}
+	paths := findingPaths(issue.CodeFindings)
+	if len(paths) == 0 {
+		return nil
+	}
+
+	candidates, err := e.issues.ListWithFindings(ctx, issue.IntegrationID, issue.ID, relatedIssueCandidates)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list issues for related issue lookup",
+			"issue_id", issue.ID,
+			"error", err)
+		return nil
+	}
+	return selectRelatedIssues(paths, candidates, e.relatedIssueLimit)
+}
+
 // postSpecError posts an error message to the issue if ack was already posted.
 func (e *actionExecutor) postSpecError(ctx context.Context, issue model.Issue, ackPosted bool, message string) {
 	if !ackPosted {
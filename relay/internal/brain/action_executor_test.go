@@ -0,0 +1,128 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"basegraph.co/relay/common/id"
+)
+
+// memSpecLocks is an in-memory SpecLockStore; expiry is not simulated.
+type memSpecLocks struct {
+	mu     sync.Mutex
+	owners map[int64]string
+}
+
+func (m *memSpecLocks) TryAcquire(_ context.Context, issueID int64, owner string, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, held := m.owners[issueID]; held {
+		return false, nil
+	}
+	m.owners[issueID] = owner
+	return true, nil
+}
+
+func (m *memSpecLocks) Release(_ context.Context, issueID int64, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.owners[issueID] == owner {
+		delete(m.owners, issueID)
+	}
+	return nil
+}
+
+func TestWithSpecLockSkipsConcurrentGeneration(t *testing.T) {
+	if err := id.Init(1); err != nil {
+		t.Fatalf("init id: %v", err)
+	}
+
+	e := &actionExecutor{specLocks: &memSpecLocks{owners: make(map[int64]string)}}
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	firstDone := make(chan bool)
+
+	go func() {
+		ran, err := e.withSpecLock(ctx, 42, func(context.Context) error {
+			close(started)
+			<-finish
+			return nil
+		})
+		if err != nil {
+			t.Errorf("first generation: %v", err)
+		}
+		firstDone <- ran
+	}()
+
+	<-started
+	secondRan, err := e.withSpecLock(ctx, 42, func(context.Context) error {
+		t.Error("second generation should not run while the first holds the lock")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("second generation: %v", err)
+	}
+	if secondRan {
+		t.Fatalf("expected second generation to be skipped")
+	}
+
+	// A different issue is not blocked.
+	otherRan, err := e.withSpecLock(ctx, 7, func(context.Context) error { return nil })
+	if err != nil || !otherRan {
+		t.Fatalf("other issue: ran=%v err=%v, want ran", otherRan, err)
+	}
+
+	close(finish)
+	if !<-firstDone {
+		t.Fatalf("expected first generation to run")
+	}
+
+	// Released on completion: the next generation runs.
+	thirdRan, err := e.withSpecLock(ctx, 42, func(context.Context) error { return nil })
+	if err != nil || !thirdRan {
+		t.Fatalf("after release: ran=%v err=%v, want ran", thirdRan, err)
+	}
+}
+
+func TestWithSpecLockReleasesOnError(t *testing.T) {
+	if err := id.Init(1); err != nil {
+		t.Fatalf("init id: %v", err)
+	}
+
+	e := &actionExecutor{specLocks: &memSpecLocks{owners: make(map[int64]string)}}
+	ctx := context.Background()
+	genErr := errors.New("llm unavailable")
+
+	ran, err := e.withSpecLock(ctx, 42, func(context.Context) error { return genErr })
+	if !ran || !errors.Is(err, genErr) {
+		t.Fatalf("ran=%v err=%v, want ran with %v", ran, err, genErr)
+	}
+
+	ran, err = e.withSpecLock(ctx, 42, func(context.Context) error { return nil })
+	if err != nil || !ran {
+		t.Fatalf("after failed run: ran=%v err=%v, want ran", ran, err)
+	}
+}
+
+func TestWithSpecLockWithoutStoreRunsUnlocked(t *testing.T) {
+	e := &actionExecutor{}
+
+	calls := 0
+	for i := 0; i < 2; i++ {
+		ran, err := e.withSpecLock(context.Background(), 42, func(context.Context) error {
+			calls++
+			return nil
+		})
+		if err != nil || !ran {
+			t.Fatalf("run %d: ran=%v err=%v", i, ran, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 runs, got %d", calls)
+	}
+}
@@ -161,6 +161,7 @@ func (v *actionValidator) validateUpdateGaps(ctx context.Context, action Action)
 		if !isValidGapCloseReason(close.Reason) {
 			return fmt.Errorf("close[%d]: %w: %s", i, ErrInvalidGapCloseReason, close.Reason)
 		}
+		//exhaustive:ignore // Only these reasons need a note
 		switch close.Reason {
 		case GapCloseAnswered, GapCloseInferred:
 			if close.Note == "" {
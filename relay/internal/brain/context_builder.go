@@ -257,8 +257,9 @@ func (b *contextBuilder) buildContextDump(issue model.Issue, learnings []model.L
 			sb.WriteString(fmt.Sprintf("**Status**: %s\n\n", *issue.SpecStatus))
 		}
 		sb.WriteString("You previously generated and posted this implementation spec. The user is now reviewing it.\n\n")
+		_, spec, _ := ParseSpecFrontmatter(*issue.Spec)
 		sb.WriteString("<spec>\n")
-		sb.WriteString(*issue.Spec)
+		sb.WriteString(spec)
 		sb.WriteString("\n</spec>\n\n")
 	}
 
@@ -0,0 +1,143 @@
+package brain
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DebugRetention bounds how much the debug run directory keeps. Zero values
+// disable the corresponding cap.
+type DebugRetention struct {
+	MaxRuns  int   // Keep at most this many runs, including the new one
+	MaxBytes int64 // Delete the oldest runs until all runs fit in this size
+}
+
+// ParseDebugRetention parses the run count and size cap (in megabytes) from
+// their string settings. Empty strings leave the cap disabled.
+func ParseDebugRetention(maxRuns, maxMB string) (DebugRetention, error) {
+	var retention DebugRetention
+	if maxRuns != "" {
+		n, err := strconv.Atoi(maxRuns)
+		if err != nil {
+			return DebugRetention{}, fmt.Errorf("parse max debug runs %q: %w", maxRuns, err)
+		}
+		retention.MaxRuns = n
+	}
+	if maxMB != "" {
+		mb, err := strconv.ParseInt(maxMB, 10, 64)
+		if err != nil {
+			return DebugRetention{}, fmt.Errorf("parse max debug size %q: %w", maxMB, err)
+		}
+		retention.MaxBytes = mb << 20
+	}
+	return retention, nil
+}
+
+// debugRun is one baseDir/YYYY-MM-DD/NNN directory.
+type debugRun struct {
+	path string
+	date string
+	num  int
+	size int64
+}
+
+// pruneDebugRuns deletes the oldest runs under baseDir until retention is met.
+// The current run is never deleted, even if it alone exceeds MaxBytes.
+func pruneDebugRuns(baseDir, current string, retention DebugRetention) {
+	if retention.MaxRuns <= 0 && retention.MaxBytes <= 0 {
+		return
+	}
+
+	runs := listDebugRuns(baseDir)
+	var total int64
+	for _, r := range runs {
+		total += r.size
+	}
+
+	for len(runs) > 0 {
+		overRuns := retention.MaxRuns > 0 && len(runs) > retention.MaxRuns
+		overBytes := retention.MaxBytes > 0 && total > retention.MaxBytes
+		if !overRuns && !overBytes {
+			return
+		}
+
+		oldest := runs[0]
+		if oldest.path == current {
+			return
+		}
+		if err := os.RemoveAll(oldest.path); err != nil {
+			slog.Warn("failed to prune debug run dir", "dir", oldest.path, "error", err)
+			return
+		}
+		slog.Info("pruned debug run directory", "path", oldest.path, "bytes", oldest.size)
+
+		// Drop the date dir once its last run is gone
+		dateDir := filepath.Dir(oldest.path)
+		if entries, err := os.ReadDir(dateDir); err == nil && len(entries) == 0 {
+			_ = os.Remove(dateDir)
+		}
+
+		total -= oldest.size
+		runs = runs[1:]
+	}
+}
+
+// listDebugRuns returns the run directories under baseDir, oldest first.
+func listDebugRuns(baseDir string) []debugRun {
+	dates, err := os.ReadDir(baseDir)
+	if err != nil {
+		return nil
+	}
+
+	var runs []debugRun
+	for _, d := range dates {
+		if !d.IsDir() {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d.Name()); err != nil {
+			continue
+		}
+		dateDir := filepath.Join(baseDir, d.Name())
+		entries, err := os.ReadDir(dateDir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			n, err := strconv.Atoi(e.Name())
+			if !e.IsDir() || err != nil {
+				continue
+			}
+			path := filepath.Join(dateDir, e.Name())
+			runs = append(runs, debugRun{path: path, date: d.Name(), num: n, size: dirSize(path)})
+		}
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].date != runs[j].date {
+			return runs[i].date < runs[j].date
+		}
+		return runs[i].num < runs[j].num
+	})
+	return runs
+}
+
+// dirSize sums the sizes of regular files under dir.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,93 @@
+package brain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func debugRunNames(t *testing.T, baseDir string) []string {
+	t.Helper()
+	var names []string
+	for _, r := range listDebugRuns(baseDir) {
+		rel, err := filepath.Rel(baseDir, r.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, filepath.ToSlash(rel))
+	}
+	return names
+}
+
+func TestSetupDebugRunDirPrunesOldestRuns(t *testing.T) {
+	baseDir := t.TempDir()
+	oldRun := filepath.Join(baseDir, "2020-01-01", "001")
+	if err := os.MkdirAll(oldRun, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var last string
+	for i := 0; i < 5; i++ {
+		last = SetupDebugRunDir(baseDir, DebugRetention{MaxRuns: 3})
+	}
+
+	names := debugRunNames(t, baseDir)
+	if len(names) != 3 {
+		t.Fatalf("runs = %v, want 3", names)
+	}
+	for i, suffix := range []string{"/003", "/004", "/005"} {
+		if !strings.HasSuffix(names[i], suffix) {
+			t.Errorf("runs[%d] = %q, want suffix %q", i, names[i], suffix)
+		}
+	}
+	if _, err := os.Stat(last); err != nil {
+		t.Errorf("current run was removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "2020-01-01")); !os.IsNotExist(err) {
+		t.Errorf("empty date dir not removed: %v", err)
+	}
+}
+
+func TestPruneDebugRunsBySize(t *testing.T) {
+	baseDir := t.TempDir()
+	for _, run := range []string{"001", "002", "003"} {
+		dir := filepath.Join(baseDir, "2024-05-01", run)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "explore.txt"), make([]byte, 100), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	current := filepath.Join(baseDir, "2024-05-01", "003")
+
+	pruneDebugRuns(baseDir, current, DebugRetention{MaxBytes: 250})
+
+	names := debugRunNames(t, baseDir)
+	if len(names) != 2 || names[0] != "2024-05-01/002" || names[1] != "2024-05-01/003" {
+		t.Errorf("runs = %v, want [2024-05-01/002 2024-05-01/003]", names)
+	}
+
+	// The current run survives even when it alone is over the cap.
+	pruneDebugRuns(baseDir, current, DebugRetention{MaxBytes: 50})
+	if names := debugRunNames(t, baseDir); len(names) != 1 || names[0] != "2024-05-01/003" {
+		t.Errorf("runs = %v, want only the current run", names)
+	}
+}
+
+func TestParseDebugRetention(t *testing.T) {
+	got, err := ParseDebugRetention("20", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MaxRuns != 20 || got.MaxBytes != 5<<20 {
+		t.Errorf("ParseDebugRetention() = %+v", got)
+	}
+	if got, err := ParseDebugRetention("", ""); err != nil || got != (DebugRetention{}) {
+		t.Errorf("ParseDebugRetention(empty) = %+v, %v; want zero", got, err)
+	}
+	if _, err := ParseDebugRetention("many", ""); err == nil {
+		t.Error("ParseDebugRetention(\"many\") succeeded, want error")
+	}
+}
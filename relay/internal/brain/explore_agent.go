@@ -3,12 +3,14 @@ package brain
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"basegraph.co/relay/common/llm"
@@ -17,17 +19,27 @@ import (
 
 const (
 	exploreTimeout    = 12 * time.Minute // Increased for thorough explorations
-	doomLoopThreshold = 3                // Stop if same tool called 3 times with identical args
+	doomLoopThreshold = 3                // Stop if same tool called 3 times with identical or near-identical args
 	maxParallelTools  = 8                // Limit concurrent tool executions
+	maxEvidenceNudges = 2                // Give up on the tool-iteration floor after this many nudges
 )
 
 // Thoroughness levels control how deep the explore agent searches.
 type Thoroughness string
 
 const (
-	ThoroughnessQuick  Thoroughness = "quick"    // Fast lookup, ~30 iterations, ~15k soft / ~25k hard
-	ThoughnessMedium   Thoroughness = "medium"   // Balanced exploration, ~50 iterations, ~40k soft / ~60k hard
-	ThoughnessThorough Thoroughness = "thorough" // Comprehensive search, ~120 iterations, ~80k soft / ~120k hard
+	ThoroughnessQuick    Thoroughness = "quick"    // Fast lookup, ~30 iterations, ~15k soft / ~25k hard
+	ThoroughnessMedium   Thoroughness = "medium"   // Balanced exploration, ~50 iterations, ~40k soft / ~60k hard
+	ThoroughnessThorough Thoroughness = "thorough" // Comprehensive search, ~120 iterations, ~80k soft / ~120k hard
+)
+
+// Misspelled names kept so existing callers compile; they will be removed in
+// the next release.
+const (
+	// Deprecated: Use ThoroughnessMedium.
+	ThoughnessMedium = ThoroughnessMedium
+	// Deprecated: Use ThoroughnessThorough.
+	ThoughnessThorough = ThoroughnessThorough
 )
 
 // ExploreMode determines the agent's behavior and system prompt.
@@ -39,7 +51,7 @@ const (
 	ModeLocate ExploreMode = "locate"
 
 	// ModeAnalyze performs deep code analysis with file reading.
-	// Uses ThoughnessMedium. Good for "how does X work?" questions.
+	// Uses ThoroughnessMedium. Good for "how does X work?" questions.
 	ModeAnalyze ExploreMode = "analyze"
 )
 
@@ -47,33 +59,37 @@ const (
 // Following Anthropic's guidance: give model autonomy, use soft limits to encourage
 // synthesis rather than hard cutoffs that reduce quality.
 type ThoroughnessConfig struct {
-	MaxIterations   int // Hard ceiling on iterations
-	SoftTokenTarget int // Encourage synthesis around this point (80% triggers gentle nudge)
-	HardTokenLimit  int // Safety ceiling (forces synthesis)
+	Level           Thoroughness // Level these limits belong to; unknown levels resolve to medium
+	MaxIterations   int          // Hard ceiling on iterations
+	SoftTokenTarget int          // Encourage synthesis around this point (80% triggers gentle nudge)
+	HardTokenLimit  int          // Safety ceiling (forces synthesis)
 }
 
 func thoroughnessConfig(t Thoroughness) ThoroughnessConfig {
 	switch t {
 	case ThoroughnessQuick:
 		return ThoroughnessConfig{
+			Level:           ThoroughnessQuick,
 			MaxIterations:   30,
 			SoftTokenTarget: 15000,
 			HardTokenLimit:  25000,
 		}
-	case ThoughnessMedium:
+	case ThoroughnessMedium:
 		return ThoroughnessConfig{
+			Level:           ThoroughnessMedium,
 			MaxIterations:   50,
 			SoftTokenTarget: 40000,
 			HardTokenLimit:  60000,
 		}
-	case ThoughnessThorough:
+	case ThoroughnessThorough:
 		return ThoroughnessConfig{
+			Level:           ThoroughnessThorough,
 			MaxIterations:   120,
 			SoftTokenTarget: 80000,
 			HardTokenLimit:  120000,
 		}
 	default:
-		return thoroughnessConfig(ThoughnessMedium)
+		return thoroughnessConfig(ThoroughnessMedium)
 	}
 }
 
@@ -89,6 +105,9 @@ type ExploreMetrics struct {
 	ContextWindowTokens   int            `json:"context_window_tokens"`   // Final context window size
 	TotalCompletionTokens int            `json:"total_completion_tokens"` // Sum of all completion tokens
 	ToolCalls             map[string]int `json:"tool_calls"`
+	ToolOutputBytes       map[string]int `json:"tool_output_bytes"` // Bytes of tool results added to context, per tool
+	ToolTruncations       map[string]int `json:"tool_truncations"`  // Tool results cut at a result cap, per tool
+	ToolErrors            map[string]int `json:"tool_errors"`       // Tool calls that failed to execute, per tool
 
 	// Codegraph effectiveness metrics
 	CodegraphOps            map[string]int `json:"codegraph_ops,omitempty"`
@@ -99,13 +118,45 @@ type ExploreMetrics struct {
 	CodegraphTraceFound     int            `json:"codegraph_trace_found"`
 	CodegraphTraceNotFound  int            `json:"codegraph_trace_not_found"`
 
-	Confidence        string `json:"confidence"`
-	HitSoftLimit      bool   `json:"hit_soft_limit"`
-	HitHardLimit      bool   `json:"hit_hard_limit"`
-	HitIterLimit      bool   `json:"hit_iteration_limit"`
-	DoomLoopDetected  bool   `json:"doom_loop_detected"`
-	FinalReportLen    int    `json:"final_report_length"`
-	TerminationReason string `json:"termination_reason"`
+	CodegraphLatency map[string]*LatencyStats `json:"codegraph_latency,omitempty"` // Per-operation call durations
+
+	Confidence        string  `json:"confidence"`                   // high, medium, low or unknown
+	ConfidenceScore   float64 `json:"confidence_score"`             // Confidence as 0.0-1.0; 0 when unknown
+	ConfidenceSource  string  `json:"confidence_source,omitempty"`  // "submit_confidence", or "prose" when parsed from text
+	ConfidenceRetries int     `json:"confidence_retries,omitempty"` // Extra rounds triggered by confidence gating
+	EvidenceNudges    int     `json:"evidence_nudges,omitempty"`    // Early conclusions sent back for evidence
+	CacheHits         int     `json:"cache_hits,omitempty"`         // Report served from the explore cache
+	CompactedResults  int     `json:"compacted_results,omitempty"`  // Old tool results replaced by stubs past the soft target
+	CompactedTokens   int     `json:"compacted_tokens,omitempty"`   // Estimated context tokens freed by compaction
+	CacheMisses       int     `json:"cache_misses,omitempty"`       // Cache consulted but had no fresh report
+	HitSoftLimit      bool    `json:"hit_soft_limit"`
+	HitHardLimit      bool    `json:"hit_hard_limit"`
+	HitIterLimit      bool    `json:"hit_iteration_limit"`
+	DoomLoopDetected  bool    `json:"doom_loop_detected"`
+	FinalReportLen    int     `json:"final_report_length"`
+	TerminationReason string  `json:"termination_reason"`
+}
+
+// LatencyStats summarizes call durations for one codegraph operation.
+type LatencyStats struct {
+	Count   int   `json:"count"`
+	MinMs   int64 `json:"min_ms"`
+	MaxMs   int64 `json:"max_ms"`
+	AvgMs   int64 `json:"avg_ms"`
+	TotalMs int64 `json:"total_ms"`
+}
+
+func (s *LatencyStats) observe(d time.Duration) {
+	ms := d.Milliseconds()
+	if s.Count == 0 || ms < s.MinMs {
+		s.MinMs = ms
+	}
+	if ms > s.MaxMs {
+		s.MaxMs = ms
+	}
+	s.Count++
+	s.TotalMs += ms
+	s.AvgMs = s.TotalMs / int64(s.Count)
 }
 
 // ExploreAgent is a sub-agent that explores the codebase.
@@ -117,6 +168,15 @@ type ExploreAgent struct {
 	modulePath string // Go module path for constructing qnames (e.g., "basegraph.co/relay")
 	debugDir   string // Directory for debug logs (empty = no logging)
 
+	confidenceGating bool         // Retry once on low self-assessed confidence and tag the report
+	repoConventions  string       // Repo-specific guidance appended to the system prompt
+	toolConcurrency  int          // Max tool calls run at once per turn; 1 = sequential in call order
+	minToolIters     int          // Tool-using iterations required before a report is accepted (0 = none)
+	cache            ExploreCache // Reports keyed by query + HEAD commit (nil = no caching)
+	compactKeep      int          // Newest tool results kept verbatim once past the soft target (0 = no compaction)
+	onContent        func(string) // Receives model text as it streams (nil = no streaming)
+	metricsSink      MetricsSink  // Receives each session's metrics (default drops them)
+
 	// Mock mode fields for A/B testing planner prompts
 	mockMode    bool            // When true, use fixture selection instead of real exploration
 	mockLLM     llm.AgentClient // Cheap LLM (e.g., gpt-4o-mini) for fixture selection
@@ -126,10 +186,12 @@ type ExploreAgent struct {
 // NewExploreAgent creates an ExploreAgent sub-agent.
 func NewExploreAgent(llmClient llm.AgentClient, tools *ExploreTools, modulePath, debugDir string) *ExploreAgent {
 	return &ExploreAgent{
-		llm:        llmClient,
-		tools:      tools,
-		modulePath: modulePath,
-		debugDir:   debugDir,
+		llm:             llmClient,
+		tools:           tools,
+		modulePath:      modulePath,
+		debugDir:        debugDir,
+		toolConcurrency: maxParallelTools,
+		metricsSink:     nopMetricsSink{},
 	}
 }
 
@@ -144,23 +206,120 @@ func (e *ExploreAgent) WithMockMode(selectorLLM llm.AgentClient, fixtureFile str
 	return e
 }
 
-// toolCallRecord tracks a tool invocation for doom loop detection.
-type toolCallRecord struct {
-	name string
-	args string
+// WithConfidenceGating enables one extra targeted exploration round when the
+// self-assessment comes back low and budget remains. Final reports are also
+// prefixed with their confidence so the planner can weigh them.
+func (e *ExploreAgent) WithConfidenceGating(enabled bool) *ExploreAgent {
+	e.confidenceGating = enabled
+	return e
+}
+
+// WithRepoConventions appends a repo-specific conventions snippet to the
+// system prompt. Empty (the default) leaves the prompt unchanged.
+func (e *ExploreAgent) WithRepoConventions(conventions string) *ExploreAgent {
+	e.repoConventions = conventions
+	return e
+}
+
+// WithToolConcurrency sets how many tool calls from one model turn run at once.
+// 1 runs them sequentially in call order, so a later call observes the effects
+// of earlier ones; n <= 0 keeps the default of maxParallelTools.
+func (e *ExploreAgent) WithToolConcurrency(n int) *ExploreAgent {
+	if n <= 0 {
+		n = maxParallelTools
+	}
+	e.toolConcurrency = n
+	return e
+}
+
+// WithMinToolIterations requires n tool-using iterations before a tool-free
+// response is accepted as the report. Earlier conclusions are sent back with a
+// nudge to gather evidence, at most maxEvidenceNudges times. 0 (the default)
+// accepts a report at any point.
+func (e *ExploreAgent) WithMinToolIterations(n int) *ExploreAgent {
+	e.minToolIters = n
+	return e
+}
+
+// WithCache reuses reports for repeated questions at the same repo commit.
+// nil (the default) disables caching.
+func (e *ExploreAgent) WithCache(cache ExploreCache) *ExploreAgent {
+	e.cache = cache
+	return e
+}
+
+// WithMetricsSink sends each exploration's metrics to sink, in addition to
+// the explore_metrics_<session>.json debug file. nil restores the default,
+// which drops them.
+func (e *ExploreAgent) WithMetricsSink(sink MetricsSink) *ExploreAgent {
+	if sink == nil {
+		sink = nopMetricsSink{}
+	}
+	e.metricsSink = sink
+	return e
+}
+
+// WithCompaction keeps long explorations going past the soft token target:
+// from then on, tool results older than the newest keepRecent are replaced by
+// short stubs before each model call, instead of riding along until the hard
+// limit forces synthesis. The 80% "wrap up" nudge is skipped while compaction
+// is on. 0 (the default) disables compaction.
+func (e *ExploreAgent) WithCompaction(keepRecent int) *ExploreAgent {
+	e.compactKeep = max(keepRecent, 0)
+	return e
+}
+
+// WithContentStream passes the model's text to fn as it is generated, so an
+// interactive caller can show the report while it is still being written.
+// It only takes effect when the client implements llm.StreamingClient;
+// otherwise turns run as usual and fn is never called.
+func (e *ExploreAgent) WithContentStream(fn func(delta string)) *ExploreAgent {
+	e.onContent = fn
+	return e
 }
 
 // toolResult holds the result of a single tool execution.
 type toolResult struct {
-	callID string
-	result string
+	callID    string
+	result    string
+	truncated bool
+	failed    bool // The tool itself failed (bad arguments, unknown tool); result is the error notice
+	duration  time.Duration
+}
+
+type exploreAbortKey struct{}
+
+// WithExploreAbort returns a context under which exploration stops once abort
+// is closed, e.g. because new events arrived on the issue being planned. The
+// loop checks it before every iteration and synthesizes a report from what it
+// has found so far; unlike cancelling ctx, the caller still gets a (partial)
+// report. The channel travels with the call rather than the agent, which is
+// shared across issues, so aborting one run leaves the others going.
+func WithExploreAbort(ctx context.Context, abort <-chan struct{}) context.Context {
+	return context.WithValue(ctx, exploreAbortKey{}, abort)
+}
+
+// exploreAborted reports whether ctx carries an abort channel that has been
+// closed.
+func exploreAborted(ctx context.Context) bool {
+	abort, _ := ctx.Value(exploreAbortKey{}).(<-chan struct{})
+	if abort == nil {
+		return false
+	}
+	select {
+	case <-abort:
+		return true
+	default:
+		return false
+	}
 }
 
-// Explore explores the codebase to answer a question using medium thoroughness.
-// Returns a prose report with code snippets for another LLM to read.
-// This is the backward-compatible method - uses ModeAnalyze (existing behavior).
-func (e *ExploreAgent) Explore(ctx context.Context, query string) (string, error) {
-	return e.ExploreWithMode(ctx, query, ModeAnalyze)
+// Explore explores the codebase to answer a question at the given thoroughness,
+// using ModeAnalyze. Returns a prose report with code snippets for another LLM
+// to read. Use ThoroughnessQuick for fast verification, ThoroughnessMedium for
+// balanced exploration, or ThoroughnessThorough for comprehensive search.
+func (e *ExploreAgent) Explore(ctx context.Context, query string, thoroughness Thoroughness) (string, error) {
+	return e.exploreInternal(ctx, query, thoroughness, ModeAnalyze)
 }
 
 // ExploreWithMode explores the codebase using the specified mode.
@@ -172,25 +331,26 @@ func (e *ExploreAgent) ExploreWithMode(ctx context.Context, query string, mode E
 	case ModeLocate:
 		thoroughness = ThoroughnessQuick
 	case ModeAnalyze:
-		thoroughness = ThoughnessMedium
+		thoroughness = ThoroughnessMedium
 	default:
-		thoroughness = ThoughnessMedium
+		thoroughness = ThoroughnessMedium
 		mode = ModeAnalyze
 	}
 
 	return e.exploreInternal(ctx, query, thoroughness, mode)
 }
 
-// ExploreWithThoroughness explores the codebase with configurable depth.
-// Use ThoroughnessQuick for fast verification, ThoughnessMedium for balanced exploration,
-// or ThoughnessThorough for comprehensive search.
-// Defaults to ModeAnalyze behavior.
-func (e *ExploreAgent) ExploreWithThoroughness(ctx context.Context, query string, thoroughness Thoroughness) (string, error) {
-	return e.exploreInternal(ctx, query, thoroughness, ModeAnalyze)
+// exploreSessionSeq tells apart explore runs started in the same millisecond,
+// which would otherwise share a notes scratchpad and debug files.
+var exploreSessionSeq atomic.Uint64
+
+// newExploreSessionID returns a time-ordered id unique within the process.
+func newExploreSessionID() string {
+	return fmt.Sprintf("%s-%d", time.Now().Format("20060102-150405.000"), exploreSessionSeq.Add(1))
 }
 
 // exploreInternal is the core exploration loop with configurable thoroughness and mode.
-func (e *ExploreAgent) exploreInternal(ctx context.Context, query string, thoroughness Thoroughness, mode ExploreMode) (string, error) {
+func (e *ExploreAgent) exploreInternal(ctx context.Context, query string, thoroughness Thoroughness, mode ExploreMode) (report string, err error) {
 	// Mock mode: use fixture selection instead of real exploration
 	if e.mockMode {
 		return e.exploreWithMock(ctx, query)
@@ -201,12 +361,17 @@ func (e *ExploreAgent) exploreInternal(ctx context.Context, query string, thorou
 
 	// Initialize metrics for structured logging
 	metrics := ExploreMetrics{
-		SessionID:    time.Now().Format("20060102-150405.000"),
-		Query:        query,
-		Thoroughness: string(thoroughness),
-		StartTime:    start,
-		ToolCalls:    make(map[string]int),
-		CodegraphOps: make(map[string]int),
+		SessionID:       newExploreSessionID(),
+		Query:           query,
+		Thoroughness:    string(config.Level),
+		StartTime:       start,
+		ToolCalls:       make(map[string]int),
+		ToolOutputBytes: make(map[string]int),
+		ToolTruncations: make(map[string]int),
+		ToolErrors:      make(map[string]int),
+		CodegraphOps:    make(map[string]int),
+
+		CodegraphLatency: make(map[string]*LatencyStats),
 	}
 
 	// Enrich context with explorer component
@@ -217,6 +382,13 @@ func (e *ExploreAgent) exploreInternal(ctx context.Context, query string, thorou
 	ctx, cancel := context.WithTimeout(ctx, exploreTimeout)
 	defer cancel()
 
+	// write_note scratchpad for this call only; each call starts from a fresh
+	// context window, so earlier calls' notes would be stale.
+	e.tools.clearNotes(metrics.SessionID)
+	ctx = withNoteSession(ctx, metrics.SessionID)
+
+	exploreSpans, ctx := startExploreTrace(ctx, query, config.Level, mode)
+
 	messages := []llm.Message{
 		{Role: "system", Content: e.systemPrompt(config, mode)},
 		{Role: "user", Content: query},
@@ -238,8 +410,10 @@ func (e *ExploreAgent) exploreInternal(ctx context.Context, query string, thorou
 	contextWindowTokens := 0
 	totalCompletionTokens := 0
 	iterations := 0
+	toolIterations := 0 // Iterations in which the model called tools
 	softNudgeSent := false
 	selfAssessmentDone := false
+	confidenceRetryDone := false
 	var pendingReport string // Holds the report while waiting for self-assessment
 
 	defer func() {
@@ -259,15 +433,74 @@ func (e *ExploreAgent) exploreInternal(ctx context.Context, query string, thorou
 			"confidence", metrics.Confidence,
 			"termination_reason", metrics.TerminationReason)
 
+		if notes := e.tools.Notes(metrics.SessionID); len(notes) > 0 {
+			debugLog.WriteString(fmt.Sprintf("\n=== NOTES (%d) ===\n%s", len(notes), formatNotes(notes)))
+		}
+		e.tools.clearNotes(metrics.SessionID)
+
 		e.writeDebugLog(metrics.SessionID, "explore", debugLog.String())
 		e.writeMetricsLog(metrics)
+		if sinkErr := e.metricsSink.Record(context.WithoutCancel(ctx), metrics); sinkErr != nil {
+			slog.WarnContext(ctx, "failed to record explore metrics", "error", sinkErr)
+		}
+		exploreSpans.finish(metrics, err)
 	}()
 
+	cacheKey := ""
+	if e.cache != nil {
+		if commit := e.tools.headCommit(ctx); commit != "" {
+			cacheKey = exploreCacheKey(commit, mode, config.Level, query)
+		}
+	}
+	if cacheKey != "" {
+		cached, ok, cacheErr := e.cache.Get(ctx, cacheKey)
+		if cacheErr != nil {
+			slog.WarnContext(ctx, "explore cache lookup failed", "error", cacheErr)
+		}
+		if ok {
+			metrics.CacheHits++
+			metrics.TerminationReason = "cached"
+			debugLog.WriteString("=== SERVED FROM CACHE ===\n")
+			slog.DebugContext(ctx, "explore report served from cache", "query", logger.Truncate(query, 100))
+			return cached, nil
+		}
+		metrics.CacheMisses++
+
+		// Store the final report once the exploration finishes; runs cut
+		// short are partial and not worth reusing.
+		defer func() {
+			if err != nil || report == "" || metrics.TerminationReason == "aborted" || metrics.TerminationReason == "circuit_open" {
+				return
+			}
+			if setErr := e.cache.Set(context.WithoutCancel(ctx), cacheKey, report); setErr != nil {
+				slog.WarnContext(ctx, "explore cache store failed", "error", setErr)
+			}
+		}()
+	}
+
 	// Track recent tool calls for doom loop detection
 	var recentCalls []toolCallRecord
 
 	for {
 		iterations++
+		iterCtx := exploreSpans.startIteration(ctx, iterations)
+
+		if exploreAborted(ctx) {
+			slog.InfoContext(ctx, "explore agent aborted, synthesizing findings",
+				"iterations", iterations)
+			debugLog.WriteString(fmt.Sprintf("\n=== ABORTED (iteration %d) - synthesizing findings ===\n", iterations))
+
+			metrics.TerminationReason = "aborted"
+
+			report, err := e.forceSynthesis(iterCtx, messages, "Exploration was cut short: the issue changed and this exploration is no longer needed in full. Write your final report now based on what you've found, and state that it is incomplete.")
+			if err != nil {
+				return "", err
+			}
+
+			report = "[exploration cut short]\n\n" + report
+			debugLog.WriteString(fmt.Sprintf("[SYNTHESIS]\n%s\n", report))
+			return report, nil
+		}
 
 		// Check iteration limit
 		if iterations > config.MaxIterations {
@@ -278,7 +511,7 @@ func (e *ExploreAgent) exploreInternal(ctx context.Context, query string, thorou
 			metrics.HitIterLimit = true
 			metrics.TerminationReason = "iteration_limit"
 
-			report, err := e.forceSynthesis(ctx, messages, "Maximum exploration steps reached. Write your final report now based on what you've found.")
+			report, err := e.forceSynthesis(iterCtx, messages, "Maximum exploration steps reached. Write your final report now based on what you've found.")
 			if err != nil {
 				return "", err
 			}
@@ -291,8 +524,10 @@ func (e *ExploreAgent) exploreInternal(ctx context.Context, query string, thorou
 		// Check limits based on current context window size
 		// On first iteration, contextWindowTokens is 0 so we skip limit checks
 
-		// Soft nudge at 80% of target (not forced - just a gentle prompt)
-		if !softNudgeSent && contextWindowTokens > config.SoftTokenTarget*80/100 {
+		// Soft nudge at 80% of target (not forced - just a gentle prompt).
+		// With compaction on, the soft target is where old output gets dropped
+		// so exploration can continue, not where it should wrap up.
+		if e.compactKeep == 0 && !softNudgeSent && contextWindowTokens > config.SoftTokenTarget*80/100 {
 			softNudgeSent = true
 			metrics.HitSoftLimit = true
 			debugLog.WriteString(fmt.Sprintf("\n=== SOFT LIMIT REACHED (context=%d tokens, 80%% of %d) - adding synthesis nudge ===\n",
@@ -312,16 +547,61 @@ Stop exploring. Start synthesizing.`,
 			})
 		}
 
+		// Past the soft target, drop old tool output so exploration can go on.
+		// The token count is an estimate until the next response reports it.
+		if e.compactKeep > 0 && contextWindowTokens > config.SoftTokenTarget {
+			metrics.HitSoftLimit = true
+			var compacted []compactedResult
+			if messages, compacted = compactToolResults(messages, e.compactKeep); len(compacted) > 0 {
+				freed := 0
+				debugLog.WriteString(fmt.Sprintf("\n=== COMPACTED %d TOOL RESULTS (context=%d tokens) ===\n", len(compacted), contextWindowTokens))
+				for _, c := range compacted {
+					freed += c.bytes / 4
+					debugLog.WriteString(fmt.Sprintf("[COMPACTED] %s (call %s, %d bytes)\n", c.tool, c.callID, c.bytes))
+				}
+				contextWindowTokens = max(contextWindowTokens-freed, 0)
+				metrics.CompactedResults += len(compacted)
+				metrics.CompactedTokens += freed
+			}
+		}
+
 		// Hard limit check moved to AFTER resp.PromptTokens is known (see below)
 
-		resp, err := e.llm.ChatWithTools(ctx, llm.AgentRequest{
+		// The self-assessment turn only gets submit_confidence, so the answer
+		// comes back structured instead of as prose to pattern-match.
+		tools := e.tools.Definitions()
+		if selfAssessmentDone {
+			tools = []llm.Tool{submitConfidenceToolDefinition()}
+		}
+
+		resp, err := e.chat(iterCtx, llm.AgentRequest{
 			Messages: messages,
-			Tools:    e.tools.Definitions(),
+			Tools:    tools,
 		})
+		if errors.Is(err, llm.ErrCircuitOpen) {
+			// The provider is failing across the board; retrying the message
+			// later won't help, and a synthesis turn would go through the same
+			// open breaker, so finish with the notes gathered so far.
+			slog.WarnContext(ctx, "explore agent llm circuit open, returning notes",
+				"iterations", iterations,
+				"error", err)
+			debugLog.WriteString(fmt.Sprintf("\n=== LLM CIRCUIT OPEN (iteration %d) - returning notes ===\n", iterations))
+
+			metrics.TerminationReason = "circuit_open"
+
+			notes := e.tools.Notes(metrics.SessionID)
+			if len(notes) == 0 {
+				return "", fmt.Errorf("explore agent chat iteration %d: %w", iterations, err)
+			}
+			report := "[exploration cut short]\n\nExploration stopped early because the model provider is unavailable. Notes gathered so far:\n" + formatNotes(notes)
+			debugLog.WriteString(fmt.Sprintf("[SYNTHESIS]\n%s\n", report))
+			return report, nil
+		}
 		if err != nil {
 			metrics.TerminationReason = "error"
 			return "", fmt.Errorf("explore agent chat iteration %d: %w", iterations, err)
 		}
+		exploreSpans.recordResponse(resp)
 
 		// Track token usage
 		// - resp.PromptTokens is the context window size for THIS call
@@ -339,7 +619,7 @@ Stop exploring. Start synthesizing.`,
 			metrics.HitHardLimit = true
 			metrics.TerminationReason = "hard_limit"
 
-			report, err := e.forceSynthesis(ctx, messages, "Token limit reached. Write your final report now based on everything you've found.")
+			report, err := e.forceSynthesis(iterCtx, messages, "Token limit reached. Write your final report now based on everything you've found.")
 			if err != nil {
 				return "", err
 			}
@@ -362,37 +642,90 @@ Stop exploring. Start synthesizing.`,
 			iterations, resp.PromptTokens, resp.CompletionTokens))
 		debugLog.WriteString(fmt.Sprintf("[ASSISTANT]\n%s\n\n", resp.Content))
 
-		// No tool calls = model wants to conclude
-		if len(resp.ToolCalls) == 0 {
-			// Self-assessment before accepting final answer
-			if !selfAssessmentDone {
-				selfAssessmentDone = true
-				pendingReport = resp.Content // Save the report
-				debugLog.WriteString("\n=== SELF-ASSESSMENT REQUESTED ===\n")
+		// This turn answers the self-assessment request
+		if selfAssessmentDone {
+			assessment := assessConfidence(resp)
+			metrics.Confidence = assessment.Level
+			metrics.ConfidenceScore = assessment.Score
+			metrics.ConfidenceSource = assessment.Source
+
+			// Low confidence with budget left: one more targeted round, then reassess.
+			if e.confidenceGating && metrics.Confidence == "low" && !confidenceRetryDone &&
+				contextWindowTokens < config.SoftTokenTarget && iterations < config.MaxIterations {
+				confidenceRetryDone = true
+				selfAssessmentDone = false
+				metrics.ConfidenceRetries++
+				debugLog.WriteString("\n=== LOW CONFIDENCE - REQUESTING ONE MORE TARGETED SEARCH ===\n")
 
 				messages = append(messages, llm.Message{
-					Role:    "assistant",
-					Content: resp.Content,
+					Role:      "assistant",
+					Content:   resp.Content,
+					ToolCalls: resp.ToolCalls,
 				})
+				for _, tc := range resp.ToolCalls {
+					messages = append(messages, llm.Message{
+						Role:       "tool",
+						Content:    "Recorded.",
+						ToolCallID: tc.ID,
+					})
+				}
 				messages = append(messages, llm.Message{
 					Role:    "user",
-					Content: "Before finalizing: Rate your confidence in this answer (high/medium/low) and note any caveats or areas of uncertainty.",
+					Content: "Your confidence is low. Pick the single biggest gap you noted and run one targeted search to close it, then write your final report again.",
 				})
 				continue
 			}
 
-			// Extract confidence from the self-assessment response
-			metrics.Confidence = extractConfidence(resp.Content)
 			metrics.TerminationReason = "natural"
 
 			// Combine the original report with the confidence assessment
-			finalReport := pendingReport + "\n\n---\n\n**Confidence Assessment:** " + resp.Content
+			finalReport := pendingReport + "\n\n---\n\n**Confidence Assessment:** " + assessment.Text
+			if e.confidenceGating {
+				finalReport = fmt.Sprintf("[confidence: %s]\n\n%s", metrics.Confidence, finalReport)
+			}
 			metrics.FinalReportLen = len(finalReport)
 
-			debugLog.WriteString(fmt.Sprintf("=== EXPLORE AGENT COMPLETED (confidence: %s) ===\n", metrics.Confidence))
+			debugLog.WriteString(fmt.Sprintf("=== EXPLORE AGENT COMPLETED (confidence: %s, via %s) ===\n", metrics.Confidence, metrics.ConfidenceSource))
 			return finalReport, nil
 		}
 
+		// No tool calls = model wants to conclude
+		if len(resp.ToolCalls) == 0 {
+			// Too early: send it back for evidence before accepting a report
+			if toolIterations < e.minToolIters && metrics.EvidenceNudges < maxEvidenceNudges {
+				metrics.EvidenceNudges++
+				debugLog.WriteString(fmt.Sprintf("\n=== CONCLUDED AFTER %d TOOL ITERATION(S), MINIMUM %d - REQUESTING EVIDENCE ===\n",
+					toolIterations, e.minToolIters))
+
+				messages = append(messages, llm.Message{
+					Role:    "assistant",
+					Content: resp.Content,
+				})
+				messages = append(messages, llm.Message{
+					Role:    "user",
+					Content: "You concluded before gathering enough evidence. Gather evidence first: use the tools to find and read the code that backs your answer, then write the report citing file:line locations.",
+				})
+				continue
+			}
+
+			// Self-assessment before accepting final answer
+			selfAssessmentDone = true
+			pendingReport = resp.Content // Save the report
+			debugLog.WriteString("\n=== SELF-ASSESSMENT REQUESTED ===\n")
+
+			messages = append(messages, llm.Message{
+				Role:    "assistant",
+				Content: resp.Content,
+			})
+			messages = append(messages, llm.Message{
+				Role:    "user",
+				Content: "Before finalizing: Rate your confidence in this answer (high/medium/low) and note any caveats or areas of uncertainty. Call submit_confidence with your rating and caveats.",
+			})
+			continue
+		}
+
+		toolIterations++
+
 		// Track tool calls for metrics
 		for _, tc := range resp.ToolCalls {
 			metrics.ToolCalls[tc.Name]++
@@ -425,31 +758,31 @@ Stop exploring. Start synthesizing.`,
 			}
 		}
 
-		// Check for doom loop (same tool called repeatedly with same args)
+		// Check for doom loop (same tool called repeatedly with the same or
+		// nearly the same args)
 		if len(resp.ToolCalls) == 1 {
 			tc := resp.ToolCalls[0]
-			currentCall := toolCallRecord{name: tc.Name, args: normalizeArgs(tc.Arguments)}
-			recentCalls = append(recentCalls, currentCall)
+			recentCalls = append(recentCalls, newToolCallRecord(tc))
 
-			// Keep only last N calls
-			if len(recentCalls) > doomLoopThreshold {
+			// Keep only the detection window
+			if len(recentCalls) > nearDuplicateWindow {
 				recentCalls = recentCalls[1:]
 			}
 
-			// Check if all recent calls are identical
-			if len(recentCalls) == doomLoopThreshold && allIdentical(recentCalls) {
+			if loop := detectDoomLoop(recentCalls); loop != "" {
 				slog.WarnContext(ctx, "explore agent doom loop detected, forcing completion",
 					"iterations", iterations,
 					"repeated_tool", tc.Name,
-					"repeated_args", tc.Arguments)
+					"repeated_args", tc.Arguments,
+					"match", loop)
 
-				debugLog.WriteString(fmt.Sprintf("\n=== DOOM LOOP DETECTED (tool '%s' called %d times with same args) ===\n",
-					tc.Name, doomLoopThreshold))
+				debugLog.WriteString(fmt.Sprintf("\n=== DOOM LOOP DETECTED (tool '%s' called %d times with %s args) ===\n",
+					tc.Name, doomLoopThreshold, loop))
 
 				metrics.DoomLoopDetected = true
 				metrics.TerminationReason = "doom_loop"
 
-				report, err := e.forceSynthesis(ctx, messages,
+				report, err := e.forceSynthesis(iterCtx, messages,
 					"You seem to be searching for the same thing repeatedly. Please write your final report now based on what you've found so far. If you couldn't find what you were looking for, explain what you found instead.")
 				if err != nil {
 					return "", err
@@ -476,14 +809,22 @@ Stop exploring. Start synthesizing.`,
 			ToolCalls: resp.ToolCalls,
 		})
 
-		// Execute all tool calls in parallel
-		results := e.executeToolsParallel(ctx, resp.ToolCalls)
+		// Execute all tool calls; results are index-aligned with resp.ToolCalls
+		results := e.executeTools(iterCtx, resp.ToolCalls)
 
 		for i, res := range results {
 			// Log tool result
-			debugLog.WriteString(fmt.Sprintf("[TOOL RESULT] %s\n", resp.ToolCalls[i].Name))
+			debugLog.WriteString(fmt.Sprintf("[TOOL RESULT] %s (call %s)\n", resp.ToolCalls[i].Name, res.callID))
 			debugLog.WriteString(fmt.Sprintf("%s\n\n", res.result))
 
+			metrics.ToolOutputBytes[resp.ToolCalls[i].Name] += len(res.result)
+			if res.truncated {
+				metrics.ToolTruncations[resp.ToolCalls[i].Name]++
+			}
+			if res.failed {
+				metrics.ToolErrors[resp.ToolCalls[i].Name]++
+			}
+
 			// Codegraph effectiveness signals (parse tool output)
 			if resp.ToolCalls[i].Name == "codegraph" {
 				if strings.Contains(res.result, "Error: invalid kind") {
@@ -496,6 +837,14 @@ Stop exploring. Start synthesizing.`,
 				params, err := llm.ParseToolArguments[CodegraphParams](resp.ToolCalls[i].Arguments)
 				if err == nil {
 					op := strings.ToLower(strings.TrimSpace(params.Operation))
+					if op != "" {
+						stats := metrics.CodegraphLatency[op]
+						if stats == nil {
+							stats = &LatencyStats{}
+							metrics.CodegraphLatency[op] = stats
+						}
+						stats.observe(res.duration)
+					}
 					if op == "trace" {
 						if strings.HasPrefix(res.result, "Trace path") {
 							metrics.CodegraphTraceFound++
@@ -516,13 +865,19 @@ Stop exploring. Start synthesizing.`,
 }
 
 // forceSynthesis forces the model to write a final report without tools.
+// Notes from write_note are appended to the prompt so earlier findings make
+// it into the report.
 func (e *ExploreAgent) forceSynthesis(ctx context.Context, messages []llm.Message, prompt string) (string, error) {
+	if notes := e.tools.Notes(noteSession(ctx)); len(notes) > 0 {
+		prompt += "\n\nYour notes from this exploration:\n" + formatNotes(notes)
+	}
+
 	messages = append(messages, llm.Message{
 		Role:    "user",
 		Content: prompt,
 	})
 
-	resp, err := e.llm.ChatWithTools(ctx, llm.AgentRequest{
+	resp, err := e.chat(ctx, llm.AgentRequest{
 		Messages: messages,
 		Tools:    nil, // No tools = force text response
 	})
@@ -533,7 +888,24 @@ func (e *ExploreAgent) forceSynthesis(ctx context.Context, messages []llm.Messag
 	return resp.Content, nil
 }
 
+// chat runs one model turn, streaming its text to onContent when a content
+// stream is set and the client supports it.
+func (e *ExploreAgent) chat(ctx context.Context, req llm.AgentRequest) (*llm.AgentResponse, error) {
+	streamer, ok := e.llm.(llm.StreamingClient)
+	if e.onContent == nil || !ok {
+		return e.llm.ChatWithTools(ctx, req)
+	}
+
+	events, err := streamer.ChatWithToolsStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return llm.CollectStream(events, e.onContent)
+}
+
 // extractConfidence parses confidence level from model's self-assessment.
+// It is the fallback for models that answer in prose instead of calling
+// submit_confidence.
 func extractConfidence(content string) string {
 	lower := strings.ToLower(content)
 	switch {
@@ -548,14 +920,35 @@ func extractConfidence(content string) string {
 	}
 }
 
+// executeTools runs a turn's tool calls, sequentially when toolConcurrency is 1
+// and in parallel otherwise. Either way results[i] belongs to toolCalls[i].
+func (e *ExploreAgent) executeTools(ctx context.Context, toolCalls []llm.ToolCall) []toolResult {
+	if e.toolConcurrency == 1 || len(toolCalls) == 1 {
+		return e.executeToolsSequential(ctx, toolCalls)
+	}
+	return e.executeToolsParallel(ctx, toolCalls)
+}
+
+// executeToolsSequential runs tool calls one at a time in call order.
+func (e *ExploreAgent) executeToolsSequential(ctx context.Context, toolCalls []llm.ToolCall) []toolResult {
+	results := make([]toolResult, len(toolCalls))
+	for i, call := range toolCalls {
+		results[i] = e.executeTool(ctx, call)
+	}
+	return results
+}
+
 // executeToolsParallel runs multiple tool calls concurrently with bounded parallelism.
-// Individual tool failures are captured as error messages in the result, not propagated.
 func (e *ExploreAgent) executeToolsParallel(ctx context.Context, toolCalls []llm.ToolCall) []toolResult {
 	results := make([]toolResult, len(toolCalls))
 	var wg sync.WaitGroup
 
 	// Semaphore to limit concurrent tool executions
-	sem := make(chan struct{}, maxParallelTools)
+	concurrency := e.toolConcurrency
+	if concurrency <= 0 {
+		concurrency = maxParallelTools
+	}
+	sem := make(chan struct{}, concurrency)
 
 	for i, tc := range toolCalls {
 		wg.Add(1)
@@ -566,19 +959,7 @@ func (e *ExploreAgent) executeToolsParallel(ctx context.Context, toolCalls []llm
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			slog.DebugContext(ctx, "explore agent executing tool",
-				"tool", call.Name,
-				"call_id", call.ID)
-
-			result, err := e.tools.Execute(ctx, call.Name, call.Arguments)
-			if err != nil {
-				result = fmt.Sprintf("Error: %s", err)
-			}
-
-			results[idx] = toolResult{
-				callID: call.ID,
-				result: result,
-			}
+			results[idx] = e.executeTool(ctx, call)
 		}(i, tc)
 	}
 
@@ -586,31 +967,43 @@ func (e *ExploreAgent) executeToolsParallel(ctx context.Context, toolCalls []llm
 	return results
 }
 
-// normalizeArgs normalizes JSON arguments for comparison.
-func normalizeArgs(args string) string {
-	var v any
-	if err := json.Unmarshal([]byte(args), &v); err != nil {
-		return args
-	}
-	normalized, err := json.Marshal(v)
+// executeTool runs a single tool call. Tool failures are not propagated:
+// they're flagged on the result and replaced with a tool-error notice, so the
+// model can tell a failed call from output that merely contains "Error:".
+func (e *ExploreAgent) executeTool(ctx context.Context, call llm.ToolCall) (result toolResult) {
+	span := startToolSpan(ctx, call)
+	defer func() { endToolSpan(span, result) }()
+	ctx = span.Context()
+
+	slog.DebugContext(ctx, "explore agent executing tool",
+		"tool", call.Name,
+		"call_id", call.ID)
+
+	res, err := e.tools.ExecuteResult(ctx, call.Name, call.Arguments)
 	if err != nil {
-		return args
+		slog.WarnContext(ctx, "explore tool failed",
+			"tool", call.Name,
+			"call_id", call.ID,
+			"error", err)
+		return toolResult{
+			callID:   call.ID,
+			result:   toolErrorMessage(call.Name, err),
+			failed:   true,
+			duration: res.Duration,
+		}
 	}
-	return string(normalized)
-}
 
-// allIdentical checks if all tool calls in the slice are identical.
-func allIdentical(calls []toolCallRecord) bool {
-	if len(calls) == 0 {
-		return false
-	}
-	first := calls[0]
-	for _, c := range calls[1:] {
-		if c.name != first.name || c.args != first.args {
-			return false
-		}
+	return toolResult{
+		callID:    call.ID,
+		result:    res.Output,
+		truncated: res.Truncated,
+		duration:  res.Duration,
 	}
-	return true
+}
+
+// toolErrorMessage is what the model sees in place of output for a failed call.
+func toolErrorMessage(tool string, err error) string {
+	return fmt.Sprintf("[tool error] %s did not run: %s\nThis is a failure of the call itself, not a search result. Fix the arguments or use a different tool.", tool, err)
 }
 
 func (e *ExploreAgent) writeDebugLog(sessionID, agentType, content string) {
@@ -654,14 +1047,26 @@ func (e *ExploreAgent) writeMetricsLog(metrics ExploreMetrics) {
 
 // systemPrompt returns the system prompt for the explore agent based on mode.
 func (e *ExploreAgent) systemPrompt(config ThoroughnessConfig, mode ExploreMode) string {
+	var prompt string
 	switch mode {
 	case ModeLocate:
-		return e.locateSystemPrompt(config)
+		prompt = e.locateSystemPrompt(config)
 	case ModeAnalyze:
-		return e.analyzeSystemPrompt(config)
+		prompt = e.analyzeSystemPrompt(config)
 	default:
-		return e.analyzeSystemPrompt(config)
+		prompt = e.analyzeSystemPrompt(config)
 	}
+	return withRepoConventions(prompt, e.repoConventions)
+}
+
+// withRepoConventions appends a team-provided conventions snippet (error
+// wrapping style, layering rules, ...) to a system prompt.
+func withRepoConventions(prompt, conventions string) string {
+	conventions = strings.TrimSpace(conventions)
+	if conventions == "" {
+		return prompt
+	}
+	return prompt + "\n\n# Repository Conventions\n\nThis team documented the following conventions for this repo. Prefer them over generic assumptions.\n\n" + conventions + "\n"
 }
 
 // locateSystemPrompt returns the system prompt for fast file location (ModeLocate).
@@ -705,7 +1110,7 @@ For Go code:
 
 # Token Budget
 
-You have approximately %d tokens for this search.
+You have approximately %d tokens for this search (%s thoroughness).
 - Work quickly — find locations, don't read contents deeply
 - Maximum %d tokens: you must synthesize by this point
 
@@ -751,7 +1156,7 @@ Structure your findings like this:
 # Context
 
 Go module: %s
-Codebase index: .basegraph/index.md`, config.SoftTokenTarget, config.HardTokenLimit, e.modulePath)
+Codebase index: .basegraph/index.md`, config.SoftTokenTarget, config.Level, config.HardTokenLimit, e.modulePath)
 }
 
 // analyzeSystemPrompt returns the system prompt for deep code analysis (ModeAnalyze).
@@ -763,7 +1168,7 @@ func (e *ExploreAgent) analyzeSystemPrompt(config ThoroughnessConfig) string {
 
 You have three types of tools:
 
-**STRUCTURE tools** — Understand code relationships (Go only)
+**STRUCTURE tools** — Understand code relationships (Go and TypeScript)
 - codegraph: Call chains, interface implementations, type usages
 
 **TEXT tools** — Find patterns in files
@@ -816,13 +1221,15 @@ The resolve operation converts name→qname. Never pass qname to resolve.
 
 ## Workflow
 
-Supported kinds (strict): function, method, struct, interface, class.
+Supported kinds (strict): %s.
 
 For structural questions in Go:
 1. Start with name — the tool resolves qname internally
    codegraph(operation="callers", name="Save", kind="method")
 2. Use resolve only to disambiguate or get the exact qname
    codegraph(operation="resolve", name="Save", kind="method", file="store/user.go")
+   To cite several symbols, resolve them in one call with names=[...]
+   codegraph(operation="resolve", names=["Save", "Find", "Repository"])
 3. Once you have a qname from results, you can use it directly
    codegraph(operation="callees", qname="github.com/acme/app/store.UserRepo.Save")
 4. Use trace for flow questions (fast + graph-accurate)
@@ -861,6 +1268,8 @@ When you explore, you're building a picture of reality. Reality includes gaps.
 - IDs/keys that link records (or "no join path")
 - Lifecycle boundary mismatches (pre vs post event)
 
+For Go structs, entity_map gives the fields, their db/json keys, who takes or returns the struct, and where each key is persisted in one call.
+
 **Report absences explicitly.** "I could not find X" is as valuable as "I found X at location Y" — especially when X is something you'd typically expect to exist. Don't just describe what's there; note what's surprisingly missing.
 
 The goal is to give the planner a complete picture — including the holes.
@@ -868,8 +1277,15 @@ The goal is to give the planner a complete picture — including the holes.
 # Tools Reference
 
 glob(pattern, path?) — Find files. Supports **, *, {a,b}. Returns paths by recency.
-grep(pattern, glob?, context?) — Search contents. Regex pattern. Returns file:line matches.
-codegraph(operation, ...) — Query code graph. Operations: search, resolve, file_symbols, callers, callees, implementations, usages, trace.
+grep(pattern, glob?, context?, literal?, offset?) — Search contents. Regex pattern (literal=true for exact text). Returns file:line matches; pass offset to page past the first 50.
+ast_grep(pattern, lang?, path?) — Structural search by syntax, e.g. "if err != nil { return nil, $ERR }". $NAME = one node, $$$ = many. Returns file:line matches.
+codegraph(operation, ...) — Query code graph. Operations: search, resolve, file_symbols, siblings, entrypoints, config_usages, hierarchy, describe, dependents, callers, callees, implementations, usages, neighborhood, trace.
+find_error(message, path?) — Find where an error message is constructed (Go). Returns file:line and enclosing function.
+entity_map(name | qname, file?) — A Go struct's fields with db/json tags, the functions taking or returning it, and the files mentioning each field's storage key. Starting point for the Entity & Join Map.
+symbol_diff(file, from?, to?) — Which functions/types a Go file added, removed or modified between two git refs, plus the diff.
+changed_files(base?, head?, path?) — Files changed between two git refs with added/modified/deleted status. Defaults to the current branch vs the default branch.
+git_history(file?, since?, limit?) — Recent commits, one line each (hash, date, author, subject). since like "30d". Prefer it over bash git log.
+write_note(observation, file?, symbol?) / read_notes(filter?) — Scratchpad for verified findings. Record key facts as you go instead of re-deriving them; notes are returned to you at synthesis.
 read(file_path, offset?, limit?) — Read file. Default 200 lines. Returns numbered lines.
 bash(command) — Git: log, diff, blame, show, status. File ops: cat, head, tail, grep, rg, ls, find.
 
@@ -880,7 +1296,7 @@ Codebase index: .basegraph/index.md
 
 # Token Budget
 
-You have approximately %d tokens for this exploration.
+You have approximately %d tokens for this exploration (%s thoroughness).
 - Around %d tokens: consider starting your report synthesis
 - Maximum %d tokens: you must synthesize by this point
 
@@ -944,5 +1360,5 @@ When you have gathered comprehensive evidence, write a detailed report:
 - Include a **Data Model & Persistence (Entity & Join Map)** section when relevant
 - Include **actual code snippets** for key logic (with file:line references)
 - Add as many numbered sections as needed to fully answer the question
-- The report should be **self-contained** — a reader shouldn't need to explore further`, e.modulePath, config.HardTokenLimit, config.SoftTokenTarget*80/100, config.HardTokenLimit)
+- The report should be **self-contained** — a reader shouldn't need to explore further`, e.tools.codegraphKindList(), e.modulePath, config.HardTokenLimit, config.Level, config.SoftTokenTarget*80/100, config.HardTokenLimit)
 }
@@ -0,0 +1,1309 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"basegraph.co/relay/common/arangodb"
+	"basegraph.co/relay/common/llm"
+	"basegraph.co/relay/internal/brain"
+)
+
+// scriptedLLM returns canned responses in order and records every request.
+type scriptedLLM struct {
+	mu        sync.Mutex
+	responses []llm.AgentResponse
+	requests  []llm.AgentRequest
+}
+
+func (s *scriptedLLM) ChatWithTools(ctx context.Context, req llm.AgentRequest) (*llm.AgentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req)
+	if len(s.responses) == 0 {
+		return nil, fmt.Errorf("scriptedLLM: no response left for call %d", len(s.requests))
+	}
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	return &resp, nil
+}
+
+func (s *scriptedLLM) Model() string { return "scripted" }
+
+func (s *scriptedLLM) lastUserMessage(callIdx int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := s.requests[callIdx].Messages
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == "user" {
+			return msgs[i].Content
+		}
+	}
+	return ""
+}
+
+// abortingLLM closes abort once the first response has been handed out, as
+// if the issue went stale while that turn's tools were running.
+type abortingLLM struct {
+	*scriptedLLM
+	abort chan struct{}
+	once  sync.Once
+}
+
+func (a *abortingLLM) ChatWithTools(ctx context.Context, req llm.AgentRequest) (*llm.AgentResponse, error) {
+	resp, err := a.scriptedLLM.ChatWithTools(ctx, req)
+	a.once.Do(func() { close(a.abort) })
+	return resp, err
+}
+
+// routedLLM answers each exploration from its own script, picked by the
+// query, so concurrent runs on one agent don't share responses. A run with a
+// gate waits for it to close before its gateAt-th answer.
+type routedLLM struct {
+	routes map[string]*scriptedLLM
+	gate   map[string]chan struct{}
+	gateAt map[string]int // Call the gate holds back (0, the first, by default)
+}
+
+func (r *routedLLM) ChatWithTools(ctx context.Context, req llm.AgentRequest) (*llm.AgentResponse, error) {
+	query := req.Messages[1].Content
+	route := r.routes[query]
+	route.mu.Lock()
+	call := len(route.requests)
+	route.mu.Unlock()
+	if gate, ok := r.gate[query]; ok && call == r.gateAt[query] {
+		<-gate
+	}
+	return route.ChatWithTools(ctx, req)
+}
+
+func (r *routedLLM) Model() string { return "routed" }
+
+// circuitOpenLLM hands out its scripted responses, then fails every call the
+// way an open circuit breaker does, counting the calls it rejected.
+type circuitOpenLLM struct {
+	*scriptedLLM
+	rejected int
+}
+
+func (c *circuitOpenLLM) ChatWithTools(ctx context.Context, req llm.AgentRequest) (*llm.AgentResponse, error) {
+	c.mu.Lock()
+	left := len(c.responses)
+	c.mu.Unlock()
+	if left == 0 {
+		c.rejected++
+		return nil, fmt.Errorf("%w: scripted", llm.ErrCircuitOpen)
+	}
+	return c.scriptedLLM.ChatWithTools(ctx, req)
+}
+
+// streamingLLM streams each scripted response's content in two halves
+// through ChatWithToolsStream.
+type streamingLLM struct {
+	*scriptedLLM
+	streamed int
+}
+
+func (s *streamingLLM) ChatWithToolsStream(ctx context.Context, req llm.AgentRequest) (<-chan llm.StreamEvent, error) {
+	resp, err := s.scriptedLLM.ChatWithTools(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.streamed++
+
+	events := make(chan llm.StreamEvent, 3)
+	half := len(resp.Content) / 2
+	for _, delta := range []string{resp.Content[:half], resp.Content[half:]} {
+		if delta != "" {
+			events <- llm.StreamEvent{ContentDelta: delta}
+		}
+	}
+	events <- llm.StreamEvent{Response: resp}
+	close(events)
+	return events, nil
+}
+
+// recordingSink keeps every ExploreMetrics it is given.
+type recordingSink struct {
+	mu      sync.Mutex
+	metrics []brain.ExploreMetrics
+}
+
+func (r *recordingSink) Record(_ context.Context, m brain.ExploreMetrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+	return nil
+}
+
+func globCall(id string) llm.ToolCall {
+	return llm.ToolCall{ID: id, Name: "glob", Arguments: `{"pattern":"*.go"}`}
+}
+
+var _ = Describe("ExploreAgent", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		tools   *brain.ExploreTools
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-agent-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(tempDir+"/main.go", []byte("package main\n"), 0o644)).To(Succeed())
+
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	Describe("confidence gating", func() {
+		It("runs one more exploration round after a low-confidence assessment", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{globCall("c1")}, PromptTokens: 100},
+				{Content: "First report", PromptTokens: 200},
+				{Content: "Low confidence: did not find the caller.", PromptTokens: 250},
+				{ToolCalls: []llm.ToolCall{globCall("c2")}, PromptTokens: 300},
+				{Content: "Second report", PromptTokens: 350},
+				{Content: "High confidence now.", PromptTokens: 400},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithConfidenceGating(true)
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.requests).To(HaveLen(6))
+			Expect(client.lastUserMessage(3)).To(ContainSubstring("one targeted search"))
+			Expect(report).To(HavePrefix("[confidence: high]"))
+			Expect(report).To(ContainSubstring("Second report"))
+			Expect(report).NotTo(ContainSubstring("First report"))
+		})
+
+		It("retries at most once", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "First report", PromptTokens: 100},
+				{Content: "Low confidence.", PromptTokens: 150},
+				{Content: "Second report", PromptTokens: 200},
+				{Content: "Still low confidence.", PromptTokens: 250},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithConfidenceGating(true)
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.requests).To(HaveLen(4))
+			Expect(report).To(HavePrefix("[confidence: low]"))
+		})
+
+		It("returns a low-confidence report unchanged when gating is off", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Only report", PromptTokens: 100},
+				{Content: "Low confidence.", PromptTokens: 150},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.requests).To(HaveLen(2))
+			Expect(report).To(HavePrefix("Only report"))
+		})
+	})
+
+	Describe("confidence assessment", func() {
+		confidenceCall := func(id, args string) llm.ToolCall {
+			return llm.ToolCall{ID: id, Name: "submit_confidence", Arguments: args}
+		}
+
+		readMetrics := func(debugDir string) brain.ExploreMetrics {
+			files, err := filepath.Glob(filepath.Join(debugDir, "explore_metrics_*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			data, err := os.ReadFile(files[0])
+			Expect(err).NotTo(HaveOccurred())
+			var metrics brain.ExploreMetrics
+			Expect(json.Unmarshal(data, &metrics)).To(Succeed())
+			return metrics
+		}
+
+		It("records a structured submit_confidence answer", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report", PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{confidenceCall("a1", `{"confidence":"medium","caveats":"did not check the tests"}`)}, PromptTokens: 150},
+			}}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", debugDir)
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.requests[1].Tools).To(HaveLen(1))
+			Expect(client.requests[1].Tools[0].Name).To(Equal("submit_confidence"))
+			Expect(report).To(ContainSubstring("**Confidence Assessment:** medium — did not check the tests"))
+
+			metrics := readMetrics(debugDir)
+			Expect(metrics.Confidence).To(Equal("medium"))
+			Expect(metrics.ConfidenceScore).To(BeNumerically("~", 0.6))
+			Expect(metrics.ConfidenceSource).To(Equal("submit_confidence"))
+		})
+
+		It("falls back to parsing prose when the model answers in text", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report", PromptTokens: 100},
+				{Content: "High confidence: traced every caller.", PromptTokens: 150},
+			}}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", debugDir)
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(ContainSubstring("**Confidence Assessment:** High confidence: traced every caller."))
+
+			metrics := readMetrics(debugDir)
+			Expect(metrics.Confidence).To(Equal("high"))
+			Expect(metrics.ConfidenceScore).To(BeNumerically("~", 0.9))
+			Expect(metrics.ConfidenceSource).To(Equal("prose"))
+		})
+
+		It("falls back to prose when submit_confidence has an invalid rating", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report", PromptTokens: 100},
+				{Content: "I'm fairly sure.", ToolCalls: []llm.ToolCall{confidenceCall("a1", `{"confidence":"certain"}`)}, PromptTokens: 150},
+			}}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", debugDir)
+			_, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			metrics := readMetrics(debugDir)
+			Expect(metrics.Confidence).To(Equal("unknown"))
+			Expect(metrics.ConfidenceScore).To(BeZero())
+			Expect(metrics.ConfidenceSource).To(Equal("prose"))
+		})
+
+		It("answers the submit_confidence call before a gated retry", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "First report", PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{confidenceCall("a1", `{"confidence":"low"}`)}, PromptTokens: 150},
+				{Content: "Second report", PromptTokens: 200},
+				{ToolCalls: []llm.ToolCall{confidenceCall("a2", `{"confidence":"high"}`)}, PromptTokens: 250},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithConfidenceGating(true)
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(HavePrefix("[confidence: high]"))
+
+			msgs := client.requests[2].Messages
+			Expect(msgs[len(msgs)-2].Role).To(Equal("tool"))
+			Expect(msgs[len(msgs)-2].ToolCallID).To(Equal("a1"))
+			Expect(len(client.requests[2].Tools)).To(BeNumerically(">", 1))
+		})
+	})
+
+	Describe("structured report", func() {
+		report := brain.ExploreReport{
+			Summary: "Webhooks are verified, then queued for the worker.",
+			Topics: []brain.ReportTopic{
+				{
+					Title: "Webhook intake",
+					Body:  "The handler checks the token before enqueueing.\n\n~~~go\n// webhook.go:12-14 - token check\nif !valid { return }\n~~~",
+					Files: []brain.FileReference{{Path: "webhook.go", Purpose: "HTTP handler"}},
+				},
+				{Title: "Worker", Body: "The worker reads the stream."},
+			},
+			KeyFindings:    []string{"Tokens are checked before anything is queued."},
+			FilesReference: []brain.FileReference{{Path: "webhook.go", Lines: "12-14", Purpose: "Token check"}},
+			Confidence:     "high",
+		}
+
+		exploreThenSubmit := func(args string) *scriptedLLM {
+			return &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "<report>## Summary\nWebhooks are verified, then queued.</report>", PromptTokens: 100},
+				{Content: "High confidence.", PromptTokens: 150},
+				{ToolCalls: []llm.ToolCall{{ID: "r1", Name: "submit_report", Arguments: args}}, PromptTokens: 200},
+			}}
+		}
+
+		It("round-trips a report submitted through submit_report", func() {
+			args, err := json.Marshal(report)
+			Expect(err).NotTo(HaveOccurred())
+			client := exploreThenSubmit(string(args))
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			got, err := agent.ExploreJSON(ctx, "how are webhooks handled?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(report))
+
+			By("asking for the structure with only submit_report available, given the markdown report")
+			last := client.requests[len(client.requests)-1]
+			Expect(last.Tools).To(HaveLen(1))
+			Expect(last.Tools[0].Name).To(Equal("submit_report"))
+			Expect(client.lastUserMessage(len(client.requests) - 1)).To(ContainSubstring("Webhooks are verified, then queued."))
+
+			By("surviving a JSON round trip unchanged")
+			data, err := json.Marshal(got)
+			Expect(err).NotTo(HaveOccurred())
+			var decoded brain.ExploreReport
+			Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+			Expect(decoded).To(Equal(report))
+		})
+
+		It("renders the structured report in the markdown report layout", func() {
+			md := report.Markdown()
+			Expect(md).To(HavePrefix("<report>\n## Summary\nWebhooks are verified, then queued for the worker.\n"))
+			Expect(md).To(ContainSubstring("## 1. Webhook intake\n\nThe handler checks the token"))
+			Expect(md).To(ContainSubstring("| webhook.go | HTTP handler |"))
+			Expect(md).To(ContainSubstring("## 2. Worker"))
+			Expect(md).To(ContainSubstring("## Key Findings\n\n1. Tokens are checked before anything is queued."))
+			Expect(md).To(ContainSubstring("| webhook.go | 12-14 | Token check |"))
+			Expect(md).To(HaveSuffix("## Confidence\nhigh\n</report>"))
+		})
+
+		It("normalizes an unrecognized confidence to unknown", func() {
+			client := exploreThenSubmit(`{"summary":"Queued for the worker.","topics":[],"confidence":"certain"}`)
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			got, err := agent.ExploreJSON(ctx, "how are webhooks handled?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got.Summary).To(Equal("Queued for the worker."))
+			Expect(got.Confidence).To(Equal("unknown"))
+		})
+
+		It("returns the markdown as the summary when the model doesn't call submit_report", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report", PromptTokens: 100},
+				{Content: "High confidence.", PromptTokens: 150},
+				{Content: "Here is the report in JSON: ...", PromptTokens: 200},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			got, err := agent.ExploreJSON(ctx, "who calls main?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got.Summary).To(HavePrefix("Report"))
+			Expect(got.Summary).To(ContainSubstring("**Confidence Assessment:** High confidence."))
+			Expect(got.Topics).To(BeEmpty())
+			Expect(got.Confidence).To(Equal("unknown"))
+		})
+	})
+
+	Describe("metrics", func() {
+		It("records output bytes per tool", func() {
+			readArgs := `{"file_path":"main.go"}`
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{
+					globCall("c1"),
+					{ID: "c2", Name: "read", Arguments: readArgs},
+				}, PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{globCall("c3")}, PromptTokens: 200},
+				{Content: "Report", PromptTokens: 300},
+				{Content: "High confidence.", PromptTokens: 350},
+			}}
+
+			globOut, err := tools.Execute(ctx, "glob", globCall("x").Arguments)
+			Expect(err).NotTo(HaveOccurred())
+			readOut, err := tools.Execute(ctx, "read", readArgs)
+			Expect(err).NotTo(HaveOccurred())
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", debugDir)
+			_, err = agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			files, err := filepath.Glob(filepath.Join(debugDir, "explore_metrics_*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+
+			data, err := os.ReadFile(files[0])
+			Expect(err).NotTo(HaveOccurred())
+			var metrics brain.ExploreMetrics
+			Expect(json.Unmarshal(data, &metrics)).To(Succeed())
+
+			Expect(metrics.ToolOutputBytes).To(Equal(map[string]int{
+				"glob": 2 * len(globOut),
+				"read": len(readOut),
+			}))
+		})
+
+		It("sends fully populated metrics to the sink", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{globCall("c1")}, PromptTokens: 100, CompletionTokens: 10},
+				{Content: "Report", PromptTokens: 300, CompletionTokens: 40},
+				{Content: "High confidence.", PromptTokens: 350, CompletionTokens: 5},
+			}}
+
+			sink := &recordingSink{}
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithMetricsSink(sink)
+			_, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessQuick)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sink.metrics).To(HaveLen(1))
+			m := sink.metrics[0]
+			Expect(m.SessionID).NotTo(BeEmpty())
+			Expect(m.Query).To(Equal("what is in main.go?"))
+			Expect(m.Thoroughness).To(Equal("quick"))
+			Expect(m.StartTime).NotTo(BeZero())
+			Expect(m.EndTime).NotTo(BeTemporally("<", m.StartTime))
+			Expect(m.Iterations).To(Equal(3))
+			Expect(m.ContextWindowTokens).To(Equal(350))
+			Expect(m.TotalCompletionTokens).To(Equal(55))
+			Expect(m.ToolCalls).To(Equal(map[string]int{"glob": 1}))
+			Expect(m.ToolOutputBytes).To(HaveKey("glob"))
+			Expect(m.Confidence).To(Equal("high"))
+			Expect(m.TerminationReason).NotTo(BeEmpty())
+			Expect(m.FinalReportLen).To(BeNumerically(">", 0))
+		})
+	})
+
+	Describe("tool errors", func() {
+		It("marks failed tool calls and counts them per tool", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{
+					{ID: "c1", Name: "glob", Arguments: `{"pattern":`},
+					globCall("c2"),
+				}, PromptTokens: 100},
+				{Content: "Report", PromptTokens: 200},
+				{Content: "High confidence.", PromptTokens: 250},
+			}}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", debugDir)
+			_, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			var toolMsgs []llm.Message
+			for _, m := range client.requests[1].Messages {
+				if m.Role == "tool" {
+					toolMsgs = append(toolMsgs, m)
+				}
+			}
+			Expect(toolMsgs).To(HaveLen(2))
+			Expect(toolMsgs[0].Content).To(HavePrefix("[tool error] glob did not run: parse glob params"))
+			Expect(toolMsgs[1].Content).To(ContainSubstring("main.go"))
+			Expect(toolMsgs[1].Content).NotTo(ContainSubstring("[tool error]"))
+
+			files, err := filepath.Glob(filepath.Join(debugDir, "explore_metrics_*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+
+			data, err := os.ReadFile(files[0])
+			Expect(err).NotTo(HaveOccurred())
+			var metrics brain.ExploreMetrics
+			Expect(json.Unmarshal(data, &metrics)).To(Succeed())
+			Expect(metrics.ToolErrors).To(Equal(map[string]int{"glob": 1}))
+		})
+	})
+
+	Describe("codegraph latency", func() {
+		It("records per-operation durations", func() {
+			fake := &fakeArangoClient{
+				searchSymbolsFn: func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+					time.Sleep(15 * time.Millisecond)
+					return nil, 0, nil
+				},
+			}
+			searchArgs := `{"operation":"search","name":"Plan"}`
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "codegraph", Arguments: searchArgs}}, PromptTokens: 100},
+				{Content: "Report", PromptTokens: 200},
+				{Content: "High confidence.", PromptTokens: 250},
+			}}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, brain.NewExploreTools(tempDir, fake, brain.ExploreToolsConfig{}), "example.com/app", debugDir)
+			_, err := agent.Explore(ctx, "where is Plan?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			files, err := filepath.Glob(filepath.Join(debugDir, "explore_metrics_*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+
+			data, err := os.ReadFile(files[0])
+			Expect(err).NotTo(HaveOccurred())
+			var metrics brain.ExploreMetrics
+			Expect(json.Unmarshal(data, &metrics)).To(Succeed())
+
+			Expect(metrics.CodegraphLatency).To(HaveKey("search"))
+			stats := metrics.CodegraphLatency["search"]
+			Expect(stats.Count).To(Equal(1))
+			Expect(stats.MaxMs).To(BeNumerically(">=", 10))
+			Expect(stats.MinMs).To(Equal(stats.MaxMs))
+		})
+	})
+
+	Describe("minimum tool iterations", func() {
+		It("sends a conclusion without tool use back to gather evidence", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Early report", PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{globCall("c1")}, PromptTokens: 150},
+				{Content: "Grounded report", PromptTokens: 200},
+				{Content: "High confidence.", PromptTokens: 250},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithMinToolIterations(1)
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.requests).To(HaveLen(4))
+			Expect(client.lastUserMessage(1)).To(ContainSubstring("Gather evidence first"))
+			Expect(report).To(HavePrefix("Grounded report"))
+		})
+
+		It("gives up nudging after repeated early conclusions", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report 1", PromptTokens: 100},
+				{Content: "Report 2", PromptTokens: 120},
+				{Content: "Report 3", PromptTokens: 140},
+				{Content: "Medium confidence.", PromptTokens: 160},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithMinToolIterations(3)
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.requests).To(HaveLen(4))
+			Expect(report).To(HavePrefix("Report 3"))
+		})
+	})
+
+	Describe("tool concurrency", func() {
+		searchCall := func(id, name string) llm.ToolCall {
+			return llm.ToolCall{ID: id, Name: "codegraph", Arguments: fmt.Sprintf(`{"operation":"search","name":%q}`, name)}
+		}
+
+		// orderedTools returns tools whose searches record the order they ran
+		// in; Alpha sleeps so parallel calls finish out of call order.
+		orderedTools := func(order *[]string) *brain.ExploreTools {
+			var mu sync.Mutex
+			fake := &fakeArangoClient{
+				searchSymbolsFn: func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+					if opts.Name == "Alpha" {
+						time.Sleep(50 * time.Millisecond)
+					}
+					mu.Lock()
+					*order = append(*order, opts.Name)
+					mu.Unlock()
+					return []arangodb.SearchResult{{
+						QName:    "example.com/app." + opts.Name,
+						Name:     opts.Name,
+						Kind:     "function",
+						Filepath: filepath.Join(tempDir, "main.go"),
+						Pos:      1,
+					}}, 1, nil
+				},
+			}
+			return brain.NewExploreTools(tempDir, fake, brain.ExploreToolsConfig{})
+		}
+
+		run := func(concurrency int, order *[]string) []llm.Message {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{
+					searchCall("c1", "Alpha"),
+					searchCall("c2", "Beta"),
+					searchCall("c3", "Gamma"),
+				}, PromptTokens: 100},
+				{Content: "Report", PromptTokens: 200},
+				{Content: "High confidence.", PromptTokens: 250},
+			}}
+
+			agent := brain.NewExploreAgent(client, orderedTools(order), "example.com/app", "").
+				WithToolConcurrency(concurrency)
+			_, err := agent.Explore(ctx, "where are the handlers?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			var toolMsgs []llm.Message
+			for _, m := range client.requests[1].Messages {
+				if m.Role == "tool" {
+					toolMsgs = append(toolMsgs, m)
+				}
+			}
+			return toolMsgs
+		}
+
+		expectAligned := func(toolMsgs []llm.Message) {
+			Expect(toolMsgs).To(HaveLen(3))
+			for i, name := range []string{"Alpha", "Beta", "Gamma"} {
+				Expect(toolMsgs[i].ToolCallID).To(Equal(fmt.Sprintf("c%d", i+1)))
+				Expect(toolMsgs[i].Content).To(ContainSubstring("example.com/app." + name))
+			}
+		}
+
+		It("runs tool calls in call order when sequential", func() {
+			var order []string
+			toolMsgs := run(1, &order)
+
+			Expect(order).To(Equal([]string{"Alpha", "Beta", "Gamma"}))
+			expectAligned(toolMsgs)
+		})
+
+		It("keeps results aligned with tool calls when parallel", func() {
+			var order []string
+			toolMsgs := run(0, &order)
+
+			Expect(order).To(ConsistOf("Alpha", "Beta", "Gamma"))
+			Expect(order[2]).To(Equal("Alpha"))
+			expectAligned(toolMsgs)
+		})
+	})
+
+	Describe("repo conventions", func() {
+		It("appends the conventions snippet to the system prompt", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report", PromptTokens: 100},
+				{Content: "High confidence.", PromptTokens: 150},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").
+				WithRepoConventions("Errors are wrapped with fmt.Errorf(\"doing x: %w\", err).")
+			_, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			system := client.requests[0].Messages[0]
+			Expect(system.Role).To(Equal("system"))
+			Expect(system.Content).To(ContainSubstring("# Repository Conventions"))
+			Expect(system.Content).To(ContainSubstring(`fmt.Errorf("doing x: %w", err)`))
+		})
+
+		It("leaves the prompt unchanged by default", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report", PromptTokens: 100},
+				{Content: "High confidence.", PromptTokens: 150},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			_, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.requests[0].Messages[0].Content).NotTo(ContainSubstring("Repository Conventions"))
+		})
+	})
+
+	Describe("doom loop", func() {
+		grepCall := func(id, pattern string) llm.ToolCall {
+			return llm.ToolCall{ID: id, Name: "grep", Arguments: fmt.Sprintf(`{"pattern":%q,"path":"internal"}`, pattern)}
+		}
+
+		It("breaks out when grep is retried with near-identical patterns", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{grepCall("c1", "Plan")}, PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{grepCall("c2", "Planner")}, PromptTokens: 200},
+				{ToolCalls: []llm.ToolCall{grepCall("c3", "PlanV2")}, PromptTokens: 300},
+				{Content: "Forced report", PromptTokens: 400},
+			}}
+
+			sink := &recordingSink{}
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithMetricsSink(sink)
+			report, err := agent.Explore(ctx, "where is the planner?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(Equal("Forced report"))
+			Expect(client.requests).To(HaveLen(4))
+			Expect(client.requests[3].Tools).To(BeEmpty())
+			Expect(client.lastUserMessage(3)).To(ContainSubstring("searching for the same thing repeatedly"))
+			Expect(sink.metrics[0].DoomLoopDetected).To(BeTrue())
+			Expect(sink.metrics[0].TerminationReason).To(Equal("doom_loop"))
+		})
+
+		It("catches near-identical calls spread over the window", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{grepCall("c1", "Planner")}, PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{globCall("c2")}, PromptTokens: 200},
+				{ToolCalls: []llm.ToolCall{grepCall("c3", "planner")}, PromptTokens: 300},
+				{ToolCalls: []llm.ToolCall{grepCall("c4", "Planners")}, PromptTokens: 400},
+				{Content: "Forced report", PromptTokens: 500},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			report, err := agent.Explore(ctx, "where is the planner?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(Equal("Forced report"))
+			Expect(client.requests).To(HaveLen(5))
+		})
+
+		It("keeps going when the searches differ", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{grepCall("c1", "Planner")}, PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{grepCall("c2", "webhook")}, PromptTokens: 200},
+				{ToolCalls: []llm.ToolCall{grepCall("c3", "RetryPolicy")}, PromptTokens: 300},
+				{Content: "Report", PromptTokens: 400},
+				{Content: "High confidence.", PromptTokens: 450},
+			}}
+
+			sink := &recordingSink{}
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithMetricsSink(sink)
+			report, err := agent.Explore(ctx, "where is the planner?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(HavePrefix("Report"))
+			Expect(sink.metrics[0].DoomLoopDetected).To(BeFalse())
+		})
+	})
+
+	Describe("thoroughness", func() {
+		// toolTurns returns n turns that each glob a different pattern, so the
+		// doom loop check never fires.
+		toolTurns := func(n int) []llm.AgentResponse {
+			turns := make([]llm.AgentResponse, 0, n)
+			for i := range n {
+				turns = append(turns, llm.AgentResponse{
+					ToolCalls:    []llm.ToolCall{{ID: fmt.Sprintf("c%d", i), Name: "glob", Arguments: fmt.Sprintf(`{"pattern":"*%d.go"}`, i)}},
+					PromptTokens: 100,
+				})
+			}
+			return turns
+		}
+
+		It("forces synthesis after 30 iterations when quick", func() {
+			client := &scriptedLLM{responses: append(toolTurns(30), llm.AgentResponse{Content: "Forced report", PromptTokens: 100})}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			report, err := agent.Explore(ctx, "where is main?", brain.ThoroughnessQuick)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(Equal("Forced report"))
+			Expect(client.requests).To(HaveLen(31))
+			Expect(client.lastUserMessage(30)).To(ContainSubstring("Maximum exploration steps reached"))
+			Expect(client.requests[0].Messages[0].Content).To(ContainSubstring("(quick thoroughness)"))
+		})
+
+		It("keeps exploring past 30 iterations when thorough", func() {
+			responses := append(toolTurns(31),
+				llm.AgentResponse{Content: "Report", PromptTokens: 100},
+				llm.AgentResponse{Content: "High confidence.", PromptTokens: 100},
+			)
+			client := &scriptedLLM{responses: responses}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", debugDir)
+			report, err := agent.Explore(ctx, "where is main?", brain.ThoroughnessThorough)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(HavePrefix("Report"))
+			Expect(client.requests).To(HaveLen(33))
+			Expect(client.requests[0].Messages[0].Content).To(ContainSubstring("(thorough thoroughness)"))
+
+			files, err := filepath.Glob(filepath.Join(debugDir, "explore_metrics_*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			data, err := os.ReadFile(files[0])
+			Expect(err).NotTo(HaveOccurred())
+			var metrics brain.ExploreMetrics
+			Expect(json.Unmarshal(data, &metrics)).To(Succeed())
+			Expect(metrics.Thoroughness).To(Equal("thorough"))
+			Expect(metrics.TerminationReason).To(Equal("natural"))
+		})
+	})
+
+	Describe("abort", func() {
+		It("stops within one iteration of the abort channel closing", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{globCall("c1")}, PromptTokens: 100},
+				{Content: "Partial report", PromptTokens: 150},
+			}}
+			abort := make(chan struct{})
+			aborting := &abortingLLM{scriptedLLM: client, abort: abort}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(aborting, tools, "example.com/app", debugDir)
+			report, err := agent.Explore(brain.WithExploreAbort(ctx, abort), "what is in main.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.requests).To(HaveLen(2))
+			Expect(client.requests[1].Tools).To(BeEmpty())
+			Expect(client.lastUserMessage(1)).To(ContainSubstring("cut short"))
+			Expect(report).To(HavePrefix("[exploration cut short]"))
+			Expect(report).To(ContainSubstring("Partial report"))
+
+			files, err := filepath.Glob(filepath.Join(debugDir, "explore_metrics_*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			data, err := os.ReadFile(files[0])
+			Expect(err).NotTo(HaveOccurred())
+			var metrics brain.ExploreMetrics
+			Expect(json.Unmarshal(data, &metrics)).To(Succeed())
+			Expect(metrics.TerminationReason).To(Equal("aborted"))
+		})
+
+		It("leaves a concurrent run on the same agent going", func() {
+			aborted := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Partial report", PromptTokens: 150},
+			}}
+			running := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{globCall("c1")}, PromptTokens: 100},
+				{Content: "Full report", PromptTokens: 200},
+				{Content: "High confidence", PromptTokens: 250},
+			}}
+			abortedDone := make(chan struct{})
+			client := &routedLLM{
+				routes: map[string]*scriptedLLM{"first question": aborted, "second question": running},
+				// The second run only gets its first answer once the first
+				// run has been aborted, so both are in flight together.
+				gate: map[string]chan struct{}{"second question": abortedDone},
+			}
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+
+			abort := make(chan struct{})
+			close(abort)
+
+			var fullReport string
+			var fullErr error
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fullReport, fullErr = agent.Explore(ctx, "second question", brain.ThoroughnessMedium)
+			}()
+
+			partial, err := agent.Explore(brain.WithExploreAbort(ctx, abort), "first question", brain.ThoroughnessMedium)
+			close(abortedDone)
+			wg.Wait()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial).To(HavePrefix("[exploration cut short]"))
+			Expect(fullErr).NotTo(HaveOccurred())
+			Expect(fullReport).NotTo(ContainSubstring("cut short"))
+			Expect(fullReport).To(ContainSubstring("Full report"))
+			Expect(running.requests).To(HaveLen(3))
+		})
+	})
+
+	Describe("telemetry", func() {
+		var recorder *tracetest.SpanRecorder
+
+		BeforeEach(func() {
+			recorder = tracetest.NewSpanRecorder()
+			prev := otel.GetTracerProvider()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			DeferCleanup(otel.SetTracerProvider, prev)
+		})
+
+		spansNamed := func(name string) []sdktrace.ReadOnlySpan {
+			var spans []sdktrace.ReadOnlySpan
+			for _, span := range recorder.Ended() {
+				if span.Name() == name {
+					spans = append(spans, span)
+				}
+			}
+			return spans
+		}
+
+		It("records one span per tool call under its iteration", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{
+					globCall("c1"),
+					{ID: "c2", Name: "glob", Arguments: `{"pattern":"**/*.md"}`},
+				}, PromptTokens: 100, CompletionTokens: 10},
+				{Content: "Report", PromptTokens: 200},
+				{Content: "High confidence", PromptTokens: 250},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			_, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			sessions := spansNamed("brain.explore.session")
+			Expect(sessions).To(HaveLen(1))
+			iterations := spansNamed("brain.explore.iteration")
+			Expect(iterations).To(HaveLen(3))
+			for _, iter := range iterations {
+				Expect(iter.Parent().SpanID()).To(Equal(sessions[0].SpanContext().SpanID()))
+			}
+
+			toolSpans := spansNamed("brain.explore.tool")
+			Expect(toolSpans).To(HaveLen(2))
+			callIDs := map[string]bool{}
+			for _, span := range toolSpans {
+				Expect(span.Parent().SpanID()).To(Equal(iterations[0].SpanContext().SpanID()))
+				attrs := map[string]attribute.Value{}
+				for _, kv := range span.Attributes() {
+					attrs[string(kv.Key)] = kv.Value
+				}
+				Expect(attrs["tool.name"].AsString()).To(Equal("glob"))
+				Expect(attrs["tool.args_bytes"].AsInt64()).To(BeNumerically(">", 0))
+				Expect(attrs["tool.result_bytes"].AsInt64()).To(BeNumerically(">", 0))
+				callIDs[attrs["tool.call_id"].AsString()] = true
+			}
+			Expect(callIDs).To(HaveKey("c1"))
+			Expect(callIDs).To(HaveKey("c2"))
+		})
+	})
+
+	Describe("cache", func() {
+		git := func(args ...string) {
+			cmd := exec.Command("git", append([]string{"-C", tempDir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+			out, err := cmd.CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+		}
+
+		BeforeEach(func() {
+			git("init", "-q")
+			git("add", "main.go")
+			git("commit", "-q", "-m", "init")
+		})
+
+		It("serves an identical query at the same commit from the cache", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report", PromptTokens: 100},
+				{Content: "High confidence", PromptTokens: 150},
+			}}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", debugDir).
+				WithCache(brain.NewMemoryExploreCache(time.Hour))
+
+			first, err := agent.Explore(ctx, "Who calls main?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := agent.Explore(ctx, "  who calls   MAIN? ", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(second).To(Equal(first))
+			Expect(client.requests).To(HaveLen(2))
+
+			files, err := filepath.Glob(filepath.Join(debugDir, "explore_metrics_*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			var hits, misses int
+			for _, f := range files {
+				data, err := os.ReadFile(f)
+				Expect(err).NotTo(HaveOccurred())
+				var metrics brain.ExploreMetrics
+				Expect(json.Unmarshal(data, &metrics)).To(Succeed())
+				hits += metrics.CacheHits
+				misses += metrics.CacheMisses
+			}
+			Expect(hits).To(Equal(1))
+			Expect(misses).To(Equal(1))
+		})
+
+		It("explores again once HEAD moves", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Old report", PromptTokens: 100},
+				{Content: "High confidence", PromptTokens: 150},
+				{Content: "New report", PromptTokens: 100},
+				{Content: "High confidence", PromptTokens: 150},
+			}}
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").
+				WithCache(brain.NewMemoryExploreCache(time.Hour))
+
+			_, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644)).To(Succeed())
+			git("commit", "-q", "-am", "add main")
+
+			report, err := agent.Explore(ctx, "who calls main?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(ContainSubstring("New report"))
+			Expect(client.requests).To(HaveLen(4))
+		})
+	})
+
+	Describe("compaction", func() {
+		// Distinct offsets keep doom loop detection out of the way.
+		readCall := func(id string) llm.ToolCall {
+			return llm.ToolCall{ID: id, Name: "read", Arguments: fmt.Sprintf(`{"file_path":"big.go","offset":%c,"limit":400}`, id[1])}
+		}
+
+		BeforeEach(func() {
+			var b strings.Builder
+			for i := 1; i <= 400; i++ {
+				fmt.Fprintf(&b, "var generatedValue%04d = \"some generated content for line %04d\"\n", i, i)
+			}
+			Expect(os.WriteFile(filepath.Join(tempDir, "big.go"), []byte(b.String()), 0o644)).To(Succeed())
+		})
+
+		toolContents := func(req llm.AgentRequest) map[string]string {
+			contents := map[string]string{}
+			for _, m := range req.Messages {
+				if m.Role == "tool" {
+					contents[m.ToolCallID] = m.Content
+				}
+			}
+			return contents
+		}
+
+		It("replaces old tool results past the soft target and keeps the newest", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{readCall("c1")}, PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{readCall("c2")}, PromptTokens: 30000},
+				{ToolCalls: []llm.ToolCall{readCall("c3")}, PromptTokens: 45000},
+				{Content: "Report", PromptTokens: 46000},
+				{Content: "High confidence", PromptTokens: 46100},
+			}}
+
+			debugDir := filepath.Join(tempDir, "debug")
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", debugDir).WithCompaction(1)
+			report, err := agent.Explore(ctx, "what is in big.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(ContainSubstring("Report"))
+
+			// Below the soft target (40000 for medium) nothing is touched.
+			before := toolContents(client.requests[2])
+			Expect(before["c1"]).To(ContainSubstring("generatedValue0010"))
+			Expect(before["c2"]).To(ContainSubstring("generatedValue0010"))
+
+			// Past it, all but the newest result become stubs.
+			after := toolContents(client.requests[3])
+			Expect(after["c1"]).To(HavePrefix("[compacted] read output"))
+			Expect(after["c2"]).To(HavePrefix("[compacted] read output"))
+			Expect(after["c3"]).To(ContainSubstring("generatedValue0010"))
+
+			files, err := filepath.Glob(filepath.Join(debugDir, "explore_metrics_*.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			data, err := os.ReadFile(files[0])
+			Expect(err).NotTo(HaveOccurred())
+			var metrics brain.ExploreMetrics
+			Expect(json.Unmarshal(data, &metrics)).To(Succeed())
+			Expect(metrics.CompactedResults).To(Equal(2))
+			Expect(metrics.CompactedTokens).To(BeNumerically(">", 5000))
+			Expect(metrics.TerminationReason).NotTo(Equal("hard_limit"))
+
+			logs, err := filepath.Glob(filepath.Join(debugDir, "explore_*.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs).NotTo(BeEmpty())
+			debugLog, err := os.ReadFile(logs[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(debugLog)).To(ContainSubstring("[TOOL RESULT] read (call c1)"))
+			Expect(string(debugLog)).To(ContainSubstring("[COMPACTED] read (call c1"))
+		})
+
+		It("compacts instead of telling the model to stop exploring", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{readCall("c1")}, PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{readCall("c2")}, PromptTokens: 33000},
+				{ToolCalls: []llm.ToolCall{readCall("c3")}, PromptTokens: 45000},
+				{Content: "Report", PromptTokens: 46000},
+				{Content: "High confidence", PromptTokens: 46100},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").WithCompaction(1)
+			_, err := agent.Explore(ctx, "what is in big.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Past 80% of the soft target (32000 for medium) the model is not
+			// nudged to wrap up; past the target itself, old output is compacted.
+			for _, req := range client.requests {
+				for _, m := range req.Messages {
+					Expect(m.Content).NotTo(ContainSubstring("Stop exploring"))
+				}
+			}
+			Expect(toolContents(client.requests[2])["c1"]).To(ContainSubstring("generatedValue0010"))
+			Expect(toolContents(client.requests[3])["c1"]).To(HavePrefix("[compacted] read output"))
+		})
+
+		It("nudges at 80% of the soft target when compaction is off", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{readCall("c1")}, PromptTokens: 33000},
+				{Content: "Report", PromptTokens: 34000},
+				{Content: "High confidence", PromptTokens: 34100},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			_, err := agent.Explore(ctx, "what is in big.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.lastUserMessage(1)).To(ContainSubstring("Stop exploring"))
+		})
+
+		It("is off by default", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{readCall("c1")}, PromptTokens: 45000},
+				{ToolCalls: []llm.ToolCall{readCall("c2")}, PromptTokens: 46000},
+				{Content: "Report", PromptTokens: 47000},
+				{Content: "High confidence", PromptTokens: 47100},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			_, err := agent.Explore(ctx, "what is in big.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(toolContents(client.requests[2])["c1"]).To(ContainSubstring("generatedValue0010"))
+		})
+	})
+
+	Describe("llm circuit breaker", func() {
+		It("ends the exploration with the notes gathered so far", func() {
+			noteArgs := `{"file":"main.go","symbol":"main","observation":"Entry point, no flags."}`
+			client := &circuitOpenLLM{scriptedLLM: &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "write_note", Arguments: noteArgs}}, PromptTokens: 100},
+			}}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			report, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(HavePrefix("[exploration cut short]"))
+			Expect(report).To(ContainSubstring("model provider is unavailable"))
+			Expect(report).To(ContainSubstring("Entry point, no flags."))
+		})
+
+		It("does not attempt a synthesis turn through the open breaker", func() {
+			noteArgs := `{"file":"main.go","symbol":"main","observation":"Entry point, no flags."}`
+			client := &circuitOpenLLM{scriptedLLM: &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "write_note", Arguments: noteArgs}}, PromptTokens: 100},
+			}}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			_, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.rejected).To(Equal(1))
+			Expect(client.requests).To(HaveLen(1))
+		})
+
+		It("returns the circuit error when there is nothing to report", func() {
+			client := &circuitOpenLLM{scriptedLLM: &scriptedLLM{}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			_, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+
+			Expect(err).To(MatchError(llm.ErrCircuitOpen))
+		})
+	})
+
+	Describe("content streaming", func() {
+		It("forwards streamed text and still returns the full report", func() {
+			client := &streamingLLM{scriptedLLM: &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{globCall("c1")}, PromptTokens: 100},
+				{Content: "main.go holds the entry point.", PromptTokens: 200},
+				{Content: "High confidence", PromptTokens: 250},
+			}}}
+
+			var streamed strings.Builder
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").
+				WithContentStream(func(delta string) { streamed.WriteString(delta) })
+			report, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(ContainSubstring("main.go holds the entry point."))
+			Expect(client.streamed).To(Equal(3))
+			Expect(streamed.String()).To(HavePrefix("main.go holds the entry point."))
+		})
+
+		It("streams forced synthesis", func() {
+			client := &streamingLLM{scriptedLLM: &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{globCall("c1")}, PromptTokens: 70000},
+				{Content: "Forced report", PromptTokens: 70100},
+			}}}
+
+			var streamed strings.Builder
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "").
+				WithContentStream(func(delta string) { streamed.WriteString(delta) })
+			report, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(Equal("Forced report"))
+			Expect(streamed.String()).To(Equal("Forced report"))
+		})
+
+		It("does not stream without a content callback", func() {
+			client := &streamingLLM{scriptedLLM: &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "Report", PromptTokens: 100},
+				{Content: "High confidence", PromptTokens: 150},
+			}}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			_, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.streamed).To(BeZero())
+		})
+	})
+
+	Describe("notes", func() {
+		It("hands notes to forced synthesis and starts each call with an empty scratchpad", func() {
+			noteArgs := `{"file":"main.go","symbol":"main","observation":"Entry point, no flags."}`
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "write_note", Arguments: noteArgs}}, PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{globCall("c2")}, PromptTokens: 70000},
+				{Content: "Forced report", PromptTokens: 70100},
+				{ToolCalls: []llm.ToolCall{globCall("c3")}, PromptTokens: 70000},
+				{Content: "Second forced report", PromptTokens: 70100},
+			}}
+
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+			report, err := agent.Explore(ctx, "what is in main.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(Equal("Forced report"))
+			Expect(client.lastUserMessage(2)).To(ContainSubstring("- main.go (main): Entry point, no flags."))
+
+			_, err = agent.Explore(ctx, "what else is in main.go?", brain.ThoroughnessMedium)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.lastUserMessage(4)).NotTo(ContainSubstring("Entry point"))
+		})
+
+		It("keeps the scratchpads of concurrent runs apart", func() {
+			first := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "write_note", Arguments: `{"observation":"first run note"}`}}, PromptTokens: 100},
+				{ToolCalls: []llm.ToolCall{{ID: "c2", Name: "read_notes", Arguments: `{}`}}, PromptTokens: 150},
+				{Content: "First report", PromptTokens: 200},
+				{Content: "High confidence", PromptTokens: 250},
+			}}
+			second := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "write_note", Arguments: `{"observation":"second run note"}`}}, PromptTokens: 100},
+				{Content: "Second report", PromptTokens: 200},
+				{Content: "High confidence", PromptTokens: 250},
+			}}
+			secondDone := make(chan struct{})
+			client := &routedLLM{
+				routes: map[string]*scriptedLLM{"first question": first, "second question": second},
+				// The first run reads its notes only after the second run has
+				// started, written its own note and finished.
+				gate:   map[string]chan struct{}{"first question": secondDone},
+				gateAt: map[string]int{"first question": 1},
+			}
+			agent := brain.NewExploreAgent(client, tools, "example.com/app", "")
+
+			var firstErr error
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, firstErr = agent.Explore(ctx, "first question", brain.ThoroughnessMedium)
+			}()
+			Eventually(func() int {
+				first.mu.Lock()
+				defer first.mu.Unlock()
+				return len(first.requests)
+			}).Should(Equal(1))
+
+			_, err := agent.Explore(ctx, "second question", brain.ThoroughnessMedium)
+			close(secondDone)
+			wg.Wait()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(firstErr).NotTo(HaveOccurred())
+			var readNotes string
+			for _, m := range first.requests[2].Messages {
+				if m.ToolCallID == "c2" {
+					readNotes = m.Content
+				}
+			}
+			Expect(readNotes).To(ContainSubstring("first run note"))
+			Expect(readNotes).NotTo(ContainSubstring("second run note"))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+package brain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultExploreCacheTTL is how long a cached explore report stays fresh.
+const DefaultExploreCacheTTL = 24 * time.Hour
+
+// ExploreCache stores explore reports by cache key. Keys include the repo's
+// HEAD commit, so a report is only reused while the code it describes is
+// unchanged; the TTL bounds how long it is kept.
+type ExploreCache interface {
+	Get(ctx context.Context, key string) (report string, ok bool, err error)
+	Set(ctx context.Context, key string, report string) error
+}
+
+// exploreCacheKey identifies an exploration: the same question (ignoring
+// case and spacing) asked in the same mode and depth at the same commit.
+func exploreCacheKey(commit string, mode ExploreMode, thoroughness Thoroughness, query string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	sum := sha256.Sum256([]byte(strings.Join([]string{commit, string(mode), string(thoroughness), normalized}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// headCommit returns the repo's HEAD commit, or "" when it can't be read
+// (git disabled, not a git checkout), in which case nothing is cached.
+func (t *ExploreTools) headCommit(ctx context.Context) string {
+	if t.gitDisabled {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.timeouts.Bash)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "git", "-C", t.repoRoot, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+type memoryExploreCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]memoryExploreEntry
+}
+
+type memoryExploreEntry struct {
+	report  string
+	expires time.Time
+}
+
+// NewMemoryExploreCache returns a process-local ExploreCache. ttl <= 0 uses
+// DefaultExploreCacheTTL.
+func NewMemoryExploreCache(ttl time.Duration) ExploreCache {
+	if ttl <= 0 {
+		ttl = DefaultExploreCacheTTL
+	}
+	return &memoryExploreCache{ttl: ttl, entries: make(map[string]memoryExploreEntry)}
+}
+
+func (c *memoryExploreCache) Get(_ context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return "", false, nil
+	}
+	return entry.report, true, nil
+}
+
+func (c *memoryExploreCache) Set(_ context.Context, key string, report string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = memoryExploreEntry{report: report, expires: now.Add(c.ttl)}
+	return nil
+}
+
+const exploreCacheRedisPrefix = "explore-cache:"
+
+type redisExploreCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisExploreCache returns an ExploreCache shared by every worker using
+// client, which survives restarts. ttl <= 0 uses DefaultExploreCacheTTL.
+func NewRedisExploreCache(client *redis.Client, ttl time.Duration) ExploreCache {
+	if ttl <= 0 {
+		ttl = DefaultExploreCacheTTL
+	}
+	return &redisExploreCache{client: client, ttl: ttl}
+}
+
+func (c *redisExploreCache) Get(ctx context.Context, key string) (string, bool, error) {
+	report, err := c.client.Get(ctx, exploreCacheRedisPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("get explore cache entry: %w", err)
+	}
+	return report, true, nil
+}
+
+func (c *redisExploreCache) Set(ctx context.Context, key string, report string) error {
+	if err := c.client.Set(ctx, exploreCacheRedisPrefix+key, report, c.ttl).Err(); err != nil {
+		return fmt.Errorf("set explore cache entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package brain
+
+import (
+	"fmt"
+	"strings"
+
+	"basegraph.co/relay/common/llm"
+)
+
+// compactedResultPrefix starts every tool result replaced by compaction.
+const compactedResultPrefix = "[compacted]"
+
+// compactedResult records one tool result dropped from the context window.
+type compactedResult struct {
+	callID string
+	tool   string
+	bytes  int
+}
+
+// compactToolResults returns messages with the content of all but the newest
+// keep tool results replaced by a one-line stub. The input slice is not
+// modified, since earlier requests may still reference it. Assistant
+// messages (the model's reasoning and tool calls) are left alone, and results
+// compacted earlier are skipped, so repeated calls are idempotent. The
+// originals stay in the debug log under [TOOL RESULT], keyed by call ID.
+func compactToolResults(messages []llm.Message, keep int) ([]llm.Message, []compactedResult) {
+	var toolIdx []int
+	toolNames := make(map[string]string)
+	for i, m := range messages {
+		switch m.Role {
+		case "assistant":
+			for _, tc := range m.ToolCalls {
+				toolNames[tc.ID] = tc.Name
+			}
+		case "tool":
+			toolIdx = append(toolIdx, i)
+		}
+	}
+	if len(toolIdx) <= keep {
+		return messages, nil
+	}
+
+	out := make([]llm.Message, len(messages))
+	copy(out, messages)
+	var compacted []compactedResult
+	for _, i := range toolIdx[:len(toolIdx)-keep] {
+		m := &out[i]
+		if strings.HasPrefix(m.Content, compactedResultPrefix) {
+			continue
+		}
+		tool := toolNames[m.ToolCallID]
+		if tool == "" {
+			tool = "tool"
+		}
+		compacted = append(compacted, compactedResult{callID: m.ToolCallID, tool: tool, bytes: len(m.Content)})
+		m.Content = fmt.Sprintf("%s %s output (%d bytes, %d lines) removed to save context. Call the tool again if you still need it.",
+			compactedResultPrefix, tool, len(m.Content), strings.Count(m.Content, "\n")+1)
+	}
+	if len(compacted) == 0 {
+		return messages, nil
+	}
+	return out, compacted
+}
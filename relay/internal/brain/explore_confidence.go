@@ -0,0 +1,73 @@
+package brain
+
+import (
+	"strings"
+
+	"basegraph.co/relay/common/llm"
+)
+
+// SubmitConfidenceParams defines the schema for the submit_confidence tool.
+type SubmitConfidenceParams struct {
+	Confidence string `json:"confidence" jsonschema:"required,enum=high,enum=medium,enum=low,description=How confident you are that the report answers the question correctly"`
+	Caveats    string `json:"caveats,omitempty" jsonschema:"description=Caveats, open questions or areas you could not verify"`
+}
+
+func submitConfidenceToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "submit_confidence",
+		Description: `Record your confidence in the report you just wrote.
+
+high: every claim is backed by code you read. medium: the main answer is verified but parts are inferred.
+low: key parts are guesses or you could not find what was asked.`,
+		Parameters: llm.GenerateSchemaFrom(SubmitConfidenceParams{}),
+	}
+}
+
+// confidenceAssessment is the outcome of the self-assessment turn.
+type confidenceAssessment struct {
+	Level  string  // high, medium, low or unknown
+	Score  float64 // Level as 0.0-1.0
+	Source string  // "submit_confidence" or "prose"
+	Text   string  // What goes after "Confidence Assessment:" in the report
+}
+
+// confidenceScores maps confidence levels to the numeric score in metrics.
+var confidenceScores = map[string]float64{
+	"high":   0.9,
+	"medium": 0.6,
+	"low":    0.3,
+}
+
+// assessConfidence reads the self-assessment from a submit_confidence call,
+// falling back to parsing the prose when the model answered in text (or
+// sent an invalid rating).
+func assessConfidence(resp *llm.AgentResponse) confidenceAssessment {
+	for _, tc := range resp.ToolCalls {
+		if tc.Name != "submit_confidence" {
+			continue
+		}
+		params, err := llm.ParseToolArguments[SubmitConfidenceParams](tc.Arguments)
+		if err != nil {
+			break
+		}
+		level := strings.ToLower(strings.TrimSpace(params.Confidence))
+		score, ok := confidenceScores[level]
+		if !ok {
+			break
+		}
+
+		text := level
+		if caveats := strings.TrimSpace(params.Caveats); caveats != "" {
+			text += " — " + caveats
+		}
+		return confidenceAssessment{Level: level, Score: score, Source: "submit_confidence", Text: text}
+	}
+
+	level := extractConfidence(resp.Content)
+	return confidenceAssessment{
+		Level:  level,
+		Score:  confidenceScores[level],
+		Source: "prose",
+		Text:   resp.Content,
+	}
+}
@@ -0,0 +1,170 @@
+package brain
+
+import (
+	"encoding/json"
+	"strings"
+
+	"basegraph.co/relay/common/llm"
+)
+
+const (
+	nearDuplicateWindow     = 5   // Recent single-call turns checked for near-duplicates
+	nearDuplicateSimilarity = 0.8 // Min Jaccard similarity of search patterns to count as a near-duplicate
+	minContainedPattern     = 3   // Shortest pattern that counts as a near-duplicate by containment
+)
+
+// Doom loop matches reported by detectDoomLoop.
+const (
+	doomLoopIdentical     = "identical"
+	doomLoopNearDuplicate = "near-identical"
+)
+
+// toolCallRecord tracks a tool invocation for doom loop detection.
+type toolCallRecord struct {
+	name   string
+	args   string         // Normalized JSON
+	params map[string]any // Parsed args; nil when they aren't a JSON object
+}
+
+func newToolCallRecord(tc llm.ToolCall) toolCallRecord {
+	r := toolCallRecord{name: tc.Name, args: normalizeArgs(tc.Arguments)}
+	_ = json.Unmarshal([]byte(tc.Arguments), &r.params)
+	return r
+}
+
+// detectDoomLoop reports whether the recent single-call turns are going in
+// circles. The fast path is the last doomLoopThreshold calls being
+// identical. Otherwise it looks for doomLoopThreshold calls in the window
+// that are near-duplicates of one of them: the agent rephrasing the same
+// search ("Plan", "Planner", "PlanV2") instead of changing approach.
+// Returns the kind of match, or "" when there is no loop.
+func detectDoomLoop(calls []toolCallRecord) string {
+	if len(calls) >= doomLoopThreshold && allIdentical(calls[len(calls)-doomLoopThreshold:]) {
+		return doomLoopIdentical
+	}
+	for _, anchor := range calls {
+		similar := 0
+		for _, c := range calls {
+			if nearDuplicate(anchor, c) {
+				similar++
+			}
+		}
+		if similar >= doomLoopThreshold {
+			return doomLoopNearDuplicate
+		}
+	}
+	return ""
+}
+
+// fuzzyArgs names, per tool, the free-text arg whose rephrasings count as
+// the same search. Every other arg (paths, qnames, names, numbers) must match
+// exactly: reading a different file or asking about a different symbol is
+// progress even when the strings look alike.
+var fuzzyArgs = map[string]string{
+	"grep":     "pattern",
+	"ast_grep": "pattern",
+}
+
+// nearDuplicate reports whether two calls are the same tool with the same
+// args except for the tool's free-text arg, which only has to be similar.
+func nearDuplicate(a, b toolCallRecord) bool {
+	if a.name != b.name {
+		return false
+	}
+	if a.args == b.args {
+		return true
+	}
+	fuzzy, ok := fuzzyArgs[a.name]
+	if !ok || a.params == nil || b.params == nil {
+		return false
+	}
+	for _, pair := range [][2]map[string]any{{a.params, b.params}, {b.params, a.params}} {
+		for k, v := range pair[0] {
+			if k == fuzzy {
+				continue
+			}
+			if normalizeArgValue(v) != normalizeArgValue(pair[1][k]) {
+				return false
+			}
+		}
+	}
+	x, xok := a.params[fuzzy].(string)
+	y, yok := b.params[fuzzy].(string)
+	return xok && yok && similarText(x, y)
+}
+
+// similarText reports whether two search patterns are rephrasings of each
+// other: one contains the other ("Plan", "Planner"), or their character
+// bigrams are at least nearDuplicateSimilarity alike.
+func similarText(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) >= minContainedPattern && strings.Contains(b, a) {
+		return true
+	}
+	return jaccard(bigrams(a), bigrams(b)) >= nearDuplicateSimilarity
+}
+
+// bigrams returns the character bigrams of s.
+func bigrams(s string) map[string]bool {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return map[string]bool{s: true}
+	}
+	out := make(map[string]bool, len(runes)-1)
+	for i := 0; i+2 <= len(runes); i++ {
+		out[string(runes[i:i+2])] = true
+	}
+	return out
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// normalizeArgs normalizes JSON arguments for comparison.
+func normalizeArgs(args string) string {
+	var v any
+	if err := json.Unmarshal([]byte(args), &v); err != nil {
+		return args
+	}
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return args
+	}
+	return string(normalized)
+}
+
+// normalizeArgValue renders one parsed arg value for comparison.
+func normalizeArgValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// allIdentical checks if all tool calls in the slice are identical.
+func allIdentical(calls []toolCallRecord) bool {
+	if len(calls) == 0 {
+		return false
+	}
+	first := calls[0]
+	for _, c := range calls[1:] {
+		if c.name != first.name || c.args != first.args {
+			return false
+		}
+	}
+	return true
+}
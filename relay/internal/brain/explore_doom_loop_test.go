@@ -0,0 +1,115 @@
+package brain
+
+import (
+	"testing"
+
+	"basegraph.co/relay/common/llm"
+)
+
+func records(calls ...llm.ToolCall) []toolCallRecord {
+	out := make([]toolCallRecord, len(calls))
+	for i, tc := range calls {
+		out[i] = newToolCallRecord(tc)
+	}
+	return out
+}
+
+func TestDetectDoomLoop(t *testing.T) {
+	call := func(name, args string) llm.ToolCall { return llm.ToolCall{Name: name, Arguments: args} }
+
+	tests := []struct {
+		name  string
+		calls []toolCallRecord
+		want  string
+	}{
+		{
+			name: "identical",
+			calls: records(
+				call("grep", `{"pattern":"Plan"}`),
+				call("grep", `{ "pattern": "Plan" }`),
+				call("grep", `{"pattern":"Plan"}`),
+			),
+			want: doomLoopIdentical,
+		},
+		{
+			name: "near-identical patterns",
+			calls: records(
+				call("grep", `{"pattern":"Plan"}`),
+				call("grep", `{"pattern":"Planner"}`),
+				call("grep", `{"pattern":"PlanV2"}`),
+			),
+			want: doomLoopNearDuplicate,
+		},
+		{
+			name: "paging through a file",
+			calls: records(
+				call("read", `{"file_path":"big.go","offset":1,"limit":400}`),
+				call("read", `{"file_path":"big.go","offset":401,"limit":400}`),
+				call("read", `{"file_path":"big.go","offset":801,"limit":400}`),
+			),
+		},
+		{
+			name: "different codegraph operations",
+			calls: records(
+				call("codegraph", `{"operation":"callers","name":"Plan"}`),
+				call("codegraph", `{"operation":"callees","name":"Plan"}`),
+				call("codegraph", `{"operation":"implementations","name":"Plan"}`),
+			),
+		},
+		{
+			name: "numbered globs",
+			calls: records(
+				call("glob", `{"pattern":"*10.go"}`),
+				call("glob", `{"pattern":"*11.go"}`),
+				call("glob", `{"pattern":"*12.go"}`),
+			),
+		},
+		{
+			name: "reading distinct files",
+			calls: records(
+				call("read", `{"file_path":"internal/brain/planner.go"}`),
+				call("read", `{"file_path":"internal/brain/planner_test.go"}`),
+				call("read", `{"file_path":"internal/brain/planners.go"}`),
+			),
+		},
+		{
+			name: "distinct qnames",
+			calls: records(
+				call("codegraph", `{"operation":"callers","qname":"example.com/app/brain.Planner.Plan"}`),
+				call("codegraph", `{"operation":"callers","qname":"example.com/app/brain.Planner.Plans"}`),
+				call("codegraph", `{"operation":"callers","qname":"example.com/app/brain.Planner.Replan"}`),
+			),
+		},
+		{
+			name: "same pattern in distinct paths",
+			calls: records(
+				call("grep", `{"pattern":"Plan","path":"internal/brain"}`),
+				call("grep", `{"pattern":"Planner","path":"internal/http"}`),
+				call("grep", `{"pattern":"PlanV2","path":"internal/queue"}`),
+			),
+		},
+		{
+			name: "unrelated patterns sharing bigrams",
+			calls: records(
+				call("grep", `{"pattern":"handleRequest"}`),
+				call("grep", `{"pattern":"handleResponse"}`),
+				call("grep", `{"pattern":"handleRetry"}`),
+			),
+		},
+		{
+			name: "only two near-duplicates",
+			calls: records(
+				call("grep", `{"pattern":"Plan"}`),
+				call("grep", `{"pattern":"Planner"}`),
+				call("grep", `{"pattern":"webhook"}`),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDoomLoop(tt.calls); got != tt.want {
+				t.Errorf("detectDoomLoop() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"basegraph.co/relay/internal/model"
+	"basegraph.co/relay/internal/store"
+)
+
+// MetricsSink receives the metrics of every finished exploration. Record is
+// called once per session, after the session's debug files are written;
+// errors are logged and otherwise ignored.
+type MetricsSink interface {
+	Record(ctx context.Context, metrics ExploreMetrics) error
+}
+
+// nopMetricsSink drops metrics. It is the ExploreAgent default.
+type nopMetricsSink struct{}
+
+func (nopMetricsSink) Record(context.Context, ExploreMetrics) error { return nil }
+
+type storeMetricsSink struct {
+	store store.ExploreMetricsStore
+}
+
+// NewStoreMetricsSink writes explore metrics to the explore_metrics table.
+func NewStoreMetricsSink(s store.ExploreMetricsStore) MetricsSink {
+	return &storeMetricsSink{store: s}
+}
+
+func (s *storeMetricsSink) Record(ctx context.Context, metrics ExploreMetrics) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("marshaling explore metrics: %w", err)
+	}
+
+	codegraphCalls := 0
+	for _, n := range metrics.CodegraphOps {
+		codegraphCalls += n
+	}
+
+	return s.store.Create(ctx, model.ExploreMetricsRecord{
+		SessionID:              metrics.SessionID,
+		Query:                  metrics.Query,
+		Thoroughness:           metrics.Thoroughness,
+		StartedAt:              metrics.StartTime,
+		DurationMs:             metrics.DurationMs,
+		Iterations:             metrics.Iterations,
+		ContextWindowTokens:    metrics.ContextWindowTokens,
+		CompletionTokens:       metrics.TotalCompletionTokens,
+		CodegraphCalls:         codegraphCalls,
+		CodegraphInvalidKind:   metrics.CodegraphInvalidKind,
+		CodegraphAmbiguous:     metrics.CodegraphAmbiguous,
+		CodegraphTraceFound:    metrics.CodegraphTraceFound,
+		CodegraphTraceNotFound: metrics.CodegraphTraceNotFound,
+		Confidence:             metrics.Confidence,
+		TerminationReason:      metrics.TerminationReason,
+		Metrics:                data,
+	})
+}
@@ -0,0 +1,70 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"basegraph.co/relay/internal/model"
+)
+
+type memExploreMetricsStore struct {
+	records []model.ExploreMetricsRecord
+}
+
+func (s *memExploreMetricsStore) Create(_ context.Context, m model.ExploreMetricsRecord) error {
+	s.records = append(s.records, m)
+	return nil
+}
+
+func TestStoreMetricsSinkRecord(t *testing.T) {
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	metrics := ExploreMetrics{
+		SessionID:              "20261017-120000.000",
+		Query:                  "who calls Enqueue?",
+		Thoroughness:           "medium",
+		StartTime:              start,
+		DurationMs:             1500,
+		Iterations:             4,
+		ContextWindowTokens:    9000,
+		TotalCompletionTokens:  700,
+		CodegraphOps:           map[string]int{"callers": 2, "trace": 3},
+		CodegraphInvalidKind:   1,
+		CodegraphAmbiguous:     2,
+		CodegraphTraceFound:    1,
+		CodegraphTraceNotFound: 2,
+		Confidence:             "medium",
+		TerminationReason:      "natural",
+	}
+
+	s := &memExploreMetricsStore{}
+	if err := NewStoreMetricsSink(s).Record(context.Background(), metrics); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if len(s.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(s.records))
+	}
+
+	r := s.records[0]
+	if r.SessionID != metrics.SessionID || r.Query != metrics.Query || r.Thoroughness != "medium" || !r.StartedAt.Equal(start) {
+		t.Errorf("session fields = %+v", r)
+	}
+	if r.DurationMs != 1500 || r.Iterations != 4 || r.ContextWindowTokens != 9000 || r.CompletionTokens != 700 {
+		t.Errorf("usage fields = %+v", r)
+	}
+	if r.CodegraphCalls != 5 || r.CodegraphInvalidKind != 1 || r.CodegraphAmbiguous != 2 || r.CodegraphTraceFound != 1 || r.CodegraphTraceNotFound != 2 {
+		t.Errorf("codegraph fields = %+v", r)
+	}
+	if r.Confidence != "medium" || r.TerminationReason != "natural" {
+		t.Errorf("outcome fields = %+v", r)
+	}
+
+	var stored ExploreMetrics
+	if err := json.Unmarshal(r.Metrics, &stored); err != nil {
+		t.Fatalf("Metrics is not ExploreMetrics JSON: %v", err)
+	}
+	if stored.CodegraphOps["trace"] != 3 {
+		t.Errorf("stored metrics = %+v", stored)
+	}
+}
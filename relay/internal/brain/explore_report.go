@@ -0,0 +1,153 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"basegraph.co/relay/common/llm"
+	"basegraph.co/relay/common/logger"
+)
+
+// ExploreReport is an explore report in structured form, for callers that
+// consume explorations programmatically instead of handing them to an LLM.
+type ExploreReport struct {
+	Summary        string          `json:"summary"`
+	Topics         []ReportTopic   `json:"topics"`
+	KeyFindings    []string        `json:"key_findings"`
+	FilesReference []FileReference `json:"files_reference"`
+	Confidence     string          `json:"confidence"` // high, medium, low or unknown
+}
+
+// ReportTopic is one numbered section of a report.
+type ReportTopic struct {
+	Title string          `json:"title"`
+	Body  string          `json:"body"` // Markdown, including any code snippets
+	Files []FileReference `json:"files,omitempty"`
+}
+
+// FileReference is a file the report relies on.
+type FileReference struct {
+	Path    string `json:"path"`
+	Lines   string `json:"lines,omitempty"` // e.g. "42-58"
+	Purpose string `json:"purpose"`
+}
+
+// SubmitReportParams defines the schema for the submit_report tool.
+type SubmitReportParams struct {
+	Summary        string          `json:"summary" jsonschema:"required,description=2-3 sentence answer to the question"`
+	Topics         []ReportTopic   `json:"topics" jsonschema:"required,description=The report's sections in order. body is markdown and keeps code snippets with their file:line headers"`
+	KeyFindings    []string        `json:"key_findings,omitempty" jsonschema:"description=Important insights, one per item"`
+	FilesReference []FileReference `json:"files_reference,omitempty" jsonschema:"description=Every file the report cites"`
+	Confidence     string          `json:"confidence" jsonschema:"required,enum=high,enum=medium,enum=low,description=Confidence stated in the report"`
+}
+
+func submitReportToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "submit_report",
+		Description: `Submit the report you just wrote in structured form.
+
+Copy the content over; do not add, drop or reword findings. Each numbered section becomes a topic.`,
+		Parameters: llm.GenerateSchemaFrom(SubmitReportParams{}),
+	}
+}
+
+// ExploreJSON explores like Explore and returns the report as an
+// ExploreReport. The markdown report is converted by one more model turn
+// that may only call submit_report; a model that answers in text instead
+// gets the markdown back as the Summary with unknown confidence.
+func (e *ExploreAgent) ExploreJSON(ctx context.Context, query string, thoroughness Thoroughness) (ExploreReport, error) {
+	markdown, err := e.Explore(ctx, query, thoroughness)
+	if err != nil {
+		return ExploreReport{}, err
+	}
+	return e.structureReport(ctx, query, markdown)
+}
+
+// structureReport asks the model to restate markdown through submit_report.
+func (e *ExploreAgent) structureReport(ctx context.Context, query, markdown string) (ExploreReport, error) {
+	resp, err := e.llm.ChatWithTools(ctx, llm.AgentRequest{
+		Messages: []llm.Message{
+			{Role: "system", Content: "You convert code exploration reports into structured data. Call submit_report exactly once."},
+			{Role: "user", Content: fmt.Sprintf("Question: %s\n\nReport:\n%s", query, markdown)},
+		},
+		Tools: []llm.Tool{submitReportToolDefinition()},
+	})
+	if err != nil {
+		return ExploreReport{}, fmt.Errorf("explore agent structure report: %w", err)
+	}
+
+	if report, ok := parseSubmitReport(resp); ok {
+		return report, nil
+	}
+
+	slog.WarnContext(ctx, "explore report not submitted in structured form, returning markdown as summary",
+		"query", logger.Truncate(query, 100))
+	return ExploreReport{Summary: markdown, Confidence: "unknown"}, nil
+}
+
+// parseSubmitReport reads the report from a submit_report call. It fails
+// when there is none, its arguments don't parse, or it has no summary.
+func parseSubmitReport(resp *llm.AgentResponse) (ExploreReport, bool) {
+	for _, tc := range resp.ToolCalls {
+		if tc.Name != "submit_report" {
+			continue
+		}
+		params, err := llm.ParseToolArguments[SubmitReportParams](tc.Arguments)
+		if err != nil || strings.TrimSpace(params.Summary) == "" {
+			return ExploreReport{}, false
+		}
+
+		confidence := strings.ToLower(strings.TrimSpace(params.Confidence))
+		if _, ok := confidenceScores[confidence]; !ok {
+			confidence = "unknown"
+		}
+		return ExploreReport{
+			Summary:        params.Summary,
+			Topics:         params.Topics,
+			KeyFindings:    params.KeyFindings,
+			FilesReference: params.FilesReference,
+			Confidence:     confidence,
+		}, true
+	}
+	return ExploreReport{}, false
+}
+
+// Markdown renders the report in the layout of the analyze prompt's
+// <report> template.
+func (r ExploreReport) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("<report>\n## Summary\n")
+	sb.WriteString(strings.TrimSpace(r.Summary) + "\n")
+
+	for i, topic := range r.Topics {
+		sb.WriteString(fmt.Sprintf("\n## %d. %s\n\n", i+1, topic.Title))
+		if body := strings.TrimSpace(topic.Body); body != "" {
+			sb.WriteString(body + "\n")
+		}
+		if len(topic.Files) > 0 {
+			sb.WriteString("\n**Key Files:**\n| File | Purpose |\n|------|---------|\n")
+			for _, f := range topic.Files {
+				sb.WriteString(fmt.Sprintf("| %s | %s |\n", f.Path, f.Purpose))
+			}
+		}
+	}
+
+	if len(r.KeyFindings) > 0 {
+		sb.WriteString("\n## Key Findings\n\n")
+		for i, finding := range r.KeyFindings {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, finding))
+		}
+	}
+
+	if len(r.FilesReference) > 0 {
+		sb.WriteString("\n## Files Reference\n\n| File | Lines | Purpose |\n|------|-------|---------|\n")
+		for _, f := range r.FilesReference {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", f.Path, f.Lines, f.Purpose))
+		}
+	}
+
+	sb.WriteString("\n## Confidence\n" + r.Confidence + "\n</report>")
+	return sb.String()
+}
@@ -0,0 +1,28 @@
+package brain
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewExploreSessionIDIsUniqueWithinAMillisecond(t *testing.T) {
+	const runs = 50
+	ids := make([]string, runs)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids[i] = newExploreSessionID()
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, runs)
+	for _, id := range ids {
+		if seen[id] {
+			t.Fatalf("newExploreSessionID() returned %q twice", id)
+		}
+		seen[id] = true
+	}
+}
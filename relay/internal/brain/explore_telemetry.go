@@ -0,0 +1,107 @@
+package brain
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"basegraph.co/relay/common/llm"
+	"basegraph.co/relay/common/logger"
+)
+
+// exploreTrace holds the spans of one exploration: a session span with one
+// child span per loop iteration. Tool calls get their own spans under the
+// iteration that requested them (see executeTool). Like the spec spans, they
+// go through the global tracer provider and are no-ops until one is installed.
+type exploreTrace struct {
+	session   *logger.SpanContext
+	iteration *logger.SpanContext
+}
+
+func startExploreTrace(ctx context.Context, query string, thoroughness Thoroughness, mode ExploreMode) (*exploreTrace, context.Context) {
+	sc := logger.StartSpan(ctx, "brain.explore.session",
+		trace.WithAttributes(
+			attribute.String("explore.thoroughness", string(thoroughness)),
+			attribute.String("explore.mode", string(mode)),
+			attribute.Int("explore.query_length", len(query)),
+		))
+	return &exploreTrace{session: sc}, sc.Context()
+}
+
+// startIteration ends the previous iteration span and starts the next one.
+// The returned context carries the iteration span, so LLM and tool calls made
+// with it nest under the iteration.
+func (t *exploreTrace) startIteration(ctx context.Context, iteration int) context.Context {
+	t.endIteration()
+	t.iteration = logger.StartSpan(ctx, "brain.explore.iteration",
+		trace.WithAttributes(attribute.Int("explore.iteration", iteration)))
+	return t.iteration.Context()
+}
+
+// recordResponse adds the iteration's token usage and tool call count.
+func (t *exploreTrace) recordResponse(resp *llm.AgentResponse) {
+	if t.iteration == nil {
+		return
+	}
+	t.iteration.Span().SetAttributes(
+		attribute.Int("llm.prompt_tokens", resp.PromptTokens),
+		attribute.Int("llm.completion_tokens", resp.CompletionTokens),
+		attribute.Int("llm.tool_calls", len(resp.ToolCalls)),
+	)
+}
+
+func (t *exploreTrace) endIteration() {
+	if t.iteration == nil {
+		return
+	}
+	t.iteration.End()
+	t.iteration = nil
+}
+
+// finish closes the open iteration and the session span with the session's
+// metrics. Call it exactly once, on every return path.
+func (t *exploreTrace) finish(metrics ExploreMetrics, err error) {
+	t.endIteration()
+
+	span := t.session.Span()
+	span.SetAttributes(
+		attribute.Int("explore.iterations", metrics.Iterations),
+		attribute.Int("llm.context_window_tokens", metrics.ContextWindowTokens),
+		attribute.Int("llm.completion_tokens", metrics.TotalCompletionTokens),
+		attribute.String("explore.confidence", metrics.Confidence),
+		attribute.String("explore.termination_reason", metrics.TerminationReason),
+		attribute.Bool("explore.cache_hit", metrics.CacheHits > 0),
+	)
+	if err != nil {
+		t.session.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	t.session.End()
+}
+
+// startToolSpan starts the span for one tool execution.
+func startToolSpan(ctx context.Context, call llm.ToolCall) *logger.SpanContext {
+	return logger.StartSpan(ctx, "brain.explore.tool",
+		trace.WithAttributes(
+			attribute.String("tool.name", call.Name),
+			attribute.String("tool.call_id", call.ID),
+			attribute.Int("tool.args_bytes", len(call.Arguments)),
+		))
+}
+
+// endToolSpan records the tool's outcome and ends its span.
+func endToolSpan(sc *logger.SpanContext, res toolResult) {
+	span := sc.Span()
+	span.SetAttributes(
+		attribute.Int("tool.result_bytes", len(res.result)),
+		attribute.Bool("tool.truncated", res.truncated),
+		attribute.Bool("tool.failed", res.failed),
+		attribute.Int64("tool.duration_ms", res.duration.Milliseconds()),
+	)
+	if res.failed {
+		span.SetStatus(codes.Error, "tool call failed")
+	}
+	sc.End()
+}
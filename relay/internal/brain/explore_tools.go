@@ -5,40 +5,55 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"basegraph.co/relay/common/arangodb"
 	"basegraph.co/relay/common/llm"
+	"github.com/invopop/jsonschema"
 )
 
 const (
-	bashTimeout      = 10    // Bash command timeout in seconds
 	maxBashOutput    = 10000 // Max bash output bytes (10KB)
+	maxBashLines     = 200   // Default max bash output lines, applied before the byte cap
 	maxGlobResults   = 100   // Max files returned by glob
 	maxGrepMatches   = 50    // Max matches returned by grep
 	maxReadLines     = 500   // Max lines returned by read
 	defaultReadLines = 200   // Default lines if not specified
 	maxLineLength    = 2000  // Truncate lines longer than this
 
+	defaultMaxReadFileBytes = 2 << 20 // Files above this are only read near the top, see executeRead
+
 	// Codegraph constants
 	maxSearchResults  = 10 // Max symbols returned by search
 	defaultGraphDepth = 1  // Default traversal depth
 	maxGraphDepth     = 3  // Max traversal depth for callers/callees
+
+	defaultCallResults = 30  // Default callers/callees per page
+	maxCallResults     = 100 // Max callers/callees per page
+
+	defaultWildcardMaxCandidates = 25 // Wildcard retry matches above this are "too broad"
 )
 
 // Tool parameter structs - Claude Code style
 
 // GlobParams for file pattern matching.
 type GlobParams struct {
-	Pattern string `json:"pattern" jsonschema:"required,description=Glob pattern to match files (e.g. '**/*.go', 'internal/**/*.ts')"`
-	Path    string `json:"path,omitempty" jsonschema:"description=Directory to search in. Defaults to repo root."`
+	Pattern        string   `json:"pattern" jsonschema:"required,description=Glob pattern to match files (e.g. '**/*.go', 'internal/**/*.ts')"`
+	Path           string   `json:"path,omitempty" jsonschema:"description=Directory to search in. Defaults to repo root."`
+	Exclude        []string `json:"exclude,omitempty" jsonschema:"description=Extra patterns to leave out, matched against each path segment and the relative path (e.g. 'testdata', '*.pb.go')"`
+	FollowSymlinks bool     `json:"follow_symlinks,omitempty" jsonschema:"description=Descend into symlinked directories (default false). Targets outside the repo are never returned."`
 }
 
 // GrepParams for content search.
@@ -47,14 +62,19 @@ type GrepParams struct {
 	Path       string `json:"path,omitempty" jsonschema:"description=File or directory to search. Defaults to repo root."`
 	Glob       string `json:"glob,omitempty" jsonschema:"description=Filter files by glob pattern (e.g. '*.go', '*.ts')"`
 	IgnoreCase bool   `json:"ignore_case,omitempty" jsonschema:"description=Case insensitive search"`
-	Context    int    `json:"context,omitempty" jsonschema:"description=Lines of context around matches (default 0)"`
+	Literal    bool   `json:"literal,omitempty" jsonschema:"description=Treat pattern as a literal string instead of a regex (useful for error messages with brackets or dots)"`
+	Context    int    `json:"context,omitempty" jsonschema:"description=Lines of context around matches (default 0). Context lines do not count toward the match limit."`
+	Offset     int    `json:"offset,omitempty" jsonschema:"description=Skip this many matches, to fetch the next page of a truncated result (default 0)"`
+	FilesOnly  bool   `json:"files_only,omitempty" jsonschema:"description=Return only the paths of files that contain a match, one per line, instead of the matching lines. Context is ignored; offset skips files."`
 }
 
 // ReadParams for reading files.
 type ReadParams struct {
-	FilePath string `json:"file_path" jsonschema:"required,description=Path to the file to read (relative to repo root)"`
+	FilePath string `json:"file_path,omitempty" jsonschema:"description=Path to the file to read (relative to repo root). Required unless qname is set."`
 	Offset   int    `json:"offset,omitempty" jsonschema:"description=Line number to start reading from (1-indexed)"`
 	Limit    int    `json:"limit,omitempty" jsonschema:"description=Number of lines to read (default 200, max 500)"`
+	EndLine  int    `json:"end_line,omitempty" jsonschema:"description=Last line to read, inclusive. Overrides limit (still capped at 500 lines)."`
+	QName    string `json:"qname,omitempty" jsonschema:"description=Read exactly this symbol's source, from its first to last line as recorded in the codegraph. Replaces file_path/offset/end_line."`
 }
 
 // BashParams for shell commands.
@@ -64,16 +84,32 @@ type BashParams struct {
 
 // CodegraphParams for querying code relationships.
 type CodegraphParams struct {
-	Operation string `json:"operation" jsonschema:"required,enum=search,enum=resolve,enum=file_symbols,enum=callers,enum=callees,enum=implementations,enum=usages,enum=trace,description=Codegraph operation"`
+	Operation string `json:"operation" jsonschema:"required,enum=search,enum=resolve,enum=file_symbols,enum=callers,enum=callees,enum=implementations,enum=usages,enum=neighborhood,enum=trace,enum=siblings,enum=entrypoints,enum=config_usages,enum=hierarchy,enum=describe,enum=dependents,description=Codegraph operation"`
 
 	// Symbol selector (used by search/resolve, and as a convenience for relationship ops when qname is unknown)
-	Name string `json:"name,omitempty" jsonschema:"description=Symbol name or glob pattern (e.g. 'Plan', 'Handler*')."`
-	Kind string `json:"kind,omitempty" jsonschema:"enum=function,enum=method,enum=struct,enum=interface,enum=class,description=Optional kind filter. Supported kinds: function, method, struct, interface, class."`
-	File string `json:"file,omitempty" jsonschema:"description=Optional file filter (suffix match, e.g. 'planner.go' or 'internal/brain/planner.go'). Required for file_symbols."`
+	Name  string   `json:"name,omitempty" jsonschema:"description=Symbol name or glob pattern (e.g. 'Plan', 'Handler*')."`
+	Names []string `json:"names,omitempty" jsonschema:"description=resolve: several names to resolve in one call (max 20), instead of name. kind/file/exact/case_insensitive apply to all of them."`
+	Kind  string   `json:"kind,omitempty" jsonschema:"description=Optional kind filter"` // enum and supported kinds set by codegraphParamsSchema
+	File  string   `json:"file,omitempty" jsonschema:"description=Optional file filter (suffix match, e.g. 'planner.go' or 'internal/brain/planner.go'). Required for file_symbols."`
+
+	// ContextFile breaks ties between same-named symbols
+	ContextFile string `json:"context_file,omitempty" jsonschema:"description=A file you have been reading. When name matches several symbols, the one in this file wins, else the only one in its directory (package). Still lists candidates if that doesn't settle it."`
+
+	// Name matching for search/resolve
+	Exact           bool  `json:"exact,omitempty" jsonschema:"description=search/resolve: match name as the full symbol name literally - no glob and no *name* retry (default false)."`
+	CaseInsensitive *bool `json:"case_insensitive,omitempty" jsonschema:"description=search/resolve: ignore case when matching name (default true)."`
+
+	// Output detail for search/resolve/file_symbols
+	IncludeDoc bool `json:"include_doc,omitempty" jsonschema:"description=search/resolve/file_symbols: append each symbol's doc comment (first sentence, truncated) to its line (default false)."`
+	Outline    bool `json:"outline,omitempty" jsonschema:"description=file_symbols: nest methods under their type and list standalone functions separately (default false)."`
 
 	// Relationship operations
-	QName string `json:"qname,omitempty" jsonschema:"description=Fully qualified symbol name (qname). If set, used directly."`
-	Depth int    `json:"depth,omitempty" jsonschema:"description=Traversal depth for callers/callees (1-3, default 1)"`
+	QName  string `json:"qname,omitempty" jsonschema:"description=Fully qualified symbol name (qname). If set, used directly."`
+	Depth  int    `json:"depth,omitempty" jsonschema:"description=Traversal depth for callers/callees (1-3, default 1)"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"description=Max callers/callees to return (1-100, default 30)"`
+	Offset int    `json:"offset,omitempty" jsonschema:"description=Skip this many callers/callees, to page past a truncated result (default 0)"`
+
+	UsageKind string `json:"usage_kind,omitempty" jsonschema:"enum=any,enum=param,enum=return,enum=field,description=usages: only functions taking the type as a parameter (param), returning it (return), or struct fields of the type (field). Default any."`
 
 	// Trace operation (call path)
 	FromName  string `json:"from_name,omitempty" jsonschema:"description=Trace start symbol name (alternative to from_qname)."`
@@ -87,22 +123,216 @@ type CodegraphParams struct {
 	ToFile  string `json:"to_file,omitempty" jsonschema:"description=Optional file filter for resolving to_name."`
 
 	MaxDepth int `json:"max_depth,omitempty" jsonschema:"description=Max call depth for trace (1-10, default 4)"`
+	MaxPaths int `json:"max_paths,omitempty" jsonschema:"description=Number of distinct shortest paths for trace to return (1-5, default 1)"`
+
+	IncludeAsync bool `json:"include_async,omitempty" jsonschema:"description=Let trace cross async boundaries (queue enqueue -> worker dequeue). Each crossing counts as 2 steps toward max_depth."`
+}
+
+// nameMatch controls how search and resolve match a symbol name.
+type nameMatch struct {
+	exact           bool // Full name, literally; disables the *name* retry
+	caseInsensitive bool
+}
+
+// defaultNameMatch is a case-insensitive glob; resolve retries *name* when
+// the name alone finds nothing.
+var defaultNameMatch = nameMatch{caseInsensitive: true}
+
+// nameMatch returns the matching requested by exact/case_insensitive.
+func (p CodegraphParams) nameMatch() nameMatch {
+	m := defaultNameMatch
+	m.exact = p.Exact
+	if p.CaseInsensitive != nil {
+		m.caseInsensitive = *p.CaseInsensitive
+	}
+	return m
 }
 
 // ExploreTools provides Claude Code-style tools for the ExploreAgent.
 type ExploreTools struct {
-	repoRoot    string
-	arango      arangodb.Client // nil = codegraph unavailable
-	definitions []llm.Tool
+	repoRoot     string
+	arango       arangodb.Client // nil = codegraph unavailable
+	definitions  []llm.Tool
+	bashMaxLines int
+	gitDisabled  bool
+	bashAllowed  []string     // Allowed bash prefixes, see ExploreToolsConfig
+	bashBlocked  []string     // Blocked bash prefixes, see ExploreToolsConfig
+	timeouts     ToolTimeouts // Per-tool time limits, see ExploreToolsConfig
+	maxFileBytes int64        // read scans at most this far into a file, see ExploreToolsConfig
+
+	// Kinds codegraph accepts and shows, in display order, and as a set.
+	// See ExploreToolsConfig.CodegraphKinds.
+	codegraphKinds   []string
+	codegraphKindSet map[string]bool
+
+	// wildcardMaxCandidates caps how many symbols the *name* retry in
+	// resolveSymbol may match before it's reported as too broad.
+	wildcardMaxCandidates int
+
+	truncationMarker   string
+	redactForeignPaths bool             // Mask absolute paths outside the repo, see WithPathRedaction
+	secretPatterns     []*regexp.Regexp // Secrets masked in every output, see ExploreToolsConfig.SecretPatterns
+	rawQueryEnabled    bool             // Expose the raw_query tool, see WithRawQuery
+
+	// Search binaries found on PATH; grep and glob fall back to grep and
+	// find without them. See detectSearchBinaries.
+	hasRipgrep bool
+	hasGrep    bool
+	hasFd      bool
+
+	indexBuilt atomic.Bool // Set once the codegraph has nodes; see codegraphIndexMessage
+
+	notesMu sync.Mutex
+	notes   map[string][]Note // write_note scratchpad, keyed by explore session
+}
+
+// ToolResult is a tool call's output plus whether it was cut at a result cap.
+// Truncated lets callers detect partial results without parsing the footer.
+type ToolResult struct {
+	Output    string
+	Truncated bool
+	Duration  time.Duration // Wall time of the call (for codegraph, dominated by Arango)
+}
+
+// defaultTruncationMarker starts every truncation footer, ahead of the
+// human-readable hint on how to narrow the query.
+const defaultTruncationMarker = "[truncated]"
+
+// truncationKey carries the per-call truncation flag through the context so
+// parallel Execute calls on one ExploreTools don't share state.
+type truncationKey struct{}
+
+// ExploreToolsConfig holds operator settings for ExploreTools. Zero values
+// keep the built-in defaults.
+type ExploreToolsConfig struct {
+	// AllowedBashPrefixes replaces the read-only commands the bash tool
+	// accepts (git log, ls, cat, find, ...), e.g. to add "go list".
+	AllowedBashPrefixes []string
+	// BlockedBashPrefixes replaces the commands the bash tool always rejects
+	// (rm, git push, sed, ...), e.g. to add "find " for performance.
+	BlockedBashPrefixes []string
+	// Timeouts bounds each tool; zero fields keep the defaults.
+	Timeouts ToolTimeouts
+	// MaxReadFileBytes bounds how far read scans into a file to reach the
+	// requested offset. Lines beyond it in huge (usually generated) files are
+	// refused with a pointer to grep instead. Default 2 MiB.
+	MaxReadFileBytes int64
+	// CodegraphKinds replaces the symbol kinds codegraph accepts as a kind
+	// filter and shows in results (function, method, struct, interface,
+	// class), e.g. to add "alias" or "variable".
+	CodegraphKinds []string
+	// RepoID scopes codegraph to the repo being explored when the graph
+	// database holds several repos (see arangodb.Config.RepoID). Empty uses
+	// the client as given. It is fixed for the tools' lifetime, so one
+	// orchestrator explores one repo's subgraph; it must match the id the
+	// repo was ingested with.
+	RepoID string
+	// SecretPatterns replaces the patterns of secrets masked in tool output
+	// (DefaultSecretPatterns: AWS keys, JWTs, tokens, KEY=value lines).
+	// A group named "secret" masks only that part of a match.
+	SecretPatterns []*regexp.Regexp
+}
+
+// ToolTimeouts bounds how long each explore tool may run. Tools without a
+// field of their own (find_error, symbol_diff, changed_files, git_history,
+// ast_grep) use Bash; entity_map's key search uses Grep.
+type ToolTimeouts struct {
+	Glob      time.Duration // fd/find. Default 5s
+	Grep      time.Duration // rg/grep. Default 10s
+	Read      time.Duration // Default 10s
+	Bash      time.Duration // Default 10s
+	Codegraph time.Duration // Whole codegraph call, resolution included. Default 30s
+}
+
+// defaultToolTimeouts are the limits used for zero ToolTimeouts fields.
+var defaultToolTimeouts = ToolTimeouts{
+	Glob:      5 * time.Second,
+	Grep:      10 * time.Second,
+	Read:      10 * time.Second,
+	Bash:      10 * time.Second,
+	Codegraph: 30 * time.Second,
+}
+
+// withDefaults fills zero fields from defaultToolTimeouts.
+func (tt ToolTimeouts) withDefaults() ToolTimeouts {
+	pick := func(v, def time.Duration) time.Duration {
+		if v > 0 {
+			return v
+		}
+		return def
+	}
+	return ToolTimeouts{
+		Glob:      pick(tt.Glob, defaultToolTimeouts.Glob),
+		Grep:      pick(tt.Grep, defaultToolTimeouts.Grep),
+		Read:      pick(tt.Read, defaultToolTimeouts.Read),
+		Bash:      pick(tt.Bash, defaultToolTimeouts.Bash),
+		Codegraph: pick(tt.Codegraph, defaultToolTimeouts.Codegraph),
+	}
+}
+
+// ParseExploreToolsConfig parses comma-separated bash prefix lists and a
+// timeout in seconds from their string settings. Empty strings keep the
+// defaults. The timeout applies to bash and grep, which it has always
+// covered.
+func ParseExploreToolsConfig(allowed, blocked, timeoutSeconds string) (ExploreToolsConfig, error) {
+	cfg := ExploreToolsConfig{
+		AllowedBashPrefixes: splitBashPrefixes(allowed),
+		BlockedBashPrefixes: splitBashPrefixes(blocked),
+	}
+	if timeoutSeconds != "" {
+		n, err := strconv.Atoi(timeoutSeconds)
+		if err != nil {
+			return ExploreToolsConfig{}, fmt.Errorf("parse bash timeout %q: %w", timeoutSeconds, err)
+		}
+		cfg.Timeouts.Bash = time.Duration(n) * time.Second
+		cfg.Timeouts.Grep = cfg.Timeouts.Bash
+	}
+	return cfg, nil
+}
+
+func splitBashPrefixes(list string) []string {
+	var prefixes []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes
 }
 
 // NewExploreTools creates tools for code exploration (Claude Code style).
 // arango can be nil - codegraph tool will gracefully degrade.
-func NewExploreTools(repoRoot string, arango arangodb.Client) *ExploreTools {
+func NewExploreTools(repoRoot string, arango arangodb.Client, cfg ExploreToolsConfig) *ExploreTools {
+	if arango != nil && cfg.RepoID != "" {
+		arango = arango.ForRepo(cfg.RepoID)
+	}
 	t := &ExploreTools{
-		repoRoot: repoRoot,
-		arango:   arango,
+		repoRoot:     repoRoot,
+		arango:       arango,
+		bashMaxLines: maxBashLines,
+		bashAllowed:  bashAllowedPrefixes,
+		bashBlocked:  bashBlockedPrefixes,
+		timeouts:     cfg.Timeouts.withDefaults(),
+		maxFileBytes: defaultMaxReadFileBytes,
+
+		wildcardMaxCandidates: defaultWildcardMaxCandidates,
+		truncationMarker:      defaultTruncationMarker,
+	}
+	if len(cfg.AllowedBashPrefixes) > 0 {
+		t.bashAllowed = cfg.AllowedBashPrefixes
+	}
+	if len(cfg.BlockedBashPrefixes) > 0 {
+		t.bashBlocked = cfg.BlockedBashPrefixes
 	}
+	if cfg.MaxReadFileBytes > 0 {
+		t.maxFileBytes = cfg.MaxReadFileBytes
+	}
+	t.secretPatterns = cfg.SecretPatterns
+	if t.secretPatterns == nil {
+		t.secretPatterns = DefaultSecretPatterns()
+	}
+	t.setCodegraphKinds(cfg.CodegraphKinds)
+	t.detectSearchBinaries()
 
 	t.definitions = []llm.Tool{
 		{
@@ -113,6 +343,7 @@ Examples:
   glob(pattern="**/*.go")                    # All Go files
   glob(pattern="internal/**/*.go")           # Go files in internal/
   glob(pattern="*_test.go", path="pkg/")     # Test files in pkg/
+  glob(pattern="*.go", exclude=["testdata", "*.pb.go"])  # Skip fixtures and generated code
 
 Use this to discover files before reading them.`,
 			Parameters: llm.GenerateSchemaFrom(GlobParams{}),
@@ -125,6 +356,9 @@ Examples:
   grep(pattern="func.*Plan")                      # Find Plan functions
   grep(pattern="TODO|FIXME", glob="*.go")         # TODOs in Go files
   grep(pattern="error", path="internal/", context=2)  # Errors with context
+  grep(pattern="map[string]any{", literal=true)   # Literal string, no regex escaping
+  grep(pattern="ctx", glob="*.go", offset=50)     # Next page of a truncated result
+  grep(pattern="NewClient", files_only=true)      # Just the files, then read the ones you need
 
 Use this to find where patterns occur in code.`,
 			Parameters: llm.GenerateSchemaFrom(GrepParams{}),
@@ -136,6 +370,8 @@ Use this to find where patterns occur in code.`,
 Examples:
   read(file_path="internal/brain/planner.go")              # First 200 lines
   read(file_path="pkg/api/handler.go", offset=50, limit=100)  # Lines 50-149
+  read(file_path="pkg/api/handler.go", offset=42, end_line=97)  # Lines 42-97
+  read(qname="github.com/acme/app/pkg/api.Handler.ServeHTTP")  # Exactly that method (needs codegraph)
 
 Use this after glob/grep to examine specific code.`,
 			Parameters: llm.GenerateSchemaFrom(ReadParams{}),
@@ -155,7 +391,7 @@ NOT allowed: rm, mv, cp, echo, write operations.`,
 		},
 		{
 			Name: "codegraph",
-			Description: `Query code structure graph for relationships and call flow. SUPPORTED: Go (.go) only.
+			Description: `Query code structure graph for relationships and call flow. SUPPORTED: Go (.go), TypeScript (.ts, .tsx) and Python (.py).
 
 QNAME FORMAT (qualified name):
 A qname is the globally unique identifier: module/path/to/package.Type.Method
@@ -163,26 +399,39 @@ A qname is the globally unique identifier: module/path/to/package.Type.Method
   Method:    github.com/acme/app/internal/store.UserRepo.Save
   Struct:    github.com/acme/app/internal/model.User
   Interface: github.com/acme/app/internal/store.Repository
+Python qnames are the dotted import path: acme.billing.invoice.Invoice.total (classes are kind=class; ABCs and Protocols are kind=interface)
 
 name vs qname:
   name="Save"                    — short name, may match multiple symbols
   qname="...store.UserRepo.Save" — exact match, globally unique
 
-SUPPORTED KINDS (strict): function, method, struct, interface, class.
+NAME MATCHING (search/resolve): name is a glob over the full name, case-insensitive by default; resolve retries *name* if nothing matches.
+  exact=true             — full name only, literally (no glob, no *name* retry)
+  case_insensitive=false — "issue" no longer matches "Issue"
+
+AMBIGUOUS NAMES: context_file="internal/store/user.go" (a file you have read) picks the match in that file, else the only one in its package directory.
+  codegraph(operation="callers", name="Save", context_file="internal/store/user.go")
+
+DOC COMMENTS (search/resolve/file_symbols): include_doc=true appends each symbol's doc comment (truncated) after "//". Off by default to save tokens; use it instead of reading files just for a one-line description.
+
+SUPPORTED KINDS (strict): ` + t.codegraphKindList() + `.
 
 OPERATIONS:
 
-- resolve: Convert name → qname (or show candidates if ambiguous)
+- resolve: Convert name → qname (or show candidates if ambiguous). names=[...] resolves up to 20 names in one call, one line each
   codegraph(operation="resolve", name="ActionExecutor", kind="interface")
+  codegraph(operation="resolve", names=["Planner", "ActionExecutor", "IssueStore"])
 
 - search: List matching symbols by name/glob
   codegraph(operation="search", name="Plan", kind="method")
 
-- file_symbols: List symbols defined in a file
+- file_symbols: List symbols defined in a file. outline=true nests methods under their type
   codegraph(operation="file_symbols", file="internal/brain/planner.go")
+  codegraph(operation="file_symbols", file="internal/brain/planner.go", outline=true)
 
-- callers: Find callers of a function/method
+- callers: Find callers of a function/method. Paged: limit (default 30, max 100) and offset
   codegraph(operation="callers", name="Plan", kind="method", depth=2)
+  codegraph(operation="callers", name="Plan", kind="method", offset=30)
 
 - callees: Find callees from a function/method (use qname if you have it)
   codegraph(operation="callees", qname="github.com/acme/app/store.UserRepo.Save", depth=2)
@@ -190,11 +439,36 @@ OPERATIONS:
 - implementations: Find types that implement an interface
   codegraph(operation="implementations", name="IssueStore", kind="interface")
 
-- usages: Find functions/methods that use a type (param/return)
+- usages: Find functions/methods that use a type (param/return) and struct fields of it. Each result is labelled param, return or field; usage_kind narrows to one
   codegraph(operation="usages", name="Issue", kind="struct")
+  codegraph(operation="usages", name="Issue", kind="struct", usage_kind="param")
+
+- neighborhood: Everything directly connected to a symbol in one call - callers, callees, implements, implemented_by, used_by (up to 10 each, with counts)
+  codegraph(operation="neighborhood", qname="github.com/acme/app/internal/store.UserRepo")
+
+- siblings: Resolve a symbol and list the other symbols in its file (resolve + file_symbols in one call)
+  codegraph(operation="siblings", name="Plan", kind="method")
+
+- entrypoints: List main and init functions plus HTTP route registrations (routes are a grep heuristic)
+  codegraph(operation="entrypoints")
 
-- trace: Find a DIRECT call path between two functions/methods.
-  codegraph(operation="trace", from_name="HandleWebhook", to_name="Plan", to_kind="method", max_depth=6)
+- config_usages: Where a config/env key is defined and read (struct fields, tags, .env/yaml, readers of the config struct)
+  codegraph(operation="config_usages", name="REPO_ROOT")
+  codegraph(operation="config_usages", name="cfg.Pipeline.RedisStream")
+
+- hierarchy: Type tree of a struct/interface - types it embeds, types embedding it, implementers (depth 1-4, default 2)
+  codegraph(operation="hierarchy", name="Reader", kind="interface")
+
+- describe: Orientation summary of a directory - package doc, file doc comments, exported types/functions/methods (file = directory)
+  codegraph(operation="describe", file="internal/brain")
+
+- dependents: Files that import a package (blast radius of a change). name = package import path, or file = a .go file in the package
+  codegraph(operation="dependents", name="basegraph.co/relay/common/arangodb")
+
+- trace: Find a DIRECT call path between two functions/methods. Follows call edges only (never param/return usage).
+  max_paths (1-5) returns that many distinct shortest paths. With no path, shows how far the closest partial path got.
+  codegraph(operation="trace", from_name="HandleWebhook", to_name="Plan", to_kind="method", max_depth=6, max_paths=3)
+  include_async=true also crosses known queues (enqueue site -> consumer), marked as an async boundary in the path.
 
 COMMON MISTAKES:
 - codegraph(operation="resolve", qname="X") — WRONG. Use name="X". resolve converts name→qname.
@@ -202,10 +476,69 @@ COMMON MISTAKES:
 - codegraph(operation="callers", to_qname="X") — WRONG. to_qname is only for trace.
 
 For text search or unsupported languages, use grep/read instead.`,
-			Parameters: llm.GenerateSchemaFrom(CodegraphParams{}),
+			Parameters: t.codegraphParamsSchema(),
 		},
+		findErrorToolDefinition(),
+		entityMapToolDefinition(),
+		symbolDiffToolDefinition(),
+		changedFilesToolDefinition(),
+		gitHistoryToolDefinition(),
+		astGrepToolDefinition(),
+		writeNoteToolDefinition(),
+		readNotesToolDefinition(),
+	}
+	for i := range t.definitions {
+		t.definitions[i].Description += t.searchModeNote(t.definitions[i].Name)
+	}
+
+	return t
+}
+
+// WithBashLineLimit overrides how many lines of bash output are returned.
+// Values <= 0 disable the line cap (the byte cap still applies).
+func (t *ExploreTools) WithBashLineLimit(n int) *ExploreTools {
+	t.bashMaxLines = n
+	return t
+}
+
+// WithWildcardMaxCandidates sets how many symbols a wildcard name retry may
+// match before resolution gives up with a "too broad" error instead of
+// listing candidates. Values <= 0 use the default.
+func (t *ExploreTools) WithWildcardMaxCandidates(n int) *ExploreTools {
+	if n <= 0 {
+		n = defaultWildcardMaxCandidates
+	}
+	t.wildcardMaxCandidates = n
+	return t
+}
+
+// WithGitDisabled turns off git in the bash tool and drops symbol_diff,
+// changed_files and git_history, for checkouts without .git or deployments
+// that forbid history access. Git is enabled by default.
+func (t *ExploreTools) WithGitDisabled(disabled bool) *ExploreTools {
+	t.gitDisabled = disabled
+	if disabled {
+		defs := t.definitions[:0]
+		for _, def := range t.definitions {
+			switch def.Name {
+			case "bash":
+				def.Description = bashNoGitDescription
+			case "symbol_diff", "changed_files", "git_history":
+				continue
+			}
+			defs = append(defs, def)
+		}
+		t.definitions = defs
 	}
+	return t
+}
 
+// WithTruncationMarker overrides the sentinel that starts truncation footers.
+// An empty marker keeps the default.
+func (t *ExploreTools) WithTruncationMarker(marker string) *ExploreTools {
+	if marker != "" {
+		t.truncationMarker = marker
+	}
 	return t
 }
 
@@ -216,6 +549,35 @@ func (t *ExploreTools) Definitions() []llm.Tool {
 
 // Execute runs a tool by name and returns output.
 func (t *ExploreTools) Execute(ctx context.Context, name, arguments string) (string, error) {
+	res, err := t.ExecuteResult(ctx, name, arguments)
+	return res.Output, err
+}
+
+// ExecuteResult runs a tool by name and reports whether its output was truncated.
+// Output and errors pass through redactOutput and redactPaths so host paths
+// don't reach the LLM, and output through redactSecrets so committed
+// credentials don't either.
+func (t *ExploreTools) ExecuteResult(ctx context.Context, name, arguments string) (ToolResult, error) {
+	var truncated bool
+	start := time.Now()
+	output, err := t.execute(context.WithValue(ctx, truncationKey{}, &truncated), name, arguments)
+	return ToolResult{
+		Output:    t.redactSecrets(t.redactOutput(name, output)),
+		Truncated: truncated,
+		Duration:  time.Since(start),
+	}, t.redactError(err)
+}
+
+// truncationFooter flags the current call as truncated and returns the footer
+// line: the truncation marker followed by the human-readable hint.
+func (t *ExploreTools) truncationFooter(ctx context.Context, hint string) string {
+	if flag, ok := ctx.Value(truncationKey{}).(*bool); ok {
+		*flag = true
+	}
+	return t.truncationMarker + " " + hint
+}
+
+func (t *ExploreTools) execute(ctx context.Context, name, arguments string) (string, error) {
 	switch name {
 	case "glob":
 		return t.executeGlob(ctx, arguments)
@@ -227,6 +589,24 @@ func (t *ExploreTools) Execute(ctx context.Context, name, arguments string) (str
 		return t.executeBash(ctx, arguments)
 	case "codegraph":
 		return t.executeCodegraph(ctx, arguments)
+	case "find_error":
+		return t.executeFindError(ctx, arguments)
+	case "entity_map":
+		return t.executeEntityMap(ctx, arguments)
+	case "symbol_diff":
+		return t.executeSymbolDiff(ctx, arguments)
+	case "changed_files":
+		return t.executeChangedFiles(ctx, arguments)
+	case "git_history":
+		return t.executeGitHistory(ctx, arguments)
+	case "ast_grep":
+		return t.executeAstGrep(ctx, arguments)
+	case "write_note":
+		return t.executeWriteNote(ctx, arguments)
+	case "read_notes":
+		return t.executeReadNotes(ctx, arguments)
+	case "raw_query":
+		return t.executeRawQuery(ctx, arguments)
 	default:
 		return "", fmt.Errorf("unknown tool: %s", name)
 	}
@@ -248,10 +628,17 @@ func (t *ExploreTools) executeGlob(ctx context.Context, arguments string) (strin
 		searchPath = filepath.Join(t.repoRoot, params.Path)
 	}
 
-	// Validate path is within repo
+	// Validate path is within repo, including where a symlinked path points
 	if !pathWithinRoot(t.repoRoot, searchPath) {
 		return "Error: path outside repository", nil
 	}
+	realRoot, err := filepath.EvalSymlinks(t.repoRoot)
+	if err != nil {
+		realRoot = t.repoRoot
+	}
+	if realSearch, err := filepath.EvalSymlinks(searchPath); err == nil && !pathWithinRoot(realRoot, realSearch) {
+		return "Error: path outside repository", nil
+	}
 
 	// Use fd for fast glob matching (falls back to find if fd not available)
 	var matches []fileMatch
@@ -265,18 +652,34 @@ func (t *ExploreTools) executeGlob(ctx context.Context, arguments string) (strin
 		"--exclude", "node_modules",
 		"--exclude", "vendor",
 		"--exclude", "__pycache__",
-		"--glob", params.Pattern,
 	}
+	for _, ex := range params.Exclude {
+		args = append(args, "--exclude", ex)
+	}
+	if params.FollowSymlinks {
+		args = append(args, "--follow")
+	}
+	args = append(args, "--glob", params.Pattern)
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Glob)
 	defer cancel()
 
-	cmd := exec.CommandContext(timeoutCtx, "fd", args...)
-	cmd.Dir = searchPath
-	output, err := cmd.Output()
+	var output []byte
+	err = errors.New("fd not installed")
+	if t.hasFd {
+		cmd := exec.CommandContext(timeoutCtx, "fd", args...)
+		cmd.Dir = searchPath
+		output, err = cmd.Output()
+	}
 	if err != nil {
-		// Fall back to find command
+		// Fall back to find command. -P (never follow symlinks) is find's
+		// default, spelled out since following them could leave the repo.
+		symlinks := "-P"
+		if params.FollowSymlinks {
+			symlinks = "-L"
+		}
 		findArgs := []string{
+			symlinks,
 			searchPath,
 			"-type", "f",
 			"-name", params.Pattern,
@@ -284,7 +687,7 @@ func (t *ExploreTools) executeGlob(ctx context.Context, arguments string) (strin
 			"-not", "-path", "*/node_modules/*",
 			"-not", "-path", "*/vendor/*",
 		}
-		cmd = exec.CommandContext(timeoutCtx, "find", findArgs...)
+		cmd := exec.CommandContext(timeoutCtx, "find", findArgs...)
 		output, err = cmd.Output()
 		if err != nil {
 			if timeoutCtx.Err() == context.DeadlineExceeded {
@@ -312,9 +715,14 @@ func (t *ExploreTools) executeGlob(ctx context.Context, arguments string) (strin
 		}
 
 		relPath, _ := filepath.Rel(t.repoRoot, fullPath)
-		if shouldSkipFile(relPath) {
+		if shouldSkipFile(relPath) || globExcluded(relPath, params.Exclude) {
 			continue
 		}
+		if params.FollowSymlinks {
+			if realPath, err := filepath.EvalSymlinks(fullPath); err != nil || !pathWithinRoot(realRoot, realPath) {
+				continue
+			}
+		}
 
 		matches = append(matches, fileMatch{
 			path:    relPath,
@@ -336,7 +744,8 @@ func (t *ExploreTools) executeGlob(ctx context.Context, arguments string) (strin
 	})
 
 	// Truncate to max results
-	truncated := len(matches) > maxGlobResults
+	total := len(matches)
+	truncated := total > maxGlobResults
 	if truncated {
 		matches = matches[:maxGlobResults]
 	}
@@ -348,7 +757,7 @@ func (t *ExploreTools) executeGlob(ctx context.Context, arguments string) (strin
 	}
 
 	if truncated {
-		result.WriteString(fmt.Sprintf("\n[Showing %d of %d+ matches. Refine pattern for more specific results.]", maxGlobResults, len(matches)))
+		result.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d+ matches. Refine pattern for more specific results.]", maxGlobResults, total)))
 	}
 
 	return withTokenEstimate(result.String()), nil
@@ -380,7 +789,30 @@ func shouldSkipFile(path string) bool {
 	return false
 }
 
-// executeGrep searches file contents with ripgrep.
+// globExcluded reports whether relPath matches one of the glob tool's exclude
+// patterns, either as a whole or in any single path segment, so "testdata"
+// drops everything under a testdata directory and "*.pb.go" drops generated
+// files anywhere.
+func globExcluded(relPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+		for _, part := range strings.Split(relPath, string(filepath.Separator)) {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// executeGrep searches file contents with ripgrep, or grep when rg is not
+// installed.
 func (t *ExploreTools) executeGrep(ctx context.Context, arguments string) (string, error) {
 	params, err := llm.ParseToolArguments[GrepParams](arguments)
 	if err != nil {
@@ -391,29 +823,6 @@ func (t *ExploreTools) executeGrep(ctx context.Context, arguments string) (strin
 		return "Error: pattern is required", nil
 	}
 
-	// Build ripgrep command
-	args := []string{
-		"-n",           // Line numbers
-		"--no-heading", // File:line format
-		"--color=never",
-	}
-
-	if params.IgnoreCase {
-		args = append(args, "-i")
-	}
-
-	if params.Context > 0 {
-		args = append(args, fmt.Sprintf("-C%d", params.Context))
-	}
-
-	if params.Glob != "" {
-		args = append(args, "-g", params.Glob)
-	}
-
-	// Add pattern
-	args = append(args, params.Pattern)
-
-	// Add path
 	searchPath := t.repoRoot
 	if params.Path != "" {
 		searchPath = filepath.Join(t.repoRoot, params.Path)
@@ -421,20 +830,32 @@ func (t *ExploreTools) executeGrep(ctx context.Context, arguments string) (strin
 	if !pathWithinRoot(t.repoRoot, searchPath) {
 		return "Error: path outside repository", nil
 	}
-	args = append(args, searchPath)
 
-	// Execute ripgrep with timeout
-	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(bashTimeout)*time.Second)
+	if !t.hasRipgrep && !t.hasGrep {
+		return "Error: grep is unavailable on this host (neither rg nor grep is installed). Use codegraph or bash instead.", nil
+	}
+
+	// Execute the search with timeout
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Grep)
 	defer cancel()
 
-	cmd := exec.CommandContext(timeoutCtx, "rg", args...)
+	var cmd *exec.Cmd
+	if t.hasRipgrep {
+		cmd = exec.CommandContext(timeoutCtx, "rg", ripgrepArgs(params, searchPath)...)
+	} else {
+		cmd = exec.CommandContext(timeoutCtx, "grep", fallbackGrepArgs(params, searchPath)...)
+	}
 	output, err := cmd.Output()
 
 	if timeoutCtx.Err() == context.DeadlineExceeded {
 		return "Search timed out. Use more specific pattern or path.", nil
 	}
+	if !t.hasRipgrep {
+		// rg --sort path keeps pages stable; grep -r walks in directory order
+		output = sortGrepOutput(output, params.Context > 0 && !params.FilesOnly)
+	}
 
-	// ripgrep returns exit code 1 for no matches
+	// rg and grep both exit 1 for no matches
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
 			return fmt.Sprintf("No matches for pattern: %s", params.Pattern), nil
@@ -445,12 +866,19 @@ func (t *ExploreTools) executeGrep(ctx context.Context, arguments string) (strin
 		}
 	}
 
-	// Truncate results
+	if params.FilesOnly {
+		return t.grepFileList(ctx, params, string(output)), nil
+	}
+
+	// Page results. Only match lines count toward the offset and cap, so
+	// context lines and "--" separators don't crowd out matches.
 	lines := strings.Split(string(output), "\n")
-	truncated := len(lines) > maxGrepMatches
-	if truncated {
-		lines = lines[:maxGrepMatches]
+	total := countGrepMatches(lines)
+	offset := max(params.Offset, 0)
+	if offset > 0 && offset >= total {
+		return fmt.Sprintf("No more matches for pattern: %s (%d total)", params.Pattern, total), nil
 	}
+	lines, truncated := limitGrepMatches(skipGrepMatches(lines, offset), maxGrepMatches)
 
 	// Make paths relative
 	var result strings.Builder
@@ -458,6 +886,7 @@ func (t *ExploreTools) executeGrep(ctx context.Context, arguments string) (strin
 		if line == "" {
 			continue
 		}
+		line = grepDisplayLine(line)
 		// Convert absolute paths to relative
 		if strings.HasPrefix(line, t.repoRoot) {
 			line = strings.TrimPrefix(line, t.repoRoot+"/")
@@ -467,12 +896,174 @@ func (t *ExploreTools) executeGrep(ctx context.Context, arguments string) (strin
 	}
 
 	if truncated {
-		result.WriteString(fmt.Sprintf("\n[Showing %d matches. Add glob filter or refine pattern.]", maxGrepMatches))
+		next := offset + maxGrepMatches
+		result.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d matches. %d more matches; call grep again with offset=%d, or add a glob filter or refine the pattern.]", maxGrepMatches, total-next, next)))
 	}
 
 	return withTokenEstimate(result.String()), nil
 }
 
+// ripgrepArgs builds the rg command line for a grep call.
+func ripgrepArgs(params GrepParams, searchPath string) []string {
+	args := []string{
+		"-n",           // Line numbers
+		"-H",           // Path on every line, even when searching a single file
+		"--no-heading", // File:line format
+		"--null",       // NUL after the path, to tell matches from context lines
+		"--color=never",
+	}
+	if params.FilesOnly {
+		args = []string{"-l", "--color=never"}
+	}
+
+	if params.IgnoreCase {
+		args = append(args, "-i")
+	}
+
+	if params.Literal {
+		args = append(args, "-F")
+	}
+
+	if params.Context > 0 && !params.FilesOnly {
+		args = append(args, fmt.Sprintf("-C%d", params.Context))
+	}
+
+	if params.Glob != "" {
+		args = append(args, "-g", params.Glob)
+	}
+
+	// Sorted output keeps pages stable across calls with different offsets
+	args = append(args, "--sort", "path")
+
+	return append(args, params.Pattern, searchPath)
+}
+
+// grepFileList formats rg/grep -l output: unique repo-relative paths, paged
+// by params.Offset and capped at maxGlobResults like glob.
+func (t *ExploreTools) grepFileList(ctx context.Context, params GrepParams, output string) string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		path := strings.TrimPrefix(line, t.repoRoot+"/")
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+
+	total := len(files)
+	offset := max(params.Offset, 0)
+	if offset > 0 && offset >= total {
+		return fmt.Sprintf("No more files for pattern: %s (%d total)", params.Pattern, total)
+	}
+	files = files[offset:]
+	truncated := len(files) > maxGlobResults
+	if truncated {
+		files = files[:maxGlobResults]
+	}
+
+	var result strings.Builder
+	for _, f := range files {
+		result.WriteString(f)
+		result.WriteString("\n")
+	}
+
+	if truncated {
+		next := offset + maxGlobResults
+		result.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d files. %d more files; call grep again with offset=%d, or add a glob filter or refine the pattern.]", maxGlobResults, total-next, next)))
+	}
+
+	return withTokenEstimate(result.String())
+}
+
+// isGrepMatchLine reports whether a ripgrep --null output line is a match
+// ("path\x00N:text") rather than a context line ("path\x00N-text") or a "--"
+// group separator. --null makes this unambiguous for paths containing ":" or "-".
+func isGrepMatchLine(line string) bool {
+	_, rest, ok := strings.Cut(line, "\x00")
+	if !ok {
+		return false
+	}
+	n := 0
+	for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+		n++
+	}
+	return n > 0 && n < len(rest) && rest[n] == ':'
+}
+
+// grepDisplayLine turns a --null output line back into rg's usual
+// "path:N:text" (match) or "path-N-text" (context) form.
+func grepDisplayLine(line string) string {
+	if isGrepMatchLine(line) {
+		return strings.Replace(line, "\x00", ":", 1)
+	}
+	return strings.Replace(line, "\x00", "-", 1)
+}
+
+// countGrepMatches counts the match lines in ripgrep --null output.
+func countGrepMatches(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		if isGrepMatchLine(line) {
+			n++
+		}
+	}
+	return n
+}
+
+// skipGrepMatches drops the first offset matches. The result starts at the
+// leading context of the next match, so a page never repeats a match from
+// an earlier one.
+func skipGrepMatches(lines []string, offset int) []string {
+	if offset <= 0 {
+		return lines
+	}
+	matches := 0
+	for i, line := range lines {
+		if !isGrepMatchLine(line) {
+			continue
+		}
+		if matches == offset {
+			start := i
+			for start > 0 && lines[start-1] != "--" && !isGrepMatchLine(lines[start-1]) {
+				start--
+			}
+			return lines[start:]
+		}
+		matches++
+	}
+	return nil
+}
+
+// limitGrepMatches keeps lines up to maxMatches match lines, along with the
+// context that follows the last kept match. truncated is true when a later
+// match was dropped.
+func limitGrepMatches(lines []string, maxMatches int) (kept []string, truncated bool) {
+	matches, cut := 0, -1
+	for i, line := range lines {
+		switch {
+		case line == "--":
+			// Leading context of the next group belongs to the dropped match.
+			if matches == maxMatches && cut < 0 {
+				cut = i
+			}
+		case isGrepMatchLine(line):
+			if matches == maxMatches {
+				if cut < 0 {
+					cut = i
+				}
+				return lines[:cut], true
+			}
+			matches++
+		}
+	}
+	return lines, false
+}
+
 // executeRead reads a file with optional line range.
 func (t *ExploreTools) executeRead(ctx context.Context, arguments string) (string, error) {
 	params, err := llm.ParseToolArguments[ReadParams](arguments)
@@ -480,6 +1071,19 @@ func (t *ExploreTools) executeRead(ctx context.Context, arguments string) (strin
 		return "", fmt.Errorf("parse read params: %w", err)
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, t.timeouts.Read)
+	defer cancel()
+
+	var header string
+	if params.QName != "" {
+		file, start, end, errMsg := t.resolveReadSpan(ctx, params.QName)
+		if errMsg != "" {
+			return errMsg, nil
+		}
+		params.FilePath, params.Offset, params.EndLine = file, start, end
+		header = fmt.Sprintf("[%s: lines %d-%d of %s]\n", params.QName, start, end, file)
+	}
+
 	if params.FilePath == "" {
 		return "Error: file_path is required", nil
 	}
@@ -500,6 +1104,17 @@ func (t *ExploreTools) executeRead(ctx context.Context, arguments string) (strin
 	}
 	defer file.Close()
 
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Sprintf("Error: cannot read file: %s", err), nil
+	}
+	// Skipping to the offset scans every line before it. For huge files cap
+	// the scan at maxFileBytes so a deep offset can't run into the timeout.
+	scanBudget := int64(-1)
+	if stat.Size() > t.maxFileBytes {
+		scanBudget = t.maxFileBytes
+	}
+
 	// Set defaults
 	offset := params.Offset
 	if offset < 1 {
@@ -509,6 +1124,12 @@ func (t *ExploreTools) executeRead(ctx context.Context, arguments string) (strin
 	if limit < 1 {
 		limit = defaultReadLines
 	}
+	if params.EndLine > 0 {
+		if params.EndLine < offset {
+			return fmt.Sprintf("Error: end_line (%d) is before offset (%d)", params.EndLine, offset), nil
+		}
+		limit = params.EndLine - offset + 1
+	}
 	if limit > maxReadLines {
 		limit = maxReadLines
 	}
@@ -516,14 +1137,26 @@ func (t *ExploreTools) executeRead(ctx context.Context, arguments string) (strin
 	// Read lines
 	scanner := bufio.NewScanner(file)
 	var result strings.Builder
+	result.WriteString(header)
 	lineNum := 0
 	linesRead := 0
 
 	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return fmt.Sprintf("Read timed out after %s. Use grep to find the lines you need.", t.timeouts.Read), nil
+		}
 		lineNum++
 
 		// Skip until offset
 		if lineNum < offset {
+			if scanBudget >= 0 {
+				scanBudget -= int64(len(scanner.Bytes())) + 1
+				if scanBudget < 0 {
+					return fmt.Sprintf("Error: %s is %d bytes, too large to read from line %d (only the first %d bytes can be read). "+
+						"It is likely generated; use grep with path=%q to find the lines you need.",
+						params.FilePath, stat.Size(), offset, t.maxFileBytes, params.FilePath), nil
+				}
+			}
 			continue
 		}
 
@@ -566,7 +1199,39 @@ func (t *ExploreTools) executeRead(ctx context.Context, arguments string) (strin
 	return withTokenEstimate(result.String()), nil
 }
 
-// bashAllowedPrefixes defines read-only commands that are allowed.
+// resolveReadSpan looks up qname's file and first/last line in the codegraph
+// for read. On failure it returns the message to show the model instead.
+func (t *ExploreTools) resolveReadSpan(ctx context.Context, qname string) (file string, start, end int, errMsg string) {
+	if t.arango == nil {
+		return "", 0, 0, "Error: reading by qname needs the codegraph, which is unavailable. Use file_path with offset/end_line instead."
+	}
+
+	symbol, errMsg := t.resolveSymbolWithFile(ctx, CodegraphParams{QName: qname})
+	if errMsg != "" {
+		return "", 0, 0, errMsg
+	}
+	if symbol.Filepath == "" || symbol.Pos <= 0 {
+		return "", 0, 0, fmt.Sprintf("Error: the codegraph has no location for %q. Use file_path instead.", qname)
+	}
+
+	// Search results carry only the start line; the end comes from the
+	// file's symbol list. Without one, read the default window from the start.
+	start, end = symbol.Pos, symbol.Pos+defaultReadLines-1
+	symbols, err := t.arango.GetFileSymbols(ctx, arangodb.FileSymbolsOptions{Filepath: symbol.Filepath})
+	if err != nil {
+		slog.WarnContext(ctx, "codegraph file symbols failed, reading from symbol start", "qname", qname, "error", err)
+	}
+	for _, s := range symbols {
+		if s.QName == qname && s.End >= start {
+			end = s.End
+			break
+		}
+	}
+
+	return t.makeCodegraphPathRelative(symbol.Filepath), start, end, ""
+}
+
+// bashAllowedPrefixes defines the read-only commands allowed by default.
 var bashAllowedPrefixes = []string{
 	// Git read-only
 	"git log", "git show", "git diff", "git blame", "git status",
@@ -579,7 +1244,17 @@ var bashAllowedPrefixes = []string{
 	"cat ", "head ", "tail ", "grep ", "rg ",
 }
 
-// bashBlockedPrefixes defines write operations that are always blocked.
+// bashNoGitDescription replaces the bash tool description when git is disabled.
+const bashNoGitDescription = `Execute read-only bash commands. Use for directory listing. Git is not available.
+
+Allowed:
+  ls -la internal/                 # Directory contents
+  find . -name "*.sql"             # Files by name
+  wc -l file.go                    # Line counts
+
+NOT allowed: git, rm, mv, cp, echo, write operations. Use grep and codegraph instead of git history.`
+
+// bashBlockedPrefixes defines the write operations blocked by default.
 var bashBlockedPrefixes = []string{
 	"rm ", "mv ", "cp ", "mkdir ", "touch ", "chmod ", "chown ",
 	"git push", "git commit", "git checkout", "git reset", "git rebase",
@@ -605,22 +1280,23 @@ func (t *ExploreTools) executeBash(ctx context.Context, arguments string) (strin
 		slog.DebugContext(ctx, "bash command blocked",
 			"command", command,
 			"reason", reason)
-		return fmt.Sprintf("Command blocked: %s\n\nAllowed: git log/show/diff/blame/status, ls, tree, find, cat, head, tail, grep, rg", reason), nil
+		return fmt.Sprintf("Command blocked: %s\n\nAllowed: %s", reason, t.allowedBashHint()), nil
 	}
 
 	// Create timeout context
-	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(bashTimeout)*time.Second)
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Bash)
 	defer cancel()
 
 	// Execute command
 	cmd := exec.CommandContext(timeoutCtx, "bash", "-c", command)
 	cmd.Dir = t.repoRoot
+	cmd.Env = bashEnv()
 
 	output, err := cmd.CombinedOutput()
 
 	// Handle timeout
 	if timeoutCtx.Err() == context.DeadlineExceeded {
-		return fmt.Sprintf("Command timed out after %d seconds.", bashTimeout), nil
+		return fmt.Sprintf("Command timed out after %s.", t.timeouts.Bash), nil
 	}
 
 	// Handle other errors (but still return output if available)
@@ -632,12 +1308,12 @@ func (t *ExploreTools) executeBash(ctx context.Context, arguments string) (strin
 			}
 		}
 		if len(output) > 0 {
-			return fmt.Sprintf("Command failed: %s\nOutput:\n%s", err, t.truncateOutput(output)), nil
+			return fmt.Sprintf("Command failed: %s\nOutput:\n%s", err, t.truncateOutput(ctx, output)), nil
 		}
 		return fmt.Sprintf("Command failed: %s", err), nil
 	}
 
-	result := t.truncateOutput(output)
+	result := t.truncateOutput(ctx, output)
 
 	slog.DebugContext(ctx, "bash executed",
 		"command", command,
@@ -650,8 +1326,12 @@ func (t *ExploreTools) executeBash(ctx context.Context, arguments string) (strin
 func (t *ExploreTools) isBashCommandAllowed(command string) (bool, string) {
 	cmd := strings.TrimSpace(command)
 
+	if t.gitDisabled && runsGit(cmd) {
+		return false, "git is disabled in this deployment - use grep, read and codegraph instead"
+	}
+
 	// Check blocked prefixes first
-	for _, prefix := range bashBlockedPrefixes {
+	for _, prefix := range t.bashBlocked {
 		if strings.HasPrefix(cmd, prefix) {
 			return false, fmt.Sprintf("'%s' not allowed - use dedicated tools", strings.TrimSpace(prefix))
 		}
@@ -662,12 +1342,16 @@ func (t *ExploreTools) isBashCommandAllowed(command string) (bool, string) {
 		return false, "output redirection not allowed"
 	}
 
+	if reason := dangerousGitArg(cmd); reason != "" {
+		return false, reason
+	}
+
 	if ok, reason := t.areBashPathsAllowed(cmd); !ok {
 		return false, reason
 	}
 
 	// Check allowed prefixes
-	for _, prefix := range bashAllowedPrefixes {
+	for _, prefix := range t.allowedBashPrefixes() {
 		if strings.HasPrefix(cmd, prefix) {
 			return true, ""
 		}
@@ -676,25 +1360,406 @@ func (t *ExploreTools) isBashCommandAllowed(command string) (bool, string) {
 	return false, "command not in allowed list"
 }
 
-var absPathPattern = regexp.MustCompile(`(?:^|[\s'"])(/[^\\s'"]+)`)
-
-func (t *ExploreTools) areBashPathsAllowed(command string) (bool, string) {
-	if strings.HasPrefix(command, "..") || strings.Contains(command, "../") {
-		return false, "path traversal outside repository not allowed"
+// allowedBashPrefixes returns the configured allowed prefixes, minus git
+// commands when git is disabled.
+func (t *ExploreTools) allowedBashPrefixes() []string {
+	if !t.gitDisabled {
+		return t.bashAllowed
 	}
-
-	matches := absPathPattern.FindAllStringSubmatch(command, -1)
-	for _, match := range matches {
-		if len(match) < 2 {
-			continue
+	prefixes := make([]string, 0, len(t.bashAllowed))
+	for _, prefix := range t.bashAllowed {
+		if !isGitCommand(prefix) {
+			prefixes = append(prefixes, prefix)
 		}
-		pathToken := strings.TrimRight(match[1], ".,;:")
-		if !pathWithinRoot(t.repoRoot, pathToken) {
-			return false, "absolute path outside repository not allowed"
+	}
+	return prefixes
+}
+
+// allowedBashHint lists the allowed commands for a blocked-command message.
+func (t *ExploreTools) allowedBashHint() string {
+	var names []string
+	seen := map[string]bool{}
+	for _, prefix := range t.allowedBashPrefixes() {
+		name := strings.TrimSpace(prefix)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
 		}
 	}
+	return strings.Join(names, ", ")
+}
 
-	return true, ""
+func isGitCommand(cmd string) bool {
+	return cmd == "git" || strings.HasPrefix(cmd, "git ") || strings.HasPrefix(cmd, "git\t")
+}
+
+// commandWrappers run their arguments as a command, so the word after them
+// (and their own flags) is the command that actually runs.
+var commandWrappers = map[string]bool{
+	"env": true, "command": true, "exec": true, "builtin": true, "nohup": true,
+	"nice": true, "time": true, "timeout": true, "xargs": true, "sudo": true,
+}
+
+// runsGit reports whether any simple command in cmd runs git, so chains like
+// "ls && git log" or "echo $(git log)" can't slip past a git-disabled check
+// on the command's first word.
+func runsGit(cmd string) bool {
+	for _, seg := range shellSegments(cmd) {
+		if filepath.Base(commandWord(seg)) == "git" {
+			return true
+		}
+	}
+	return false
+}
+
+// shellSegments splits a command line into the simple commands bash would
+// run: at ;, &, |, newlines, parentheses and the start of $(...) and `...`
+// substitutions. Single-quoted text is never split; double-quoted text only
+// at substitutions, which bash still expands there.
+func shellSegments(cmd string) []string {
+	var segs []string
+	var cur strings.Builder
+	flush := func() {
+		if seg := strings.TrimSpace(cur.String()); seg != "" {
+			segs = append(segs, seg)
+		}
+		cur.Reset()
+	}
+
+	var quote byte
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\' && i+1 < len(cmd):
+			cur.WriteByte(c)
+			i++
+			c = cmd[i]
+		case c == '`':
+			flush()
+			continue
+		case c == '$' && i+1 < len(cmd) && cmd[i+1] == '(':
+			flush()
+			i++
+			continue
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case strings.IndexByte(";&|\n()", c) >= 0:
+			flush()
+			continue
+		}
+		cur.WriteByte(c)
+	}
+	flush()
+	return segs
+}
+
+// commandWord returns the command a simple command runs, skipping VAR=value
+// assignments and wrappers like env or xargs, with quotes and backslashes
+// removed the way bash would ("g\it" and 'git' both run git).
+func commandWord(seg string) string {
+	wrapped := false
+	for _, field := range strings.Fields(seg) {
+		word := strings.NewReplacer(`"`, "", "'", "", `\`, "").Replace(field)
+		switch {
+		case word == "":
+			continue
+		case !wrapped && strings.Contains(word, "=") && !strings.HasPrefix(word, "="):
+			continue // FOO=bar git log
+		case wrapped && (strings.HasPrefix(word, "-") || strings.Contains(word, "=")):
+			continue // env -i, xargs -0, env FOO=bar
+		case wrapped && word[0] >= '0' && word[0] <= '9':
+			continue // timeout 10, nice 5
+		case commandWrappers[word]:
+			wrapped = true
+			continue
+		}
+		return word
+	}
+	return ""
+}
+
+// dangerousGitFlags are git options that run external commands or write
+// files, which an allowed read-only subcommand like git log or git diff
+// would otherwise accept.
+var dangerousGitFlags = []string{
+	"--ext-diff",            // External diff driver
+	"--exec",                // --exec=<cmd>
+	"--exec-path",           // Runs git-<subcommand> from another directory
+	"--output",              // Writes the diff to a file
+	"--open-files-in-pager", // git grep -O runs a pager command
+	"--config-env",          // Config from the environment, like -c
+	"--upload-pack",         // Remote helper commands
+	"--receive-pack",
+}
+
+// dangerousGitArg returns why cmd must not run when it runs git with an
+// argument that could execute something: one of dangerousGitFlags (or an
+// abbreviation git would accept for it), a -c key=value config override
+// (core.pager, diff.external, ...) or an %(exec:...) format atom. Arguments
+// are compared after bash's quote removal, and commands that also use shell
+// expansion are refused outright, since an expansion can assemble any
+// option. It returns "" when the command is safe or doesn't run git.
+func dangerousGitArg(cmd string) string {
+	if !runsGit(cmd) {
+		return ""
+	}
+	for _, w := range shellWords(cmd) {
+		if w.expands {
+			return fmt.Sprintf("shell expansion '%s' not allowed in git commands", w.raw)
+		}
+	}
+	if flag := dangerousGitFlag(cmd); flag != "" {
+		return fmt.Sprintf("git option '%s' can run external commands or write files", flag)
+	}
+	if setting := gitEnvironmentChange(cmd); setting != "" {
+		return fmt.Sprintf("'%s' changes the environment git runs in", setting)
+	}
+	return ""
+}
+
+// gitEnvVars are variables, besides GIT_*, that change which programs or
+// config git uses (pagers, editors, ~/.gitconfig, the PATH it searches).
+var gitEnvVars = map[string]bool{
+	"PAGER": true, "EDITOR": true, "VISUAL": true, "LESSOPEN": true, "LESSCLOSE": true,
+	"HOME": true, "XDG_CONFIG_HOME": true, "PATH": true,
+}
+
+// gitArgWrappers run git with arguments or environment that aren't on the
+// command line (env FOO=bar git, xargs git), so its options can't be checked.
+var gitArgWrappers = map[string]bool{"env": true, "xargs": true, "sudo": true}
+
+// gitEnvironmentChange returns the part of cmd, a command line that runs
+// git, that sets up git's environment: any assignment or env/xargs wrapper
+// in front of git, or elsewhere in the line a word naming a GIT_* or other
+// gitEnvVars variable (GIT_EXTERNAL_DIFF=x, export GIT_CONFIG_COUNT=1,
+// read PAGER). These do what the blocked -c overrides do. Search tools may
+// still look for such names. It returns "" when there is none.
+func gitEnvironmentChange(cmd string) string {
+	for _, seg := range shellSegments(cmd) {
+		word := commandWord(seg)
+		words := shellWords(seg)
+		if filepath.Base(word) == "git" {
+			for _, w := range words {
+				if w.value == word {
+					break
+				}
+				if gitArgWrappers[w.value] || isAssignment(w.value) {
+					return w.raw
+				}
+			}
+			continue
+		}
+		if word == "grep" || word == "rg" {
+			continue
+		}
+		for _, w := range words {
+			name, _, _ := strings.Cut(w.value, "=")
+			if strings.HasPrefix(name, "GIT_") || gitEnvVars[name] {
+				return w.raw
+			}
+		}
+	}
+	return ""
+}
+
+// isAssignment reports whether word is a NAME=value variable assignment.
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && !(c >= 'A' && c <= 'Z') && !(c >= 'a' && c <= 'z') && (i == 0 || !(c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// bashEnv is the environment bash commands run in: the worker's own without
+// GIT_* and the other gitEnvVars that redirect git to other programs or
+// config, and with system-wide git config ignored. PATH and HOME are kept
+// as they are.
+func bashEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, "GIT_") || (gitEnvVars[name] && name != "PATH" && name != "HOME") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env, "GIT_CONFIG_NOSYSTEM=1")
+}
+
+// dangerousGitFlag returns the first dangerous option passed to git in
+// cmd, or "".
+func dangerousGitFlag(cmd string) string {
+	if strings.Contains(cmd, "%(exec") {
+		return "%(exec:...)"
+	}
+	for _, seg := range shellSegments(cmd) {
+		git := commandWord(seg)
+		if filepath.Base(git) != "git" {
+			continue
+		}
+		words := shellWords(seg)
+		for len(words) > 0 && words[0].value != git {
+			words = words[1:] // Assignments and wrappers before git
+		}
+		if len(words) == 0 {
+			continue
+		}
+		args := words[1:]
+		grep := slices.ContainsFunc(args, func(w shellWord) bool { return w.value == "grep" })
+
+		for i, w := range args {
+			arg := w.value
+			if strings.Contains(arg, "%(exec") {
+				return "%(exec:...)"
+			}
+			if !strings.HasPrefix(arg, "-") {
+				continue
+			}
+			name, _, _ := strings.Cut(arg, "=")
+			for _, flag := range dangerousGitFlags {
+				// git accepts any unambiguous prefix of a long option.
+				if name == flag || (len(name) > len("--") && strings.HasPrefix(flag, name)) {
+					return flag
+				}
+			}
+			// -c alone is git log's combined diff; with key=value it sets config.
+			if arg == "-c" && i+1 < len(args) && strings.Contains(args[i+1].value, "=") {
+				return "-c " + args[i+1].value
+			}
+			if strings.HasPrefix(arg, "-c") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, "=") {
+				return arg
+			}
+			if strings.HasPrefix(arg, "-O") && grep {
+				return "-O" // git grep -O<pager>
+			}
+		}
+	}
+	return ""
+}
+
+// shellWord is one word of a command line as bash would pass it on.
+type shellWord struct {
+	value   string // After quote and backslash removal
+	raw     string // As written
+	expands bool   // Bash would expand part of it ($VAR, $(...), `...`, {a,b})
+}
+
+// shellWords splits a command line into words the way bash does, removing
+// quotes and backslashes, so "--ext""-diff" and --ext\-diff both read as
+// --ext-diff. Operators (;, &, |, parentheses, newlines) end a word.
+func shellWords(cmd string) []shellWord {
+	var words []shellWord
+	var value, raw strings.Builder
+	inWord, expands, brace, braceList := false, false, false, false
+	flush := func() {
+		if inWord {
+			words = append(words, shellWord{value: value.String(), raw: raw.String(), expands: expands || (brace && braceList)})
+		}
+		value.Reset()
+		raw.Reset()
+		inWord, expands, brace, braceList = false, false, false, false
+	}
+	// expansionAt reports whether a $ at i starts a parameter, arithmetic,
+	// command or (outside double quotes) $'...' / $"..." expansion.
+	expansionAt := func(i int, quoted bool) bool {
+		if i+1 >= len(cmd) {
+			return false
+		}
+		c := cmd[i+1]
+		if c == '\'' || c == '"' {
+			return !quoted
+		}
+		return c == '_' || c == '{' || c == '(' || strings.IndexByte("@*#?!$-", c) >= 0 ||
+			(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+	}
+
+	var quote byte
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				value.WriteByte(c)
+			}
+		case quote == '"':
+			switch {
+			case c == '"':
+				quote = 0
+			case c == '\\' && i+1 < len(cmd) && strings.IndexByte("$`\"\\", cmd[i+1]) >= 0:
+				raw.WriteByte(c)
+				i++
+				c = cmd[i]
+				value.WriteByte(c)
+			case c == '`' || (c == '$' && expansionAt(i, true)):
+				expands = true
+				value.WriteByte(c)
+			default:
+				value.WriteByte(c)
+			}
+		case c == '\\' && i+1 < len(cmd):
+			raw.WriteByte(c)
+			i++
+			c = cmd[i]
+			value.WriteByte(c)
+		case c == ' ' || c == '\t' || strings.IndexByte(";&|\n()", c) >= 0:
+			flush()
+			continue
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '`' || (c == '$' && expansionAt(i, false)):
+			expands = true
+			value.WriteByte(c)
+		default:
+			switch {
+			case c == '{':
+				brace = true
+			case brace && (c == ',' || (c == '.' && i+1 < len(cmd) && cmd[i+1] == '.')):
+				braceList = true
+			}
+			value.WriteByte(c)
+		}
+		raw.WriteByte(c)
+		inWord = true
+	}
+	flush()
+	return words
+}
+
+var absPathPattern = regexp.MustCompile(`(?:^|[\s'"])(/[^\\s'"]+)`)
+
+func (t *ExploreTools) areBashPathsAllowed(command string) (bool, string) {
+	if strings.HasPrefix(command, "..") || strings.Contains(command, "../") {
+		return false, "path traversal outside repository not allowed"
+	}
+
+	matches := absPathPattern.FindAllStringSubmatch(command, -1)
+	for _, match := range matches {
+		if len(match) < 2 {
+			continue
+		}
+		pathToken := strings.TrimRight(match[1], ".,;:")
+		if !pathWithinRoot(t.repoRoot, pathToken) {
+			return false, "absolute path outside repository not allowed"
+		}
+	}
+
+	return true, ""
 }
 
 func pathWithinRoot(root, path string) bool {
@@ -716,10 +1781,21 @@ func pathWithinRoot(root, path string) bool {
 	return true
 }
 
-// truncateOutput limits output size.
-func (t *ExploreTools) truncateOutput(output []byte) string {
+// truncateOutput limits output size. The line cap runs first so long
+// listings like git log are cut between entries rather than mid-entry; when
+// the kept lines are still too large, both footers are shown.
+func (t *ExploreTools) truncateOutput(ctx context.Context, output []byte) string {
+	footer := ""
+	if t.bashMaxLines > 0 {
+		lines := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+		if len(lines) > t.bashMaxLines {
+			output = []byte(strings.Join(lines[:t.bashMaxLines], "\n"))
+			footer = "\n\n" + t.truncationFooter(ctx, fmt.Sprintf("[showing first %d lines]", t.bashMaxLines))
+		}
+	}
+
 	if len(output) <= maxBashOutput {
-		return string(output)
+		return string(output) + footer
 	}
 
 	truncated := output[:maxBashOutput]
@@ -727,7 +1803,7 @@ func (t *ExploreTools) truncateOutput(output []byte) string {
 		truncated = truncated[:lastNewline]
 	}
 
-	return string(truncated) + "\n\n[Output truncated]"
+	return string(truncated) + "\n\n" + t.truncationFooter(ctx, "[Output truncated]") + footer
 }
 
 // withTokenEstimate appends a token cost estimate.
@@ -749,12 +1825,22 @@ func (t *ExploreTools) executeCodegraph(ctx context.Context, arguments string) (
 		return "Codegraph is not available. Use grep and read tools instead.", nil
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, t.timeouts.Codegraph)
+	defer cancel()
+
+	if msg := t.codegraphIndexMessage(ctx); msg != "" {
+		return msg, nil
+	}
+	if params.ContextFile != "" {
+		ctx = context.WithValue(ctx, contextFileKey{}, params.ContextFile)
+	}
+
 	params.Operation = strings.ToLower(strings.TrimSpace(params.Operation))
 	params.Kind = normalizeCodegraphKind(params.Kind)
 	params.FromKind = normalizeCodegraphKind(params.FromKind)
 	params.ToKind = normalizeCodegraphKind(params.ToKind)
 
-	if errMsg := validateCodegraphKind(params.Kind); errMsg != "" {
+	if errMsg := t.validateCodegraphKind(params.Kind); errMsg != "" {
 		return errMsg, nil
 	}
 	if params.FromKind != "" && !isSupportedTraceEndpointKind(params.FromKind) {
@@ -780,30 +1866,44 @@ func (t *ExploreTools) executeCodegraph(ctx context.Context, arguments string) (
 		return t.executeCodegraphResolve(ctx, params)
 	case "file_symbols":
 		return t.executeCodegraphFileSymbols(ctx, params)
+	case "siblings":
+		return t.executeCodegraphSiblings(ctx, params)
+	case "entrypoints":
+		return t.executeCodegraphEntrypoints(ctx, params)
+	case "config_usages":
+		return t.executeCodegraphConfigUsages(ctx, params)
+	case "hierarchy":
+		return t.executeCodegraphHierarchy(ctx, params)
+	case "describe":
+		return t.executeCodegraphDescribe(ctx, params)
+	case "dependents":
+		return t.executeCodegraphDependents(ctx, params)
 
 	case "callers":
 		qname, errMsg := t.resolveQNameForOperation(ctx, "callers", params)
 		if errMsg != "" {
 			return errMsg, nil
 		}
-		nodes, err := t.arango.GetCallers(ctx, qname, depth)
+		page := params.callPage()
+		nodes, total, err := t.arango.GetCallers(ctx, qname, depth, page)
 		if err != nil {
 			slog.ErrorContext(ctx, "codegraph callers failed", "qname", qname, "error", err)
-			return fmt.Sprintf("Error querying callers: %s", err), nil
+			return formatCodegraphError("querying callers", err), nil
 		}
-		return t.formatRelationshipResults("Callers", qname, depth, nodes), nil
+		return t.formatRelationshipResults(ctx, "Callers", qname, depth, nodes, page.Offset, total), nil
 
 	case "callees":
 		qname, errMsg := t.resolveQNameForOperation(ctx, "callees", params)
 		if errMsg != "" {
 			return errMsg, nil
 		}
-		nodes, err := t.arango.GetCallees(ctx, qname, depth)
+		page := params.callPage()
+		nodes, total, err := t.arango.GetCallees(ctx, qname, depth, page)
 		if err != nil {
 			slog.ErrorContext(ctx, "codegraph callees failed", "qname", qname, "error", err)
-			return fmt.Sprintf("Error querying callees: %s", err), nil
+			return formatCodegraphError("querying callees", err), nil
 		}
-		return t.formatRelationshipResults("Callees", qname, depth, nodes), nil
+		return t.formatRelationshipResults(ctx, "Callees", qname, depth, nodes, page.Offset, total), nil
 
 	case "implementations":
 		qname, errMsg := t.resolveQNameForOperation(ctx, "implementations", params)
@@ -813,31 +1913,58 @@ func (t *ExploreTools) executeCodegraph(ctx context.Context, arguments string) (
 		nodes, err := t.arango.GetImplementations(ctx, qname)
 		if err != nil {
 			slog.ErrorContext(ctx, "codegraph implementations failed", "qname", qname, "error", err)
-			return fmt.Sprintf("Error querying implementations: %s", err), nil
+			return formatCodegraphError("querying implementations", err), nil
 		}
-		return t.formatRelationshipResults("Implementations", qname, 1, nodes), nil
+		return t.formatRelationshipResults(ctx, "Implementations", qname, 1, nodes, 0, len(nodes)), nil
 
 	case "usages":
 		qname, errMsg := t.resolveQNameForOperation(ctx, "usages", params)
 		if errMsg != "" {
 			return errMsg, nil
 		}
-		nodes, err := t.arango.GetUsages(ctx, qname)
+		kind, errMsg := parseUsageKind(params.UsageKind)
+		if errMsg != "" {
+			return errMsg, nil
+		}
+		nodes, err := t.arango.GetUsages(ctx, qname, kind)
 		if err != nil {
 			slog.ErrorContext(ctx, "codegraph usages failed", "qname", qname, "error", err)
-			return fmt.Sprintf("Error querying usages: %s", err), nil
+			return formatCodegraphError("querying usages", err), nil
 		}
-		return t.formatRelationshipResults("Usages", qname, 1, nodes), nil
+		return t.formatRelationshipResults(ctx, "Usages", qname, 1, nodes, 0, len(nodes)), nil
+
+	case "neighborhood":
+		return t.executeCodegraphNeighborhood(ctx, params)
 
 	case "trace":
 		params.Depth = depth
 		return t.executeCodegraphTrace(ctx, params)
 
 	default:
-		return "Error: invalid operation. Valid operations: search, resolve, file_symbols, callers, callees, implementations, usages, trace", nil
+		return "Error: invalid operation. Valid operations: search, resolve, file_symbols, siblings, entrypoints, config_usages, hierarchy, describe, dependents, callers, callees, implementations, usages, neighborhood, trace", nil
 	}
 }
 
+// codegraphIndexMessage returns a message for the agent when the graph has no
+// nodes yet, so an unbuilt index isn't mistaken for "no such symbol". An empty
+// graph is re-checked on every call; once nodes show up the check stops.
+func (t *ExploreTools) codegraphIndexMessage(ctx context.Context) string {
+	if t.indexBuilt.Load() {
+		return ""
+	}
+	n, err := t.arango.NodeCount(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "codegraph node count failed", "error", err)
+		return ""
+	}
+	if n == 0 {
+		return "Codegraph index is not built yet (the graph has no symbols; ingestion may still be running). " +
+			"Empty results would not mean a symbol doesn't exist. Use grep and read tools instead."
+	}
+	t.indexBuilt.Store(true)
+	return ""
+}
+
 // executeCodegraphSearch handles symbol search.
 func (t *ExploreTools) executeCodegraphSearch(ctx context.Context, params CodegraphParams) (string, error) {
 	if params.Name == "" {
@@ -855,21 +1982,24 @@ func (t *ExploreTools) executeCodegraphSearch(ctx context.Context, params Codegr
 			"to_name", params.ToName, "from_name", params.FromName)
 	}
 
+	match := params.nameMatch()
 	results, total, err := t.arango.SearchSymbols(ctx, arangodb.SearchOptions{
-		Name: params.Name,
-		Kind: params.Kind,
-		File: params.File,
+		Name:            params.Name,
+		Kind:            params.Kind,
+		File:            params.File,
+		Exact:           match.exact,
+		CaseInsensitive: match.caseInsensitive,
 	})
 	if err != nil {
 		slog.ErrorContext(ctx, "codegraph search failed", "name", params.Name, "error", err)
-		return fmt.Sprintf("Error searching symbols: %s", err), nil
+		return formatCodegraphError("searching symbols", err), nil
 	}
 
-	return t.formatSearchResults(params, results, total), nil
+	return t.formatSearchResults(ctx, params, results, total), nil
 }
 
 // formatSearchResults formats symbol search results.
-func (t *ExploreTools) formatSearchResults(params CodegraphParams, results []arangodb.SearchResult, total int) string {
+func (t *ExploreTools) formatSearchResults(ctx context.Context, params CodegraphParams, results []arangodb.SearchResult, total int) string {
 	if total == 0 {
 		var sb strings.Builder
 		sb.WriteString(fmt.Sprintf("No symbols found matching %q", params.Name))
@@ -886,15 +2016,18 @@ func (t *ExploreTools) formatSearchResults(params CodegraphParams, results []ara
 	filtered := make([]arangodb.SearchResult, 0, len(results))
 	for _, r := range results {
 		kind := normalizeCodegraphKind(r.Kind)
-		if !isSupportedCodegraphKind(kind) {
+		if !t.isSupportedCodegraphKind(kind) {
 			continue
 		}
 		r.Kind = kind
 		filtered = append(filtered, r)
 	}
 	if len(filtered) == 0 {
-		return fmt.Sprintf("No supported symbols found matching %q. Supported kinds: function, method, struct, interface, class.", params.Name)
+		return fmt.Sprintf("No supported symbols found matching %q. Supported kinds: %s.", params.Name, t.codegraphKindList())
 	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return searchRank(filtered[i].Name, params.Name) < searchRank(filtered[j].Name, params.Name)
+	})
 
 	displayResults := filtered
 	truncated := false
@@ -915,36 +2048,78 @@ func (t *ExploreTools) formatSearchResults(params CodegraphParams, results []ara
 
 	for _, r := range displayResults {
 		sb.WriteString(t.formatCodegraphLine(r.Filepath, r.Pos, r.Kind, r.QName, r.Signature))
+		if params.IncludeDoc {
+			sb.WriteString(formatCodegraphDoc(r.Doc))
+		}
 		sb.WriteString("\n")
 	}
 
 	if truncated {
-		sb.WriteString(fmt.Sprintf("\n[Showing %d of %d. Refine with kind/file to see more.]\n", len(displayResults), len(filtered)))
+		sb.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d. Refine with kind/file to see more.]", len(displayResults), len(filtered))) + "\n")
 	}
 
 	return strings.TrimSpace(sb.String())
 }
 
-// formatRelationshipResults formats callers/callees/implementations/usages results.
-func (t *ExploreTools) formatRelationshipResults(operation string, qname string, depth int, nodes []arangodb.GraphNode) string {
+// searchRank orders a search hit: 0 for an exact name match, 1 for a prefix
+// match, 2 otherwise. Case is ignored and wildcards are dropped from pattern.
+func searchRank(name, pattern string) int {
+	name = strings.ToLower(name)
+	term := strings.ToLower(strings.ReplaceAll(pattern, "*", ""))
+	switch {
+	case name == term:
+		return 0
+	case strings.HasPrefix(name, term):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// callPage returns the callers/callees page selected by Limit and Offset,
+// with Limit clamped to 1..maxCallResults.
+func (p CodegraphParams) callPage() arangodb.Page {
+	limit := p.Limit
+	if limit <= 0 {
+		limit = defaultCallResults
+	}
+	return arangodb.Page{Limit: min(limit, maxCallResults), Offset: max(p.Offset, 0)}
+}
+
+// formatRelationshipResults formats callers/callees/implementations/usages
+// results. nodes is the page starting at offset out of total results; a
+// footer names the next offset when more remain.
+func (t *ExploreTools) formatRelationshipResults(ctx context.Context, operation string, qname string, depth int, nodes []arangodb.GraphNode, offset, total int) string {
 	filtered := make([]arangodb.GraphNode, 0, len(nodes))
 	for _, node := range nodes {
 		if node.QName == "" {
 			continue
 		}
-		node.Kind = normalizeCodegraphKind(node.Kind)
+		node.Kind = nodeKindLabel(node)
 		filtered = append(filtered, node)
 	}
+	noun := strings.ToLower(operation)
 	if len(filtered) == 0 {
-		return fmt.Sprintf("No %s found for %s.", strings.ToLower(operation), qname)
+		if offset > 0 && total > 0 {
+			return fmt.Sprintf("No more %s for %s (%d total).", noun, qname, total)
+		}
+		return fmt.Sprintf("No %s found for %s.", noun, qname)
 	}
+	total = max(total, offset+len(filtered))
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%s of %s (depth %d) - %d result(s):\n", operation, qname, depth, len(filtered)))
+	if offset == 0 && len(filtered) == total {
+		sb.WriteString(fmt.Sprintf("%s of %s (depth %d) - %d result(s):\n", operation, qname, depth, len(filtered)))
+	} else {
+		sb.WriteString(fmt.Sprintf("%s of %s (depth %d) - results %d-%d of %d:\n", operation, qname, depth, offset+1, offset+len(filtered), total))
+	}
 	for _, node := range filtered {
 		sb.WriteString(t.formatCodegraphLine(node.Filepath, node.Pos, node.Kind, node.QName, node.Signature))
 		sb.WriteString("\n")
 	}
+	if next := offset + len(filtered); next < total {
+		sb.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d %s (depth %d). Call again with offset=%d for more.]", len(filtered), total, noun, depth, next)))
+	}
 
 	return strings.TrimSpace(sb.String())
 }
@@ -952,35 +2127,93 @@ func (t *ExploreTools) formatRelationshipResults(operation string, qname string,
 const (
 	defaultTraceDepth        = 4
 	maxTraceDepth            = 10
+	maxTracePaths            = 5
 	maxCodegraphSignatureLen = 220
+	maxCodegraphDocLen       = 160
 	maxFileSymbolsResults    = 50
+	maxEntrypointResults     = 30
+	maxResolveBatch          = 20
 )
 
-var codegraphSupportedKindSet = map[string]struct{}{
-	"function":  {},
-	"method":    {},
-	"struct":    {},
-	"interface": {},
-	"class":     {},
+// defaultCodegraphKinds are the kinds codegraph supports unless
+// ExploreToolsConfig.CodegraphKinds replaces them.
+var defaultCodegraphKinds = []string{"function", "method", "struct", "interface", "class"}
+
+// setCodegraphKinds normalizes kinds and makes them the supported set. Empty
+// kinds keep the default.
+func (t *ExploreTools) setCodegraphKinds(kinds []string) {
+	t.codegraphKinds = nil
+	t.codegraphKindSet = map[string]bool{}
+	for _, k := range kinds {
+		k = normalizeCodegraphKind(k)
+		if k == "" || t.codegraphKindSet[k] {
+			continue
+		}
+		t.codegraphKinds = append(t.codegraphKinds, k)
+		t.codegraphKindSet[k] = true
+	}
+	if len(t.codegraphKinds) == 0 {
+		t.setCodegraphKinds(defaultCodegraphKinds)
+	}
+}
+
+func (t *ExploreTools) isSupportedCodegraphKind(kind string) bool {
+	return t.codegraphKindSet[kind]
 }
 
-func isSupportedCodegraphKind(kind string) bool {
-	_, ok := codegraphSupportedKindSet[kind]
-	return ok
+// codegraphKindList is the supported kinds for messages, e.g. "function, method".
+func (t *ExploreTools) codegraphKindList() string {
+	return strings.Join(t.codegraphKinds, ", ")
+}
+
+// codegraphParamsSchema is the CodegraphParams schema with kind limited to
+// the supported kinds.
+func (t *ExploreTools) codegraphParamsSchema() any {
+	schema := llm.GenerateSchemaFrom(CodegraphParams{}).(*jsonschema.Schema)
+	if kind, ok := schema.Properties.Get("kind"); ok {
+		kind.Enum = make([]any, len(t.codegraphKinds))
+		for i, k := range t.codegraphKinds {
+			kind.Enum[i] = k
+		}
+		kind.Description = "Optional kind filter. Supported kinds: " + t.codegraphKindList() + "."
+	}
+	return schema
 }
 
 func normalizeCodegraphKind(kind string) string {
 	return strings.ToLower(strings.TrimSpace(kind))
 }
 
-func validateCodegraphKind(kind string) string {
+// nodeKindLabel is the node's kind, followed by how it uses the queried type
+// for usages results, e.g. "method (param)".
+func nodeKindLabel(n arangodb.GraphNode) string {
+	kind := normalizeCodegraphKind(n.Kind)
+	if n.Usage != "" {
+		return fmt.Sprintf("%s (%s)", kind, n.Usage)
+	}
+	return kind
+}
+
+// parseUsageKind validates the usages operation's usage_kind.
+func parseUsageKind(kind string) (arangodb.UsageKind, string) {
+	switch k := strings.ToLower(strings.TrimSpace(kind)); k {
+	case "", "any":
+		return arangodb.UsageAny, ""
+	case string(arangodb.UsageParam), string(arangodb.UsageReturn), string(arangodb.UsageField):
+		return arangodb.UsageKind(k), ""
+	default:
+		return "", fmt.Sprintf("Error: invalid usage_kind %q. Supported: any, param, return, field.", kind)
+	}
+}
+
+func (t *ExploreTools) validateCodegraphKind(kind string) string {
 	if kind == "" {
 		return ""
 	}
-	if isSupportedCodegraphKind(kind) {
+	if t.isSupportedCodegraphKind(kind) {
 		return ""
 	}
-	return fmt.Sprintf("Error: invalid kind %q. Supported kinds: function, method, struct, interface, class.", kind)
+	return fmt.Sprintf("Error: invalid kind %q. Supported kinds: %s.", kind, t.codegraphKindList())
 }
 
 func isSupportedTraceEndpointKind(kind string) bool {
@@ -1008,6 +2241,22 @@ func (t *ExploreTools) formatCodegraphLine(filePath string, pos int, kind, qname
 	return fmt.Sprintf("%s\t%s\t%s\t%s", location, kind, qname, sig)
 }
 
+// formatCodegraphDoc renders the first sentence of a doc comment as a
+// "\t// ..." suffix for a codegraph line, or "" when there is no doc.
+func formatCodegraphDoc(doc string) string {
+	doc = firstParagraph(doc)
+	if end := strings.Index(doc, ". "); end >= 0 {
+		doc = doc[:end+1]
+	}
+	if doc == "" {
+		return ""
+	}
+	if len(doc) > maxCodegraphDocLen {
+		doc = doc[:maxCodegraphDocLen] + "..."
+	}
+	return "\t// " + doc
+}
+
 func (t *ExploreTools) makeCodegraphPathRelative(path string) string {
 	if path == "" {
 		return ""
@@ -1034,6 +2283,12 @@ func sanitizeCodegraphSignature(sig string) string {
 }
 
 func (t *ExploreTools) executeCodegraphResolve(ctx context.Context, params CodegraphParams) (string, error) {
+	if len(params.Names) > 0 {
+		if params.Name != "" {
+			return "Error: pass name or names to resolve, not both.", nil
+		}
+		return t.executeCodegraphResolveBatch(ctx, params)
+	}
 	if params.Name == "" {
 		hint := ""
 		if params.QName != "" {
@@ -1049,17 +2304,114 @@ func (t *ExploreTools) executeCodegraphResolve(ctx context.Context, params Codeg
 			"to_name", params.ToName, "from_name", params.FromName)
 	}
 
-	symbol, err := t.resolveSymbol(ctx, params.Name, params.Kind, params.File)
+	symbol, err := t.resolveSymbolMatching(ctx, params.Name, params.Kind, params.File, params.nameMatch())
 	if err != nil {
 		return t.formatResolveError(params.Name, params.Kind, params.File, err), nil
 	}
 
 	symbol.Kind = normalizeCodegraphKind(symbol.Kind)
-	if symbol.Kind != "" && !isSupportedCodegraphKind(symbol.Kind) {
-		return fmt.Sprintf("Error: resolved kind %q is unsupported. Supported kinds: function, method, struct, interface, class.", symbol.Kind), nil
+	if symbol.Kind != "" && !t.isSupportedCodegraphKind(symbol.Kind) {
+		return fmt.Sprintf("Error: resolved kind %q is unsupported. Supported kinds: %s.", symbol.Kind, t.codegraphKindList()), nil
 	}
 
-	return t.formatCodegraphLine(symbol.Filepath, symbol.Pos, symbol.Kind, symbol.QName, symbol.Signature), nil
+	line := t.formatCodegraphLine(symbol.Filepath, symbol.Pos, symbol.Kind, symbol.QName, symbol.Signature)
+	if params.IncludeDoc {
+		line += formatCodegraphDoc(symbol.Doc)
+	}
+	return line, nil
+}
+
+// executeCodegraphResolveBatch resolves params.Names with one graph query
+// and lists one line per name. A name falls back to its own resolve only
+// when the *name* retry or context_file could still settle it.
+func (t *ExploreTools) executeCodegraphResolveBatch(ctx context.Context, params CodegraphParams) (string, error) {
+	var names []string
+	seen := make(map[string]bool, len(params.Names))
+	for _, name := range params.Names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "Error: names must contain at least one non-empty name.", nil
+	}
+	if len(names) > maxResolveBatch {
+		return fmt.Sprintf("Error: too many names (%d). Resolve at most %d per call.", len(names), maxResolveBatch), nil
+	}
+
+	match := params.nameMatch()
+	opts := make([]arangodb.SearchOptions, len(names))
+	for i, name := range names {
+		opts[i] = arangodb.SearchOptions{
+			Name:            name,
+			Kind:            params.Kind,
+			File:            params.File,
+			Exact:           match.exact,
+			CaseInsensitive: match.caseInsensitive,
+		}
+	}
+	results, err := t.arango.ResolveSymbols(ctx, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "codegraph batch resolve failed", "names", len(names), "error", err)
+		return formatCodegraphError("resolving names", err), nil
+	}
+
+	_, hasContextFile := ctx.Value(contextFileKey{}).(string)
+	var sb strings.Builder
+	resolved := 0
+	for i, name := range names {
+		symbol, err := results[i].Symbol, results[i].Err
+		var amb arangodb.AmbiguousSymbolError
+		retryWildcard := errors.Is(err, arangodb.ErrNotFound) && !match.exact && !strings.Contains(name, "*")
+		if retryWildcard || (errors.As(err, &amb) && hasContextFile) {
+			symbol, err = t.resolveSymbolMatching(ctx, name, params.Kind, params.File, match)
+		}
+
+		line, ok := t.formatResolveBatchLine(symbol, err)
+		if ok {
+			resolved++
+		}
+		sb.WriteString(name + "\t" + line + "\n")
+	}
+
+	return fmt.Sprintf("Resolved %d of %d names:\n%s", resolved, len(names), strings.TrimRight(sb.String(), "\n")), nil
+}
+
+// formatResolveBatchLine renders one batch resolve outcome: the symbol's
+// location, kind and qname, or a marker saying why there is none. ok is
+// false for a marker.
+func (t *ExploreTools) formatResolveBatchLine(symbol arangodb.ResolvedSymbol, err error) (line string, ok bool) {
+	var amb arangodb.AmbiguousSymbolError
+	var broad wildcardTooBroadError
+	switch {
+	case err == nil:
+		kind := normalizeCodegraphKind(symbol.Kind)
+		if kind != "" && !t.isSupportedCodegraphKind(kind) {
+			return fmt.Sprintf("UNSUPPORTED kind %q (%s)", kind, symbol.QName), false
+		}
+		return t.formatCodegraphLine(symbol.Filepath, symbol.Pos, kind, symbol.QName, ""), true
+	case errors.As(err, &amb):
+		var qnames []string
+		for _, c := range amb.Candidates {
+			if t.isSupportedCodegraphKind(normalizeCodegraphKind(c.Kind)) {
+				qnames = append(qnames, c.QName)
+			}
+		}
+		more := ""
+		if amb.Total > len(amb.Candidates) {
+			more = ", ..."
+		}
+		return fmt.Sprintf("AMBIGUOUS (%d matches: %s%s)", amb.Total, strings.Join(qnames, ", "), more), false
+	case errors.As(err, &broad):
+		return fmt.Sprintf("NOT FOUND (*%s* is too broad: %d matches)", broad.Name, broad.Total), false
+	case errors.Is(err, arangodb.ErrNotFound):
+		return "NOT FOUND", false
+	default:
+		return fmt.Sprintf("ERROR (%s)", err), false
+	}
 }
 
 func (t *ExploreTools) executeCodegraphFileSymbols(ctx context.Context, params CodegraphParams) (string, error) {
@@ -1070,24 +2422,117 @@ func (t *ExploreTools) executeCodegraphFileSymbols(ctx context.Context, params C
 	symbols, err := t.arango.GetFileSymbols(ctx, arangodb.FileSymbolsOptions{Filepath: params.File, Kind: params.Kind})
 	if err != nil {
 		slog.ErrorContext(ctx, "codegraph file_symbols failed", "file", params.File, "error", err)
-		return fmt.Sprintf("Error querying file symbols: %s", err), nil
+		return formatCodegraphError("querying file symbols", err), nil
 	}
 
-	filtered := make([]arangodb.FileSymbol, 0, len(symbols))
+	filtered := t.supportedFileSymbols(symbols, "")
+	if len(filtered) == 0 {
+		return fmt.Sprintf("No supported symbols found in %s.", params.File), nil
+	}
+
+	display := filtered
+	truncated := false
+	if len(display) > maxFileSymbolsResults {
+		display = display[:maxFileSymbolsResults]
+		truncated = true
+	}
+
+	var sb strings.Builder
+	if params.Outline {
+		sb.WriteString(fmt.Sprintf("Outline of %s:\n", params.File))
+		t.writeFileOutline(&sb, params, display)
+	} else {
+		sb.WriteString(fmt.Sprintf("Symbols in %s:\n", params.File))
+		for _, s := range display {
+			t.writeFileSymbolLine(&sb, params, "", s)
+		}
+	}
+	if truncated {
+		sb.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d. Use kind filter to narrow.]", len(display), len(filtered))) + "\n")
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func (t *ExploreTools) writeFileSymbolLine(sb *strings.Builder, params CodegraphParams, indent string, s arangodb.FileSymbol) {
+	sb.WriteString(indent + t.formatCodegraphLine(params.File, s.Pos, s.Kind, s.QName, s.Signature))
+	if params.IncludeDoc {
+		sb.WriteString(formatCodegraphDoc(s.Doc))
+	}
+	sb.WriteString("\n")
+}
+
+// writeFileOutline lists the file's types with their methods (and fields)
+// indented below them, then members of types declared in other files, then
+// standalone functions. symbols are in file order.
+func (t *ExploreTools) writeFileOutline(sb *strings.Builder, params CodegraphParams, symbols []arangodb.FileSymbol) {
+	inFile := make(map[string]bool, len(symbols))
 	for _, s := range symbols {
-		kind := normalizeCodegraphKind(s.Kind)
-		if !isSupportedCodegraphKind(kind) {
+		inFile[s.QName] = true
+	}
+
+	children := make(map[string][]arangodb.FileSymbol)
+	var foreignParents []string // Parents declared elsewhere, in order of first member
+	var functions []arangodb.FileSymbol
+	for _, s := range symbols {
+		switch {
+		case s.ParentQName != "":
+			if !inFile[s.ParentQName] && len(children[s.ParentQName]) == 0 {
+				foreignParents = append(foreignParents, s.ParentQName)
+			}
+			children[s.ParentQName] = append(children[s.ParentQName], s)
+		case s.Kind == "function":
+			functions = append(functions, s)
+		}
+	}
+
+	for _, s := range symbols {
+		if s.ParentQName != "" || s.Kind == "function" {
 			continue
 		}
-		s.Kind = kind
-		filtered = append(filtered, s)
+		t.writeFileSymbolLine(sb, params, "", s)
+		for _, c := range children[s.QName] {
+			t.writeFileSymbolLine(sb, params, "  ", c)
+		}
+	}
+	for _, parent := range foreignParents {
+		sb.WriteString(fmt.Sprintf("%s (declared in another file)\n", parent))
+		for _, c := range children[parent] {
+			t.writeFileSymbolLine(sb, params, "  ", c)
+		}
+	}
+	if len(functions) > 0 {
+		sb.WriteString("\nFunctions:\n")
+		for _, s := range functions {
+			t.writeFileSymbolLine(sb, params, "", s)
+		}
 	}
+}
 
-	if len(filtered) == 0 {
-		return fmt.Sprintf("No supported symbols found in %s.", params.File), nil
+// executeCodegraphSiblings resolves a symbol and lists the other symbols
+// defined in the same file.
+func (t *ExploreTools) executeCodegraphSiblings(ctx context.Context, params CodegraphParams) (string, error) {
+	symbol, errMsg := t.resolveSymbolWithFile(ctx, params)
+	if errMsg != "" {
+		return errMsg, nil
+	}
+	if symbol.Filepath == "" {
+		return fmt.Sprintf("Error: no file recorded for %s.", symbol.QName), nil
 	}
 
-	display := filtered
+	symbols, err := t.arango.GetFileSymbols(ctx, arangodb.FileSymbolsOptions{Filepath: symbol.Filepath})
+	if err != nil {
+		slog.ErrorContext(ctx, "codegraph siblings failed", "qname", symbol.QName, "file", symbol.Filepath, "error", err)
+		return formatCodegraphError("querying file symbols", err), nil
+	}
+
+	file := t.makeCodegraphPathRelative(symbol.Filepath)
+	siblings := t.supportedFileSymbols(symbols, symbol.QName)
+	if len(siblings) == 0 {
+		return fmt.Sprintf("No other symbols in %s besides %s.", file, symbol.QName), nil
+	}
+
+	display := siblings
 	truncated := false
 	if len(display) > maxFileSymbolsResults {
 		display = display[:maxFileSymbolsResults]
@@ -1095,18 +2540,206 @@ func (t *ExploreTools) executeCodegraphFileSymbols(ctx context.Context, params C
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Symbols in %s:\n", params.File))
+	sb.WriteString(fmt.Sprintf("Siblings of %s in %s:\n", symbol.QName, file))
 	for _, s := range display {
-		sb.WriteString(t.formatCodegraphLine(params.File, s.Pos, s.Kind, s.QName, s.Signature))
+		sb.WriteString(t.formatCodegraphLine(symbol.Filepath, s.Pos, s.Kind, s.QName, s.Signature))
+		sb.WriteString("\n")
+	}
+	if truncated {
+		sb.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d. Use file_symbols with a kind filter to narrow.]", len(display), len(siblings))) + "\n")
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// executeCodegraphEntrypoints lists main and init functions from the graph and
+// HTTP route registrations found by scanning Go sources.
+func (t *ExploreTools) executeCodegraphEntrypoints(ctx context.Context, params CodegraphParams) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("Entrypoints:\n")
+
+	for _, name := range []string{"main", "init"} {
+		results, _, err := t.arango.SearchSymbols(ctx, arangodb.SearchOptions{
+			Name: name,
+			Kind: "function",
+			File: params.File,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "codegraph entrypoints failed", "name", name, "error", err)
+			return formatCodegraphError("searching symbols", err), nil
+		}
+
+		var funcs []arangodb.SearchResult
+		for _, r := range results {
+			if r.Name == name {
+				funcs = append(funcs, r)
+			}
+		}
+		sort.Slice(funcs, func(i, j int) bool { return funcs[i].Filepath < funcs[j].Filepath })
+
+		sb.WriteString(fmt.Sprintf("\n%s functions (%d):\n", name, len(funcs)))
+		for i, r := range funcs {
+			if i == maxEntrypointResults {
+				sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d. Pass file to narrow.]", maxEntrypointResults, len(funcs))) + "\n")
+				break
+			}
+			sb.WriteString(t.formatCodegraphLine(r.Filepath, r.Pos, r.Kind, r.QName, r.Signature))
+			sb.WriteString("\n")
+		}
+	}
+
+	routes, truncated := t.findRouteRegistrations(ctx)
+	sb.WriteString(fmt.Sprintf("\nHTTP route registrations (%d, heuristic):\n", len(routes)))
+	for _, r := range routes {
+		sb.WriteString(r)
 		sb.WriteString("\n")
 	}
 	if truncated {
-		sb.WriteString(fmt.Sprintf("\n[Showing %d of %d. Use kind filter to narrow.]\n", len(display), len(filtered)))
+		sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing %d routes. grep the router package for the rest.]", maxEntrypointResults)) + "\n")
 	}
 
 	return strings.TrimSpace(sb.String()), nil
 }
 
+// routeRegistrationPattern matches router calls with a literal path, e.g.
+// mux.HandleFunc("GET /users", h), r.POST("/webhooks", h), g.Group("/api").
+var routeRegistrationPattern = regexp.MustCompile(`\.(HandleFunc|Handle|Group|Route|Any|GET|POST|PUT|PATCH|DELETE|Get|Post|Put|Patch|Delete)\(\s*"((?:[A-Z]+ )?/[^"]*)"`)
+
+// findRouteRegistrations scans non-test Go files for route registrations and
+// returns them as "file:line\tMETHOD path\tsource" lines.
+func (t *ExploreTools) findRouteRegistrations(ctx context.Context) ([]string, bool) {
+	var routes []string
+	truncated := false
+	isGoSource := func(relPath string) bool {
+		return strings.HasSuffix(relPath, ".go") && !strings.HasSuffix(relPath, "_test.go") && !shouldSkipFile(relPath)
+	}
+	t.walkRepoFiles(ctx, isGoSource, func(relPath string, data []byte) bool {
+		for i, line := range strings.Split(string(data), "\n") {
+			m := routeRegistrationPattern.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			if len(routes) == maxEntrypointResults {
+				truncated = true
+				return false
+			}
+			method, route := routeMethod(m[1]), m[2]
+			if verb, p, ok := strings.Cut(route, " "); ok {
+				method, route = verb, p
+			}
+			routes = append(routes, fmt.Sprintf("%s:%d\t%s %s\t%s", relPath, i+1, method, route, strings.TrimSpace(line)))
+		}
+		return true
+	})
+	return routes, truncated
+}
+
+// walkRepoFiles calls visit with the contents of every file under the repo
+// root for which include(relPath) is true, skipping hidden and vendored
+// directories. visit returns false to stop the walk. The walk is bounded by
+// the bash timeout.
+func (t *ExploreTools) walkRepoFiles(ctx context.Context, include func(relPath string) bool, visit func(relPath string, data []byte) bool) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Bash)
+	defer cancel()
+
+	_ = filepath.WalkDir(t.repoRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if timeoutCtx.Err() != nil {
+			return timeoutCtx.Err()
+		}
+		relPath, relErr := filepath.Rel(t.repoRoot, path)
+		if relErr != nil {
+			return nil
+		}
+		if d.IsDir() {
+			sep := string(filepath.Separator)
+			if path != t.repoRoot && shouldSkipFile(sep+relPath+sep) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !include(relPath) {
+			return nil
+		}
+
+		data, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return nil
+		}
+		if !visit(relPath, data) {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+}
+
+// routeMethod maps a router function name to an HTTP method; registrations
+// that accept any method (HandleFunc, Group) are "*".
+func routeMethod(fn string) string {
+	switch fn {
+	case "GET", "POST", "PUT", "PATCH", "DELETE":
+		return fn
+	case "Get", "Post", "Put", "Patch", "Delete":
+		return strings.ToUpper(fn)
+	default:
+		return "*"
+	}
+}
+
+// resolveSymbolWithFile resolves params to a single symbol including its file.
+// With qname, the symbol is looked up by its short name and filtered on the
+// exact qname in the query, so a common short name can't push it past the
+// search result cap.
+func (t *ExploreTools) resolveSymbolWithFile(ctx context.Context, params CodegraphParams) (arangodb.ResolvedSymbol, string) {
+	if params.QName != "" {
+		shortName := params.QName
+		if idx := strings.LastIndex(shortName, "."); idx >= 0 {
+			shortName = shortName[idx+1:]
+		}
+		results, _, err := t.arango.SearchSymbols(ctx, arangodb.SearchOptions{Name: shortName, Exact: true, QName: params.QName, Kind: params.Kind, File: params.File})
+		if err != nil {
+			slog.ErrorContext(ctx, "codegraph search failed", "qname", params.QName, "error", err)
+			return arangodb.ResolvedSymbol{}, formatCodegraphError("searching symbols", err)
+		}
+		for _, r := range results {
+			if r.QName == params.QName {
+				return arangodb.ResolvedSymbol{QName: r.QName, Name: r.Name, Kind: r.Kind, Filepath: r.Filepath, Pos: r.Pos, Signature: r.Signature, Doc: r.Doc}, ""
+			}
+		}
+		return arangodb.ResolvedSymbol{}, fmt.Sprintf("Error: no symbol found with qname %q.", params.QName)
+	}
+
+	name := strings.TrimSpace(params.Name)
+	if name == "" {
+		return arangodb.ResolvedSymbol{}, "Error: siblings requires qname or name."
+	}
+	symbol, err := t.resolveSymbol(ctx, name, params.Kind, params.File)
+	if err != nil {
+		return arangodb.ResolvedSymbol{}, t.formatResolveError(name, params.Kind, params.File, err)
+	}
+	return symbol, ""
+}
+
+// supportedFileSymbols normalizes kinds, drops unsupported ones and the
+// excluded qname (if any).
+func (t *ExploreTools) supportedFileSymbols(symbols []arangodb.FileSymbol, excludeQName string) []arangodb.FileSymbol {
+	filtered := make([]arangodb.FileSymbol, 0, len(symbols))
+	for _, s := range symbols {
+		if excludeQName != "" && s.QName == excludeQName {
+			continue
+		}
+		kind := normalizeCodegraphKind(s.Kind)
+		if !t.isSupportedCodegraphKind(kind) {
+			continue
+		}
+		s.Kind = kind
+		filtered = append(filtered, s)
+	}
+	return filtered
+}
+
 func (t *ExploreTools) resolveQNameForOperation(ctx context.Context, operation string, params CodegraphParams) (string, string) {
 	if params.QName != "" {
 		return params.QName, ""
@@ -1214,38 +2847,111 @@ func (t *ExploreTools) resolveSymbolForKinds(ctx context.Context, name, file str
 }
 
 func (t *ExploreTools) resolveSymbol(ctx context.Context, name, kind, file string) (arangodb.ResolvedSymbol, error) {
+	return t.resolveSymbolMatching(ctx, name, kind, file, defaultNameMatch)
+}
+
+// resolveSymbolMatching is resolveSymbol with explicit name matching.
+func (t *ExploreTools) resolveSymbolMatching(ctx context.Context, name, kind, file string, match nameMatch) (arangodb.ResolvedSymbol, error) {
 	name = strings.TrimSpace(name)
 	if name == "" {
 		return arangodb.ResolvedSymbol{}, fmt.Errorf("name is required")
 	}
 
 	opts := arangodb.SearchOptions{
-		Name: name,
-		Kind: kind,
-		File: file,
+		Name:            name,
+		Kind:            kind,
+		File:            file,
+		Exact:           match.exact,
+		CaseInsensitive: match.caseInsensitive,
 	}
 	symbol, err := t.arango.ResolveSymbol(ctx, opts)
 	if err == nil {
 		return symbol, nil
 	}
 
-	if errors.Is(err, arangodb.ErrNotFound) && !strings.Contains(name, "*") {
+	if errors.Is(err, arangodb.ErrNotFound) && !match.exact && !strings.Contains(name, "*") {
 		opts.Name = "*" + name + "*"
 		symbol, err2 := t.arango.ResolveSymbol(ctx, opts)
 		if err2 == nil {
 			return symbol, nil
 		}
+		var amb arangodb.AmbiguousSymbolError
+		if errors.As(err2, &amb) && amb.Total > t.wildcardMaxCandidates {
+			return arangodb.ResolvedSymbol{}, wildcardTooBroadError{Name: name, Total: amb.Total}
+		}
 		err = err2
 	}
 
+	var amb arangodb.AmbiguousSymbolError
+	if errors.As(err, &amb) {
+		if symbol, ok := t.preferContextFile(ctx, opts); ok {
+			return symbol, nil
+		}
+	}
 	return arangodb.ResolvedSymbol{}, err
 }
 
+// contextFileKey carries the codegraph call's context_file through the
+// context, so every name resolution in the call can use it.
+type contextFileKey struct{}
+
+// preferContextFile settles an ambiguous resolution with the call's
+// context_file: the single candidate in that file, else the single one in
+// its directory (a Go package). ok is false when there is no context file,
+// the candidate list was cut off (uniqueness can't be told), or neither
+// rule picks exactly one symbol.
+func (t *ExploreTools) preferContextFile(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, bool) {
+	contextFile, _ := ctx.Value(contextFileKey{}).(string)
+	if contextFile == "" {
+		return arangodb.ResolvedSymbol{}, false
+	}
+	contextFile = filepath.Clean(t.makeCodegraphPathRelative(contextFile))
+
+	results, total, err := t.arango.SearchSymbols(ctx, opts)
+	if err != nil || total > len(results) {
+		return arangodb.ResolvedSymbol{}, false
+	}
+
+	var inFile, inDir []arangodb.SearchResult
+	for _, r := range results {
+		path := filepath.Clean(t.makeCodegraphPathRelative(r.Filepath))
+		if path == contextFile {
+			inFile = append(inFile, r)
+		}
+		if filepath.Dir(path) == filepath.Dir(contextFile) {
+			inDir = append(inDir, r)
+		}
+	}
+	for _, matches := range [][]arangodb.SearchResult{inFile, inDir} {
+		if len(matches) == 1 {
+			r := matches[0]
+			return arangodb.ResolvedSymbol{QName: r.QName, Name: r.Name, Kind: r.Kind, Filepath: r.Filepath, Pos: r.Pos, Signature: r.Signature, Doc: r.Doc}, true
+		}
+	}
+	return arangodb.ResolvedSymbol{}, false
+}
+
+// wildcardTooBroadError means an exact lookup found nothing and the *name*
+// retry matched too many symbols to be worth listing.
+type wildcardTooBroadError struct {
+	Name  string
+	Total int
+}
+
+func (e wildcardTooBroadError) Error() string {
+	return fmt.Sprintf("%d symbols match *%s*", e.Total, e.Name)
+}
+
 func (t *ExploreTools) formatResolveError(name, kind, file string, err error) string {
 	var amb arangodb.AmbiguousSymbolError
 	if errors.As(err, &amb) {
 		return t.formatAmbiguousSymbolError(amb)
 	}
+	var broad wildcardTooBroadError
+	if errors.As(err, &broad) {
+		return fmt.Sprintf("Error: no symbol named %q, and *%s* is too broad (%d matches). Add kind/file, use a longer name, or pass qname.",
+			broad.Name, broad.Name, broad.Total)
+	}
 	if errors.Is(err, arangodb.ErrNotFound) {
 		var sb strings.Builder
 		sb.WriteString(fmt.Sprintf("Error: no symbol found matching %q", name))
@@ -1258,7 +2964,20 @@ func (t *ExploreTools) formatResolveError(name, kind, file string, err error) st
 		sb.WriteString(".")
 		return sb.String()
 	}
-	return fmt.Sprintf("Error resolving %q: %s", name, err)
+	return formatCodegraphError(fmt.Sprintf("resolving %q", name), err)
+}
+
+// formatCodegraphError reports a failed codegraph call. When the graph is
+// down or too slow, it says so, so the agent falls back to grep instead of
+// retrying or concluding the symbol doesn't exist.
+func formatCodegraphError(action string, err error) string {
+	switch {
+	case errors.Is(err, arangodb.ErrUnavailable):
+		return fmt.Sprintf("Error %s: the code graph is unavailable (%s). Use grep and read for now.", action, err)
+	case errors.Is(err, arangodb.ErrTimeout):
+		return fmt.Sprintf("Error %s: the code graph query timed out (%s). Narrow it (lower depth, add kind/file) or use grep.", action, err)
+	}
+	return fmt.Sprintf("Error %s: %s", action, err)
 }
 
 func (t *ExploreTools) formatAmbiguousSymbolError(err arangodb.AmbiguousSymbolError) string {
@@ -1266,7 +2985,7 @@ func (t *ExploreTools) formatAmbiguousSymbolError(err arangodb.AmbiguousSymbolEr
 	sb.WriteString(fmt.Sprintf("Error: ambiguous symbol %q. Candidates:\n", err.Query))
 	for _, c := range err.Candidates {
 		kind := normalizeCodegraphKind(c.Kind)
-		if !isSupportedCodegraphKind(kind) {
+		if !t.isSupportedCodegraphKind(kind) {
 			continue
 		}
 		sb.WriteString(t.formatCodegraphLine(c.Filepath, c.Pos, kind, c.QName, c.Signature))
@@ -1294,32 +3013,76 @@ func (t *ExploreTools) executeCodegraphTrace(ctx context.Context, params Codegra
 		return errMsg, nil
 	}
 
-	path, err := t.arango.FindCallPath(ctx, fromQName, toQName, maxDepth)
+	maxPaths := min(max(params.MaxPaths, 1), maxTracePaths)
+
+	result, err := t.arango.FindCallPaths(ctx, fromQName, toQName, arangodb.CallPathOptions{
+		MaxDepth:     maxDepth,
+		MaxPaths:     maxPaths,
+		IncludeAsync: params.IncludeAsync,
+	})
 	if err != nil {
 		slog.ErrorContext(ctx, "codegraph trace failed", "from", fromQName, "to", toQName, "error", err)
-		return fmt.Sprintf("Error tracing call path: %s", err), nil
+		return formatCodegraphError("tracing call path", err), nil
 	}
-	if len(path) == 0 {
-		return t.formatTraceNotFound(fromQName, toQName, maxDepth), nil
+	if len(result.Paths) == 0 {
+		return t.formatTraceNotFound(fromQName, toQName, maxDepth, result), nil
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Trace path from %s to %s (max_depth=%d) - %d step(s):\n", fromQName, toQName, maxDepth, len(path)))
+	if len(result.Paths) == 1 {
+		path := result.Paths[0]
+		sb.WriteString(fmt.Sprintf("Trace path from %s to %s (max_depth=%d) - %d step(s):\n", fromQName, toQName, maxDepth, len(path)))
+		t.writeTracePath(&sb, path)
+		return strings.TrimSpace(sb.String()), nil
+	}
+
+	sb.WriteString(fmt.Sprintf("Trace paths from %s to %s (max_depth=%d) - %d path(s), shortest first:\n", fromQName, toQName, maxDepth, len(result.Paths)))
+	for i, path := range result.Paths {
+		sb.WriteString(fmt.Sprintf("\nPath %d (%d step(s)):\n", i+1, len(path)))
+		t.writeTracePath(&sb, path)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// writeTracePath writes one line per function on path. A queue vertex,
+// which only include_async paths go through, is written as an async
+// boundary marker between the enqueuing and the dequeuing function.
+func (t *ExploreTools) writeTracePath(sb *strings.Builder, path []arangodb.GraphNode) {
 	for _, node := range path {
 		if node.QName == "" {
 			continue
 		}
+		if node.Kind == "queue" {
+			sb.WriteString(fmt.Sprintf("~~ async boundary: enqueued on %s, dequeued by the next function ~~\n", node.QName))
+			continue
+		}
 		kind := normalizeCodegraphKind(node.Kind)
 		sb.WriteString(t.formatCodegraphLine(node.Filepath, node.Pos, kind, node.QName, node.Signature))
 		sb.WriteString("\n")
 	}
-	return strings.TrimSpace(sb.String()), nil
 }
 
-// formatTraceNotFound generates an actionable error message when trace finds no path.
-func (t *ExploreTools) formatTraceNotFound(fromQName, toQName string, maxDepth int) string {
-	return fmt.Sprintf("No direct call path found from %s to %s (max_depth=%d). Try callers/callees + grep.",
-		fromQName, toQName, maxDepth)
+// formatTraceNotFound generates an actionable error message when trace finds
+// no path, showing how far the closest partial path got.
+func (t *ExploreTools) formatTraceNotFound(fromQName, toQName string, maxDepth int, result arangodb.CallPaths) string {
+	msg := fmt.Sprintf("No direct call path found from %s to %s (max_depth=%d).", fromQName, toQName, maxDepth)
+	partial := result.Partial
+	if len(partial) == 0 {
+		return msg + " Try callers/callees + grep."
+	}
+
+	last := partial[len(partial)-1].QName
+	var sb strings.Builder
+	sb.WriteString(msg + "\n")
+	if result.PartialGap > 0 {
+		sb.WriteString(fmt.Sprintf("Closest partial path reaches %s, which calls %s in %d more step(s); retry with max_depth=%d:\n",
+			last, toQName, result.PartialGap, min(len(partial)-1+result.PartialGap, maxTraceDepth)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Closest partial path reached %s, no call edge to %s:\n", last, toQName))
+	}
+	t.writeTracePath(&sb, partial)
+	sb.WriteString("Continue from there with callees/callers + grep (the link may be an interface call, callback or goroutine).")
+	return sb.String()
 }
 
 func (t *ExploreTools) resolveTraceEndpointQName(ctx context.Context, label, name, qname, kind, file string) (string, string) {
@@ -0,0 +1,333 @@
+package brain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"basegraph.co/relay/common/llm"
+)
+
+const maxAstGrepFileBytes = 1 << 20
+
+// AstGrepParams for structural code search.
+type AstGrepParams struct {
+	Pattern string `json:"pattern" jsonschema:"required,description=Code pattern with metavariables, e.g. 'if err != nil { return nil, $ERR }'. $NAME matches one node, $$$ matches any number."`
+	Lang    string `json:"lang,omitempty" jsonschema:"description=Language of the pattern (go, python, typescript, ...). Inferred from file extensions if omitted."`
+	Path    string `json:"path,omitempty" jsonschema:"description=File or directory to search. Defaults to repo root."`
+}
+
+// astGrepExtensions maps the languages the fallback understands to their
+// file extensions. Keys follow ast-grep's --lang names and aliases.
+var astGrepExtensions = map[string][]string{
+	"go":         {".go"},
+	"python":     {".py"},
+	"py":         {".py"},
+	"javascript": {".js", ".jsx", ".mjs", ".cjs"},
+	"js":         {".js", ".jsx", ".mjs", ".cjs"},
+	"typescript": {".ts"},
+	"ts":         {".ts"},
+	"tsx":        {".tsx"},
+	"java":       {".java"},
+	"rust":       {".rs"},
+	"rs":         {".rs"},
+	"ruby":       {".rb"},
+	"rb":         {".rb"},
+	"c":          {".c", ".h"},
+	"cpp":        {".cc", ".cpp", ".cxx", ".hpp", ".hh"},
+	"kotlin":     {".kt"},
+	"php":        {".php"},
+}
+
+// astGrepMetavar matches ast-grep metavariables: $$$, $$$ARGS, $X, $_.
+var astGrepMetavar = regexp.MustCompile(`\$\$\$[A-Z0-9_]*|\$[A-Z_][A-Z0-9_]*`)
+
+// astGrepMatch is one line of `ast-grep run --json=stream` output.
+type astGrepMatch struct {
+	File  string `json:"file"`
+	Lines string `json:"lines"`
+	Range struct {
+		Start struct {
+			Line int `json:"line"` // 0-based
+		} `json:"start"`
+	} `json:"range"`
+}
+
+func astGrepToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "ast_grep",
+		Description: `Structural code search: match code by syntax tree, not text. Whitespace, line breaks
+and formatting don't matter.
+
+Metavariables: $NAME matches one expression/identifier, $$$ matches any number of nodes
+(arguments, statements).
+
+Examples:
+  ast_grep(pattern="if err != nil { return nil, $ERR }", lang="go")
+  ast_grep(pattern="$DB.Exec($$$)", lang="go", path="internal/store")
+  ast_grep(pattern="console.log($$$)", lang="typescript")
+
+Returns file:line matches. Use grep for plain text, codegraph for symbol relationships.`,
+		Parameters: llm.GenerateSchemaFrom(AstGrepParams{}),
+	}
+}
+
+// executeAstGrep runs ast-grep, falling back to an approximate regex match
+// over find's file list when ast-grep isn't installed.
+func (t *ExploreTools) executeAstGrep(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[AstGrepParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse ast_grep params: %w", err)
+	}
+
+	pattern := strings.TrimSpace(params.Pattern)
+	if pattern == "" {
+		return "Error: pattern is required", nil
+	}
+	lang := strings.ToLower(strings.TrimSpace(params.Lang))
+	if lang != "" {
+		if _, ok := astGrepExtensions[lang]; !ok {
+			if _, err := exec.LookPath("ast-grep"); err != nil {
+				return fmt.Sprintf("Error: unsupported lang %q", params.Lang), nil
+			}
+		}
+	}
+
+	searchPath := t.repoRoot
+	if params.Path != "" {
+		searchPath = filepath.Join(t.repoRoot, params.Path)
+	}
+	if !pathWithinRoot(t.repoRoot, searchPath) {
+		return "Error: path outside repository", nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Bash)
+	defer cancel()
+
+	approximate := false
+	lines, err := t.runAstGrep(timeoutCtx, pattern, lang, searchPath)
+	if errors.Is(err, exec.ErrNotFound) {
+		lines, err = t.fallbackAstGrep(timeoutCtx, pattern, lang, searchPath)
+		approximate = true
+	}
+	if timeoutCtx.Err() == context.DeadlineExceeded {
+		return "Search timed out. Use more specific pattern or path.", nil
+	}
+	if err != nil {
+		return fmt.Sprintf("Error: ast_grep failed: %s", err), nil
+	}
+	if len(lines) == 0 {
+		return fmt.Sprintf("No matches for pattern: %s", pattern), nil
+	}
+
+	truncated := len(lines) > maxGrepMatches
+	if truncated {
+		lines = lines[:maxGrepMatches]
+	}
+
+	var result strings.Builder
+	if approximate {
+		result.WriteString("(ast-grep not installed; approximate textual match)\n")
+	}
+	for _, line := range lines {
+		result.WriteString(line)
+		result.WriteString("\n")
+	}
+	if truncated {
+		result.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d matches. Add lang/path or refine pattern.]", maxGrepMatches)))
+	}
+
+	return withTokenEstimate(result.String()), nil
+}
+
+// runAstGrep returns up to maxGrepMatches+1 "path:line:text" lines from
+// ast-grep. The error wraps exec.ErrNotFound when the binary is missing.
+func (t *ExploreTools) runAstGrep(ctx context.Context, pattern, lang, searchPath string) ([]string, error) {
+	args := []string{"run", "--pattern", pattern, "--json=stream"}
+	if lang != "" {
+		args = append(args, "--lang", lang)
+	}
+	args = append(args, searchPath)
+
+	cmd := exec.CommandContext(ctx, "ast-grep", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(bytes.TrimSpace(output)) == 0 && stderr.Len() == 0:
+			return nil, nil // No matches
+		case errors.As(err, &exitErr):
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, errors.New(msg)
+			}
+			return nil, err
+		default:
+			return nil, err
+		}
+	}
+
+	// ast-grep reports paths relative to the searched directory, or the
+	// file itself when searchPath is a file.
+	baseDir, baseFile := searchPath, ""
+	if info, err := os.Stat(searchPath); err == nil && !info.IsDir() {
+		baseFile = searchPath
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxAstGrepFileBytes)
+	for scanner.Scan() && len(lines) <= maxGrepMatches {
+		var m astGrepMatch
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			continue
+		}
+		path := m.File
+		switch {
+		case baseFile != "":
+			path = baseFile
+		case !filepath.IsAbs(path):
+			path = filepath.Join(baseDir, path)
+		}
+		relPath, err := filepath.Rel(t.repoRoot, path)
+		if err != nil {
+			relPath = m.File
+		}
+		first, _, _ := strings.Cut(m.Lines, "\n")
+		lines = append(lines, fmt.Sprintf("%s:%d:%s", filepath.ToSlash(relPath), m.Range.Start.Line+1, strings.TrimSpace(first)))
+	}
+	return lines, nil
+}
+
+// fallbackAstGrep approximates ast-grep with a whitespace-tolerant regex over
+// the files find lists for the language. Metavariables become wildcards, so
+// it can over-match nested code; results are flagged as approximate.
+func (t *ExploreTools) fallbackAstGrep(ctx context.Context, pattern, lang, searchPath string) ([]string, error) {
+	re, err := astGrepPatternRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern: %w", err)
+	}
+
+	exts := astGrepExtensions[lang]
+	if lang == "" {
+		seen := map[string]bool{}
+		for _, langExts := range astGrepExtensions {
+			for _, ext := range langExts {
+				if !seen[ext] {
+					seen[ext] = true
+					exts = append(exts, ext)
+				}
+			}
+		}
+		sort.Strings(exts)
+	}
+
+	findArgs := []string{searchPath, "-type", "f", "("}
+	for i, ext := range exts {
+		if i > 0 {
+			findArgs = append(findArgs, "-o")
+		}
+		findArgs = append(findArgs, "-name", "*"+ext)
+	}
+	findArgs = append(findArgs, ")",
+		"-not", "-path", "*/.git/*",
+		"-not", "-path", "*/node_modules/*",
+		"-not", "-path", "*/vendor/*",
+	)
+	output, err := exec.CommandContext(ctx, "find", findArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("find: %w", err)
+	}
+
+	files := strings.Split(strings.TrimSpace(string(output)), "\n")
+	sort.Strings(files)
+
+	var lines []string
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		relPath, err := filepath.Rel(t.repoRoot, file)
+		if err != nil || shouldSkipFile(relPath) {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil || info.Size() > maxAstGrepFileBytes {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, loc := range re.FindAllIndex(content, -1) {
+			lineNo := bytes.Count(content[:loc[0]], []byte("\n")) + 1
+			first, _, _ := strings.Cut(string(content[loc[0]:loc[1]]), "\n")
+			lines = append(lines, fmt.Sprintf("%s:%d:%s", filepath.ToSlash(relPath), lineNo, strings.TrimSpace(first)))
+			if len(lines) > maxGrepMatches {
+				return lines, nil
+			}
+		}
+	}
+	return lines, nil
+}
+
+// astGrepPatternRegexp converts an ast-grep pattern into a regex: $$$ spans
+// anything, $NAME spans a run without statement or block delimiters, and
+// whitespace is optional except where the pattern separates two words.
+func astGrepPatternRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	var prev rune // Last literal written; 0 at the start and after a metavariable
+	spaced := false
+	writeLiteral := func(s string) {
+		for _, r := range s {
+			if unicode.IsSpace(r) {
+				spaced = true
+				continue
+			}
+			if prev != 0 {
+				words := isWordRune(prev) && isWordRune(r)
+				switch {
+				case words && spaced:
+					sb.WriteString(`\s+`)
+				case !words:
+					sb.WriteString(`\s*`)
+				}
+			}
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			prev, spaced = r, false
+		}
+	}
+
+	last := 0
+	for _, loc := range astGrepMetavar.FindAllStringIndex(pattern, -1) {
+		writeLiteral(pattern[last:loc[0]])
+		if prev != 0 {
+			sb.WriteString(`\s*`)
+		}
+		if strings.HasPrefix(pattern[loc[0]:loc[1]], "$$$") {
+			sb.WriteString(`[\s\S]*?\s*`)
+		} else {
+			sb.WriteString(`[^;{}\n]+?\s*`)
+		}
+		prev, spaced = 0, false
+		last = loc[1]
+	}
+	writeLiteral(pattern[last:])
+
+	return regexp.Compile(sb.String())
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
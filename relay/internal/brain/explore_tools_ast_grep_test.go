@@ -0,0 +1,93 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/internal/brain"
+)
+
+var _ = Describe("ExploreTools ast_grep", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		tools   *brain.ExploreTools
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-ast-grep-test-*")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(tempDir, "store"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tempDir, "store", "user.go"), []byte(`package store
+
+func (s *UserStore) Find(id string) (*User, error) {
+	u, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (s *UserStore) Save(u *User) error {
+	if err != nil { return err }
+	return nil
+}
+`), 0o644)).To(Succeed())
+
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	It("is registered as a tool", func() {
+		var names []string
+		for _, def := range tools.Definitions() {
+			names = append(names, def.Name)
+		}
+		Expect(names).To(ContainElement("ast_grep"))
+	})
+
+	It("matches code structurally regardless of formatting", func() {
+		args, _ := json.Marshal(map[string]any{
+			"pattern": "if err != nil { return nil, $ERR }",
+			"lang":    "go",
+		})
+
+		result, err := tools.Execute(ctx, "ast_grep", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("store/user.go:5:if err != nil {"))
+		Expect(result).NotTo(ContainSubstring("store/user.go:12"))
+	})
+
+	It("reports when nothing matches", func() {
+		args, _ := json.Marshal(map[string]any{"pattern": "panic($MSG)", "lang": "go"})
+
+		result, err := tools.Execute(ctx, "ast_grep", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("No matches"))
+	})
+
+	It("rejects paths outside the repository", func() {
+		args, _ := json.Marshal(map[string]any{"pattern": "return nil", "path": "../"})
+
+		result, err := tools.Execute(ctx, "ast_grep", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("Error: path outside repository"))
+	})
+})
@@ -0,0 +1,180 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"basegraph.co/relay/common/llm"
+)
+
+const maxChangedFiles = 200
+
+// ChangedFilesParams for listing the files changed between two git refs.
+type ChangedFilesParams struct {
+	Base string `json:"base,omitempty" jsonschema:"description=Base ref (default: merge-base of head with the default branch)"`
+	Head string `json:"head,omitempty" jsonschema:"description=Target ref (default HEAD)"`
+	Path string `json:"path,omitempty" jsonschema:"description=Only list changes under this directory or file, relative to repo root"`
+}
+
+// changedFileStatus maps git's --name-status letters to the words shown to
+// the agent.
+var changedFileStatus = map[byte]string{
+	'A': "added",
+	'M': "modified",
+	'D': "deleted",
+	'R': "renamed",
+	'C': "copied",
+	'T': "type changed",
+}
+
+func changedFilesToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "changed_files",
+		Description: `List the files changed between two git refs, with added/modified/deleted/renamed status.
+
+Cheaper than reading git diff when you only need to know which files a branch or range touches.
+By default compares the merge-base with the default branch (main/master) to HEAD - i.e. the current branch's changes.
+
+Examples:
+  changed_files()                                       # Current branch vs default branch
+  changed_files(base="HEAD~5")                          # Last five commits
+  changed_files(base="v1.2.0", head="v1.3.0", path="internal/")`,
+		Parameters: llm.GenerateSchemaFrom(ChangedFilesParams{}),
+	}
+}
+
+// executeChangedFiles runs git diff --name-status between two refs.
+func (t *ExploreTools) executeChangedFiles(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[ChangedFilesParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse changed_files params: %w", err)
+	}
+
+	if t.gitDisabled {
+		return "Error: git is disabled in this deployment - use grep, read and codegraph instead", nil
+	}
+
+	relPath := ""
+	if params.Path != "" {
+		if !pathWithinRoot(t.repoRoot, filepath.Join(t.repoRoot, params.Path)) {
+			return "Error: path outside repository", nil
+		}
+		relPath = filepath.ToSlash(filepath.Clean(params.Path))
+	}
+
+	head := params.Head
+	if head == "" {
+		head = "HEAD"
+	}
+	for _, ref := range []string{params.Base, head} {
+		if ref != "" && (strings.HasPrefix(ref, "-") || !gitRefPattern.MatchString(ref)) {
+			return fmt.Sprintf("Error: invalid ref %q", ref), nil
+		}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Bash)
+	defer cancel()
+
+	for _, ref := range []string{params.Base, head} {
+		if ref != "" && !t.gitRefExists(timeoutCtx, ref) {
+			return fmt.Sprintf("Error: unknown git ref %q", ref), nil
+		}
+	}
+
+	base, label := params.Base, params.Base
+	if base == "" {
+		branch := t.defaultBranch(timeoutCtx)
+		if branch == "" {
+			return "Error: could not find the default branch (no origin/HEAD, main or master). Pass base explicitly.", nil
+		}
+		out, err := exec.CommandContext(timeoutCtx, "git", "-C", t.repoRoot, "merge-base", branch, head).Output()
+		if err != nil {
+			return fmt.Sprintf("Error: %s and %s share no history. Pass base explicitly.", branch, head), nil
+		}
+		base = strings.TrimSpace(string(out))
+		label = fmt.Sprintf("merge-base(%s, %s)", branch, head)
+	}
+
+	args := []string{"-C", t.repoRoot, "diff", "--name-status", "-z", "--find-renames", base, head}
+	if relPath != "" {
+		args = append(args, "--", relPath)
+	}
+	out, err := exec.CommandContext(timeoutCtx, "git", args...).Output()
+	if err != nil {
+		if timeoutCtx.Err() == context.DeadlineExceeded {
+			return "git diff timed out. Narrow the range or pass path.", nil
+		}
+		return fmt.Sprintf("Error: git diff failed: %s", err), nil
+	}
+
+	changes := parseNameStatus(out)
+	scope := ""
+	if relPath != "" {
+		scope = " under " + relPath
+	}
+	if len(changes) == 0 {
+		return fmt.Sprintf("No files changed%s between %s and %s.", scope, label, head), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d file(s) changed%s (%s..%s):\n", len(changes), scope, label, head)
+	for i, c := range changes {
+		if i == maxChangedFiles {
+			sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d files. Pass path to narrow.]", maxChangedFiles, len(changes))) + "\n")
+			break
+		}
+		sb.WriteString(c + "\n")
+	}
+	return withTokenEstimate(sb.String()), nil
+}
+
+// gitRefExists reports whether ref resolves to a commit.
+func (t *ExploreTools) gitRefExists(ctx context.Context, ref string) bool {
+	verify := exec.CommandContext(ctx, "git", "-C", t.repoRoot, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return verify.Run() == nil
+}
+
+// defaultBranch returns origin's HEAD branch, or main/master when there is
+// no remote. Empty when none of them exist.
+func (t *ExploreTools) defaultBranch(ctx context.Context) string {
+	out, err := exec.CommandContext(ctx, "git", "-C", t.repoRoot, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD").Output()
+	if err == nil {
+		if branch := strings.TrimSpace(string(out)); branch != "" {
+			return branch
+		}
+	}
+	for _, branch := range []string{"main", "master"} {
+		if t.gitRefExists(ctx, branch) {
+			return branch
+		}
+	}
+	return ""
+}
+
+// parseNameStatus turns git diff --name-status -z output into
+// "status\tpath" lines. Renames and copies carry two paths.
+func parseNameStatus(out []byte) []string {
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	var changes []string
+	for i := 0; i < len(fields); i++ {
+		code := fields[i]
+		if code == "" || i+1 >= len(fields) {
+			continue
+		}
+		status, ok := changedFileStatus[code[0]]
+		if !ok {
+			status = "changed"
+		}
+		path := fields[i+1]
+		i++
+		if (code[0] == 'R' || code[0] == 'C') && i+1 < len(fields) {
+			path += " -> " + fields[i+1]
+			i++
+		}
+		changes = append(changes, status+"\t"+path)
+	}
+	return changes
+}
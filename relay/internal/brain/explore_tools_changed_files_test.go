@@ -0,0 +1,119 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/internal/brain"
+)
+
+var _ = Describe("ExploreTools changed_files", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		tools   *brain.ExploreTools
+	)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", tempDir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+	}
+
+	write := func(name, content string) {
+		path := filepath.Join(tempDir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+	}
+
+	changedFiles := func(params map[string]any) string {
+		args, _ := json.Marshal(params)
+		result, err := tools.Execute(ctx, "changed_files", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-changed-files-test-*")
+		Expect(err).NotTo(HaveOccurred())
+
+		git("init", "-q", "-b", "main")
+		write("store/user.go", "package store\n\nfunc Find() {}\n")
+		write("store/legacy.go", "package store\n\nfunc Legacy() {}\n")
+		write("docs/setup.md", "# Setup\n\nRun make.\n")
+		git("add", "-A")
+		git("commit", "-q", "-m", "initial")
+
+		git("checkout", "-q", "-b", "feature")
+		write("store/user.go", "package store\n\nfunc Find() string { return \"\" }\n")
+		write("store/purge.go", "package store\n\nfunc Purge() {}\n")
+		Expect(os.Remove(filepath.Join(tempDir, "store", "legacy.go"))).To(Succeed())
+		git("mv", "docs/setup.md", "docs/install.md")
+		git("add", "-A")
+		git("commit", "-q", "-m", "feature")
+
+		// A later commit on main must not show up in the branch's changes.
+		git("checkout", "-q", "main")
+		write("README.md", "# App\n")
+		git("add", "-A")
+		git("commit", "-q", "-m", "readme")
+		git("checkout", "-q", "feature")
+
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	It("lists the branch's changes against the merge-base with main by default", func() {
+		result := changedFiles(map[string]any{})
+
+		Expect(result).To(ContainSubstring("4 file(s) changed (merge-base(main, HEAD)..HEAD)"))
+		Expect(result).To(ContainSubstring("modified\tstore/user.go"))
+		Expect(result).To(ContainSubstring("added\tstore/purge.go"))
+		Expect(result).To(ContainSubstring("deleted\tstore/legacy.go"))
+		Expect(result).To(ContainSubstring("renamed\tdocs/setup.md -> docs/install.md"))
+		Expect(result).NotTo(ContainSubstring("README.md"))
+	})
+
+	It("takes explicit refs and a path filter", func() {
+		result := changedFiles(map[string]any{"base": "main", "head": "feature", "path": "store"})
+
+		Expect(result).To(ContainSubstring("3 file(s) changed under store (main..feature)"))
+		Expect(result).NotTo(ContainSubstring("docs/"))
+		Expect(result).NotTo(ContainSubstring("README.md"))
+
+		result = changedFiles(map[string]any{"base": "HEAD", "path": "store"})
+		Expect(result).To(ContainSubstring("No files changed under store between HEAD and HEAD."))
+	})
+
+	It("rejects bad refs and paths outside the repo", func() {
+		Expect(changedFiles(map[string]any{"base": "--output=/tmp/x"})).To(ContainSubstring("invalid ref"))
+		Expect(changedFiles(map[string]any{"head": "nope"})).To(ContainSubstring(`unknown git ref "nope"`))
+		Expect(changedFiles(map[string]any{"path": "../outside"})).To(ContainSubstring("path outside repository"))
+	})
+
+	It("is unavailable when git is disabled", func() {
+		tools.WithGitDisabled(true)
+
+		Expect(changedFiles(map[string]any{})).To(ContainSubstring("git is disabled"))
+		for _, def := range tools.Definitions() {
+			Expect(def.Name).NotTo(Equal("changed_files"))
+		}
+	})
+})
@@ -3,8 +3,12 @@ package brain_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -14,19 +18,27 @@ import (
 )
 
 type fakeArangoClient struct {
-	searchSymbolsFn func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error)
-	resolveSymbolFn func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error)
-	fileSymbolsFn   func(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error)
-	getCallersFn    func(ctx context.Context, qname string, depth int) ([]arangodb.GraphNode, error)
-	getCalleesFn    func(ctx context.Context, qname string, depth int) ([]arangodb.GraphNode, error)
-	getImplsFn      func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
-	getUsagesFn     func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
-	findCallPathFn  func(ctx context.Context, fromQName string, toQName string, maxDepth int) ([]arangodb.GraphNode, error)
-	getChildrenFn   func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
-	getMethodsFn    func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
-	getInheritorsFn func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
-	traverseFromFn  func(ctx context.Context, qnames []string, opts arangodb.TraversalOptions) ([]arangodb.GraphNode, []arangodb.GraphEdge, error)
-	closeFn         func() error
+	searchSymbolsFn   func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error)
+	resolveSymbolFn   func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error)
+	resolveSymbolsFn  func(ctx context.Context, opts []arangodb.SearchOptions) ([]arangodb.ResolveResult, error)
+	fileSymbolsFn     func(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error)
+	getCallersFn      func(ctx context.Context, qname string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error)
+	getCalleesFn      func(ctx context.Context, qname string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error)
+	getImplsFn        func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
+	getUsagesFn       func(ctx context.Context, qname string, kind arangodb.UsageKind) ([]arangodb.GraphNode, error)
+	findCallPathFn    func(ctx context.Context, fromQName string, toQName string, maxDepth int) ([]arangodb.GraphNode, error)
+	findCallPathsFn   func(ctx context.Context, fromQName string, toQName string, opts arangodb.CallPathOptions) (arangodb.CallPaths, error)
+	getChildrenFn     func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
+	getMethodsFn      func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
+	getInheritorsFn   func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
+	getEmbeddedFn     func(ctx context.Context, qname string) ([]arangodb.GraphNode, error)
+	traverseFromFn    func(ctx context.Context, qnames []string, opts arangodb.TraversalOptions) ([]arangodb.GraphNode, []arangodb.GraphEdge, error)
+	getDependentsFn   func(ctx context.Context, opts arangodb.DependentsOptions) (arangodb.DependentsResult, error)
+	exportCallGraphFn func(ctx context.Context, namespacePrefix string, emit func(arangodb.CallGraphEntry) error) error
+	rawQueryFn        func(ctx context.Context, query string) (arangodb.RawQueryResult, error)
+	nodeCountFn       func(ctx context.Context) (int64, error)
+	closeFn           func() error
+	forRepoFn         func(repoID string) arangodb.Client
 }
 
 func (f *fakeArangoClient) EnsureDatabase(ctx context.Context) error    { return nil }
@@ -40,19 +52,26 @@ func (f *fakeArangoClient) IngestEdges(ctx context.Context, collection string, e
 	return nil
 }
 func (f *fakeArangoClient) TruncateCollections(ctx context.Context) error { return nil }
+func (f *fakeArangoClient) BeginIngest(ctx context.Context) (arangodb.IngestTx, error) {
+	return nil, errors.New("not supported")
+}
+func (f *fakeArangoClient) GetFileHashes(ctx context.Context) (map[string]string, error) {
+	return nil, nil
+}
+func (f *fakeArangoClient) DeleteFileNodes(ctx context.Context, filepaths []string) error { return nil }
 
-func (f *fakeArangoClient) GetCallers(ctx context.Context, qname string, depth int) ([]arangodb.GraphNode, error) {
+func (f *fakeArangoClient) GetCallers(ctx context.Context, qname string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error) {
 	if f.getCallersFn != nil {
-		return f.getCallersFn(ctx, qname, depth)
+		return f.getCallersFn(ctx, qname, depth, page)
 	}
-	return nil, nil
+	return nil, 0, nil
 }
 
-func (f *fakeArangoClient) GetCallees(ctx context.Context, qname string, depth int) ([]arangodb.GraphNode, error) {
+func (f *fakeArangoClient) GetCallees(ctx context.Context, qname string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error) {
 	if f.getCalleesFn != nil {
-		return f.getCalleesFn(ctx, qname, depth)
+		return f.getCalleesFn(ctx, qname, depth, page)
 	}
-	return nil, nil
+	return nil, 0, nil
 }
 
 func (f *fakeArangoClient) FindCallPath(ctx context.Context, fromQName string, toQName string, maxDepth int) ([]arangodb.GraphNode, error) {
@@ -62,6 +81,13 @@ func (f *fakeArangoClient) FindCallPath(ctx context.Context, fromQName string, t
 	return nil, nil
 }
 
+func (f *fakeArangoClient) FindCallPaths(ctx context.Context, fromQName string, toQName string, opts arangodb.CallPathOptions) (arangodb.CallPaths, error) {
+	if f.findCallPathsFn != nil {
+		return f.findCallPathsFn(ctx, fromQName, toQName, opts)
+	}
+	return arangodb.CallPaths{}, nil
+}
+
 func (f *fakeArangoClient) GetChildren(ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
 	if f.getChildrenFn != nil {
 		return f.getChildrenFn(ctx, qname)
@@ -83,9 +109,9 @@ func (f *fakeArangoClient) GetMethods(ctx context.Context, qname string) ([]aran
 	return nil, nil
 }
 
-func (f *fakeArangoClient) GetUsages(ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
+func (f *fakeArangoClient) GetUsages(ctx context.Context, qname string, kind arangodb.UsageKind) ([]arangodb.GraphNode, error) {
 	if f.getUsagesFn != nil {
-		return f.getUsagesFn(ctx, qname)
+		return f.getUsagesFn(ctx, qname, kind)
 	}
 	return nil, nil
 }
@@ -97,6 +123,30 @@ func (f *fakeArangoClient) GetInheritors(ctx context.Context, qname string) ([]a
 	return nil, nil
 }
 
+func (f *fakeArangoClient) GetEmbedded(ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
+	if f.getEmbeddedFn != nil {
+		return f.getEmbeddedFn(ctx, qname)
+	}
+	return nil, nil
+}
+
+func (f *fakeArangoClient) NodeCount(ctx context.Context) (int64, error) {
+	if f.nodeCountFn != nil {
+		return f.nodeCountFn(ctx)
+	}
+	return 1, nil
+}
+
+func (f *fakeArangoClient) RawQuery(ctx context.Context, query string) (arangodb.RawQueryResult, error) {
+	if err := arangodb.ValidateReadOnlyQuery(query); err != nil {
+		return arangodb.RawQueryResult{}, err
+	}
+	if f.rawQueryFn != nil {
+		return f.rawQueryFn(ctx, query)
+	}
+	return arangodb.RawQueryResult{}, nil
+}
+
 func (f *fakeArangoClient) TraverseFrom(ctx context.Context, qnames []string, opts arangodb.TraversalOptions) ([]arangodb.GraphNode, []arangodb.GraphEdge, error) {
 	if f.traverseFromFn != nil {
 		return f.traverseFromFn(ctx, qnames, opts)
@@ -104,6 +154,13 @@ func (f *fakeArangoClient) TraverseFrom(ctx context.Context, qnames []string, op
 	return nil, nil, nil
 }
 
+func (f *fakeArangoClient) GetDependents(ctx context.Context, opts arangodb.DependentsOptions) (arangodb.DependentsResult, error) {
+	if f.getDependentsFn != nil {
+		return f.getDependentsFn(ctx, opts)
+	}
+	return arangodb.DependentsResult{}, arangodb.ErrNotFound
+}
+
 func (f *fakeArangoClient) GetFileSymbols(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error) {
 	if f.fileSymbolsFn != nil {
 		return f.fileSymbolsFn(ctx, opts)
@@ -125,6 +182,32 @@ func (f *fakeArangoClient) ResolveSymbol(ctx context.Context, opts arangodb.Sear
 	return arangodb.ResolvedSymbol{}, arangodb.ErrNotFound
 }
 
+// ResolveSymbols falls back to ResolveSymbol per entry.
+func (f *fakeArangoClient) ResolveSymbols(ctx context.Context, opts []arangodb.SearchOptions) ([]arangodb.ResolveResult, error) {
+	if f.resolveSymbolsFn != nil {
+		return f.resolveSymbolsFn(ctx, opts)
+	}
+	results := make([]arangodb.ResolveResult, len(opts))
+	for i, o := range opts {
+		results[i].Symbol, results[i].Err = f.ResolveSymbol(ctx, o)
+	}
+	return results, nil
+}
+
+func (f *fakeArangoClient) ExportCallGraph(ctx context.Context, namespacePrefix string, emit func(arangodb.CallGraphEntry) error) error {
+	if f.exportCallGraphFn != nil {
+		return f.exportCallGraphFn(ctx, namespacePrefix, emit)
+	}
+	return nil
+}
+
+func (f *fakeArangoClient) ForRepo(repoID string) arangodb.Client {
+	if f.forRepoFn != nil {
+		return f.forRepoFn(repoID)
+	}
+	return f
+}
+
 func (f *fakeArangoClient) Close() error {
 	if f.closeFn != nil {
 		return f.closeFn()
@@ -150,7 +233,7 @@ var _ = Describe("ExploreTools codegraph", func() {
 		Expect(os.WriteFile(filepath.Join(tempDir, "src", "main.go"), []byte("package main\n\nfunc Plan() {}\n"), 0o644)).To(Succeed())
 
 		fake = &fakeArangoClient{}
-		tools = brain.NewExploreTools(tempDir, fake)
+		tools = brain.NewExploreTools(tempDir, fake, brain.ExploreToolsConfig{})
 	})
 
 	AfterEach(func() {
@@ -172,6 +255,39 @@ var _ = Describe("ExploreTools codegraph", func() {
 		Expect(result).To(ContainSubstring("Supported kinds: function, method, struct, interface, class"))
 	})
 
+	It("accepts and shows the kinds configured in CodegraphKinds", func() {
+		tools = brain.NewExploreTools(tempDir, fake, brain.ExploreToolsConfig{
+			CodegraphKinds: []string{"function", "struct", "Alias"},
+		})
+		var gotKind string
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			gotKind = opts.Kind
+			return []arangodb.SearchResult{
+				{QName: "example.com/app.PlanID", Name: "PlanID", Kind: "alias", Filepath: filepath.Join(tempDir, "src", "main.go"), Pos: 5},
+				{QName: "example.com/app.Planner.Plan", Name: "Plan", Kind: "method", Filepath: filepath.Join(tempDir, "src", "main.go"), Pos: 9},
+			}, 2, nil
+		}
+
+		result, err := tools.Execute(ctx, "codegraph", `{"operation":"search","name":"Plan*","kind":"alias"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gotKind).To(Equal("alias"))
+		Expect(result).To(ContainSubstring("src/main.go:5\talias\texample.com/app.PlanID"))
+		Expect(result).NotTo(ContainSubstring("example.com/app.Planner.Plan"))
+
+		result, err = tools.Execute(ctx, "codegraph", `{"operation":"search","name":"Plan","kind":"method"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring(`Error: invalid kind "method". Supported kinds: function, struct, alias.`))
+
+		for _, def := range tools.Definitions() {
+			if def.Name == "codegraph" {
+				Expect(def.Description).To(ContainSubstring("SUPPORTED KINDS (strict): function, struct, alias."))
+				schema, err := json.Marshal(def.Parameters)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(schema)).To(ContainSubstring(`"enum":["function","struct","alias"]`))
+			}
+		}
+	})
+
 	It("auto-resolves name for callers (method->function fallback)", func() {
 		fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
 			if opts.Kind == "method" {
@@ -188,7 +304,7 @@ var _ = Describe("ExploreTools codegraph", func() {
 		}
 
 		var calledQName string
-		fake.getCallersFn = func(ctx context.Context, qname string, depth int) ([]arangodb.GraphNode, error) {
+		fake.getCallersFn = func(ctx context.Context, qname string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error) {
 			calledQName = qname
 			return []arangodb.GraphNode{
 				{
@@ -199,7 +315,7 @@ var _ = Describe("ExploreTools codegraph", func() {
 					Pos:       3,
 					Signature: "func Plan() {}",
 				},
-			}, nil
+			}, 1, nil
 		}
 
 		args, _ := json.Marshal(map[string]any{
@@ -215,6 +331,26 @@ var _ = Describe("ExploreTools codegraph", func() {
 		Expect(result).To(ContainSubstring("src/main.go:3\tfunction\texample.com/app.Caller"))
 	})
 
+	It("ranks exact name matches before prefix and substring matches", func() {
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			var results []arangodb.SearchResult
+			for i := range 11 {
+				results = append(results, arangodb.SearchResult{QName: fmt.Sprintf("example.com/app.ReplanHandler%d", i), Name: fmt.Sprintf("ReplanHandler%d", i), Kind: "function", Filepath: filepath.Join(tempDir, "src", "a.go"), Pos: i + 1})
+			}
+			results = append(results,
+				arangodb.SearchResult{QName: "example.com/app.Planner", Name: "Planner", Kind: "struct", Filepath: filepath.Join(tempDir, "src", "b.go"), Pos: 1},
+				arangodb.SearchResult{QName: "example.com/app.Plan", Name: "Plan", Kind: "function", Filepath: filepath.Join(tempDir, "src", "c.go"), Pos: 1},
+			)
+			return results, len(results), nil
+		}
+
+		result, err := tools.Execute(ctx, "codegraph", `{"operation":"search","name":"*plan*"}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(MatchRegexp(`(?s)matching "\*plan\*":\nsrc/c.go:1\tfunction\texample.com/app.Plan\nsrc/b.go:1\tstruct\texample.com/app.Planner\nsrc/a.go:1\t`))
+		Expect(result).To(ContainSubstring("[Showing 10 of 13."))
+	})
+
 	It("formats ambiguous resolve with candidates", func() {
 		fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
 			return arangodb.ResolvedSymbol{}, arangodb.AmbiguousSymbolError{Query: opts.Name, Candidates: []arangodb.SearchResult{
@@ -234,6 +370,422 @@ var _ = Describe("ExploreTools codegraph", func() {
 		Expect(result).To(ContainSubstring("src/main.go:3\tfunction\texample.com/a.Plan"))
 	})
 
+	It("scopes codegraph to RepoID", func() {
+		symbols := map[string][]arangodb.SearchResult{
+			"repo-a": {{QName: "acme.com/a/store.Save", Name: "Save", Kind: "function", Filepath: filepath.Join(tempDir, "store", "save.go"), Pos: 3}},
+			"repo-b": {{QName: "acme.com/b/store.Save", Name: "Save", Kind: "function", Filepath: filepath.Join(tempDir, "store", "save.go"), Pos: 7}},
+		}
+		var scopedTo []string
+		fake.forRepoFn = func(repoID string) arangodb.Client {
+			scopedTo = append(scopedTo, repoID)
+			return &fakeArangoClient{
+				searchSymbolsFn: func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+					return symbols[repoID], len(symbols[repoID]), nil
+				},
+			}
+		}
+
+		tools = brain.NewExploreTools(tempDir, fake, brain.ExploreToolsConfig{RepoID: "repo-a"})
+		result, err := tools.Execute(ctx, "codegraph", `{"operation":"search","name":"Save"}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scopedTo).To(Equal([]string{"repo-a"}))
+		Expect(result).To(ContainSubstring("acme.com/a/store.Save"))
+		Expect(result).NotTo(ContainSubstring("acme.com/b/store.Save"))
+	})
+
+	Describe("resolve names", func() {
+		var batches [][]string
+		var singles []string
+
+		BeforeEach(func() {
+			batches, singles = nil, nil
+			symbols := map[string]arangodb.ResolvedSymbol{
+				"Plan":    {QName: "example.com/app.Plan", Kind: "function", Filepath: filepath.Join(tempDir, "src", "main.go"), Pos: 3},
+				"Planner": {QName: "example.com/app.Planner", Kind: "struct", Filepath: filepath.Join(tempDir, "src", "planner.go"), Pos: 8},
+			}
+			resolveOne := func(opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+				if opts.Name == "Save" {
+					return arangodb.ResolvedSymbol{}, arangodb.AmbiguousSymbolError{Query: opts.Name, Total: 2, Candidates: []arangodb.SearchResult{
+						{QName: "example.com/billing.Save", Kind: "function", Filepath: filepath.Join(tempDir, "billing", "invoice.go"), Pos: 10},
+						{QName: "example.com/store.Save", Kind: "function", Filepath: filepath.Join(tempDir, "store", "user.go"), Pos: 20},
+					}}
+				}
+				if symbol, ok := symbols[opts.Name]; ok {
+					return symbol, nil
+				}
+				return arangodb.ResolvedSymbol{}, arangodb.ErrNotFound
+			}
+			fake.resolveSymbolsFn = func(ctx context.Context, opts []arangodb.SearchOptions) ([]arangodb.ResolveResult, error) {
+				var names []string
+				results := make([]arangodb.ResolveResult, len(opts))
+				for i, o := range opts {
+					names = append(names, o.Name)
+					results[i].Symbol, results[i].Err = resolveOne(o)
+				}
+				batches = append(batches, names)
+				return results, nil
+			}
+			fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+				singles = append(singles, opts.Name)
+				return resolveOne(opts)
+			}
+		})
+
+		resolve := func(params map[string]any) string {
+			params["operation"] = "resolve"
+			args, _ := json.Marshal(params)
+			result, err := tools.Execute(ctx, "codegraph", string(args))
+			Expect(err).NotTo(HaveOccurred())
+			return result
+		}
+
+		It("resolves every name in one query and marks the ambiguous one", func() {
+			result := resolve(map[string]any{"names": []string{"Plan", "Save", "Planner"}})
+
+			Expect(result).To(Equal("Resolved 2 of 3 names:\n" +
+				"Plan\tsrc/main.go:3\tfunction\texample.com/app.Plan\n" +
+				"Save\tAMBIGUOUS (2 matches: example.com/billing.Save, example.com/store.Save)\n" +
+				"Planner\tsrc/planner.go:8\tstruct\texample.com/app.Planner"))
+			Expect(batches).To(Equal([][]string{{"Plan", "Save", "Planner"}}))
+			Expect(singles).To(BeEmpty())
+		})
+
+		It("retries only the names the batch didn't find", func() {
+			result := resolve(map[string]any{"names": []string{"Plan", "Missing"}})
+
+			Expect(result).To(ContainSubstring("Missing\tNOT FOUND"))
+			Expect(singles).To(Equal([]string{"Missing", "*Missing*"}))
+		})
+
+		It("caps the batch size and rejects name with names", func() {
+			names := make([]string, 21)
+			for i := range names {
+				names[i] = fmt.Sprintf("Sym%d", i)
+			}
+			Expect(resolve(map[string]any{"names": names})).To(Equal("Error: too many names (21). Resolve at most 20 per call."))
+			Expect(resolve(map[string]any{"name": "Plan", "names": []string{"Save"}})).To(Equal("Error: pass name or names to resolve, not both."))
+			Expect(batches).To(BeEmpty())
+		})
+	})
+
+	Describe("context_file", func() {
+		var gotQName string
+
+		BeforeEach(func() {
+			gotQName = ""
+			candidates := []arangodb.SearchResult{
+				{QName: "example.com/billing.Save", Name: "Save", Kind: "function", Filepath: filepath.Join(tempDir, "billing", "invoice.go"), Pos: 10},
+				{QName: "example.com/store.Save", Name: "Save", Kind: "function", Filepath: filepath.Join(tempDir, "store", "user.go"), Pos: 20},
+			}
+			fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+				return arangodb.ResolvedSymbol{}, arangodb.AmbiguousSymbolError{Query: opts.Name, Candidates: candidates, Total: len(candidates)}
+			}
+			fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+				return candidates, len(candidates), nil
+			}
+			fake.getCallersFn = func(ctx context.Context, qname string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error) {
+				gotQName = qname
+				return nil, 0, nil
+			}
+		})
+
+		callers := func(contextFile string) string {
+			args, _ := json.Marshal(map[string]any{"operation": "callers", "name": "Save", "context_file": contextFile})
+			result, err := tools.Execute(ctx, "codegraph", string(args))
+			Expect(err).NotTo(HaveOccurred())
+			return result
+		}
+
+		It("picks the candidate defined in the context file", func() {
+			Expect(callers("store/user.go")).To(Equal("No callers found for example.com/store.Save."))
+			Expect(gotQName).To(Equal("example.com/store.Save"))
+		})
+
+		It("picks the candidate in the context file's package", func() {
+			Expect(callers(filepath.Join(tempDir, "billing", "payment.go"))).To(Equal("No callers found for example.com/billing.Save."))
+			Expect(gotQName).To(Equal("example.com/billing.Save"))
+		})
+
+		It("still lists every candidate when the context file doesn't decide", func() {
+			result := callers("api/handler.go")
+			Expect(result).To(ContainSubstring(`Error: ambiguous symbol "Save"`))
+			Expect(result).To(ContainSubstring("example.com/billing.Save"))
+			Expect(result).To(ContainSubstring("example.com/store.Save"))
+			Expect(gotQName).To(BeEmpty())
+		})
+	})
+
+	Describe("callers paging", func() {
+		var gotPage arangodb.Page
+
+		BeforeEach(func() {
+			// 45 callers in total; the fake serves the requested window.
+			fake.getCallersFn = func(ctx context.Context, qname string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error) {
+				gotPage = page
+				var nodes []arangodb.GraphNode
+				for i := page.Offset; i < min(page.Offset+page.Limit, 45); i++ {
+					nodes = append(nodes, arangodb.GraphNode{
+						QName:    fmt.Sprintf("example.com/app.Caller%02d", i),
+						Kind:     "function",
+						Filepath: filepath.Join(tempDir, "src", "main.go"),
+						Pos:      i + 1,
+					})
+				}
+				return nodes, 45, nil
+			}
+		})
+
+		It("reports the total and the next offset when truncated", func() {
+			res, err := tools.ExecuteResult(ctx, "codegraph", `{"operation":"callers","qname":"example.com/app.Plan"}`)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotPage).To(Equal(arangodb.Page{Limit: 30}))
+			Expect(res.Truncated).To(BeTrue())
+			Expect(res.Output).To(ContainSubstring("Callers of example.com/app.Plan (depth 1) - results 1-30 of 45:"))
+			Expect(res.Output).To(ContainSubstring("[truncated] [Showing 30 of 45 callers (depth 1). Call again with offset=30 for more.]"))
+		})
+
+		It("returns the last page without a footer", func() {
+			res, err := tools.ExecuteResult(ctx, "codegraph", `{"operation":"callers","qname":"example.com/app.Plan","offset":30}`)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Truncated).To(BeFalse())
+			Expect(res.Output).To(ContainSubstring("results 31-45 of 45:"))
+			Expect(res.Output).To(ContainSubstring("example.com/app.Caller30"))
+			Expect(res.Output).NotTo(ContainSubstring("example.com/app.Caller29"))
+		})
+
+		It("clamps the limit", func() {
+			_, err := tools.Execute(ctx, "codegraph", `{"operation":"callers","qname":"example.com/app.Plan","limit":1000}`)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotPage.Limit).To(Equal(100))
+		})
+
+		It("reports an offset past the end", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"callers","qname":"example.com/app.Plan","offset":60}`)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("No more callers for example.com/app.Plan (45 total)."))
+		})
+	})
+
+	Describe("include_doc", func() {
+		const doc = "Plan builds the execution plan for an issue. It retries on conflicts.\n\nMore detail here."
+
+		BeforeEach(func() {
+			fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+				return []arangodb.SearchResult{
+					{QName: "example.com/app.Plan", Name: "Plan", Kind: "function", Filepath: filepath.Join(tempDir, "src", "main.go"), Pos: 3, Doc: doc},
+				}, 1, nil
+			}
+			fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+				return arangodb.ResolvedSymbol{QName: "example.com/app.Plan", Name: "Plan", Kind: "function", Filepath: filepath.Join(tempDir, "src", "main.go"), Pos: 3, Doc: doc}, nil
+			}
+			fake.fileSymbolsFn = func(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error) {
+				return []arangodb.FileSymbol{
+					{QName: "example.com/app.Plan", Name: "Plan", Kind: "function", Pos: 3, Doc: doc},
+					{QName: "example.com/app.helper", Name: "helper", Kind: "function", Pos: 9},
+				}, nil
+			}
+		})
+
+		for _, args := range []string{
+			`{"operation":"search","name":"Plan"}`,
+			`{"operation":"resolve","name":"Plan"}`,
+			`{"operation":"file_symbols","file":"src/main.go"}`,
+		} {
+			It("omits docs by default for "+args, func() {
+				result, err := tools.Execute(ctx, "codegraph", args)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("example.com/app.Plan"))
+				Expect(result).NotTo(ContainSubstring("builds the execution plan"))
+			})
+
+			It("appends the first sentence of the doc when requested for "+args, func() {
+				result, err := tools.Execute(ctx, "codegraph", strings.TrimSuffix(args, "}")+`,"include_doc":true}`)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("example.com/app.Plan\t// Plan builds the execution plan for an issue."))
+				Expect(result).NotTo(ContainSubstring("retries on conflicts"))
+			})
+		}
+
+		It("leaves symbols without a doc unchanged", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"file_symbols","file":"src/main.go","include_doc":true}`)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveSuffix("function\texample.com/app.helper"))
+		})
+	})
+
+	Describe("file_symbols outline", func() {
+		BeforeEach(func() {
+			fake.fileSymbolsFn = func(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error) {
+				return []arangodb.FileSymbol{
+					{QName: "example.com/app.Store", Name: "Store", Kind: "struct", Pos: 3},
+					{QName: "example.com/app.NewStore", Name: "NewStore", Kind: "function", Pos: 8},
+					{QName: "example.com/app.Store.Get", Name: "Get", Kind: "method", Pos: 12, ParentQName: "example.com/app.Store"},
+					{QName: "example.com/app.Cache", Name: "Cache", Kind: "struct", Pos: 20},
+					{QName: "example.com/app.Cache.Put", Name: "Put", Kind: "method", Pos: 25, ParentQName: "example.com/app.Cache"},
+					{QName: "example.com/app.Store.Save", Name: "Save", Kind: "method", Pos: 30, ParentQName: "example.com/app.Store"},
+					{QName: "example.com/app.Planner.Plan", Name: "Plan", Kind: "method", Pos: 40, ParentQName: "example.com/app.Planner"},
+					{QName: "example.com/app.helper", Name: "helper", Kind: "function", Pos: 50},
+				}, nil
+			}
+		})
+
+		It("nests methods under their type and lists functions separately", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"file_symbols","file":"src/store.go","outline":true}`)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(`Outline of src/store.go:
+src/store.go:3	struct	example.com/app.Store
+  src/store.go:12	method	example.com/app.Store.Get
+  src/store.go:30	method	example.com/app.Store.Save
+src/store.go:20	struct	example.com/app.Cache
+  src/store.go:25	method	example.com/app.Cache.Put
+example.com/app.Planner (declared in another file)
+  src/store.go:40	method	example.com/app.Planner.Plan
+
+Functions:
+src/store.go:8	function	example.com/app.NewStore
+src/store.go:50	function	example.com/app.helper`))
+		})
+
+		It("keeps the flat list by default", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"file_symbols","file":"src/store.go"}`)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("Symbols in src/store.go:\nsrc/store.go:3\tstruct\texample.com/app.Store\nsrc/store.go:8\tfunction\texample.com/app.NewStore\n"))
+		})
+	})
+
+	Describe("index building", func() {
+		It("reports an empty graph as not built instead of symbol not found", func() {
+			nodes := int64(0)
+			fake.nodeCountFn = func(ctx context.Context) (int64, error) { return nodes, nil }
+
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"resolve","name":"Plan"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HavePrefix("Codegraph index is not built yet"))
+			Expect(result).NotTo(ContainSubstring("no symbol found"))
+
+			// Once ingestion lands, queries go through again
+			nodes = 42
+			result, err = tools.Execute(ctx, "codegraph", `{"operation":"resolve","name":"Plan"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(`Error: no symbol found matching "Plan".`))
+		})
+	})
+
+	Describe("name matching", func() {
+		seeded := []arangodb.SearchResult{
+			{QName: "example.com/app.Issue", Name: "Issue", Kind: "struct", Pos: 1},
+			{QName: "example.com/app.issue", Name: "issue", Kind: "function", Pos: 5},
+			{QName: "example.com/app.IssueStore", Name: "IssueStore", Kind: "interface", Pos: 9},
+		}
+
+		// matchSeeded applies SearchOptions name matching the way the
+		// ArangoDB query does.
+		matchSeeded := func(opts arangodb.SearchOptions) []arangodb.SearchResult {
+			pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(opts.Name), `\*`, ".*") + "$"
+			if opts.Exact {
+				pattern = "^" + regexp.QuoteMeta(opts.Name) + "$"
+			}
+			if opts.CaseInsensitive {
+				pattern = "(?i)" + pattern
+			}
+			re := regexp.MustCompile(pattern)
+			var out []arangodb.SearchResult
+			for _, r := range seeded {
+				if re.MatchString(r.Name) {
+					r.Filepath = filepath.Join(tempDir, "src", "main.go")
+					out = append(out, r)
+				}
+			}
+			return out
+		}
+
+		BeforeEach(func() {
+			fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+				results := matchSeeded(opts)
+				return results, len(results), nil
+			}
+			fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+				results := matchSeeded(opts)
+				switch len(results) {
+				case 0:
+					return arangodb.ResolvedSymbol{}, arangodb.ErrNotFound
+				case 1:
+					r := results[0]
+					return arangodb.ResolvedSymbol{QName: r.QName, Name: r.Name, Kind: r.Kind, Filepath: r.Filepath, Pos: r.Pos}, nil
+				default:
+					return arangodb.ResolvedSymbol{}, arangodb.AmbiguousSymbolError{Query: opts.Name, Candidates: results, Total: len(results)}
+				}
+			}
+		})
+
+		It("matches both cases by default and only the given case when case_insensitive=false", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"search","name":"issue"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("example.com/app.Issue\n"))
+			Expect(result).To(ContainSubstring("example.com/app.issue"))
+
+			result, err = tools.Execute(ctx, "codegraph", `{"operation":"search","name":"issue","case_insensitive":false}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("example.com/app.issue"))
+			Expect(result).NotTo(ContainSubstring("example.com/app.Issue"))
+		})
+
+		It("excludes partial matches in exact mode", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"search","name":"Issue*","exact":true}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring(`No symbols found matching "Issue*"`))
+
+			// Without exact, resolve falls back to *Store* and finds IssueStore
+			result, err = tools.Execute(ctx, "codegraph", `{"operation":"resolve","name":"Store"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("example.com/app.IssueStore"))
+
+			result, err = tools.Execute(ctx, "codegraph", `{"operation":"resolve","name":"Store","exact":true}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(`Error: no symbol found matching "Store".`))
+		})
+	})
+
+	Describe("wildcard retry", func() {
+		BeforeEach(func() {
+			fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+				if opts.Name == "Handle" {
+					return arangodb.ResolvedSymbol{}, arangodb.ErrNotFound
+				}
+				candidates := make([]arangodb.SearchResult, 5)
+				for i := range candidates {
+					candidates[i] = arangodb.SearchResult{QName: fmt.Sprintf("example.com/app.Handle%d", i), Kind: "function"}
+				}
+				return arangodb.ResolvedSymbol{}, arangodb.AmbiguousSymbolError{Query: opts.Name, Candidates: candidates, Total: 40}
+			}
+		})
+
+		It("reports a wildcard match over the limit as too broad", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"resolve","name":"Handle"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(`Error: no symbol named "Handle", and *Handle* is too broad (40 matches). Add kind/file, use a longer name, or pass qname.`))
+		})
+
+		It("lists candidates when the match count is within the configured limit", func() {
+			tools.WithWildcardMaxCandidates(50)
+
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"resolve","name":"Handle"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring(`Error: ambiguous symbol "*Handle*"`))
+			Expect(result).To(ContainSubstring("example.com/app.Handle0"))
+		})
+	})
+
 	It("formats search results with file:line", func() {
 		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
 			return []arangodb.SearchResult{{
@@ -257,12 +809,38 @@ var _ = Describe("ExploreTools codegraph", func() {
 		Expect(result).To(ContainSubstring("src/main.go:3\tfunction\texample.com/app.Plan"))
 	})
 
+	It("flags search results cut at the display cap", func() {
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			var results []arangodb.SearchResult
+			for i := 0; i < 12; i++ {
+				results = append(results, arangodb.SearchResult{
+					QName:    fmt.Sprintf("example.com/app.Plan%d", i),
+					Name:     fmt.Sprintf("Plan%d", i),
+					Kind:     "function",
+					Filepath: filepath.Join(tempDir, "src", "main.go"),
+					Pos:      3,
+				})
+			}
+			return results, len(results), nil
+		}
+
+		args, _ := json.Marshal(map[string]any{
+			"operation": "search",
+			"name":      "Plan*",
+		})
+
+		res, err := tools.ExecuteResult(ctx, "codegraph", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Truncated).To(BeTrue())
+		Expect(res.Output).To(ContainSubstring("[truncated] [Showing 10 of 12."))
+	})
+
 	It("formats trace path", func() {
-		fake.findCallPathFn = func(ctx context.Context, fromQName string, toQName string, maxDepth int) ([]arangodb.GraphNode, error) {
-			return []arangodb.GraphNode{
+		fake.findCallPathsFn = func(ctx context.Context, fromQName string, toQName string, opts arangodb.CallPathOptions) (arangodb.CallPaths, error) {
+			return arangodb.CallPaths{Paths: [][]arangodb.GraphNode{{
 				{QName: fromQName, Kind: "function", Filepath: filepath.Join(tempDir, "src", "main.go"), Pos: 3, Signature: "func A() {}"},
 				{QName: toQName, Kind: "function", Filepath: filepath.Join(tempDir, "src", "main.go"), Pos: 3, Signature: "func B() {}"},
-			}, nil
+			}}}, nil
 		}
 
 		args, _ := json.Marshal(map[string]any{
@@ -278,4 +856,584 @@ var _ = Describe("ExploreTools codegraph", func() {
 		Expect(result).To(ContainSubstring("src/main.go:3\tfunction\texample.com/app.A"))
 		Expect(result).To(ContainSubstring("src/main.go:3\tfunction\texample.com/app.B"))
 	})
+
+	It("lists each of several trace paths", func() {
+		mainFile := filepath.Join(tempDir, "src", "main.go")
+		var requested int
+		fake.findCallPathsFn = func(ctx context.Context, fromQName string, toQName string, opts arangodb.CallPathOptions) (arangodb.CallPaths, error) {
+			requested = opts.MaxPaths
+			return arangodb.CallPaths{Paths: [][]arangodb.GraphNode{
+				{
+					{QName: fromQName, Kind: "function", Filepath: mainFile, Pos: 3},
+					{QName: toQName, Kind: "function", Filepath: mainFile, Pos: 9},
+				},
+				{
+					{QName: fromQName, Kind: "function", Filepath: mainFile, Pos: 3},
+					{QName: "example.com/app.Mid", Kind: "function", Filepath: mainFile, Pos: 6},
+					{QName: toQName, Kind: "function", Filepath: mainFile, Pos: 9},
+				},
+			}}, nil
+		}
+
+		args, _ := json.Marshal(map[string]any{
+			"operation":  "trace",
+			"from_qname": "example.com/app.A",
+			"to_qname":   "example.com/app.B",
+			"max_paths":  9,
+		})
+
+		result, err := tools.Execute(ctx, "codegraph", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requested).To(Equal(5), "max_paths is capped")
+		Expect(result).To(ContainSubstring("2 path(s), shortest first"))
+		Expect(result).To(ContainSubstring("Path 1 (2 step(s)):\nsrc/main.go:3\tfunction\texample.com/app.A\nsrc/main.go:9\tfunction\texample.com/app.B"))
+		Expect(result).To(ContainSubstring("Path 2 (3 step(s)):\nsrc/main.go:3\tfunction\texample.com/app.A\nsrc/main.go:6\tfunction\texample.com/app.Mid"))
+	})
+
+	It("marks the async boundary on a trace path that crosses a queue", func() {
+		var opts arangodb.CallPathOptions
+		fake.findCallPathsFn = func(ctx context.Context, fromQName string, toQName string, o arangodb.CallPathOptions) (arangodb.CallPaths, error) {
+			opts = o
+			return arangodb.CallPaths{Paths: [][]arangodb.GraphNode{{
+				{QName: fromQName, Kind: "function", Filepath: filepath.Join(tempDir, "src", "webhook.go"), Pos: 10},
+				{QName: "relay-tasks", Kind: "queue"},
+				{QName: "example.com/app.process", Kind: "function", Filepath: filepath.Join(tempDir, "src", "worker.go"), Pos: 20},
+				{QName: toQName, Kind: "method", Filepath: filepath.Join(tempDir, "src", "planner.go"), Pos: 30},
+			}}}, nil
+		}
+
+		args, _ := json.Marshal(map[string]any{
+			"operation":     "trace",
+			"from_qname":    "example.com/app.HandleWebhook",
+			"to_qname":      "example.com/app.Planner.Plan",
+			"include_async": true,
+		})
+
+		result, err := tools.Execute(ctx, "codegraph", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.IncludeAsync).To(BeTrue())
+		Expect(result).To(ContainSubstring("src/webhook.go:10\tfunction\texample.com/app.HandleWebhook\n~~ async boundary: enqueued on relay-tasks, dequeued by the next function ~~\nsrc/worker.go:20\tfunction\texample.com/app.process"))
+	})
+
+	It("reports the closest partial path when trace finds none", func() {
+		mainFile := filepath.Join(tempDir, "src", "main.go")
+		fake.findCallPathsFn = func(ctx context.Context, fromQName string, toQName string, opts arangodb.CallPathOptions) (arangodb.CallPaths, error) {
+			return arangodb.CallPaths{Partial: []arangodb.GraphNode{
+				{QName: fromQName, Kind: "function", Filepath: mainFile, Pos: 3},
+				{QName: "example.com/app.Dispatch", Kind: "function", Filepath: mainFile, Pos: 12},
+			}}, nil
+		}
+
+		args, _ := json.Marshal(map[string]any{
+			"operation":  "trace",
+			"from_qname": "example.com/app.A",
+			"to_qname":   "example.com/app.B",
+		})
+
+		result, err := tools.Execute(ctx, "codegraph", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("No direct call path found from example.com/app.A to example.com/app.B (max_depth=4)."))
+		Expect(result).To(ContainSubstring("Closest partial path reached example.com/app.Dispatch, no call edge to example.com/app.B:"))
+		Expect(result).To(ContainSubstring("src/main.go:12\tfunction\texample.com/app.Dispatch"))
+
+		By("suggesting a deeper trace when the partial path does reach the target")
+		fake.findCallPathsFn = func(ctx context.Context, fromQName string, toQName string, opts arangodb.CallPathOptions) (arangodb.CallPaths, error) {
+			return arangodb.CallPaths{
+				Partial: []arangodb.GraphNode{
+					{QName: fromQName, Kind: "function", Filepath: mainFile, Pos: 3},
+					{QName: "example.com/app.Dispatch", Kind: "function", Filepath: mainFile, Pos: 12},
+				},
+				PartialGap: 4,
+			}, nil
+		}
+
+		result, err = tools.Execute(ctx, "codegraph", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("reaches example.com/app.Dispatch, which calls example.com/app.B in 4 more step(s); retry with max_depth=5"))
+	})
+
+	It("lists siblings of a resolved symbol, excluding the symbol itself", func() {
+		mainFile := filepath.Join(tempDir, "src", "main.go")
+		fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+			return arangodb.ResolvedSymbol{
+				QName:    "example.com/app.Plan",
+				Name:     "Plan",
+				Kind:     "function",
+				Filepath: mainFile,
+				Pos:      3,
+			}, nil
+		}
+
+		var requestedFile string
+		fake.fileSymbolsFn = func(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error) {
+			requestedFile = opts.Filepath
+			return []arangodb.FileSymbol{
+				{QName: "example.com/app.Planner", Name: "Planner", Kind: "struct", Pos: 1},
+				{QName: "example.com/app.Plan", Name: "Plan", Kind: "function", Pos: 3},
+				{QName: "example.com/app.Replan", Name: "Replan", Kind: "function", Pos: 9, Signature: "func Replan() {}"},
+			}, nil
+		}
+
+		args, _ := json.Marshal(map[string]any{
+			"operation": "siblings",
+			"name":      "Plan",
+		})
+
+		result, err := tools.Execute(ctx, "codegraph", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requestedFile).To(Equal(mainFile))
+		Expect(result).To(ContainSubstring("Siblings of example.com/app.Plan in src/main.go"))
+		Expect(result).To(ContainSubstring("src/main.go:1\tstruct\texample.com/app.Planner"))
+		Expect(result).To(ContainSubstring("src/main.go:9\tfunction\texample.com/app.Replan"))
+		Expect(result).NotTo(ContainSubstring("\texample.com/app.Plan\n"))
+		Expect(result).NotTo(ContainSubstring("src/main.go:3"))
+	})
+
+	It("looks up a qname exactly, even when its short name is common", func() {
+		mainFile := filepath.Join(tempDir, "src", "main.go")
+		const qname = "example.com/app/zz.Save"
+		// Like the real query: 30 results at most, ranked by file, and the
+		// qname only survives the cap when it is filtered on.
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			if opts.QName == qname {
+				Expect(opts.Name).To(Equal("Save"))
+				return []arangodb.SearchResult{{QName: qname, Name: "Save", Kind: "function", Filepath: mainFile, Pos: 3}}, 1, nil
+			}
+			var results []arangodb.SearchResult
+			for i := 0; i < 30; i++ {
+				results = append(results, arangodb.SearchResult{QName: fmt.Sprintf("example.com/app/a%02d.Save", i), Name: "Save", Kind: "function"})
+			}
+			return results, 45, nil
+		}
+		fake.fileSymbolsFn = func(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error) {
+			return []arangodb.FileSymbol{
+				{QName: qname, Name: "Save", Kind: "function", Pos: 3},
+				{QName: "example.com/app/zz.Load", Name: "Load", Kind: "function", Pos: 9},
+			}, nil
+		}
+
+		result, err := tools.Execute(ctx, "codegraph", `{"operation":"siblings","qname":"`+qname+`"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("Siblings of " + qname))
+		Expect(result).To(ContainSubstring("example.com/app/zz.Load"))
+	})
+
+	It("lists main functions and route registrations as entrypoints", func() {
+		mainFile := filepath.Join(tempDir, "src", "main.go")
+		Expect(os.WriteFile(filepath.Join(tempDir, "src", "routes.go"), []byte(
+			"package main\n\nfunc routes(mux *http.ServeMux) {\n\tmux.HandleFunc(\"POST /webhooks\", handleWebhook)\n\tr.GET(\"/health\", health)\n}\n",
+		), 0o644)).To(Succeed())
+
+		var kinds []string
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			kinds = append(kinds, opts.Kind)
+			if opts.Name != "main" {
+				return nil, 0, nil
+			}
+			return []arangodb.SearchResult{
+				{QName: "example.com/app.main", Name: "main", Kind: "function", Filepath: mainFile, Pos: 5, Signature: "func main()"},
+			}, 1, nil
+		}
+
+		result, err := tools.Execute(ctx, "codegraph", `{"operation":"entrypoints"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kinds).To(Equal([]string{"function", "function"}))
+		Expect(result).To(ContainSubstring("main functions (1):"))
+		Expect(result).To(ContainSubstring("src/main.go:5\tfunction\texample.com/app.main"))
+		Expect(result).To(ContainSubstring("init functions (0):"))
+		Expect(result).To(ContainSubstring("src/routes.go:4\tPOST /webhooks"))
+		Expect(result).To(ContainSubstring("src/routes.go:5\tGET /health"))
+	})
+
+	It("renders the type hierarchy of an interface as a tree", func() {
+		ioFile := filepath.Join(tempDir, "src", "io.go")
+		storeFile := filepath.Join(tempDir, "src", "store.go")
+		reader := arangodb.GraphNode{QName: "example.com/app.Reader", Name: "Reader", Kind: "interface", Filepath: ioFile, Pos: 3}
+		readWriter := arangodb.GraphNode{QName: "example.com/app.ReadWriter", Name: "ReadWriter", Kind: "interface", Filepath: ioFile, Pos: 7}
+		memoryStore := arangodb.GraphNode{QName: "example.com/app.MemoryStore", Name: "MemoryStore", Kind: "struct", Filepath: storeFile, Pos: 5}
+
+		fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+			return arangodb.ResolvedSymbol{QName: reader.QName, Name: reader.Name, Kind: reader.Kind, Filepath: reader.Filepath, Pos: reader.Pos}, nil
+		}
+		fake.getInheritorsFn = func(ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
+			if qname == reader.QName {
+				return []arangodb.GraphNode{readWriter}, nil
+			}
+			return nil, nil
+		}
+		fake.getImplsFn = func(ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
+			return []arangodb.GraphNode{memoryStore}, nil
+		}
+		fake.getEmbeddedFn = func(ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
+			if qname == readWriter.QName {
+				return []arangodb.GraphNode{reader}, nil
+			}
+			return nil, nil
+		}
+
+		result, err := tools.Execute(ctx, "codegraph", `{"operation":"hierarchy","name":"Reader","kind":"interface"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("Hierarchy of example.com/app.Reader (depth 2):\n" +
+			"src/io.go:3\tinterface\texample.com/app.Reader\n" +
+			"  embedded by:\n" +
+			"    src/io.go:7\tinterface\texample.com/app.ReadWriter\n" +
+			"      implemented by:\n" +
+			"        src/store.go:5\tstruct\texample.com/app.MemoryStore\n" +
+			"  implemented by:\n" +
+			"    src/store.go:5\tstruct\texample.com/app.MemoryStore"))
+	})
+
+	Describe("usages", func() {
+		var gotKind arangodb.UsageKind
+
+		BeforeEach(func() {
+			mainFile := filepath.Join(tempDir, "src", "main.go")
+			// One usage of each kind; the fake filters like GetUsages does.
+			all := []arangodb.GraphNode{
+				{QName: "example.com/app.Save", Kind: "function", Filepath: mainFile, Pos: 3, Usage: arangodb.UsageParam},
+				{QName: "example.com/app.Load", Kind: "function", Filepath: mainFile, Pos: 7, Usage: arangodb.UsageReturn},
+				{QName: "example.com/app.Cache.issue", Kind: "member", Filepath: mainFile, Pos: 12, Usage: arangodb.UsageField},
+			}
+			fake.getUsagesFn = func(ctx context.Context, qname string, kind arangodb.UsageKind) ([]arangodb.GraphNode, error) {
+				gotKind = kind
+				var nodes []arangodb.GraphNode
+				for _, n := range all {
+					if kind == arangodb.UsageAny || n.Usage == kind {
+						nodes = append(nodes, n)
+					}
+				}
+				return nodes, nil
+			}
+		})
+
+		It("labels each usage with how the type is used", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"usages","qname":"example.com/app.Issue"}`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gotKind).To(Equal(arangodb.UsageAny))
+			Expect(result).To(ContainSubstring("src/main.go:3\tfunction (param)\texample.com/app.Save"))
+			Expect(result).To(ContainSubstring("src/main.go:7\tfunction (return)\texample.com/app.Load"))
+			Expect(result).To(ContainSubstring("src/main.go:12\tmember (field)\texample.com/app.Cache.issue"))
+		})
+
+		It("excludes return-only usages with usage_kind=param", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"usages","qname":"example.com/app.Issue","usage_kind":"param"}`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gotKind).To(Equal(arangodb.UsageParam))
+			Expect(result).To(ContainSubstring("example.com/app.Save"))
+			Expect(result).NotTo(ContainSubstring("example.com/app.Load"))
+			Expect(result).NotTo(ContainSubstring("example.com/app.Cache.issue"))
+		})
+
+		It("rejects an unknown usage_kind", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"usages","qname":"example.com/app.Issue","usage_kind":"embed"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(`Error: invalid usage_kind "embed". Supported: any, param, return, field.`))
+		})
+	})
+
+	Describe("neighborhood", func() {
+		const qname = "example.com/app.MemoryStore"
+		mainFile := func() string { return filepath.Join(tempDir, "src", "main.go") }
+
+		BeforeEach(func() {
+			fake.getCallersFn = func(ctx context.Context, q string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error) {
+				Expect(q).To(Equal(qname))
+				Expect(depth).To(Equal(1))
+				// 12 callers in total; the fake serves the requested window.
+				var nodes []arangodb.GraphNode
+				for i := 0; i < min(page.Limit, 12); i++ {
+					nodes = append(nodes, arangodb.GraphNode{QName: fmt.Sprintf("example.com/app.Caller%02d", i), Kind: "function", Filepath: mainFile(), Pos: i + 1})
+				}
+				return nodes, 12, nil
+			}
+			fake.getCalleesFn = func(ctx context.Context, q string, depth int, page arangodb.Page) ([]arangodb.GraphNode, int, error) {
+				return []arangodb.GraphNode{{QName: "example.com/app.flush", Kind: "function", Filepath: mainFile(), Pos: 20}}, 1, nil
+			}
+			fake.traverseFromFn = func(ctx context.Context, qnames []string, opts arangodb.TraversalOptions) ([]arangodb.GraphNode, []arangodb.GraphEdge, error) {
+				Expect(qnames).To(Equal([]string{qname}))
+				Expect(opts).To(Equal(arangodb.TraversalOptions{EdgeTypes: []string{"implements"}, Direction: arangodb.DirectionOutbound, MaxDepth: 1}))
+				return []arangodb.GraphNode{
+					{QName: "example.com/app.Writer", Kind: "interface"},
+					{QName: "example.com/app.Reader", Kind: "interface"},
+				}, nil, nil
+			}
+			fake.getImplsFn = func(ctx context.Context, q string) ([]arangodb.GraphNode, error) {
+				return []arangodb.GraphNode{{QName: "example.com/app.CachedStore", Kind: "struct", Filepath: mainFile(), Pos: 30}}, nil
+			}
+		})
+
+		It("groups the direct neighbors under their headers with counts", func() {
+			res, err := tools.ExecuteResult(ctx, "codegraph", `{"operation":"neighborhood","qname":"`+qname+`"}`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(res.Output).To(ContainSubstring("Neighborhood of example.com/app.MemoryStore (depth 1):"))
+			Expect(res.Output).To(ContainSubstring("callers (12):\n  src/main.go:1\tfunction\texample.com/app.Caller00\n"))
+			Expect(res.Output).To(ContainSubstring("example.com/app.Caller09"))
+			Expect(res.Output).NotTo(ContainSubstring("example.com/app.Caller10"))
+			Expect(res.Output).To(ContainSubstring(`[truncated] [Showing 10 of 12. Use codegraph(operation="callers") for the full list.]`))
+			Expect(res.Truncated).To(BeTrue())
+
+			Expect(res.Output).To(ContainSubstring("callees (1):\n  src/main.go:20\tfunction\texample.com/app.flush\n"))
+			Expect(res.Output).To(ContainSubstring("implements (2):\n  interface\texample.com/app.Reader\n  interface\texample.com/app.Writer\n"))
+			Expect(res.Output).To(ContainSubstring("implemented_by (1):\n  src/main.go:30\tstruct\texample.com/app.CachedStore\n"))
+			Expect(res.Output).To(ContainSubstring("used_by (0):\n  (none)"))
+		})
+
+		It("reports a failing group", func() {
+			fake.getImplsFn = func(ctx context.Context, q string) ([]arangodb.GraphNode, error) {
+				return nil, errors.New("connection reset")
+			}
+
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"neighborhood","qname":"`+qname+`"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("Error querying implemented_by: connection reset"))
+		})
+
+		It("tells the agent to fall back when the graph is down", func() {
+			fake.getImplsFn = func(ctx context.Context, q string) ([]arangodb.GraphNode, error) {
+				return nil, fmt.Errorf("%w: connection refused", arangodb.ErrUnavailable)
+			}
+
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"neighborhood","qname":"`+qname+`"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HavePrefix("Error querying implemented_by: the code graph is unavailable"))
+			Expect(result).To(ContainSubstring("Use grep and read"))
+		})
+
+		It("suggests narrowing a query that timed out", func() {
+			fake.getImplsFn = func(ctx context.Context, q string) ([]arangodb.GraphNode, error) {
+				return nil, fmt.Errorf("%w: context deadline exceeded", arangodb.ErrTimeout)
+			}
+
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"neighborhood","qname":"`+qname+`"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HavePrefix("Error querying implemented_by: the code graph query timed out"))
+		})
+	})
+
+	Describe("config_usages", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(filepath.Join(tempDir, "src", "config.go"), []byte(
+				"package main\n\ntype PipelineConfig struct {\n\tRedisStream string `env:\"REDIS_STREAM\"`\n}\n",
+			), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tempDir, "src", "worker.go"), []byte(
+				"package main\n\nfunc run(cfg Config) {\n\tconsume(cfg.Pipeline.RedisStream)\n}\n",
+			), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tempDir, ".env.example"), []byte("REDIS_STREAM=relay-events\n"), 0o644)).To(Succeed())
+
+			fake.resolveSymbolFn = func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+				return arangodb.ResolvedSymbol{QName: "example.com/app.PipelineConfig", Name: opts.Name, Kind: "struct"}, nil
+			}
+			fake.getUsagesFn = func(ctx context.Context, qname string, kind arangodb.UsageKind) ([]arangodb.GraphNode, error) {
+				return []arangodb.GraphNode{
+					{QName: "example.com/app.run", Kind: "function", Filepath: filepath.Join(tempDir, "src", "worker.go"), Pos: 3},
+				}, nil
+			}
+		})
+
+		It("reports the field definition, readers and config struct usages for a field path", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"config_usages","name":"cfg.Pipeline.RedisStream"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("Definitions (1):\nsrc/config.go:4\tfield PipelineConfig.RedisStream"))
+			Expect(result).To(ContainSubstring("Usages (1):\nsrc/worker.go:4\tread\tconsume(cfg.Pipeline.RedisStream)"))
+			Expect(result).To(ContainSubstring("Usages of example.com/app.PipelineConfig"))
+			Expect(result).To(ContainSubstring("src/worker.go:3\tfunction\texample.com/app.run"))
+		})
+
+		It("reports env files and struct tags as definitions for an env key", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"config_usages","name":"REDIS_STREAM"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring(".env.example:1\tset\tREDIS_STREAM=relay-events"))
+			Expect(result).To(ContainSubstring("src/config.go:4\tfield PipelineConfig.RedisStream"))
+		})
+	})
+
+	Describe("describe", func() {
+		BeforeEach(func() {
+			dir := filepath.Join(tempDir, "internal", "store")
+			Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "doc.go"), []byte("// Package store persists issues.\n//\n// Details follow.\npackage store\n"), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "issue.go"), []byte("// Issue queries run in one transaction.\npackage store\n"), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "issue_test.go"), []byte("package store\n"), 0o644)).To(Succeed())
+
+			fake.fileSymbolsFn = func(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error) {
+				if opts.Filepath != "internal/store/issue.go" {
+					return nil, nil
+				}
+				return []arangodb.FileSymbol{
+					{QName: "example.com/app/store.IssueStore", Name: "IssueStore", Kind: "interface", Pos: 4},
+					{QName: "example.com/app/store.issueStore", Name: "issueStore", Kind: "struct", Pos: 10},
+					{QName: "example.com/app/store.NewIssueStore", Name: "NewIssueStore", Kind: "function", Pos: 20},
+					{QName: "example.com/app/store.issueStore.Get", Name: "Get", Kind: "method", Pos: 30},
+				}, nil
+			}
+		})
+
+		It("summarizes the package doc, file docs and exported symbols", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"describe","file":"internal/store"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("Directory internal/store: package store, 2 Go file(s)"))
+			Expect(result).To(ContainSubstring("Package doc: Package store persists issues."))
+			Expect(result).To(ContainSubstring("File docs (1):\nissue.go: Issue queries run in one transaction."))
+			Expect(result).To(ContainSubstring("Exported types (1):\ninternal/store/issue.go:4\tinterface\texample.com/app/store.IssueStore"))
+			Expect(result).To(ContainSubstring("Exported functions (1):\ninternal/store/issue.go:20\tfunction\texample.com/app/store.NewIssueStore"))
+			Expect(result).To(ContainSubstring("Exported methods (1):"))
+			Expect(result).NotTo(ContainSubstring("store.issueStore\n"))
+			Expect(result).To(ContainSubstring("Files: doc.go, issue.go"))
+		})
+
+		It("requires a directory", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"describe"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("Error: describe requires file"))
+		})
+	})
+
+	Describe("dependents", func() {
+		var gotOpts arangodb.DependentsOptions
+
+		BeforeEach(func() {
+			fake.getDependentsFn = func(ctx context.Context, opts arangodb.DependentsOptions) (arangodb.DependentsResult, error) {
+				gotOpts = opts
+				if opts.Package != "example.com/app/store" && opts.File != "store/issue.go" {
+					return arangodb.DependentsResult{}, arangodb.ErrNotFound
+				}
+				return arangodb.DependentsResult{Package: "example.com/app/store", Files: []arangodb.DependentFile{
+					{Filepath: filepath.Join(tempDir, "api", "issues.go"), Namespace: "example.com/app/api"},
+					{Filepath: filepath.Join(tempDir, "api", "users.go"), Namespace: "example.com/app/api"},
+					{Filepath: filepath.Join(tempDir, "worker", "sync.go"), Namespace: "example.com/app/worker"},
+				}}, nil
+			}
+		})
+
+		It("lists importing files grouped by package", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"dependents","name":"example.com/app/store"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotOpts).To(Equal(arangodb.DependentsOptions{Package: "example.com/app/store"}))
+			Expect(result).To(ContainSubstring("Files importing example.com/app/store - 3 file(s) in 2 package(s):"))
+			Expect(result).To(ContainSubstring("api/issues.go\texample.com/app/api"))
+			Expect(result).To(ContainSubstring("By package:\nexample.com/app/api\t2 file(s)\nexample.com/app/worker\t1 file(s)"))
+		})
+
+		It("accepts a file in the package", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"dependents","file":"store/issue.go"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotOpts).To(Equal(arangodb.DependentsOptions{File: "store/issue.go"}))
+			Expect(result).To(ContainSubstring("Files importing example.com/app/store"))
+		})
+
+		It("rejects targets that aren't package paths", func() {
+			for target, want := range map[string]string{
+				`{"operation":"dependents"}`:                                      "requires name set to a package import path",
+				`{"operation":"dependents","name":"store/issue.go"}`:              `Pass it as file="store/issue.go"`,
+				`{"operation":"dependents","name":"./internal/store"}`:            "is a filesystem path",
+				`{"operation":"dependents","name":"example.com/app/store.Issue"}`: `looks like a symbol qname. Pass its package path (e.g. "example.com/app/store")`,
+				`{"operation":"dependents","name":"not a path"}`:                  "is not a package import path",
+			} {
+				result, err := tools.Execute(ctx, "codegraph", target)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(HavePrefix("Error:"), target)
+				Expect(result).To(ContainSubstring(want), target)
+			}
+		})
+
+		It("reports an unknown package", func() {
+			result, err := tools.Execute(ctx, "codegraph", `{"operation":"dependents","name":"example.com/app/missing"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring(`Error: package "example.com/app/missing" is not in the codegraph`))
+		})
+	})
+
+	Describe("read by qname", func() {
+		BeforeEach(func() {
+			src := "package main\n\n// Plan plans.\nfunc Plan() {\n\tstep1()\n\tstep2()\n}\n\nfunc Other() {}\n"
+			Expect(os.WriteFile(filepath.Join(tempDir, "src", "main.go"), []byte(src), 0o644)).To(Succeed())
+
+			fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+				Expect(opts.Name).To(Equal("Plan"))
+				return []arangodb.SearchResult{{
+					QName:    "example.com/app.Plan",
+					Name:     "Plan",
+					Kind:     "function",
+					Filepath: filepath.Join(tempDir, "src", "main.go"),
+					Pos:      4,
+				}}, 1, nil
+			}
+		})
+
+		It("reads exactly the symbol's span", func() {
+			fake.fileSymbolsFn = func(ctx context.Context, opts arangodb.FileSymbolsOptions) ([]arangodb.FileSymbol, error) {
+				return []arangodb.FileSymbol{
+					{QName: "example.com/app.Plan", Name: "Plan", Kind: "function", Pos: 4, End: 7},
+					{QName: "example.com/app.Other", Name: "Other", Kind: "function", Pos: 9, End: 9},
+				}, nil
+			}
+
+			args, _ := json.Marshal(map[string]any{"qname": "example.com/app.Plan"})
+			result, err := tools.Execute(ctx, "read", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("[example.com/app.Plan: lines 4-7 of src/main.go]"))
+			Expect(result).To(ContainSubstring("func Plan() {"))
+			Expect(result).To(ContainSubstring("step2()"))
+			Expect(result).NotTo(ContainSubstring("package main"))
+			Expect(result).NotTo(ContainSubstring("func Other"))
+		})
+
+		It("reports an unknown qname", func() {
+			args, _ := json.Marshal(map[string]any{"qname": "example.com/app.Missing"})
+			fake.searchSymbolsFn = nil
+
+			result, err := tools.Execute(ctx, "read", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring(`no symbol found with qname "example.com/app.Missing"`))
+		})
+	})
+
+	Describe("raw_query", func() {
+		toolNames := func() []string {
+			var names []string
+			for _, def := range tools.Definitions() {
+				names = append(names, def.Name)
+			}
+			return names
+		}
+
+		It("is hidden unless enabled", func() {
+			Expect(toolNames()).NotTo(ContainElement("raw_query"))
+
+			result, err := tools.Execute(ctx, "raw_query", `{"query":"FOR f IN functions RETURN f.qname"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("raw_query is disabled"))
+
+			tools.WithRawQuery(true)
+			Expect(toolNames()).To(ContainElement("raw_query"))
+		})
+
+		It("returns rows for a valid read query", func() {
+			fake.rawQueryFn = func(ctx context.Context, query string) (arangodb.RawQueryResult, error) {
+				return arangodb.RawQueryResult{Rows: []any{"app.Handle", map[string]any{"file": "api.go", "n": 3}}}, nil
+			}
+			tools.WithRawQuery(true)
+
+			result, err := tools.Execute(ctx, "raw_query", `{"query":"FOR f IN functions RETURN f.qname"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("2 row(s):\n\"app.Handle\"\n{\"file\":\"api.go\",\"n\":3}"))
+		})
+
+		It("rejects write queries", func() {
+			fake.rawQueryFn = func(ctx context.Context, query string) (arangodb.RawQueryResult, error) {
+				Fail("write query reached the database")
+				return arangodb.RawQueryResult{}, nil
+			}
+			tools.WithRawQuery(true)
+
+			result, err := tools.Execute(ctx, "raw_query", `{"query":"FOR f IN functions REMOVE f IN functions"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("Error: query not allowed: REMOVE is a write operation"))
+		})
+	})
 })
@@ -0,0 +1,243 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"basegraph.co/relay/common/arangodb"
+)
+
+const maxConfigUsageResults = 30
+
+// configFileExts are non-Go files where config keys are commonly set.
+var configFileExts = map[string]bool{
+	".yaml": true, ".yml": true, ".toml": true, ".json": true,
+	".ini": true, ".conf": true, ".env": true, ".sh": true, ".example": true,
+}
+
+// configSite is a line where a config key is defined or read.
+type configSite struct {
+	file string
+	line int
+	what string // "field PipelineConfig.RedisStream", "set" (config file) or "read"
+	text string
+}
+
+// configStruct is a Go struct declaring the key as a field or in a field tag.
+type configStruct struct {
+	name string
+	file string
+}
+
+// executeCodegraphConfigUsages answers "where is X configured and used" for an
+// env key (REPO_ROOT) or a config field path (cfg.Pipeline.RedisStream).
+// Definitions come from struct fields, struct tags and KEY= lines in config
+// files; usages from a literal/selector scan plus codegraph usages of the
+// declaring struct.
+func (t *ExploreTools) executeCodegraphConfigUsages(ctx context.Context, params CodegraphParams) (string, error) {
+	key := strings.TrimSpace(params.Name)
+	if key == "" {
+		return "Error: name parameter required for config_usages (e.g. name=\"REPO_ROOT\" or name=\"cfg.Pipeline.RedisStream\").", nil
+	}
+
+	field := key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		field = key[i+1:]
+	}
+	if !token.IsIdentifier(field) {
+		return fmt.Sprintf("Error: %q is not a config key or field name.", key), nil
+	}
+	envStyle := !strings.Contains(key, ".")
+
+	selectorPattern := regexp.MustCompile(`\.` + regexp.QuoteMeta(field) + `\b`)
+	wordPattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(key) + `\b`)
+	assignPattern := regexp.MustCompile(`^#?\s*(export\s+)?` + regexp.QuoteMeta(key) + `\s*[=:]`)
+
+	var defs, uses []configSite
+	var structs []configStruct
+
+	include := func(relPath string) bool {
+		if strings.HasSuffix(relPath, ".go") {
+			return !strings.HasSuffix(relPath, "_test.go")
+		}
+		base := filepath.Base(relPath)
+		return envStyle && (strings.HasPrefix(base, ".env") || base == "Dockerfile" || configFileExts[filepath.Ext(base)])
+	}
+
+	t.walkRepoFiles(ctx, include, func(relPath string, data []byte) bool {
+		src := string(data)
+		if !strings.Contains(src, field) && !strings.Contains(src, key) {
+			return true
+		}
+		lines := strings.Split(src, "\n")
+
+		if !strings.HasSuffix(relPath, ".go") {
+			for i, line := range lines {
+				if !wordPattern.MatchString(line) {
+					continue
+				}
+				site := configSite{file: relPath, line: i + 1, what: "read", text: strings.TrimSpace(line)}
+				if assignPattern.MatchString(strings.TrimSpace(line)) {
+					site.what = "set"
+					defs = append(defs, site)
+				} else {
+					uses = append(uses, site)
+				}
+			}
+			return true
+		}
+
+		fieldDefs, fieldStructs := configFieldDefinitions(relPath, data, field, key, envStyle)
+		defs = append(defs, fieldDefs...)
+		structs = append(structs, fieldStructs...)
+
+		defLines := make(map[int]bool, len(fieldDefs))
+		for _, d := range fieldDefs {
+			defLines[d.line] = true
+		}
+		quoted := `"` + key + `"`
+		for i, line := range lines {
+			if defLines[i+1] {
+				continue
+			}
+			if selectorPattern.MatchString(line) || (envStyle && strings.Contains(line, quoted)) {
+				uses = append(uses, configSite{file: relPath, line: i + 1, what: "read", text: strings.TrimSpace(line)})
+			}
+		}
+		return true
+	})
+
+	if len(defs) == 0 && len(uses) == 0 {
+		return fmt.Sprintf("No definitions or usages of %s found.", key), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Config key %s:\n", key)
+	t.writeConfigSites(ctx, &sb, "Definitions", defs)
+	t.writeConfigSites(ctx, &sb, "Usages", uses)
+
+	for _, s := range structs {
+		symbol, err := t.resolveSymbol(ctx, s.name, "struct", s.file)
+		if err != nil {
+			fmt.Fprintf(&sb, "\n(codegraph could not resolve %s in %s: %s)\n", s.name, s.file, err)
+			continue
+		}
+		nodes, err := t.arango.GetUsages(ctx, symbol.QName, arangodb.UsageAny)
+		if err != nil {
+			slog.ErrorContext(ctx, "codegraph config usages failed", "qname", symbol.QName, "error", err)
+			fmt.Fprintf(&sb, "\nError querying usages of %s: %s\n", symbol.QName, err)
+			continue
+		}
+		sb.WriteString("\n")
+		sb.WriteString(t.formatRelationshipResults(ctx, "Usages", symbol.QName, 1, nodes, 0, len(nodes)))
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func (t *ExploreTools) writeConfigSites(ctx context.Context, sb *strings.Builder, heading string, sites []configSite) {
+	sort.SliceStable(sites, func(i, j int) bool {
+		if sites[i].file != sites[j].file {
+			return sites[i].file < sites[j].file
+		}
+		return sites[i].line < sites[j].line
+	})
+
+	fmt.Fprintf(sb, "\n%s (%d):\n", heading, len(sites))
+	for i, s := range sites {
+		if i == maxConfigUsageResults {
+			sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d. grep the key to see the rest.]", maxConfigUsageResults, len(sites))) + "\n")
+			break
+		}
+		text := s.text
+		if len(text) > maxCodegraphSignatureLen {
+			text = text[:maxCodegraphSignatureLen] + "..."
+		}
+		fmt.Fprintf(sb, "%s:%d\t%s\t%s\n", s.file, s.line, s.what, text)
+	}
+}
+
+// configFieldDefinitions finds struct fields named field, or (for env-style
+// keys) whose tag names key, e.g. `env:"REPO_ROOT"`.
+func configFieldDefinitions(relPath string, src []byte, field, key string, envStyle bool) ([]configSite, []configStruct) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, relPath, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil
+	}
+	lines := strings.Split(string(src), "\n")
+
+	var defs []configSite
+	var structs []configStruct
+	ast.Inspect(file, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		found := false
+		for _, f := range st.Fields.List {
+			if !fieldMatches(f, field, key, envStyle) {
+				continue
+			}
+			name := field
+			if len(f.Names) > 0 {
+				name = f.Names[0].Name
+			}
+			line := fset.Position(f.Pos()).Line
+			defs = append(defs, configSite{
+				file: relPath,
+				line: line,
+				what: "field " + ts.Name.Name + "." + name,
+				text: strings.TrimSpace(lines[line-1]),
+			})
+			found = true
+		}
+		if found {
+			structs = append(structs, configStruct{name: ts.Name.Name, file: relPath})
+		}
+		return true
+	})
+	return defs, structs
+}
+
+// fieldMatches reports whether a struct field declares the config key.
+func fieldMatches(f *ast.Field, field, key string, envStyle bool) bool {
+	for _, n := range f.Names {
+		if n.Name == field {
+			return true
+		}
+	}
+	if !envStyle || f.Tag == nil {
+		return false
+	}
+	raw, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return false
+	}
+	tag := reflect.StructTag(raw)
+	for _, name := range []string{"env", "envconfig", "mapstructure", "yaml", "json", "koanf"} {
+		value, ok := tag.Lookup(name)
+		if !ok {
+			continue
+		}
+		if tagKey, _, _ := strings.Cut(value, ","); tagKey == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+
+	"basegraph.co/relay/common/arangodb"
+)
+
+const maxDependentsResults = 50
+
+var (
+	// importPathPattern matches Go import paths: slash-separated elements of
+	// letters, digits and ._-~ (e.g. "basegraph.co/relay/common/arangodb").
+	importPathPattern = regexp.MustCompile(`^[A-Za-z0-9_.~-]+(/[A-Za-z0-9_.~-]+)*$`)
+	// symbolSuffixPattern matches a trailing ".Name" as in a symbol qname.
+	symbolSuffixPattern = regexp.MustCompile(`\.[A-Z]\w*$`)
+)
+
+// executeCodegraphDependents lists the files that import a package, for
+// blast-radius questions. name is the package import path; file is an
+// alternative that selects the package the file belongs to.
+func (t *ExploreTools) executeCodegraphDependents(ctx context.Context, params CodegraphParams) (string, error) {
+	opts, errMsg := dependentsOptions(params)
+	if errMsg != "" {
+		return errMsg, nil
+	}
+
+	res, err := t.arango.GetDependents(ctx, opts)
+	if errors.Is(err, arangodb.ErrNotFound) {
+		if opts.File != "" {
+			return fmt.Sprintf("Error: file %q is not in the codegraph. Use file_symbols to check the path, or pass name=<package import path>.", opts.File), nil
+		}
+		return fmt.Sprintf("Error: package %q is not in the codegraph (no file imports it, or it isn't indexed). Check the import path with grep(pattern=%q, literal=true).", opts.Package, `"`+opts.Package+`"`), nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "codegraph dependents failed", "package", opts.Package, "file", opts.File, "error", err)
+		return formatCodegraphError("querying dependents", err), nil
+	}
+	if len(res.Files) == 0 {
+		return fmt.Sprintf("No files import %s.", res.Package), nil
+	}
+
+	packages := map[string]int{}
+	for _, f := range res.Files {
+		packages[f.Namespace]++
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Files importing %s - %d file(s) in %d package(s):\n", res.Package, len(res.Files), len(packages))
+	for i, f := range res.Files {
+		if i == maxDependentsResults {
+			sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d files. See the package summary below.]", maxDependentsResults, len(res.Files))))
+			sb.WriteString("\n")
+			break
+		}
+		path := t.makeCodegraphPathRelative(f.Filepath)
+		if path == "" {
+			path = f.Filepath
+		}
+		fmt.Fprintf(&sb, "%s\t%s\n", path, f.Namespace)
+	}
+
+	if len(packages) > 1 {
+		names := make([]string, 0, len(packages))
+		for name := range packages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteString("\nBy package:\n")
+		for _, name := range names {
+			fmt.Fprintf(&sb, "%s\t%d file(s)\n", name, packages[name])
+		}
+	}
+
+	return withTokenEstimate(strings.TrimSpace(sb.String())), nil
+}
+
+// dependentsOptions validates the dependents target, returning an actionable
+// error message for anything that isn't a package path or Go file.
+func dependentsOptions(params CodegraphParams) (arangodb.DependentsOptions, string) {
+	pkg := strings.Trim(strings.TrimSpace(params.Name), "\"")
+	file := strings.TrimSpace(params.File)
+
+	switch {
+	case pkg == "" && file == "":
+		return arangodb.DependentsOptions{}, `Error: dependents requires name set to a package import path (e.g. name="basegraph.co/relay/common/arangodb"), or file set to a .go file in the package.`
+	case pkg == "":
+		if !strings.HasSuffix(file, ".go") {
+			return arangodb.DependentsOptions{}, fmt.Sprintf("Error: file %q is not a .go file. Pass a file in the package, or name=<package import path>.", file)
+		}
+		return arangodb.DependentsOptions{File: file}, ""
+	case strings.HasSuffix(pkg, ".go"):
+		return arangodb.DependentsOptions{}, fmt.Sprintf("Error: %q is a file, not a package path. Pass it as file=%q instead.", pkg, pkg)
+	case strings.HasPrefix(pkg, "/") || strings.HasPrefix(pkg, "./") || strings.HasPrefix(pkg, "../"):
+		return arangodb.DependentsOptions{}, fmt.Sprintf("Error: %q is a filesystem path. Pass the package import path as written in import statements (e.g. \"basegraph.co/relay/common/arangodb\").", pkg)
+	case !importPathPattern.MatchString(pkg):
+		return arangodb.DependentsOptions{}, fmt.Sprintf("Error: %q is not a package import path (e.g. \"basegraph.co/relay/common/arangodb\").", pkg)
+	case symbolSuffixPattern.MatchString(pkg):
+		return arangodb.DependentsOptions{}, fmt.Sprintf("Error: %q looks like a symbol qname. Pass its package path (e.g. %q), or use callers/usages for the symbol.", pkg, symbolSuffixPattern.ReplaceAllString(pkg, ""))
+	}
+	return arangodb.DependentsOptions{Package: pkg}, ""
+}
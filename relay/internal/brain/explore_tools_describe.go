@@ -0,0 +1,173 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"basegraph.co/relay/common/arangodb"
+)
+
+const (
+	maxDescribeSymbols = 30  // Per section (types, functions, methods)
+	maxDescribeDocLen  = 300 // Chars kept from each file's doc comment
+)
+
+// describeFile is one Go source file of the described directory.
+type describeFile struct {
+	name    string
+	relPath string
+	pkg     string
+	doc     string // First paragraph of the comment above the package clause
+}
+
+// executeCodegraphDescribe summarizes a directory for orientation: package
+// name and doc, per-file doc comments, and the exported types, functions and
+// methods from the codegraph. It only aggregates; the agent does the
+// synthesis.
+func (t *ExploreTools) executeCodegraphDescribe(ctx context.Context, params CodegraphParams) (string, error) {
+	dir := strings.Trim(strings.TrimSpace(params.File), "/")
+	if dir == "" {
+		return "Error: describe requires file set to a directory (e.g. file=\"internal/brain\").", nil
+	}
+	fullDir := filepath.Join(t.repoRoot, dir)
+	if !pathWithinRoot(t.repoRoot, fullDir) {
+		return "Error: path outside repository", nil
+	}
+
+	files, err := describeGoFiles(fullDir, dir)
+	if err != nil {
+		return fmt.Sprintf("Error: %s", err), nil
+	}
+	if len(files) == 0 {
+		return fmt.Sprintf("No Go files in %s.", dir), nil
+	}
+
+	var types, funcs, methods []string
+	for _, f := range files {
+		symbols, err := t.arango.GetFileSymbols(ctx, arangodb.FileSymbolsOptions{Filepath: f.relPath})
+		if err != nil {
+			slog.ErrorContext(ctx, "codegraph describe failed", "file", f.relPath, "error", err)
+			return formatCodegraphError("querying file symbols", err), nil
+		}
+		for _, s := range t.supportedFileSymbols(symbols, "") {
+			if !isExportedName(s.Name) {
+				continue
+			}
+			line := t.formatCodegraphLine(f.relPath, s.Pos, s.Kind, s.QName, s.Signature)
+			switch s.Kind {
+			case "function":
+				funcs = append(funcs, line)
+			case "method":
+				methods = append(methods, line)
+			default:
+				types = append(types, line)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Directory %s: package %s, %d Go file(s)\n", dir, files[0].pkg, len(files))
+
+	var fileDocs []string
+	for _, f := range files {
+		if f.doc == "" {
+			continue
+		}
+		if strings.HasPrefix(f.doc, "Package ") {
+			fmt.Fprintf(&sb, "Package doc: %s\n", f.doc)
+			continue
+		}
+		fileDocs = append(fileDocs, fmt.Sprintf("%s: %s", f.name, f.doc))
+	}
+	t.writeDescribeSection(ctx, &sb, "File docs", fileDocs)
+
+	t.writeDescribeSection(ctx, &sb, "Exported types", types)
+	t.writeDescribeSection(ctx, &sb, "Exported functions", funcs)
+	t.writeDescribeSection(ctx, &sb, "Exported methods", methods)
+	if len(types)+len(funcs)+len(methods) == 0 {
+		sb.WriteString("\nNo exported symbols found in the codegraph for this directory.\n")
+	}
+
+	var names []string
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+	fmt.Fprintf(&sb, "\nFiles: %s\n", strings.Join(names, ", "))
+
+	return withTokenEstimate(strings.TrimSpace(sb.String())), nil
+}
+
+func (t *ExploreTools) writeDescribeSection(ctx context.Context, sb *strings.Builder, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n%s (%d):\n", title, len(lines))
+	for i, line := range lines {
+		if i == maxDescribeSymbols {
+			sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d. Use file_symbols on a file for the rest.]", maxDescribeSymbols, len(lines))))
+			sb.WriteString("\n")
+			break
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+}
+
+// describeGoFiles lists the non-test Go files directly in dir with their
+// package name and leading doc comment.
+func describeGoFiles(fullDir, relDir string) ([]describeFile, error) {
+	entries, err := os.ReadDir(fullDir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory %s: %w", relDir, err)
+	}
+
+	var files []describeFile
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(fullDir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		doc := ""
+		if f.Doc != nil {
+			doc = firstParagraph(f.Doc.Text())
+			if len(doc) > maxDescribeDocLen {
+				doc = doc[:maxDescribeDocLen] + "..."
+			}
+		}
+		files = append(files, describeFile{
+			name:    name,
+			relPath: filepath.ToSlash(filepath.Join(relDir, name)),
+			pkg:     f.Name.Name,
+			doc:     doc,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files, nil
+}
+
+// firstParagraph joins the lines of text up to the first blank line.
+func firstParagraph(text string) string {
+	para, _, _ := strings.Cut(strings.TrimSpace(text), "\n\n")
+	return strings.Join(strings.Fields(para), " ")
+}
+
+func isExportedName(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
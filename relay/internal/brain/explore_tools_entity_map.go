@@ -0,0 +1,275 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"basegraph.co/relay/common/arangodb"
+	"basegraph.co/relay/common/llm"
+)
+
+const (
+	maxEntityMapUsages     = 30
+	maxEntityMapFieldFiles = 3 // Files listed per field; the rest are counted
+	minEntityMapKeyLength  = 3 // Shorter keys ("id") match everywhere and aren't searched
+)
+
+// EntityMapParams for mapping a struct's fields, users and storage.
+type EntityMapParams struct {
+	Name  string `json:"name,omitempty" jsonschema:"description=Struct name (e.g. 'Issue'). Use qname instead when the name is ambiguous."`
+	QName string `json:"qname,omitempty" jsonschema:"description=Fully qualified struct name from codegraph (e.g. 'basegraph.co/relay/internal/model.Issue')"`
+	File  string `json:"file,omitempty" jsonschema:"description=File defining the struct, to disambiguate name"`
+}
+
+// entityField is one struct field with the keys it is stored under.
+type entityField struct {
+	name    string
+	typ     string
+	dbKey   string
+	jsonKey string
+}
+
+// storageKey is the name the field is persisted under: its db tag, else its
+// json tag.
+func (f entityField) storageKey() string {
+	if f.dbKey != "" {
+		return f.dbKey
+	}
+	return f.jsonKey
+}
+
+func entityMapToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "entity_map",
+		Description: `Map a Go struct for the Entity & Join Map: its fields with db/json tags, the functions that take or return it, and the files that mention each field's storage key (SQL, queries, other structs).
+
+One call replaces codegraph resolve + reading the struct + codegraph usages + a grep per column.
+
+Examples:
+  entity_map(name="Issue")
+  entity_map(qname="basegraph.co/relay/internal/model.Issue")
+
+Fields without tags are listed but not searched; keys shorter than 3 characters (like "id") are too generic to search.`,
+		Parameters: llm.GenerateSchemaFrom(EntityMapParams{}),
+	}
+}
+
+// executeEntityMap combines the struct's source, codegraph usages and a
+// whole-word search per storage key into one table.
+func (t *ExploreTools) executeEntityMap(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[EntityMapParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse entity_map params: %w", err)
+	}
+
+	if t.arango == nil {
+		return "Codegraph is not available. Use grep and read tools instead.", nil
+	}
+	if msg := t.codegraphIndexMessage(ctx); msg != "" {
+		return msg, nil
+	}
+	if strings.TrimSpace(params.Name) == "" && strings.TrimSpace(params.QName) == "" {
+		return "Error: entity_map requires name or qname.", nil
+	}
+
+	symbol, errMsg := t.resolveSymbolWithFile(ctx, CodegraphParams{
+		Name:  params.Name,
+		QName: strings.TrimSpace(params.QName),
+		Kind:  "struct",
+		File:  params.File,
+	})
+	if errMsg != "" {
+		return errMsg, nil
+	}
+	relPath := t.makeCodegraphPathRelative(symbol.Filepath)
+	if !strings.HasSuffix(relPath, ".go") {
+		return fmt.Sprintf("Error: entity_map supports Go structs only; %s is defined in %s.", symbol.QName, relPath), nil
+	}
+
+	fields, err := t.structFields(relPath, symbol.Name)
+	if err != nil {
+		return fmt.Sprintf("Error reading struct %s: %s", symbol.QName, err), nil
+	}
+
+	usages, err := t.arango.GetUsages(ctx, symbol.QName, arangodb.UsageAny)
+	if err != nil {
+		slog.ErrorContext(ctx, "codegraph usages failed", "qname", symbol.QName, "error", err)
+		return formatCodegraphError("querying usages", err), nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Grep)
+	defer cancel()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Entity map for %s (%s:%d)\n\n", symbol.QName, relPath, symbol.Pos)
+
+	fmt.Fprintf(&sb, "Fields (%d):\n", len(fields))
+	sb.WriteString("| Field | Type | db | json | Key found in |\n")
+	sb.WriteString("|-------|------|----|------|--------------|\n")
+	for _, f := range fields {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n",
+			f.name, f.typ, orDash(f.dbKey), orDash(f.jsonKey), t.fieldReferences(timeoutCtx, f.storageKey(), relPath))
+	}
+
+	fmt.Fprintf(&sb, "\nUsed by (%d as param, return value or field):\n", len(usages))
+	if len(usages) == 0 {
+		sb.WriteString("(none in the codegraph)\n")
+	}
+	for i, n := range usages {
+		if i == maxEntityMapUsages {
+			sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d. Use codegraph usages for the full list.]", maxEntityMapUsages, len(usages))) + "\n")
+			break
+		}
+		sb.WriteString(t.formatCodegraphLine(n.Filepath, n.Pos, nodeKindLabel(n), n.QName, "") + "\n")
+	}
+
+	return withTokenEstimate(strings.TrimSpace(sb.String())), nil
+}
+
+// structFields parses relPath and returns the fields of struct name, with
+// embedded fields named after their type.
+func (t *ExploreTools) structFields(relPath, name string) ([]entityField, error) {
+	src, err := os.ReadFile(filepath.Join(t.repoRoot, relPath))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, relPath, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var st *ast.StructType
+	ast.Inspect(file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == name {
+			st, _ = ts.Type.(*ast.StructType)
+			return false
+		}
+		return st == nil
+	})
+	if st == nil {
+		return nil, fmt.Errorf("no struct %s in %s", name, relPath)
+	}
+
+	var fields []entityField
+	for _, field := range st.Fields.List {
+		var typ bytes.Buffer
+		_ = printer.Fprint(&typ, fset, field.Type)
+
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquoted)
+			}
+		}
+		f := entityField{typ: typ.String(), dbKey: tagKey(tag, "db"), jsonKey: tagKey(tag, "json")}
+
+		if len(field.Names) == 0 {
+			f.name = strings.TrimPrefix(f.typ, "*")
+			fields = append(fields, f)
+			continue
+		}
+		for _, n := range field.Names {
+			f.name = n.Name
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// tagKey returns the name part of a struct tag ("id" for `json:"id,omitempty"`),
+// or "" when the tag is missing or "-".
+func tagKey(tag reflect.StructTag, key string) string {
+	value, _, _ := strings.Cut(tag.Get(key), ",")
+	if value == "-" {
+		return ""
+	}
+	return value
+}
+
+// fieldReferences lists the files other than the struct's own that contain
+// key as a whole word.
+func (t *ExploreTools) fieldReferences(ctx context.Context, key, ownFile string) string {
+	switch {
+	case key == "":
+		return "-"
+	case len(key) < minEntityMapKeyLength:
+		return "(too generic to search)"
+	}
+
+	files, err := t.filesWithWord(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "entity_map key search failed", "key", key, "error", err)
+		return "(search failed)"
+	}
+	var others []string
+	for _, f := range files {
+		if f != ownFile {
+			others = append(others, f)
+		}
+	}
+	if len(others) == 0 {
+		return "(not found)"
+	}
+	if len(others) > maxEntityMapFieldFiles {
+		return fmt.Sprintf("%s, +%d more", strings.Join(others[:maxEntityMapFieldFiles], ", "), len(others)-maxEntityMapFieldFiles)
+	}
+	return strings.Join(others, ", ")
+}
+
+// filesWithWord returns the repo-relative paths of files containing word as
+// a whole word, sorted, using the same rg/grep setup as the grep tool.
+func (t *ExploreTools) filesWithWord(ctx context.Context, word string) ([]string, error) {
+	params := GrepParams{Pattern: word, Literal: true, FilesOnly: true}
+
+	var cmd *exec.Cmd
+	switch {
+	case t.hasRipgrep:
+		cmd = exec.CommandContext(ctx, "rg", append([]string{"-w"}, ripgrepArgs(params, t.repoRoot)...)...)
+	case t.hasGrep:
+		cmd = exec.CommandContext(ctx, "grep", append([]string{"-w"}, fallbackGrepArgs(params, t.repoRoot)...)...)
+	default:
+		return nil, fmt.Errorf("neither rg nor grep is installed")
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line == "" {
+			continue
+		}
+		rel, err := filepath.Rel(t.repoRoot, line)
+		if err != nil || shouldSkipFile(rel) {
+			continue
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/common/arangodb"
+	"basegraph.co/relay/internal/brain"
+)
+
+var _ = Describe("ExploreTools entity_map", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		fake    *fakeArangoClient
+		tools   *brain.ExploreTools
+	)
+
+	write := func(name, content string) {
+		path := filepath.Join(tempDir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+	}
+
+	entityMap := func(params map[string]any) string {
+		args, _ := json.Marshal(params)
+		result, err := tools.Execute(ctx, "entity_map", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-entity-map-test-*")
+		Expect(err).NotTo(HaveOccurred())
+
+		write("model/issue.go", `package model
+
+type Base struct{}
+
+type Issue struct {
+	*Base
+	ID          int64  `+"`db:\"id\" json:\"id\"`"+`
+	ExternalRef string `+"`db:\"external_ref\" json:\"externalRef,omitempty\"`"+`
+	Title       string `+"`json:\"title\"`"+`
+	Secret      string `+"`json:\"-\"`"+`
+	A, B        bool
+}
+`)
+		write("store/queries.sql", "SELECT id, external_ref FROM issues WHERE external_ref = $1;\n")
+		write("api/issue_dto.go", "package api\n\ntype IssueDTO struct {\n\tTitle string `json:\"title\"`\n}\n")
+		// Substring matches must not count as references.
+		write("store/other.sql", "SELECT external_reference FROM links;\n")
+
+		fake = &fakeArangoClient{
+			resolveSymbolFn: func(ctx context.Context, opts arangodb.SearchOptions) (arangodb.ResolvedSymbol, error) {
+				Expect(opts.Kind).To(Equal("struct"))
+				return arangodb.ResolvedSymbol{
+					QName:    "example.com/model.Issue",
+					Name:     "Issue",
+					Kind:     "struct",
+					Filepath: filepath.Join(tempDir, "model", "issue.go"),
+					Pos:      5,
+				}, nil
+			},
+			getUsagesFn: func(ctx context.Context, qname string, kind arangodb.UsageKind) ([]arangodb.GraphNode, error) {
+				Expect(qname).To(Equal("example.com/model.Issue"))
+				return []arangodb.GraphNode{
+					{QName: "example.com/store.(*Store).Get", Kind: "method", Filepath: filepath.Join(tempDir, "store", "store.go"), Pos: 12, Usage: arangodb.UsageReturn},
+				}, nil
+			},
+		}
+		tools = brain.NewExploreTools(tempDir, fake, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	It("lists fields, their storage references and the struct's users", func() {
+		result := entityMap(map[string]any{"name": "Issue"})
+
+		Expect(result).To(ContainSubstring("Entity map for example.com/model.Issue (model/issue.go:5)"))
+		Expect(result).To(ContainSubstring("Fields (7):"))
+		Expect(result).To(ContainSubstring("| Base | *Base | - | - | - |"))
+		Expect(result).To(ContainSubstring("| ID | int64 | id | id | (too generic to search) |"))
+		Expect(result).To(ContainSubstring("| ExternalRef | string | external_ref | externalRef | store/queries.sql |"))
+		Expect(result).To(ContainSubstring("| Title | string | - | title | api/issue_dto.go |"))
+		Expect(result).To(ContainSubstring("| Secret | string | - | - | - |"))
+		Expect(result).To(ContainSubstring("| A | bool |"))
+		Expect(result).To(ContainSubstring("| B | bool |"))
+
+		Expect(result).To(ContainSubstring("Used by (1 as param, return value or field):"))
+		Expect(result).To(ContainSubstring("store/store.go:12\tmethod (return)\texample.com/store.(*Store).Get"))
+	})
+
+	It("resolves by qname", func() {
+		fake.resolveSymbolFn = nil
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			Expect(opts.Name).To(Equal("Issue"))
+			return []arangodb.SearchResult{
+				{QName: "example.com/model.Issue", Name: "Issue", Kind: "struct", Filepath: filepath.Join(tempDir, "model", "issue.go"), Pos: 5},
+			}, 1, nil
+		}
+
+		result := entityMap(map[string]any{"qname": "example.com/model.Issue"})
+		Expect(result).To(ContainSubstring("| ExternalRef | string | external_ref | externalRef | store/queries.sql |"))
+	})
+
+	It("reports missing input and non-struct symbols", func() {
+		Expect(entityMap(map[string]any{})).To(ContainSubstring("entity_map requires name or qname"))
+
+		write("model/issue.go", "package model\n\ntype Issue int\n")
+		Expect(entityMap(map[string]any{"name": "Issue"})).To(ContainSubstring("no struct Issue in model/issue.go"))
+	})
+
+	It("needs the codegraph", func() {
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
+
+		Expect(entityMap(map[string]any{"name": "Issue"})).To(ContainSubstring("Codegraph is not available"))
+	})
+})
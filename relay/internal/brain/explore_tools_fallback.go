@@ -0,0 +1,142 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Notes appended to tool descriptions when a search binary is missing, so
+// the model doesn't rely on features the fallback lacks.
+const (
+	grepFallbackNote = "\n\nDegraded mode: ripgrep is not installed, so grep runs GNU/BSD grep -E. Patterns are POSIX extended regex (no \\d, \\b or lookarounds; use [0-9]), .gitignore is not applied, and glob only matches file names (e.g. '*.go', not 'internal/**/*.go')."
+	grepMissingNote  = "\n\nUnavailable: neither ripgrep nor grep is installed on this host. Use codegraph or bash to search instead."
+	globFallbackNote = "\n\nDegraded mode: fd is not installed, so glob runs find -name. The pattern matches file names only (e.g. '*_test.go'); use path to narrow the directory instead of 'dir/**/' patterns."
+)
+
+// detectSearchBinaries records which of rg, grep and fd are on PATH and
+// warns once about missing ones. grep and glob fall back to grep and find.
+func (t *ExploreTools) detectSearchBinaries() {
+	t.hasRipgrep = onPath("rg")
+	t.hasGrep = onPath("grep")
+	t.hasFd = onPath("fd")
+
+	var missing []string
+	for name, ok := range map[string]bool{"rg": t.hasRipgrep, "fd": t.hasFd} {
+		if !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	sort.Strings(missing)
+	slog.Warn("explore search binaries missing, tools run in degraded mode",
+		"missing", strings.Join(missing, ","),
+		"grep_fallback", t.hasGrep)
+}
+
+func onPath(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// searchModeNote is the degraded-mode note for a tool's description, or ""
+// when its preferred binary is installed.
+func (t *ExploreTools) searchModeNote(tool string) string {
+	switch {
+	case tool == "grep" && !t.hasRipgrep && t.hasGrep:
+		return grepFallbackNote
+	case tool == "grep" && !t.hasRipgrep:
+		return grepMissingNote
+	case tool == "glob" && !t.hasFd:
+		return globFallbackNote
+	}
+	return ""
+}
+
+// fallbackGrepArgs builds a grep command line equivalent to ripgrepArgs as
+// far as grep allows: the same --null output, with hidden files and the
+// directories rg would usually ignore skipped by hand.
+func fallbackGrepArgs(params GrepParams, searchPath string) []string {
+	args := []string{
+		"-r",
+		"-I", // Skip binary files like rg does
+		"-n",
+		"-H",
+		"--null",
+		"--color=never",
+		"--exclude=.*",
+		"--exclude-dir=.*",
+		"--exclude-dir=node_modules",
+		"--exclude-dir=vendor",
+		"--exclude-dir=__pycache__",
+	}
+	if params.FilesOnly {
+		args = []string{"-r", "-I", "-l", "--color=never", "--exclude=.*", "--exclude-dir=.*",
+			"--exclude-dir=node_modules", "--exclude-dir=vendor", "--exclude-dir=__pycache__"}
+	}
+
+	if params.IgnoreCase {
+		args = append(args, "-i")
+	}
+
+	if params.Literal {
+		args = append(args, "-F")
+	} else {
+		args = append(args, "-E")
+	}
+
+	if params.Context > 0 && !params.FilesOnly {
+		args = append(args, fmt.Sprintf("-C%d", params.Context))
+	}
+
+	if params.Glob != "" {
+		args = append(args, "--include="+params.Glob)
+	}
+
+	return append(args, "-e", params.Pattern, searchPath)
+}
+
+// sortGrepOutput orders grep output by path, as rg --sort path does, so
+// pages stay stable across calls. Lines of one file keep their order. With
+// context, grep separates groups with "--"; those inside a file are kept and
+// files are joined with one.
+func sortGrepOutput(output []byte, withContext bool) []byte {
+	if len(output) == 0 {
+		return output
+	}
+
+	blocks := make(map[string][]string)
+	var files []string
+	current := ""
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if line != "--" {
+			current, _, _ = strings.Cut(line, "\x00")
+			if _, ok := blocks[current]; !ok {
+				files = append(files, current)
+			}
+		}
+		blocks[current] = append(blocks[current], line)
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	for i, f := range files {
+		lines := blocks[f]
+		for len(lines) > 0 && lines[len(lines)-1] == "--" {
+			lines = lines[:len(lines)-1]
+		}
+		if i > 0 && withContext {
+			buf.WriteString("--\n")
+		}
+		for _, line := range lines {
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes()
+}
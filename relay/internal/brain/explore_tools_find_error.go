@@ -0,0 +1,339 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"basegraph.co/relay/common/llm"
+)
+
+const (
+	maxFindErrorResults   = 10
+	minErrorStaticLength  = 6 // Ignore format strings that are mostly verbs, e.g. "%s: %w"
+	maxFindErrorFileBytes = 1 << 20
+)
+
+// FindErrorParams for locating where an error message originates.
+type FindErrorParams struct {
+	Message string `json:"message" jsonschema:"required,description=Error text as it appears in logs or the issue (e.g. 'load config: user 42 not found')"`
+	Path    string `json:"path,omitempty" jsonschema:"description=Directory to search. Defaults to repo root."`
+}
+
+// errorPackageConstructors are, per import path, the functions whose string
+// argument defines an error message.
+var errorPackageConstructors = map[string]map[string]bool{
+	"errors":                        {"New": true},
+	"fmt":                           {"Errorf": true},
+	"golang.org/x/xerrors":          {"New": true, "Errorf": true},
+	"github.com/pkg/errors":         {"New": true, "Errorf": true, "Wrap": true, "Wrapf": true, "WithMessage": true, "WithMessagef": true},
+	"github.com/cockroachdb/errors": {"New": true, "Newf": true, "Errorf": true, "Wrap": true, "Wrapf": true},
+}
+
+// ownErrorConstructorPattern matches a repo's own error constructors by
+// naming convention: NewNotFoundError, newErr, wrapErrorf. A bare Errorf is
+// left out; at package level that is usually a logger.
+var ownErrorConstructorPattern = regexp.MustCompile(`^(?:[Nn]ew\w*Err(?:or)?|\w+Errorf)$`)
+
+// formatVerbPattern matches fmt verbs including flags, width and precision (%s, %-10v, %.2f, %w).
+var formatVerbPattern = regexp.MustCompile(`%[-+# 0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?[a-zA-Z%]`)
+
+type errorSite struct {
+	path       string
+	line       int
+	function   string
+	format     string
+	staticLen  int
+	matchBegin int
+}
+
+func findErrorToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "find_error",
+		Description: `Find where an error message is created (fmt.Errorf, errors.New, errors.Wrap, or the repo's own
+New...Error constructors). Go only.
+
+Pass the error text as quoted in the issue or logs. Format verbs (%s, %d, %w) in the source are
+treated as wildcards, so rendered values and wrapped chains still match.
+
+Examples:
+  find_error(message="user 42 not found")
+  find_error(message="load config: open /etc/app.yaml: no such file", path="internal/")
+
+Returns file:line, the enclosing function and the source format string for each site.`,
+		Parameters: llm.GenerateSchemaFrom(FindErrorParams{}),
+	}
+}
+
+// executeFindError maps rendered error text back to the Go call sites that produce it.
+func (t *ExploreTools) executeFindError(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[FindErrorParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse find_error params: %w", err)
+	}
+
+	message := strings.TrimSpace(params.Message)
+	if message == "" {
+		return "Error: message is required", nil
+	}
+
+	searchPath := t.repoRoot
+	if params.Path != "" {
+		searchPath = filepath.Join(t.repoRoot, params.Path)
+	}
+	if !pathWithinRoot(t.repoRoot, searchPath) {
+		return "Error: path outside repository", nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Bash)
+	defer cancel()
+
+	var sites []errorSite
+	walkErr := filepath.WalkDir(searchPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if timeoutCtx.Err() != nil {
+			return timeoutCtx.Err()
+		}
+
+		relPath, relErr := filepath.Rel(t.repoRoot, path)
+		if relErr != nil {
+			return nil
+		}
+		if d.IsDir() {
+			sep := string(filepath.Separator)
+			if path != searchPath && shouldSkipFile(sep+relPath+sep) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || shouldSkipFile(relPath) {
+			return nil
+		}
+
+		sites = append(sites, findErrorSitesInFile(path, relPath, message)...)
+		return nil
+	})
+	if walkErr != nil && timeoutCtx.Err() == context.DeadlineExceeded {
+		return "Search timed out. Narrow the search with path.", nil
+	}
+
+	slog.DebugContext(ctx, "find_error completed",
+		"message", message,
+		"sites", len(sites))
+
+	if len(sites) == 0 {
+		return fmt.Sprintf("No error construction site matches: %s\n\nTry a shorter, distinctive part of the message, or grep for it.", message), nil
+	}
+
+	// Longest static text first: it is the most specific match. For wrapped
+	// chains, earlier segments of the message are the outer wrappers.
+	sort.SliceStable(sites, func(i, j int) bool {
+		if sites[i].staticLen != sites[j].staticLen {
+			return sites[i].staticLen > sites[j].staticLen
+		}
+		return sites[i].matchBegin < sites[j].matchBegin
+	})
+
+	truncated := len(sites) > maxFindErrorResults
+	if truncated {
+		sites = sites[:maxFindErrorResults]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Error sites for %q - %d result(s):\n", message, len(sites))
+	for _, s := range sites {
+		fmt.Fprintf(&sb, "%s:%d\t%s\t%s\n", s.path, s.line, s.function, strconv.Quote(s.format))
+	}
+	if truncated {
+		sb.WriteString("\n" + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d sites. Pass path or a more specific message.]", maxFindErrorResults)))
+	}
+
+	return withTokenEstimate(sb.String()), nil
+}
+
+// findErrorSitesInFile parses a Go file and returns error constructors whose
+// format string matches message.
+func findErrorSitesInFile(path, relPath, message string) []errorSite {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxFindErrorFileBytes {
+		return nil
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	if !bytes.Contains(src, []byte("Err")) && !bytes.Contains(src, []byte("New")) && !bytes.Contains(src, []byte("Wrap")) {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	imports := fileImports(file)
+	var sites []errorSite
+	collect := func(root ast.Node, function string) {
+		ast.Inspect(root, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !isErrorConstructor(call, imports) {
+				return true
+			}
+			for _, arg := range call.Args {
+				lit, ok := arg.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				format, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					continue
+				}
+				begin, staticLen, ok := matchErrorFormat(format, message)
+				if !ok {
+					continue
+				}
+				sites = append(sites, errorSite{
+					path:       relPath,
+					line:       fset.Position(lit.Pos()).Line,
+					function:   function,
+					format:     format,
+					staticLen:  staticLen,
+					matchBegin: begin,
+				})
+				break
+			}
+			return true
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			collect(d, funcDisplayName(d))
+		case *ast.GenDecl:
+			collect(d, "(package level)")
+		}
+	}
+
+	return sites
+}
+
+// matchErrorFormat reports whether the rendered message could have been
+// produced by format. Verbs match any text, so "user %s not found" matches
+// "get user: user 42 not found: sql: no rows". It returns where the match
+// starts in message and how many literal characters matched.
+func matchErrorFormat(format, message string) (int, int, bool) {
+	segments := formatVerbPattern.Split(format, -1)
+
+	var pattern strings.Builder
+	staticLen := 0
+	for i, seg := range segments {
+		if i > 0 {
+			pattern.WriteString(".*?")
+		}
+		pattern.WriteString(regexp.QuoteMeta(seg))
+		staticLen += len(strings.TrimSpace(seg))
+	}
+	if staticLen < minErrorStaticLength {
+		return 0, 0, false
+	}
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return 0, 0, false
+	}
+	if loc := re.FindStringIndex(message); loc != nil {
+		return loc[0], staticLen, true
+	}
+
+	// The issue may quote only part of the message; accept a static segment
+	// that contains the whole quote.
+	if len(message) >= minErrorStaticLength {
+		for _, seg := range segments {
+			if strings.Contains(seg, message) {
+				return 0, len(message), true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// fileImports maps the names file refers to its imports by to their paths.
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+// isErrorConstructor reports whether call builds an error from a message:
+// a known constructor of an error package (errors.New, fmt.Errorf,
+// errors.Wrap from pkg/errors), or a package-level function named like an
+// error constructor. Method calls (t.Errorf, logger.Errorf) never match.
+func isErrorConstructor(call *ast.CallExpr, imports map[string]string) bool {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return ownErrorConstructorPattern.MatchString(fn.Name)
+	case *ast.SelectorExpr:
+		pkg, ok := fn.X.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		path, ok := imports[pkg.Name]
+		if !ok {
+			return false
+		}
+		if funcs, ok := errorPackageConstructors[path]; ok {
+			return funcs[fn.Sel.Name]
+		}
+		return ownErrorConstructorPattern.MatchString(fn.Sel.Name)
+	}
+	return false
+}
+
+// funcDisplayName renders a FuncDecl as Name or Recv.Name.
+func funcDisplayName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	for {
+		switch r := recv.(type) {
+		case *ast.StarExpr:
+			recv = r.X
+			continue
+		case *ast.IndexExpr:
+			recv = r.X
+			continue
+		case *ast.IndexListExpr:
+			recv = r.X
+			continue
+		case *ast.Ident:
+			return r.Name + "." + fn.Name.Name
+		}
+		return fn.Name.Name
+	}
+}
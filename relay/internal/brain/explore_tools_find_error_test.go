@@ -0,0 +1,182 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/internal/brain"
+)
+
+var _ = Describe("ExploreTools find_error", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		tools   *brain.ExploreTools
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-find-error-test-*")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(tempDir, "store"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tempDir, "store", "user.go"), []byte(`package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrClosed = errors.New("store is closed")
+
+type UserStore struct{}
+
+func (s *UserStore) Find(id string) error {
+	if id == "" {
+		return ErrClosed
+	}
+	return fmt.Errorf("user %s not found in workspace %d", id, 7)
+}
+`), 0o644)).To(Succeed())
+
+		Expect(os.WriteFile(filepath.Join(tempDir, "store", "load.go"), []byte(`package store
+
+import "fmt"
+
+func Load(path string) error {
+	if err := open(path); err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	return nil
+}
+
+func open(string) error { return nil }
+`), 0o644)).To(Succeed())
+
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	It("finds a format string site from a rendered message with verbs", func() {
+		args, _ := json.Marshal(map[string]any{
+			"message": "user 42 not found in workspace 7",
+		})
+
+		result, err := tools.Execute(ctx, "find_error", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("store/user.go:16"))
+		Expect(result).To(ContainSubstring("UserStore.Find"))
+		Expect(result).To(ContainSubstring(`"user %s not found in workspace %d"`))
+	})
+
+	It("finds each layer of a wrapped error chain", func() {
+		args, _ := json.Marshal(map[string]any{
+			"message": "load config: user abc not found in workspace 3",
+		})
+
+		result, err := tools.Execute(ctx, "find_error", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("store/user.go:16"))
+		Expect(result).To(ContainSubstring("store/load.go:7\tLoad"))
+	})
+
+	It("attributes package-level sentinel errors", func() {
+		args, _ := json.Marshal(map[string]any{
+			"message": "store is closed",
+		})
+
+		result, err := tools.Execute(ctx, "find_error", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("store/user.go:8\t(package level)"))
+	})
+
+	Describe("constructor matching", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(filepath.Join(tempDir, "store", "cache.go"), []byte(`package store
+
+import (
+	"log"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+type NotFoundError struct{ msg string }
+
+func (e *NotFoundError) Error() string { return e.msg }
+
+func NewNotFoundError(format string, args ...any) error { return &NotFoundError{format} }
+
+func NewCache(name string) *NotFoundError { return nil }
+
+func Warm(logger *log.Logger) error {
+	NewCache("widget cache is cold")
+	logger.Printf("widget cache is cold")
+	if err := open(""); err != nil {
+		return pkgerrors.Wrap(err, "warm widget cache")
+	}
+	return NewNotFoundError("widget %s has expired", "a")
+}
+`), 0o644)).To(Succeed())
+		})
+
+		It("ignores constructors and calls that don't build errors", func() {
+			args, _ := json.Marshal(map[string]any{"message": "widget cache is cold"})
+
+			result, err := tools.Execute(ctx, "find_error", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("No error construction site matches"))
+		})
+
+		It("finds the repo's own error constructors", func() {
+			args, _ := json.Marshal(map[string]any{"message": "widget abc has expired"})
+
+			result, err := tools.Execute(ctx, "find_error", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("store/cache.go:23\tWarm"))
+		})
+
+		It("resolves renamed error package imports", func() {
+			args, _ := json.Marshal(map[string]any{"message": "warm widget cache: EOF"})
+
+			result, err := tools.Execute(ctx, "find_error", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("store/cache.go:21\tWarm"))
+		})
+	})
+
+	It("reports when nothing matches", func() {
+		args, _ := json.Marshal(map[string]any{
+			"message": "connection reset by peer",
+		})
+
+		result, err := tools.Execute(ctx, "find_error", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("No error construction site matches"))
+	})
+
+	It("requires a message", func() {
+		result, err := tools.Execute(ctx, "find_error", `{}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("message is required"))
+	})
+})
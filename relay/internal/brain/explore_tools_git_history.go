@@ -0,0 +1,139 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"basegraph.co/relay/common/llm"
+)
+
+const (
+	defaultGitHistoryLimit = 20
+	maxGitHistoryLimit     = 100
+)
+
+// GitHistoryParams for listing recent commits.
+type GitHistoryParams struct {
+	File  string `json:"file,omitempty" jsonschema:"description=Only commits touching this file or directory, relative to repo root. Renames are followed for a single file."`
+	Since string `json:"since,omitempty" jsonschema:"description=Only commits from this far back: a number with h (hours), d (days) or w (weeks), e.g. '30d'"`
+	Limit int    `json:"limit,omitempty" jsonschema:"description=Max commits to return (1-100, default 20)"`
+}
+
+// sinceDurationPattern matches git_history's since argument, e.g. "30d".
+var sinceDurationPattern = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+var sinceUnits = map[string]time.Duration{
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+func gitHistoryToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "git_history",
+		Description: `List recent commits, newest first, one line each: hash, date, author, subject.
+
+Use instead of bash git log: the output stays small and never gets cut off on old history.
+
+Examples:
+  git_history(since="30d")                                  # What changed lately
+  git_history(file="internal/brain/planner.go", limit=10)   # Who touched this file and why
+  git_history(file="internal/queue/", since="2w")`,
+		Parameters: llm.GenerateSchemaFrom(GitHistoryParams{}),
+	}
+}
+
+// executeGitHistory runs git log with a compact format.
+func (t *ExploreTools) executeGitHistory(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[GitHistoryParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse git_history params: %w", err)
+	}
+
+	if t.gitDisabled {
+		return "Error: git is disabled in this deployment - use grep, read and codegraph instead", nil
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultGitHistoryLimit
+	}
+	limit = min(limit, maxGitHistoryLimit)
+
+	// One extra commit tells whether the list was cut at limit.
+	args := []string{"-C", t.repoRoot, "log", "--no-merges", "--date=short",
+		"--pretty=format:%h%x09%ad%x09%an%x09%s", "-n", strconv.Itoa(limit + 1)}
+
+	since := strings.TrimSpace(params.Since)
+	if since != "" {
+		m := sinceDurationPattern.FindStringSubmatch(since)
+		if m == nil {
+			return fmt.Sprintf("Error: invalid since %q. Use a number with h, d or w, e.g. \"30d\".", params.Since), nil
+		}
+		n, _ := strconv.Atoi(m[1])
+		cutoff := time.Now().Add(-time.Duration(n) * sinceUnits[m[2]])
+		args = append(args, fmt.Sprintf("--since=@%d", cutoff.Unix()))
+	}
+
+	relPath := ""
+	if params.File != "" {
+		fullPath := filepath.Join(t.repoRoot, params.File)
+		if !pathWithinRoot(t.repoRoot, fullPath) {
+			return "Error: path outside repository", nil
+		}
+		relPath = filepath.ToSlash(filepath.Clean(params.File))
+		// --follow works for a single file only.
+		if info, err := os.Stat(fullPath); err == nil && info.Mode().IsRegular() {
+			args = append(args, "--follow")
+		}
+		args = append(args, "--", relPath)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Bash)
+	defer cancel()
+
+	out, err := exec.CommandContext(timeoutCtx, "git", args...).Output()
+	if err != nil {
+		if timeoutCtx.Err() == context.DeadlineExceeded {
+			return "git log timed out. Pass since or file to narrow it.", nil
+		}
+		return fmt.Sprintf("Error: git log failed: %s", err), nil
+	}
+
+	scope := ""
+	if relPath != "" {
+		scope += " touching " + relPath
+	}
+	if since != "" {
+		scope += " in the last " + since
+	}
+
+	output := strings.TrimSpace(string(out))
+	if output == "" {
+		return fmt.Sprintf("No commits%s.", scope), nil
+	}
+	commits := strings.Split(output, "\n")
+	truncated := len(commits) > limit
+
+	var sb strings.Builder
+	if truncated {
+		fmt.Fprintf(&sb, "Latest %d commits%s (newest first):\n", limit, scope)
+		commits = commits[:limit]
+	} else {
+		fmt.Fprintf(&sb, "%d commit(s)%s (newest first):\n", len(commits), scope)
+	}
+	for _, c := range commits {
+		sb.WriteString(c + "\n")
+	}
+	if truncated {
+		sb.WriteString(t.truncationFooter(ctx, "[More commits exist. Raise limit or pass since to narrow.]") + "\n")
+	}
+	return withTokenEstimate(sb.String()), nil
+}
@@ -0,0 +1,115 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/internal/brain"
+)
+
+var _ = Describe("ExploreTools git_history", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		tools   *brain.ExploreTools
+	)
+
+	// commit writes name and commits it, dated daysAgo days back.
+	commit := func(name, content, author, subject string, daysAgo int) {
+		path := filepath.Join(tempDir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o644)).To(Succeed())
+
+		date := time.Now().AddDate(0, 0, -daysAgo).Format(time.RFC3339)
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", subject}} {
+			cmd := exec.Command("git", append([]string{"-C", tempDir}, args...)...)
+			cmd.Env = append(os.Environ(),
+				"GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL=dev@example.com", "GIT_AUTHOR_DATE="+date,
+				"GIT_COMMITTER_NAME="+author, "GIT_COMMITTER_EMAIL=dev@example.com", "GIT_COMMITTER_DATE="+date)
+			out, err := cmd.CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+		}
+	}
+
+	gitHistory := func(params map[string]any) string {
+		args, _ := json.Marshal(params)
+		result, err := tools.Execute(ctx, "git_history", string(args))
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-git-history-test-*")
+		Expect(err).NotTo(HaveOccurred())
+
+		out, err := exec.Command("git", "-C", tempDir, "init", "-q", "-b", "main").CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+
+		commit("store/user.go", "package store\n", "alice", "Add user store", 90)
+		commit("docs/setup.md", "# Setup\n", "bob", "Document setup", 40)
+		commit("store/user.go", "package store\n\nfunc Find() {}\n", "carol", "Add Find", 10)
+		commit("store/user.go", "package store\n\nfunc Find() string { return \"\" }\n", "alice", "Return the user name", 2)
+
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	It("lists commits newest first with date, author and subject", func() {
+		result := gitHistory(map[string]any{})
+
+		Expect(result).To(ContainSubstring("4 commit(s) (newest first):"))
+		Expect(result).To(MatchRegexp(`[0-9a-f]{7,}\t` + time.Now().AddDate(0, 0, -2).Format("2006-01-02") + `\talice\tReturn the user name\n`))
+		Expect(result).To(MatchRegexp(`(?s)Return the user name.*Add Find.*Document setup.*Add user store`))
+	})
+
+	It("keeps only commits within since", func() {
+		result := gitHistory(map[string]any{"since": "30d"})
+
+		Expect(result).To(ContainSubstring("2 commit(s) in the last 30d (newest first):"))
+		Expect(result).To(ContainSubstring("Add Find"))
+		Expect(result).NotTo(ContainSubstring("Document setup"))
+		Expect(result).NotTo(ContainSubstring("Add user store"))
+
+		Expect(gitHistory(map[string]any{"since": "1d"})).To(Equal("No commits in the last 1d."))
+	})
+
+	It("filters by file and caps at limit", func() {
+		result := gitHistory(map[string]any{"file": "store/user.go", "limit": 2})
+
+		Expect(result).To(ContainSubstring("Latest 2 commits touching store/user.go (newest first):"))
+		Expect(result).To(ContainSubstring("Return the user name"))
+		Expect(result).To(ContainSubstring("Add Find"))
+		Expect(result).NotTo(ContainSubstring("Add user store"))
+		Expect(result).NotTo(ContainSubstring("Document setup"))
+		Expect(result).To(ContainSubstring("[truncated] [More commits exist."))
+	})
+
+	It("rejects bad input", func() {
+		Expect(gitHistory(map[string]any{"since": "1 month"})).To(ContainSubstring(`invalid since "1 month"`))
+		Expect(gitHistory(map[string]any{"file": "../outside"})).To(ContainSubstring("path outside repository"))
+	})
+
+	It("is unavailable when git is disabled", func() {
+		tools.WithGitDisabled(true)
+
+		Expect(gitHistory(map[string]any{})).To(ContainSubstring("git is disabled"))
+		for _, def := range tools.Definitions() {
+			Expect(def.Name).NotTo(Equal("git_history"))
+		}
+	})
+})
@@ -0,0 +1,278 @@
+package brain
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLimitGrepMatchesCountsMatchesNotLines(t *testing.T) {
+	// rg -n --null -C1 output: three matches, each with a line of context.
+	lines := []string{
+		"/repo/a.go\x001-package a",
+		"/repo/a.go\x002:func Plan() {}",
+		"/repo/a.go\x003-",
+		"--",
+		"/repo/b.go\x009-// Plan again",
+		"/repo/b.go\x0010:func Plan() {}",
+		"/repo/b.go\x0011-}",
+		"--",
+		"/repo/c-1-x.go\x004-",
+		"/repo/c-1-x.go\x005:Plan()",
+		"",
+	}
+
+	kept, truncated := limitGrepMatches(lines, 3)
+	if truncated {
+		t.Errorf("truncated with 3 matches and a cap of 3 (%d lines)", len(lines))
+	}
+	if !reflect.DeepEqual(kept, lines) {
+		t.Errorf("kept = %q, want all lines", kept)
+	}
+
+	kept, truncated = limitGrepMatches(lines, 2)
+	if !truncated {
+		t.Error("not truncated with 3 matches and a cap of 2")
+	}
+	want := lines[:7]
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %q, want %q", kept, want)
+	}
+}
+
+func TestLimitGrepMatchesKeepsTrailingContextInGroup(t *testing.T) {
+	// Two matches in one group: the second is dropped, the context between
+	// them stays with the first.
+	lines := []string{
+		"/repo/a.go\x002:Plan()",
+		"/repo/a.go\x003-ctx",
+		"/repo/a.go\x004:Plan()",
+	}
+
+	kept, truncated := limitGrepMatches(lines, 1)
+	if !truncated {
+		t.Error("not truncated")
+	}
+	if want := lines[:2]; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %q, want %q", kept, want)
+	}
+}
+
+func TestSkipGrepMatchesPagesWithoutDuplicates(t *testing.T) {
+	// rg -n --null -C1 output: four matches, the last two in one group.
+	lines := []string{
+		"/repo/a.go\x002:Plan()",
+		"/repo/a.go\x003-",
+		"--",
+		"/repo/b.go\x009-// Plan again",
+		"/repo/b.go\x0010:Plan()",
+		"/repo/b.go\x0011-ctx",
+		"/repo/b.go\x0012:Plan()",
+		"",
+	}
+	if n := countGrepMatches(lines); n != 3 {
+		t.Fatalf("countGrepMatches() = %d, want 3", n)
+	}
+
+	first, truncated := limitGrepMatches(skipGrepMatches(lines, 0), 2)
+	if !truncated {
+		t.Error("first page not truncated")
+	}
+	second, truncated := limitGrepMatches(skipGrepMatches(lines, 2), 2)
+	if truncated {
+		t.Error("second page truncated")
+	}
+	if want := lines[5:]; !reflect.DeepEqual(second, want) { // Leading context comes along
+		t.Errorf("second page = %q, want %q", second, want)
+	}
+
+	seen := map[string]bool{}
+	for _, line := range append(first, second...) {
+		if !isGrepMatchLine(line) {
+			continue
+		}
+		if seen[line] {
+			t.Errorf("match %q on both pages", line)
+		}
+		seen[line] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("pages cover %d matches, want 3", len(seen))
+	}
+
+	if got := skipGrepMatches(lines, 3); got != nil {
+		t.Errorf("skipGrepMatches(past end) = %q, want nil", got)
+	}
+}
+
+func TestIsGrepMatchLine(t *testing.T) {
+	tests := map[string]bool{
+		"/repo/a.go\x0012:func main() {}":  true,
+		"/repo/a.go\x0012-func main() {}":  false,
+		"/repo/a-1-b.go\x003:x":            true,
+		"/repo/a-1-b.go\x003-x":            false,
+		"/repo/a:1:b.go\x003-x":            false,
+		"--":                               false,
+		"/repo/a.go\x0012-  return a:1:":   false,
+		"/repo/a.go\x0012:map[string]int{": true,
+	}
+	for line, want := range tests {
+		if got := isGrepMatchLine(line); got != want {
+			t.Errorf("isGrepMatchLine(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestGrepDisplayLine(t *testing.T) {
+	if got := grepDisplayLine("/repo/a.go\x0012:func main() {}"); got != "/repo/a.go:12:func main() {}" {
+		t.Errorf("match line = %q", got)
+	}
+	if got := grepDisplayLine("/repo/a.go\x0013-}"); got != "/repo/a.go-13-}" {
+		t.Errorf("context line = %q", got)
+	}
+	if got := grepDisplayLine("--"); got != "--" {
+		t.Errorf("separator = %q", got)
+	}
+}
+
+func TestSortGrepOutputOrdersByPath(t *testing.T) {
+	// grep -r -C1 output in directory order, not path order.
+	output := "/repo/b.go\x001-// b\n/repo/b.go\x002:Plan()\n--\n/repo/b.go\x009:Plan()\n--\n/repo/a.go\x004:Plan()\n"
+
+	got := string(sortGrepOutput([]byte(output), true))
+	want := "/repo/a.go\x004:Plan()\n--\n/repo/b.go\x001-// b\n/repo/b.go\x002:Plan()\n--\n/repo/b.go\x009:Plan()\n"
+	if got != want {
+		t.Errorf("sortGrepOutput() = %q, want %q", got, want)
+	}
+
+	files := "/repo/c.go\n/repo/a.go\n"
+	if got := string(sortGrepOutput([]byte(files), false)); got != "/repo/a.go\n/repo/c.go\n" {
+		t.Errorf("sortGrepOutput(files) = %q", got)
+	}
+}
+
+func TestExecuteGrepFallsBackToGrep(t *testing.T) {
+	grepPath, err := exec.LookPath("grep")
+	if err != nil {
+		t.Skip("grep not installed")
+	}
+	// A PATH with only grep on it, so rg and fd are missing.
+	bin := t.TempDir()
+	if err := os.Symlink(grepPath, filepath.Join(bin, "grep")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	repo := t.TempDir()
+	files := map[string]string{
+		"b.go":          "package b\n\nfunc Plan() {}\n",
+		"a.go":          "package a\n\nfunc Plan() {}\n",
+		".hidden/c.go":  "func Plan() {}\n",
+		"vendor/x/d.go": "func Plan() {}\n",
+		"notes.md":      "Plan\n",
+	}
+	for name, body := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tools := NewExploreTools(repo, nil, ExploreToolsConfig{})
+	if tools.hasRipgrep || tools.hasFd || !tools.hasGrep {
+		t.Fatalf("detected rg=%v fd=%v grep=%v, want only grep", tools.hasRipgrep, tools.hasFd, tools.hasGrep)
+	}
+	for _, def := range tools.Definitions() {
+		if (def.Name == "grep" || def.Name == "glob") && !strings.Contains(def.Description, "Degraded mode") {
+			t.Errorf("%s description does not mention degraded mode", def.Name)
+		}
+	}
+
+	out, err := tools.Execute(context.Background(), "grep", `{"pattern": "func Pl[a-z]+\\(", "glob": "*.go"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a.go:3", "b.go:3"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "a.go:3") > strings.Index(out, "b.go:3") {
+		t.Errorf("matches not sorted by path:\n%s", out)
+	}
+	for _, skipped := range []string{".hidden", "vendor", "notes.md"} {
+		if strings.Contains(out, skipped) {
+			t.Errorf("output includes %s:\n%s", skipped, out)
+		}
+	}
+}
+
+func TestRipgrepArgsAlwaysPrintPaths(t *testing.T) {
+	// Without -H, rg omits the path (and the NUL after it) when searching a
+	// single file, and no line counts as a match.
+	args := ripgrepArgs(GrepParams{Pattern: "Plan"}, "/repo/a.go")
+	if !slices.Contains(args, "-H") {
+		t.Errorf("ripgrepArgs() = %q, want -H", args)
+	}
+}
+
+func TestExecuteGrepPagesSingleFile(t *testing.T) {
+	grepPath, err := exec.LookPath("grep")
+	if err != nil {
+		t.Skip("grep not installed")
+	}
+	modes := map[string]func(t *testing.T){
+		"ripgrep": func(t *testing.T) {
+			if _, err := exec.LookPath("rg"); err != nil {
+				t.Skip("rg not installed")
+			}
+		},
+		"grep fallback": func(t *testing.T) {
+			bin := t.TempDir()
+			if err := os.Symlink(grepPath, filepath.Join(bin, "grep")); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", bin)
+		},
+	}
+
+	for name, setup := range modes {
+		t.Run(name, func(t *testing.T) {
+			setup(t)
+
+			repo := t.TempDir()
+			if err := os.WriteFile(filepath.Join(repo, "big.txt"), []byte(strings.Repeat("needle\n", maxGrepMatches+10)), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			tools := NewExploreTools(repo, nil, ExploreToolsConfig{})
+			ctx := context.Background()
+
+			page1, err := tools.Execute(ctx, "grep", `{"pattern": "needle", "path": "big.txt"}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := strings.Count(page1, "big.txt:"); n != maxGrepMatches {
+				t.Errorf("first page has %d matches, want %d:\n%s", n, maxGrepMatches, page1)
+			}
+			if !strings.Contains(page1, "10 more matches; call grep again with offset=50") {
+				t.Errorf("first page is not truncated:\n%s", page1)
+			}
+
+			page2, err := tools.Execute(ctx, "grep", `{"pattern": "needle", "path": "big.txt", "offset": 50}`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := strings.Count(page2, "big.txt:"); n != 10 {
+				t.Errorf("second page has %d matches, want 10:\n%s", n, page2)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"basegraph.co/relay/common/arangodb"
+)
+
+const (
+	defaultHierarchyDepth = 2
+	maxHierarchyDepth     = 4
+	maxHierarchyNodes     = 60
+)
+
+// hierarchyRelation is one edge direction in the type hierarchy tree. Each
+// relation's children are expanded with the relations listed in next, so the
+// tree keeps walking in one direction (down through embedders, up through
+// embedded types) instead of bouncing back.
+type hierarchyRelation struct {
+	label string
+	fetch func(t *ExploreTools, ctx context.Context, qname string) ([]arangodb.GraphNode, error)
+	next  []string
+}
+
+var hierarchyRelations = map[string]hierarchyRelation{
+	"embedded by": {
+		label: "embedded by",
+		fetch: func(t *ExploreTools, ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
+			return t.arango.GetInheritors(ctx, qname)
+		},
+		next: []string{"embedded by", "implemented by"},
+	},
+	"implemented by": {
+		label: "implemented by",
+		fetch: func(t *ExploreTools, ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
+			return t.arango.GetImplementations(ctx, qname)
+		},
+	},
+	"embeds": {
+		label: "embeds",
+		fetch: func(t *ExploreTools, ctx context.Context, qname string) ([]arangodb.GraphNode, error) {
+			return t.arango.GetEmbedded(ctx, qname)
+		},
+		next: []string{"embeds"},
+	},
+}
+
+// hierarchyRootRelations are expanded for the requested type itself.
+var hierarchyRootRelations = []string{"embeds", "embedded by", "implemented by"}
+
+// hierarchyWriter renders the tree with a node budget shared across branches.
+type hierarchyWriter struct {
+	t         *ExploreTools
+	sb        strings.Builder
+	nodes     int
+	truncated bool
+}
+
+// executeCodegraphHierarchy renders the type hierarchy of a struct or
+// interface: the types it embeds, the types embedding it (sub-interfaces,
+// wrapper structs) and its implementers, as an indented tree.
+func (t *ExploreTools) executeCodegraphHierarchy(ctx context.Context, params CodegraphParams) (string, error) {
+	root := arangodb.ResolvedSymbol{QName: strings.TrimSpace(params.QName)}
+	if root.QName == "" {
+		if strings.TrimSpace(params.Name) == "" {
+			return "Error: hierarchy requires name or qname.", nil
+		}
+		kinds := []string{"interface", "struct", "class"}
+		if params.Kind != "" {
+			kinds = []string{params.Kind}
+		}
+		symbol, errMsg := t.resolveSymbolForKinds(ctx, params.Name, params.File, kinds)
+		if errMsg != "" {
+			return errMsg, nil
+		}
+		root = symbol
+	}
+
+	depth := params.Depth
+	if depth < 1 {
+		depth = defaultHierarchyDepth
+	}
+	if depth > maxHierarchyDepth {
+		depth = maxHierarchyDepth
+	}
+
+	w := &hierarchyWriter{t: t}
+	fmt.Fprintf(&w.sb, "Hierarchy of %s (depth %d):\n", root.QName, depth)
+	if root.Kind != "" {
+		w.sb.WriteString(t.formatCodegraphLine(root.Filepath, root.Pos, normalizeCodegraphKind(root.Kind), root.QName, ""))
+		w.sb.WriteString("\n")
+	}
+
+	visited := map[string]bool{root.QName: true}
+	if err := w.expand(ctx, root.QName, hierarchyRootRelations, 1, depth, "  ", visited); err != nil {
+		slog.ErrorContext(ctx, "codegraph hierarchy failed", "qname", root.QName, "error", err)
+		return formatCodegraphError("querying hierarchy", err), nil
+	}
+	if w.nodes == 0 {
+		return fmt.Sprintf("No embedded types, embedders or implementations found for %s.", root.QName), nil
+	}
+	if w.truncated {
+		w.sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing %d nodes. Lower depth or query a subtree by qname.]", maxHierarchyNodes)))
+		w.sb.WriteString("\n")
+	}
+
+	return strings.TrimSpace(w.sb.String()), nil
+}
+
+// expand writes the children of qname (at tree depth level) for each
+// relation, recursing until maxDepth. visited holds the qnames on the current
+// path to break cycles.
+func (w *hierarchyWriter) expand(ctx context.Context, qname string, relations []string, level, maxDepth int, indent string, visited map[string]bool) error {
+	for _, name := range relations {
+		if w.truncated {
+			return nil
+		}
+		rel := hierarchyRelations[name]
+		nodes, err := rel.fetch(w.t, ctx, qname)
+		if err != nil {
+			return err
+		}
+
+		var children []arangodb.GraphNode
+		for _, n := range nodes {
+			if n.QName != "" && !visited[n.QName] {
+				children = append(children, n)
+			}
+		}
+		if len(children) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&w.sb, "%s%s:\n", indent, rel.label)
+		for _, n := range children {
+			if w.nodes == maxHierarchyNodes {
+				w.truncated = true
+				return nil
+			}
+			w.nodes++
+			w.sb.WriteString(indent + "  ")
+			w.sb.WriteString(w.t.formatCodegraphLine(n.Filepath, n.Pos, normalizeCodegraphKind(n.Kind), n.QName, ""))
+			w.sb.WriteString("\n")
+
+			if level < maxDepth && len(rel.next) > 0 {
+				visited[n.QName] = true
+				err := w.expand(ctx, n.QName, rel.next, level+1, maxDepth, indent+"    ", visited)
+				delete(visited, n.QName)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
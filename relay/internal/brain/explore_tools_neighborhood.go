@@ -0,0 +1,124 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"basegraph.co/relay/common/arangodb"
+)
+
+const maxNeighborhoodGroup = 10 // Nodes listed per neighborhood group; the rest are counted
+
+// neighborhoodGroup is one kind of direct neighbor. fetch returns up to limit
+// nodes and the total count.
+type neighborhoodGroup struct {
+	label string
+	more  string // Operation to page through the full group
+	fetch func(t *ExploreTools, ctx context.Context, qname string, limit int) ([]arangodb.GraphNode, int, error)
+}
+
+var neighborhoodGroups = []neighborhoodGroup{
+	{
+		label: "callers",
+		more:  `codegraph(operation="callers")`,
+		fetch: func(t *ExploreTools, ctx context.Context, qname string, limit int) ([]arangodb.GraphNode, int, error) {
+			return t.arango.GetCallers(ctx, qname, 1, arangodb.Page{Limit: limit})
+		},
+	},
+	{
+		label: "callees",
+		more:  `codegraph(operation="callees")`,
+		fetch: func(t *ExploreTools, ctx context.Context, qname string, limit int) ([]arangodb.GraphNode, int, error) {
+			return t.arango.GetCallees(ctx, qname, 1, arangodb.Page{Limit: limit})
+		},
+	},
+	{
+		label: "implements",
+		more:  `codegraph(operation="hierarchy")`,
+		fetch: func(t *ExploreTools, ctx context.Context, qname string, limit int) ([]arangodb.GraphNode, int, error) {
+			nodes, _, err := t.arango.TraverseFrom(ctx, []string{qname}, arangodb.TraversalOptions{
+				EdgeTypes: []string{"implements"},
+				Direction: arangodb.DirectionOutbound,
+				MaxDepth:  1,
+			})
+			// TraverseFrom returns nodes in no particular order.
+			sort.Slice(nodes, func(i, j int) bool { return nodes[i].QName < nodes[j].QName })
+			return nodes, len(nodes), err
+		},
+	},
+	{
+		label: "implemented_by",
+		more:  `codegraph(operation="implementations")`,
+		fetch: func(t *ExploreTools, ctx context.Context, qname string, limit int) ([]arangodb.GraphNode, int, error) {
+			nodes, err := t.arango.GetImplementations(ctx, qname)
+			return nodes, len(nodes), err
+		},
+	},
+	{
+		label: "used_by",
+		more:  `codegraph(operation="usages")`,
+		fetch: func(t *ExploreTools, ctx context.Context, qname string, limit int) ([]arangodb.GraphNode, int, error) {
+			nodes, err := t.arango.GetUsages(ctx, qname, arangodb.UsageAny)
+			return nodes, len(nodes), err
+		},
+	},
+}
+
+// executeCodegraphNeighborhood lists everything directly connected to a
+// symbol: callers, callees, the interfaces it implements, its implementers
+// and its usages as param, return value or field, each group capped.
+func (t *ExploreTools) executeCodegraphNeighborhood(ctx context.Context, params CodegraphParams) (string, error) {
+	qname, errMsg := t.resolveQNameForOperation(ctx, "neighborhood", params)
+	if errMsg != "" {
+		return errMsg, nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Neighborhood of %s (depth 1):\n", qname)
+	for _, g := range neighborhoodGroups {
+		nodes, total, err := g.fetch(t, ctx, qname, maxNeighborhoodGroup)
+		if err != nil {
+			slog.ErrorContext(ctx, "codegraph neighborhood failed", "qname", qname, "group", g.label, "error", err)
+			return formatCodegraphError("querying "+g.label, err), nil
+		}
+
+		var lines []string
+		for _, n := range nodes {
+			if n.QName == "" {
+				continue
+			}
+			lines = append(lines, t.formatNeighborLine(n))
+		}
+		total = max(total, len(lines))
+
+		fmt.Fprintf(&sb, "\n%s (%d):\n", g.label, total)
+		if total == 0 {
+			sb.WriteString("  (none)\n")
+			continue
+		}
+		for i, line := range lines {
+			if i == maxNeighborhoodGroup {
+				break
+			}
+			sb.WriteString("  " + line + "\n")
+		}
+		if total > maxNeighborhoodGroup {
+			sb.WriteString("  " + t.truncationFooter(ctx, fmt.Sprintf("[Showing %d of %d. Use %s for the full list.]", maxNeighborhoodGroup, total, g.more)) + "\n")
+		}
+	}
+
+	return withTokenEstimate(strings.TrimSpace(sb.String())), nil
+}
+
+// formatNeighborLine is formatCodegraphLine for nodes that may come without a
+// location (traversals only return qname and kind).
+func (t *ExploreTools) formatNeighborLine(n arangodb.GraphNode) string {
+	kind := nodeKindLabel(n)
+	if n.Filepath == "" {
+		return fmt.Sprintf("%s\t%s", kind, n.QName)
+	}
+	return t.formatCodegraphLine(n.Filepath, n.Pos, kind, n.QName, "")
+}
@@ -0,0 +1,159 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"basegraph.co/relay/common/llm"
+)
+
+const (
+	maxNotesPerSession = 100
+	maxNoteLen         = 500 // Chars kept from each observation
+)
+
+// Note is one finding the explore agent stashed with write_note.
+type Note struct {
+	File        string `json:"file,omitempty"`
+	Symbol      string `json:"symbol,omitempty"`
+	Observation string `json:"observation"`
+}
+
+// WriteNoteParams for recording a finding on the scratchpad.
+type WriteNoteParams struct {
+	File        string `json:"file,omitempty" jsonschema:"description=File the finding is about (e.g. 'internal/store/issue.go:88')"`
+	Symbol      string `json:"symbol,omitempty" jsonschema:"description=Function or type the finding is about (e.g. 'IssueStore.Upsert')"`
+	Observation string `json:"observation" jsonschema:"required,description=What you learned, in one or two sentences"`
+}
+
+// ReadNotesParams for recalling scratchpad notes.
+type ReadNotesParams struct {
+	Filter string `json:"filter,omitempty" jsonschema:"description=Only return notes whose file, symbol or observation contains this text (case-insensitive)"`
+}
+
+// noteSessionKey carries the explore session whose notes a tool call reads
+// and writes. Calls without one share the "" session.
+type noteSessionKey struct{}
+
+func withNoteSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, noteSessionKey{}, session)
+}
+
+func noteSession(ctx context.Context) string {
+	session, _ := ctx.Value(noteSessionKey{}).(string)
+	return session
+}
+
+func writeNoteToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "write_note",
+		Description: `Save a finding to your scratchpad so you don't have to re-derive it later. Notes survive
+the whole exploration and are handed back to you when you write the final report.
+
+Record facts you verified (where something is defined, what a function does, how two pieces
+connect), not guesses.
+
+Example:
+  write_note(file="internal/store/issue.go:88", symbol="IssueStore.Upsert", observation="Upserts on (integration_id, external_id); no soft delete.")`,
+		Parameters: llm.GenerateSchemaFrom(WriteNoteParams{}),
+	}
+}
+
+func readNotesToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name:        "read_notes",
+		Description: `List the notes saved with write_note this exploration, optionally filtered by text.`,
+		Parameters:  llm.GenerateSchemaFrom(ReadNotesParams{}),
+	}
+}
+
+// executeWriteNote appends a note to the current session's scratchpad.
+func (t *ExploreTools) executeWriteNote(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[WriteNoteParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse write_note params: %w", err)
+	}
+
+	note := Note{
+		File:        strings.TrimSpace(params.File),
+		Symbol:      strings.TrimSpace(params.Symbol),
+		Observation: strings.TrimSpace(params.Observation),
+	}
+	if note.Observation == "" {
+		return "Error: observation is required", nil
+	}
+	if len(note.Observation) > maxNoteLen {
+		note.Observation = note.Observation[:maxNoteLen] + "..."
+	}
+
+	session := noteSession(ctx)
+	t.notesMu.Lock()
+	defer t.notesMu.Unlock()
+	if len(t.notes[session]) >= maxNotesPerSession {
+		return fmt.Sprintf("Error: scratchpad is full (%d notes). Write your report or rely on existing notes.", maxNotesPerSession), nil
+	}
+	if t.notes == nil {
+		t.notes = make(map[string][]Note)
+	}
+	t.notes[session] = append(t.notes[session], note)
+
+	return fmt.Sprintf("Noted (%d total).", len(t.notes[session])), nil
+}
+
+// executeReadNotes lists the current session's notes.
+func (t *ExploreTools) executeReadNotes(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[ReadNotesParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse read_notes params: %w", err)
+	}
+
+	notes := t.Notes(noteSession(ctx))
+	if filter := strings.ToLower(strings.TrimSpace(params.Filter)); filter != "" {
+		var kept []Note
+		for _, n := range notes {
+			if strings.Contains(strings.ToLower(n.File+" "+n.Symbol+" "+n.Observation), filter) {
+				kept = append(kept, n)
+			}
+		}
+		notes = kept
+	}
+	if len(notes) == 0 {
+		return "No notes.", nil
+	}
+
+	return withTokenEstimate(formatNotes(notes)), nil
+}
+
+// Notes returns a copy of the notes recorded in an explore session.
+func (t *ExploreTools) Notes(session string) []Note {
+	t.notesMu.Lock()
+	defer t.notesMu.Unlock()
+	return append([]Note(nil), t.notes[session]...)
+}
+
+// clearNotes drops a session's notes.
+func (t *ExploreTools) clearNotes(session string) {
+	t.notesMu.Lock()
+	defer t.notesMu.Unlock()
+	delete(t.notes, session)
+}
+
+// formatNotes renders notes one per line: "- file (symbol): observation".
+func formatNotes(notes []Note) string {
+	var sb strings.Builder
+	for _, n := range notes {
+		sb.WriteString("- ")
+		switch {
+		case n.File != "" && n.Symbol != "":
+			fmt.Fprintf(&sb, "%s (%s): ", n.File, n.Symbol)
+		case n.File != "":
+			sb.WriteString(n.File + ": ")
+		case n.Symbol != "":
+			sb.WriteString(n.Symbol + ": ")
+		}
+		sb.WriteString(n.Observation)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
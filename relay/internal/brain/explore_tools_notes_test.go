@@ -0,0 +1,73 @@
+package brain_test
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/internal/brain"
+)
+
+var _ = Describe("ExploreTools notes", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		tools   *brain.ExploreTools
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-notes-test-*")
+		Expect(err).NotTo(HaveOccurred())
+
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	It("records notes and reads them back, optionally filtered", func() {
+		result, err := tools.Execute(ctx, "write_note", `{"file":"store/issue.go:88","symbol":"IssueStore.Upsert","observation":"Upserts on external_id."}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("Noted (1 total)."))
+		_, err = tools.Execute(ctx, "write_note", `{"observation":"Webhooks are verified before queueing."}`)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err = tools.Execute(ctx, "read_notes", `{}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("- store/issue.go:88 (IssueStore.Upsert): Upserts on external_id."))
+		Expect(result).To(ContainSubstring("- Webhooks are verified before queueing."))
+
+		result, err = tools.Execute(ctx, "read_notes", `{"filter":"WEBHOOK"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("Webhooks are verified"))
+		Expect(result).NotTo(ContainSubstring("Upsert"))
+
+		Expect(tools.Notes("")).To(Equal([]brain.Note{
+			{File: "store/issue.go:88", Symbol: "IssueStore.Upsert", Observation: "Upserts on external_id."},
+			{Observation: "Webhooks are verified before queueing."},
+		}))
+	})
+
+	It("requires an observation", func() {
+		result, err := tools.Execute(ctx, "write_note", `{"file":"main.go"}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("Error: observation is required"))
+		Expect(tools.Notes("")).To(BeEmpty())
+	})
+
+	It("reports an empty scratchpad", func() {
+		result, err := tools.Execute(ctx, "read_notes", `{}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal("No notes."))
+	})
+})
@@ -0,0 +1,94 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"basegraph.co/relay/common/arangodb"
+	"basegraph.co/relay/common/llm"
+)
+
+// RawQueryParams for running a read-only AQL query against the codegraph.
+type RawQueryParams struct {
+	Query string `json:"query" jsonschema:"required,description=Read-only AQL query. No INSERT/UPDATE/REPLACE/REMOVE/UPSERT, no bind parameters, codegraph collections only."`
+}
+
+func rawQueryToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "raw_query",
+		Description: `Run a custom read-only AQL query against the code graph, for questions the codegraph operations can't express.
+
+Collections - nodes: functions, types, members, files, modules, queues; edges: calls, implements, inherits, returns, param_of, parent, imports, decorated_by, async_enqueue (functions -> queues), async_dequeue (queues -> functions).
+Node fields: qname, name, kind, filepath, pos, signature. Results are capped at 200 rows and 10s.
+
+Prefer codegraph operations; use this only when none fits.
+
+Examples:
+  raw_query(query="FOR f IN functions FILTER f.name LIKE 'Handle%' COLLECT file = f.filepath WITH COUNT INTO n SORT n DESC LIMIT 10 RETURN {file, n}")
+  raw_query(query="FOR t IN types FILTER LENGTH(FOR e IN implements FILTER e._to == t._id RETURN 1) > 5 RETURN t.qname")`,
+		Parameters: llm.GenerateSchemaFrom(RawQueryParams{}),
+	}
+}
+
+// WithRawQuery exposes the raw_query tool. It is off by default: even with
+// the read-only checks, arbitrary queries can be expensive. Queries only run
+// when the arangodb client has a read-only user (RawQueryUsername).
+func (t *ExploreTools) WithRawQuery(enabled bool) *ExploreTools {
+	t.rawQueryEnabled = enabled
+	if enabled && t.arango != nil {
+		t.definitions = append(t.definitions, rawQueryToolDefinition())
+	}
+	return t
+}
+
+// executeRawQuery runs a validated read-only AQL query and returns its rows as
+// JSON lines.
+func (t *ExploreTools) executeRawQuery(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[RawQueryParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse raw_query params: %w", err)
+	}
+
+	if !t.rawQueryEnabled {
+		return "Error: raw_query is disabled in this deployment - use codegraph operations instead", nil
+	}
+	if t.arango == nil {
+		return "Codegraph is not available. Use grep and read tools instead.", nil
+	}
+	if msg := t.codegraphIndexMessage(ctx); msg != "" {
+		return msg, nil
+	}
+
+	result, err := t.arango.RawQuery(ctx, params.Query)
+	if errors.Is(err, arangodb.ErrQueryNotAllowed) {
+		return fmt.Sprintf("Error: %s", err), nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "codegraph raw query failed", "error", err)
+		return formatCodegraphError("running query", err), nil
+	}
+	if len(result.Rows) == 0 {
+		return "Query returned no rows.", nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d row(s):\n", len(result.Rows))
+	for _, row := range result.Rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return "", fmt.Errorf("encode row: %w", err)
+		}
+		sb.Write(data)
+		sb.WriteString("\n")
+	}
+	if result.Truncated {
+		sb.WriteString(t.truncationFooter(ctx, fmt.Sprintf("[Showing first %d rows. Add LIMIT or a tighter FILTER.]", arangodb.MaxRawQueryRows)))
+		sb.WriteString("\n")
+	}
+
+	return withTokenEstimate(strings.TrimSpace(sb.String())), nil
+}
@@ -0,0 +1,169 @@
+package brain
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// redactedPathPrefix replaces the directories of absolute paths outside the
+// repo when foreign path redaction is on. The base name is kept so errors like
+// "open <redacted>/config.yaml" still say what was missing.
+const redactedPathPrefix = "<redacted>"
+
+// foreignPathPattern matches absolute paths with at least two segments, after
+// the start of a line, whitespace, a quote or a separator that commonly
+// precedes paths in error text. "//" comments and URLs don't match.
+var foreignPathPattern = regexp.MustCompile(`(?m)(^|[\s'"(=:])(/[A-Za-z0-9._-]+(?:/[^\s'"():,;]*)+)`)
+
+// redactedError keeps the cause for errors.Is/As while hiding paths in the message.
+type redactedError struct {
+	msg   string
+	cause error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.cause }
+
+// WithPathRedaction also masks absolute paths outside the repo (module cache,
+// temp dirs, system files) in tool output, for deployments where the host
+// layout must not reach the LLM. Paths under the repo root are always made
+// relative.
+func (t *ExploreTools) WithPathRedaction(redactForeign bool) *ExploreTools {
+	t.redactForeignPaths = redactForeign
+	return t
+}
+
+// contentTools return file contents, where absolute-looking strings outside
+// the repo are routes, URL paths and literals rather than host paths.
+var contentTools = map[string]bool{
+	"read":        true,
+	"grep":        true,
+	"ast_grep":    true,
+	"bash":        true,
+	"symbol_diff": true,
+	"git_history": true,
+}
+
+// redactOutput applies redactPaths to a tool's output. Successful output of
+// contentTools only has repo paths relativized; foreign paths there are
+// masked only when the call failed and the output is an error message.
+func (t *ExploreTools) redactOutput(tool, output string) string {
+	if contentTools[tool] && !isErrorOutput(output) {
+		return t.relativizeRepoPaths(output)
+	}
+	return t.redactPaths(output)
+}
+
+// isErrorOutput reports whether a tool returned an error message in place of
+// its result.
+func isErrorOutput(output string) bool {
+	return strings.HasPrefix(output, "Error") ||
+		strings.HasPrefix(output, "Command failed") ||
+		strings.HasPrefix(output, "Command blocked")
+}
+
+// redactPaths relativizes repo paths and, when enabled, masks other absolute paths.
+func (t *ExploreTools) redactPaths(s string) string {
+	s = t.relativizeRepoPaths(s)
+	if !t.redactForeignPaths {
+		return s
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range foreignPathPattern.FindAllStringSubmatchIndex(s, -1) {
+		pathStart, pathEnd := m[4], m[5]
+		sb.WriteString(s[last:pathStart])
+		sb.WriteString(redactedPathPrefix + "/" + filepath.Base(s[pathStart:pathEnd]))
+		last = pathEnd
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// relativizeRepoPaths rewrites "<root>/x" to "x" and a bare "<root>" to ".".
+func (t *ExploreTools) relativizeRepoPaths(s string) string {
+	for _, root := range t.repoRoots() {
+		s = relativizeRoot(s, root)
+	}
+	return s
+}
+
+// relativizeRoot replaces root in s only where the path ends with it or
+// continues below it, so a sibling like /srv/repo2 is left alone.
+func relativizeRoot(s, root string) string {
+	var sb strings.Builder
+	last, from := 0, 0
+	for {
+		i := strings.Index(s[from:], root)
+		if i < 0 {
+			break
+		}
+		start, end := from+i, from+i+len(root)
+		from = start + 1
+
+		var replacement string
+		switch {
+		case end < len(s) && s[end] == filepath.Separator:
+			end++
+		case end < len(s) && isPathNameByte(s[end]):
+			continue
+		default:
+			replacement = "."
+		}
+		sb.WriteString(s[last:start])
+		sb.WriteString(replacement)
+		last, from = end, end
+	}
+	if last == 0 {
+		return s
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// isPathNameByte reports whether c continues the last segment of a path.
+func isPathNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '.' || c == '_' || c == '-' || c == filepath.Separator
+}
+
+// redactError applies redactPaths to an error message, keeping the cause.
+func (t *ExploreTools) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := t.redactPaths(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, cause: err}
+}
+
+// repoRoots returns the spellings of the repo root that may appear in output:
+// as configured, absolute, and with symlinks resolved (e.g. /tmp vs /private/tmp).
+func (t *ExploreTools) repoRoots() []string {
+	seen := make(map[string]bool)
+	var roots []string
+	add := func(p string) {
+		p = strings.TrimRight(p, string(filepath.Separator))
+		if p == "" || seen[p] {
+			return
+		}
+		seen[p] = true
+		roots = append(roots, p)
+	}
+
+	add(t.repoRoot)
+	if abs, err := filepath.Abs(t.repoRoot); err == nil {
+		add(abs)
+		if real, err := filepath.EvalSymlinks(abs); err == nil {
+			add(real)
+		}
+	}
+	// Longest first, so a root nested in another spelling is replaced whole.
+	sort.Slice(roots, func(i, j int) bool { return len(roots[i]) > len(roots[j]) })
+	return roots
+}
@@ -0,0 +1,136 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/common/arangodb"
+	"basegraph.co/relay/internal/brain"
+)
+
+var _ = Describe("ExploreTools path redaction", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		fake    *fakeArangoClient
+		tools   *brain.ExploreTools
+	)
+
+	execute := func(name string, args map[string]any) string {
+		raw, _ := json.Marshal(args)
+		result, err := tools.Execute(ctx, name, string(raw))
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-redact-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(tempDir, "src"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tempDir, "src", "main.go"), []byte("package main\n"), 0o644)).To(Succeed())
+
+		fake = &fakeArangoClient{}
+		tools = brain.NewExploreTools(tempDir, fake, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	It("relativizes repo paths in read errors", func() {
+		result := execute("read", map[string]any{"file_path": "src"})
+
+		Expect(result).To(ContainSubstring("src"))
+		Expect(result).NotTo(ContainSubstring(tempDir))
+	})
+
+	It("relativizes repo paths in bash errors", func() {
+		result := execute("bash", map[string]any{"command": `cat "$PWD/src/missing.go"`})
+
+		Expect(result).To(ContainSubstring("Command failed"))
+		Expect(result).To(ContainSubstring("src/missing.go"))
+		Expect(result).NotTo(ContainSubstring(tempDir))
+	})
+
+	It("relativizes repo paths in codegraph errors", func() {
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			return nil, 0, fmt.Errorf("index stale for %s", filepath.Join(tempDir, "src", "main.go"))
+		}
+
+		result := execute("codegraph", map[string]any{"operation": "search", "name": "Plan"})
+
+		Expect(result).To(ContainSubstring("index stale for src/main.go"))
+		Expect(result).NotTo(ContainSubstring(tempDir))
+	})
+
+	It("relativizes returned errors", func() {
+		_, err := tools.Execute(ctx, "no_such_tool_"+tempDir, "{}")
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).NotTo(ContainSubstring(tempDir))
+	})
+
+	It("leaves paths outside the repo alone by default", func() {
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			return nil, 0, errors.New("open /home/deploy/.cache/codegraph/index.db: permission denied")
+		}
+
+		result := execute("codegraph", map[string]any{"operation": "search", "name": "Plan"})
+
+		Expect(result).To(ContainSubstring("/home/deploy/.cache/codegraph/index.db"))
+	})
+
+	It("masks paths outside the repo when enabled", func() {
+		tools.WithPathRedaction(true)
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			return nil, 0, errors.New("open /home/deploy/.cache/codegraph/index.db: permission denied")
+		}
+
+		result := execute("codegraph", map[string]any{"operation": "search", "name": "Plan"})
+
+		Expect(result).To(ContainSubstring("open <redacted>/index.db: permission denied"))
+		Expect(result).NotTo(ContainSubstring("/home/deploy"))
+	})
+
+	It("leaves a sibling directory that shares the root's prefix alone", func() {
+		fake.searchSymbolsFn = func(ctx context.Context, opts arangodb.SearchOptions) ([]arangodb.SearchResult, int, error) {
+			return nil, 0, fmt.Errorf("index for %s2/x conflicts with %s", tempDir, filepath.Join(tempDir, "src", "main.go"))
+		}
+
+		result := execute("codegraph", map[string]any{"operation": "search", "name": "Plan"})
+
+		Expect(result).To(ContainSubstring("index for " + tempDir + "2/x conflicts with src/main.go"))
+		Expect(result).NotTo(ContainSubstring(".2/x"))
+	})
+
+	It("does not mask route literals in file contents", func() {
+		tools.WithPathRedaction(true)
+		Expect(os.WriteFile(filepath.Join(tempDir, "src", "routes.go"),
+			[]byte("package main\n\nfunc routes() {\n\tmux.HandleFunc(\"/api/v1/users\", listUsers)\n}\n"), 0o644)).To(Succeed())
+
+		Expect(execute("read", map[string]any{"file_path": "src/routes.go"})).To(ContainSubstring(`"/api/v1/users"`))
+		Expect(execute("grep", map[string]any{"pattern": "HandleFunc"})).To(ContainSubstring(`"/api/v1/users"`))
+	})
+
+	It("does not mask URLs or comments", func() {
+		tools.WithPathRedaction(true)
+		Expect(os.WriteFile(filepath.Join(tempDir, "src", "doc.go"),
+			[]byte("// See https://example.com/docs/a\npackage main\n"), 0o644)).To(Succeed())
+
+		result := execute("read", map[string]any{"file_path": "src/doc.go"})
+
+		Expect(result).To(ContainSubstring("// See https://example.com/docs/a"))
+	})
+})
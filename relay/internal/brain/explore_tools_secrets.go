@@ -0,0 +1,154 @@
+package brain
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// redactedSecret replaces secrets in tool output.
+const redactedSecret = "<redacted-secret>"
+
+// defaultSecretPatterns match well-known credential formats. A pattern with a
+// group named "secret" masks only that group, so the key of a KEY=value line
+// stays readable.
+var defaultSecretPatterns = []string{
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,                                             // AWS access key id
+	`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`,           // JWT
+	`\bgh[pousr]_[A-Za-z0-9]{30,}\b`,                                            // GitHub token
+	`\bxox[abprs]-[A-Za-z0-9-]{10,}`,                                            // Slack token
+	`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`,                                    // OpenAI/Anthropic key
+	`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`, // PEM private key
+	// Env-style assignments: FOO_API_KEY=..., DB_PASSWORD: "...". Upper case
+	// only, so Go code like Password: cfg.Password is left alone.
+	`\b[A-Z0-9_]*(?:API_KEY|SECRET|TOKEN|PASSWORD|PASSWD)[A-Z0-9_]*\s*[=:]\s*["']?(?P<secret>[^\s"'$]{8,})`,
+}
+
+// DefaultSecretPatterns returns the secret patterns tool output is masked
+// with unless ExploreToolsConfig.SecretPatterns replaces them.
+func DefaultSecretPatterns() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(defaultSecretPatterns))
+	for i, p := range defaultSecretPatterns {
+		patterns[i] = regexp.MustCompile(p)
+	}
+	return patterns
+}
+
+// LoadSecretPatterns returns DefaultSecretPatterns followed by the patterns
+// in the file at path, one regular expression per line. Blank lines and
+// lines starting with # are skipped. An empty path returns the defaults.
+func LoadSecretPatterns(path string) ([]*regexp.Regexp, error) {
+	patterns := DefaultSecretPatterns()
+	if path == "" {
+		return patterns, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open secret patterns: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		re, err := regexp.Compile(text)
+		if err != nil {
+			return nil, fmt.Errorf("secret pattern on line %d: %w", line, err)
+		}
+		patterns = append(patterns, re)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read secret patterns: %w", err)
+	}
+	return patterns, nil
+}
+
+// entropyCandidatePattern matches quoted or assigned tokens that look like
+// encoded keys; redactSecrets masks the ones that are random enough.
+var entropyCandidatePattern = regexp.MustCompile("(?:[\"'`]|[=:]\\s*)([A-Za-z0-9+/_-]{24,}={0,2})")
+
+// minSecretEntropy is the Shannon entropy, in bits per character, above
+// which a candidate token is treated as a key. Hex digests top out at 4.
+const minSecretEntropy = 4.2
+
+// redactSecrets masks known secret formats and high-entropy tokens in s.
+func (t *ExploreTools) redactSecrets(s string) string {
+	for _, re := range t.secretPatterns {
+		s = maskMatches(s, re, re.SubexpIndex("secret"))
+	}
+	return maskMatches(s, entropyCandidatePattern, 1, looksRandom)
+}
+
+// maskMatches replaces the matches of re in s (group, when > 0, instead of the
+// whole match) that pass every filter.
+func maskMatches(s string, re *regexp.Regexp, group int, filters ...func(string) bool) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	if group < 0 {
+		group = 0
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 || start < last {
+			continue
+		}
+		if !passesAll(s[start:end], filters) {
+			continue
+		}
+		sb.WriteString(s[last:start])
+		sb.WriteString(redactedSecret)
+		last = end
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+func passesAll(s string, filters []func(string) bool) bool {
+	for _, f := range filters {
+		if !f(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// looksRandom reports whether token mixes upper case, lower case and digits
+// with high entropy, as generated keys do and identifiers and hashes don't.
+func looksRandom(token string) bool {
+	var upper, lower, digit bool
+	counts := make(map[rune]int)
+	for _, r := range token {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+		counts[r]++
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+
+	var entropy float64
+	n := float64(len(token))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= minSecretEntropy
+}
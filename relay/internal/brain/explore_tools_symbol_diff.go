@@ -0,0 +1,268 @@
+package brain
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"basegraph.co/relay/common/llm"
+)
+
+const defaultSymbolDiffFrom = "HEAD~1"
+
+// SymbolDiffParams for comparing a file's symbols between two git refs.
+type SymbolDiffParams struct {
+	File string `json:"file" jsonschema:"required,description=Go file to compare, relative to repo root (e.g. 'internal/brain/planner.go')"`
+	From string `json:"from,omitempty" jsonschema:"description=Base ref (default HEAD~1)"`
+	To   string `json:"to,omitempty" jsonschema:"description=Target ref (default HEAD)"`
+}
+
+// gitRefPattern allows branch/tag names, SHAs and rev suffixes (HEAD~3, main^, @{1}).
+// Leading dashes are rejected separately so a ref can't become a flag.
+var gitRefPattern = regexp.MustCompile(`^[A-Za-z0-9_./~^@{}-]+$`)
+
+// goSymbol is a top-level declaration, keyed by Name or Recv.Name.
+type goSymbol struct {
+	name string
+	kind string // function, method, struct, interface, type
+	line int
+	body string // Declaration tokens without comments, so formatting-only edits don't count
+}
+
+type symbolChange struct {
+	change string // added, removed, modified
+	symbol goSymbol
+}
+
+func symbolDiffToolDefinition() llm.Tool {
+	return llm.Tool{
+		Name: "symbol_diff",
+		Description: `Show which functions, methods and types changed in a Go file between two git refs.
+
+Reports each top-level symbol as added, removed or modified, followed by the diff itself.
+Use it for "what changed in X recently" before reading the raw diff.
+
+Examples:
+  symbol_diff(file="internal/brain/planner.go")                       # HEAD~1..HEAD
+  symbol_diff(file="internal/brain/planner.go", from="main", to="HEAD")`,
+		Parameters: llm.GenerateSchemaFrom(SymbolDiffParams{}),
+	}
+}
+
+// executeSymbolDiff compares a Go file's top-level declarations at two refs.
+func (t *ExploreTools) executeSymbolDiff(ctx context.Context, arguments string) (string, error) {
+	params, err := llm.ParseToolArguments[SymbolDiffParams](arguments)
+	if err != nil {
+		return "", fmt.Errorf("parse symbol_diff params: %w", err)
+	}
+
+	if t.gitDisabled {
+		return "Error: git is disabled in this deployment - use grep, read and codegraph instead", nil
+	}
+	if params.File == "" {
+		return "Error: file is required", nil
+	}
+	if !strings.HasSuffix(params.File, ".go") {
+		return fmt.Sprintf("Error: symbol_diff supports Go files only. Use bash: git diff %s -- %s", defaultSymbolDiffFrom, params.File), nil
+	}
+	if !pathWithinRoot(t.repoRoot, filepath.Join(t.repoRoot, params.File)) {
+		return "Error: path outside repository", nil
+	}
+	relPath := filepath.ToSlash(filepath.Clean(params.File))
+
+	from, to := params.From, params.To
+	if from == "" {
+		from = defaultSymbolDiffFrom
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	for _, ref := range []string{from, to} {
+		if strings.HasPrefix(ref, "-") || !gitRefPattern.MatchString(ref) {
+			return fmt.Sprintf("Error: invalid ref %q", ref), nil
+		}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, t.timeouts.Bash)
+	defer cancel()
+
+	before, errMsg := t.symbolsAtRef(timeoutCtx, from, relPath)
+	if errMsg != "" {
+		return errMsg, nil
+	}
+	after, errMsg := t.symbolsAtRef(timeoutCtx, to, relPath)
+	if errMsg != "" {
+		return errMsg, nil
+	}
+	if before == nil && after == nil {
+		return fmt.Sprintf("%s does not exist at %s or %s.", relPath, from, to), nil
+	}
+
+	changes, unchanged := diffSymbols(before, after)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Symbol changes in %s (%s..%s):\n", relPath, from, to)
+	switch {
+	case before == nil:
+		sb.WriteString("(file added)\n")
+	case after == nil:
+		sb.WriteString("(file removed)\n")
+	}
+	if len(changes) == 0 {
+		sb.WriteString("No function or type changed.\n")
+	}
+	for _, c := range changes {
+		loc := fmt.Sprintf("%s:%d", relPath, c.symbol.line)
+		if c.change == "removed" {
+			loc = "(was " + loc + ")"
+		}
+		fmt.Fprintf(&sb, "%s\t%s\t%s\t%s\n", c.change, c.symbol.kind, c.symbol.name, loc)
+	}
+	if unchanged > 0 {
+		fmt.Fprintf(&sb, "\nUnchanged: %d symbol(s).\n", unchanged)
+	}
+
+	cmd := exec.CommandContext(timeoutCtx, "git", "-C", t.repoRoot, "diff", "-U1", from, to, "--", relPath)
+	if diff, err := cmd.Output(); err == nil && len(diff) > 0 {
+		sb.WriteString("\nDiff:\n")
+		sb.WriteString(t.truncateOutput(ctx, diff))
+		sb.WriteString("\n")
+	}
+
+	return withTokenEstimate(sb.String()), nil
+}
+
+// symbolsAtRef parses relPath as of ref. A nil map means the file doesn't
+// exist at that ref; a non-empty errMsg is returned to the agent as-is.
+//
+// This parses the blob rather than asking the codegraph: the graph holds a
+// single snapshot (whatever was last ingested, not any given ref) and
+// records a symbol's signature and span but not its body, so it can say
+// neither what a file looked like at from nor whether a body changed.
+
+func (t *ExploreTools) symbolsAtRef(ctx context.Context, ref, relPath string) (map[string]goSymbol, string) {
+	verify := exec.CommandContext(ctx, "git", "-C", t.repoRoot, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err := verify.Run(); err != nil {
+		return nil, fmt.Sprintf("Error: unknown git ref %q", ref)
+	}
+
+	show := exec.CommandContext(ctx, "git", "-C", t.repoRoot, "show", ref+":"+relPath)
+	src, err := show.Output()
+	if err != nil {
+		return nil, ""
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, relPath, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Sprintf("Error: %s does not parse at %s: %s", relPath, ref, err)
+	}
+	return topLevelSymbols(fset, file, src), ""
+}
+
+// topLevelSymbols collects functions, methods and type declarations.
+func topLevelSymbols(fset *token.FileSet, file *ast.File, src []byte) map[string]goSymbol {
+	symbols := make(map[string]goSymbol)
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			kind := "function"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			name := funcDisplayName(d)
+			symbols[name] = goSymbol{name: name, kind: kind, line: fset.Position(d.Pos()).Line, body: declTokens(fset, src, d)}
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				kind := "type"
+				switch ts.Type.(type) {
+				case *ast.StructType:
+					kind = "struct"
+				case *ast.InterfaceType:
+					kind = "interface"
+				}
+				name := ts.Name.Name
+				symbols[name] = goSymbol{name: name, kind: kind, line: fset.Position(ts.Pos()).Line, body: declTokens(fset, src, ts)}
+			}
+		}
+	}
+	return symbols
+}
+
+// declTokens renders a node's source as its token sequence, dropping comments
+// and the semicolons Go inserts at line ends, so reformatting a declaration
+// doesn't make it look modified.
+func declTokens(fset *token.FileSet, src []byte, node ast.Node) string {
+	start, end := fset.Position(node.Pos()).Offset, fset.Position(node.End()).Offset
+	if start < 0 || end > len(src) || start >= end {
+		return ""
+	}
+	body := src[start:end]
+
+	var s scanner.Scanner
+	s.Init(token.NewFileSet().AddFile("", -1, len(body)), body, nil, 0)
+
+	var sb strings.Builder
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		if lit == "" {
+			lit = tok.String()
+		}
+		sb.WriteString(lit)
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+// diffSymbols classifies symbols as added, removed or modified, ordered by
+// change then line.
+func diffSymbols(before, after map[string]goSymbol) ([]symbolChange, int) {
+	var changes []symbolChange
+	unchanged := 0
+	for name, a := range after {
+		b, existed := before[name]
+		switch {
+		case !existed:
+			changes = append(changes, symbolChange{change: "added", symbol: a})
+		case b.body != a.body:
+			changes = append(changes, symbolChange{change: "modified", symbol: a})
+		default:
+			unchanged++
+		}
+	}
+	for name, b := range before {
+		if _, ok := after[name]; !ok {
+			changes = append(changes, symbolChange{change: "removed", symbol: b})
+		}
+	}
+
+	order := map[string]int{"modified": 0, "added": 1, "removed": 2}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].change != changes[j].change {
+			return order[changes[i].change] < order[changes[j].change]
+		}
+		return changes[i].symbol.line < changes[j].symbol.line
+	})
+	return changes, unchanged
+}
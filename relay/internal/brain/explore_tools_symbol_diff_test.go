@@ -0,0 +1,133 @@
+package brain_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/internal/brain"
+)
+
+var _ = Describe("ExploreTools symbol_diff", func() {
+	var (
+		ctx     context.Context
+		tempDir string
+		tools   *brain.ExploreTools
+	)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", tempDir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+	}
+
+	commit := func(content string) {
+		Expect(os.WriteFile(filepath.Join(tempDir, "store", "user.go"), []byte(content), 0o644)).To(Succeed())
+		git("add", "-A")
+		git("commit", "-q", "-m", "change")
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "explore-symbol-diff-test-*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(tempDir, "store"), 0o755)).To(Succeed())
+
+		git("init", "-q")
+		commit(`package store
+
+type UserStore struct{}
+
+// Find looks up a user.
+func (s *UserStore) Find(id string) string {
+	return id
+}
+
+func Count() int { return 0 }
+
+func Legacy() {}
+`)
+		commit(`package store
+
+type UserStore struct{}
+
+// Find looks up a user by ID.
+func (s *UserStore) Find(id string) string {
+	if id == "" {
+		return "anonymous"
+	}
+	return id
+}
+
+func Count() int {
+	return 0
+}
+
+func Purge() {}
+`)
+
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
+	})
+
+	AfterEach(func() {
+		if tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	})
+
+	It("flags the modified, added and removed symbols", func() {
+		args, _ := json.Marshal(map[string]any{"file": "store/user.go"})
+
+		result, err := tools.Execute(ctx, "symbol_diff", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("Symbol changes in store/user.go (HEAD~1..HEAD)"))
+		Expect(result).To(ContainSubstring("modified\tmethod\tUserStore.Find\tstore/user.go:6"))
+		Expect(result).To(ContainSubstring("added\tfunction\tPurge"))
+		Expect(result).To(ContainSubstring("removed\tfunction\tLegacy\t(was store/user.go:12)"))
+		Expect(result).To(ContainSubstring("Unchanged: 2 symbol(s)."))
+		Expect(result).NotTo(ContainSubstring("\tCount\t"))
+		Expect(result).To(ContainSubstring("Diff:"))
+	})
+
+	It("rejects refs that look like flags", func() {
+		args, _ := json.Marshal(map[string]any{"file": "store/user.go", "from": "--output=/tmp/x"})
+
+		result, err := tools.Execute(ctx, "symbol_diff", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("invalid ref"))
+	})
+
+	It("reports unknown refs", func() {
+		args, _ := json.Marshal(map[string]any{"file": "store/user.go", "from": "nope"})
+
+		result, err := tools.Execute(ctx, "symbol_diff", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring(`unknown git ref "nope"`))
+	})
+
+	It("is unavailable when git is disabled", func() {
+		tools.WithGitDisabled(true)
+		args, _ := json.Marshal(map[string]any{"file": "store/user.go"})
+
+		result, err := tools.Execute(ctx, "symbol_diff", string(args))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring("git is disabled"))
+		for _, def := range tools.Definitions() {
+			Expect(def.Name).NotTo(Equal("symbol_diff"))
+		}
+	})
+})
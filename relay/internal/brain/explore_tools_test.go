@@ -3,8 +3,12 @@ package brain_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -40,7 +44,7 @@ var _ = Describe("ExploreTools", func() {
 		Expect(os.WriteFile(filepath.Join(tempDir, "README.md"), []byte("# Test Project\n\nThis is a test.\n"), 0o644)).To(Succeed())
 
 		// Create tools for testing (nil arango client since we're only testing file tools)
-		tools = brain.NewExploreTools(tempDir, nil)
+		tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{})
 	})
 
 	AfterEach(func() {
@@ -89,6 +93,35 @@ var _ = Describe("ExploreTools", func() {
 			Expect(result).To(ContainSubstring("lines 1-2"))
 		})
 
+		It("reads through end_line", func() {
+			args, _ := json.Marshal(map[string]any{
+				"file_path": "src/main.go",
+				"offset":    3,
+				"end_line":  4,
+			})
+
+			result, err := tools.Execute(ctx, "read", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("func main()"))
+			Expect(result).To(ContainSubstring("println"))
+			Expect(result).NotTo(ContainSubstring("package main"))
+			Expect(result).To(ContainSubstring("lines 3-4"))
+		})
+
+		It("rejects end_line before offset", func() {
+			args, _ := json.Marshal(map[string]any{
+				"file_path": "src/main.go",
+				"offset":    4,
+				"end_line":  2,
+			})
+
+			result, err := tools.Execute(ctx, "read", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("end_line (2) is before offset (4)"))
+		})
+
 		It("returns error for missing file", func() {
 			args, _ := json.Marshal(map[string]any{
 				"file_path": "nonexistent.go",
@@ -119,6 +152,48 @@ var _ = Describe("ExploreTools", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(ContainSubstring("file_path is required"))
 		})
+
+		Context("with a file above the size limit", func() {
+			BeforeEach(func() {
+				// ~40 KB of generated code against a 4 KB limit.
+				var b strings.Builder
+				for i := 1; i <= 1000; i++ {
+					fmt.Fprintf(&b, "var Field%04d = \"generated value %04d\"\n", i, i)
+				}
+				Expect(os.WriteFile(filepath.Join(tempDir, "gen.pb.go"), []byte(b.String()), 0o644)).To(Succeed())
+				tools = brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{MaxReadFileBytes: 4096})
+			})
+
+			It("refuses to scan deep into the file", func() {
+				args, _ := json.Marshal(map[string]any{"file_path": "gen.pb.go", "offset": 900})
+
+				result, err := tools.Execute(ctx, "read", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("too large to read from line 900"))
+				Expect(result).To(ContainSubstring("use grep"))
+				Expect(result).NotTo(ContainSubstring("Field0900"))
+			})
+
+			It("still reads lines within the limit", func() {
+				args, _ := json.Marshal(map[string]any{"file_path": "gen.pb.go", "offset": 10, "limit": 5})
+
+				result, err := tools.Execute(ctx, "read", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("Field0010"))
+				Expect(result).To(ContainSubstring("lines 10-14"))
+			})
+
+			It("leaves smaller files alone", func() {
+				args, _ := json.Marshal(map[string]any{"file_path": "src/main.go", "offset": 3})
+
+				result, err := tools.Execute(ctx, "read", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("func main()"))
+			})
+		})
 	})
 
 	Describe("Grep Tool", func() {
@@ -169,6 +244,26 @@ var _ = Describe("ExploreTools", func() {
 			Expect(result).To(ContainSubstring("No matches"))
 		})
 
+		It("lists each matching file once with files_only", func() {
+			if _, err := exec.LookPath("rg"); err != nil {
+				Skip("rg not installed")
+			}
+			args, _ := json.Marshal(map[string]any{
+				"pattern":    "package|func",
+				"glob":       "*.go",
+				"files_only": true,
+			})
+
+			result, err := tools.Execute(ctx, "grep", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(result, "src/main.go\n")).To(Equal(1))
+			Expect(strings.Count(result, "src/util/helper.go\n")).To(Equal(1))
+			Expect(result).NotTo(ContainSubstring("README.md"))
+			Expect(result).NotTo(MatchRegexp(`\.go:\d+`))
+			Expect(result).NotTo(ContainSubstring(tempDir))
+		})
+
 		It("returns error for missing pattern", func() {
 			args, _ := json.Marshal(map[string]any{})
 
@@ -216,6 +311,60 @@ var _ = Describe("ExploreTools", func() {
 			Expect(result).To(ContainSubstring("No files match"))
 		})
 
+		It("leaves out excluded patterns", func() {
+			Expect(os.WriteFile(filepath.Join(tempDir, "src", "api.pb.go"), []byte("package main\n"), 0o644)).To(Succeed())
+			args, _ := json.Marshal(map[string]any{
+				"pattern": "*.go",
+				"exclude": []string{"util/", "*.pb.go"},
+			})
+
+			result, err := tools.Execute(ctx, "glob", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(ContainSubstring("main.go"))
+			Expect(result).NotTo(ContainSubstring("helper.go"))
+			Expect(result).NotTo(ContainSubstring("api.pb.go"))
+		})
+
+		Context("with a symlinked directory outside the repo", func() {
+			BeforeEach(func() {
+				outside, err := os.MkdirTemp("", "explore-tools-outside-*")
+				Expect(err).NotTo(HaveOccurred())
+				DeferCleanup(os.RemoveAll, outside)
+				Expect(os.WriteFile(filepath.Join(outside, "secret.go"), []byte("package secret\n"), 0o644)).To(Succeed())
+				Expect(os.Symlink(outside, filepath.Join(tempDir, "linked"))).To(Succeed())
+			})
+
+			It("does not traverse it by default", func() {
+				args, _ := json.Marshal(map[string]any{"pattern": "*.go"})
+
+				result, err := tools.Execute(ctx, "glob", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("main.go"))
+				Expect(result).NotTo(ContainSubstring("secret.go"))
+			})
+
+			It("never returns files outside the repo when following symlinks", func() {
+				args, _ := json.Marshal(map[string]any{"pattern": "*.go", "follow_symlinks": true})
+
+				result, err := tools.Execute(ctx, "glob", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("main.go"))
+				Expect(result).NotTo(ContainSubstring("secret.go"))
+			})
+
+			It("rejects it as the search path", func() {
+				args, _ := json.Marshal(map[string]any{"pattern": "*.go", "path": "linked"})
+
+				result, err := tools.Execute(ctx, "glob", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("path outside repository"))
+			})
+		})
+
 		It("returns error for missing pattern", func() {
 			args, _ := json.Marshal(map[string]any{})
 
@@ -238,6 +387,89 @@ var _ = Describe("ExploreTools", func() {
 		})
 	})
 
+	Describe("Truncation", func() {
+		writeFiles := func(n int, content string) {
+			dir := filepath.Join(tempDir, "gen")
+			Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+			for i := 0; i < n; i++ {
+				Expect(os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.txt", i)), []byte(content), 0o644)).To(Succeed())
+			}
+		}
+
+		It("flags glob results cut at the cap", func() {
+			writeFiles(101, "x\n")
+			args, _ := json.Marshal(map[string]any{"pattern": "*.txt"})
+
+			res, err := tools.ExecuteResult(ctx, "glob", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Truncated).To(BeTrue())
+			Expect(res.Output).To(ContainSubstring("[truncated] [Showing 100 of 101+ matches."))
+		})
+
+		It("does not flag results under the cap", func() {
+			args, _ := json.Marshal(map[string]any{"pattern": "*.go"})
+
+			res, err := tools.ExecuteResult(ctx, "glob", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Truncated).To(BeFalse())
+			Expect(res.Output).NotTo(ContainSubstring("[truncated]"))
+		})
+
+		It("flags grep results cut at the cap", func() {
+			if _, err := exec.LookPath("rg"); err != nil {
+				Skip("rg not installed")
+			}
+			writeFiles(60, "needle\n")
+			args, _ := json.Marshal(map[string]any{"pattern": "needle"})
+
+			res, err := tools.ExecuteResult(ctx, "grep", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Truncated).To(BeTrue())
+			Expect(res.Output).To(ContainSubstring("[truncated] [Showing 50 matches."))
+		})
+
+		It("pages grep results with offset", func() {
+			if _, err := exec.LookPath("rg"); err != nil {
+				Skip("rg not installed")
+			}
+			writeFiles(60, "needle\n")
+			first, _ := json.Marshal(map[string]any{"pattern": "needle"})
+			second, _ := json.Marshal(map[string]any{"pattern": "needle", "offset": 50})
+
+			page1, err := tools.Execute(ctx, "grep", string(first))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page1).To(ContainSubstring("10 more matches; call grep again with offset=50"))
+			page2, err := tools.Execute(ctx, "grep", string(second))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page2).NotTo(ContainSubstring("more matches"))
+
+			seen := map[string]bool{}
+			for _, page := range []string{page1, page2} {
+				for _, line := range strings.Split(page, "\n") {
+					if strings.Contains(line, ":1:needle") {
+						Expect(seen).NotTo(HaveKey(line))
+						seen[line] = true
+					}
+				}
+			}
+			Expect(seen).To(HaveLen(60))
+		})
+
+		It("uses a configured marker", func() {
+			writeFiles(101, "x\n")
+			args, _ := json.Marshal(map[string]any{"pattern": "*.txt"})
+
+			res, err := tools.WithTruncationMarker("<<TRUNCATED>>").ExecuteResult(ctx, "glob", string(args))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Truncated).To(BeTrue())
+			Expect(res.Output).To(ContainSubstring("<<TRUNCATED>> [Showing 100 of 101+ matches."))
+		})
+	})
+
 	Describe("Bash Tool", func() {
 		Describe("Allowed Commands", func() {
 			It("executes ls command", func() {
@@ -274,6 +506,17 @@ var _ = Describe("ExploreTools", func() {
 				Expect(result).NotTo(BeEmpty())
 			})
 
+			It("allows git log with safe flags", func() {
+				args, _ := json.Marshal(map[string]any{
+					"command": "git log --oneline -1 2>/dev/null || echo 'not a git repo'",
+				})
+
+				result, err := tools.Execute(ctx, "bash", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).NotTo(ContainSubstring("Command blocked"))
+			})
+
 			It("executes tree command", func() {
 				args, _ := json.Marshal(map[string]any{
 					"command": "tree -L 1 2>/dev/null || ls",
@@ -343,6 +586,103 @@ var _ = Describe("ExploreTools", func() {
 				Expect(result).To(ContainSubstring("Command blocked"))
 				Expect(result).To(ContainSubstring("not in allowed list"))
 			})
+
+			DescribeTable("blocks git options that run commands or write files",
+				func(command string) {
+					args, _ := json.Marshal(map[string]any{
+						"command": command,
+					})
+
+					result, err := tools.Execute(ctx, "bash", string(args))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(ContainSubstring("Command blocked"))
+					Expect(result).To(ContainSubstring("git option"))
+				},
+				Entry("exec format atom", "git log --format=%(exec:id)"),
+				Entry("external diff driver", "git diff --ext-diff"),
+				Entry("config override", "git log -c core.pager=less"),
+				Entry("attached config override", "git show -ccore.pager=less HEAD"),
+				Entry("diff output file", "git diff --output=diff.txt"),
+				Entry("exec path", "git log --exec-path=/tmp"),
+				Entry("grep pager", "git grep -Oless main"),
+				Entry("empty quotes inside an option", `git diff --ext""-diff`),
+				Entry("escaped dash", `git diff --ext\-diff`),
+				Entry("quoted option name", `git diff "--output"=diff.txt`),
+				Entry("quoted exec atom", `git log "--format=%(""exec:id)"`),
+				Entry("quoted config value", `git log -c "core.pager=less"`),
+				Entry("abbreviated option", "git grep --open-files=less main"),
+				Entry("git by path", "/usr/bin/git diff --ext-diff"),
+			)
+
+			DescribeTable("blocks shell expansion in git commands",
+				func(command string) {
+					args, _ := json.Marshal(map[string]any{
+						"command": command,
+					})
+
+					result, err := tools.Execute(ctx, "bash", string(args))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(ContainSubstring("Command blocked"))
+					Expect(result).To(ContainSubstring("shell expansion"))
+				},
+				Entry("variable", `git diff --ext$X-diff`),
+				Entry("variable in double quotes", `git diff "--ext${X}-diff"`),
+				Entry("ANSI-C quoting", `git diff $'--ext\x2ddiff'`),
+				Entry("command substitution", `git diff -$(echo -)ext-diff`),
+				Entry("brace expansion", `git diff --{ext-diff,stat}`),
+			)
+
+			DescribeTable("blocks environment changes around git",
+				func(command string) {
+					args, _ := json.Marshal(map[string]any{
+						"command": command,
+					})
+
+					result, err := tools.Execute(ctx, "bash", string(args))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(ContainSubstring("Command blocked"))
+					Expect(result).To(ContainSubstring("changes the environment git runs in"))
+				},
+				Entry("external diff variable", "git log -1 ; GIT_EXTERNAL_DIFF=touch git diff HEAD~1"),
+				Entry("config variables", "git log -1 && GIT_CONFIG_COUNT=1 GIT_CONFIG_KEY_0=diff.external GIT_CONFIG_VALUE_0=touch git diff HEAD~1"),
+				Entry("env wrapper", "env GIT_PAGER=less git log"),
+				Entry("exported variable", "export GIT_EXTERNAL_DIFF=touch; git diff HEAD~1"),
+				Entry("pager", "PAGER=less git log"),
+				Entry("xargs supplies the options", "ls | xargs git diff"),
+			)
+
+			It("still allows searching for git variable names", func() {
+				args, _ := json.Marshal(map[string]any{
+					"command": "git log -1 --oneline; grep -rn GIT_DIR .",
+				})
+
+				result, err := tools.Execute(ctx, "bash", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).NotTo(ContainSubstring("Command blocked"))
+			})
+
+			DescribeTable("allows git options that only share a prefix with a blocked one",
+				func(command string) {
+					args, _ := json.Marshal(map[string]any{
+						"command": command,
+					})
+
+					result, err := tools.Execute(ctx, "bash", string(args))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).NotTo(ContainSubstring("Command blocked"))
+				},
+				Entry("output indicator new", "git log -p --output-indicator-new=+ -1"),
+				Entry("output indicator old", "git diff --output-indicator-old=- HEAD"),
+				Entry("output indicator context", "git show --output-indicator-context=' ' HEAD"),
+				Entry("quoted format", `git log --format="%h %s" -1`),
+				Entry("reflog selector", "git show HEAD@{1}"),
+				Entry("anchored pattern", `git grep -n "main$"`),
+			)
 		})
 
 		Describe("Edge Cases", func() {
@@ -386,6 +726,213 @@ var _ = Describe("ExploreTools", func() {
 				// Should not error, output may be truncated for very large results
 				Expect(result).NotTo(BeEmpty())
 			})
+
+			It("caps output by line count before byte truncation", func() {
+				var sb strings.Builder
+				for i := 1; i <= 500; i++ {
+					fmt.Fprintf(&sb, "%d\n", i)
+				}
+				Expect(os.WriteFile(filepath.Join(tempDir, "history.txt"), []byte(sb.String()), 0o644)).To(Succeed())
+
+				args, _ := json.Marshal(map[string]any{
+					"command": "cat history.txt",
+				})
+
+				result, err := tools.WithBashLineLimit(100).Execute(ctx, "bash", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("[showing first 100 lines]"))
+				Expect(result).To(ContainSubstring("\n100\n"))
+				Expect(result).NotTo(ContainSubstring("\n101\n"))
+				Expect(result).NotTo(ContainSubstring("[Output truncated]"))
+			})
+
+			It("reports both caps when the kept lines are still too large", func() {
+				line := strings.Repeat("x", 199)
+				var sb strings.Builder
+				for i := 1; i <= 500; i++ {
+					fmt.Fprintf(&sb, "%03d %s\n", i, line)
+				}
+				Expect(os.WriteFile(filepath.Join(tempDir, "wide.txt"), []byte(sb.String()), 0o644)).To(Succeed())
+
+				args, _ := json.Marshal(map[string]any{
+					"command": "cat wide.txt",
+				})
+
+				result, err := tools.WithBashLineLimit(100).Execute(ctx, "bash", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("[Output truncated]"))
+				Expect(result).To(ContainSubstring("[showing first 100 lines]"))
+				Expect(result).NotTo(ContainSubstring("\n100 "))
+			})
+
+			It("leaves output under the line cap untouched", func() {
+				args, _ := json.Marshal(map[string]any{
+					"command": "cat README.md",
+				})
+
+				result, err := tools.Execute(ctx, "bash", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).NotTo(ContainSubstring("showing first"))
+			})
+		})
+
+		Describe("Git Safe Mode", func() {
+			It("allows git commands by default", func() {
+				args, _ := json.Marshal(map[string]any{
+					"command": "git status",
+				})
+
+				result, err := tools.Execute(ctx, "bash", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).NotTo(ContainSubstring("Command blocked"))
+			})
+
+			It("blocks git commands when git is disabled", func() {
+				tools.WithGitDisabled(true)
+
+				for _, command := range []string{"git log --oneline -5", "git blame main.go", "git"} {
+					args, _ := json.Marshal(map[string]any{
+						"command": command,
+					})
+
+					result, err := tools.Execute(ctx, "bash", string(args))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(ContainSubstring("Command blocked"))
+					Expect(result).To(ContainSubstring("git is disabled"))
+					Expect(result).NotTo(ContainSubstring("git log/show"))
+				}
+			})
+
+			DescribeTable("blocks git anywhere in a command chain when git is disabled",
+				func(command string) {
+					tools.WithGitDisabled(true)
+
+					args, _ := json.Marshal(map[string]any{
+						"command": command,
+					})
+
+					result, err := tools.Execute(ctx, "bash", string(args))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(ContainSubstring("Command blocked"))
+					Expect(result).To(ContainSubstring("git is disabled"))
+				},
+				Entry("and list", "ls && git log"),
+				Entry("or list", "ls || git log"),
+				Entry("sequence", "cat src/main.go; git log"),
+				Entry("pipe", "ls | git log"),
+				Entry("newline", "ls\ngit log"),
+				Entry("command substitution", "echo $(git log)"),
+				Entry("quoted command substitution", `echo "$(git log)"`),
+				Entry("backticks", "echo `git log`"),
+				Entry("subshell", "ls && (git log)"),
+				Entry("env assignment", "ls; GIT_PAGER=cat git log"),
+				Entry("xargs", "ls | xargs git log"),
+				Entry("quoted name", `ls; "g"it log`),
+			)
+
+			It("allows searching for the word git when git is disabled", func() {
+				tools.WithGitDisabled(true)
+
+				args, _ := json.Marshal(map[string]any{
+					"command": "grep -rn 'git log' src; echo git",
+				})
+				result, err := tools.Execute(ctx, "bash", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).NotTo(ContainSubstring("git is disabled"))
+			})
+
+			It("keeps other commands and updates the tool description", func() {
+				tools.WithGitDisabled(true)
+
+				args, _ := json.Marshal(map[string]any{
+					"command": "ls src",
+				})
+				result, err := tools.Execute(ctx, "bash", string(args))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(ContainSubstring("main.go"))
+
+				for _, def := range tools.Definitions() {
+					if def.Name == "bash" {
+						Expect(def.Description).To(ContainSubstring("Git is not available"))
+					}
+				}
+			})
+		})
+
+		Describe("Operator Config", func() {
+			runBash := func(t *brain.ExploreTools, command string) string {
+				args, _ := json.Marshal(map[string]any{"command": command})
+				result, err := t.Execute(ctx, "bash", string(args))
+				Expect(err).NotTo(HaveOccurred())
+				return result
+			}
+
+			It("blocks go list by default", func() {
+				Expect(runBash(tools, "go list ./...")).To(ContainSubstring("not in allowed list"))
+			})
+
+			It("allows commands an operator adds to the allowlist", func() {
+				cfg, err := brain.ParseExploreToolsConfig("ls,go list,go doc", "", "")
+				Expect(err).NotTo(HaveOccurred())
+				custom := brain.NewExploreTools(tempDir, nil, cfg)
+
+				Expect(runBash(custom, "go list ./...")).NotTo(ContainSubstring("Command blocked"))
+				Expect(runBash(custom, "ls src")).To(ContainSubstring("main.go"))
+
+				result := runBash(custom, "cat src/main.go")
+				Expect(result).To(ContainSubstring("not in allowed list"))
+				Expect(result).To(ContainSubstring("Allowed: ls, go list, go doc"))
+			})
+
+			It("blocks commands an operator adds to the blocklist", func() {
+				custom := brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{
+					BlockedBashPrefixes: []string{"find ", "rm "},
+				})
+
+				Expect(runBash(custom, "find . -name '*.go'")).To(ContainSubstring("'find' not allowed"))
+				Expect(runBash(custom, "ls src")).To(ContainSubstring("main.go"))
+			})
+
+			It("applies the configured timeout", func() {
+				custom := brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{
+					AllowedBashPrefixes: []string{"sleep "},
+					Timeouts:            brain.ToolTimeouts{Bash: 100 * time.Millisecond},
+				})
+
+				Expect(runBash(custom, "sleep 5")).To(Equal("Command timed out after 100ms."))
+			})
+
+			It("times each tool out separately", func() {
+				custom := brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{
+					Timeouts: brain.ToolTimeouts{Grep: time.Nanosecond},
+				})
+
+				args, _ := json.Marshal(map[string]any{"pattern": "func"})
+				result, err := custom.Execute(ctx, "grep", string(args))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal("Search timed out. Use more specific pattern or path."))
+
+				Expect(runBash(custom, "ls src")).To(ContainSubstring("main.go"))
+			})
+
+			It("applies the legacy bash timeout setting to bash and grep", func() {
+				cfg, err := brain.ParseExploreToolsConfig("", "", "30")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfg.Timeouts).To(Equal(brain.ToolTimeouts{Bash: 30 * time.Second, Grep: 30 * time.Second}))
+			})
+
+			It("rejects a non-numeric timeout", func() {
+				_, err := brain.ParseExploreToolsConfig("", "", "10s")
+				Expect(err).To(HaveOccurred())
+			})
 		})
 	})
 
@@ -0,0 +1,96 @@
+package brain
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"basegraph.co/relay/internal/model"
+)
+
+// minRelevanceTermLen drops short words ("the", "and", "go") from overlap scoring.
+const minRelevanceTermLen = 4
+
+// pruneFindings caps findings at limit, keeping the most relevant ones in their
+// original order. Core findings - those whose sources are cited in the focus
+// text (the planner's context summary and the proceed signal) - rank ahead of
+// supporting ones. Supporting findings are ranked by keyword overlap with the
+// focus text, ties going to the most recent. If more than limit findings are
+// core, the oldest core findings are dropped too. limit <= 0 disables the cap.
+// Returns the kept findings and how many were dropped.
+func pruneFindings(findings []model.CodeFinding, limit int, focus string) ([]model.CodeFinding, int) {
+	if limit <= 0 || len(findings) <= limit {
+		return findings, 0
+	}
+
+	focusTerms := relevanceTerms(focus)
+	type ranked struct {
+		idx   int
+		core  bool
+		score int
+	}
+	ranks := make([]ranked, len(findings))
+	for i, f := range findings {
+		r := ranked{idx: i}
+		var text strings.Builder
+		text.WriteString(f.Synthesis)
+		r.core = isCoreFinding(f, focus)
+		for _, src := range f.Sources {
+			text.WriteString(" ")
+			text.WriteString(src.Location)
+		}
+		for term := range relevanceTerms(text.String()) {
+			if focusTerms[term] {
+				r.score++
+			}
+		}
+		ranks[i] = r
+	}
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		if ranks[i].core != ranks[j].core {
+			return ranks[i].core
+		}
+		if !ranks[i].core && ranks[i].score != ranks[j].score {
+			return ranks[i].score > ranks[j].score
+		}
+		return ranks[i].idx > ranks[j].idx // Findings are appended, so later = more recent
+	})
+
+	keep := make([]bool, len(findings))
+	for _, r := range ranks[:limit] {
+		keep[r.idx] = true
+	}
+	kept := make([]model.CodeFinding, 0, limit)
+	for i, f := range findings {
+		if keep[i] {
+			kept = append(kept, f)
+		}
+	}
+	return kept, len(findings) - limit
+}
+
+// isCoreFinding reports whether any of the finding's sources is cited in the
+// focus text.
+func isCoreFinding(f model.CodeFinding, focus string) bool {
+	for _, src := range f.Sources {
+		if p := sourcePath(src.Location); p != "" && strings.Contains(focus, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// relevanceTerms returns the distinct lowercase words in text, splitting
+// identifiers and paths on punctuation.
+func relevanceTerms(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) >= minRelevanceTermLen {
+			terms[w] = true
+		}
+	}
+	return terms
+}
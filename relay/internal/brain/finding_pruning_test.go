@@ -0,0 +1,69 @@
+package brain
+
+import (
+	"testing"
+
+	"basegraph.co/relay/internal/model"
+)
+
+func finding(id, synthesis string, locations ...string) model.CodeFinding {
+	f := model.CodeFinding{ID: id, Synthesis: synthesis}
+	for _, loc := range locations {
+		f.Sources = append(f.Sources, model.CodeSource{Location: loc})
+	}
+	return f
+}
+
+func findingIDs(findings []model.CodeFinding) []string {
+	ids := make([]string, len(findings))
+	for i, f := range findings {
+		ids[i] = f.ID
+	}
+	return ids
+}
+
+func TestPruneFindingsKeepsCoreAndDropsLowRelevance(t *testing.T) {
+	findings := []model.CodeFinding{
+		finding("core", "Webhook handler enqueues deliveries.", "internal/webhook/handler.go:42"),
+		finding("unrelated", "Billing invoices are rendered with templates.", "internal/billing/render.go:10"),
+		finding("retry", "Delivery retries use a fixed backoff in the worker.", "internal/worker/retry.go:7"),
+		finding("logging", "Logger fields are attached per request.", "common/logger/fields.go:3"),
+	}
+	focus := "Add exponential backoff to webhook delivery retries in internal/webhook/handler.go"
+
+	kept, dropped := pruneFindings(findings, 2, focus)
+
+	if dropped != 2 {
+		t.Errorf("dropped = %d, want 2", dropped)
+	}
+	got := findingIDs(kept)
+	want := []string{"core", "retry"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("kept = %v, want %v", got, want)
+	}
+}
+
+func TestPruneFindingsPrefersRecentOnTies(t *testing.T) {
+	findings := []model.CodeFinding{
+		finding("old", "Unrelated."),
+		finding("middle", "Also unrelated."),
+		finding("new", "Still unrelated."),
+	}
+
+	kept, _ := pruneFindings(findings, 2, "webhook retries")
+
+	if got := findingIDs(kept); len(got) != 2 || got[0] != "middle" || got[1] != "new" {
+		t.Errorf("kept = %v, want [middle new]", got)
+	}
+}
+
+func TestPruneFindingsUnderCap(t *testing.T) {
+	findings := []model.CodeFinding{finding("a", "x"), finding("b", "y")}
+
+	for _, limit := range []int{0, 2, 5} {
+		kept, dropped := pruneFindings(findings, limit, "")
+		if dropped != 0 || len(kept) != 2 {
+			t.Errorf("pruneFindings(limit=%d) = %v, %d dropped; want both kept", limit, findingIDs(kept), dropped)
+		}
+	}
+}
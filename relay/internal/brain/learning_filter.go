@@ -0,0 +1,83 @@
+package brain
+
+import (
+	"sort"
+	"strings"
+
+	"basegraph.co/relay/internal/model"
+)
+
+// learningStopTerms are common words that would otherwise tie a learning to
+// any issue that happens to use them.
+var learningStopTerms = map[string]bool{
+	"about": true, "after": true, "always": true, "before": true, "does": true,
+	"each": true, "every": true, "first": true, "from": true, "have": true,
+	"into": true, "just": true, "last": true, "must": true, "never": true,
+	"only": true, "other": true, "should": true, "some": true, "than": true,
+	"that": true, "their": true, "them": true, "then": true, "there": true,
+	"these": true, "they": true, "this": true, "when": true, "where": true,
+	"which": true, "will": true, "with": true, "without": true,
+}
+
+// FilterLearnings keeps the workspace learnings pertinent to issue, most
+// pertinent first, capped at limit (<= 0 = no cap). A learning scores one
+// point per term it shares with the issue's title, labels, keywords and code
+// findings, and one more when that term comes from a label or keyword.
+// Learnings sharing no term are dropped; ties keep their original order.
+func FilterLearnings(issue model.Issue, learnings []model.Learning, limit int) []model.Learning {
+	var text strings.Builder
+	if issue.Title != nil {
+		text.WriteString(*issue.Title)
+	}
+	for _, f := range issue.CodeFindings {
+		text.WriteString(" ")
+		text.WriteString(f.Synthesis)
+		for _, src := range f.Sources {
+			text.WriteString(" ")
+			text.WriteString(src.Location)
+		}
+	}
+	issueTerms := relevanceTerms(text.String())
+
+	tags := append([]string(nil), issue.Labels...)
+	for _, k := range issue.Keywords {
+		tags = append(tags, k.Value)
+	}
+	tagTerms := relevanceTerms(strings.Join(tags, " "))
+
+	type ranked struct {
+		learning model.Learning
+		score    int
+	}
+	var ranks []ranked
+	for _, l := range learnings {
+		score := 0
+		for term := range relevanceTerms(l.Content) {
+			if learningStopTerms[term] {
+				continue
+			}
+			if issueTerms[term] || tagTerms[term] {
+				score++
+			}
+			if tagTerms[term] {
+				score++
+			}
+		}
+		if score > 0 {
+			ranks = append(ranks, ranked{learning: l, score: score})
+		}
+	}
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return ranks[i].score > ranks[j].score
+	})
+	if limit > 0 && len(ranks) > limit {
+		ranks = ranks[:limit]
+	}
+
+	kept := make([]model.Learning, len(ranks))
+	for i, r := range ranks {
+		kept[i] = r.learning
+	}
+	return kept
+}
@@ -0,0 +1,63 @@
+package brain
+
+import (
+	"slices"
+	"testing"
+
+	"basegraph.co/relay/internal/model"
+)
+
+func learningIDs(learnings []model.Learning) []int64 {
+	ids := make([]int64, len(learnings))
+	for i, l := range learnings {
+		ids[i] = l.ID
+	}
+	return ids
+}
+
+func TestFilterLearningsDropsUnrelated(t *testing.T) {
+	title := "Retry failed webhook deliveries"
+	issue := model.Issue{
+		Title:    &title,
+		Labels:   []string{"webhooks"},
+		Keywords: []model.Keyword{{Value: "backoff"}},
+		CodeFindings: []model.CodeFinding{
+			finding("f1", "The delivery worker gives up after the first failure.", "internal/webhook/worker.go:42"),
+		},
+	}
+	learnings := []model.Learning{
+		{ID: 1, Type: model.LearningTypeDomainLearnings, Content: "Invoices are finalized on the first of the month."},
+		{ID: 2, Type: model.LearningTypeCodeLearnings, Content: "Webhook deliveries must use exponential backoff, never fixed delays."},
+		{ID: 3, Type: model.LearningTypeCodeLearnings, Content: "Frontend components live under apps/dashboard."},
+		{ID: 4, Type: model.LearningTypeCodeLearnings, Content: "The worker logs every delivery attempt."},
+	}
+
+	got := learningIDs(FilterLearnings(issue, learnings, 0))
+	if want := []int64{2, 4}; !slices.Equal(got, want) {
+		t.Errorf("kept %v, want %v", got, want)
+	}
+}
+
+func TestFilterLearningsCapsMostPertinentFirst(t *testing.T) {
+	issue := model.Issue{Labels: []string{"billing", "invoices"}}
+	learnings := []model.Learning{
+		{ID: 1, Content: "Billing runs nightly."},
+		{ID: 2, Content: "Billing invoices are immutable once sent."},
+		{ID: 3, Content: "Invoices carry the customer's currency."},
+	}
+
+	got := learningIDs(FilterLearnings(issue, learnings, 2))
+	if want := []int64{2, 1}; !slices.Equal(got, want) {
+		t.Errorf("kept %v, want %v", got, want)
+	}
+}
+
+func TestFilterLearningsNoOverlap(t *testing.T) {
+	title := "Add dark mode"
+	issue := model.Issue{Title: &title}
+	learnings := []model.Learning{{ID: 1, Content: "Webhook deliveries are retried."}}
+
+	if got := FilterLearnings(issue, learnings, 5); len(got) != 0 {
+		t.Errorf("kept %v, want none", learningIDs(got))
+	}
+}
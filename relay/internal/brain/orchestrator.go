@@ -25,6 +25,11 @@ var ErrIssueNotFound = errors.New("issue not found")
 
 const maxValidationRetries = 2 // Allow 2 retries after initial attempt (3 total)
 
+// issueChangePollInterval is how often a running planner cycle checks its
+// issue for new events. New events cut the cycle's explorations short, since
+// the next cycle re-plans with them anyway.
+const issueChangePollInterval = 5 * time.Second
+
 type EngagementInput struct {
 	IssueID         int64
 	EventLogID      int64
@@ -58,6 +63,9 @@ type OrchestratorConfig struct {
 	ModulePath string
 	DebugDir   string // Base directory for debug logs (empty = no logging)
 
+	// DebugRetention caps how many debug runs (or bytes) DebugDir keeps.
+	DebugRetention DebugRetention
+
 	// Mock explore mode for A/B testing planner prompts
 	MockExploreEnabled bool            // Enable mock explore mode
 	MockExploreLLM     llm.AgentClient // Cheap LLM for fixture selection (e.g., gpt-4o-mini)
@@ -65,12 +73,83 @@ type OrchestratorConfig struct {
 
 	// Spec generator LLM (required)
 	SpecGeneratorClient llm.AgentClient
+
+	// ExploreConfidenceGating retries low-confidence explore reports once and tags reports with confidence.
+	ExploreConfidenceGating bool
+
+	// ExploreGitDisabled removes git from the explore bash tool (checkouts without .git).
+	ExploreGitDisabled bool
+
+	// ExploreMinToolIterations is how many tool-using iterations the explore
+	// agent needs before its report is accepted. 0 = no floor.
+	ExploreMinToolIterations int
+
+	// ExploreToolConcurrency caps concurrent tool calls per explore turn.
+	// 1 runs them sequentially in call order; 0 uses the default.
+	ExploreToolConcurrency int
+
+	// ExploreRedactPaths masks absolute paths outside the repo in explore tool output.
+	ExploreRedactPaths bool
+
+	// ExploreWildcardMaxCandidates caps the matches of a codegraph *name*
+	// retry before it's reported as too broad (0 = default).
+	ExploreWildcardMaxCandidates int
+
+	// ExploreRawQuery exposes the read-only raw AQL query tool to explore.
+	ExploreRawQuery bool
+
+	// ExploreCompactKeep enables explore context compaction past the soft
+	// token target, keeping this many recent tool results verbatim (0 = off).
+	ExploreCompactKeep int
+
+	// ExploreCache reuses explore reports for the same question at the same
+	// repo commit (nil = no caching).
+	ExploreCache ExploreCache
+
+	// ExploreMetricsSink receives every exploration's metrics (nil = debug
+	// files only).
+	ExploreMetricsSink MetricsSink
+
+	// ExploreTools tunes the explore bash tool's allow/block lists and timeout.
+	ExploreTools ExploreToolsConfig
+
+	// SpecLocateAllowance overrides the spec generator's locate budget (nil = default).
+	SpecLocateAllowance *LocateAllowance
+
+	// SpecTemplate overrides the spec template and the sections specs are
+	// validated against (nil = DefaultSpecTemplate).
+	SpecTemplate *SpecTemplateConfig
+
+	// SpecFrontmatter prepends machine-readable metadata to stored specs.
+	SpecFrontmatter bool
+
+	// SpecMaxFindings caps the code findings in the spec prompt, pruning the
+	// least relevant. 0 keeps all.
+	SpecMaxFindings int
+
+	// SpecMaxLearnings caps the workspace learnings in the spec prompt,
+	// keeping those that overlap the issue most. 0 keeps all.
+	SpecMaxLearnings int
+
+	// SpecMaxContextTokens bounds the spec prompt's context message, leaving
+	// out supporting findings, learnings and related issues to fit. 0 = no
+	// budget.
+	SpecMaxContextTokens int
+
+	// SpecRelatedIssues is how many past issues citing the same files are
+	// referenced in the spec prompt. 0 disables the lookup.
+	SpecRelatedIssues int
+
+	// RepoConventions is a repo-specific snippet (error style, layering rules)
+	// appended to the explore and spec generator system prompts. Empty = none.
+	RepoConventions string
 }
 
-// SetupDebugRunDir creates a new debug run directory under baseDir/YYYY-MM-DD/NNN.
+// SetupDebugRunDir creates a new debug run directory under baseDir/YYYY-MM-DD/NNN
+// and prunes the oldest runs beyond retention.
 // Returns the path to the new run directory, or empty string if baseDir is empty.
 // This is a development feature - remove once product goes live.
-func SetupDebugRunDir(baseDir string) string {
+func SetupDebugRunDir(baseDir string, retention DebugRetention) string {
 	if baseDir == "" {
 		return ""
 	}
@@ -103,6 +182,7 @@ func SetupDebugRunDir(baseDir string) string {
 	}
 
 	slog.Info("debug run directory created", "path", runDir)
+	pruneDebugRuns(baseDir, runDir, retention)
 	return runDir
 }
 
@@ -117,6 +197,7 @@ type Orchestrator struct {
 	gaps            store.GapStore
 	integrations    store.IntegrationStore
 	learnings       store.LearningStore
+	specLocks       store.SpecLockStore
 	eventLogs       store.EventLogStore
 	queueProducer   queue.Producer
 	issueTrackers   map[model.Provider]issue_tracker.IssueTrackerService
@@ -135,12 +216,24 @@ func NewOrchestrator(
 	integrations store.IntegrationStore,
 	configs store.IntegrationConfigStore,
 	learnings store.LearningStore,
+	specLocks store.SpecLockStore,
 	issueTrackers map[model.Provider]issue_tracker.IssueTrackerService,
 ) *Orchestrator {
-	debugDir := SetupDebugRunDir(cfg.DebugDir)
-
-	tools := NewExploreTools(cfg.RepoRoot, arango)
-	explore := NewExploreAgent(exploreClient, tools, cfg.ModulePath, debugDir)
+	debugDir := SetupDebugRunDir(cfg.DebugDir, cfg.DebugRetention)
+
+	tools := NewExploreTools(cfg.RepoRoot, arango, cfg.ExploreTools).
+		WithGitDisabled(cfg.ExploreGitDisabled).
+		WithPathRedaction(cfg.ExploreRedactPaths).
+		WithRawQuery(cfg.ExploreRawQuery).
+		WithWildcardMaxCandidates(cfg.ExploreWildcardMaxCandidates)
+	explore := NewExploreAgent(exploreClient, tools, cfg.ModulePath, debugDir).
+		WithConfidenceGating(cfg.ExploreConfidenceGating).
+		WithRepoConventions(cfg.RepoConventions).
+		WithToolConcurrency(cfg.ExploreToolConcurrency).
+		WithMinToolIterations(cfg.ExploreMinToolIterations).
+		WithCache(cfg.ExploreCache).
+		WithMetricsSink(cfg.ExploreMetricsSink).
+		WithCompaction(cfg.ExploreCompactKeep)
 
 	// Enable mock explore mode if configured (for A/B testing planner prompts)
 	if cfg.MockExploreEnabled && cfg.MockExploreLLM != nil && cfg.MockFixtureFile != "" {
@@ -154,7 +247,18 @@ func NewOrchestrator(
 	validator := NewActionValidator(gaps)
 
 	// Create spec generator (required)
-	specGen := NewSpecGenerator(cfg.SpecGeneratorClient, explore, debugDir)
+	specGen := NewSpecGenerator(cfg.SpecGeneratorClient, explore, debugDir).
+		WithRepoConventions(cfg.RepoConventions).
+		WithFrontmatter(cfg.SpecFrontmatter).
+		WithMaxFindings(cfg.SpecMaxFindings).
+		WithMaxLearnings(cfg.SpecMaxLearnings).
+		WithMaxContextTokens(cfg.SpecMaxContextTokens)
+	if cfg.SpecLocateAllowance != nil {
+		specGen = specGen.WithLocateAllowance(*cfg.SpecLocateAllowance)
+	}
+	if cfg.SpecTemplate != nil {
+		specGen = specGen.WithTemplate(*cfg.SpecTemplate)
+	}
 	slog.InfoContext(context.Background(), "spec generator enabled",
 		"model", cfg.SpecGeneratorClient.Model())
 
@@ -173,6 +277,7 @@ func NewOrchestrator(
 		gaps:            gaps,
 		integrations:    integrations,
 		learnings:       learnings,
+		specLocks:       specLocks,
 		eventLogs:       eventLogs,
 		queueProducer:   queueProducer,
 		issueTrackers:   issueTrackers,
@@ -252,7 +357,7 @@ func (o *Orchestrator) HandleEngagement(ctx context.Context, input EngagementInp
 			EventType:       followUpEventType,
 			Attempt:         1,
 			TriggerThreadID: input.TriggerThreadID,
-		}); err != nil {
+		}, queue.WithIdempotencyKey(fmt.Sprintf("issue_follow_up:%d", followUpEventID))); err != nil {
 			slog.ErrorContext(ctx, "failed to enqueue follow-up event", "error", err)
 			if resetErr := o.issues.ResetQueuedToIdle(ctx, input.IssueID); resetErr != nil {
 				slog.ErrorContext(ctx, "failed to reset queued issue after enqueue failure", "error", resetErr)
@@ -279,8 +384,23 @@ func (o *Orchestrator) HandleEngagement(ctx context.Context, input EngagementInp
 			slog.WarnContext(ctx, "failed to list pending events", "error", err)
 		}
 
-		// Run planner cycle (5-60s for LLM + actions)
-		if err := o.runPlannerCycle(ctx, issue, input.TriggerThreadID); err != nil {
+		// Run planner cycle (5-60s for LLM + actions), aborting its
+		// explorations if the issue changes meanwhile.
+		cycleCtx, stopWatch := context.WithCancel(ctx)
+		if err == nil {
+			seen := make(map[int64]bool, len(pendingEvents))
+			for _, e := range pendingEvents {
+				seen[e.ID] = true
+			}
+			issueID := issue.ID
+			changed := watchNewEvents(cycleCtx, issueChangePollInterval, seen, func(ctx context.Context) ([]model.EventLog, error) {
+				return o.eventLogs.ListUnprocessedByIssue(ctx, issueID)
+			})
+			cycleCtx = WithExploreAbort(cycleCtx, changed)
+		}
+		err = o.runPlannerCycle(cycleCtx, issue, input.TriggerThreadID)
+		stopWatch()
+		if err != nil {
 			return err
 		}
 
@@ -333,6 +453,41 @@ func (o *Orchestrator) HandleEngagement(ctx context.Context, input EngagementInp
 	return nil
 }
 
+// watchNewEvents polls list every interval and closes the returned channel
+// once it reports an event not in seen, i.e. one that arrived after the
+// planner cycle started. It stops when ctx is done.
+func watchNewEvents(ctx context.Context, interval time.Duration, seen map[int64]bool, list func(context.Context) ([]model.EventLog, error)) <-chan struct{} {
+	changed := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			events, err := list(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.WarnContext(ctx, "failed to check issue for new events", "error", err)
+				}
+				continue
+			}
+			for _, e := range events {
+				if !seen[e.ID] {
+					slog.InfoContext(ctx, "issue changed during planner cycle, cutting explorations short",
+						"event_log_id", e.ID)
+					close(changed)
+					return
+				}
+			}
+		}
+	}()
+	return changed
+}
+
 // runPlannerCycle runs a single planner iteration: build context → plan → validate → execute.
 // If validation fails, the error is sent back to the Planner as a tool result, giving the model
 // a chance to fix the issue (up to maxValidationRetries attempts).
@@ -407,7 +562,7 @@ func (o *Orchestrator) runPlannerCycle(ctx context.Context, issue *model.Issue,
 		return NewFatalError(fmt.Errorf("no issue tracker for provider: %s", issue.Provider))
 	}
 
-	executor := NewActionExecutor(tracker, o.txRunner, o.issues, o.gaps, o.integrations, o.learnings, o.specGenerator)
+	executor := NewActionExecutor(tracker, o.txRunner, o.issues, o.gaps, o.integrations, o.learnings, o.specLocks, o.specGenerator, o.cfg.SpecRelatedIssues)
 	errs := executor.ExecuteBatch(ctx, *issue, output.Actions)
 	if len(errs) > 0 {
 		for _, e := range errs {
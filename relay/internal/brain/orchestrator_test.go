@@ -0,0 +1,50 @@
+package brain
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"basegraph.co/relay/internal/model"
+)
+
+func TestWatchNewEventsClosesOnUnseenEvent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var polls atomic.Int32
+	list := func(context.Context) ([]model.EventLog, error) {
+		if polls.Add(1) < 3 {
+			return []model.EventLog{{ID: 1}}, nil
+		}
+		return []model.EventLog{{ID: 1}, {ID: 2}}, nil
+	}
+
+	changed := watchNewEvents(ctx, time.Millisecond, map[int64]bool{1: true}, list)
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after an unseen event")
+	}
+	if n := polls.Load(); n != 3 {
+		t.Errorf("polls = %d, want 3", n)
+	}
+}
+
+func TestWatchNewEventsStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	list := func(context.Context) ([]model.EventLog, error) {
+		return []model.EventLog{{ID: 1}}, nil
+	}
+	changed := watchNewEvents(ctx, time.Millisecond, map[int64]bool{1: true}, list)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-changed:
+		t.Fatal("channel closed without a new event")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
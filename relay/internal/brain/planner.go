@@ -357,6 +357,7 @@ func (p *Planner) collectActionMetrics(actions []Action, metrics *PlannerMetrics
 	for _, action := range actions {
 		metrics.ActionCounts[string(action.Type)]++
 
+		//exhaustive:ignore // Other actions only count toward ActionCounts
 		switch action.Type {
 		case ActionTypeUpdateGaps:
 			data, err := ParseActionData[UpdateGapsAction](action)
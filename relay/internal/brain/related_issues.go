@@ -0,0 +1,77 @@
+package brain
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"basegraph.co/relay/internal/model"
+)
+
+// relatedIssueCandidates caps how many recent issues are scanned for overlap.
+const relatedIssueCandidates = 200
+
+// RelatedIssue is a past issue whose code findings cite files this issue touches.
+type RelatedIssue struct {
+	ExternalID string
+	Title      string
+	URL        string
+	Files      []string // Overlapping file paths, sorted
+}
+
+// findingPaths returns the distinct file paths cited by findings' sources.
+func findingPaths(findings []model.CodeFinding) map[string]bool {
+	paths := make(map[string]bool)
+	for _, f := range findings {
+		for _, src := range f.Sources {
+			if p := sourcePath(src.Location); p != "" {
+				paths[p] = true
+			}
+		}
+	}
+	return paths
+}
+
+// sourcePath strips the line suffix from a location like "internal/x.go:42".
+func sourcePath(location string) string {
+	p, _, _ := strings.Cut(strings.TrimSpace(location), ":")
+	if p == "" {
+		return ""
+	}
+	return path.Clean(p)
+}
+
+// selectRelatedIssues keeps candidates citing any of paths, most overlap first.
+// Candidates arrive newest first, so ties keep recency order.
+func selectRelatedIssues(paths map[string]bool, candidates []model.Issue, limit int) []RelatedIssue {
+	var related []RelatedIssue
+	for _, c := range candidates {
+		var files []string
+		for p := range findingPaths(c.CodeFindings) {
+			if paths[p] {
+				files = append(files, p)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		sort.Strings(files)
+
+		r := RelatedIssue{ExternalID: c.ExternalIssueID, Files: files}
+		if c.Title != nil {
+			r.Title = *c.Title
+		}
+		if c.ExternalIssueURL != nil {
+			r.URL = *c.ExternalIssueURL
+		}
+		related = append(related, r)
+	}
+
+	sort.SliceStable(related, func(i, j int) bool {
+		return len(related[i].Files) > len(related[j].Files)
+	})
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related
+}
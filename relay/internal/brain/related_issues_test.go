@@ -0,0 +1,94 @@
+package brain
+
+import (
+	"context"
+	"testing"
+
+	"basegraph.co/relay/internal/model"
+	"basegraph.co/relay/internal/store"
+)
+
+// seededIssueStore serves ListWithFindings from a fixed slice.
+type seededIssueStore struct {
+	store.IssueStore
+	issues []model.Issue
+}
+
+func (s *seededIssueStore) ListWithFindings(_ context.Context, integrationID, excludeID int64, limit int32) ([]model.Issue, error) {
+	var out []model.Issue
+	for _, i := range s.issues {
+		if i.IntegrationID == integrationID && i.ID != excludeID && len(i.CodeFindings) > 0 {
+			out = append(out, i)
+		}
+	}
+	if len(out) > int(limit) {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func issueCiting(id int64, title string, locations ...string) model.Issue {
+	sources := make([]model.CodeSource, len(locations))
+	for i, loc := range locations {
+		sources[i] = model.CodeSource{Location: loc}
+	}
+	return model.Issue{
+		ID:              id,
+		IntegrationID:   1,
+		ExternalIssueID: title,
+		Title:           &title,
+		CodeFindings:    []model.CodeFinding{{Synthesis: title, Sources: sources}},
+	}
+}
+
+func TestFindRelatedIssues(t *testing.T) {
+	current := issueCiting(1, "current", "internal/webhook/worker.go:42", "internal/webhook/store.go:10")
+	issues := &seededIssueStore{issues: []model.Issue{
+		current,
+		issueCiting(2, "one-overlap", "internal/webhook/worker.go:7"),
+		issueCiting(3, "unrelated", "internal/billing/invoice.go:3"),
+		issueCiting(4, "two-overlaps", "internal/webhook/store.go:1", "internal/webhook/worker.go:99"),
+	}}
+
+	e := &actionExecutor{issues: issues, relatedIssueLimit: 5}
+	related := e.findRelatedIssues(context.Background(), current)
+
+	if len(related) != 2 {
+		t.Fatalf("got %d related issues, want 2: %+v", len(related), related)
+	}
+	if related[0].Title != "two-overlaps" || len(related[0].Files) != 2 {
+		t.Errorf("first related = %+v, want two-overlaps citing 2 files", related[0])
+	}
+	if related[1].Title != "one-overlap" || related[1].Files[0] != "internal/webhook/worker.go" {
+		t.Errorf("second related = %+v, want one-overlap citing worker.go", related[1])
+	}
+	for _, r := range related {
+		if r.Title == "unrelated" || r.Title == "current" {
+			t.Errorf("unexpected related issue %q", r.Title)
+		}
+	}
+}
+
+func TestFindRelatedIssuesDisabled(t *testing.T) {
+	current := issueCiting(1, "current", "a.go:1")
+	issues := &seededIssueStore{issues: []model.Issue{current, issueCiting(2, "other", "a.go:2")}}
+
+	e := &actionExecutor{issues: issues}
+	if related := e.findRelatedIssues(context.Background(), current); related != nil {
+		t.Errorf("lookup ran with limit 0: %+v", related)
+	}
+}
+
+func TestSourcePath(t *testing.T) {
+	tests := map[string]string{
+		"internal/x.go:42":    "internal/x.go",
+		"internal/x.go:10-20": "internal/x.go",
+		"./internal/x.go":     "internal/x.go",
+		"":                    "",
+	}
+	for in, want := range tests {
+		if got := sourcePath(in); got != want {
+			t.Errorf("sourcePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package brain
+
+import (
+	"regexp"
+	"strings"
+
+	"basegraph.co/relay/internal/model"
+)
+
+var (
+	// reportSnippetHeader matches the first line of a report code block,
+	// "// path/file.go:42-58 - What this code does".
+	reportSnippetHeader = regexp.MustCompile(`^\s*(?://|#|--)\s*(\S+?):(\d+(?:-\d+)?)\b\s*(?:[-—:]\s*(.*))?$`)
+	reportFenceOpen     = regexp.MustCompile("^\\s*(~~~|```)")
+)
+
+// reportSnippet is a fenced code block from an explore report.
+type reportSnippet struct {
+	path        string
+	lines       string
+	description string
+	code        string // Without the header line
+	used        bool
+}
+
+// ExtractFindings turns an explore report into code findings without another
+// LLM pass. Each "Files Reference" row becomes a finding whose synthesis is
+// the row's purpose, with the code snippets for that file as sources. Snippets
+// for files missing from the table become findings of their own. Reports
+// without either section yield no findings. IDs are left for the caller to
+// assign.
+func ExtractFindings(report string) []model.CodeFinding {
+	snippets := parseReportSnippets(report)
+
+	var findings []model.CodeFinding
+	for _, row := range parseFilesReference(report) {
+		path, lines, purpose := row[0], row[1], row[2]
+		finding := model.CodeFinding{Synthesis: purpose}
+		for i := range snippets {
+			s := &snippets[i]
+			if !reportPathsMatch(path, s.path) {
+				continue
+			}
+			s.used = true
+			finding.Sources = append(finding.Sources, model.CodeSource{Location: s.path + ":" + s.lines, Snippet: s.code})
+		}
+		if len(finding.Sources) == 0 {
+			location := path
+			if lines != "" {
+				location += ":" + lines
+			}
+			finding.Sources = []model.CodeSource{{Location: location}}
+		}
+		if finding.Synthesis == "" {
+			finding.Synthesis = "See " + finding.Sources[0].Location
+		}
+		findings = append(findings, finding)
+	}
+
+	for _, s := range snippets {
+		if s.used {
+			continue
+		}
+		synthesis := s.description
+		if synthesis == "" {
+			synthesis = "Code at " + s.path + ":" + s.lines
+		}
+		findings = append(findings, model.CodeFinding{
+			Synthesis: synthesis,
+			Sources:   []model.CodeSource{{Location: s.path + ":" + s.lines, Snippet: s.code}},
+		})
+	}
+
+	return findings
+}
+
+// parseFilesReference returns the (file, lines, purpose) rows of the report's
+// "## Files Reference" table. Tables without a Lines column get empty lines.
+func parseFilesReference(report string) [][3]string {
+	section := reportSection(report, "Files Reference")
+	if section == "" {
+		return nil
+	}
+
+	var rows [][3]string
+	var header []string
+	for _, line := range strings.Split(section, "\n") {
+		cells := tableCells(line)
+		if cells == nil {
+			if header != nil {
+				break // Table ended
+			}
+			continue
+		}
+		if header == nil {
+			header = cells
+			continue
+		}
+		if isTableDivider(cells) {
+			continue
+		}
+
+		var row [3]string
+		for i, cell := range cells {
+			if i >= len(header) {
+				break
+			}
+			switch strings.ToLower(header[i]) {
+			case "file", "path":
+				row[0] = strings.Trim(cell, "`")
+			case "lines", "line":
+				row[1] = cell
+			case "purpose", "description", "role":
+				row[2] = cell
+			}
+		}
+		if row[0] != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// parseReportSnippets returns the fenced code blocks whose first line names
+// a file:line location. Blocks without one can't be grounded and are skipped.
+func parseReportSnippets(report string) []reportSnippet {
+	var snippets []reportSnippet
+	lines := strings.Split(report, "\n")
+	for i := 0; i < len(lines); i++ {
+		m := reportFenceOpen.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		fence := m[1]
+		end := i + 1
+		for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), fence) {
+			end++
+		}
+		body := lines[i+1 : min(end, len(lines))]
+		i = end
+
+		if len(body) == 0 {
+			continue
+		}
+		h := reportSnippetHeader.FindStringSubmatch(body[0])
+		if h == nil {
+			continue
+		}
+		snippets = append(snippets, reportSnippet{
+			path:        h[1],
+			lines:       h[2],
+			description: strings.TrimSpace(h[3]),
+			code:        strings.TrimSpace(strings.Join(body[1:], "\n")),
+		})
+	}
+	return snippets
+}
+
+// reportSection returns the body of the "## <title>" section, up to the next
+// level-2 heading.
+func reportSection(report, title string) string {
+	lines := strings.Split(report, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "## ") || !strings.EqualFold(strings.TrimSpace(line[3:]), title) {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !strings.HasPrefix(lines[end], "## ") {
+			end++
+		}
+		return strings.Join(lines[i+1:end], "\n")
+	}
+	return ""
+}
+
+// tableCells splits a markdown table row, or returns nil for other lines.
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "|") {
+		return nil
+	}
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+func isTableDivider(cells []string) bool {
+	for _, c := range cells {
+		if strings.Trim(c, "-: ") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// reportPathsMatch reports whether two report paths name the same file;
+// snippets often use a shorter suffix of the table's path.
+func reportPathsMatch(a, b string) bool {
+	if a == b {
+		return true
+	}
+	return strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+}
@@ -0,0 +1,86 @@
+package brain
+
+import (
+	"reflect"
+	"testing"
+
+	"basegraph.co/relay/internal/model"
+)
+
+const sampleExploreReport = `<report>
+## Summary
+Webhooks are verified in the handler and queued for the worker.
+
+## 1. Webhook Intake
+
+The handler checks the token before enqueueing.
+
+**Key Files:**
+| File | Purpose |
+|------|---------|
+| internal/http/handler/webhook/gitlab.go | Intake |
+
+**Code:**
+~~~go
+// internal/http/handler/webhook/gitlab.go:40-44 - Token check
+if token != h.secret {
+	return errUnauthorized
+}
+~~~
+
+~~~go
+// queue.go:12 - Enqueue with trace ID
+q.Push(ctx, msg)
+~~~
+
+~~~text
+no location here
+~~~
+
+## Files Reference
+
+| File | Lines | Purpose |
+|------|-------|---------|
+| ` + "`internal/http/handler/webhook/gitlab.go`" + ` | 40-44 | Verifies the webhook token |
+| internal/store/issue.go | 88-120 | Persists issues |
+
+## Confidence
+high — traced end to end
+</report>`
+
+func TestExtractFindings(t *testing.T) {
+	got := ExtractFindings(sampleExploreReport)
+	want := []model.CodeFinding{
+		{
+			Synthesis: "Verifies the webhook token",
+			Sources: []model.CodeSource{{
+				Location: "internal/http/handler/webhook/gitlab.go:40-44",
+				Snippet:  "if token != h.secret {\n\treturn errUnauthorized\n}",
+			}},
+		},
+		{
+			Synthesis: "Persists issues",
+			Sources:   []model.CodeSource{{Location: "internal/store/issue.go:88-120"}},
+		},
+		{
+			Synthesis: "Enqueue with trace ID",
+			Sources:   []model.CodeSource{{Location: "queue.go:12", Snippet: "q.Push(ctx, msg)"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractFindings() =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestExtractFindingsMissingSections(t *testing.T) {
+	if got := ExtractFindings("## Summary\nNothing found.\n"); len(got) != 0 {
+		t.Errorf("ExtractFindings(no sections) = %#v, want none", got)
+	}
+
+	// Files Reference without a Lines column, and no snippets
+	report := "## Files Reference\n\n| File | Purpose |\n|---|---|\n| main.go | Entry point |\n"
+	want := []model.CodeFinding{{Synthesis: "Entry point", Sources: []model.CodeSource{{Location: "main.go"}}}}
+	if got := ExtractFindings(report); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractFindings(no lines) = %#v, want %#v", got, want)
+	}
+}
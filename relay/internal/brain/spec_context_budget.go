@@ -0,0 +1,62 @@
+package brain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// specCharsPerToken approximates prompt tokens from length.
+const specCharsPerToken = 4
+
+// fitSpecContext trims input until its context message fits maxTokens.
+// Supporting findings go first, least relevant first (ranked as in
+// pruneFindings), then learnings and related issues from the end of their
+// lists. Core findings, gaps, the issue and the context summary are never
+// dropped, so the message still exceeds maxTokens when they alone do.
+// Returns the trimmed input and a note for the prompt listing what was left
+// out, or "" when everything fit.
+func fitSpecContext(input SpecGeneratorInput, maxTokens int) (SpecGeneratorInput, string) {
+	findings := input.Findings
+	learnings := input.Learnings
+	related := input.RelatedIssues
+
+	note := func() string {
+		var parts []string
+		if n := len(findings) - len(input.Findings); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d supporting code finding(s)", n))
+		}
+		if n := len(learnings) - len(input.Learnings); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d workspace learning(s)", n))
+		}
+		if n := len(related) - len(input.RelatedIssues); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d related past issue(s)", n))
+		}
+		if len(parts) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("**Note**: To fit the context budget, %s were left out. Use locate to check anything you need that isn't covered above.",
+			strings.Join(parts, ", "))
+	}
+	fits := func() bool {
+		return len(specContextMessage(input, note()))/specCharsPerToken <= maxTokens
+	}
+
+	focus := input.ContextSummary + "\n" + input.ProceedSignal
+	core := 0
+	for _, f := range findings {
+		if isCoreFinding(f, focus) {
+			core++
+		}
+	}
+	for len(input.Findings) > core && !fits() {
+		input.Findings, _ = pruneFindings(findings, len(input.Findings)-1, focus)
+	}
+	for len(input.Learnings) > 0 && !fits() {
+		input.Learnings = input.Learnings[:len(input.Learnings)-1]
+	}
+	for len(input.RelatedIssues) > 0 && !fits() {
+		input.RelatedIssues = input.RelatedIssues[:len(input.RelatedIssues)-1]
+	}
+
+	return input, note()
+}
@@ -0,0 +1,52 @@
+package brain
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"basegraph.co/relay/internal/model"
+)
+
+func TestFitSpecContextKeepsCoreFindingsWithinBudget(t *testing.T) {
+	bulk := strings.Repeat("Supporting detail about unrelated code paths. ", 200)
+	input := SpecGeneratorInput{
+		Issue:          model.Issue{ID: 1},
+		ContextSummary: "Retries live in internal/webhook/worker.go.",
+		Findings: []model.CodeFinding{
+			finding("old", bulk, "internal/billing/invoice.go:10"),
+			finding("core", "Worker retries failed deliveries three times.", "internal/webhook/worker.go:42"),
+			finding("new", bulk, "internal/billing/tax.go:7"),
+		},
+		Learnings: []model.Learning{{Type: "domain", Content: bulk}},
+	}
+
+	got, note := fitSpecContext(input, 1000)
+
+	if ids := findingIDs(got.Findings); !slices.Equal(ids, []string{"core"}) {
+		t.Errorf("findings = %v, want [core]", ids)
+	}
+	if len(got.Learnings) != 0 {
+		t.Errorf("learnings = %d, want 0", len(got.Learnings))
+	}
+	if tokens := len(specContextMessage(got, note)) / specCharsPerToken; tokens > 1000 {
+		t.Errorf("context message is %d tokens, want <= 1000", tokens)
+	}
+	want := "2 supporting code finding(s), 1 workspace learning(s) were left out"
+	if !strings.Contains(note, want) || !strings.Contains(specContextMessage(got, note), note) {
+		t.Errorf("note = %q, want it to contain %q and end the message", note, want)
+	}
+}
+
+func TestFitSpecContextLeavesFittingInputAlone(t *testing.T) {
+	input := SpecGeneratorInput{
+		Issue:    model.Issue{ID: 1},
+		Findings: []model.CodeFinding{finding("a", "Small.", "a.go:1"), finding("b", "Small.", "b.go:1")},
+	}
+
+	got, note := fitSpecContext(input, 1000)
+
+	if note != "" || len(got.Findings) != 2 {
+		t.Errorf("fitSpecContext = %v, %q; want input unchanged and no note", findingIDs(got.Findings), note)
+	}
+}
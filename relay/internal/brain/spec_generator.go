@@ -18,7 +18,7 @@ import (
 const (
 	maxSpecIterations        = 30 // Safety limit for spec generation loop
 	maxParallelSpecExplorers = 5  // Parallel exploration during spec generation
-	maxLocateCalls           = 8  // Hard cap on locate calls for spec generation
+	maxLocateCalls           = 8  // Default cap on locate calls for spec generation
 )
 
 // SubmitSpecParams defines the schema for the submit_spec tool.
@@ -34,20 +34,49 @@ type SpecGeneratorInput struct {
 	Gaps           []model.Gap
 	Findings       []model.CodeFinding
 	Learnings      []model.Learning
+	RelatedIssues  []RelatedIssue // Past issues citing the same files (optional)
 	ProceedSignal  string
 }
 
 // SpecGeneratorOutput contains the generated spec.
 type SpecGeneratorOutput struct {
-	Spec string
+	Spec     string
+	Metadata SpecMetadataJSON
+
+	// Frontmatter is a "---" delimited JSON block to prepend to the stored
+	// spec. Empty unless enabled with WithFrontmatter.
+	Frontmatter string
+
+	// Validation lists structural problems found in Spec. The spec is
+	// returned either way; callers decide what to do with them.
+	Validation []SpecValidationIssue
+}
+
+// LocateAllowance bounds how much the spec generator may explore through the
+// locate tool.
+type LocateAllowance struct {
+	MaxCalls     int          // Hard cap on locate calls per spec. 0 disables locate entirely.
+	Thoroughness Thoroughness // Depth of each locate run
+}
+
+// DefaultLocateAllowance is 8 quick locate calls per spec.
+func DefaultLocateAllowance() LocateAllowance {
+	return LocateAllowance{MaxCalls: maxLocateCalls, Thoroughness: ThoroughnessQuick}
 }
 
 // SpecGenerator generates implementation specs from gathered context.
 // It uses ExploreAgent to verify code references and ensure accuracy.
 type SpecGenerator struct {
-	llm      llm.AgentClient
-	explore  *ExploreAgent
-	debugDir string
+	llm              llm.AgentClient
+	explore          *ExploreAgent
+	debugDir         string
+	repoConventions  string // Repo-specific guidance appended to the system prompt
+	locate           LocateAllowance
+	frontmatter      bool // Emit SpecGeneratorOutput.Frontmatter
+	maxFindings      int  // Cap on findings in the prompt (0 = no cap)
+	maxLearnings     int  // Cap on learnings in the prompt, unrelated ones dropped (0 = keep all)
+	maxContextTokens int  // Budget for the context message, see WithMaxContextTokens (0 = no budget)
+	template         SpecTemplateConfig
 }
 
 // NewSpecGenerator creates a SpecGenerator with an ExploreAgent for code verification.
@@ -56,18 +85,103 @@ func NewSpecGenerator(llmClient llm.AgentClient, explore *ExploreAgent, debugDir
 		llm:      llmClient,
 		explore:  explore,
 		debugDir: debugDir,
+		locate:   DefaultLocateAllowance(),
+		template: DefaultSpecTemplate(),
 	}
 }
 
+// WithLocateAllowance overrides the locate call cap and thoroughness.
+// MaxCalls <= 0 removes the locate tool so the model can only submit.
+func (s *SpecGenerator) WithLocateAllowance(allowance LocateAllowance) *SpecGenerator {
+	if allowance.MaxCalls < 0 {
+		allowance.MaxCalls = 0
+	}
+	if allowance.Thoroughness == "" {
+		allowance.Thoroughness = ThoroughnessQuick
+	}
+	s.locate = allowance
+	return s
+}
+
+// WithRepoConventions appends a repo-specific conventions snippet to the
+// system prompt. Empty (the default) leaves the prompt unchanged.
+func (s *SpecGenerator) WithRepoConventions(conventions string) *SpecGenerator {
+	s.repoConventions = conventions
+	return s
+}
+
+// WithTemplate replaces the spec template: the skeleton in the system prompt
+// and the sections generated specs are validated against.
+func (s *SpecGenerator) WithTemplate(tmpl SpecTemplateConfig) *SpecGenerator {
+	s.template = tmpl
+	return s
+}
+
+// WithFrontmatter makes Generate also return machine-readable frontmatter
+// (issue ID, metadata, content hash) for the stored spec. Off by default.
+func (s *SpecGenerator) WithFrontmatter(enabled bool) *SpecGenerator {
+	s.frontmatter = enabled
+	return s
+}
+
+// WithMaxFindings caps how many code findings go into the prompt. Findings
+// cited by the context summary or proceed signal are kept first; the rest are
+// pruned by keyword overlap with them. 0 (the default) keeps every finding.
+func (s *SpecGenerator) WithMaxFindings(limit int) *SpecGenerator {
+	s.maxFindings = limit
+	return s
+}
+
+// WithMaxContextTokens bounds the context message (issue, gaps, findings,
+// related issues, learnings) at roughly limit tokens. Over budget, supporting
+// findings are left out least relevant first, then learnings and related
+// issues, and the prompt says so. Core findings, gaps, the issue and the
+// context summary are always kept. 0 (the default) sets no budget.
+func (s *SpecGenerator) WithMaxContextTokens(limit int) *SpecGenerator {
+	s.maxContextTokens = limit
+	return s
+}
+
+// WithMaxLearnings filters workspace learnings by overlap with the issue's
+// title, labels, keywords and findings, keeping at most limit of them.
+// Learnings unrelated to the issue are dropped. 0 (the default) keeps every
+// learning.
+func (s *SpecGenerator) WithMaxLearnings(limit int) *SpecGenerator {
+	s.maxLearnings = limit
+	return s
+}
+
+// output builds the result for a finished spec, logging any validation issues.
+func (s *SpecGenerator) output(ctx context.Context, input SpecGeneratorInput, spec string) SpecGeneratorOutput {
+	out := SpecGeneratorOutput{
+		Spec:       spec,
+		Metadata:   extractSpecMetadata(spec),
+		Validation: validateSpec(spec, input.Gaps, s.template),
+	}
+	if s.frontmatter {
+		out.Frontmatter = renderSpecFrontmatter(newSpecFrontmatter(input.Issue.ID, spec, out.Metadata))
+	}
+	for _, issue := range out.Validation {
+		slog.WarnContext(ctx, "spec validation issue",
+			"issue_id", input.Issue.ID,
+			"severity", issue.Severity,
+			"message", logger.Truncate(issue.Message, 200))
+	}
+	return out
+}
+
 // Generate creates an implementation spec from the gathered context.
 // Returns the spec markdown and confidence assessment.
-func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput) (SpecGeneratorOutput, error) {
+func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput) (_ SpecGeneratorOutput, err error) {
 	start := time.Now()
 
 	ctx = logger.WithLogFields(ctx, logger.LogFields{
 		Component: "relay.brain.spec_generator",
 	})
 
+	tr, ctx := startSpecTrace(ctx, input.Issue.ID)
+	defer func() { tr.finish(ctx, err) }()
+
 	sessionID := time.Now().Format("20060102-150405")
 	var debugLog strings.Builder
 	debugLog.WriteString(fmt.Sprintf("=== SPEC GENERATOR SESSION %s ===\n", sessionID))
@@ -81,7 +195,42 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 		"gaps", len(input.Gaps),
 		"findings", len(input.Findings))
 
-	messages := s.buildMessages(input)
+	if pruned, dropped := pruneFindings(input.Findings, s.maxFindings, input.ContextSummary+"\n"+input.ProceedSignal); dropped > 0 {
+		slog.InfoContext(ctx, "pruned low-relevance findings from spec prompt",
+			"issue_id", input.Issue.ID,
+			"kept", len(pruned),
+			"dropped", dropped)
+		debugLog.WriteString(fmt.Sprintf("Pruned %d finding(s), kept %d\n\n", dropped, len(pruned)))
+		input.Findings = pruned
+	}
+
+	if s.maxLearnings > 0 && len(input.Learnings) > 0 {
+		filtered := FilterLearnings(input.Issue, input.Learnings, s.maxLearnings)
+		if dropped := len(input.Learnings) - len(filtered); dropped > 0 {
+			slog.InfoContext(ctx, "filtered unrelated learnings from spec prompt",
+				"issue_id", input.Issue.ID,
+				"kept", len(filtered),
+				"dropped", dropped)
+			debugLog.WriteString(fmt.Sprintf("Filtered %d learning(s), kept %d\n\n", dropped, len(filtered)))
+		}
+		input.Learnings = filtered
+	}
+
+	note := ""
+	if s.maxContextTokens > 0 {
+		input, note = fitSpecContext(input, s.maxContextTokens)
+		if note != "" {
+			slog.InfoContext(ctx, "trimmed spec prompt context to fit token budget",
+				"issue_id", input.Issue.ID,
+				"max_tokens", s.maxContextTokens,
+				"findings", len(input.Findings),
+				"learnings", len(input.Learnings),
+				"related_issues", len(input.RelatedIssues))
+			debugLog.WriteString(note + "\n\n")
+		}
+	}
+
+	messages := s.buildMessages(input, note)
 
 	iterations := 0
 	totalPromptTokens := 0
@@ -94,7 +243,10 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 			"iterations", iterations,
 			"total_prompt_tokens", totalPromptTokens,
 			"total_completion_tokens", totalCompletionTokens,
-			"locate_calls", locateCallCount)
+			"locate_calls", locateCallCount,
+			"locate_limit", s.locate.MaxCalls)
+		debugLog.WriteString(fmt.Sprintf("Locate usage: %d/%d calls (thoroughness: %s)\n",
+			locateCallCount, s.locate.MaxCalls, s.locate.Thoroughness))
 		s.writeDebugLog(sessionID, debugLog.String())
 	}()
 
@@ -108,8 +260,9 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 		}
 
 		slog.DebugContext(ctx, "spec generator iteration", "iteration", iterations)
+		attemptCtx := tr.startAttempt(ctx)
 
-		resp, err := s.llm.ChatWithTools(ctx, llm.AgentRequest{
+		resp, err := s.llm.ChatWithTools(attemptCtx, llm.AgentRequest{
 			Messages: messages,
 			Tools:    s.tools(),
 		})
@@ -120,6 +273,7 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 
 		totalPromptTokens += resp.PromptTokens
 		totalCompletionTokens += resp.CompletionTokens
+		tr.recordResponse(attemptCtx, resp)
 
 		debugLog.WriteString(fmt.Sprintf("--- ITERATION %d ---\n", iterations))
 		debugLog.WriteString(fmt.Sprintf("[ASSISTANT] (prompt=%d, completion=%d)\n%s\n\n",
@@ -130,6 +284,7 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 			if tc.Name == "submit_spec" {
 				params, err := llm.ParseToolArguments[SubmitSpecParams](tc.Arguments)
 				if err != nil {
+					tr.validationFailure(attemptCtx, "invalid_submit_spec")
 					s.writeDebugLog(sessionID, debugLog.String())
 					return SpecGeneratorOutput{}, fmt.Errorf("parsing submit_spec: %w", err)
 				}
@@ -143,9 +298,8 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 					"spec_length", len(params.Spec),
 					"duration_ms", time.Since(start).Milliseconds())
 
-				return SpecGeneratorOutput{
-					Spec: params.Spec,
-				}, nil
+				tr.outcome = specOutcomeSubmitted
+				return s.output(ctx, input, params.Spec), nil
 			}
 		}
 
@@ -157,11 +311,11 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 
 			slog.WarnContext(ctx, "spec generator completed without submit_spec",
 				"iterations", iterations)
+			tr.validationFailure(attemptCtx, "missing_submit_spec")
 
 			// Treat the content as the spec
-			return SpecGeneratorOutput{
-				Spec: resp.Content,
-			}, nil
+			tr.outcome = specOutcomeUnsubmitted
+			return s.output(ctx, input, resp.Content), nil
 		}
 
 		// Log tool calls
@@ -186,14 +340,14 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 		}
 
 		// Check hard limit BEFORE executing
-		if locateCallCount+batchLocateCalls > maxLocateCalls {
+		if locateCallCount+batchLocateCalls > s.locate.MaxCalls {
 			slog.WarnContext(ctx, "spec generator hit locate limit",
 				"locate_calls", locateCallCount,
 				"batch_calls", batchLocateCalls,
-				"limit", maxLocateCalls)
+				"limit", s.locate.MaxCalls)
 
 			debugLog.WriteString(fmt.Sprintf("\n=== LOCATE LIMIT REACHED (%d/%d) ===\n",
-				locateCallCount+batchLocateCalls, maxLocateCalls))
+				locateCallCount+batchLocateCalls, s.locate.MaxCalls))
 
 			messages = append(messages, llm.Message{
 				Role: "user",
@@ -201,7 +355,7 @@ func (s *SpecGenerator) Generate(ctx context.Context, input SpecGeneratorInput)
 
 You must submit your spec now using submit_spec. No more location calls allowed.
 Trust the findings from the planner and write your spec based on available context.`,
-					locateCallCount+batchLocateCalls, maxLocateCalls),
+					locateCallCount+batchLocateCalls, s.locate.MaxCalls),
 			})
 			continue
 		}
@@ -209,7 +363,7 @@ Trust the findings from the planner and write your spec based on available conte
 		locateCallCount += batchLocateCalls
 
 		// Execute locate calls in parallel
-		results := s.executeExploresParallel(ctx, resp.ToolCalls)
+		results := s.executeExploresParallel(attemptCtx, resp.ToolCalls)
 
 		for _, r := range results {
 			debugLog.WriteString(fmt.Sprintf("[TOOL RESULT] (length: %d)\n", len(r.report)))
@@ -231,12 +385,18 @@ Trust the findings from the planner and write your spec based on available conte
 }
 
 // buildMessages constructs the initial message thread for spec generation.
-func (s *SpecGenerator) buildMessages(input SpecGeneratorInput) []llm.Message {
-	messages := []llm.Message{
-		{Role: "system", Content: specGeneratorSystemPrompt},
+// buildMessages returns the system prompt and the context message, ending
+// with note when it isn't empty.
+func (s *SpecGenerator) buildMessages(input SpecGeneratorInput, note string) []llm.Message {
+	return []llm.Message{
+		{Role: "system", Content: withRepoConventions(specSystemPrompt(s.template), s.repoConventions)},
+		{Role: "user", Content: specContextMessage(input, note)},
 	}
+}
 
-	// Build context message
+// specContextMessage renders everything gathered for the spec: issue, planner
+// summary, resolved gaps, findings, related issues and learnings.
+func specContextMessage(input SpecGeneratorInput, note string) string {
 	var ctx strings.Builder
 
 	// Issue context
@@ -297,6 +457,27 @@ func (s *SpecGenerator) buildMessages(input SpecGeneratorInput) []llm.Message {
 		}
 	}
 
+	// Related past issues
+	if len(input.RelatedIssues) > 0 {
+		ctx.WriteString("# Related Past Issues\n\n")
+		ctx.WriteString("Earlier issues whose findings cite the same files. Check them for prior decisions and regressions to avoid:\n\n")
+		for _, r := range input.RelatedIssues {
+			ctx.WriteString(fmt.Sprintf("- #%s", r.ExternalID))
+			if r.Title != "" {
+				ctx.WriteString(fmt.Sprintf(" %s", r.Title))
+			}
+			if r.URL != "" {
+				ctx.WriteString(fmt.Sprintf(" (%s)", r.URL))
+			}
+			files := make([]string, len(r.Files))
+			for i, f := range r.Files {
+				files[i] = fmt.Sprintf("`%s`", f)
+			}
+			ctx.WriteString(fmt.Sprintf(" — touches %s\n", strings.Join(files, ", ")))
+		}
+		ctx.WriteString("\n")
+	}
+
 	// Learnings
 	if len(input.Learnings) > 0 {
 		ctx.WriteString("# Workspace Learnings\n\n")
@@ -307,12 +488,12 @@ func (s *SpecGenerator) buildMessages(input SpecGeneratorInput) []llm.Message {
 		ctx.WriteString("\n")
 	}
 
-	messages = append(messages, llm.Message{
-		Role:    "user",
-		Content: ctx.String(),
-	})
+	if note != "" {
+		ctx.WriteString(note)
+		ctx.WriteString("\n")
+	}
 
-	return messages
+	return ctx.String()
 }
 
 type specExploreResult struct {
@@ -357,7 +538,7 @@ func (s *SpecGenerator) executeExploresParallel(ctx context.Context, toolCalls [
 				"query", logger.Truncate(params.Query, 100))
 
 			// Use ModeLocate for fast file finding
-			report, err := s.explore.ExploreWithMode(ctx, params.Query, ModeLocate)
+			report, err := s.explore.exploreInternal(ctx, params.Query, s.locate.Thoroughness, ModeLocate)
 			if err != nil {
 				slog.WarnContext(ctx, "spec generator locate failed",
 					"error", err,
@@ -377,21 +558,31 @@ func (s *SpecGenerator) executeExploresParallel(ctx context.Context, toolCalls [
 }
 
 func (s *SpecGenerator) tools() []llm.Tool {
-	return []llm.Tool{
+	if s.locate.MaxCalls <= 0 {
+		return submitOnlyTools()
+	}
+
+	return append([]llm.Tool{
 		{
 			Name: "locate",
-			Description: `Verify file locations before including them in the spec.
+			Description: fmt.Sprintf(`Verify file locations before including them in the spec.
 
-⚠️ LIMITED to 8 calls. Most specs need 0-3.
+⚠️ LIMITED to %d calls. Most specs need 0-3.
 The planner already explored and provided findings. Trust them first.
 
 Only use locate if:
 - A file path in findings seems incorrect
 - You need to verify a location exists
 
-You're verifying locations, not exploring from scratch.`,
+You're verifying locations, not exploring from scratch.`, s.locate.MaxCalls),
 			Parameters: llm.GenerateSchemaFrom(ExploreParams{}),
 		},
+	}, submitOnlyTools()...)
+}
+
+// submitOnlyTools is the tool set when locate is disabled.
+func submitOnlyTools() []llm.Tool {
+	return []llm.Tool{
 		{
 			Name:        "submit_spec",
 			Description: "Submit the final implementation spec. Call this when you've completed the spec with all sections. Include the Confidence Assessment section at the end of your spec.",
@@ -418,7 +609,9 @@ func (s *SpecGenerator) writeDebugLog(sessionID, content string) {
 	}
 }
 
-const specGeneratorSystemPrompt = `You are a senior architect generating an implementation spec.
+// specGeneratorPromptHead and specGeneratorPromptTail wrap the spec template
+// body in the system prompt; see specSystemPrompt.
+const specGeneratorPromptHead = `You are a senior architect generating an implementation spec.
 
 You receive comprehensive context from the planner:
 - A detailed handoff report with decisions, rationale, and code patterns discovered
@@ -448,8 +641,10 @@ This spec will be read by:
 
 Your spec MUST follow this structure:
 
-` + "```" + `markdown
-# Implementation Spec: [Issue Title]
+`
+
+// defaultSpecTemplateBody is the markdown skeleton of DefaultSpecTemplate.
+const defaultSpecTemplateBody = `# Implementation Spec: [Issue Title]
 
 ## Summary
 [2-3 sentences: What we're building and why. Written for PMs.]
@@ -541,7 +736,9 @@ func DoThing(ctx context.Context, input Input) (Output, error) {
 
 **Uncertainties:**
 - [Any areas where you're not 100% sure]
-` + "```" + `
+`
+
+const specGeneratorPromptTail = `
 
 # Guidelines
 
@@ -549,7 +746,7 @@ func DoThing(ctx context.Context, input Input) (Output, error) {
 2. **Trust your context** — Once you start writing, trust the findings and your Phase 1 exploration. Don't second-guess.
 3. **Be specific** — Include actual file paths, function names, and signatures from your gathered context.
 4. **Pseudocode over prose** — For implementation phases, show the logic structure, not paragraphs.
-5. **Trace decisions to gaps** — Each key decision should reference the resolved question that drove it.
+5. **Trace decisions to gaps** — Each Key Decisions row quotes, in its Question column, the resolved gap question that drove it.
 6. **Include the "why"** — Don't just say what to do, explain why this approach was chosen.
 
 # Tools
@@ -0,0 +1,231 @@
+package brain_test
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"basegraph.co/relay/common/llm"
+	"basegraph.co/relay/internal/brain"
+	"basegraph.co/relay/internal/model"
+)
+
+var _ = Describe("SpecGenerator", func() {
+	It("appends repo conventions to the system prompt", func() {
+		client := &scriptedLLM{responses: []llm.AgentResponse{
+			{Content: "# Implementation Spec: Test"},
+		}}
+
+		gen := brain.NewSpecGenerator(client, nil, "").
+			WithRepoConventions("Handlers never talk to the store directly; go through services.")
+		output, err := gen.Generate(context.Background(), brain.SpecGeneratorInput{Issue: model.Issue{ID: 1}})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.Spec).To(Equal("# Implementation Spec: Test"))
+		system := client.requests[0].Messages[0]
+		Expect(system.Role).To(Equal("system"))
+		Expect(system.Content).To(ContainSubstring("# Repository Conventions"))
+		Expect(system.Content).To(ContainSubstring("go through services"))
+	})
+
+	Describe("frontmatter", func() {
+		It("returns frontmatter matching the spec metadata when enabled", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "# Implementation Spec: Test\n\n## Summary\nDo it.\n"},
+			}}
+
+			output, err := brain.NewSpecGenerator(client, nil, "").WithFrontmatter(true).
+				Generate(context.Background(), brain.SpecGeneratorInput{Issue: model.Issue{ID: 7}})
+			Expect(err).NotTo(HaveOccurred())
+
+			fm, body, ok := brain.ParseSpecFrontmatter(output.Frontmatter + output.Spec)
+			Expect(ok).To(BeTrue())
+			Expect(body).To(Equal(output.Spec))
+			Expect(fm.IssueID).To(Equal(int64(7)))
+			Expect(fm.SpecMetadataJSON).To(Equal(output.Metadata))
+			Expect(fm.Sections).To(Equal([]string{"Summary"}))
+		})
+
+		It("is off by default", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "# Implementation Spec: Test"},
+			}}
+
+			output, err := brain.NewSpecGenerator(client, nil, "").
+				Generate(context.Background(), brain.SpecGeneratorInput{Issue: model.Issue{ID: 7}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.Frontmatter).To(BeEmpty())
+		})
+	})
+
+	It("references related past issues in the prompt", func() {
+		client := &scriptedLLM{responses: []llm.AgentResponse{
+			{Content: "# Implementation Spec: Test"},
+		}}
+
+		gen := brain.NewSpecGenerator(client, nil, "")
+		_, err := gen.Generate(context.Background(), brain.SpecGeneratorInput{
+			Issue: model.Issue{ID: 1},
+			RelatedIssues: []brain.RelatedIssue{{
+				ExternalID: "17",
+				Title:      "Webhook retries double-send",
+				Files:      []string{"internal/webhook/worker.go"},
+			}},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.lastUserMessage(0)).To(ContainSubstring("# Related Past Issues"))
+		Expect(client.lastUserMessage(0)).To(ContainSubstring("- #17 Webhook retries double-send — touches `internal/webhook/worker.go`"))
+	})
+
+	Describe("locate allowance", func() {
+		var (
+			tempDir string
+			explore *brain.ExploreAgent
+			locator *scriptedLLM
+		)
+
+		locateCall := func(id string) llm.ToolCall {
+			return llm.ToolCall{ID: id, Name: "locate", Arguments: `{"query":"where is main?"}`}
+		}
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = os.MkdirTemp("", "spec-generator-test-*")
+			Expect(err).NotTo(HaveOccurred())
+
+			locator = &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "main.go:1", PromptTokens: 100},
+				{Content: "High confidence.", PromptTokens: 120},
+			}}
+			explore = brain.NewExploreAgent(locator, brain.NewExploreTools(tempDir, nil, brain.ExploreToolsConfig{}), "example.com/app", "")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tempDir)
+		})
+
+		It("enforces the configured call limit", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{locateCall("l1")}},
+				{ToolCalls: []llm.ToolCall{locateCall("l2")}},
+				{Content: "# Implementation Spec: Test"},
+			}}
+
+			gen := brain.NewSpecGenerator(client, explore, "").
+				WithLocateAllowance(brain.LocateAllowance{MaxCalls: 1})
+			_, err := gen.Generate(context.Background(), brain.SpecGeneratorInput{Issue: model.Issue{ID: 1}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locator.requests).To(HaveLen(2)) // one locate run: report + self-assessment
+			Expect(client.requests).To(HaveLen(3))
+			Expect(client.lastUserMessage(2)).To(ContainSubstring("LOCATE LIMIT REACHED (2/1)"))
+			Expect(client.requests[0].Tools[0].Description).To(ContainSubstring("LIMITED to 1 calls"))
+		})
+
+		It("removes the locate tool when disabled", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "# Implementation Spec: Test"},
+			}}
+
+			gen := brain.NewSpecGenerator(client, explore, "").
+				WithLocateAllowance(brain.LocateAllowance{MaxCalls: 0})
+			_, err := gen.Generate(context.Background(), brain.SpecGeneratorInput{Issue: model.Issue{ID: 1}})
+
+			Expect(err).NotTo(HaveOccurred())
+			tools := client.requests[0].Tools
+			Expect(tools).To(HaveLen(1))
+			Expect(tools[0].Name).To(Equal("submit_spec"))
+		})
+	})
+
+	Describe("telemetry", func() {
+		var recorder *tracetest.SpanRecorder
+
+		BeforeEach(func() {
+			recorder = tracetest.NewSpanRecorder()
+			prev := otel.GetTracerProvider()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			DeferCleanup(otel.SetTracerProvider, prev)
+		})
+
+		spanAttrs := func(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Attributes() {
+				attrs[kv.Key] = kv.Value
+			}
+			return attrs
+		}
+
+		It("records a span per attempt under the generate span", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{ToolCalls: []llm.ToolCall{{ID: "l1", Name: "locate", Arguments: `{"query":"where?"}`}}, PromptTokens: 100, CompletionTokens: 10},
+				{ToolCalls: []llm.ToolCall{{ID: "s1", Name: "submit_spec", Arguments: `{"spec":"# Spec"}`}}, PromptTokens: 150, CompletionTokens: 40},
+			}}
+
+			_, err := brain.NewSpecGenerator(client, nil, "").
+				WithLocateAllowance(brain.LocateAllowance{MaxCalls: 0}).
+				Generate(context.Background(), brain.SpecGeneratorInput{Issue: model.Issue{ID: 9}})
+			Expect(err).NotTo(HaveOccurred())
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(3))
+			first, second, generate := spans[0], spans[1], spans[2]
+			Expect(generate.Name()).To(Equal("brain.spec_generator.generate"))
+			for _, attempt := range []sdktrace.ReadOnlySpan{first, second} {
+				Expect(attempt.Name()).To(Equal("brain.spec_generator.attempt"))
+				Expect(attempt.Parent().SpanID()).To(Equal(generate.SpanContext().SpanID()))
+			}
+
+			Expect(spanAttrs(first)["spec.attempt"].AsInt64()).To(Equal(int64(1)))
+			Expect(spanAttrs(first)["llm.prompt_tokens"].AsInt64()).To(Equal(int64(100)))
+			Expect(spanAttrs(second)["spec.attempt"].AsInt64()).To(Equal(int64(2)))
+			Expect(spanAttrs(second)["llm.completion_tokens"].AsInt64()).To(Equal(int64(40)))
+			Expect(spanAttrs(second)["spec.validation_errors"].AsInt64()).To(BeZero())
+
+			attrs := spanAttrs(generate)
+			Expect(attrs["issue.id"].AsInt64()).To(Equal(int64(9)))
+			Expect(attrs["spec.attempts"].AsInt64()).To(Equal(int64(2)))
+			Expect(attrs["llm.prompt_tokens"].AsInt64()).To(Equal(int64(250)))
+			Expect(attrs["llm.completion_tokens"].AsInt64()).To(Equal(int64(50)))
+			Expect(attrs["spec.outcome"].AsString()).To(Equal("submitted"))
+		})
+
+		It("counts a missing submit_spec as a validation error", func() {
+			client := &scriptedLLM{responses: []llm.AgentResponse{
+				{Content: "# Implementation Spec: Test"},
+			}}
+
+			_, err := brain.NewSpecGenerator(client, nil, "").
+				Generate(context.Background(), brain.SpecGeneratorInput{Issue: model.Issue{ID: 1}})
+			Expect(err).NotTo(HaveOccurred())
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(2))
+			Expect(spanAttrs(spans[0])["spec.validation_errors"].AsInt64()).To(Equal(int64(1)))
+			Expect(spanAttrs(spans[1])["spec.validation_errors"].AsInt64()).To(Equal(int64(1)))
+			Expect(spanAttrs(spans[1])["spec.outcome"].AsString()).To(Equal("unsubmitted"))
+		})
+
+		It("ends all spans and marks the error when the LLM call fails", func() {
+			client := &scriptedLLM{}
+
+			_, err := brain.NewSpecGenerator(client, nil, "").
+				Generate(context.Background(), brain.SpecGeneratorInput{Issue: model.Issue{ID: 1}})
+			Expect(err).To(HaveOccurred())
+
+			Expect(recorder.Started()).To(HaveLen(2))
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(2))
+			Expect(spans[1].Status().Code).To(Equal(codes.Error))
+			Expect(spanAttrs(spans[1])["spec.outcome"].AsString()).To(Equal("error"))
+		})
+	})
+})
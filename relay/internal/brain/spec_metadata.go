@@ -0,0 +1,210 @@
+package brain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Effort buckets for EstimatedEffort.
+const (
+	EffortSmall      = "S"
+	EffortMedium     = "M"
+	EffortLarge      = "L"
+	EffortExtraLarge = "XL"
+)
+
+// SpecMetadataJSON is a structured summary of a generated spec, derived from
+// its markdown so callers don't have to re-parse it.
+type SpecMetadataJSON struct {
+	Sections        []string `json:"sections,omitempty"` // "## " headings, in order
+	PhaseCount      int      `json:"phase_count"`
+	TaskCount       int      `json:"task_count"`
+	TestCount       int      `json:"test_count"`
+	DecisionCount   int      `json:"decision_count"`
+	FileCount       int      `json:"file_count"`
+	CodeBlockCount  int      `json:"code_block_count"`
+	CodeLines       int      `json:"code_lines"`
+	Complexity      string   `json:"complexity"` // low, medium, high
+	Confidence      string   `json:"confidence,omitempty"`
+	EstimatedEffort string   `json:"estimated_effort"` // S, M, L, XL
+}
+
+// SpecFrontmatter is the machine-readable header stored ahead of a spec.
+type SpecFrontmatter struct {
+	IssueID       int64  `json:"issue_id"`
+	ContentSHA256 string `json:"content_sha256"` // Hash of the spec markdown after the frontmatter
+	SpecMetadataJSON
+}
+
+// specFrontmatterDelim fences the frontmatter. The block between the fences
+// is JSON, which YAML frontmatter parsers also accept.
+const specFrontmatterDelim = "---"
+
+var (
+	numberedItemPattern = regexp.MustCompile(`^\s*\d+\.\s+\S`)
+	backtickPathPattern = regexp.MustCompile("`([A-Za-z0-9_./-]+\\.[A-Za-z0-9]+)(?::\\d+)?`")
+	overallConfPattern  = regexp.MustCompile(`(?i)\*\*Overall:\*\*\s*\[?\s*(high|medium|low)`)
+)
+
+// extractSpecMetadata derives counts from the sections the spec prompt asks for:
+//   - phases are "### Phase" headings under "## Implementation Plan"
+//   - tasks are numbered list items in that section, outside code blocks
+//   - tests are numbered list items under "## Testing"
+//   - decisions are data rows of the "### Key Decisions" table
+//   - files are distinct backticked paths with an extension
+//
+// Sections the spec doesn't have simply count as zero.
+func extractSpecMetadata(spec string) SpecMetadataJSON {
+	var meta SpecMetadataJSON
+	files := make(map[string]bool)
+
+	var (
+		section     string // current "## " heading
+		subsection  string // current "### " heading
+		inCode      bool
+		tableHeader bool
+	)
+
+	for _, line := range strings.Split(spec, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if !inCode {
+				meta.CodeBlockCount++
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			meta.CodeLines++
+			continue
+		}
+
+		for _, m := range backtickPathPattern.FindAllStringSubmatch(line, -1) {
+			files[m[1]] = true
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "## "):
+			section = strings.TrimSpace(strings.TrimPrefix(trimmed, "## "))
+			subsection = ""
+			meta.Sections = append(meta.Sections, section)
+			continue
+		case strings.HasPrefix(trimmed, "### "):
+			subsection = strings.TrimSpace(strings.TrimPrefix(trimmed, "### "))
+			tableHeader = true
+			if strings.HasPrefix(section, "Implementation Plan") && strings.HasPrefix(subsection, "Phase") {
+				meta.PhaseCount++
+			}
+			continue
+		}
+
+		if numberedItemPattern.MatchString(line) {
+			switch {
+			case strings.HasPrefix(section, "Implementation Plan"):
+				meta.TaskCount++
+			case strings.HasPrefix(section, "Testing"):
+				meta.TestCount++
+			}
+		}
+
+		if strings.HasPrefix(subsection, "Key Decisions") && strings.HasPrefix(trimmed, "|") {
+			switch {
+			case tableHeader:
+				tableHeader = false
+			case strings.Trim(trimmed, "|-: ") == "":
+				// separator row
+			default:
+				meta.DecisionCount++
+			}
+		}
+
+		if meta.Confidence == "" {
+			if m := overallConfPattern.FindStringSubmatch(line); m != nil {
+				meta.Confidence = strings.ToLower(m[1])
+			}
+		}
+	}
+
+	meta.FileCount = len(files)
+	meta.Complexity = inferComplexity(meta)
+	meta.EstimatedEffort = estimateEffort(meta)
+	return meta
+}
+
+// inferComplexity grades how spread out the change is. Touching many files or
+// needing many phases is what makes a change hard to land, more than its size.
+func inferComplexity(meta SpecMetadataJSON) string {
+	switch {
+	case meta.PhaseCount >= 4 || meta.FileCount >= 10:
+		return "high"
+	case meta.PhaseCount >= 2 || meta.FileCount >= 4:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// estimateEffort maps spec metadata to a t-shirt size. It is a heuristic, not
+// a schedule: each task is one point, each decision two (decisions mean open
+// design questions), every 25 lines of sample code one, plus 0/3/6 for
+// low/medium/high complexity. Buckets: <8 S, <16 M, <28 L, otherwise XL.
+func estimateEffort(meta SpecMetadataJSON) string {
+	points := meta.TaskCount + 2*meta.DecisionCount + meta.CodeLines/25
+	switch meta.Complexity {
+	case "medium":
+		points += 3
+	case "high":
+		points += 6
+	}
+
+	switch {
+	case points < 8:
+		return EffortSmall
+	case points < 16:
+		return EffortMedium
+	case points < 28:
+		return EffortLarge
+	default:
+		return EffortExtraLarge
+	}
+}
+
+func newSpecFrontmatter(issueID int64, spec string, meta SpecMetadataJSON) SpecFrontmatter {
+	sum := sha256.Sum256([]byte(spec))
+	return SpecFrontmatter{
+		IssueID:          issueID,
+		ContentSHA256:    hex.EncodeToString(sum[:]),
+		SpecMetadataJSON: meta,
+	}
+}
+
+// renderSpecFrontmatter renders fm as a fenced JSON block followed by a blank
+// line, ready to prepend to the spec markdown.
+func renderSpecFrontmatter(fm SpecFrontmatter) string {
+	data, err := json.MarshalIndent(fm, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return specFrontmatterDelim + "\n" + string(data) + "\n" + specFrontmatterDelim + "\n\n"
+}
+
+// ParseSpecFrontmatter splits a stored spec into its frontmatter and markdown
+// body. ok is false when the spec has no frontmatter; body is then the input.
+func ParseSpecFrontmatter(stored string) (fm SpecFrontmatter, body string, ok bool) {
+	rest, found := strings.CutPrefix(stored, specFrontmatterDelim+"\n")
+	if !found {
+		return SpecFrontmatter{}, stored, false
+	}
+	block, body, found := strings.Cut(rest, "\n"+specFrontmatterDelim+"\n")
+	if !found {
+		return SpecFrontmatter{}, stored, false
+	}
+	if err := json.Unmarshal([]byte(block), &fm); err != nil {
+		return SpecFrontmatter{}, stored, false
+	}
+	return fm, strings.TrimPrefix(body, "\n"), true
+}
@@ -0,0 +1,190 @@
+package brain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleSpec = "# Implementation Spec: Retry webhooks\n" +
+	"\n" +
+	"## Summary\n" +
+	"Retry failed webhook deliveries.\n" +
+	"\n" +
+	"## Scope & Decisions\n" +
+	"\n" +
+	"### Key Decisions\n" +
+	"| Question | Decision | Rationale |\n" +
+	"|----------|----------|-----------|\n" +
+	"| Backoff? | Exponential | Avoid thundering herd |\n" +
+	"| Max attempts? | 5 | Matches queue default |\n" +
+	"\n" +
+	"## Implementation Plan\n" +
+	"\n" +
+	"### Phase 1: Store attempts\n" +
+	"**Files:** `internal/webhook/store.go`\n" +
+	"**Changes:**\n" +
+	"1. Add attempts column\n" +
+	"2. Persist last error\n" +
+	"\n" +
+	"```go\n" +
+	"type Attempt struct {\n" +
+	"\tN int\n" +
+	"}\n" +
+	"```\n" +
+	"\n" +
+	"### Phase 2: Retry loop\n" +
+	"**Files:** `internal/webhook/worker.go`, `internal/webhook/store.go`\n" +
+	"**Changes:**\n" +
+	"1. Schedule retries\n" +
+	"\n" +
+	"## Testing Guide\n" +
+	"1. Not a task\n" +
+	"\n" +
+	"## Confidence Assessment\n" +
+	"**Overall:** High\n"
+
+func TestExtractSpecMetadata(t *testing.T) {
+	meta := extractSpecMetadata(sampleSpec)
+
+	if meta.PhaseCount != 2 {
+		t.Errorf("PhaseCount = %d, want 2", meta.PhaseCount)
+	}
+	if meta.TaskCount != 3 {
+		t.Errorf("TaskCount = %d, want 3", meta.TaskCount)
+	}
+	if meta.TestCount != 1 {
+		t.Errorf("TestCount = %d, want 1", meta.TestCount)
+	}
+	wantSections := []string{"Summary", "Scope & Decisions", "Implementation Plan", "Testing Guide", "Confidence Assessment"}
+	if !reflect.DeepEqual(meta.Sections, wantSections) {
+		t.Errorf("Sections = %q, want %q", meta.Sections, wantSections)
+	}
+	if meta.DecisionCount != 2 {
+		t.Errorf("DecisionCount = %d, want 2", meta.DecisionCount)
+	}
+	if meta.FileCount != 2 {
+		t.Errorf("FileCount = %d, want 2", meta.FileCount)
+	}
+	if meta.CodeBlockCount != 1 || meta.CodeLines != 3 {
+		t.Errorf("code blocks = %d (%d lines), want 1 (3 lines)", meta.CodeBlockCount, meta.CodeLines)
+	}
+	if meta.Confidence != "high" {
+		t.Errorf("Confidence = %q, want high", meta.Confidence)
+	}
+	if meta.Complexity != "medium" {
+		t.Errorf("Complexity = %q, want medium", meta.Complexity)
+	}
+	// 3 tasks + 2*2 decisions + 0 code + 3 medium = 10
+	if meta.EstimatedEffort != EffortMedium {
+		t.Errorf("EstimatedEffort = %q, want %q", meta.EstimatedEffort, EffortMedium)
+	}
+}
+
+func TestExtractSpecMetadataEmpty(t *testing.T) {
+	meta := extractSpecMetadata("")
+	if meta.TaskCount != 0 || meta.Complexity != "low" || meta.EstimatedEffort != EffortSmall {
+		t.Errorf("unexpected metadata for empty spec: %+v", meta)
+	}
+}
+
+func TestEstimateEffort(t *testing.T) {
+	tests := []struct {
+		name string
+		meta SpecMetadataJSON
+		want string
+	}{
+		{
+			name: "single task, low complexity",
+			meta: SpecMetadataJSON{TaskCount: 1, Complexity: "low"},
+			want: EffortSmall,
+		},
+		{
+			name: "few tasks and one decision",
+			meta: SpecMetadataJSON{TaskCount: 4, DecisionCount: 1, Complexity: "low"},
+			want: EffortSmall,
+		},
+		{
+			name: "medium complexity pushes into M",
+			meta: SpecMetadataJSON{TaskCount: 4, DecisionCount: 1, Complexity: "medium"},
+			want: EffortMedium,
+		},
+		{
+			name: "code volume counts",
+			meta: SpecMetadataJSON{TaskCount: 3, CodeLines: 125, Complexity: "low"},
+			want: EffortMedium,
+		},
+		{
+			name: "many tasks, high complexity",
+			meta: SpecMetadataJSON{TaskCount: 12, DecisionCount: 3, Complexity: "high"},
+			want: EffortLarge,
+		},
+		{
+			name: "sprawling change",
+			meta: SpecMetadataJSON{TaskCount: 20, DecisionCount: 5, CodeLines: 200, Complexity: "high"},
+			want: EffortExtraLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateEffort(tt.meta); got != tt.want {
+				t.Errorf("estimateEffort() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferComplexity(t *testing.T) {
+	tests := []struct {
+		meta SpecMetadataJSON
+		want string
+	}{
+		{SpecMetadataJSON{PhaseCount: 1, FileCount: 2}, "low"},
+		{SpecMetadataJSON{PhaseCount: 2, FileCount: 2}, "medium"},
+		{SpecMetadataJSON{PhaseCount: 1, FileCount: 5}, "medium"},
+		{SpecMetadataJSON{PhaseCount: 4, FileCount: 1}, "high"},
+		{SpecMetadataJSON{PhaseCount: 1, FileCount: 12}, "high"},
+	}
+
+	for _, tt := range tests {
+		if got := inferComplexity(tt.meta); got != tt.want {
+			t.Errorf("inferComplexity(%+v) = %q, want %q", tt.meta, got, tt.want)
+		}
+	}
+}
+
+func TestSpecFrontmatterRoundTrip(t *testing.T) {
+	meta := extractSpecMetadata(sampleSpec)
+	stored := renderSpecFrontmatter(newSpecFrontmatter(42, sampleSpec, meta)) + sampleSpec
+
+	if !strings.HasPrefix(stored, "---\n{") {
+		t.Fatalf("stored spec does not start with frontmatter: %q", stored[:20])
+	}
+
+	fm, body, ok := ParseSpecFrontmatter(stored)
+	if !ok {
+		t.Fatal("ParseSpecFrontmatter() found no frontmatter")
+	}
+	if body != sampleSpec {
+		t.Errorf("body = %q, want the original spec", body)
+	}
+	if fm.IssueID != 42 {
+		t.Errorf("IssueID = %d, want 42", fm.IssueID)
+	}
+	if !reflect.DeepEqual(fm.SpecMetadataJSON, meta) {
+		t.Errorf("metadata = %+v, want %+v", fm.SpecMetadataJSON, meta)
+	}
+	if want := newSpecFrontmatter(42, body, meta).ContentSHA256; fm.ContentSHA256 != want || len(want) != 64 {
+		t.Errorf("ContentSHA256 = %q, want %q", fm.ContentSHA256, want)
+	}
+}
+
+func TestParseSpecFrontmatterPlainMarkdown(t *testing.T) {
+	for _, spec := range []string{sampleSpec, "---\nnot json\n---\n\nbody", "---\n{\"issue_id\": 1}"} {
+		_, body, ok := ParseSpecFrontmatter(spec)
+		if ok || body != spec {
+			t.Errorf("ParseSpecFrontmatter(%q) = (%q, %v), want input unchanged", spec, body, ok)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package brain
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"basegraph.co/relay/common/llm"
+	"basegraph.co/relay/common/logger"
+)
+
+// Spec generation metrics. They go through the global meter provider and are
+// no-ops until one is installed. Instrument creation only fails for invalid
+// names, so the errors are dropped.
+var (
+	specMeter = otel.Meter("relay-worker")
+
+	specsGenerated, _ = specMeter.Int64Counter("brain.spec.generated",
+		metric.WithDescription("Specs returned by the spec generator, by outcome"))
+	specValidationFailures, _ = specMeter.Int64Counter("brain.spec.validation_failures",
+		metric.WithDescription("Spec generator replies without a usable submit_spec call"))
+	specTokens, _ = specMeter.Int64Counter("brain.spec.tokens",
+		metric.WithDescription("LLM tokens used by the spec generator"),
+		metric.WithUnit("{token}"))
+)
+
+// Spec generation outcomes, recorded on the generate span and (except for
+// errors) the brain.spec.generated counter.
+const (
+	specOutcomeSubmitted   = "submitted"   // Spec delivered through submit_spec
+	specOutcomeUnsubmitted = "unsubmitted" // Plain content used as the spec
+	specOutcomeError       = "error"
+)
+
+// specTrace holds the spans of one Generate call: a generate span with one
+// child span per LLM attempt. The attempt span stays open while that
+// attempt's locate calls run, so they nest under it.
+type specTrace struct {
+	generate *logger.SpanContext
+	attempt  *logger.SpanContext
+
+	attempts         int
+	validationErrors int // In the current attempt
+	totalValidation  int
+	promptTokens     int
+	completionTokens int
+	outcome          string
+}
+
+func startSpecTrace(ctx context.Context, issueID int64) (*specTrace, context.Context) {
+	sc := logger.StartSpan(ctx, "brain.spec_generator.generate",
+		trace.WithAttributes(attribute.Int64("issue.id", issueID)))
+	return &specTrace{generate: sc, outcome: specOutcomeError}, sc.Context()
+}
+
+// startAttempt ends the previous attempt span and starts the next one. The
+// returned context carries the attempt span.
+func (t *specTrace) startAttempt(ctx context.Context) context.Context {
+	t.endAttempt()
+	t.attempts++
+	t.attempt = logger.StartSpan(ctx, "brain.spec_generator.attempt",
+		trace.WithAttributes(attribute.Int("spec.attempt", t.attempts)))
+	return t.attempt.Context()
+}
+
+// recordResponse adds the attempt's token usage to the attempt span and the
+// token counter.
+func (t *specTrace) recordResponse(ctx context.Context, resp *llm.AgentResponse) {
+	t.promptTokens += resp.PromptTokens
+	t.completionTokens += resp.CompletionTokens
+	t.attempt.Span().SetAttributes(
+		attribute.Int("llm.prompt_tokens", resp.PromptTokens),
+		attribute.Int("llm.completion_tokens", resp.CompletionTokens),
+		attribute.Int("llm.reasoning_tokens", resp.ReasoningTokens),
+		attribute.Int("llm.tool_calls", len(resp.ToolCalls)),
+	)
+	specTokens.Add(ctx, int64(resp.PromptTokens), metric.WithAttributes(attribute.String("type", "prompt")))
+	specTokens.Add(ctx, int64(resp.CompletionTokens), metric.WithAttributes(attribute.String("type", "completion")))
+}
+
+// validationFailure records a reply that didn't deliver a spec through a
+// well-formed submit_spec call.
+func (t *specTrace) validationFailure(ctx context.Context, reason string) {
+	t.validationErrors++
+	t.totalValidation++
+	specValidationFailures.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
+
+func (t *specTrace) endAttempt() {
+	if t.attempt == nil {
+		return
+	}
+	t.attempt.Span().SetAttributes(attribute.Int("spec.validation_errors", t.validationErrors))
+	t.attempt.End()
+	t.attempt = nil
+	t.validationErrors = 0
+}
+
+// finish closes the open attempt and the generate span with the totals.
+// Call it exactly once, on every return path.
+func (t *specTrace) finish(ctx context.Context, err error) {
+	t.endAttempt()
+
+	span := t.generate.Span()
+	span.SetAttributes(
+		attribute.Int("spec.attempts", t.attempts),
+		attribute.Int("spec.validation_errors", t.totalValidation),
+		attribute.Int("llm.prompt_tokens", t.promptTokens),
+		attribute.Int("llm.completion_tokens", t.completionTokens),
+		attribute.String("spec.outcome", t.outcome),
+	)
+	if err != nil {
+		t.generate.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	t.generate.End()
+
+	if err == nil {
+		specsGenerated.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", t.outcome)))
+	}
+}
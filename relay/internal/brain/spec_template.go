@@ -0,0 +1,88 @@
+package brain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SpecTemplateConfig is the house style specs are written in: the markdown
+// skeleton shown to the spec generator and the sections validateSpec holds
+// specs to.
+type SpecTemplateConfig struct {
+	// Body is the markdown skeleton the model must follow. Bracketed
+	// fill-ins like "[Name]" are flagged by validation if left in a spec.
+	Body string `json:"body"`
+
+	// Required are "## " headings every spec must have; a missing one is an
+	// error. A heading matches if it starts with the name.
+	Required []string `json:"required"`
+
+	// Recommended are "## " headings a spec should have; a missing one is a
+	// warning.
+	Recommended []string `json:"recommended,omitempty"`
+}
+
+// DefaultSpecTemplate returns the built-in spec template.
+func DefaultSpecTemplate() SpecTemplateConfig {
+	return SpecTemplateConfig{
+		Body: defaultSpecTemplateBody,
+		Required: []string{
+			"Summary",
+			"Scope & Decisions",
+			"Implementation Plan",
+			"Testing Guide",
+			"Confidence Assessment",
+		},
+		Recommended: []string{
+			"Review Guide",
+			"Verification Steps",
+		},
+	}
+}
+
+// LoadSpecTemplate reads a SpecTemplateConfig from a JSON file.
+func LoadSpecTemplate(path string) (SpecTemplateConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SpecTemplateConfig{}, fmt.Errorf("reading spec template: %w", err)
+	}
+	var tmpl SpecTemplateConfig
+	if err := json.Unmarshal(data, &tmpl); err != nil {
+		return SpecTemplateConfig{}, fmt.Errorf("parsing spec template %s: %w", path, err)
+	}
+	if strings.TrimSpace(tmpl.Body) == "" {
+		return SpecTemplateConfig{}, fmt.Errorf("spec template %s has no body", path)
+	}
+	return tmpl, nil
+}
+
+// samplePathPlaceholder is the example path the default template uses in
+// place of real file paths. It is always treated as a placeholder.
+const samplePathPlaceholder = "path/to/file.go"
+
+// templateFillInPattern matches bracketed fill-ins like "[Issue Title]".
+// Checkboxes ("[ ]", "[x]") have no two consecutive letters and don't match.
+var templateFillInPattern = regexp.MustCompile(`\[[^\[\]\n]*[A-Za-z]{2}[^\[\]\n]*\]`)
+
+// specSystemPrompt embeds the template body in the spec generator's system
+// prompt.
+func specSystemPrompt(tmpl SpecTemplateConfig) string {
+	return specGeneratorPromptHead + "```markdown\n" + strings.TrimRight(tmpl.Body, "\n") + "\n```" + specGeneratorPromptTail
+}
+
+// placeholders returns the distinct bracketed fill-ins of the template body,
+// plus the sample path, in order of appearance.
+func (t SpecTemplateConfig) placeholders() []string {
+	seen := map[string]bool{samplePathPlaceholder: true}
+	found := []string{samplePathPlaceholder}
+	for _, p := range templateFillInPattern.FindAllString(t.Body, -1) {
+		if !seen[p] {
+			seen[p] = true
+			found = append(found, p)
+		}
+	}
+	return found
+}
@@ -0,0 +1,181 @@
+package brain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"basegraph.co/relay/internal/model"
+)
+
+// Spec validation severities. Errors mean the spec is unusable as written;
+// warnings flag parts a reader would have to fix up.
+const (
+	SpecSeverityError   = "error"
+	SpecSeverityWarning = "warning"
+)
+
+// SpecValidationIssue is one structural problem found in a generated spec.
+type SpecValidationIssue struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+var (
+	// bracePlaceholderPattern matches fill-ins like {task} or {file:line}.
+	bracePlaceholderPattern = regexp.MustCompile(`\{[A-Za-z][A-Za-z0-9_ :/.-]*\}`)
+	inlineCodePattern       = regexp.MustCompile("`[^`]*`")
+)
+
+// validateSpec checks a spec against tmpl and the gaps it resolves:
+//   - the template's required sections are present (error)
+//   - its recommended sections are present (warning)
+//   - the Implementation Plan has at least one task that isn't a template
+//     placeholder (error)
+//   - when gaps were resolved, the Key Decisions table has rows and each row
+//     quotes the gap question it settles (warning)
+//   - none of the template's fill-ins, nor {task}-style braces, are left
+//     over (warning)
+//
+// Fenced code blocks are skipped, and brace placeholders inside inline code
+// are ignored, so sample code like "/users/{id}" isn't flagged.
+func validateSpec(spec string, gaps []model.Gap, tmpl SpecTemplateConfig) []SpecValidationIssue {
+	var issues []SpecValidationIssue
+	addIssue := func(severity, format string, args ...any) {
+		issues = append(issues, SpecValidationIssue{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	templatePlaceholders := tmpl.placeholders()
+	sections := make(map[string]bool)
+	placeholders := make(map[string]bool)
+	var placeholderOrder []string
+	var decisionRows []string
+	concreteTasks := 0
+
+	var (
+		section     string
+		subsection  string
+		inCode      bool
+		tableHeader bool
+	)
+	for _, line := range strings.Split(spec, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		for _, p := range linePlaceholders(line, templatePlaceholders) {
+			if !placeholders[p] {
+				placeholders[p] = true
+				placeholderOrder = append(placeholderOrder, p)
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "## "):
+			section = strings.TrimSpace(strings.TrimPrefix(trimmed, "## "))
+			subsection = ""
+			sections[section] = true
+			continue
+		case strings.HasPrefix(trimmed, "### "):
+			subsection = strings.TrimSpace(strings.TrimPrefix(trimmed, "### "))
+			tableHeader = true
+			continue
+		}
+
+		if strings.HasPrefix(section, "Implementation Plan") && numberedItemPattern.MatchString(line) {
+			if len(linePlaceholders(line, templatePlaceholders)) == 0 {
+				concreteTasks++
+			}
+		}
+
+		if strings.HasPrefix(subsection, "Key Decisions") && strings.HasPrefix(trimmed, "|") {
+			switch {
+			case tableHeader:
+				tableHeader = false
+			case strings.Trim(trimmed, "|-: ") == "":
+				// separator row
+			default:
+				decisionRows = append(decisionRows, trimmed)
+			}
+		}
+	}
+
+	for _, s := range tmpl.Required {
+		if !hasSection(sections, s) {
+			addIssue(SpecSeverityError, "missing section %q", s)
+		}
+	}
+	for _, s := range tmpl.Recommended {
+		if !hasSection(sections, s) {
+			addIssue(SpecSeverityWarning, "missing recommended section %q", s)
+		}
+	}
+
+	if hasSection(sections, "Implementation Plan") && concreteTasks == 0 {
+		addIssue(SpecSeverityError, "Implementation Plan has no concrete tasks")
+	}
+
+	if len(gaps) > 0 {
+		if len(decisionRows) == 0 {
+			addIssue(SpecSeverityWarning, "Key Decisions table has no rows but %d gap(s) were resolved", len(gaps))
+		}
+		for _, row := range decisionRows {
+			if !quotesGap(row, gaps) {
+				addIssue(SpecSeverityWarning, "Key Decisions row does not quote a resolved gap: %s", row)
+			}
+		}
+	}
+
+	for _, p := range placeholderOrder {
+		addIssue(SpecSeverityWarning, "template placeholder left in spec: %s", p)
+	}
+	return issues
+}
+
+// hasSection reports whether a heading starting with name is present, so
+// "Testing Guide" is satisfied by "Testing Guide (manual)".
+func hasSection(sections map[string]bool, name string) bool {
+	for s := range sections {
+		if strings.HasPrefix(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// linePlaceholders returns the template placeholders on one line of prose.
+func linePlaceholders(line string, templatePlaceholders []string) []string {
+	var found []string
+	for _, p := range templatePlaceholders {
+		if strings.Contains(line, p) {
+			found = append(found, p)
+		}
+	}
+	found = append(found, bracePlaceholderPattern.FindAllString(inlineCodePattern.ReplaceAllString(line, ""), -1)...)
+	return found
+}
+
+// quotesGap reports whether a decision row contains one of the gap
+// questions, ignoring case, punctuation and spacing.
+func quotesGap(row string, gaps []model.Gap) bool {
+	normRow := normalizeQuote(row)
+	for _, g := range gaps {
+		if q := normalizeQuote(g.Question); q != "" && strings.Contains(normRow, q) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeQuote(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -0,0 +1,155 @@
+package brain
+
+import (
+	"strings"
+	"testing"
+
+	"basegraph.co/relay/internal/model"
+)
+
+const placeholderSpec = "# Implementation Spec: [Issue Title]\n" +
+	"\n" +
+	"## Summary\n" +
+	"{summary}\n" +
+	"\n" +
+	"## Scope & Decisions\n" +
+	"\n" +
+	"### Key Decisions\n" +
+	"| Question | Decision | Rationale |\n" +
+	"|----------|----------|-----------|\n" +
+	"| [From resolved gaps] | [The answer] | [Why this decision] |\n" +
+	"\n" +
+	"## Implementation Plan\n" +
+	"\n" +
+	"### Phase 1: [Name]\n" +
+	"**Files:** `path/to/file.go`\n" +
+	"1. {task}\n" +
+	"2. [What to change] at {file:line}\n" +
+	"\n" +
+	"## Testing Guide\n" +
+	"1. Happy path\n" +
+	"\n" +
+	"## Confidence Assessment\n" +
+	"**Overall:** [High/Medium/Low]\n"
+
+// filledSpec is sampleSpec with the recommended sections too.
+const filledSpec = sampleSpec +
+	"\n" +
+	"## Review Guide\n" +
+	"Check the retry cap.\n" +
+	"\n" +
+	"## Verification Steps\n" +
+	"1. `make test`\n"
+
+func issuesOf(issues []SpecValidationIssue, severity string) []string {
+	var msgs []string
+	for _, i := range issues {
+		if i.Severity == severity {
+			msgs = append(msgs, i.Message)
+		}
+	}
+	return msgs
+}
+
+func TestValidateSpecAcceptsFilledSpec(t *testing.T) {
+	if issues := validateSpec(filledSpec, nil, DefaultSpecTemplate()); len(issues) != 0 {
+		t.Errorf("validateSpec(filledSpec) = %+v, want none", issues)
+	}
+}
+
+func TestValidateSpecFlagsTemplatePlaceholders(t *testing.T) {
+	gaps := []model.Gap{{Question: "Should retries back off exponentially?"}}
+	issues := validateSpec(placeholderSpec, gaps, DefaultSpecTemplate())
+
+	errs := issuesOf(issues, SpecSeverityError)
+	if len(errs) != 1 || errs[0] != "Implementation Plan has no concrete tasks" {
+		t.Errorf("errors = %q, want only the missing-tasks error", errs)
+	}
+
+	warnings := strings.Join(issuesOf(issues, SpecSeverityWarning), "\n")
+	for _, want := range []string{
+		"template placeholder left in spec: {task}",
+		"template placeholder left in spec: {file:line}",
+		"template placeholder left in spec: {summary}",
+		"template placeholder left in spec: [Name]",
+		"template placeholder left in spec: path/to/file.go",
+		"template placeholder left in spec: [High/Medium/Low]",
+		"Key Decisions row does not quote a resolved gap",
+	} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings missing %q:\n%s", want, warnings)
+		}
+	}
+}
+
+func TestValidateSpecDecisionsQuoteGaps(t *testing.T) {
+	gaps := []model.Gap{{Question: "Backoff?"}, {Question: "Which queue do retries use?"}}
+	warnings := issuesOf(validateSpec(filledSpec, gaps, DefaultSpecTemplate()), SpecSeverityWarning)
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "Max attempts?") {
+		t.Errorf("warnings = %q, want one for the Max attempts row", warnings)
+	}
+
+	noDecisions := strings.Replace(filledSpec, "| Backoff? | Exponential | Avoid thundering herd |\n| Max attempts? | 5 | Matches queue default |\n", "", 1)
+	warnings = issuesOf(validateSpec(noDecisions, gaps, DefaultSpecTemplate()), SpecSeverityWarning)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "has no rows but 2 gap(s)") {
+		t.Errorf("warnings = %q, want the empty decisions table warning", warnings)
+	}
+}
+
+func TestValidateSpecMissingSectionsAndInlineCode(t *testing.T) {
+	spec := "## Summary\nServe `/users/{id}` from the cache.\n\n## Implementation Plan\n1. Add the handler\n"
+	issues := validateSpec(spec, nil, DefaultSpecTemplate())
+
+	for _, w := range issuesOf(issues, SpecSeverityWarning) {
+		if strings.Contains(w, "placeholder") {
+			t.Errorf("inline code flagged as placeholder: %q", w)
+		}
+	}
+	errs := issuesOf(issues, SpecSeverityError)
+	want := []string{`missing section "Scope & Decisions"`, `missing section "Testing Guide"`, `missing section "Confidence Assessment"`}
+	if strings.Join(errs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors = %q, want %q", errs, want)
+	}
+}
+
+func TestValidateSpecCustomTemplate(t *testing.T) {
+	tmpl := SpecTemplateConfig{
+		Body:        "## Summary\n[One line]\n\n## Implementation Plan\n1. [Step]\n\n## Security Review\n[Threats]\n",
+		Required:    []string{"Summary", "Implementation Plan", "Security Review"},
+		Recommended: []string{"Rollout"},
+	}
+	spec := "## Summary\nRotate API keys.\n\n## Implementation Plan\n1. Add a rotation endpoint\n2. [Step]\n"
+
+	issues := validateSpec(spec, nil, tmpl)
+
+	errs := issuesOf(issues, SpecSeverityError)
+	if len(errs) != 1 || errs[0] != `missing section "Security Review"` {
+		t.Errorf("errors = %q, want the missing Security Review error", errs)
+	}
+	warnings := strings.Join(issuesOf(issues, SpecSeverityWarning), "\n")
+	for _, want := range []string{`missing recommended section "Rollout"`, "template placeholder left in spec: [Step]"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings missing %q:\n%s", want, warnings)
+		}
+	}
+	if strings.Contains(warnings, "Testing Guide") || strings.Contains(warnings, "Alternatives") {
+		t.Errorf("default template rules applied to custom template:\n%s", warnings)
+	}
+
+	spec += "\n## Security Review\nKeys are never logged.\n\n## Rollout\nBehind a flag.\n"
+	spec = strings.Replace(spec, "2. [Step]\n", "", 1)
+	if issues := validateSpec(spec, nil, tmpl); len(issues) != 0 {
+		t.Errorf("validateSpec(complete spec) = %+v, want none", issues)
+	}
+}
+
+func TestSpecSystemPromptUsesTemplateBody(t *testing.T) {
+	prompt := specSystemPrompt(SpecTemplateConfig{Body: "## Security Review\n[Threats]\n"})
+	if !strings.Contains(prompt, "```markdown\n## Security Review\n[Threats]\n```\n\n# Guidelines") {
+		t.Errorf("template body not embedded in prompt:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "## Testing Guide") {
+		t.Error("custom template prompt still contains the default template")
+	}
+}
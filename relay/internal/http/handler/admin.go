@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdminAPIKey rejects requests that don't carry adminAPIKey in the
+// X-Admin-API-Key header or as a Bearer token. Admin routes are disabled
+// when no key is configured.
+func RequireAdminAPIKey(adminAPIKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminAPIKey == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "admin API not configured"})
+			c.Abort()
+			return
+		}
+
+		apiKey := c.GetHeader("X-Admin-API-Key")
+		if apiKey == "" {
+			apiKey = c.GetHeader("Authorization")
+			if len(apiKey) > 7 && apiKey[:7] == "Bearer " {
+				apiKey = apiKey[7:]
+			}
+		}
+
+		if apiKey != adminAPIKey {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing API key"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
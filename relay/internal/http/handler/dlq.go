@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"basegraph.co/relay/internal/queue"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDLQListCount = 50
+	maxDLQListCount     = 500
+)
+
+// DLQHandler lets operators inspect and re-drive dead-lettered worker
+// messages (admin only).
+type DLQHandler struct {
+	dlq *queue.DLQManager
+}
+
+func NewDLQHandler(dlq *queue.DLQManager) *DLQHandler {
+	return &DLQHandler{dlq: dlq}
+}
+
+// List returns the oldest DLQ entries, up to ?count= (default 50, max 500).
+func (h *DLQHandler) List(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	count := int64(defaultDLQListCount)
+	if raw := c.Query("count"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "count must be a positive integer"})
+			return
+		}
+		count = min(n, maxDLQListCount)
+	}
+
+	entries, err := h.dlq.ListDLQ(ctx, count)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list dlq entries", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list dlq entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+// Get returns a single DLQ entry by its stream ID.
+func (h *DLQHandler) Get(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	entry, err := h.dlq.GetDLQEntry(ctx, id)
+	if err != nil {
+		if errors.Is(err, queue.ErrDLQEntryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "dlq entry not found"})
+			return
+		}
+		if errors.Is(err, queue.ErrInvalidDLQID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a stream entry id like 1700000000000-0"})
+			return
+		}
+		slog.ErrorContext(ctx, "failed to get dlq entry", "error", err, "dlq_id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get dlq entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// Redrive moves a DLQ entry back onto the worker stream.
+func (h *DLQHandler) Redrive(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	messageID, err := h.dlq.RedriveDLQ(ctx, id)
+	if err != nil {
+		if errors.Is(err, queue.ErrDLQEntryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "dlq entry not found"})
+			return
+		}
+		if errors.Is(err, queue.ErrInvalidDLQID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a stream entry id like 1700000000000-0"})
+			return
+		}
+		slog.ErrorContext(ctx, "failed to redrive dlq entry", "error", err, "dlq_id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to redrive dlq entry"})
+		return
+	}
+
+	slog.InfoContext(ctx, "dlq entry redriven via admin API", "dlq_id", id, "message_id", messageID)
+	c.JSON(http.StatusOK, gin.H{"message_id": messageID})
+}
@@ -0,0 +1,136 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redis/go-redis/v9"
+
+	"basegraph.co/relay/internal/http/handler"
+	"basegraph.co/relay/internal/queue"
+)
+
+var _ = Describe("DLQHandler", func() {
+	const (
+		stream      = "relay_events"
+		dlqStream   = "relay_events_dlq"
+		adminAPIKey = "test-admin-key"
+	)
+
+	var (
+		router *gin.Engine
+		client *redis.Client
+		dlqID  string
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mr := miniredis.RunT(GinkgoT())
+		client = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		DeferCleanup(client.Close)
+
+		var err error
+		dlqID, err = client.XAdd(context.Background(), &redis.XAddArgs{
+			Stream: dlqStream,
+			Values: map[string]any{
+				"task_type":    "issue_event",
+				"event_log_id": 10,
+				"issue_id":     5,
+				"event_type":   "issue_created",
+				"attempt":      3,
+				"error":        "planner failed",
+			},
+		}).Result()
+		Expect(err).NotTo(HaveOccurred())
+
+		h := handler.NewDLQHandler(queue.NewDLQManager(client, stream, dlqStream))
+		router = gin.New()
+		admin := router.Group("/admin/dlq")
+		admin.Use(handler.RequireAdminAPIKey(adminAPIKey))
+		{
+			admin.GET("", h.List)
+			admin.GET("/:id", h.Get)
+			admin.POST("/:id/redrive", h.Redrive)
+		}
+	})
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Admin-API-Key", adminAPIKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("rejects requests without the admin API key", func() {
+		req := httptest.NewRequest(http.MethodGet, "/admin/dlq", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("lists DLQ entries", func() {
+		w := do(http.MethodGet, "/admin/dlq?count=10")
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var resp struct {
+			Entries []queue.DLQEntry `json:"entries"`
+		}
+		Expect(json.Unmarshal(w.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Entries).To(HaveLen(1))
+		Expect(resp.Entries[0].ID).To(Equal(dlqID))
+		Expect(resp.Entries[0].Error).To(Equal("planner failed"))
+		Expect(resp.Entries[0].TaskType).To(Equal(queue.TaskTypeIssueEvent))
+	})
+
+	It("rejects an invalid count", func() {
+		w := do(http.MethodGet, "/admin/dlq?count=-1")
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns a single entry", func() {
+		w := do(http.MethodGet, "/admin/dlq/"+dlqID)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var entry queue.DLQEntry
+		Expect(json.Unmarshal(w.Body.Bytes(), &entry)).To(Succeed())
+		Expect(entry.Attempt).To(Equal(3))
+		Expect(entry.Values).To(HaveKeyWithValue("issue_id", "5"))
+	})
+
+	It("returns 404 for an unknown entry", func() {
+		Expect(do(http.MethodGet, "/admin/dlq/1-0").Code).To(Equal(http.StatusNotFound))
+		Expect(do(http.MethodPost, "/admin/dlq/1-0/redrive").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("returns 400 for a malformed entry id", func() {
+		Expect(do(http.MethodGet, "/admin/dlq/not-an-id").Code).To(Equal(http.StatusBadRequest))
+		Expect(do(http.MethodPost, "/admin/dlq/not-an-id/redrive").Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("redrives an entry onto the worker stream", func() {
+		w := do(http.MethodPost, "/admin/dlq/"+dlqID+"/redrive")
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var resp struct {
+			MessageID string `json:"message_id"`
+		}
+		Expect(json.Unmarshal(w.Body.Bytes(), &resp)).To(Succeed())
+
+		msgs, err := client.XRange(context.Background(), stream, "-", "+").Result()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msgs).To(HaveLen(1))
+		Expect(msgs[0].ID).To(Equal(resp.MessageID))
+		Expect(msgs[0].Values).To(HaveKeyWithValue("attempt", "1"))
+		Expect(msgs[0].Values).To(HaveKeyWithValue("last_error", "planner failed"))
+
+		Expect(client.XLen(context.Background(), dlqStream).Val()).To(BeZero())
+	})
+})
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// HealthCheck pings one dependency; a nil error means it is up.
+type HealthCheck func(ctx context.Context) error
+
+type HealthHandler struct {
+	checks  map[string]HealthCheck
+	timeout time.Duration
+}
+
+// NewHealthHandler builds liveness and readiness handlers over the named
+// dependency checks. Each check gets its own timeout (default 2s) so one
+// hung dependency can't stall the probe.
+func NewHealthHandler(checks map[string]HealthCheck, timeout time.Duration) *HealthHandler {
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	return &HealthHandler{checks: checks, timeout: timeout}
+}
+
+// Live reports that the process is serving requests. It checks nothing, so
+// a dependency outage doesn't get the process restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready pings every dependency concurrently and responds 200 when all are
+// up, 503 otherwise, with a per-dependency "up"/"down" map. Errors are
+// logged rather than returned since the endpoint is unauthenticated.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]string, len(h.checks))
+		healthy = true
+	)
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			status := "up"
+			if err := check(checkCtx); err != nil {
+				slog.WarnContext(ctx, "readiness check failed", "dependency", name, "error", err)
+				status = "down"
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = status
+			if status != "up" {
+				healthy = false
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !healthy {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": results})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": results})
+}
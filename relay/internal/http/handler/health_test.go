@@ -0,0 +1,93 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"basegraph.co/relay/internal/http/handler"
+)
+
+var _ = Describe("HealthHandler", func() {
+	var (
+		router   *gin.Engine
+		redisErr error
+	)
+
+	type readiness struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+
+	get := func(path string) (*httptest.ResponseRecorder, readiness) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body readiness
+		Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+		return rec, body
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		redisErr = nil
+
+		h := handler.NewHealthHandler(map[string]handler.HealthCheck{
+			"postgres": func(context.Context) error { return nil },
+			"redis":    func(context.Context) error { return redisErr },
+			"arangodb": func(ctx context.Context) error {
+				<-ctx.Done() // Hangs until the per-check timeout
+				return ctx.Err()
+			},
+		}, 20*time.Millisecond)
+		router = gin.New()
+		router.GET("/healthz", h.Live)
+		router.GET("/readyz", h.Ready)
+	})
+
+	It("reports each dependency and 503 when one is down", func() {
+		rec, body := get("/readyz")
+
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(body.Status).To(Equal("unavailable"))
+		Expect(body.Checks).To(Equal(map[string]string{"postgres": "up", "redis": "up", "arangodb": "down"}))
+	})
+
+	It("flips to 503 when a dependency fails and back to 200 when it recovers", func() {
+		h := handler.NewHealthHandler(map[string]handler.HealthCheck{
+			"postgres": func(context.Context) error { return nil },
+			"redis":    func(context.Context) error { return redisErr },
+		}, 0)
+		router = gin.New()
+		router.GET("/readyz", h.Ready)
+
+		rec, body := get("/readyz")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(body.Status).To(Equal("ok"))
+
+		redisErr = errors.New("dial tcp 10.0.0.5:6379: connection refused")
+		rec, body = get("/readyz")
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(body.Checks).To(HaveKeyWithValue("redis", "down"))
+		Expect(rec.Body.String()).NotTo(ContainSubstring("10.0.0.5"))
+
+		redisErr = nil
+		rec, _ = get("/readyz")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	It("keeps liveness up regardless of dependencies", func() {
+		redisErr = errors.New("down")
+
+		rec, body := get("/healthz")
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(body.Status).To(Equal("ok"))
+	})
+})
@@ -299,29 +299,7 @@ func parseSessionID(s string) (int64, error) {
 
 // RequireAdminAPIKey middleware checks for valid admin API key
 func (h *InvitationHandler) RequireAdminAPIKey() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if h.adminAPIKey == "" {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "admin API not configured"})
-			c.Abort()
-			return
-		}
-
-		apiKey := c.GetHeader("X-Admin-API-Key")
-		if apiKey == "" {
-			apiKey = c.GetHeader("Authorization")
-			if len(apiKey) > 7 && apiKey[:7] == "Bearer " {
-				apiKey = apiKey[7:]
-			}
-		}
-
-		if apiKey != h.adminAPIKey {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing API key"})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
+	return RequireAdminAPIKey(h.adminAPIKey)
 }
 
 func toInvitationResponse(inv model.Invitation) invitationResponse {
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRateLimitKeyPrefix = "ratelimit:"
+
+// RateLimitConfig configures the RateLimit middleware.
+type RateLimitConfig struct {
+	Redis             redis.Scripter
+	RequestsPerMinute int              // Steady refill rate of each bucket
+	Burst             int              // Bucket size; defaults to RequestsPerMinute
+	KeyPrefix         string           // Redis key prefix; defaults to "ratelimit:"
+	Now               func() time.Time // Clock, for tests; defaults to time.Now
+}
+
+// tokenBucketScript refills and takes one token from the bucket at KEYS[1].
+// ARGV: refill rate in tokens per millisecond, burst, now in ms, ttl in ms.
+// Returns {allowed (0/1), ms until a token is available}. The clock comes
+// from the caller so every server instance shares one notion of time per
+// request and tests can drive it.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate)
+	ts = now
+end
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {allowed, wait}
+`)
+
+// RateLimit limits requests with a token bucket per client IP, kept in Redis
+// so the limit holds across server instances. Over the limit it responds 429 with Retry-After. If Redis is
+// unreachable the request is let through: a flaky limiter must not take
+// ingestion down with it.
+func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = cfg.RequestsPerMinute
+	}
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = defaultRateLimitKeyPrefix
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	ratePerMs := float64(cfg.RequestsPerMinute) / float64(time.Minute/time.Millisecond)
+	// Keep idle buckets until they would be full again, plus a margin.
+	ttl := time.Duration(float64(burst)/ratePerMs)*time.Millisecond + time.Second
+
+	return func(c *gin.Context) {
+		if cfg.RequestsPerMinute <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		res, err := tokenBucketScript.Run(ctx, cfg.Redis,
+			[]string{prefix + rateLimitKey(c)},
+			strconv.FormatFloat(ratePerMs, 'g', -1, 64), burst, now().UnixMilli(), ttl.Milliseconds(),
+		).Int64Slice()
+		if err != nil || len(res) != 2 {
+			slog.WarnContext(ctx, "rate limiter unavailable, allowing request", "error", err)
+			c.Next()
+			return
+		}
+
+		if res[0] == 0 {
+			retryAfter := int(math.Ceil(float64(res[1]) / 1000))
+			c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey identifies the caller by client IP. The limiter runs before
+// authentication, so API key headers are not trusted here: keying on them
+// would let a caller reset its bucket by sending a new made-up key with each
+// request. ClientIP reads X-Forwarded-For only from the engine's trusted
+// proxies (Engine.SetTrustedProxies), so the server must set them: gin
+// trusts every peer by default.
+func rateLimitKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,127 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redis/go-redis/v9"
+
+	"basegraph.co/relay/internal/http/middleware"
+)
+
+var _ = Describe("RateLimit", func() {
+	var (
+		mr     *miniredis.Miniredis
+		router *gin.Engine
+		now    time.Time
+	)
+
+	// 60 requests per minute refills one token per second.
+	setup := func(burst int) {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+		DeferCleanup(client.Close)
+
+		router = gin.New()
+		router.Use(middleware.RateLimit(middleware.RateLimitConfig{
+			Redis:             client,
+			RequestsPerMinute: 60,
+			Burst:             burst,
+			Now:               func() time.Time { return now },
+		}))
+		router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+
+	request := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		mr = miniredis.RunT(GinkgoT())
+		now = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	It("allows the burst, then rejects with Retry-After until a token refills", func() {
+		setup(3)
+
+		for i := 0; i < 3; i++ {
+			Expect(request(nil).Code).To(Equal(http.StatusOK), "request %d", i+1)
+		}
+
+		rec := request(nil)
+		Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(rec.Header().Get("Retry-After")).To(Equal("1"))
+		Expect(rec.Body.String()).To(ContainSubstring("rate limit exceeded"))
+
+		now = now.Add(999 * time.Millisecond)
+		Expect(request(nil).Code).To(Equal(http.StatusTooManyRequests))
+
+		now = now.Add(time.Millisecond)
+		Expect(request(nil).Code).To(Equal(http.StatusOK))
+		Expect(request(nil).Code).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("reports how long until the next token", func() {
+		setup(1)
+
+		Expect(request(nil).Code).To(Equal(http.StatusOK))
+		now = now.Add(200 * time.Millisecond)
+
+		rec := request(nil)
+		Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(rec.Header().Get("Retry-After")).To(Equal("1"))
+
+		now = now.Add(800 * time.Millisecond)
+		Expect(request(nil).Code).To(Equal(http.StatusOK))
+	})
+
+	It("keeps separate buckets per client IP", func() {
+		setup(1)
+
+		Expect(request(nil).Code).To(Equal(http.StatusOK))
+		Expect(request(nil).Code).To(Equal(http.StatusTooManyRequests))
+
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "10.0.0.2:1234"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	It("does not reset the limit when the API key header changes", func() {
+		setup(1)
+
+		Expect(request(map[string]string{"X-Gitlab-Token": "source-a"}).Code).To(Equal(http.StatusOK))
+		Expect(request(map[string]string{"X-Gitlab-Token": "source-b"}).Code).To(Equal(http.StatusTooManyRequests))
+		Expect(request(map[string]string{"Authorization": "Bearer source-c"}).Code).To(Equal(http.StatusTooManyRequests))
+		Expect(request(map[string]string{"X-Admin-API-Key": "source-d"}).Code).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("ignores X-Forwarded-For from untrusted peers", func() {
+		setup(1)
+		Expect(router.SetTrustedProxies(nil)).To(Succeed())
+
+		Expect(request(map[string]string{"X-Forwarded-For": "203.0.113.1"}).Code).To(Equal(http.StatusOK))
+		Expect(request(map[string]string{"X-Forwarded-For": "203.0.113.2"}).Code).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("lets requests through when redis is down", func() {
+		setup(1)
+		mr.Close()
+
+		Expect(request(nil).Code).To(Equal(http.StatusOK))
+		Expect(request(nil).Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"basegraph.co/relay/internal/http/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// DLQRouter sets up the dead letter queue routes; all require the admin API key.
+func DLQRouter(rg *gin.RouterGroup, h *handler.DLQHandler, adminAPIKey string) {
+	rg.Use(handler.RequireAdminAPIKey(adminAPIKey))
+	{
+		rg.GET("", h.List)
+		rg.GET("/:id", h.Get)
+		rg.POST("/:id/redrive", h.Redrive)
+	}
+}
@@ -0,0 +1,12 @@
+package router
+
+import (
+	"basegraph.co/relay/internal/http/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// HealthRouter sets up the liveness (/healthz) and readiness (/readyz) probes.
+func HealthRouter(r gin.IRoutes, h *handler.HealthHandler) {
+	r.GET("/healthz", h.Live)
+	r.GET("/readyz", h.Ready)
+}
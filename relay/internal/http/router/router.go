@@ -3,7 +3,9 @@ package router
 import (
 	"basegraph.co/relay/internal/http/handler"
 	"basegraph.co/relay/internal/http/handler/webhook"
+	"basegraph.co/relay/internal/http/middleware"
 	"basegraph.co/relay/internal/mapper"
+	"basegraph.co/relay/internal/queue"
 	"basegraph.co/relay/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
@@ -15,12 +17,21 @@ type RouterConfig struct {
 	TraceHeaderName string // header name for distributed tracing (e.g., "X-Trace-ID")
 	AdminAPIKey     string // API key for admin endpoints
 	RedisClient     *redis.Client
+	DLQ             *queue.DLQManager              // Worker dead letter queue; admin routes are skipped when nil
+	RateLimit       *middleware.RateLimitConfig    // Per-client-IP request limit; disabled when nil
+	HealthChecks    map[string]handler.HealthCheck // Dependencies checked by /readyz, keyed by name
 }
 
 func SetupRoutes(router *gin.Engine, services *service.Services, cfg RouterConfig) {
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
+	HealthRouter(router, handler.NewHealthHandler(cfg.HealthChecks, 0))
+
+	// Registered after the health routes so probes are never limited
+	if cfg.RateLimit != nil {
+		router.Use(middleware.RateLimit(*cfg.RateLimit))
+	}
 
 	invitationService := services.Invitations()
 
@@ -31,6 +42,10 @@ func SetupRoutes(router *gin.Engine, services *service.Services, cfg RouterConfi
 	invitationHandler := handler.NewInvitationHandler(invitationService, services.Auth(), cfg.AdminAPIKey)
 	InvitationRouter(router.Group("/invites"), router.Group("/admin/invites"), invitationHandler)
 
+	if cfg.DLQ != nil {
+		DLQRouter(router.Group("/admin/dlq"), handler.NewDLQHandler(cfg.DLQ), cfg.AdminAPIKey)
+	}
+
 	v1 := router.Group("/api/v1")
 	{
 		userHandler := handler.NewUserHandler(services.Users())
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// ExploreMetricsRecord is one explore agent session as stored for
+// dashboards. The counters are broken out for charting; Metrics holds the
+// full session metrics as JSON.
+type ExploreMetricsRecord struct {
+	SessionID           string    `json:"session_id"`
+	Query               string    `json:"query"`
+	Thoroughness        string    `json:"thoroughness"`
+	StartedAt           time.Time `json:"started_at"`
+	DurationMs          int64     `json:"duration_ms"`
+	Iterations          int       `json:"iterations"`
+	ContextWindowTokens int       `json:"context_window_tokens"`
+	CompletionTokens    int       `json:"completion_tokens"`
+
+	CodegraphCalls         int `json:"codegraph_calls"`
+	CodegraphInvalidKind   int `json:"codegraph_invalid_kind"`
+	CodegraphAmbiguous     int `json:"codegraph_ambiguous"`
+	CodegraphTraceFound    int `json:"codegraph_trace_found"`
+	CodegraphTraceNotFound int `json:"codegraph_trace_not_found"`
+
+	Confidence        string `json:"confidence"`
+	TerminationReason string `json:"termination_reason"`
+	Metrics           []byte `json:"metrics"`
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"math/rand/v2"
 	"strconv"
 	"time"
 
@@ -20,9 +22,22 @@ type ConsumerConfig struct {
 	BatchSize    int64         // Number of messages to process per batch
 	Block        time.Duration // How long to block/poll for new messages
 	MaxAttempts  int           // Maximum retry attempts before moving to DLQ
-	RequeueDelay time.Duration // Delay before retrying failed messages
+	ProcessedTTL time.Duration // How long processed idempotency keys are remembered (default 24h)
+
+	// Requeued messages wait RequeueBaseDelay before their first retry,
+	// doubling on every further attempt up to RequeueMaxDelay (0 = uncapped).
+	// RequeueJitter (0-1) shaves a random fraction off each delay so failed
+	// messages don't all retry in lockstep.
+	RequeueBaseDelay time.Duration
+	RequeueMaxDelay  time.Duration
+	RequeueJitter    float64
 }
 
+const (
+	defaultProcessedTTL = 24 * time.Hour
+	processedKeyPrefix  = "relay:idempotency:processed:"
+)
+
 type Message struct {
 	ID              string
 	TaskType        TaskType
@@ -37,6 +52,7 @@ type Message struct {
 	RunID           *int64
 	RepoID          *int64
 	Branch          string
+	IdempotencyKey  string // Set by WithIdempotencyKey; empty when the producer gave none
 	Raw             redis.XMessage
 }
 
@@ -49,6 +65,10 @@ type RedisConsumer struct {
 }
 
 func NewRedisConsumer(client *redis.Client, cfg ConsumerConfig) (*RedisConsumer, error) {
+	if cfg.ProcessedTTL <= 0 {
+		cfg.ProcessedTTL = defaultProcessedTTL
+	}
+
 	consumer := &RedisConsumer{
 		client: client,
 		cfg:    cfg,
@@ -105,6 +125,9 @@ func (c *RedisConsumer) Read(ctx context.Context) ([]Message, error) {
 				_ = c.Ack(ctx, Message{ID: msg.ID, Raw: msg})
 				continue
 			}
+			if c.AckIfProcessed(ctx, parsed) {
+				continue
+			}
 			messages = append(messages, parsed)
 		}
 	}
@@ -128,6 +151,46 @@ func (c *RedisConsumer) Ack(ctx context.Context, msg Message) error {
 	return nil
 }
 
+// MarkProcessed records msg's idempotency key as done, so a redelivered or
+// reclaimed copy is dropped instead of processed again. Messages without a
+// key are not tracked.
+func (c *RedisConsumer) MarkProcessed(ctx context.Context, msg Message) error {
+	if msg.IdempotencyKey == "" {
+		return nil
+	}
+	if err := c.client.Set(ctx, processedKeyPrefix+msg.IdempotencyKey, msg.ID, c.cfg.ProcessedTTL).Err(); err != nil {
+		return fmt.Errorf("marking %s processed: %w", msg.IdempotencyKey, err)
+	}
+	return nil
+}
+
+// AckIfProcessed acks msg and reports true when its idempotency key was
+// already processed. On a Redis error it logs and reports false: processing
+// twice beats dropping a task.
+func (c *RedisConsumer) AckIfProcessed(ctx context.Context, msg Message) bool {
+	if msg.IdempotencyKey == "" {
+		return false
+	}
+	n, err := c.client.Exists(ctx, processedKeyPrefix+msg.IdempotencyKey).Result()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check idempotency key, processing message",
+			"error", err,
+			"idempotency_key", msg.IdempotencyKey)
+		return false
+	}
+	if n == 0 {
+		return false
+	}
+
+	slog.InfoContext(ctx, "dropping already processed message",
+		"message_id", msg.ID,
+		"idempotency_key", msg.IdempotencyKey)
+	if err := c.Ack(ctx, msg); err != nil {
+		slog.WarnContext(ctx, "failed to ack already processed message", "error", err)
+	}
+	return true
+}
+
 func (c *RedisConsumer) Requeue(ctx context.Context, msg Message, errMsg string) error {
 	nextAttempt := msg.Attempt + 1
 	return c.RequeueWithAttempt(ctx, msg, nextAttempt, errMsg)
@@ -141,17 +204,25 @@ func (c *RedisConsumer) RequeueWithAttempt(ctx context.Context, msg Message, att
 		}
 	}
 
-	if err := c.Ack(ctx, msg); err != nil {
-		return fmt.Errorf("acking failed message for requeue: %w", err)
-	}
-
 	values := messageValues(msg, attempt)
 	if errMsg != "" {
 		values["last_error"] = errMsg
 	}
 
-	if c.cfg.RequeueDelay > 0 {
-		time.Sleep(c.cfg.RequeueDelay)
+	// The message stays pending until the copy is added, so nothing is lost
+	// if the worker dies while waiting. If ctx is cancelled mid-wait (e.g. on
+	// shutdown), requeue right away instead of dropping the retry.
+	if delay := c.cfg.requeueDelay(attempt, rand.Float64()); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			slog.InfoContext(ctx, "requeue wait interrupted, requeuing now",
+				"next_attempt", attempt,
+				"error", ctx.Err())
+			ctx = context.WithoutCancel(ctx)
+		}
 	}
 
 	if err := c.client.XAdd(ctx, &redis.XAddArgs{
@@ -161,12 +232,46 @@ func (c *RedisConsumer) RequeueWithAttempt(ctx context.Context, msg Message, att
 		return fmt.Errorf("xadd requeue: %w", err)
 	}
 
+	// Ack after the copy is in: failing here leaves a duplicate for the
+	// reclaimer, which beats losing the message.
+	if err := c.Ack(ctx, msg); err != nil {
+		return fmt.Errorf("acking failed message for requeue: %w", err)
+	}
+
 	slog.InfoContext(ctx, "message requeued for retry",
 		"next_attempt", attempt,
 		"reason", errMsg)
 	return nil
 }
 
+// requeueDelay returns how long to wait before re-adding a message for the
+// given attempt. Attempt 2 (the first retry) waits the base delay, and each
+// later attempt doubles it up to the max. r is a uniform sample in [0, 1)
+// used for jitter, so the result always lies in [delay*(1-jitter), delay].
+func (cfg ConsumerConfig) requeueDelay(attempt int, r float64) time.Duration {
+	if cfg.RequeueBaseDelay <= 0 {
+		return 0
+	}
+
+	delay := cfg.RequeueBaseDelay
+	for i := 2; i < attempt; i++ {
+		if cfg.RequeueMaxDelay > 0 && delay >= cfg.RequeueMaxDelay {
+			break
+		}
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64
+			break
+		}
+		delay *= 2
+	}
+	if cfg.RequeueMaxDelay > 0 && delay > cfg.RequeueMaxDelay {
+		delay = cfg.RequeueMaxDelay
+	}
+
+	jitter := min(max(cfg.RequeueJitter, 0), 1)
+	return delay - time.Duration(float64(delay)*jitter*r)
+}
+
 func (c *RedisConsumer) SendDLQ(ctx context.Context, msg Message, errMsg string) error {
 	if err := c.Ack(ctx, msg); err != nil {
 		return fmt.Errorf("acking failed message for dlq: %w", err)
@@ -230,6 +335,10 @@ func ParseMessage(msg redis.XMessage) (Message, error) {
 	if err != nil {
 		return Message{}, err
 	}
+	idempotencyKey, err := parseOptionalString(msg.Values, "idempotency_key")
+	if err != nil {
+		return Message{}, err
+	}
 
 	attempt, err := parseOptionalInt(msg.Values, "attempt")
 	if err != nil {
@@ -287,6 +396,7 @@ func ParseMessage(msg redis.XMessage) (Message, error) {
 		RunID:           runID,
 		RepoID:          repoID,
 		Branch:          branch,
+		IdempotencyKey:  idempotencyKey,
 		Raw:             msg,
 	}, nil
 }
@@ -403,6 +513,9 @@ func messageValues(msg Message, attempt int) map[string]any {
 	if msg.TriggerThreadID != "" {
 		values["trigger_thread_id"] = msg.TriggerThreadID
 	}
+	if msg.IdempotencyKey != "" {
+		values["idempotency_key"] = msg.IdempotencyKey
+	}
 
 	return values
 }
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRequeueDelayGrowsPerAttemptUpToMax(t *testing.T) {
+	cfg := ConsumerConfig{
+		RequeueBaseDelay: time.Second,
+		RequeueMaxDelay:  10 * time.Second,
+	}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		attempt := i + 2
+		if got := cfg.requeueDelay(attempt, 0); got != w {
+			t.Errorf("attempt %d: delay = %v, want %v", attempt, got, w)
+		}
+	}
+
+	if got := cfg.requeueDelay(1000, 0); got != cfg.RequeueMaxDelay {
+		t.Errorf("attempt 1000: delay = %v, want max %v", got, cfg.RequeueMaxDelay)
+	}
+}
+
+func TestRequeueDelayJitterStaysWithinBounds(t *testing.T) {
+	cfg := ConsumerConfig{
+		RequeueBaseDelay: time.Second,
+		RequeueMaxDelay:  10 * time.Second,
+		RequeueJitter:    0.5,
+	}
+
+	for attempt := 2; attempt <= 8; attempt++ {
+		full := cfg.requeueDelay(attempt, 0)
+		for _, r := range []float64{0, 0.25, 0.5, 0.999} {
+			got := cfg.requeueDelay(attempt, r)
+			if got > full || got > cfg.RequeueMaxDelay {
+				t.Errorf("attempt %d r=%v: delay %v above %v", attempt, r, got, full)
+			}
+			if got < full/2 {
+				t.Errorf("attempt %d r=%v: delay %v below half of %v", attempt, r, got, full)
+			}
+		}
+	}
+
+	if got := cfg.requeueDelay(3, 0.5); got != 1500*time.Millisecond {
+		t.Errorf("attempt 3 r=0.5: delay = %v, want 1.5s", got)
+	}
+}
+
+func TestRequeueDelayDisabledWithoutBase(t *testing.T) {
+	cfg := ConsumerConfig{RequeueMaxDelay: time.Minute, RequeueJitter: 1}
+	if got := cfg.requeueDelay(5, 0.5); got != 0 {
+		t.Errorf("delay = %v, want 0", got)
+	}
+}
+
+func TestRequeueCancelledDuringWaitKeepsMessage(t *testing.T) {
+	consumer, _, client := newTestConsumer(t)
+	consumer.cfg.RequeueBaseDelay = time.Hour
+
+	if err := NewRedisProducer(client, testStream).Enqueue(context.Background(), issueTask(100)); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	msgs, err := consumer.Read(context.Background())
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Read = %v, %v; want one message", msgs, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if err := consumer.Requeue(ctx, msgs[0], "boom"); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+
+	requeued, err := consumer.Read(context.Background())
+	if err != nil || len(requeued) != 1 {
+		t.Fatalf("Read after requeue = %v, %v; want the requeued copy", requeued, err)
+	}
+	if requeued[0].Attempt != 2 {
+		t.Errorf("requeued attempt = %d, want 2", requeued[0].Attempt)
+	}
+	if pending := client.XPending(context.Background(), testStream, testGroup).Val(); pending.Count != 1 {
+		t.Errorf("pending = %d, want only the requeued copy", pending.Count)
+	}
+}
@@ -0,0 +1,152 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"basegraph.co/relay/common/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrDLQEntryNotFound = errors.New("dlq entry not found")
+	ErrInvalidDLQID     = errors.New("invalid dlq entry id")
+)
+
+// streamIDPattern matches a full Redis stream entry ID, <ms>-<seq>.
+var streamIDPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// redriveScript deletes the entry ARGV[1] from the DLQ at KEYS[2] and, only
+// if this call deleted it, adds the remaining ARGV field/value pairs to the
+// stream at KEYS[1]. Returns the new message ID, or nil when the entry was
+// already gone, so concurrent redrives of one entry enqueue it once.
+var redriveScript = redis.NewScript(`
+if redis.call('XDEL', KEYS[2], ARGV[1]) == 0 then
+	return false
+end
+return redis.call('XADD', KEYS[1], '*', unpack(ARGV, 2))
+`)
+
+// DLQEntry is a message that exhausted its attempts (or failed for good)
+// and was moved to the dead letter stream by SendDLQ.
+type DLQEntry struct {
+	ID       string         `json:"id"`
+	TaskType TaskType       `json:"task_type"`
+	Attempt  int            `json:"attempt"`
+	Error    string         `json:"error"`
+	Values   map[string]any `json:"values"`
+}
+
+// DLQManager inspects the dead letter stream and re-drives its entries
+// onto the stream the worker consumes.
+type DLQManager struct {
+	client    *redis.Client
+	stream    string
+	dlqStream string
+}
+
+func NewDLQManager(client *redis.Client, stream, dlqStream string) *DLQManager {
+	return &DLQManager{
+		client:    client,
+		stream:    stream,
+		dlqStream: dlqStream,
+	}
+}
+
+// ListDLQ returns up to count entries, oldest first.
+func (m *DLQManager) ListDLQ(ctx context.Context, count int64) ([]DLQEntry, error) {
+	msgs, err := m.client.XRangeN(ctx, m.dlqStream, "-", "+", count).Result()
+	if err != nil {
+		return nil, fmt.Errorf("xrange dlq (stream=%s): %w", m.dlqStream, err)
+	}
+
+	entries := make([]DLQEntry, 0, len(msgs))
+	for _, msg := range msgs {
+		entries = append(entries, newDLQEntry(msg))
+	}
+	return entries, nil
+}
+
+// GetDLQEntry returns the entry with the given stream ID, or
+// ErrDLQEntryNotFound.
+func (m *DLQManager) GetDLQEntry(ctx context.Context, id string) (DLQEntry, error) {
+	msg, err := m.get(ctx, id)
+	if err != nil {
+		return DLQEntry{}, err
+	}
+	return newDLQEntry(msg), nil
+}
+
+// RedriveDLQ moves an entry back onto the main stream with its attempt
+// count reset, and returns the ID of the new message. The delete and the
+// add run in one script, and the add only if the delete removed the entry,
+// so the entry is never in both streams and is redriven at most once.
+func (m *DLQManager) RedriveDLQ(ctx context.Context, id string) (string, error) {
+	ctx = logger.WithLogFields(ctx, logger.LogFields{
+		Component: "relay.queue.dlq",
+	})
+
+	raw, err := m.get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	msg, err := ParseMessage(raw)
+	if err != nil {
+		return "", fmt.Errorf("parsing dlq entry %s: %w", id, err)
+	}
+
+	values := messageValues(msg, 1)
+	if errMsg, ok := raw.Values["error"]; ok {
+		values["last_error"] = errMsg
+	}
+
+	args := make([]any, 0, 1+2*len(values))
+	args = append(args, id)
+	for field, value := range values {
+		args = append(args, field, value)
+	}
+	messageID, err := redriveScript.Run(ctx, m.client, []string{m.stream, m.dlqStream}, args...).Text()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrDLQEntryNotFound // Redriven by someone else since we read it
+	}
+	if err != nil {
+		return "", fmt.Errorf("redrive dlq entry %s: %w", id, err)
+	}
+
+	slog.InfoContext(ctx, "dlq entry redriven",
+		"dlq_id", id,
+		"message_id", messageID,
+		"task_type", msg.TaskType,
+		"stream", m.stream)
+	return messageID, nil
+}
+
+func (m *DLQManager) get(ctx context.Context, id string) (redis.XMessage, error) {
+	if !streamIDPattern.MatchString(id) {
+		return redis.XMessage{}, fmt.Errorf("%w: %q", ErrInvalidDLQID, id)
+	}
+
+	msgs, err := m.client.XRangeN(ctx, m.dlqStream, id, id, 1).Result()
+	if err != nil {
+		return redis.XMessage{}, fmt.Errorf("xrange dlq (stream=%s): %w", m.dlqStream, err)
+	}
+	if len(msgs) == 0 {
+		return redis.XMessage{}, ErrDLQEntryNotFound
+	}
+	return msgs[0], nil
+}
+
+// newDLQEntry reads the fields SendDLQ writes. Entries that don't parse as
+// a Message are still listed so they can be inspected.
+func newDLQEntry(msg redis.XMessage) DLQEntry {
+	entry := DLQEntry{ID: msg.ID, Values: msg.Values}
+	entry.Error, _ = parseOptionalString(msg.Values, "error")
+	if taskType, _ := parseOptionalString(msg.Values, "task_type"); taskType != "" {
+		entry.TaskType = TaskType(taskType)
+	}
+	entry.Attempt, _ = parseOptionalInt(msg.Values, "attempt")
+	return entry
+}
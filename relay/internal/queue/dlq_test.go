@@ -0,0 +1,227 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	testStream = "relay_events"
+	testGroup  = "relay_workers"
+	testDLQ    = "relay_events_dlq"
+)
+
+func newTestConsumer(t *testing.T) (*RedisConsumer, *DLQManager, *redis.Client) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	consumer, err := NewRedisConsumer(client, ConsumerConfig{
+		Stream:      testStream,
+		Group:       testGroup,
+		Consumer:    "worker-1",
+		DLQStream:   testDLQ,
+		BatchSize:   10,
+		MaxAttempts: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewRedisConsumer: %v", err)
+	}
+	return consumer, NewDLQManager(client, testStream, testDLQ), client
+}
+
+// deadLetter enqueues an issue event, reads it and sends it to the DLQ the
+// way the worker does after its last attempt.
+func deadLetter(t *testing.T, consumer *RedisConsumer, client *redis.Client, issueID int64, errMsg string) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := NewRedisProducer(client, testStream).Enqueue(ctx, Task{
+		TaskType:   TaskTypeIssueEvent,
+		EventLogID: 100 + issueID,
+		IssueID:    issueID,
+		EventType:  "issue_updated",
+		Attempt:    3,
+	}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	msgs, err := consumer.Read(ctx)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Read = %v, %v; want one message", msgs, err)
+	}
+	if err := consumer.SendDLQ(ctx, msgs[0], errMsg); err != nil {
+		t.Fatalf("SendDLQ: %v", err)
+	}
+}
+
+func TestListDLQ(t *testing.T) {
+	consumer, dlq, client := newTestConsumer(t)
+	deadLetter(t, consumer, client, 1, "planner timed out")
+	deadLetter(t, consumer, client, 2, "issue not found")
+
+	entries, err := dlq.ListDLQ(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("ListDLQ: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ListDLQ returned %d entries, want 2", len(entries))
+	}
+	first := entries[0]
+	if first.Error != "planner timed out" || first.TaskType != TaskTypeIssueEvent || first.Attempt != 3 {
+		t.Errorf("first entry = %+v", first)
+	}
+	if entries[1].Error != "issue not found" {
+		t.Errorf("second entry error = %q", entries[1].Error)
+	}
+
+	limited, err := dlq.ListDLQ(context.Background(), 1)
+	if err != nil || len(limited) != 1 || limited[0].ID != first.ID {
+		t.Errorf("ListDLQ(1) = %+v, %v", limited, err)
+	}
+}
+
+func TestGetDLQEntry(t *testing.T) {
+	consumer, dlq, client := newTestConsumer(t)
+	deadLetter(t, consumer, client, 7, "boom")
+
+	entries, err := dlq.ListDLQ(context.Background(), 10)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListDLQ = %v, %v", entries, err)
+	}
+
+	entry, err := dlq.GetDLQEntry(context.Background(), entries[0].ID)
+	if err != nil {
+		t.Fatalf("GetDLQEntry: %v", err)
+	}
+	if entry.Error != "boom" || entry.Values["issue_id"] != "7" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	if _, err := dlq.GetDLQEntry(context.Background(), "1-0"); !errors.Is(err, ErrDLQEntryNotFound) {
+		t.Errorf("GetDLQEntry(missing) error = %v, want ErrDLQEntryNotFound", err)
+	}
+}
+
+func TestRedriveDLQ(t *testing.T) {
+	ctx := context.Background()
+	consumer, dlq, client := newTestConsumer(t)
+	deadLetter(t, consumer, client, 42, "llm unavailable")
+
+	entries, err := dlq.ListDLQ(ctx, 10)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListDLQ = %v, %v", entries, err)
+	}
+
+	newID, err := dlq.RedriveDLQ(ctx, entries[0].ID)
+	if err != nil {
+		t.Fatalf("RedriveDLQ: %v", err)
+	}
+	if newID == "" {
+		t.Error("RedriveDLQ returned an empty message id")
+	}
+
+	if remaining, err := dlq.ListDLQ(ctx, 10); err != nil || len(remaining) != 0 {
+		t.Errorf("DLQ after redrive = %v, %v; want empty", remaining, err)
+	}
+
+	msgs, err := consumer.Read(ctx)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Read after redrive = %v, %v; want one message", msgs, err)
+	}
+	msg := msgs[0]
+	if msg.ID != newID || msg.IssueID == nil || *msg.IssueID != 42 || msg.EventType != "issue_updated" {
+		t.Errorf("redriven message = %+v", msg)
+	}
+	if msg.Attempt != 1 {
+		t.Errorf("redriven attempt = %d, want 1", msg.Attempt)
+	}
+	if msg.Raw.Values["last_error"] != "llm unavailable" {
+		t.Errorf("last_error = %v", msg.Raw.Values["last_error"])
+	}
+
+	if _, err := dlq.RedriveDLQ(ctx, entries[0].ID); !errors.Is(err, ErrDLQEntryNotFound) {
+		t.Errorf("second RedriveDLQ error = %v, want ErrDLQEntryNotFound", err)
+	}
+}
+
+func TestRedriveDLQConcurrentRedrivesEnqueueOnce(t *testing.T) {
+	ctx := context.Background()
+	consumer, dlq, client := newTestConsumer(t)
+	deadLetter(t, consumer, client, 42, "llm unavailable")
+	entries, err := dlq.ListDLQ(ctx, 10)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListDLQ = %v, %v", entries, err)
+	}
+
+	const redrives = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, redrives)
+	for range redrives {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := dlq.RedriveDLQ(ctx, entries[0].ID)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrDLQEntryNotFound):
+			t.Errorf("RedriveDLQ error = %v, want nil or ErrDLQEntryNotFound", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d redrives succeeded, want 1", succeeded)
+	}
+	// The original message plus one redrive.
+	if n, err := client.XLen(ctx, testStream).Result(); err != nil || n != 2 {
+		t.Errorf("stream length = %d, %v; want 2", n, err)
+	}
+}
+
+func TestDLQRejectsMalformedIDs(t *testing.T) {
+	ctx := context.Background()
+	_, dlq, _ := newTestConsumer(t)
+
+	for _, id := range []string{"abc", "1-x", "-", "+", "1700000000000"} {
+		if _, err := dlq.GetDLQEntry(ctx, id); !errors.Is(err, ErrInvalidDLQID) {
+			t.Errorf("GetDLQEntry(%q) error = %v, want ErrInvalidDLQID", id, err)
+		}
+		if _, err := dlq.RedriveDLQ(ctx, id); !errors.Is(err, ErrInvalidDLQID) {
+			t.Errorf("RedriveDLQ(%q) error = %v, want ErrInvalidDLQID", id, err)
+		}
+	}
+}
+
+func TestRedriveDLQRejectsUnparseableEntry(t *testing.T) {
+	ctx := context.Background()
+	_, dlq, client := newTestConsumer(t)
+
+	id, err := client.XAdd(ctx, &redis.XAddArgs{
+		Stream: testDLQ,
+		Values: map[string]any{"task_type": "unknown", "error": "bad"},
+	}).Result()
+	if err != nil {
+		t.Fatalf("XAdd: %v", err)
+	}
+
+	if _, err := dlq.RedriveDLQ(ctx, id); err == nil {
+		t.Fatal("expected an error redriving an unparseable entry")
+	}
+	if entry, err := dlq.GetDLQEntry(ctx, id); err != nil || entry.TaskType != "unknown" {
+		t.Errorf("unparseable entry should stay in the DLQ: %+v, %v", entry, err)
+	}
+}
@@ -0,0 +1,150 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func issueTask(eventLogID int64) Task {
+	return Task{
+		TaskType:   TaskTypeIssueEvent,
+		EventLogID: eventLogID,
+		IssueID:    9,
+		EventType:  "issue_created",
+	}
+}
+
+func TestEnqueueSkipsDuplicateIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	consumer, _, client := newTestConsumer(t)
+	producer := NewRedisProducer(client, testStream)
+
+	for i := 0; i < 2; i++ {
+		if err := producer.Enqueue(ctx, issueTask(100), WithIdempotencyKey("issue_event:100")); err != nil {
+			t.Fatalf("Enqueue #%d: %v", i+1, err)
+		}
+	}
+	if err := producer.Enqueue(ctx, issueTask(101), WithIdempotencyKey("issue_event:101")); err != nil {
+		t.Fatalf("Enqueue other key: %v", err)
+	}
+	// Without a key every enqueue goes through, as before.
+	for i := 0; i < 2; i++ {
+		if err := producer.Enqueue(ctx, issueTask(102)); err != nil {
+			t.Fatalf("Enqueue without key: %v", err)
+		}
+	}
+
+	if n := client.XLen(ctx, testStream).Val(); n != 4 {
+		t.Fatalf("stream length = %d, want 4", n)
+	}
+	msgs, err := consumer.Read(ctx)
+	if err != nil || len(msgs) != 4 {
+		t.Fatalf("Read = %v, %v; want 4 messages", msgs, err)
+	}
+	if msgs[0].IdempotencyKey != "issue_event:100" || msgs[2].IdempotencyKey != "" {
+		t.Errorf("idempotency keys = %q, %q", msgs[0].IdempotencyKey, msgs[2].IdempotencyKey)
+	}
+	if ttl := client.TTL(ctx, enqueuedKey(testStream, "issue_event:100")).Val(); ttl <= 0 || ttl > IdempotencyTTL {
+		t.Errorf("idempotency key ttl = %v, want within %v", ttl, IdempotencyTTL)
+	}
+}
+
+func TestEnqueueDoesNotClaimKeyWhenPublishFails(t *testing.T) {
+	ctx := context.Background()
+	_, _, client := newTestConsumer(t)
+
+	// A non-stream value at the stream key makes XADD fail.
+	if err := client.Set(ctx, "not-a-stream", "x", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	broken := NewRedisProducer(client, "not-a-stream")
+	if err := broken.Enqueue(ctx, issueTask(100), WithIdempotencyKey("issue_event:100")); err == nil {
+		t.Fatal("expected enqueue to a non-stream key to fail")
+	}
+	if n := client.Exists(ctx, enqueuedKey("not-a-stream", "issue_event:100")).Val(); n != 0 {
+		t.Fatal("idempotency key set by a failed publish")
+	}
+
+	// Once the stream is usable, the retry with the same key goes through.
+	if err := client.Del(ctx, "not-a-stream").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := broken.Enqueue(ctx, issueTask(100), WithIdempotencyKey("issue_event:100")); err != nil {
+		t.Fatalf("retry Enqueue: %v", err)
+	}
+	if n := client.XLen(ctx, "not-a-stream").Val(); n != 1 {
+		t.Errorf("stream length after retry = %d, want 1", n)
+	}
+}
+
+func TestEnqueuedKeySharesTheStreamsHashSlot(t *testing.T) {
+	tests := []struct {
+		stream string
+		want   string
+	}{
+		{stream: "relay_events", want: "relay_events"},
+		{stream: "{org-123}:agent-stream", want: "org-123"},
+		{stream: "agent-stream:{org-123}:ws-1", want: "org-123"},
+	}
+	for _, tt := range tests {
+		if got := hashTag(tt.stream); got != tt.want {
+			t.Errorf("hashTag(%q) = %q, want %q", tt.stream, got, tt.want)
+		}
+		key := enqueuedKey(tt.stream, "issue_event:100")
+		if got := hashTag(key); got != hashTag(tt.stream) {
+			t.Errorf("enqueuedKey(%q) = %q hashes on %q, want %q", tt.stream, key, got, hashTag(tt.stream))
+		}
+	}
+}
+
+func TestProcessedMessagesAreDroppedOnRedelivery(t *testing.T) {
+	ctx := context.Background()
+	consumer, _, client := newTestConsumer(t)
+
+	if err := NewRedisProducer(client, testStream).Enqueue(ctx, issueTask(100), WithIdempotencyKey("issue_event:100")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	msgs, err := consumer.Read(ctx)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Read = %v, %v; want one message", msgs, err)
+	}
+
+	// The worker finishes the task but dies before acking; the reclaimer
+	// claims the pending message.
+	if consumer.AckIfProcessed(ctx, msgs[0]) {
+		t.Fatal("AckIfProcessed before MarkProcessed = true")
+	}
+	if err := consumer.MarkProcessed(ctx, msgs[0]); err != nil {
+		t.Fatalf("MarkProcessed: %v", err)
+	}
+	claimed, err := client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   testStream,
+		Group:    testGroup,
+		Consumer: "reclaimer",
+		Messages: []string{msgs[0].ID},
+	}).Result()
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("XClaim = %v, %v", claimed, err)
+	}
+	reclaimed, err := ParseMessage(claimed[0])
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+
+	if !consumer.AckIfProcessed(ctx, reclaimed) {
+		t.Fatal("AckIfProcessed(reclaimed) = false, want the processed message dropped")
+	}
+	if pending := client.XPending(ctx, testStream, testGroup).Val(); pending.Count != 0 {
+		t.Errorf("pending after drop = %d, want 0", pending.Count)
+	}
+
+	// A requeued copy keeps its key, so Read drops it too.
+	if err := client.XAdd(ctx, &redis.XAddArgs{Stream: testStream, Values: messageValues(reclaimed, 2)}).Err(); err != nil {
+		t.Fatalf("XAdd: %v", err)
+	}
+	if msgs, err := consumer.Read(ctx); err != nil || len(msgs) != 0 {
+		t.Errorf("Read after processed = %v, %v; want none", msgs, err)
+	}
+}
@@ -2,18 +2,78 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"basegraph.co/relay/common/logger"
 	"github.com/redis/go-redis/v9"
 )
 
 type Producer interface {
-	Enqueue(ctx context.Context, task Task) error
+	Enqueue(ctx context.Context, task Task, opts ...EnqueueOption) error
 	Close() error
 }
 
+// IdempotencyTTL is how long an enqueued idempotency key blocks duplicates.
+// It only needs to cover publish retries, not the task's lifetime; the
+// consumer records processed keys separately.
+const IdempotencyTTL = 10 * time.Minute
+
+const enqueuedKeyPrefix = "relay:idempotency:enqueued:"
+
+// enqueuedKey is the idempotency key for key on stream. enqueueOnceScript
+// touches both keys, which Redis Cluster only allows within one hash slot,
+// so the key carries the stream's hash tag: the part of its name in {braces},
+// or the whole name when it has none. A stream with a "}" but no tag (such as
+// "a{}b") can't be matched and needs a tag of its own to run on a cluster.
+func enqueuedKey(stream, key string) string {
+	return enqueuedKeyPrefix + "{" + hashTag(stream) + "}:" + key
+}
+
+// hashTag returns the part of key Redis Cluster hashes to pick a slot.
+func hashTag(key string) string {
+	if open := strings.IndexByte(key, '{'); open >= 0 {
+		if end := strings.IndexByte(key[open+1:], '}'); end > 0 {
+			return key[open+1 : open+1+end]
+		}
+	}
+	return key
+}
+
+// enqueueOnceScript adds the ARGV field/value pairs (after the TTL in ms at
+// ARGV[1]) to the stream at KEYS[1] unless the idempotency key KEYS[2] is
+// set, then sets it. Returns the new message ID, or nil for a duplicate.
+// The key is only ever set alongside a successful XADD, so a publish that
+// fails or never reaches Redis can't block the caller's retry.
+var enqueueOnceScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	return false
+end
+local id = redis.call('XADD', KEYS[1], '*', unpack(ARGV, 2))
+redis.call('SET', KEYS[2], KEYS[1], 'PX', ARGV[1])
+return id
+`)
+
+// EnqueueOption configures a single Enqueue call.
+type EnqueueOption func(*enqueueOptions)
+
+type enqueueOptions struct {
+	idempotencyKey string
+}
+
+// WithIdempotencyKey skips the enqueue if a task with the same key was
+// enqueued to the same stream within IdempotencyTTL, so a retried publish doesn't reach the
+// worker twice. The key travels with the message for the consumer's
+// processed check.
+func WithIdempotencyKey(key string) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.idempotencyKey = key
+	}
+}
+
 type StreamResolver func(task Task) (string, error)
 
 type redisProducer struct {
@@ -36,7 +96,12 @@ func NewRedisProducerWithResolver(client *redis.Client, resolver StreamResolver)
 	}
 }
 
-func (p *redisProducer) Enqueue(ctx context.Context, task Task) error {
+func (p *redisProducer) Enqueue(ctx context.Context, task Task, opts ...EnqueueOption) error {
+	var options enqueueOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var issueID *int64
 	var eventLogID *int64
 	if task.IssueID != 0 {
@@ -106,11 +171,31 @@ func (p *redisProducer) Enqueue(ctx context.Context, task Task) error {
 
 	// TODO - @nithinsj - Add MAXLEN to prevent stream growing unbounded. Redis streams grow until out of memory.
 	// Consider XTRIM periodically or MAXLEN ~ with XAdd to cap at ~1M entries.
-	if err := p.client.XAdd(ctx, &redis.XAddArgs{
-		Stream: stream,
-		Values: fields,
-	}).Err(); err != nil {
-		return fmt.Errorf("enqueue task (stream=%s): %w", stream, err)
+	key := options.idempotencyKey
+	if key == "" {
+		if err := p.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			Values: fields,
+		}).Err(); err != nil {
+			return fmt.Errorf("enqueue task (stream=%s): %w", stream, err)
+		}
+	} else {
+		fields["idempotency_key"] = key
+		args := []any{IdempotencyTTL.Milliseconds()}
+		for field, value := range fields {
+			args = append(args, field, value)
+		}
+		err := enqueueOnceScript.Run(ctx, p.client, []string{stream, enqueuedKey(stream, key)}, args...).Err()
+		if errors.Is(err, redis.Nil) {
+			slog.InfoContext(ctx, "duplicate enqueue skipped",
+				"task_type", taskType,
+				"idempotency_key", key,
+				"stream", stream)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("enqueue task (stream=%s): %w", stream, err)
+		}
 	}
 
 	slog.InfoContext(ctx, "enqueued task",
@@ -118,6 +203,7 @@ func (p *redisProducer) Enqueue(ctx context.Context, task Task) error {
 		"event_type", task.EventType,
 		"attempt", attempt,
 		"trace_id", traceIDStr,
+		"idempotency_key", key,
 		"stream", stream)
 	return nil
 }
@@ -273,7 +273,7 @@ func (s *eventIngestService) Ingest(ctx context.Context, params EventIngestParam
 			TriggerThreadID: params.DiscussionID,
 			WorkspaceID:     &integration.WorkspaceID,
 			OrganizationID:  &integration.OrganizationID,
-		}); err != nil {
+		}, queue.WithIdempotencyKey(fmt.Sprintf("issue_event:%d", eventLog.ID))); err != nil {
 			return nil, fmt.Errorf("enqueueing event: %w", err)
 		}
 		enqueued = true
@@ -126,6 +126,10 @@ func (m *mockIssueStore) UpdateSpecStatus(ctx context.Context, id int64, status
 	return nil
 }
 
+func (m *mockIssueStore) ListWithFindings(ctx context.Context, integrationID, excludeID int64, limit int32) ([]model.Issue, error) {
+	return nil, nil
+}
+
 type mockIssueTrackerService struct {
 	fetchFn            func(ctx context.Context, params issue_tracker.FetchIssueParams) (*model.Issue, error)
 	fetchDiscussionsFn func(ctx context.Context, params issue_tracker.FetchDiscussionsParams) ([]model.Discussion, error)
@@ -223,7 +227,7 @@ type mockQueueProducer struct {
 	enqueueFn func(ctx context.Context, task queue.Task) error
 }
 
-func (m *mockQueueProducer) Enqueue(ctx context.Context, task queue.Task) error {
+func (m *mockQueueProducer) Enqueue(ctx context.Context, task queue.Task, opts ...queue.EnqueueOption) error {
 	if m.enqueueFn != nil {
 		return m.enqueueFn(ctx, task)
 	}
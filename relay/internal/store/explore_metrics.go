@@ -0,0 +1,40 @@
+package store
+
+import (
+	"context"
+
+	"basegraph.co/relay/common/id"
+	"basegraph.co/relay/core/db/sqlc"
+	"basegraph.co/relay/internal/model"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type exploreMetricsStore struct {
+	queries *sqlc.Queries
+}
+
+func newExploreMetricsStore(queries *sqlc.Queries) ExploreMetricsStore {
+	return &exploreMetricsStore{queries: queries}
+}
+
+func (s *exploreMetricsStore) Create(ctx context.Context, m model.ExploreMetricsRecord) error {
+	return s.queries.InsertExploreMetrics(ctx, sqlc.InsertExploreMetricsParams{
+		ID:                     id.New(),
+		SessionID:              m.SessionID,
+		Query:                  m.Query,
+		Thoroughness:           m.Thoroughness,
+		StartedAt:              pgtype.Timestamptz{Time: m.StartedAt, Valid: true},
+		DurationMs:             m.DurationMs,
+		Iterations:             int32(m.Iterations),
+		ContextWindowTokens:    int32(m.ContextWindowTokens),
+		CompletionTokens:       int32(m.CompletionTokens),
+		CodegraphCalls:         int32(m.CodegraphCalls),
+		CodegraphInvalidKind:   int32(m.CodegraphInvalidKind),
+		CodegraphAmbiguous:     int32(m.CodegraphAmbiguous),
+		CodegraphTraceFound:    int32(m.CodegraphTraceFound),
+		CodegraphTraceNotFound: int32(m.CodegraphTraceNotFound),
+		Confidence:             m.Confidence,
+		TerminationReason:      m.TerminationReason,
+		Metrics:                m.Metrics,
+	})
+}
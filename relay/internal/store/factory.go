@@ -48,6 +48,10 @@ func (s *Stores) Issues() IssueStore {
 	return newIssueStore(s.queries)
 }
 
+func (s *Stores) SpecLocks() SpecLockStore {
+	return newSpecLockStore(s.queries)
+}
+
 func (s *Stores) EventLogs() EventLogStore {
 	return newEventLogStore(s.queries)
 }
@@ -60,6 +64,10 @@ func (s *Stores) Learnings() LearningStore {
 	return newLearningStore(s.queries)
 }
 
+func (s *Stores) ExploreMetrics() ExploreMetricsStore {
+	return newExploreMetricsStore(s.queries)
+}
+
 func (s *Stores) LLMEvals() LLMEvalStore {
 	return newLLMEvalStore(s.queries)
 }
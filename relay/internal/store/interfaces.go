@@ -118,6 +118,9 @@ type IssueStore interface {
 	UpdateSpec(ctx context.Context, id int64, spec *string) error
 	// UpdateSpecStatus updates only the spec_status column.
 	UpdateSpecStatus(ctx context.Context, id int64, status model.SpecStatus) error
+	// ListWithFindings returns the most recently updated issues in an integration
+	// that have code findings, excluding excludeID.
+	ListWithFindings(ctx context.Context, integrationID, excludeID int64, limit int32) ([]model.Issue, error)
 
 	// Issue-centric processing state transitions
 	// QueueIfIdle queues an issue for processing, with automatic stuck issue recovery.
@@ -133,6 +136,16 @@ type IssueStore interface {
 	ResetQueuedToIdle(ctx context.Context, issueID int64) error
 }
 
+// SpecLockStore is an advisory, expiring lock around spec generation so two
+// workers don't generate and write specs for the same issue concurrently.
+type SpecLockStore interface {
+	// TryAcquire takes the lock for issueID unless someone else holds an
+	// unexpired one. The lock expires after ttl even if never released.
+	TryAcquire(ctx context.Context, issueID int64, owner string, ttl time.Duration) (acquired bool, err error)
+	// Release drops the lock if owner still holds it.
+	Release(ctx context.Context, issueID int64, owner string) error
+}
+
 type EventLogStore interface {
 	Create(ctx context.Context, log *model.EventLog) (*model.EventLog, error)
 	CreateOrGet(ctx context.Context, log *model.EventLog) (*model.EventLog, bool, error)
@@ -176,6 +189,11 @@ type LLMEvalStore interface {
 	GetStats(ctx context.Context, stage string, since time.Time) (*model.LLMEvalStats, error)
 }
 
+// ExploreMetricsStore keeps one row per explore agent session.
+type ExploreMetricsStore interface {
+	Create(ctx context.Context, m model.ExploreMetricsRecord) error
+}
+
 type GapStore interface {
 	Create(ctx context.Context, gap model.Gap) (model.Gap, error)
 	GetByID(ctx context.Context, id int64) (model.Gap, error)
@@ -172,6 +172,26 @@ func (s *issueStore) UpdateSpecStatus(ctx context.Context, id int64, status mode
 	})
 }
 
+func (s *issueStore) ListWithFindings(ctx context.Context, integrationID, excludeID int64, limit int32) ([]model.Issue, error) {
+	rows, err := s.queries.ListIssuesWithFindings(ctx, sqlc.ListIssuesWithFindingsParams{
+		IntegrationID: integrationID,
+		ID:            excludeID,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]model.Issue, 0, len(rows))
+	for _, row := range rows {
+		issue, err := toIssueModel(row)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, *issue)
+	}
+	return issues, nil
+}
+
 func toIssueModel(row sqlc.Issue) (*model.Issue, error) {
 	var keywords []model.Keyword
 	if len(row.Keywords) > 0 {
@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"basegraph.co/relay/core/db/sqlc"
+)
+
+type specLockStore struct {
+	queries *sqlc.Queries
+}
+
+func newSpecLockStore(queries *sqlc.Queries) SpecLockStore {
+	return &specLockStore{queries: queries}
+}
+
+func (s *specLockStore) TryAcquire(ctx context.Context, issueID int64, owner string, ttl time.Duration) (bool, error) {
+	rows, err := s.queries.AcquireSpecLock(ctx, sqlc.AcquireSpecLockParams{
+		IssueID:    issueID,
+		Owner:      owner,
+		TtlSeconds: ttl.Seconds(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (s *specLockStore) Release(ctx context.Context, issueID int64, owner string) error {
+	return s.queries.ReleaseSpecLock(ctx, sqlc.ReleaseSpecLockParams{
+		IssueID: issueID,
+		Owner:   owner,
+	})
+}
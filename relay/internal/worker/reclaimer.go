@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"basegraph.co/relay/common/logger"
@@ -29,12 +30,19 @@ type RedisReclaimer struct {
 	consumer  *queue.RedisConsumer
 	processor queue.MessageProcessor
 
+	stopOnce  sync.Once
 	stopCh    chan struct{}
 	stoppedCh chan struct{}
+
+	// abortCtx is cancelled when Stop's deadline passes, cancelling the
+	// message being processed. See Stop.
+	abortCtx context.Context
+	abort    context.CancelFunc
 }
 
 // NewRedisReclaimer creates a new RedisReclaimer.
 func NewRedisReclaimer(client *redis.Client, cfg RedisReclaimerConfig, consumer *queue.RedisConsumer, processor queue.MessageProcessor) *RedisReclaimer {
+	abortCtx, abort := context.WithCancel(context.Background())
 	return &RedisReclaimer{
 		client:    client,
 		cfg:       cfg,
@@ -42,10 +50,14 @@ func NewRedisReclaimer(client *redis.Client, cfg RedisReclaimerConfig, consumer
 		processor: processor,
 		stopCh:    make(chan struct{}),
 		stoppedCh: make(chan struct{}),
+		abortCtx:  abortCtx,
+		abort:     abort,
 	}
 }
 
-// Run starts the reclaimer loop. Blocks until Stop() is called.
+// Run starts the reclaimer loop. Blocks until Stop() is called or ctx is
+// done. Cancelling ctx stops claiming but doesn't cancel the message being
+// processed; Stop's deadline does.
 func (r *RedisReclaimer) Run(ctx context.Context) {
 	ctx = logger.WithLogFields(ctx, logger.LogFields{
 		Component: "relay.worker.reclaimer",
@@ -53,6 +65,11 @@ func (r *RedisReclaimer) Run(ctx context.Context) {
 
 	defer close(r.stoppedCh)
 
+	processCtx, cancelProcessing := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelProcessing()
+	stopAbort := context.AfterFunc(r.abortCtx, cancelProcessing)
+	defer stopAbort()
+
 	ticker := time.NewTicker(r.cfg.Interval)
 	defer ticker.Stop()
 
@@ -70,21 +87,46 @@ func (r *RedisReclaimer) Run(ctx context.Context) {
 			slog.InfoContext(ctx, "reclaimer stopping")
 			return
 		case <-ticker.C:
-			if err := r.reclaimOnce(ctx); err != nil {
+			if err := r.reclaimOnce(ctx, processCtx); err != nil {
 				slog.ErrorContext(ctx, "reclaim cycle error", "error", err)
 			}
 		}
 	}
 }
 
-// Stop signals the reclaimer to stop gracefully.
-func (r *RedisReclaimer) Stop() {
-	close(r.stopCh)
-	<-r.stoppedCh
+// Stop stops the reclaimer from claiming more messages and waits for the
+// reclaimed message in flight to finish processing, so it isn't left half
+// done and reclaimed again after restart. If ctx ends first, the in-flight
+// processing is cancelled and Stop returns ctx's error without waiting
+// further. Safe to call more than once; Run must have been started.
+func (r *RedisReclaimer) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+
+	select {
+	case <-r.stoppedCh:
+		return nil
+	case <-ctx.Done():
+		r.abort()
+		return ctx.Err()
+	}
+}
+
+// stopping reports whether Run should stop claiming messages.
+func (r *RedisReclaimer) stopping(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-r.stopCh:
+		return true
+	default:
+		return false
+	}
 }
 
-// reclaimOnce performs one reclaim cycle.
-func (r *RedisReclaimer) reclaimOnce(ctx context.Context) error {
+// reclaimOnce performs one reclaim cycle. ctx bounds the cycle; messages are
+// claimed and processed under processCtx so a stop lets the current one
+// finish. The rest of the batch is left pending for the next worker.
+func (r *RedisReclaimer) reclaimOnce(ctx, processCtx context.Context) error {
 	pending, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
 		Stream: r.cfg.Stream,
 		Group:  r.cfg.Group,
@@ -104,7 +146,11 @@ func (r *RedisReclaimer) reclaimOnce(ctx context.Context) error {
 	slog.InfoContext(ctx, "found stale pending messages", "count", len(pending))
 
 	for _, p := range pending {
-		if err := r.reclaimMessage(ctx, p); err != nil {
+		if r.stopping(ctx) {
+			slog.InfoContext(ctx, "reclaimer stopping, leaving rest of batch pending")
+			return nil
+		}
+		if err := r.reclaimMessage(processCtx, p); err != nil {
 			slog.ErrorContext(ctx, "failed to reclaim message",
 				"error", err,
 				"message_id", p.ID,
@@ -165,6 +211,11 @@ func (r *RedisReclaimer) reclaimMessage(ctx context.Context, pending redis.XPend
 
 	slog.DebugContext(ctx, "message claimed successfully")
 
+	// The original consumer may have finished it and died before acking
+	if r.consumer.AckIfProcessed(ctx, parsed) {
+		return nil
+	}
+
 	start := time.Now()
 	if err := r.processor(ctx, parsed); err != nil {
 		return fmt.Errorf("processing reclaimed message: %w", err)
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"basegraph.co/relay/internal/queue"
+)
+
+const (
+	testStream = "relay_events"
+	testGroup  = "relay_workers"
+)
+
+// newStaleReclaimer leaves n issue events pending on a dead consumer and
+// returns a reclaimer over them that runs process.
+func newStaleReclaimer(t *testing.T, n int, process queue.MessageProcessor) (*RedisReclaimer, *redis.Client) {
+	t.Helper()
+	ctx := context.Background()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	consumer, err := queue.NewRedisConsumer(client, queue.ConsumerConfig{
+		Stream:    testStream,
+		Group:     testGroup,
+		Consumer:  "dead-worker",
+		BatchSize: int64(n),
+	})
+	if err != nil {
+		t.Fatalf("NewRedisConsumer: %v", err)
+	}
+	producer := queue.NewRedisProducer(client, testStream)
+	for i := 0; i < n; i++ {
+		if err := producer.Enqueue(ctx, queue.Task{
+			TaskType:   queue.TaskTypeIssueEvent,
+			EventLogID: int64(100 + i),
+			IssueID:    int64(i + 1),
+			EventType:  "issue_created",
+		}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	if msgs, err := consumer.Read(ctx); err != nil || len(msgs) != n {
+		t.Fatalf("Read = %d messages, %v; want %d", len(msgs), err, n)
+	}
+
+	// The processor acks the way the worker's does.
+	ackingProcess := func(ctx context.Context, msg queue.Message) error {
+		if err := process(ctx, msg); err != nil {
+			return err
+		}
+		return consumer.Ack(ctx, msg)
+	}
+	return NewRedisReclaimer(client, RedisReclaimerConfig{
+		Stream:    testStream,
+		Group:     testGroup,
+		Consumer:  "reclaimer",
+		Interval:  10 * time.Millisecond,
+		BatchSize: int64(n),
+	}, consumer, ackingProcess), client
+}
+
+// blockingProcessor signals started when a message begins processing and
+// holds it until release is closed or its context is cancelled.
+func blockingProcessor(calls *atomic.Int32, started chan<- struct{}, release <-chan struct{}) queue.MessageProcessor {
+	return func(ctx context.Context, msg queue.Message) error {
+		calls.Add(1)
+		started <- struct{}{}
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestReclaimerStopWaitsForInFlightMessage(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	reclaimer, client := newStaleReclaimer(t, 2, blockingProcessor(&calls, started, release))
+
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	defer cancelRun()
+	go reclaimer.Run(runCtx)
+	<-started
+
+	// The worker cancels its run context before stopping the reclaimer; the
+	// in-flight message must survive that.
+	cancelRun()
+	stopped := make(chan error, 1)
+	go func() { stopped <- reclaimer.Stop(context.Background()) }()
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned %v while a reclaimed message was in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("Stop = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the in-flight message finished")
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("processed %d messages, want 1: the rest of the batch stays pending", n)
+	}
+	pending := client.XPending(context.Background(), testStream, testGroup).Val()
+	if pending.Count != 1 {
+		t.Errorf("pending = %d, want 1 (finished message acked, unclaimed one left)", pending.Count)
+	}
+}
+
+func TestReclaimerStopCancelsInFlightMessageAtDeadline(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{}, 1)
+	reclaimer, _ := newStaleReclaimer(t, 1, blockingProcessor(&calls, started, nil))
+
+	go reclaimer.Run(context.Background())
+	<-started
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := reclaimer.Stop(stopCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The processor sees its context cancelled and Run exits.
+	select {
+	case <-reclaimer.stoppedCh:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not exit after the in-flight message was cancelled")
+	}
+	if err := reclaimer.Stop(context.Background()); err != nil {
+		t.Errorf("second Stop = %v, want nil", err)
+	}
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+create table spec_locks (
+    issue_id bigint primary key references issues(id) on delete cascade,
+    owner text not null,
+    locked_until timestamptz not null,
+
+    created_at timestamptz not null default now()
+);
+
+comment on table spec_locks is 'Advisory lock held while a spec is generated for an issue; expires at locked_until';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists spec_locks;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+create table explore_metrics (
+    id bigint primary key,
+    session_id text not null,
+    query text not null,
+    thoroughness text not null,
+    started_at timestamptz not null,
+    duration_ms bigint not null,
+    iterations int not null,
+    context_window_tokens int not null,
+    completion_tokens int not null,
+
+    -- Codegraph effectiveness, denormalized for charting
+    codegraph_calls int not null,
+    codegraph_invalid_kind int not null,
+    codegraph_ambiguous int not null,
+    codegraph_trace_found int not null,
+    codegraph_trace_not_found int not null,
+
+    confidence text not null,
+    termination_reason text not null,
+
+    -- Full ExploreMetrics as written to explore_metrics_<session>.json
+    metrics jsonb not null,
+
+    created_at timestamptz not null default now()
+);
+
+create index idx_explore_metrics_started_at on explore_metrics (started_at);
+
+comment on table explore_metrics is 'One row per explore agent session, for effectiveness dashboards';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists explore_metrics;
+-- +goose StatementEnd